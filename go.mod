@@ -3,6 +3,7 @@ module github.com/stormkit-io/stormkit-io
 go 1.25
 
 require (
+	github.com/BurntSushi/toml v1.6.0
 	github.com/DATA-DOG/go-txdb v0.2.1
 	github.com/NYTimes/gziphandler v1.1.1
 	github.com/Pallinder/go-randomdata v1.2.0
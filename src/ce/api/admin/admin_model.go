@@ -4,6 +4,7 @@ import (
 	"context"
 	"database/sql/driver"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path"
@@ -26,6 +27,29 @@ const (
 	SIGNUP_MODE_WAITLIST = "waitlist"
 )
 
+// DefaultRetentionMaxAgeDays is the number of days after which unpublished
+// deployments are removed when no retention policy is configured.
+const DefaultRetentionMaxAgeDays = 30
+
+var ErrInvalidRetentionPolicy = errors.New("Retention policy values cannot be negative.")
+
+// DefaultBuildQueueConcurrency is the number of deployments that are built in
+// parallel when no limit is configured.
+const DefaultBuildQueueConcurrency = 10
+
+var ErrInvalidBuildQueueConfig = errors.New("Build queue limits cannot be negative.")
+
+var ErrInvalidSMTPConfig = errors.New("SMTP host and username are required.")
+
+// Default quotas of the key-value store of each environment.
+const (
+	DefaultKVMaxKeys      = 1000
+	DefaultKVMaxValueSize = 64 * 1024
+	DefaultKVMaxTotalSize = 10 * 1024 * 1024
+)
+
+var ErrInvalidKVConfig = errors.New("Key-value store quotas cannot be negative.")
+
 type mdwrs = []func(stack *middleware.Stack) error
 
 type VolumesConfig struct {
@@ -108,6 +132,76 @@ type DomainConfig struct {
 	Webhooks string `json:"webhooks,omitempty"` // e.g. webhooks.stormkit.io
 }
 
+// RetentionPolicy describes which deployments keep their artifacts. Currently
+// published, pinned and tagged deployments are always kept.
+type RetentionPolicy struct {
+	KeepLast          int `json:"keepLast,omitempty"`          // The number of most recent deployments that are kept per environment.
+	KeepPublishedDays int `json:"keepPublishedDays,omitempty"` // Deployments that were published within the last N days are kept.
+	MaxAgeDays        int `json:"maxAgeDays,omitempty"`        // Unpublished deployments are removed after N days. Defaults to 30.
+	PreviewMaxAgeDays int `json:"previewMaxAgeDays,omitempty"` // Unpublished preview and branch deployments are removed after N days. Defaults to MaxAgeDays.
+}
+
+// Validate validates the retention policy.
+func (p *RetentionPolicy) Validate() error {
+	if p.KeepLast < 0 || p.KeepPublishedDays < 0 || p.MaxAgeDays < 0 || p.PreviewMaxAgeDays < 0 {
+		return ErrInvalidRetentionPolicy
+	}
+
+	return nil
+}
+
+// BuildQueueConfig limits the number of deployments that are built in parallel.
+// Deployments exceeding the limits wait in the build queue.
+type BuildQueueConfig struct {
+	MaxConcurrency        int `json:"maxConcurrency,omitempty"`        // The number of deployments built in parallel on the instance.
+	MaxConcurrencyPerTeam int `json:"maxConcurrencyPerTeam,omitempty"` // The number of deployments built in parallel per team. 0 means no limit.
+}
+
+// Validate validates the build queue limits.
+func (c *BuildQueueConfig) Validate() error {
+	if c.MaxConcurrency < 0 || c.MaxConcurrencyPerTeam < 0 {
+		return ErrInvalidBuildQueueConfig
+	}
+
+	return nil
+}
+
+// SMTPConfig is the mail server used to send platform emails, such as
+// deployment and domain notifications, to the users of the instance.
+type SMTPConfig struct {
+	Host     string `json:"host"`
+	Port     string `json:"port,omitempty"` // Defaults to 587
+	Username string `json:"username"`
+	Password string `json:"password,omitempty"`
+	From     string `json:"from,omitempty"` // The sender address. Defaults to the username.
+}
+
+// KVConfig contains the quotas of the key-value store of each environment.
+type KVConfig struct {
+	MaxKeys      int  `json:"maxKeys,omitempty"`      // The number of keys per environment. Defaults to DefaultKVMaxKeys.
+	MaxValueSize int  `json:"maxValueSize,omitempty"` // The size of a value in bytes. Defaults to DefaultKVMaxValueSize.
+	MaxTotalSize int  `json:"maxTotalSize,omitempty"` // The size of all values per environment in bytes. Defaults to DefaultKVMaxTotalSize.
+	RedisCache   bool `json:"redisCache,omitempty"`   // Whether reads are cached in Redis.
+}
+
+// Validate validates the key-value store quotas.
+func (c *KVConfig) Validate() error {
+	if c.MaxKeys < 0 || c.MaxValueSize < 0 || c.MaxTotalSize < 0 {
+		return ErrInvalidKVConfig
+	}
+
+	return nil
+}
+
+// Validate validates the SMTP configuration.
+func (c *SMTPConfig) Validate() error {
+	if c.Host == "" || c.Username == "" {
+		return ErrInvalidSMTPConfig
+	}
+
+	return nil
+}
+
 type InstanceConfig struct {
 	AdminUserConfig    *AdminUserConfig    `json:"adminUser"`
 	VolumesConfig      *VolumesConfig      `json:"volumes"`
@@ -117,6 +211,10 @@ type InstanceConfig struct {
 	LicenseConfig      *LicenseConfig      `json:"license,omitempty"`
 	AuthConfig         *AuthConfig         `json:"auth,omitempty"`
 	DomainConfig       *DomainConfig       `json:"domains,omitempty"`
+	RetentionConfig    *RetentionPolicy    `json:"retention,omitempty"`
+	BuildQueueConfig   *BuildQueueConfig   `json:"buildQueue,omitempty"`
+	SMTPConfig         *SMTPConfig         `json:"smtp,omitempty"`
+	KVConfig           *KVConfig           `json:"kv,omitempty"`
 }
 
 // Scan implements the sql.Scanner interface
@@ -152,6 +250,10 @@ func (c *InstanceConfig) Scan(val any) error {
 		c.LicenseConfig.Key = utils.DecryptToString(c.LicenseConfig.Key)
 	}
 
+	if c.SMTPConfig != nil && c.SMTPConfig.Password != "" {
+		c.SMTPConfig.Password = utils.DecryptToString(c.SMTPConfig.Password)
+	}
+
 	if c.AuthConfig != nil {
 		c.AuthConfig.Github.ClientSecret = utils.DecryptToString(c.AuthConfig.Github.ClientSecret)
 		c.AuthConfig.Github.PrivateKey = utils.DecryptToString(c.AuthConfig.Github.PrivateKey)
@@ -178,6 +280,12 @@ func (c InstanceConfig) Value() (driver.Value, error) {
 		c.LicenseConfig.Key = utils.EncryptToString(c.LicenseConfig.Key)
 	}
 
+	if c.SMTPConfig != nil && c.SMTPConfig.Password != "" {
+		smtp := *c.SMTPConfig
+		smtp.Password = utils.EncryptToString(smtp.Password)
+		c.SMTPConfig = &smtp
+	}
+
 	if c.AuthConfig != nil {
 		if c.AuthConfig.Github.ClientSecret != "" {
 			c.AuthConfig.Github.ClientSecret = utils.EncryptToString(c.AuthConfig.Github.ClientSecret)
@@ -249,6 +357,70 @@ func (vc InstanceConfig) IsGithubEnabled() bool {
 		vc.AuthConfig.Github.AppID > 0
 }
 
+// Retention returns the default retention policy of the instance.
+func (vc InstanceConfig) Retention() RetentionPolicy {
+	policy := RetentionPolicy{}
+
+	if vc.RetentionConfig != nil {
+		policy = *vc.RetentionConfig
+	}
+
+	if policy.MaxAgeDays == 0 {
+		policy.MaxAgeDays = DefaultRetentionMaxAgeDays
+	}
+
+	return policy
+}
+
+// BuildQueue returns the build queue limits of the instance. When the instance
+// limit is not configured, the runner concurrency is used.
+func (vc InstanceConfig) BuildQueue() BuildQueueConfig {
+	cnf := BuildQueueConfig{}
+
+	if vc.BuildQueueConfig != nil {
+		cnf = *vc.BuildQueueConfig
+	}
+
+	if cnf.MaxConcurrency == 0 {
+		cnf.MaxConcurrency = DefaultBuildQueueConcurrency
+
+		if runner := config.Get().Runner; runner != nil && runner.Concurrency > 0 {
+			cnf.MaxConcurrency = runner.Concurrency
+		}
+	}
+
+	return cnf
+}
+
+// KV returns the quotas of the key-value store. Quotas that are
+// not configured fall back to their default values.
+func (vc InstanceConfig) KV() KVConfig {
+	cnf := KVConfig{}
+
+	if vc.KVConfig != nil {
+		cnf = *vc.KVConfig
+	}
+
+	if cnf.MaxKeys == 0 {
+		cnf.MaxKeys = DefaultKVMaxKeys
+	}
+
+	if cnf.MaxValueSize == 0 {
+		cnf.MaxValueSize = DefaultKVMaxValueSize
+	}
+
+	if cnf.MaxTotalSize == 0 {
+		cnf.MaxTotalSize = DefaultKVMaxTotalSize
+	}
+
+	return cnf
+}
+
+// IsSMTPEnabled returns whether platform emails can be sent or not.
+func (vc InstanceConfig) IsSMTPEnabled() bool {
+	return vc.SMTPConfig != nil && vc.SMTPConfig.Host != "" && vc.SMTPConfig.Username != ""
+}
+
 // SignUpMode returns the configured sign up mode.
 // If the AuthConfig or UserManagement configurations are not defined,
 // the default is `on`
@@ -197,6 +197,45 @@ func (s *AdminModelSuite) Test_SignUpMode_CE() {
 	s.Equal(admin.SIGNUP_MODE_OFF, vc.SignUpMode())
 }
 
+func (s *AdminModelSuite) Test_Retention() {
+	vc := admin.InstanceConfig{}
+	s.Equal(admin.RetentionPolicy{MaxAgeDays: 30}, vc.Retention())
+
+	vc.RetentionConfig = &admin.RetentionPolicy{KeepLast: 10, PreviewMaxAgeDays: 7}
+	s.Equal(admin.RetentionPolicy{KeepLast: 10, MaxAgeDays: 30, PreviewMaxAgeDays: 7}, vc.Retention())
+
+	s.Equal(admin.ErrInvalidRetentionPolicy, (&admin.RetentionPolicy{KeepLast: -1}).Validate())
+}
+
+func (s *AdminModelSuite) Test_BuildQueue() {
+	vc := admin.InstanceConfig{}
+	s.Equal(admin.BuildQueueConfig{MaxConcurrency: admin.DefaultBuildQueueConcurrency}, vc.BuildQueue())
+
+	vc.BuildQueueConfig = &admin.BuildQueueConfig{MaxConcurrency: 4, MaxConcurrencyPerTeam: 2}
+	s.Equal(admin.BuildQueueConfig{MaxConcurrency: 4, MaxConcurrencyPerTeam: 2}, vc.BuildQueue())
+
+	s.Equal(admin.ErrInvalidBuildQueueConfig, (&admin.BuildQueueConfig{MaxConcurrencyPerTeam: -1}).Validate())
+}
+
+func (s *AdminModelSuite) Test_KV() {
+	vc := admin.InstanceConfig{}
+	s.Equal(admin.KVConfig{
+		MaxKeys:      admin.DefaultKVMaxKeys,
+		MaxValueSize: admin.DefaultKVMaxValueSize,
+		MaxTotalSize: admin.DefaultKVMaxTotalSize,
+	}, vc.KV())
+
+	vc.KVConfig = &admin.KVConfig{MaxKeys: 50, RedisCache: true}
+	s.Equal(admin.KVConfig{
+		MaxKeys:      50,
+		MaxValueSize: admin.DefaultKVMaxValueSize,
+		MaxTotalSize: admin.DefaultKVMaxTotalSize,
+		RedisCache:   true,
+	}, vc.KV())
+
+	s.Equal(admin.ErrInvalidKVConfig, (&admin.KVConfig{MaxValueSize: -1}).Validate())
+}
+
 func (s *AdminModelSuite) Test_IsUserWhitelisted() {
 	// Sign up mode waitlist
 	vc := admin.InstanceConfig{
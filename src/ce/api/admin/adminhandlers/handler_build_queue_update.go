@@ -0,0 +1,48 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type BuildQueueUpdateRequest struct {
+	BuildQueue admin.BuildQueueConfig `json:"buildQueue"`
+}
+
+// handlerBuildQueueUpdate sets the number of deployments that are built
+// in parallel on the instance and per team.
+func handlerBuildQueueUpdate(req *user.RequestContext) *shttp.Response {
+	data := BuildQueueUpdateRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := data.BuildQueue.Validate(); err != nil {
+		return shttp.BadRequest(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	vc.BuildQueueConfig = &data.BuildQueue
+
+	if err := admin.Store().UpsertConfig(req.Context(), vc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"buildQueue": vc.BuildQueue(),
+		},
+	}
+}
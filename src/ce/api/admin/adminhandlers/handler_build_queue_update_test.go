@@ -0,0 +1,102 @@
+package adminhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin/adminhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerBuildQueueUpdateSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerBuildQueueUpdateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerBuildQueueUpdateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerBuildQueueUpdateSuite) Test_Update_Success() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/build-queue",
+		map[string]any{
+			"buildQueue": map[string]any{
+				"maxConcurrency":        4,
+				"maxConcurrencyPerTeam": 2,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(`{ "buildQueue": { "maxConcurrency": 4, "maxConcurrencyPerTeam": 2 } }`, resp.String())
+
+	vc, err := admin.Store().Config(context.Background())
+	s.NoError(err)
+	s.Equal(4, vc.BuildQueueConfig.MaxConcurrency)
+	s.Equal(2, vc.BuildQueueConfig.MaxConcurrencyPerTeam)
+}
+
+func (s *HandlerBuildQueueUpdateSuite) Test_Update_Invalid() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/build-queue",
+		map[string]any{
+			"buildQueue": map[string]any{
+				"maxConcurrencyPerTeam": -1,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, resp.Code)
+	s.JSONEq(`{ "error": "Build queue limits cannot be negative." }`, resp.String())
+}
+
+func (s *HandlerBuildQueueUpdateSuite) Test_Update_Unauthorized_NonAdmin() {
+	usr := s.MockUser(map[string]any{"IsAdmin": false})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/build-queue",
+		map[string]any{
+			"buildQueue": map[string]any{"maxConcurrency": 4},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerBuildQueueUpdateSuite(t *testing.T) {
+	suite.Run(t, &HandlerBuildQueueUpdateSuite{})
+}
@@ -0,0 +1,39 @@
+package adminhandlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerJobsRemoveOldArtifactsDryRun lists the deployments whose artifacts
+// would be removed by the retention policies, without removing them.
+func handlerJobsRemoveOldArtifactsDryRun(req *user.RequestContext) *shttp.Response {
+	limit := utils.StringToInt(req.Query().Get("limit"))
+
+	if limit <= 0 || limit > 1000 {
+		limit = 100
+	}
+
+	ctx := context.WithValue(req.Context(), jobs.KeyContextNumberOfDeploymentsToDelete{}, limit)
+	ids, err := jobs.RemoveDeploymentArtifactsManually(ctx, true)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if ids == nil {
+		ids = []string{}
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"deployments": ids,
+		},
+	}
+}
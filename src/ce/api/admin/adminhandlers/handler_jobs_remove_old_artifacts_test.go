@@ -1,6 +1,7 @@
 package adminhandlers_test
 
 import (
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -10,6 +11,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -46,6 +48,29 @@ func (s *HandlerJobsRemoveOldArtifactsSuite) Test_Success() {
 	s.JSONEq(`{"deleted": null}`, response.String()) // since we got no data
 }
 
+func (s *HandlerJobsRemoveOldArtifactsSuite) Test_DryRun() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+	env := s.MockEnv(nil)
+
+	T45daysAgo := utils.NewUnix()
+	T45daysAgo.Time = T45daysAgo.AddDate(0, 0, -45)
+
+	depl := s.MockDeployment(env, map[string]any{"CreatedAt": T45daysAgo})
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		"/admin/jobs/remove-old-artifacts",
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.JSONEq(fmt.Sprintf(`{"deployments": ["%s"]}`, depl.ID.String()), response.String())
+}
+
 func (s *HandlerJobsRemoveOldArtifactsSuite) Test_NonAdmin() {
 	usr := s.MockUser(map[string]any{"IsAdmin": false})
 
@@ -0,0 +1,24 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+func handlerKV(req *user.RequestContext) *shttp.Response {
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"kv": vc.KV(),
+		},
+	}
+}
@@ -0,0 +1,47 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type KVUpdateRequest struct {
+	KV admin.KVConfig `json:"kv"`
+}
+
+// handlerKVUpdate sets the quotas of the key-value store of each environment.
+func handlerKVUpdate(req *user.RequestContext) *shttp.Response {
+	data := KVUpdateRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := data.KV.Validate(); err != nil {
+		return shttp.BadRequest(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	vc.KVConfig = &data.KV
+
+	if err := admin.Store().UpsertConfig(req.Context(), vc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"kv": vc.KV(),
+		},
+	}
+}
@@ -0,0 +1,103 @@
+package adminhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin/adminhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerKVUpdateSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerKVUpdateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerKVUpdateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerKVUpdateSuite) Test_Update_Success() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/kv",
+		map[string]any{
+			"kv": map[string]any{
+				"maxKeys":    50,
+				"redisCache": true,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(`{ "kv": { "maxKeys": 50, "maxValueSize": 65536, "maxTotalSize": 10485760, "redisCache": true } }`, resp.String())
+
+	vc, err := admin.Store().Config(context.Background())
+	s.NoError(err)
+	s.Equal(50, vc.KVConfig.MaxKeys)
+	s.Equal(0, vc.KVConfig.MaxValueSize)
+	s.True(vc.KVConfig.RedisCache)
+}
+
+func (s *HandlerKVUpdateSuite) Test_Update_Invalid() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/kv",
+		map[string]any{
+			"kv": map[string]any{
+				"maxTotalSize": -1,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, resp.Code)
+	s.JSONEq(`{ "error": "Key-value store quotas cannot be negative." }`, resp.String())
+}
+
+func (s *HandlerKVUpdateSuite) Test_Update_Unauthorized_NonAdmin() {
+	usr := s.MockUser(map[string]any{"IsAdmin": false})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/kv",
+		map[string]any{
+			"kv": map[string]any{"maxKeys": 4},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerKVUpdateSuite(t *testing.T) {
+	suite.Run(t, &HandlerKVUpdateSuite{})
+}
@@ -0,0 +1,48 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type RetentionUpdateRequest struct {
+	Retention admin.RetentionPolicy `json:"retention"`
+}
+
+// handlerRetentionUpdate sets the default retention policy, which is used for
+// environments that do not configure their own retention policy.
+func handlerRetentionUpdate(req *user.RequestContext) *shttp.Response {
+	data := RetentionUpdateRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := data.Retention.Validate(); err != nil {
+		return shttp.BadRequest(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	vc.RetentionConfig = &data.Retention
+
+	if err := admin.Store().UpsertConfig(req.Context(), vc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"retention": vc.Retention(),
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package adminhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin/adminhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerRetentionUpdateSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerRetentionUpdateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerRetentionUpdateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerRetentionUpdateSuite) Test_Update_Success() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/retention",
+		map[string]any{
+			"retention": map[string]any{
+				"keepLast":          10,
+				"previewMaxAgeDays": 7,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(`{ "retention": { "keepLast": 10, "maxAgeDays": 30, "previewMaxAgeDays": 7 } }`, resp.String())
+
+	vc, err := admin.Store().Config(context.Background())
+	s.NoError(err)
+	s.Equal(10, vc.RetentionConfig.KeepLast)
+	s.Equal(7, vc.RetentionConfig.PreviewMaxAgeDays)
+}
+
+func (s *HandlerRetentionUpdateSuite) Test_Update_Invalid() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/retention",
+		map[string]any{
+			"retention": map[string]any{
+				"keepLast": -1,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, resp.Code)
+	s.JSONEq(`{ "error": "Retention policy values cannot be negative." }`, resp.String())
+}
+
+func (s *HandlerRetentionUpdateSuite) Test_Update_Unauthorized_NonAdmin() {
+	usr := s.MockUser(map[string]any{"IsAdmin": false})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/retention",
+		map[string]any{
+			"retention": map[string]any{"keepLast": 10},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerRetentionUpdateSuite(t *testing.T) {
+	suite.Run(t, &HandlerRetentionUpdateSuite{})
+}
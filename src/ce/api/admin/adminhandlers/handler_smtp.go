@@ -0,0 +1,35 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// publicSMTPConfig returns the smtp configuration without the password.
+func publicSMTPConfig(vc *admin.InstanceConfig) *admin.SMTPConfig {
+	if vc.SMTPConfig == nil {
+		return nil
+	}
+
+	cnf := *vc.SMTPConfig
+	cnf.Password = ""
+	return &cnf
+}
+
+func handlerSMTP(req *user.RequestContext) *shttp.Response {
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"smtp": publicSMTPConfig(&vc),
+		},
+	}
+}
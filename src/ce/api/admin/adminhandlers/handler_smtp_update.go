@@ -0,0 +1,55 @@
+package adminhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type SMTPUpdateRequest struct {
+	SMTP *admin.SMTPConfig `json:"smtp"`
+}
+
+// handlerSMTPUpdate sets the mail server used for platform emails. The
+// current password is kept when it is omitted. Sending a null config
+// disables platform emails.
+func handlerSMTPUpdate(req *user.RequestContext) *shttp.Response {
+	data := SMTPUpdateRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if data.SMTP != nil {
+		if err := data.SMTP.Validate(); err != nil {
+			return shttp.BadRequest(map[string]any{
+				"error": err.Error(),
+			})
+		}
+	}
+
+	vc, err := admin.Store().Config(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if data.SMTP != nil && data.SMTP.Password == "" && vc.SMTPConfig != nil {
+		data.SMTP.Password = vc.SMTPConfig.Password
+	}
+
+	vc.SMTPConfig = data.SMTP
+
+	if err := admin.Store().UpsertConfig(req.Context(), vc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"smtp": publicSMTPConfig(&vc),
+		},
+	}
+}
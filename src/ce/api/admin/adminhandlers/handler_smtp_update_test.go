@@ -0,0 +1,100 @@
+package adminhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin/adminhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerSMTPUpdateSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerSMTPUpdateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerSMTPUpdateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerSMTPUpdateSuite) update(usr *factory.MockUser, smtp map[string]any) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(adminhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/admin/system/smtp",
+		map[string]any{
+			"smtp": smtp,
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+}
+
+func (s *HandlerSMTPUpdateSuite) Test_Update_Success() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := s.update(usr, map[string]any{
+		"host":     "smtp.example.org",
+		"username": "notifications@example.org",
+		"password": "secret",
+		"from":     "Stormkit <notifications@example.org>",
+	})
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(`{
+		"smtp": {
+			"host": "smtp.example.org",
+			"username": "notifications@example.org",
+			"from": "Stormkit <notifications@example.org>"
+		}
+	}`, resp.String())
+
+	// The password is kept when it is omitted
+	resp = s.update(usr, map[string]any{
+		"host":     "smtp.example.org",
+		"port":     "465",
+		"username": "notifications@example.org",
+	})
+
+	s.Equal(http.StatusOK, resp.Code)
+
+	vc, err := admin.Store().Config(context.Background())
+	s.NoError(err)
+	s.Equal("secret", vc.SMTPConfig.Password)
+	s.Equal("465", vc.SMTPConfig.Port)
+	s.True(vc.IsSMTPEnabled())
+}
+
+func (s *HandlerSMTPUpdateSuite) Test_Update_Invalid() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+	resp := s.update(usr, map[string]any{"host": "smtp.example.org"})
+
+	s.Equal(http.StatusBadRequest, resp.Code)
+	s.JSONEq(`{ "error": "SMTP host and username are required." }`, resp.String())
+}
+
+func (s *HandlerSMTPUpdateSuite) Test_Update_Unauthorized_NonAdmin() {
+	usr := s.MockUser(map[string]any{"IsAdmin": false})
+	resp := s.update(usr, map[string]any{"host": "smtp.example.org", "username": "joe"})
+
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerSMTPUpdateSuite(t *testing.T) {
+	suite.Run(t, &HandlerSMTPUpdateSuite{})
+}
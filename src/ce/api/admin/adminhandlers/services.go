@@ -12,6 +12,7 @@ func Services(r *shttp.Router) *shttp.Service {
 
 	s.NewEndpoint("/admin/jobs").
 		Handler(shttp.MethodPost, "/sync-analytics", user.WithAdmin(handlerJobsSyncAnalytics)).
+		Handler(shttp.MethodGet, "/remove-old-artifacts", user.WithAdmin(handlerJobsRemoveOldArtifactsDryRun)).
 		Handler(shttp.MethodPost, "/remove-old-artifacts", user.WithAdmin(handlerJobsRemoveOldArtifacts))
 
 	s.NewEndpoint("/admin/system").
@@ -20,7 +21,15 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodGet, "/mise", user.WithAdmin(handlerMise)).
 		Handler(shttp.MethodPost, "/mise", user.WithAdmin(handlerMiseUpdate)).
 		Handler(shttp.MethodGet, "/proxies", user.WithAdmin(handlerProxies)).
-		Handler(shttp.MethodPut, "/proxies", user.WithAdmin(handlerProxiesUpdate))
+		Handler(shttp.MethodPut, "/proxies", user.WithAdmin(handlerProxiesUpdate)).
+		Handler(shttp.MethodGet, "/retention", user.WithAdmin(handlerRetention)).
+		Handler(shttp.MethodPut, "/retention", user.WithAdmin(handlerRetentionUpdate)).
+		Handler(shttp.MethodGet, "/build-queue", user.WithAdmin(handlerBuildQueue)).
+		Handler(shttp.MethodPut, "/build-queue", user.WithAdmin(handlerBuildQueueUpdate)).
+		Handler(shttp.MethodGet, "/smtp", user.WithAdmin(handlerSMTP)).
+		Handler(shttp.MethodPut, "/smtp", user.WithAdmin(handlerSMTPUpdate)).
+		Handler(shttp.MethodGet, "/kv", user.WithAdmin(handlerKV)).
+		Handler(shttp.MethodPut, "/kv", user.WithAdmin(handlerKVUpdate))
 
 	s.NewEndpoint("/admin/license").
 		Handler(shttp.MethodPost, "", user.WithAdmin(handlerLicenseSet))
@@ -23,9 +23,14 @@ func (s *ServicesSuite) Test_Services_SelfHosted() {
 		"GET:/admin/domains",
 		"GET:/admin/git/details",
 		"GET:/admin/git/github/callback",
+		"GET:/admin/jobs/remove-old-artifacts",
+		"GET:/admin/system/build-queue",
+		"GET:/admin/system/kv",
 		"GET:/admin/system/mise",
 		"GET:/admin/system/proxies",
+		"GET:/admin/system/retention",
 		"GET:/admin/system/runtimes",
+		"GET:/admin/system/smtp",
 		"GET:/admin/users/pending",
 		"GET:/admin/users/sign-up-mode",
 		"POST:/admin/domains",
@@ -38,7 +43,11 @@ func (s *ServicesSuite) Test_Services_SelfHosted() {
 		"POST:/admin/system/runtimes",
 		"POST:/admin/users/manage",
 		"POST:/admin/users/sign-up-mode",
+		"PUT:/admin/system/build-queue",
+		"PUT:/admin/system/kv",
 		"PUT:/admin/system/proxies",
+		"PUT:/admin/system/retention",
+		"PUT:/admin/system/smtp",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
@@ -56,9 +65,14 @@ func (s *ServicesSuite) Test_Services_Cloud() {
 		"GET:/admin/domains",
 		"GET:/admin/git/details",
 		"GET:/admin/git/github/callback",
+		"GET:/admin/jobs/remove-old-artifacts",
+		"GET:/admin/system/build-queue",
+		"GET:/admin/system/kv",
 		"GET:/admin/system/mise",
 		"GET:/admin/system/proxies",
+		"GET:/admin/system/retention",
 		"GET:/admin/system/runtimes",
+		"GET:/admin/system/smtp",
 		"GET:/admin/users/pending",
 		"GET:/admin/users/sign-up-mode",
 		"POST:/admin/cloud/impersonate",
@@ -73,7 +87,11 @@ func (s *ServicesSuite) Test_Services_Cloud() {
 		"POST:/admin/system/runtimes",
 		"POST:/admin/users/manage",
 		"POST:/admin/users/sign-up-mode",
+		"PUT:/admin/system/build-queue",
+		"PUT:/admin/system/kv",
 		"PUT:/admin/system/proxies",
+		"PUT:/admin/system/retention",
+		"PUT:/admin/system/smtp",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
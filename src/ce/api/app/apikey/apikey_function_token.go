@@ -0,0 +1,65 @@
+package apikey
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/config"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// FunctionTokenPrefix is the prefix of the tokens that functions receive
+// in their invocation context.
+const FunctionTokenPrefix = "SKF_"
+
+// FunctionTokenTTL is how long a token passed to a function remains valid.
+const FunctionTokenTTL = time.Hour
+
+// FunctionToken returns a token that gives access to the given service of the
+// environment until the expiry date. Unlike API keys, function tokens are not
+// stored: they are passed to functions in their invocation context.
+func FunctionToken(service string, envID types.ID, expiresAt time.Time) string {
+	exp := strconv.FormatInt(expiresAt.Unix(), 10)
+	sig := functionTokenSignature(service, envID.String(), exp)
+	return fmt.Sprintf("%s%s_%s_%s_%s", FunctionTokenPrefix, service, envID.String(), exp, sig)
+}
+
+// EnvIDFromFunctionToken returns the environment ID of the token, or 0 when the
+// token is not valid, expired or was issued for another service.
+func EnvIDFromFunctionToken(service, token string, now time.Time) types.ID {
+	if !strings.HasPrefix(token, FunctionTokenPrefix) {
+		return 0
+	}
+
+	pieces := strings.Split(strings.TrimPrefix(token, FunctionTokenPrefix), "_")
+
+	if len(pieces) != 4 || pieces[0] != service {
+		return 0
+	}
+
+	expected := functionTokenSignature(service, pieces[1], pieces[2])
+
+	if !hmac.Equal([]byte(expected), []byte(pieces[3])) {
+		return 0
+	}
+
+	exp, err := strconv.ParseInt(pieces[2], 10, 64)
+
+	if err != nil || now.Unix() > exp {
+		return 0
+	}
+
+	return utils.StringToID(pieces[1])
+}
+
+func functionTokenSignature(service, envID, exp string) string {
+	mac := hmac.New(sha256.New, []byte(config.Get().AppSecret))
+	mac.Write([]byte("function-token:" + service + ":" + envID + ":" + exp))
+	return hex.EncodeToString(mac.Sum(nil))
+}
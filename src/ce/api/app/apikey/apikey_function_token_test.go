@@ -0,0 +1,40 @@
+package apikey_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type FunctionTokenSuite struct {
+	suite.Suite
+}
+
+func (s *FunctionTokenSuite) Test_FunctionToken() {
+	now := time.Now()
+	token := apikey.FunctionToken("jobs", types.ID(15), now.Add(time.Hour))
+
+	s.True(strings.HasPrefix(token, "SKF_jobs_15_"))
+	s.Equal(types.ID(15), apikey.EnvIDFromFunctionToken("jobs", token, now))
+
+	// Expired
+	s.Equal(types.ID(0), apikey.EnvIDFromFunctionToken("jobs", token, now.Add(2*time.Hour)))
+
+	// Issued for another service
+	s.Equal(types.ID(0), apikey.EnvIDFromFunctionToken("other", token, now))
+
+	// Tampered environment
+	tampered := strings.Replace(token, "SKF_jobs_15_", "SKF_jobs_16_", 1)
+	s.Equal(types.ID(0), apikey.EnvIDFromFunctionToken("jobs", tampered, now))
+
+	// Not a function token
+	s.Equal(types.ID(0), apikey.EnvIDFromFunctionToken("jobs", "SK_my-api-key", now))
+}
+
+func TestFunctionToken(t *testing.T) {
+	suite.Run(t, &FunctionTokenSuite{})
+}
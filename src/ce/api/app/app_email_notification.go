@@ -0,0 +1,180 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/mail"
+	"path"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/html"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// EmailNotificationMaxRetry is the number of times a failed email is retried.
+const EmailNotificationMaxRetry = 3
+
+// EmailNotificationMessage is the payload of the email notification task.
+type EmailNotificationMessage struct {
+	Event    string                  `json:"event"`
+	Settings OutboundWebhookSettings `json:"settings"`
+}
+
+// TriggerEmailNotifications enqueues an email notification to the team members
+// of the app, when the event is one that users can be emailed about and the
+// instance has an SMTP configuration.
+func TriggerEmailNotifications(ctx context.Context, event string, settings OutboundWebhookSettings) {
+	switch event {
+	case TriggerOnDeployFailed, TriggerOnDomainFailing, TriggerOnCertificateExpiring:
+	case TriggerOnPublish:
+		if !settings.EnvironmentProtected {
+			return
+		}
+	default:
+		return
+	}
+
+	if !admin.MustConfig().IsSMTPEnabled() {
+		return
+	}
+
+	_, err := tasks.Enqueue(ctx, tasks.EmailNotification, EmailNotificationMessage{
+		Event:    event,
+		Settings: settings,
+	}, &tasks.EnqueueOptions{
+		MaxRetry: EmailNotificationMaxRetry,
+	})
+
+	if err != nil {
+		slog.Errorf("error while enqueuing email notification for app %s: %v", settings.AppID.String(), err)
+	}
+}
+
+// EmailRecipients returns the email addresses of the team members that are
+// notified about the event. Failed deployments are only sent to their author.
+func EmailRecipients(event string, settings OutboundWebhookSettings, members []team.Member, prefs map[types.ID]user.NotificationPreferences) []string {
+	to := []string{}
+
+	for _, m := range members {
+		if !m.Status || m.Email == "" {
+			continue
+		}
+
+		p, ok := prefs[m.UserID]
+
+		if !ok {
+			p = user.DefaultNotificationPreferences()
+		}
+
+		var enabled bool
+
+		switch event {
+		case TriggerOnDeployFailed:
+			enabled = p.DeployFailed && isDeploymentAuthor(m, settings.DeploymentAuthor)
+		case TriggerOnPublish:
+			enabled = p.ProtectedPublish
+		case TriggerOnDomainFailing:
+			enabled = p.DomainFailing
+		case TriggerOnCertificateExpiring:
+			enabled = p.CertificateExpiring
+		}
+
+		if enabled {
+			to = append(to, m.Email)
+		}
+	}
+
+	return to
+}
+
+// isDeploymentAuthor matches the commit author of the deployment, which is
+// either `Name <email>` for deployments built from a commit, or the
+// display name of the user for deployments started manually.
+func isDeploymentAuthor(m team.Member, author string) bool {
+	author = strings.TrimSpace(author)
+
+	if author == "" {
+		return false
+	}
+
+	if addr, err := mail.ParseAddress(author); err == nil {
+		return strings.EqualFold(addr.Address, m.Email)
+	}
+
+	if strings.EqualFold(author, m.Email) {
+		return true
+	}
+
+	name := strings.TrimSpace(m.FirstName.ValueOrZero() + " " + m.LastName.ValueOrZero())
+	return author == name || author == m.DisplayName
+}
+
+// NewNotificationEmail renders the email that is sent for the event.
+func NewNotificationEmail(event string, settings OutboundWebhookSettings, appName string) (*mailer.Email, error) {
+	cnf := admin.MustConfig()
+	appURL := cnf.AppURL(path.Join("apps", settings.AppID.String()))
+
+	var subject, tmpl string
+
+	data := map[string]any{
+		"app_name":        appName,
+		"env_name":        settings.EnvironmentName,
+		"deployment_id":   settings.DeploymentID.String(),
+		"branch":          settings.Branch,
+		"domain_name":     settings.DomainName,
+		"preferences_url": cnf.AppURL("user/account"),
+		"link_title":      "View deployment",
+		"link_url":        settings.DeploymentLogsEndpoint,
+	}
+
+	switch event {
+	case TriggerOnDeployFailed:
+		tmpl = "deploy_failed"
+		subject = fmt.Sprintf("[%s] Deployment #%s failed", appName, settings.DeploymentID.String())
+		data["error"] = settings.DeploymentError
+		data["link_title"] = "View logs"
+	case TriggerOnPublish:
+		tmpl = "protected_publish"
+		subject = fmt.Sprintf("[%s] Deployment #%s published to %s", appName, settings.DeploymentID.String(), settings.EnvironmentName)
+	case TriggerOnDomainFailing:
+		tmpl = "domain_failing"
+		subject = fmt.Sprintf("[%s] %s is not responding", appName, settings.DomainName)
+		data["error"] = settings.DomainError
+		data["link_title"] = "View app"
+		data["link_url"] = appURL
+	case TriggerOnCertificateExpiring:
+		tmpl = "certificate_expiring"
+		subject = fmt.Sprintf("[%s] Certificate of %s is expiring", appName, settings.DomainName)
+		data["expires_at"] = settings.CertificateExpiresAt.UTC().Format("January 2, 2006")
+		data["link_title"] = "View app"
+		data["link_url"] = appURL
+	default:
+		return nil, fmt.Errorf("no email template for event %s", event)
+	}
+
+	body, err := html.RenderEmail(html.RenderArgs{
+		PageContent: html.EmailTemplates[tmpl],
+		ContentData: data,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	email := &mailer.Email{
+		Subject: subject,
+		Body:    string(body),
+	}
+
+	if cnf.SMTPConfig != nil {
+		email.From = cnf.SMTPConfig.From
+	}
+
+	return email, nil
+}
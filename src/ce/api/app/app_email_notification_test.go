@@ -0,0 +1,52 @@
+package app_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+	null "gopkg.in/guregu/null.v3"
+)
+
+type EmailNotificationSuite struct {
+	suite.Suite
+	members []team.Member
+}
+
+func (s *EmailNotificationSuite) BeforeTest(_, _ string) {
+	s.members = []team.Member{
+		{UserID: 1, Email: "jane@example.org", FirstName: null.StringFrom("Jane"), LastName: null.StringFrom("Doe"), Status: true},
+		{UserID: 2, Email: "joe@example.org", DisplayName: "joe", Status: true},
+		{UserID: 3, Email: "invited@example.org", Status: false},
+	}
+}
+
+func (s *EmailNotificationSuite) Test_DeployFailed_Author() {
+	recipients := func(author string) []string {
+		return app.EmailRecipients(app.TriggerOnDeployFailed, app.OutboundWebhookSettings{DeploymentAuthor: author}, s.members, nil)
+	}
+
+	s.Equal([]string{"jane@example.org"}, recipients("Jane D. <Jane@Example.org>"))
+	s.Equal([]string{"jane@example.org"}, recipients("Jane Doe"))
+	s.Equal([]string{"joe@example.org"}, recipients("joe"))
+	s.Equal([]string{}, recipients("invited@example.org"))
+	s.Equal([]string{}, recipients(""))
+}
+
+func (s *EmailNotificationSuite) Test_Preferences() {
+	prefs := map[types.ID]user.NotificationPreferences{
+		1: {DomainFailing: false, CertificateExpiring: true},
+	}
+
+	s.Equal([]string{"joe@example.org"}, app.EmailRecipients(app.TriggerOnDomainFailing, app.OutboundWebhookSettings{}, s.members, prefs))
+	s.Equal([]string{"jane@example.org", "joe@example.org"}, app.EmailRecipients(app.TriggerOnCertificateExpiring, app.OutboundWebhookSettings{}, s.members, prefs))
+	s.Equal([]string{"joe@example.org"}, app.EmailRecipients(app.TriggerOnPublish, app.OutboundWebhookSettings{}, s.members, prefs))
+	s.Equal([]string{}, app.EmailRecipients(app.TriggerOnDeploySuccess, app.OutboundWebhookSettings{}, s.members, prefs))
+}
+
+func TestEmailNotification(t *testing.T) {
+	suite.Run(t, &EmailNotificationSuite{})
+}
@@ -0,0 +1,391 @@
+package app
+
+import (
+	"fmt"
+	"html"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/discord"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const NotificationStatusSuccess = "success"
+const NotificationStatusFailure = "failure"
+const NotificationStatusWarning = "warning"
+const NotificationStatusInfo = "info"
+
+// NotificationMessage is the provider agnostic content of a notification.
+// Each provider formats it with its own message format.
+type NotificationMessage struct {
+	ID        string              `json:"id"` // ID is unique per event, and stays the same across retries.
+	Event     string              `json:"event"`
+	Title     string              `json:"title"`
+	Text      string              `json:"text,omitempty"`
+	Status    string              `json:"status"` // success | failure | warning | info
+	Fields    []NotificationField `json:"fields,omitempty"`
+	Links     []NotificationLink  `json:"links,omitempty"`
+	CreatedAt int64               `json:"createdAt"`
+}
+
+type NotificationField struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+type NotificationLink struct {
+	Title string `json:"title"`
+	URL   string `json:"url"`
+}
+
+// NewNotificationMessage returns the message that describes the event.
+func NewNotificationMessage(event string, s OutboundWebhookSettings) NotificationMessage {
+	msg := NotificationMessage{
+		ID:        "evt_" + strings.ToLower(utils.RandomToken(24)),
+		Event:     event,
+		Status:    NotificationStatusInfo,
+		CreatedAt: time.Now().Unix(),
+	}
+
+	switch event {
+	case TriggerOnDeployStart:
+		msg.Title = "Deployment started"
+	case TriggerOnDeploySuccess:
+		msg.Title, msg.Status = "Deployment succeeded", NotificationStatusSuccess
+	case TriggerOnDeployFailed:
+		msg.Title, msg.Status, msg.Text = "Deployment failed", NotificationStatusFailure, s.DeploymentError
+	case TriggerOnPublish:
+		msg.Title, msg.Status = "Deployment published", NotificationStatusSuccess
+	case TriggerOnCachePurge:
+		msg.Title = "Cache purged"
+	case TriggerOnRolloutStep:
+		msg.Title = fmt.Sprintf("Rollout is %s", strings.ReplaceAll(s.RolloutStatus, "_", " "))
+		msg.Text = s.RolloutReason
+	case TriggerOnRollback:
+		msg.Title, msg.Status, msg.Text = "Deployment rolled back", NotificationStatusWarning, s.RolloutReason
+	case TriggerOnApprovalRequest:
+		msg.Title = "Publish approval requested"
+	case TriggerOnApprovalDecision:
+		msg.Title = fmt.Sprintf("Publish approval %s", s.ApprovalStatus)
+		msg.Status = NotificationStatusSuccess
+
+		if s.ApprovalStatus != "approved" {
+			msg.Status = NotificationStatusFailure
+		}
+	case TriggerOnStatusChecks:
+		msg.Title = fmt.Sprintf("Status checks %s", s.StatusChecksStatus)
+		msg.Status = NotificationStatusSuccess
+
+		if s.StatusChecksStatus != "passed" {
+			msg.Status = NotificationStatusFailure
+		}
+	case TriggerOnDomainVerified:
+		msg.Title, msg.Status = "Domain verified", NotificationStatusSuccess
+	case TriggerOnDomainFailing:
+		msg.Title, msg.Status, msg.Text = "Domain is failing", NotificationStatusFailure, s.DomainError
+	case TriggerOnCertificateIssued:
+		msg.Title, msg.Status = "Certificate issued", NotificationStatusSuccess
+	case TriggerOnCertificateExpiring:
+		msg.Title, msg.Status = "Certificate is expiring", NotificationStatusWarning
+
+		if s.CertificateExpiresAt.Valid {
+			msg.Text = fmt.Sprintf("The certificate expires on %s.", s.CertificateExpiresAt.Format(time.RFC1123))
+		}
+	case TriggerOnFunctionTriggerFailed:
+		msg.Title, msg.Status, msg.Text = "Function trigger failed", NotificationStatusFailure, s.FunctionTriggerError
+	case TriggerOnEnvVarsChanged:
+		msg.Title = "Environment variables changed"
+		msg.Text = fmt.Sprintf("Changed variables: %s", strings.Join(s.ChangedVars, ", "))
+	default:
+		msg.Title = event
+	}
+
+	id := func(v types.ID) string {
+		if v == 0 {
+			return ""
+		}
+
+		return v.String()
+	}
+
+	var rollout string
+
+	if s.RolloutPercentage != "" {
+		rollout = s.RolloutPercentage + "%"
+	}
+
+	fields := []NotificationField{
+		{Name: "App", Value: id(s.AppID)},
+		{Name: "Environment", Value: s.EnvironmentName},
+		{Name: "Deployment", Value: id(s.DeploymentID)},
+		{Name: "Branch", Value: s.Branch},
+		{Name: "Rollout", Value: rollout},
+		{Name: "Domain", Value: s.DomainName},
+		{Name: "Function trigger", Value: id(s.FunctionTriggerID)},
+	}
+
+	for _, field := range fields {
+		if field.Value != "" {
+			msg.Fields = append(msg.Fields, field)
+		}
+	}
+
+	if s.DeploymentLogsEndpoint != "" {
+		msg.Links = append(msg.Links, NotificationLink{Title: "View logs", URL: s.DeploymentLogsEndpoint})
+	}
+
+	if s.DeploymentEndpoint != "" {
+		msg.Links = append(msg.Links, NotificationLink{Title: "Preview", URL: s.DeploymentEndpoint})
+	}
+
+	return msg
+}
+
+// statusColor returns the RGB color of the message status.
+func (msg NotificationMessage) statusColor() int {
+	switch msg.Status {
+	case NotificationStatusSuccess:
+		return 0x2EB67D
+	case NotificationStatusFailure:
+		return 0xE01E5A
+	case NotificationStatusWarning:
+		return 0xECB22E
+	default:
+		return 0x36C5F0
+	}
+}
+
+// Send delivers the message to the channel. An error is returned when the
+// provider does not accept the message, so that the delivery is retried.
+func (nc *NotificationChannel) Send(msg NotificationMessage) error {
+	method, endpoint, headers := shttp.MethodPost, nc.Config.WebhookURL, map[string]string{
+		"Content-Type": "application/json",
+	}
+
+	var payload any
+
+	switch nc.Provider {
+	case NotificationProviderSlack:
+		payload = SlackPayload(msg)
+	case NotificationProviderTeams:
+		payload = TeamsPayload(msg)
+	case NotificationProviderDiscord:
+		payload = DiscordPayload(msg)
+	case NotificationProviderMatrix:
+		// The transaction id makes retries idempotent.
+		method = shttp.MethodPut
+		endpoint = fmt.Sprintf(
+			"%s/_matrix/client/v3/rooms/%s/send/m.room.message/%s",
+			strings.TrimSuffix(nc.Config.HomeserverURL, "/"),
+			url.PathEscape(nc.Config.RoomID),
+			url.PathEscape(msg.ID),
+		)
+		headers["Authorization"] = "Bearer " + nc.Config.AccessToken
+		payload = MatrixPayload(msg)
+	default:
+		return fmt.Errorf("unknown notification provider: %s", nc.Provider)
+	}
+
+	res, err := shttp.NewRequestV2(method, endpoint).
+		Headers(shttp.HeadersFromMap(headers)).
+		Payload(payload).
+		Do()
+
+	if err != nil {
+		return err
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	if res.Body != nil {
+		defer res.Body.Close()
+	}
+
+	if res.StatusCode < 200 || res.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with status %d: %s", nc.Provider, res.StatusCode, truncate(res.String(), 256))
+	}
+
+	return nil
+}
+
+// SlackPayload formats the message with Slack blocks. The blocks are wrapped
+// in an attachment, which is the only way to display the status color.
+//
+// See https://api.slack.com/reference/block-kit/blocks
+func SlackPayload(msg NotificationMessage) map[string]any {
+	blocks := []map[string]any{
+		{
+			"type": "header",
+			"text": map[string]any{"type": "plain_text", "text": msg.Title},
+		},
+	}
+
+	if msg.Text != "" {
+		blocks = append(blocks, map[string]any{
+			"type": "section",
+			"text": map[string]any{"type": "mrkdwn", "text": msg.Text},
+		})
+	}
+
+	if len(msg.Fields) > 0 {
+		fields := []map[string]any{}
+
+		for _, field := range msg.Fields {
+			fields = append(fields, map[string]any{
+				"type": "mrkdwn",
+				"text": fmt.Sprintf("*%s*\n%s", field.Name, field.Value),
+			})
+		}
+
+		blocks = append(blocks, map[string]any{"type": "section", "fields": fields})
+	}
+
+	if len(msg.Links) > 0 {
+		elements := []map[string]any{}
+
+		for _, link := range msg.Links {
+			elements = append(elements, map[string]any{
+				"type": "button",
+				"text": map[string]any{"type": "plain_text", "text": link.Title},
+				"url":  link.URL,
+			})
+		}
+
+		blocks = append(blocks, map[string]any{"type": "actions", "elements": elements})
+	}
+
+	return map[string]any{
+		"text": msg.Title, // Used in push notifications
+		"attachments": []map[string]any{
+			{"color": fmt.Sprintf("#%06X", msg.statusColor()), "blocks": blocks},
+		},
+	}
+}
+
+// TeamsPayload formats the message as an adaptive card.
+//
+// See https://learn.microsoft.com/en-us/microsoftteams/platform/task-modules-and-cards/cards/cards-reference#adaptive-card
+func TeamsPayload(msg NotificationMessage) map[string]any {
+	color := map[string]string{
+		NotificationStatusSuccess: "Good",
+		NotificationStatusFailure: "Attention",
+		NotificationStatusWarning: "Warning",
+		NotificationStatusInfo:    "Accent",
+	}[msg.Status]
+
+	body := []map[string]any{
+		{
+			"type":   "TextBlock",
+			"text":   msg.Title,
+			"size":   "Medium",
+			"weight": "Bolder",
+			"color":  color,
+			"wrap":   true,
+		},
+	}
+
+	if msg.Text != "" {
+		body = append(body, map[string]any{"type": "TextBlock", "text": msg.Text, "wrap": true})
+	}
+
+	if len(msg.Fields) > 0 {
+		facts := []map[string]any{}
+
+		for _, field := range msg.Fields {
+			facts = append(facts, map[string]any{"title": field.Name, "value": field.Value})
+		}
+
+		body = append(body, map[string]any{"type": "FactSet", "facts": facts})
+	}
+
+	actions := []map[string]any{}
+
+	for _, link := range msg.Links {
+		actions = append(actions, map[string]any{"type": "Action.OpenUrl", "title": link.Title, "url": link.URL})
+	}
+
+	return map[string]any{
+		"type": "message",
+		"attachments": []map[string]any{
+			{
+				"contentType": "application/vnd.microsoft.card.adaptive",
+				"content": map[string]any{
+					"$schema": "http://adaptivecards.io/schemas/adaptive-card.json",
+					"type":    "AdaptiveCard",
+					"version": "1.4",
+					"body":    body,
+					"actions": actions,
+				},
+			},
+		},
+	}
+}
+
+// DiscordPayload formats the message as a Discord embed.
+func DiscordPayload(msg NotificationMessage) discord.Payload {
+	description := []string{}
+
+	if msg.Text != "" {
+		description = append(description, msg.Text)
+	}
+
+	for _, link := range msg.Links {
+		description = append(description, fmt.Sprintf("[%s](%s)", link.Title, link.URL))
+	}
+
+	fields := []discord.PayloadField{}
+
+	for _, field := range msg.Fields {
+		fields = append(fields, discord.PayloadField{Name: field.Name, Value: field.Value, Inline: true})
+	}
+
+	embed := discord.PayloadEmbed{
+		Title:       msg.Title,
+		Description: strings.Join(description, "\n\n"),
+		Color:       msg.statusColor(),
+		Timestamp:   time.Unix(msg.CreatedAt, 0).UTC().Format(time.RFC3339),
+		Fields:      fields,
+	}
+
+	if len(msg.Links) > 0 {
+		embed.URL = msg.Links[0].URL
+	}
+
+	return discord.Payload{Embeds: []discord.PayloadEmbed{embed}}
+}
+
+// MatrixPayload formats the message as an m.notice event, which is the
+// message type that bots are expected to use.
+//
+// See https://spec.matrix.org/latest/client-server-api/#mnotice
+func MatrixPayload(msg NotificationMessage) map[string]any {
+	plain := []string{msg.Title}
+	formatted := []string{fmt.Sprintf("<strong>%s</strong>", html.EscapeString(msg.Title))}
+
+	if msg.Text != "" {
+		plain = append(plain, msg.Text)
+		formatted = append(formatted, html.EscapeString(msg.Text))
+	}
+
+	for _, field := range msg.Fields {
+		plain = append(plain, fmt.Sprintf("%s: %s", field.Name, field.Value))
+		formatted = append(formatted, fmt.Sprintf("<b>%s:</b> %s", html.EscapeString(field.Name), html.EscapeString(field.Value)))
+	}
+
+	for _, link := range msg.Links {
+		plain = append(plain, fmt.Sprintf("%s: %s", link.Title, link.URL))
+		formatted = append(formatted, fmt.Sprintf(`<a href="%s">%s</a>`, html.EscapeString(link.URL), html.EscapeString(link.Title)))
+	}
+
+	return map[string]any{
+		"msgtype":        "m.notice",
+		"body":           strings.Join(plain, "\n"),
+		"format":         "org.matrix.custom.html",
+		"formatted_body": strings.Join(formatted, "<br />"),
+	}
+}
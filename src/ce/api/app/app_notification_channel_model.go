@@ -0,0 +1,177 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const NotificationProviderSlack = "slack"
+const NotificationProviderTeams = "teams"
+const NotificationProviderDiscord = "discord"
+const NotificationProviderMatrix = "matrix"
+
+// NotificationProviders is the list of supported chat providers.
+var NotificationProviders = []string{
+	NotificationProviderSlack,
+	NotificationProviderTeams,
+	NotificationProviderDiscord,
+	NotificationProviderMatrix,
+}
+
+// NotificationChannelMaxRetry is the number of times a failed notification is retried.
+const NotificationChannelMaxRetry = 5
+
+// NotificationChannel sends formatted messages to a chat app when one of
+// its events is triggered. It receives the same events as outbound webhooks.
+type NotificationChannel struct {
+	ID        types.ID                  `json:"id,string"`
+	AppID     types.ID                  `json:"appId,string"`
+	Name      string                    `json:"name"`
+	Provider  string                    `json:"provider"`
+	Events    []string                  `json:"events"`
+	Config    NotificationChannelConfig `json:"config"`
+	CreatedAt utils.Unix                `json:"createdAt"`
+	UpdatedAt utils.Unix                `json:"updatedAt"`
+}
+
+// NotificationChannelConfig holds the credentials of the channel. It is
+// stored encrypted, since webhook URLs and access tokens grant write access
+// to the chat.
+type NotificationChannelConfig struct {
+	// WebhookURL is the incoming webhook URL for Slack, Teams and Discord.
+	WebhookURL string `json:"webhookUrl,omitempty"`
+
+	// HomeserverURL, RoomID and AccessToken are used by Matrix.
+	HomeserverURL string `json:"homeserverUrl,omitempty"`
+	RoomID        string `json:"roomId,omitempty"`
+	AccessToken   string `json:"accessToken,omitempty"`
+}
+
+// NotificationDeliveryMessage is the payload of the notification delivery task.
+type NotificationDeliveryMessage struct {
+	AppID     types.ID            `json:"appId,string"`
+	ChannelID types.ID            `json:"channelId,string"`
+	Message   NotificationMessage `json:"message"`
+}
+
+// Public returns the config without the secrets, so that it can be
+// returned in API responses.
+func (c NotificationChannelConfig) Public() NotificationChannelConfig {
+	if c.WebhookURL != "" {
+		if u, err := url.Parse(c.WebhookURL); err == nil && u.Host != "" {
+			c.WebhookURL = fmt.Sprintf("%s://%s/********", u.Scheme, u.Host)
+		} else {
+			c.WebhookURL = "********"
+		}
+	}
+
+	if c.AccessToken != "" {
+		c.AccessToken = "********"
+	}
+
+	return c
+}
+
+// Validate validates the channel. Secrets are only required when
+// requireSecrets is true, so that they can be omitted when a channel
+// is updated.
+func (nc *NotificationChannel) Validate(requireSecrets bool) *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if strings.TrimSpace(nc.Name) == "" {
+		err.SetError("name", "Name is a required field.")
+	}
+
+	if !utils.InSliceString(NotificationProviders, nc.Provider) {
+		err.SetError("provider", fmt.Sprintf("Invalid provider value. Accepted values are: %s", strings.Join(NotificationProviders, " | ")))
+	}
+
+	if len(nc.Events) == 0 {
+		err.SetError("events", "At least one event is required.")
+	}
+
+	for _, event := range nc.Events {
+		if !utils.InSliceString(OutboundWebhookEvents, event) {
+			err.SetError("events", fmt.Sprintf("Invalid event: %s. Accepted values are: %s", event, strings.Join(OutboundWebhookEvents, " | ")))
+		}
+	}
+
+	if nc.Provider == NotificationProviderMatrix {
+		if _, uerr := url.ParseRequestURI(nc.Config.HomeserverURL); uerr != nil {
+			err.SetError("homeserverUrl", "Homeserver URL is invalid.")
+		}
+
+		if !strings.HasPrefix(nc.Config.RoomID, "!") {
+			err.SetError("roomId", "Room ID must start with an exclamation mark, e.g. !abc:matrix.org.")
+		}
+
+		if requireSecrets && nc.Config.AccessToken == "" {
+			err.SetError("accessToken", "Access token is a required field.")
+		}
+	} else if requireSecrets || nc.Config.WebhookURL != "" {
+		if _, uerr := url.ParseRequestURI(nc.Config.WebhookURL); uerr != nil {
+			err.SetError("webhookUrl", "Webhook URL is invalid.")
+		}
+	}
+
+	return err.ToError()
+}
+
+// Subscribes returns true when the channel receives the given event.
+func (nc *NotificationChannel) Subscribes(event string) bool {
+	return utils.InSliceString(nc.Events, event)
+}
+
+// TriggerEvent notifies the outbound webhooks and the notification channels
+// of the app that are subscribed to the given event, and emails the team
+// members about the events they opted in.
+func TriggerEvent(ctx context.Context, event string, settings OutboundWebhookSettings) {
+	TriggerOutboundWebhooks(ctx, event, settings)
+	TriggerNotificationChannels(ctx, event, settings)
+	TriggerEmailNotifications(ctx, event, settings)
+}
+
+// TriggerNotificationChannels enqueues a notification for each channel of
+// the app that is subscribed to the given event.
+func TriggerNotificationChannels(ctx context.Context, event string, settings OutboundWebhookSettings) {
+	channels, err := NewStore().NotificationChannels(ctx, settings.AppID)
+
+	if err != nil {
+		slog.Errorf("error while fetching notification channels: %v", err)
+		return
+	}
+
+	var message *NotificationMessage
+
+	for _, channel := range channels {
+		if !channel.Subscribes(event) {
+			continue
+		}
+
+		// The message is the same for all channels, only the format differs.
+		if message == nil {
+			msg := NewNotificationMessage(event, settings)
+			message = &msg
+		}
+
+		_, err := tasks.Enqueue(ctx, tasks.NotificationDelivery, NotificationDeliveryMessage{
+			AppID:     settings.AppID,
+			ChannelID: channel.ID,
+			Message:   *message,
+		}, &tasks.EnqueueOptions{
+			MaxRetry: NotificationChannelMaxRetry,
+		})
+
+		if err != nil {
+			slog.Errorf("error while enqueuing notification for channel %s: %v", channel.ID.String(), err)
+		}
+	}
+}
@@ -0,0 +1,195 @@
+package app_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/testutils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationChannelModelSuite struct {
+	suite.Suite
+}
+
+func (s *NotificationChannelModelSuite) AfterTest(_, _ string) {
+	shttp.DefaultRequest = nil
+}
+
+func (s *NotificationChannelModelSuite) message() app.NotificationMessage {
+	return app.NewNotificationMessage(app.TriggerOnDeployFailed, app.OutboundWebhookSettings{
+		AppID:                  1,
+		DeploymentID:           15,
+		EnvironmentName:        "production",
+		Branch:                 "main",
+		DeploymentError:        "build failed",
+		DeploymentEndpoint:     "https://app--15.stormkit.dev",
+		DeploymentLogsEndpoint: "https://app.stormkit.io/apps/1/deployments/15",
+	})
+}
+
+func (s *NotificationChannelModelSuite) Test_Validate() {
+	nc := &app.NotificationChannel{
+		Name:     "Deployments",
+		Provider: app.NotificationProviderSlack,
+		Events:   []string{app.TriggerOnDeployFailed},
+		Config:   app.NotificationChannelConfig{WebhookURL: "https://hooks.slack.com/services/T0/B0/X"},
+	}
+
+	s.Nil(nc.Validate(true))
+
+	nc.Events = []string{"on_something"}
+	s.Contains(nc.Validate(true).Errors["events"], "Invalid event: on_something")
+
+	// Secrets can be omitted when updating
+	nc.Events = []string{app.TriggerOnDeployFailed}
+	nc.Config.WebhookURL = ""
+	s.Nil(nc.Validate(false))
+	s.NotNil(nc.Validate(true))
+
+	nc.Provider = app.NotificationProviderMatrix
+	nc.Config = app.NotificationChannelConfig{HomeserverURL: "https://matrix.org", RoomID: "abc"}
+	errs := nc.Validate(true).Errors
+	s.Contains(errs, "roomId")
+	s.Contains(errs, "accessToken")
+}
+
+func (s *NotificationChannelModelSuite) Test_Public() {
+	cnf := app.NotificationChannelConfig{
+		WebhookURL:  "https://hooks.slack.com/services/T0/B0/X",
+		AccessToken: "syt_secret",
+		RoomID:      "!abc:matrix.org",
+	}
+
+	public := cnf.Public()
+	s.Equal("https://hooks.slack.com/********", public.WebhookURL)
+	s.Equal("********", public.AccessToken)
+	s.Equal("!abc:matrix.org", public.RoomID)
+}
+
+func (s *NotificationChannelModelSuite) Test_NewNotificationMessage() {
+	msg := s.message()
+
+	s.Equal("Deployment failed", msg.Title)
+	s.Equal("build failed", msg.Text)
+	s.Equal(app.NotificationStatusFailure, msg.Status)
+	s.Equal([]app.NotificationField{
+		{Name: "App", Value: "1"},
+		{Name: "Environment", Value: "production"},
+		{Name: "Deployment", Value: "15"},
+		{Name: "Branch", Value: "main"},
+	}, msg.Fields)
+	s.Equal([]app.NotificationLink{
+		{Title: "View logs", URL: "https://app.stormkit.io/apps/1/deployments/15"},
+		{Title: "Preview", URL: "https://app--15.stormkit.dev"},
+	}, msg.Links)
+}
+
+func (s *NotificationChannelModelSuite) Test_Payloads() {
+	msg := s.message()
+
+	slack, err := json.Marshal(app.SlackPayload(msg))
+	s.NoError(err)
+	s.Contains(string(slack), `"color":"#E01E5A"`)
+	s.Contains(string(slack), `"type":"header"`)
+	s.Contains(string(slack), `"url":"https://app--15.stormkit.dev"`)
+
+	teams, err := json.Marshal(app.TeamsPayload(msg))
+	s.NoError(err)
+	s.Contains(string(teams), `"contentType":"application/vnd.microsoft.card.adaptive"`)
+	s.Contains(string(teams), `"type":"Action.OpenUrl"`)
+	s.Contains(string(teams), `"color":"Attention"`)
+
+	discord := app.DiscordPayload(msg)
+	s.Len(discord.Embeds, 1)
+	s.Equal(0xE01E5A, discord.Embeds[0].Color)
+	s.Contains(discord.Embeds[0].Description, "[View logs](https://app.stormkit.io/apps/1/deployments/15)")
+
+	matrix := app.MatrixPayload(msg)
+	s.Equal("m.notice", matrix["msgtype"])
+	s.Contains(matrix["body"], "Environment: production")
+	s.Contains(matrix["formatted_body"], `<a href="https://app--15.stormkit.dev">Preview</a>`)
+}
+
+func (s *NotificationChannelModelSuite) Test_Send_Slack() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusOK,
+		Method:   shttp.MethodPost,
+		DataText: "ok",
+		Expect: func(req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			s.Equal("application/json", req.Header.Get("Content-Type"))
+			s.Contains(string(body), `"text":"Deployment failed"`)
+		},
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	nc := &app.NotificationChannel{
+		Provider: app.NotificationProviderSlack,
+		Config:   app.NotificationChannelConfig{WebhookURL: ms.URL()},
+	}
+
+	s.NoError(nc.Send(s.message()))
+	s.Equal(1, mr.NumberOfCalls)
+}
+
+func (s *NotificationChannelModelSuite) Test_Send_Failed() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusNotFound,
+		Method:   shttp.MethodPost,
+		DataText: "no_team",
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	nc := &app.NotificationChannel{
+		Provider: app.NotificationProviderDiscord,
+		Config:   app.NotificationChannelConfig{WebhookURL: ms.URL()},
+	}
+
+	err := nc.Send(s.message())
+	s.Error(err)
+	s.Contains(err.Error(), "discord responded with status 404")
+}
+
+func (s *NotificationChannelModelSuite) Test_Send_Matrix() {
+	mockRequest := &mocks.RequestInterface{}
+	shttp.DefaultRequest = mockRequest
+
+	msg := s.message()
+	nc := &app.NotificationChannel{
+		Provider: app.NotificationProviderMatrix,
+		Config: app.NotificationChannelConfig{
+			HomeserverURL: "https://matrix.example.org/",
+			RoomID:        "!abc:example.org",
+			AccessToken:   "syt_secret",
+		},
+	}
+
+	mockRequest.On("URL", "https://matrix.example.org/_matrix/client/v3/rooms/%21abc:example.org/send/m.room.message/"+msg.ID).Return(mockRequest).Once()
+	mockRequest.On("Method", shttp.MethodPut).Return(mockRequest).Once()
+	mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{
+		"Authorization": "Bearer syt_secret",
+		"Content-Type":  "application/json",
+	})).Return(mockRequest).Once()
+	mockRequest.On("Payload", mock.Anything).Return(mockRequest).Once()
+	mockRequest.On("Do").Return(nil, nil).Once()
+
+	s.NoError(nc.Send(msg))
+	mockRequest.AssertExpectations(s.T())
+}
+
+func TestNotificationChannelModel(t *testing.T) {
+	suite.Run(t, &NotificationChannelModelSuite{})
+}
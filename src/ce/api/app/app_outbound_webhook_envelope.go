@@ -0,0 +1,175 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// OutboundWebhookEnvelopeVersion is the version of the JSON envelope. It is
+// incremented only when a field is removed or its meaning changes.
+const OutboundWebhookEnvelopeVersion = 1
+
+// WebhookEnvelope is the payload that is sent when the payload format is json.
+// It is also the data that templates are rendered with. Sections that are not
+// relevant for the event are omitted.
+type WebhookEnvelope struct {
+	Version         int                     `json:"version"`
+	ID              string                  `json:"id"` // ID is unique per event, and stays the same across retries.
+	Event           string                  `json:"event"`
+	CreatedAt       int64                   `json:"createdAt"`
+	App             WebhookApp              `json:"app"`
+	Environment     *WebhookEnvironment     `json:"environment,omitempty"`
+	Deployment      *WebhookDeployment      `json:"deployment,omitempty"`
+	StatusChecks    *WebhookStatusChecks    `json:"statusChecks,omitempty"`
+	Rollout         *WebhookRollout         `json:"rollout,omitempty"`
+	Approval        *WebhookApproval        `json:"approval,omitempty"`
+	Domain          *WebhookDomain          `json:"domain,omitempty"`
+	FunctionTrigger *WebhookFunctionTrigger `json:"functionTrigger,omitempty"`
+	EnvVars         *WebhookEnvVars         `json:"envVars,omitempty"`
+}
+
+type WebhookApp struct {
+	ID types.ID `json:"id,string"`
+}
+
+type WebhookEnvironment struct {
+	Name string `json:"name"`
+}
+
+type WebhookDeployment struct {
+	ID           types.ID `json:"id,string"`
+	Status       string   `json:"status,omitempty"`
+	Branch       string   `json:"branch,omitempty"`
+	Error        string   `json:"error,omitempty"`
+	Endpoint     string   `json:"endpoint,omitempty"`
+	LogsEndpoint string   `json:"logsEndpoint,omitempty"`
+}
+
+type WebhookStatusChecks struct {
+	Status string `json:"status"` // passed | failed
+}
+
+type WebhookRollout struct {
+	Percentage string `json:"percentage,omitempty"`
+	Status     string `json:"status"`
+	Reason     string `json:"reason,omitempty"`
+}
+
+type WebhookApproval struct {
+	ID     string `json:"id"`
+	Status string `json:"status"`
+}
+
+type WebhookDomain struct {
+	Name                 string `json:"name"`
+	Error                string `json:"error,omitempty"`
+	CertificateExpiresAt int64  `json:"certificateExpiresAt,omitempty"`
+}
+
+type WebhookFunctionTrigger struct {
+	ID    types.ID `json:"id,string"`
+	Error string   `json:"error"`
+}
+
+type WebhookEnvVars struct {
+	Changed []string `json:"changed"` // Changed contains the names of the variables, never their values.
+}
+
+// Envelope returns the envelope of the event.
+func (wh OutboundWebhook) Envelope(settings OutboundWebhookSettings) WebhookEnvelope {
+	env := WebhookEnvelope{
+		Version:   OutboundWebhookEnvelopeVersion,
+		ID:        "evt_" + strings.ToLower(utils.RandomToken(24)),
+		Event:     wh.TriggerWhen,
+		CreatedAt: time.Now().Unix(),
+		App:       WebhookApp{ID: settings.AppID},
+	}
+
+	if settings.EnvironmentName != "" {
+		env.Environment = &WebhookEnvironment{Name: settings.EnvironmentName}
+	}
+
+	if settings.DeploymentID != 0 {
+		env.Deployment = &WebhookDeployment{
+			ID:           settings.DeploymentID,
+			Status:       settings.DeploymentStatus,
+			Branch:       settings.Branch,
+			Error:        settings.DeploymentError,
+			Endpoint:     settings.DeploymentEndpoint,
+			LogsEndpoint: settings.DeploymentLogsEndpoint,
+		}
+	}
+
+	if settings.StatusChecksStatus != "" {
+		env.StatusChecks = &WebhookStatusChecks{Status: settings.StatusChecksStatus}
+	}
+
+	if settings.RolloutStatus != "" {
+		env.Rollout = &WebhookRollout{
+			Percentage: settings.RolloutPercentage,
+			Status:     settings.RolloutStatus,
+			Reason:     settings.RolloutReason,
+		}
+	}
+
+	if settings.ApprovalID != "" {
+		env.Approval = &WebhookApproval{ID: settings.ApprovalID, Status: settings.ApprovalStatus}
+	}
+
+	if settings.DomainName != "" {
+		env.Domain = &WebhookDomain{
+			Name:                 settings.DomainName,
+			Error:                settings.DomainError,
+			CertificateExpiresAt: settings.CertificateExpiresAt.Unix(),
+		}
+	}
+
+	if settings.FunctionTriggerID != 0 {
+		env.FunctionTrigger = &WebhookFunctionTrigger{
+			ID:    settings.FunctionTriggerID,
+			Error: settings.FunctionTriggerError,
+		}
+	}
+
+	if settings.ChangedVars != nil {
+		env.EnvVars = &WebhookEnvVars{Changed: settings.ChangedVars}
+	}
+
+	return env
+}
+
+var templateFuncs = template.FuncMap{
+	// json encodes the value, so that strings can be embedded safely in JSON payloads.
+	"json": func(v any) (string, error) {
+		b, err := json.Marshal(v)
+		return string(b), err
+	},
+}
+
+// ParseWebhookTemplate parses the given payload template.
+func ParseWebhookTemplate(tmpl string) (*template.Template, error) {
+	return template.New("payload").Funcs(templateFuncs).Option("missingkey=error").Parse(tmpl)
+}
+
+// renderTemplate renders the payload template with the envelope of the event.
+func (wh OutboundWebhook) renderTemplate(settings OutboundWebhookSettings) (string, error) {
+	tmpl, err := ParseWebhookTemplate(wh.RequestPayload.ValueOrZero())
+
+	if err != nil {
+		return "", err
+	}
+
+	var buf bytes.Buffer
+
+	if err := tmpl.Execute(&buf, wh.Envelope(settings)); err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
@@ -1,12 +1,22 @@
 package app
 
 import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 	null "gopkg.in/guregu/null.v3"
 )
 
@@ -14,6 +24,61 @@ const TriggerOnPublish = "on_publish"
 const TriggerOnDeploySuccess = "on_deploy_success"
 const TriggerOnDeployFailed = "on_deploy_failed"
 const TriggerOnCachePurge = "on_cache_purge"
+const TriggerOnRolloutStep = "on_rollout_step"
+const TriggerOnRollback = "on_rollback"
+const TriggerOnApprovalRequest = "on_approval_request"
+const TriggerOnApprovalDecision = "on_approval_decision"
+const TriggerOnDeployStart = "on_deploy_start"
+const TriggerOnStatusChecks = "on_status_checks_completed"
+const TriggerOnDomainVerified = "on_domain_verified"
+const TriggerOnDomainFailing = "on_domain_failing"
+const TriggerOnCertificateIssued = "on_certificate_issued"
+const TriggerOnCertificateExpiring = "on_certificate_expiring"
+const TriggerOnFunctionTriggerFailed = "on_function_trigger_failed"
+const TriggerOnEnvVarsChanged = "on_env_vars_changed"
+
+// OutboundWebhookEvents is the list of events that can trigger an outbound webhook.
+var OutboundWebhookEvents = []string{
+	TriggerOnDeploySuccess,
+	TriggerOnDeployFailed,
+	TriggerOnPublish,
+	TriggerOnCachePurge,
+	TriggerOnRolloutStep,
+	TriggerOnRollback,
+	TriggerOnApprovalRequest,
+	TriggerOnApprovalDecision,
+	TriggerOnDeployStart,
+	TriggerOnStatusChecks,
+	TriggerOnDomainVerified,
+	TriggerOnDomainFailing,
+	TriggerOnCertificateIssued,
+	TriggerOnCertificateExpiring,
+	TriggerOnFunctionTriggerFailed,
+	TriggerOnEnvVarsChanged,
+}
+
+// Payload formats of an outbound webhook.
+const (
+	// PayloadFormatRaw sends the request payload as it is, after replacing the $SK_* variables.
+	PayloadFormatRaw = "raw"
+
+	// PayloadFormatJSON sends the versioned JSON envelope of the event. The request payload is ignored.
+	PayloadFormatJSON = "json"
+
+	// PayloadFormatTemplate renders the request payload as a Go template with the envelope of the event.
+	PayloadFormatTemplate = "template"
+)
+
+// OutboundWebhookMaxRetry is the number of times a failed delivery is retried.
+const OutboundWebhookMaxRetry = 8
+
+// OutboundWebhookMaxFailures is the number of consecutive failed deliveries
+// after which the webhook is disabled.
+const OutboundWebhookMaxFailures = 5
+
+// maxResponseBodySize is the maximum size of the response body that is
+// stored in the delivery log.
+const maxResponseBodySize = 4 * 1024
 
 type OutboundWebhook struct {
 	WebhookID      types.ID          `json:"id,string"`
@@ -22,6 +87,13 @@ type OutboundWebhook struct {
 	RequestPayload null.String       `json:"requestPayload"`
 	RequestHeaders map[string]string `json:"requestHeaders"`
 	TriggerWhen    string            `json:"triggerWhen"`
+	PayloadFormat  string            `json:"payloadFormat"`
+
+	// Secret is the decrypted signing secret. It is returned only once,
+	// when the webhook is created or the secret is rotated.
+	Secret              string     `json:"-"`
+	ConsecutiveFailures int        `json:"consecutiveFailures"`
+	DisabledAt          utils.Unix `json:"disabledAt"`
 }
 
 type DispatchOutput struct {
@@ -32,6 +104,65 @@ type DispatchOutput struct {
 	} `json:"result"`
 }
 
+func (o DispatchOutput) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+func (o *DispatchOutput) Scan(value any) error {
+	return scanJSON(value, o)
+}
+
+// OutboundWebhookRequest is the request that is sent to the webhook url.
+type OutboundWebhookRequest struct {
+	URL     string            `json:"url"`
+	Method  string            `json:"method"`
+	Headers map[string]string `json:"headers"`
+	Payload string            `json:"payload"`
+}
+
+func (r OutboundWebhookRequest) Value() (driver.Value, error) {
+	return json.Marshal(r)
+}
+
+func (r *OutboundWebhookRequest) Scan(value any) error {
+	return scanJSON(value, r)
+}
+
+// OutboundWebhookDelivery is a record of a single attempt to deliver a webhook.
+type OutboundWebhookDelivery struct {
+	ID          types.ID               `json:"id,string"`
+	WebhookID   types.ID               `json:"webhookId,string"`
+	TriggerWhen string                 `json:"triggerWhen"`
+	Request     OutboundWebhookRequest `json:"request"`
+	Response    DispatchOutput         `json:"response"`
+	Latency     int64                  `json:"latency"` // Latency is the duration of the request in milliseconds.
+	Attempt     int                    `json:"attempt"`
+	IsSuccess   bool                   `json:"success"`
+	CreatedAt   utils.Unix             `json:"createdAt"`
+}
+
+// OutboundWebhookMessage is the payload of the task that delivers a webhook.
+type OutboundWebhookMessage struct {
+	AppID       types.ID `json:"appId,string"`
+	WebhookID   types.ID `json:"webhookId,string"`
+	TriggerWhen string   `json:"triggerWhen"`
+	Payload     string   `json:"payload"`
+}
+
+func scanJSON(value, out any) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, out)
+}
+
 type OutboundWebhookSettings struct {
 	AppID                  types.ID
 	DeploymentID           types.ID
@@ -40,6 +171,26 @@ type OutboundWebhookSettings struct {
 	DeploymentEndpoint     string
 	DeploymentLogsEndpoint string
 	DeploymentStatus       string // success | failed
+	DeploymentAuthor       string // The commit author, or the user who started the deployment
+	EnvironmentProtected   bool
+	RolloutPercentage      string
+	RolloutStatus          string // running | completed | rolled_back
+	RolloutReason          string
+	ApprovalID             string
+	ApprovalStatus         string // pending | approved | rejected
+	Branch                 string
+	StatusChecksStatus     string // passed | failed
+	DomainName             string
+	DomainError            string
+	CertificateExpiresAt   utils.Unix
+	FunctionTriggerID      types.ID
+	FunctionTriggerError   string
+	ChangedVars            []string // The names of the environment variables that changed
+}
+
+// IsDisabled returns true when the webhook was disabled after sustained failures.
+func (wh OutboundWebhook) IsDisabled() bool {
+	return wh.DisabledAt.Valid
 }
 
 func (wh OutboundWebhook) TriggerOnDeploySuccess() bool {
@@ -58,64 +209,280 @@ func (wh OutboundWebhook) TriggerOnCachePurge() bool {
 	return wh.TriggerWhen == TriggerOnCachePurge
 }
 
-// Dispatch an outbound webhook
+func (wh OutboundWebhook) TriggerOnRolloutStep() bool {
+	return wh.TriggerWhen == TriggerOnRolloutStep
+}
+
+func (wh OutboundWebhook) TriggerOnRollback() bool {
+	return wh.TriggerWhen == TriggerOnRollback
+}
+
+func (wh OutboundWebhook) TriggerOnApprovalRequest() bool {
+	return wh.TriggerWhen == TriggerOnApprovalRequest
+}
+
+func (wh OutboundWebhook) TriggerOnApprovalDecision() bool {
+	return wh.TriggerWhen == TriggerOnApprovalDecision
+}
+
+// Dispatch sends the outbound webhook synchronously and returns the response.
 func (wh OutboundWebhook) Dispatch(settings OutboundWebhookSettings) DispatchOutput {
-	req := shttp.NewRequestV2(wh.RequestMethod, wh.RequestURL)
-	req.Headers(shttp.HeadersFromMap(wh.RequestHeaders))
+	payload, err := wh.Payload(settings)
 
-	if wh.RequestPayload.ValueOrZero() != "" {
-		payload := wh.RequestPayload.ValueOrZero()
-		patterns := map[string]string{
-			"$SK_NOW":      time.Now().Format(time.RFC3339),
-			"$SK_NOW_UNIX": fmt.Sprintf("%d", time.Now().Unix()),
-		}
+	if err != nil {
+		return DispatchOutput{Error: err.Error()}
+	}
 
-		if appID := settings.AppID.String(); appID != "" {
-			patterns["$SK_APP_ID"] = appID
-		}
+	return wh.Send(payload).Response
+}
 
-		if did := settings.DeploymentID.String(); did != "" {
-			patterns["$SK_DEPLOYMENT_ID"] = did
+// TriggerOutboundWebhooks enqueues the outbound webhooks of the app that are
+// triggered by the given event.
+func TriggerOutboundWebhooks(ctx context.Context, event string, settings OutboundWebhookSettings) {
+	for _, wh := range NewStore().OutboundWebhooks(ctx, settings.AppID) {
+		if wh.TriggerWhen != event {
+			continue
 		}
 
-		if ep := settings.DeploymentEndpoint; ep != "" {
-			patterns["$SK_DEPLOYMENT_ENDPOINT"] = ep
+		if err := wh.Enqueue(ctx, settings); err != nil {
+			slog.Errorf("error while enqueuing outbound webhook %s: %v", wh.WebhookID.String(), err)
 		}
+	}
+}
 
-		if ep := settings.DeploymentLogsEndpoint; ep != "" {
-			patterns["$SK_DEPLOYMENT_LOGS_ENDPOINT"] = ep
-		}
+// TriggerDomainEvent triggers the given event for the app that the domain
+// belongs to.
+func TriggerDomainEvent(ctx context.Context, event string, domain *buildconf.DomainModel, settings OutboundWebhookSettings) {
+	settings.AppID = domain.AppID
+	settings.DomainName = domain.Name
 
-		if status := settings.DeploymentStatus; status != "" {
-			patterns["$SK_DEPLOYMENT_STATUS"] = status
-		}
+	if env, err := buildconf.NewStore().EnvironmentByID(ctx, domain.EnvID); err != nil {
+		slog.Errorf("cannot fetch environment for domain webhooks: %v", err)
+	} else if env != nil {
+		settings.EnvironmentName = env.Name
+	}
 
-		if err := settings.DeploymentError; err != "" {
-			patterns["$SK_DEPLOYMENT_ERROR"] = err
-		}
+	TriggerEvent(ctx, event, settings)
+}
 
-		if env := settings.EnvironmentName; env != "" {
-			patterns["$SK_ENVIRONMENT"] = env
-		}
+// Enqueue schedules the delivery of the outbound webhook. Failed deliveries
+// are retried with an exponential backoff. Disabled webhooks are skipped.
+func (wh OutboundWebhook) Enqueue(ctx context.Context, settings OutboundWebhookSettings) error {
+	if wh.IsDisabled() {
+		return nil
+	}
 
-		for key, value := range patterns {
-			payload = strings.Replace(payload, key, value, -1)
-		}
+	payload, err := wh.Payload(settings)
+
+	if err != nil {
+		return err
+	}
+
+	return EnqueueOutboundWebhook(ctx, OutboundWebhookMessage{
+		AppID:       settings.AppID,
+		WebhookID:   wh.WebhookID,
+		TriggerWhen: wh.TriggerWhen,
+		Payload:     payload,
+	})
+}
+
+// EnqueueOutboundWebhook enqueues the given message for delivery.
+func EnqueueOutboundWebhook(ctx context.Context, msg OutboundWebhookMessage) error {
+	_, err := tasks.Enqueue(ctx, tasks.OutboundWebhookDelivery, msg, &tasks.EnqueueOptions{
+		MaxRetry: OutboundWebhookMaxRetry,
+	})
+
+	return err
+}
+
+// Payload returns the request payload of the event, based on the payload format.
+func (wh OutboundWebhook) Payload(settings OutboundWebhookSettings) (string, error) {
+	switch wh.PayloadFormat {
+	case PayloadFormatJSON:
+		b, err := json.Marshal(wh.Envelope(settings))
+		return string(b), err
+	case PayloadFormatTemplate:
+		return wh.renderTemplate(settings)
+	default:
+		return wh.replaceVariables(settings), nil
+	}
+}
 
+// replaceVariables returns the request payload with the $SK_* variables replaced.
+func (wh OutboundWebhook) replaceVariables(settings OutboundWebhookSettings) string {
+	payload := wh.RequestPayload.ValueOrZero()
+
+	if payload == "" {
+		return ""
+	}
+
+	patterns := map[string]string{
+		"$SK_NOW":      time.Now().Format(time.RFC3339),
+		"$SK_NOW_UNIX": fmt.Sprintf("%d", time.Now().Unix()),
+		"$SK_EVENT":    wh.TriggerWhen,
+	}
+
+	if appID := settings.AppID.String(); appID != "" {
+		patterns["$SK_APP_ID"] = appID
+	}
+
+	if did := settings.DeploymentID.String(); did != "" {
+		patterns["$SK_DEPLOYMENT_ID"] = did
+	}
+
+	if ep := settings.DeploymentEndpoint; ep != "" {
+		patterns["$SK_DEPLOYMENT_ENDPOINT"] = ep
+	}
+
+	if ep := settings.DeploymentLogsEndpoint; ep != "" {
+		patterns["$SK_DEPLOYMENT_LOGS_ENDPOINT"] = ep
+	}
+
+	if status := settings.DeploymentStatus; status != "" {
+		patterns["$SK_DEPLOYMENT_STATUS"] = status
+	}
+
+	if err := settings.DeploymentError; err != "" {
+		patterns["$SK_DEPLOYMENT_ERROR"] = err
+	}
+
+	if env := settings.EnvironmentName; env != "" {
+		patterns["$SK_ENVIRONMENT"] = env
+	}
+
+	if branch := settings.Branch; branch != "" {
+		patterns["$SK_BRANCH"] = branch
+	}
+
+	if status := settings.StatusChecksStatus; status != "" {
+		patterns["$SK_STATUS_CHECKS_STATUS"] = status
+	}
+
+	if percentage := settings.RolloutPercentage; percentage != "" {
+		patterns["$SK_ROLLOUT_PERCENTAGE"] = percentage
+	}
+
+	if status := settings.RolloutStatus; status != "" {
+		patterns["$SK_ROLLOUT_STATUS"] = status
+	}
+
+	if reason := settings.RolloutReason; reason != "" {
+		patterns["$SK_ROLLOUT_REASON"] = reason
+	}
+
+	if id := settings.ApprovalID; id != "" {
+		patterns["$SK_APPROVAL_ID"] = id
+	}
+
+	if status := settings.ApprovalStatus; status != "" {
+		patterns["$SK_APPROVAL_STATUS"] = status
+	}
+
+	if domain := settings.DomainName; domain != "" {
+		patterns["$SK_DOMAIN"] = domain
+	}
+
+	if err := settings.DomainError; err != "" {
+		patterns["$SK_DOMAIN_ERROR"] = err
+	}
+
+	if settings.CertificateExpiresAt.Valid {
+		patterns["$SK_CERTIFICATE_EXPIRES_AT"] = settings.CertificateExpiresAt.Format(time.RFC3339)
+	}
+
+	if id := settings.FunctionTriggerID.String(); id != "" {
+		patterns["$SK_FUNCTION_TRIGGER_ID"] = id
+	}
+
+	if err := settings.FunctionTriggerError; err != "" {
+		patterns["$SK_FUNCTION_TRIGGER_ERROR"] = err
+	}
+
+	if len(settings.ChangedVars) > 0 {
+		patterns["$SK_CHANGED_VARS"] = strings.Join(settings.ChangedVars, ",")
+	}
+
+	// Longer variables are replaced first, so that $SK_NOW does not
+	// replace the prefix of $SK_NOW_UNIX.
+	keys := make([]string, 0, len(patterns))
+
+	for key := range patterns {
+		keys = append(keys, key)
+	}
+
+	sort.Slice(keys, func(i, j int) bool {
+		return len(keys[i]) > len(keys[j])
+	})
+
+	pairs := make([]string, 0, len(keys)*2)
+
+	for _, key := range keys {
+		pairs = append(pairs, key, patterns[key])
+	}
+
+	return strings.NewReplacer(pairs...).Replace(payload)
+}
+
+// Send sends the given payload to the webhook url and returns the delivery.
+// When the webhook has a signing secret, the request is signed with an
+// HMAC-SHA256 signature of the timestamp and the payload. The values of the
+// configured headers are masked in the returned delivery.
+func (wh OutboundWebhook) Send(payload string) *OutboundWebhookDelivery {
+	headers := map[string]string{}
+	logged := map[string]string{}
+
+	for key, value := range wh.RequestHeaders {
+		headers[key] = value
+		logged[key] = "********"
+	}
+
+	if wh.Secret != "" {
+		ts := time.Now().Unix()
+		headers[shttp.HeaderTimestamp] = strconv.FormatInt(ts, 10)
+		headers[shttp.HeaderSignature] = utils.SignPayload(wh.Secret, ts, []byte(payload))
+		logged[shttp.HeaderTimestamp] = headers[shttp.HeaderTimestamp]
+		logged[shttp.HeaderSignature] = headers[shttp.HeaderSignature]
+	}
+
+	req := shttp.NewRequestV2(wh.RequestMethod, wh.RequestURL)
+	req.Headers(shttp.HeadersFromMap(headers))
+
+	if payload != "" {
 		req.Payload(payload)
 	}
 
+	start := time.Now()
 	res, err := req.Do()
-	data := DispatchOutput{}
+
+	delivery := &OutboundWebhookDelivery{
+		WebhookID:   wh.WebhookID,
+		TriggerWhen: wh.TriggerWhen,
+		Latency:     time.Since(start).Milliseconds(),
+		Request: OutboundWebhookRequest{
+			URL:     wh.RequestURL,
+			Method:  wh.RequestMethod,
+			Headers: logged,
+			Payload: payload,
+		},
+	}
 
 	if err != nil {
-		data.Error = err.Error()
+		delivery.Response.Error = err.Error()
 	}
 
 	if res != nil {
-		data.Result.Body = res.String()
-		data.Result.Status = res.StatusCode
+		delivery.Response.Result.Body = truncate(res.String(), maxResponseBodySize)
+		delivery.Response.Result.Status = res.StatusCode
+	}
+
+	delivery.IsSuccess = err == nil && res != nil && res.StatusCode >= 200 && res.StatusCode < 300
+	return delivery
+}
+
+func truncate(s string, size int) string {
+	if len(s) <= size {
+		return s
 	}
 
-	return data
+	return s[:size]
 }
@@ -0,0 +1,172 @@
+package app_test
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/testutils"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stretchr/testify/suite"
+	null "gopkg.in/guregu/null.v3"
+)
+
+type OutboundWebhookModelSuite struct {
+	suite.Suite
+}
+
+func (s *OutboundWebhookModelSuite) Test_Payload() {
+	wh := app.OutboundWebhook{
+		RequestPayload: null.StringFrom(`{"id":"$SK_DEPLOYMENT_ID","env":"$SK_ENVIRONMENT"}`),
+	}
+
+	payload, err := wh.Payload(app.OutboundWebhookSettings{
+		DeploymentID:    15,
+		EnvironmentName: "production",
+	})
+
+	s.NoError(err)
+	s.JSONEq(`{"id":"15","env":"production"}`, payload)
+}
+
+func (s *OutboundWebhookModelSuite) Test_Payload_OverlappingVariables() {
+	wh := app.OutboundWebhook{
+		TriggerWhen:    app.TriggerOnEnvVarsChanged,
+		RequestPayload: null.StringFrom(`$SK_NOW_UNIX|$SK_EVENT|$SK_CHANGED_VARS`),
+	}
+
+	payload, err := wh.Payload(app.OutboundWebhookSettings{
+		ChangedVars: []string{"API_KEY", "NODE_ENV"},
+	})
+
+	s.NoError(err)
+
+	parts := strings.Split(payload, "|")
+	s.Len(parts, 3)
+
+	_, err = strconv.ParseInt(parts[0], 10, 64)
+	s.NoError(err)
+	s.Equal(app.TriggerOnEnvVarsChanged, parts[1])
+	s.Equal("API_KEY,NODE_ENV", parts[2])
+}
+
+func (s *OutboundWebhookModelSuite) Test_Payload_JSON() {
+	wh := app.OutboundWebhook{
+		TriggerWhen:   app.TriggerOnDomainFailing,
+		PayloadFormat: app.PayloadFormatJSON,
+	}
+
+	payload, err := wh.Payload(app.OutboundWebhookSettings{
+		AppID:           1,
+		EnvironmentName: "production",
+		DomainName:      "www.example.org",
+		DomainError:     "connection refused",
+	})
+
+	s.NoError(err)
+
+	envelope := map[string]any{}
+	s.NoError(json.Unmarshal([]byte(payload), &envelope))
+	s.Equal(float64(app.OutboundWebhookEnvelopeVersion), envelope["version"])
+	s.Equal(app.TriggerOnDomainFailing, envelope["event"])
+	s.True(strings.HasPrefix(envelope["id"].(string), "evt_"))
+	s.Equal(map[string]any{"id": "1"}, envelope["app"])
+	s.Equal(map[string]any{"name": "production"}, envelope["environment"])
+	s.Equal(map[string]any{"name": "www.example.org", "error": "connection refused"}, envelope["domain"])
+	s.NotContains(envelope, "deployment")
+}
+
+func (s *OutboundWebhookModelSuite) Test_Payload_Template() {
+	wh := app.OutboundWebhook{
+		TriggerWhen:    app.TriggerOnDeployFailed,
+		PayloadFormat:  app.PayloadFormatTemplate,
+		RequestPayload: null.StringFrom(`{"text":{{ json .Deployment.Error }},"id":"{{ .Deployment.ID }}","event":"{{ .Event }}"}`),
+	}
+
+	payload, err := wh.Payload(app.OutboundWebhookSettings{
+		AppID:           1,
+		DeploymentID:    15,
+		DeploymentError: `command "npm run build" failed`,
+	})
+
+	s.NoError(err)
+	s.JSONEq(`{"text":"command \"npm run build\" failed","id":"15","event":"on_deploy_failed"}`, payload)
+
+	// Missing sections fail the rendering instead of sending a broken payload
+	wh.RequestPayload = null.StringFrom(`{{ .Domain.Name }}`)
+	_, err = wh.Payload(app.OutboundWebhookSettings{AppID: 1})
+	s.Error(err)
+}
+
+func (s *OutboundWebhookModelSuite) Test_Send_Signed() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusOK,
+		Method:   shttp.MethodPost,
+		DataText: "ok",
+		Expect: func(req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			ts, err := strconv.ParseInt(req.Header.Get(shttp.HeaderTimestamp), 10, 64)
+
+			s.NoError(err)
+			s.Equal("Bearer abc", req.Header.Get("Authorization"))
+			s.Equal(utils.SignPayload("whsec_123", ts, body), req.Header.Get(shttp.HeaderSignature))
+			s.Equal(`{"hello":"world"}`, string(body))
+		},
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	wh := app.OutboundWebhook{
+		WebhookID:      5,
+		RequestURL:     ms.URL(),
+		RequestMethod:  shttp.MethodPost,
+		RequestHeaders: map[string]string{"Authorization": "Bearer abc"},
+		TriggerWhen:    app.TriggerOnPublish,
+		Secret:         "whsec_123",
+	}
+
+	delivery := wh.Send(`{"hello":"world"}`)
+
+	s.Equal(1, mr.NumberOfCalls)
+	s.True(delivery.IsSuccess)
+	s.Equal(http.StatusOK, delivery.Response.Result.Status)
+	s.Equal("ok", delivery.Response.Result.Body)
+	s.Equal("********", delivery.Request.Headers["Authorization"])
+	s.NotEmpty(delivery.Request.Headers[shttp.HeaderSignature])
+	s.Equal(`{"hello":"world"}`, delivery.Request.Payload)
+}
+
+func (s *OutboundWebhookModelSuite) Test_Send_Failed() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status: http.StatusInternalServerError,
+		Method: shttp.MethodGet,
+		Expect: func(req *http.Request) {
+			s.Empty(req.Header.Get(shttp.HeaderSignature))
+		},
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	wh := app.OutboundWebhook{
+		RequestURL:    ms.URL(),
+		RequestMethod: shttp.MethodGet,
+	}
+
+	delivery := wh.Send("")
+
+	s.False(delivery.IsSuccess)
+	s.Equal(http.StatusInternalServerError, delivery.Response.Result.Status)
+}
+
+func TestOutboundWebhookModel(t *testing.T) {
+	suite.Run(t, &OutboundWebhookModelSuite{})
+}
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"time"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
@@ -172,6 +173,45 @@ func WithAPIKey(handler func(*RequestContext) *shttp.Response, opts ...*Opts) sh
 	}
 }
 
+// WithFunctionToken authenticates requests that use the token that functions receive
+// in their invocation context for the given service. Any other request is authenticated
+// with an environment-level API key.
+func WithFunctionToken(service string, handler func(*RequestContext) *shttp.Response) shttp.RequestFunc {
+	withAPIKey := WithAPIKey(handler, &Opts{Env: true})
+
+	return func(req *shttp.RequestContext) *shttp.Response {
+		token := strings.Replace(req.Headers().Get("Authorization"), "Bearer ", "", 1)
+
+		if !strings.HasPrefix(token, apikey.FunctionTokenPrefix) {
+			return withAPIKey(req)
+		}
+
+		envID := apikey.EnvIDFromFunctionToken(service, token, time.Now())
+
+		if envID == 0 {
+			return shttp.Forbidden()
+		}
+
+		app, err := NewStore().AppByEnvID(req.Context(), envID)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if app == nil {
+			return shttp.Forbidden()
+		}
+
+		return handler(&RequestContext{
+			RequestContext: &user.RequestContext{
+				RequestContext: req,
+			},
+			App:   app,
+			EnvID: envID,
+		})
+	}
+}
+
 // WithApp adds the app that is currently requested to the context.
 func WithApp(handler func(*RequestContext) *shttp.Response, opts ...*Opts) shttp.RequestFunc {
 	options := getOpts(opts...)
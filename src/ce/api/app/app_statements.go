@@ -12,29 +12,43 @@ var (
 	tableEnvs             = "apps_build_conf"
 	tableDomains          = "domains"
 	tableOutboundWebhooks = "app_outbound_webhooks"
+	tableWebhookDelivery  = "outbound_webhook_deliveries"
+	tableNotifications    = "app_notification_channels"
 )
 
 type statement struct {
-	selectApp              string
-	selectApps             string
-	selectAppPrivateKey    string
-	selectDeployCandidates string
-	selectAppSettings      string
-	insertApp              string
-	updateApp              string
-	updatePrivateKey       string
-	deletedApps            string
-	isMember               string
-	markAsDeleted          string
-	markArtifactsAsDeleted string
-	membersCount           string
-	removeDeployTrigger    string
-	updateDeployTrigger    string
-	selectOutboundWebhook  string
-	selectOutboundWebhooks string
-	insertOutboundWebhook  string
-	updateOutboundWebhook  string
-	deleteOutboundWebhook  string
+	selectApp                  string
+	selectApps                 string
+	selectAppPrivateKey        string
+	selectDeployCandidates     string
+	selectAppSettings          string
+	insertApp                  string
+	updateApp                  string
+	updatePrivateKey           string
+	deletedApps                string
+	isMember                   string
+	markAsDeleted              string
+	markArtifactsAsDeleted     string
+	membersCount               string
+	removeDeployTrigger        string
+	updateDeployTrigger        string
+	selectOutboundWebhook      string
+	selectOutboundWebhooks     string
+	insertOutboundWebhook      string
+	updateOutboundWebhook      string
+	deleteOutboundWebhook      string
+	updateWebhookSecret        string
+	resetWebhookFailures       string
+	incWebhookFailures         string
+	insertWebhookDelivery      string
+	selectWebhookDelivery      string
+	selectWebhookDeliveries    string
+	removeOldDeliveries        string
+	selectNotificationChannel  string
+	selectNotificationChannels string
+	insertNotificationChannel  string
+	updateNotificationChannel  string
+	deleteNotificationChannel  string
 }
 
 var stmt = &statement{
@@ -185,7 +199,11 @@ var stmt = &statement{
 			wh.request_url,
 			wh.request_method,
 			wh.trigger_when,
-			wh.wh_id
+			wh.wh_id,
+			wh.signing_secret,
+			wh.consecutive_failures,
+			wh.disabled_at,
+			wh.payload_format
 		FROM %s wh
 		WHERE wh.app_id = $1 AND wh.wh_id = $2;
 	`, tableOutboundWebhooks),
@@ -197,7 +215,11 @@ var stmt = &statement{
 			wh.request_url,
 			wh.request_method,
 			wh.trigger_when,
-			wh.wh_id
+			wh.wh_id,
+			wh.signing_secret,
+			wh.consecutive_failures,
+			wh.disabled_at,
+			wh.payload_format
 		FROM %s wh
 		WHERE wh.app_id = $1
 		LIMIT 10 OFFSET 0;
@@ -210,8 +232,11 @@ var stmt = &statement{
 			request_body,
 			request_url,
 			request_method,
-			trigger_when
-		) VALUES ($1, $2, $3, $4, $5, $6)
+			trigger_when,
+			signing_secret,
+			payload_format
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+		RETURNING wh_id;
 	`, tableOutboundWebhooks),
 
 	updateOutboundWebhook: fmt.Sprintf(`
@@ -220,7 +245,10 @@ var stmt = &statement{
 			request_body = $2,
 			request_url = $3,
 			request_method = $4,
-			trigger_when = $5
+			trigger_when = $5,
+			payload_format = $8,
+			consecutive_failures = 0,
+			disabled_at = NULL
 		WHERE
 			app_id = $6 AND
 			wh_id = $7;
@@ -229,4 +257,95 @@ var stmt = &statement{
 	deleteOutboundWebhook: fmt.Sprintf(`
 		DELETE FROM %s WHERE app_id = $1 AND wh_id = $2;
 	`, tableOutboundWebhooks),
+
+	updateWebhookSecret: fmt.Sprintf(`
+		UPDATE %s SET signing_secret = $3 WHERE app_id = $1 AND wh_id = $2;
+	`, tableOutboundWebhooks),
+
+	resetWebhookFailures: fmt.Sprintf(`
+		UPDATE %s SET consecutive_failures = 0 WHERE wh_id = $1;
+	`, tableOutboundWebhooks),
+
+	incWebhookFailures: fmt.Sprintf(`
+		UPDATE %s SET
+			consecutive_failures = consecutive_failures + 1,
+			disabled_at = CASE
+				WHEN consecutive_failures + 1 >= $2 THEN NOW() AT TIME ZONE 'UTC'
+				ELSE disabled_at
+			END
+		WHERE wh_id = $1
+		RETURNING disabled_at IS NOT NULL;
+	`, tableOutboundWebhooks),
+
+	insertWebhookDelivery: fmt.Sprintf(`
+		INSERT INTO %s (
+			wh_id, trigger_when, request, response,
+			latency_ms, attempt, is_success
+		) VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING delivery_id, created_at;
+	`, tableWebhookDelivery),
+
+	selectWebhookDelivery: fmt.Sprintf(`
+		SELECT
+			d.delivery_id, d.wh_id, d.trigger_when, d.request, d.response,
+			d.latency_ms, d.attempt, d.is_success, d.created_at
+		FROM %s d
+		INNER JOIN %s wh ON wh.wh_id = d.wh_id
+		WHERE wh.app_id = $1 AND d.delivery_id = $2;
+	`, tableWebhookDelivery, tableOutboundWebhooks),
+
+	selectWebhookDeliveries: fmt.Sprintf(`
+		SELECT
+			d.delivery_id, d.wh_id, d.trigger_when, d.request, d.response,
+			d.latency_ms, d.attempt, d.is_success, d.created_at
+		FROM %s d
+		INNER JOIN %s wh ON wh.wh_id = d.wh_id
+		WHERE wh.app_id = $1 AND d.wh_id = $2
+		ORDER BY d.delivery_id DESC
+		LIMIT 50;
+	`, tableWebhookDelivery, tableOutboundWebhooks),
+
+	removeOldDeliveries: fmt.Sprintf(`
+		DELETE FROM %s
+		WHERE created_at < NOW() AT TIME ZONE 'UTC' - INTERVAL '30 days';
+	`, tableWebhookDelivery),
+
+	selectNotificationChannel: fmt.Sprintf(`
+		SELECT
+			channel_id, app_id, channel_name, channel_provider,
+			channel_events, channel_config, created_at, updated_at
+		FROM %s
+		WHERE app_id = $1 AND channel_id = $2;
+	`, tableNotifications),
+
+	selectNotificationChannels: fmt.Sprintf(`
+		SELECT
+			channel_id, app_id, channel_name, channel_provider,
+			channel_events, channel_config, created_at, updated_at
+		FROM %s
+		WHERE app_id = $1
+		ORDER BY channel_id ASC;
+	`, tableNotifications),
+
+	insertNotificationChannel: fmt.Sprintf(`
+		INSERT INTO %s (
+			app_id, channel_name, channel_provider,
+			channel_events, channel_config
+		) VALUES ($1, $2, $3, $4, $5)
+		RETURNING channel_id, created_at;
+	`, tableNotifications),
+
+	updateNotificationChannel: fmt.Sprintf(`
+		UPDATE %s SET
+			channel_name = $3,
+			channel_provider = $4,
+			channel_events = $5,
+			channel_config = $6,
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE app_id = $1 AND channel_id = $2;
+	`, tableNotifications),
+
+	deleteNotificationChannel: fmt.Sprintf(`
+		DELETE FROM %s WHERE app_id = $1 AND channel_id = $2;
+	`, tableNotifications),
 }
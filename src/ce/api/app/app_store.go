@@ -17,6 +17,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/lib/database"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
 // Store is the store to handle app logic
@@ -191,10 +192,6 @@ func (s *Store) MarkArtifactsAsDeleted(ctx context.Context, a *App) error {
 }
 
 func (s *Store) fetchApp(ctx context.Context, data map[string]any, params ...any) (*App, error) {
-	app := &App{}
-	env := null.NewString("", false)
-	rnt := null.NewString("", false)
-
 	var qb strings.Builder
 
 	if err := s.selectAppTmpl.Execute(&qb, data); err != nil {
@@ -208,17 +205,28 @@ func (s *Store) fetchApp(ctx context.Context, data map[string]any, params ...any
 		return nil, err
 	}
 
-	err = row.Scan(
+	app, err := scanApp(row)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return app, err
+}
+
+// scanApp scans a row that is returned by the selectApp statement.
+func scanApp(row interface{ Scan(...any) error }) (*App, error) {
+	app := &App{}
+	env := null.NewString("", false)
+	rnt := null.NewString("", false)
+
+	err := row.Scan(
 		&app.ID, &app.Repo,
 		&app.UserID, &app.CreatedAt, &app.ClientID,
 		&app.ClientSecret, &app.DisplayName,
 		&app.AutoDeploy, &env, &app.TeamID, &rnt,
 	)
 
-	if err == sql.ErrNoRows {
-		return nil, nil
-	}
-
 	app.DefaultEnv = env.ValueOrZero()
 	app.Runtime = rnt.ValueOrZero()
 
@@ -233,6 +241,43 @@ func (s *Store) fetchApp(ctx context.Context, data map[string]any, params ...any
 	return app, err
 }
 
+// AppsByRepo returns the apps that are connected to the given repository.
+func (s *Store) AppsByRepo(ctx context.Context, repo string) ([]*App, error) {
+	var qb strings.Builder
+
+	err := s.selectAppTmpl.Execute(&qb, map[string]any{
+		"join":  "",
+		"where": "LOWER(a.repo) = $1 AND a.deleted_at IS NULL ORDER BY a.app_id ASC LIMIT 50",
+	})
+
+	if err != nil {
+		slog.Errorf("error executing query template: %s", err.Error())
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), strings.ToLower(repo))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	apps := []*App{}
+
+	for rows.Next() {
+		app, err := scanApp(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		apps = append(apps, app)
+	}
+
+	return apps, rows.Err()
+}
+
 // AppByID returns an app by its id.
 func (s *Store) AppByID(ctx context.Context, appID types.ID) (*App, error) {
 	return s.fetchApp(ctx, map[string]any{
@@ -475,23 +520,13 @@ func (s *Store) DeleteDeployTrigger(ctx context.Context, appId types.ID) error {
 
 // OutboundWebhook returns an item by it's ID.
 func (s *Store) OutboundWebhook(ctx context.Context, appID, whID types.ID) *OutboundWebhook {
-	var headers []byte
-	wh := &OutboundWebhook{}
-
 	row, err := s.QueryRow(ctx, stmt.selectOutboundWebhook, appID, whID)
 
 	if err != nil {
 		return nil
 	}
 
-	err = row.Scan(
-		&headers,
-		&wh.RequestPayload,
-		&wh.RequestURL,
-		&wh.RequestMethod,
-		&wh.TriggerWhen,
-		&wh.WebhookID,
-	)
+	wh, err := scanOutboundWebhook(row)
 
 	if err != nil {
 		if err == sql.ErrNoRows {
@@ -502,13 +537,6 @@ func (s *Store) OutboundWebhook(ctx context.Context, appID, whID types.ID) *Outb
 		return nil
 	}
 
-	if headers != nil {
-		if err := json.Unmarshal(headers, &wh.RequestHeaders); err != nil {
-			slog.Errorf("failed while unmarshaling outbound webhooks headers: %s", err.Error())
-			return nil
-		}
-	}
-
 	return wh
 }
 
@@ -527,45 +555,86 @@ func (s *Store) OutboundWebhooks(ctx context.Context, appID types.ID) []Outbound
 	whs := []OutboundWebhook{}
 
 	for rows.Next() {
-		var headers []byte
-
-		wh := OutboundWebhook{}
-		params := []interface{}{
-			&headers,
-			&wh.RequestPayload,
-			&wh.RequestURL,
-			&wh.RequestMethod,
-			&wh.TriggerWhen,
-			&wh.WebhookID,
-		}
+		wh, err := scanOutboundWebhook(rows)
 
-		if err := rows.Scan(params...); err != nil {
+		if err != nil {
 			slog.Errorf("failed while scanning outbound webhooks: %s", err.Error())
 			return nil
 		}
 
-		if headers != nil {
-			if err := json.Unmarshal(headers, &wh.RequestHeaders); err != nil {
-				slog.Errorf("failed while unmarshaling outbound webhooks headers: %s", err.Error())
-				return nil
-			}
+		whs = append(whs, *wh)
+	}
+
+	return whs
+}
+
+func scanOutboundWebhook(scanner interface{ Scan(...any) error }) (*OutboundWebhook, error) {
+	var headers []byte
+	var secret null.String
+
+	wh := &OutboundWebhook{}
+
+	err := scanner.Scan(
+		&headers,
+		&wh.RequestPayload,
+		&wh.RequestURL,
+		&wh.RequestMethod,
+		&wh.TriggerWhen,
+		&wh.WebhookID,
+		&secret,
+		&wh.ConsecutiveFailures,
+		&wh.DisabledAt,
+		&wh.PayloadFormat,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if headers != nil {
+		if err := json.Unmarshal(headers, &wh.RequestHeaders); err != nil {
+			return nil, err
 		}
+	}
 
-		whs = append(whs, wh)
+	if secret.ValueOrZero() != "" {
+		wh.Secret = utils.DecryptToString(secret.ValueOrZero())
 	}
 
-	return whs
+	return wh, nil
+}
+
+// encryptWebhookSecret returns the value that is stored in the signing_secret column.
+func encryptWebhookSecret(secret string) (any, error) {
+	if secret == "" {
+		return nil, nil
+	}
+
+	encrypted, err := utils.Encrypt([]byte(secret))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.EncodeToString(encrypted), nil
 }
 
 // InsertOutgoingWebhook inserts an outgoing webhook into the database.
-func (s *Store) InsertOutboundWebhook(ctx context.Context, appID types.ID, wh OutboundWebhook) error {
+// The signing secret is encrypted before it is stored.
+func (s *Store) InsertOutboundWebhook(ctx context.Context, appID types.ID, wh *OutboundWebhook) error {
 	headers, err := json.Marshal(wh.RequestHeaders)
 
 	if err != nil {
 		return err
 	}
 
-	_, err = s.Exec(
+	secret, err := encryptWebhookSecret(wh.Secret)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(
 		ctx,
 		stmt.insertOutboundWebhook,
 		appID,
@@ -574,12 +643,19 @@ func (s *Store) InsertOutboundWebhook(ctx context.Context, appID types.ID, wh Ou
 		wh.RequestURL,
 		wh.RequestMethod,
 		wh.TriggerWhen,
+		secret,
+		utils.GetString(wh.PayloadFormat, PayloadFormatRaw),
 	)
 
-	return err
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&wh.WebhookID)
 }
 
-// UpdateOutgoingWebhook updates the given outgoing webhook.
+// UpdateOutgoingWebhook updates the given outgoing webhook. Updating a
+// webhook re-enables it when it was disabled after sustained failures.
 func (s *Store) UpdateOutboundWebhook(ctx context.Context, appID types.ID, wh *OutboundWebhook) error {
 	headers, err := json.Marshal(wh.RequestHeaders)
 
@@ -597,13 +673,275 @@ func (s *Store) UpdateOutboundWebhook(ctx context.Context, appID types.ID, wh *O
 		wh.TriggerWhen,
 		appID,
 		wh.WebhookID,
+		utils.GetString(wh.PayloadFormat, PayloadFormatRaw),
 	)
 
 	return err
 }
 
+// UpdateOutboundWebhookSecret sets the signing secret of the webhook.
+// An empty secret disables signing.
+func (s *Store) UpdateOutboundWebhookSecret(ctx context.Context, appID, whID types.ID, secret string) error {
+	encrypted, err := encryptWebhookSecret(secret)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Exec(ctx, stmt.updateWebhookSecret, appID, whID, encrypted)
+	return err
+}
+
+// ResetOutboundWebhookFailures resets the consecutive failures of the webhook
+// after a successful delivery.
+func (s *Store) ResetOutboundWebhookFailures(ctx context.Context, whID types.ID) error {
+	_, err := s.Exec(ctx, stmt.resetWebhookFailures, whID)
+	return err
+}
+
+// IncrementOutboundWebhookFailures increments the consecutive failures of the
+// webhook and disables it once they reach OutboundWebhookMaxFailures. It
+// returns true when the webhook is disabled.
+func (s *Store) IncrementOutboundWebhookFailures(ctx context.Context, whID types.ID) (bool, error) {
+	row, err := s.QueryRow(ctx, stmt.incWebhookFailures, whID, OutboundWebhookMaxFailures)
+
+	if err != nil {
+		return false, err
+	}
+
+	var disabled bool
+
+	if err := row.Scan(&disabled); err != nil && err != sql.ErrNoRows {
+		return false, err
+	}
+
+	return disabled, nil
+}
+
+// InsertOutboundWebhookDelivery records a delivery attempt.
+func (s *Store) InsertOutboundWebhookDelivery(ctx context.Context, d *OutboundWebhookDelivery) error {
+	row, err := s.QueryRow(
+		ctx,
+		stmt.insertWebhookDelivery,
+		d.WebhookID,
+		d.TriggerWhen,
+		d.Request,
+		d.Response,
+		d.Latency,
+		d.Attempt,
+		d.IsSuccess,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&d.ID, &d.CreatedAt)
+}
+
+// OutboundWebhookDelivery returns the delivery with the given id that belongs
+// to a webhook of the given app.
+func (s *Store) OutboundWebhookDelivery(ctx context.Context, appID, deliveryID types.ID) (*OutboundWebhookDelivery, error) {
+	row, err := s.QueryRow(ctx, stmt.selectWebhookDelivery, appID, deliveryID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := scanOutboundWebhookDelivery(row)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return d, err
+}
+
+// OutboundWebhookDeliveries returns the last 50 deliveries of the webhook.
+func (s *Store) OutboundWebhookDeliveries(ctx context.Context, appID, whID types.ID) ([]*OutboundWebhookDelivery, error) {
+	rows, err := s.Query(ctx, stmt.selectWebhookDeliveries, appID, whID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deliveries := []*OutboundWebhookDelivery{}
+
+	for rows.Next() {
+		d, err := scanOutboundWebhookDelivery(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deliveries = append(deliveries, d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func scanOutboundWebhookDelivery(scanner interface{ Scan(...any) error }) (*OutboundWebhookDelivery, error) {
+	d := &OutboundWebhookDelivery{}
+
+	err := scanner.Scan(
+		&d.ID, &d.WebhookID, &d.TriggerWhen, &d.Request, &d.Response,
+		&d.Latency, &d.Attempt, &d.IsSuccess, &d.CreatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// RemoveOldOutboundWebhookDeliveries removes the deliveries that are older than 30 days.
+func (s *Store) RemoveOldOutboundWebhookDeliveries(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmt.removeOldDeliveries)
+	return err
+}
+
 // DeleteOutboundWebhook deletes the given webhook for the given app.
 func (s *Store) DeleteOutboundWebhook(ctx context.Context, appID, whID types.ID) error {
 	_, err := s.Exec(ctx, stmt.deleteOutboundWebhook, appID, whID)
 	return err
 }
+
+// NotificationChannel returns the notification channel with the given id
+// that belongs to the app.
+func (s *Store) NotificationChannel(ctx context.Context, appID, channelID types.ID) (*NotificationChannel, error) {
+	row, err := s.QueryRow(ctx, stmt.selectNotificationChannel, appID, channelID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nc, err := scanNotificationChannel(row)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+
+	return nc, err
+}
+
+// NotificationChannels returns the notification channels of the app.
+func (s *Store) NotificationChannels(ctx context.Context, appID types.ID) ([]*NotificationChannel, error) {
+	rows, err := s.Query(ctx, stmt.selectNotificationChannels, appID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	channels := []*NotificationChannel{}
+
+	for rows.Next() {
+		nc, err := scanNotificationChannel(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		channels = append(channels, nc)
+	}
+
+	return channels, rows.Err()
+}
+
+func scanNotificationChannel(scanner interface{ Scan(...any) error }) (*NotificationChannel, error) {
+	var config string
+
+	nc := &NotificationChannel{}
+
+	err := scanner.Scan(
+		&nc.ID, &nc.AppID, &nc.Name, &nc.Provider,
+		pq.Array(&nc.Events), &config, &nc.CreatedAt, &nc.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if config != "" {
+		if err := json.Unmarshal([]byte(utils.DecryptToString(config)), &nc.Config); err != nil {
+			return nil, err
+		}
+	}
+
+	return nc, nil
+}
+
+// encryptNotificationConfig returns the value that is stored in the channel_config column.
+func encryptNotificationConfig(config NotificationChannelConfig) (string, error) {
+	data, err := json.Marshal(config)
+
+	if err != nil {
+		return "", err
+	}
+
+	encrypted, err := utils.Encrypt(data)
+
+	if err != nil {
+		return "", err
+	}
+
+	return utils.EncodeToString(encrypted), nil
+}
+
+// InsertNotificationChannel inserts a notification channel. The config is
+// encrypted before it is stored.
+func (s *Store) InsertNotificationChannel(ctx context.Context, nc *NotificationChannel) error {
+	config, err := encryptNotificationConfig(nc.Config)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(
+		ctx,
+		stmt.insertNotificationChannel,
+		nc.AppID,
+		nc.Name,
+		nc.Provider,
+		pq.Array(nc.Events),
+		config,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&nc.ID, &nc.CreatedAt)
+}
+
+// UpdateNotificationChannel updates the given notification channel.
+func (s *Store) UpdateNotificationChannel(ctx context.Context, nc *NotificationChannel) error {
+	config, err := encryptNotificationConfig(nc.Config)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Exec(
+		ctx,
+		stmt.updateNotificationChannel,
+		nc.AppID,
+		nc.ID,
+		nc.Name,
+		nc.Provider,
+		pq.Array(nc.Events),
+		config,
+	)
+
+	return err
+}
+
+// DeleteNotificationChannel deletes the given notification channel of the app.
+func (s *Store) DeleteNotificationChannel(ctx context.Context, appID, channelID types.ID) error {
+	_, err := s.Exec(ctx, stmt.deleteNotificationChannel, appID, channelID)
+	return err
+}
@@ -110,16 +110,10 @@ func (CacheService) Reset(envID types.ID, keys ...string) error {
 		return errors.New("environment not found")
 	}
 
-	whs := app.NewStore().OutboundWebhooks(ctx, env.AppID)
-
-	for _, wh := range whs {
-		if wh.TriggerOnCachePurge() {
-			wh.Dispatch(app.OutboundWebhookSettings{
-				AppID:           env.AppID,
-				EnvironmentName: env.Name,
-			})
-		}
-	}
+	app.TriggerEvent(ctx, app.TriggerOnCachePurge, app.OutboundWebhookSettings{
+		AppID:           env.AppID,
+		EnvironmentName: env.Name,
+	})
 
 	return nil
 }
@@ -30,6 +30,7 @@ type Config struct {
 	UpdatedAt        utils.Unix           `json:"updatedAt"`
 	Redirects        []redirects.Redirect `json:"redirects,omitempty"`
 	EnvVariables     map[string]string    `json:"envVariables,omitempty"`
+	SecretVars       []string             `json:"secretVars,omitempty"` // Names of the environment variables that come from secrets
 	CertKey          string               `json:"certKey,omitempty"`
 	CertValue        string               `json:"certValue,omitempty"`
 	DomainID         types.ID             `json:"domainId,omitempty"`
@@ -16,6 +16,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/authwall"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 
 	"github.com/stormkit-io/stormkit-io/src/lib/database"
@@ -55,6 +56,7 @@ var stmt = &statement{
 				e.env_name,
 				e.updated_at							 as env_updated,
 				e.build_conf							 as build_conf,
+				d.runtime_vars							 as runtime_vars,
 				e.auth_wall_conf						 as auth_wall_conf,
 				coalesce(dp.percentage_released, 0)		 as percentage,
 				a.display_name,
@@ -105,12 +107,26 @@ var stmt = &statement{
 		SELECT
 			d.app_id, d.deployment_id, d.env_id,
 			d.fn_loc, d.st_loc, d.api_loc, d.api_path_prefix,
-			d.manifest, d.env_updated, d.build_conf, d.percentage,
+			d.manifest, d.env_updated, d.build_conf, d.runtime_vars, d.percentage,
 			coalesce(d.cert_value, '') as cert_value,
 			coalesce(d.cert_key, '') as cert_key,
 			d.domain_id, d.auth_wall_conf,
 			(SELECT json_data FROM snippets) as snippets,
-			d.display_name, d.env_name, d.subscription_tier, d.billing_user_id
+			d.display_name, d.env_name, d.subscription_tier, d.billing_user_id,
+			(
+				SELECT
+					json_object_agg(
+						s.secret_name,
+						json_build_object('value', v.encrypted_value, 'key', v.encrypted_key)
+					)
+				FROM env_secrets s
+				INNER JOIN env_secret_versions v ON
+					v.secret_id = s.secret_id AND
+					v.secret_version = s.current_version
+				WHERE
+					s.env_id = d.env_id AND
+					s.secret_scope IN ('runtime', 'both')
+			) as secrets
 		FROM deployment d
 	`,
 }
@@ -227,6 +243,8 @@ func rowsToConfigs(rows *sql.Rows, err error) ([]*Config, error) {
 
 	for rows.Next() {
 		var buildConf []byte
+		var runtimeVars []byte
+		var secrets []byte
 		var buildManifest *deploy.BuildManifest
 		var certKey string
 		var certVal string
@@ -239,10 +257,10 @@ func rowsToConfigs(rows *sql.Rows, err error) ([]*Config, error) {
 			&cnf.AppID, &cnf.DeploymentID, &cnf.EnvID,
 			&cnf.FunctionLocation, &cnf.StorageLocation,
 			&cnf.APILocation, &cnf.APIPathPrefix,
-			&buildManifest, &cnf.UpdatedAt, &buildConf,
+			&buildManifest, &cnf.UpdatedAt, &buildConf, &runtimeVars,
 			&cnf.Percentage, &certVal, &certKey, &cnf.DomainID,
 			&authwall, &cnf.Snippets, &displayName, &envName, &tier,
-			&cnf.BillingUserID,
+			&cnf.BillingUserID, &secrets,
 		)
 
 		if err != nil {
@@ -263,6 +281,14 @@ func rowsToConfigs(rows *sql.Rows, err error) ([]*Config, error) {
 				return nil, err
 			}
 
+			// Promoted deployments may keep the variables of the source deployment
+			if runtimeVars != nil {
+				if data.Vars, err = deploy.OpenRuntimeVars(runtimeVars); err != nil {
+					slog.Errorf("cannot open the runtime variables of deployment %s: %v", cnf.DeploymentID.String(), err)
+					return nil, err
+				}
+			}
+
 			if data.ErrorFile != "" {
 				data.ErrorFile = "/" + strings.TrimLeft(data.ErrorFile, "/")
 			}
@@ -279,6 +305,14 @@ func rowsToConfigs(rows *sql.Rows, err error) ([]*Config, error) {
 			cnf.Redirects = data.Redirects
 			cnf.ServerCmd = data.ServerCmd
 			cnf.ErrorFile = data.ErrorFile
+			runtimeSecrets, err := envsecret.OpenAll(secrets)
+
+			if err != nil {
+				slog.Errorf("cannot open the runtime secrets of deployment %s: %v", cnf.DeploymentID.String(), err)
+				return nil, err
+			}
+
+			cnf.SecretVars = envsecret.Names(runtimeSecrets)
 			cnf.EnvVariables = data.InterpolatedVars(
 				buildconf.InterpolatedVarsOpts{
 					DeploymentID: cnf.DeploymentID.String(),
@@ -286,6 +320,7 @@ func rowsToConfigs(rows *sql.Rows, err error) ([]*Config, error) {
 					EnvID:        cnf.EnvID.String(),
 					Env:          envName,
 					DisplayName:  displayName,
+					Secrets:      runtimeSecrets,
 				},
 			)
 		}
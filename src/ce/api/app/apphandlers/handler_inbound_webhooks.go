@@ -12,6 +12,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/github"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
@@ -34,6 +35,10 @@ type TriggerDeployInput struct {
 	PullRequestNumber int64
 
 	payload any // The payload that is sent by the provider - we store this in the database.
+
+	// command is the command that is posted as a pull request comment.
+	// When it is set, the command is run instead of triggering a deployment.
+	command *deploycommands.Request
 }
 
 // NewTriggerDeployInput is a helper function to
@@ -57,6 +62,15 @@ func handlerInboundWebhooks(req *shttp.RequestContext) *shttp.Response {
 		return shttp.Forbidden().SetError(err)
 	}
 
+	if input.command != nil {
+		if err := RunCommand(req.Context(), *input.command); err != nil {
+			slog.Errorf("error while running pull request command: %v", err)
+			return shttp.Error(err)
+		}
+
+		return shttp.OK()
+	}
+
 	response := TriggerDeploy(req.Context(), *input)
 
 	if response == nil {
@@ -171,6 +185,9 @@ func TriggerDeploy(ctx context.Context, input TriggerDeployInput) *shttp.Respons
 	return shttp.NoContent()
 }
 
+// RunCommand runs the command that is posted as a pull request comment.
+var RunCommand = deploycommands.Run
+
 // FilterDeployCandidates checks the following conditions and determines
 // whether a deploy candidate should be deployed or not.
 //
@@ -4,6 +4,8 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"gopkg.in/go-playground/webhooks.v5/bitbucket"
@@ -17,6 +19,7 @@ func processBitbucketPayload(req *shttp.RequestContext) (*TriggerDeployInput, er
 		bitbucket.RepoPushEvent,
 		bitbucket.PullRequestCreatedEvent,
 		bitbucket.PullRequestMergedEvent,
+		bitbucket.PullRequestCommentCreatedEvent,
 	)
 
 	if err != nil {
@@ -61,6 +64,29 @@ func processBitbucketPayload(req *shttp.RequestContext) (*TriggerDeployInput, er
 		input.EventType = typePullRequest
 		input.IsFork = false
 
+	// Pull request comment event
+	case bitbucket.PullRequestCommentCreatedPayload:
+		cmd := commands.Parse(event.Comment.Content.Raw)
+
+		if cmd == nil {
+			return nil, nil
+		}
+
+		input.Repo = fmt.Sprintf("bitbucket/%s", event.Repository.FullName)
+		input.command = &deploycommands.Request{
+			Repo:              input.Repo,
+			CheckoutRepo:      fmt.Sprintf("bitbucket/%s", event.PullRequest.Source.Repository.FullName),
+			Branch:            event.PullRequest.Source.Branch.Name,
+			CommitSha:         event.PullRequest.Source.Commit.Hash,
+			PullRequestNumber: event.PullRequest.ID,
+			CommentID:         event.Comment.ID,
+			Commenter:         event.Actor.NickName,
+			CommenterID:       event.Actor.UUID,
+			Command:           cmd,
+		}
+
+		input.command.IsFork = !strings.EqualFold(input.command.CheckoutRepo, input.Repo)
+
 	default:
 		return nil, nil
 	}
@@ -2,8 +2,11 @@ package apphandlers
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils"
@@ -76,6 +79,24 @@ func processGithubPayload(req *shttp.RequestContext) (*TriggerDeployInput, error
 			return nil, nil
 		}
 
+	// Pull request comment event
+	case github.IssueCommentPayload:
+		cmd := commands.Parse(event.Comment.Body)
+
+		if cmd == nil || event.Action != "created" || event.Issue.PullRequest == nil || event.Comment.User.Type == "Bot" {
+			return nil, nil
+		}
+
+		input.Repo = fmt.Sprintf("github/%s", event.Repository.FullName)
+		input.command = &deploycommands.Request{
+			Repo:              input.Repo,
+			PullRequestNumber: event.Issue.Number,
+			CommentID:         event.Comment.ID,
+			Commenter:         event.Comment.User.Login,
+			CommenterID:       strconv.FormatInt(event.Comment.User.ID, 10),
+			Command:           cmd,
+		}
+
 	default:
 		return nil, nil
 	}
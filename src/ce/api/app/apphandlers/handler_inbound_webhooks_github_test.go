@@ -1,6 +1,7 @@
 package apphandlers_test
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha1"
 	"encoding/hex"
@@ -14,7 +15,9 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apphandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
@@ -319,6 +322,74 @@ func (s *InboundGithubSuite) Test_PullRequestMerged() {
 	s.mockDeployer.AssertNotCalled(s.T(), "Deploy")
 }
 
+func (s *InboundGithubSuite) Test_PullRequestComment() {
+	var request *deploycommands.Request
+
+	apphandlers.RunCommand = func(ctx context.Context, req deploycommands.Request) error {
+		request = &req
+		return nil
+	}
+
+	defer func() {
+		apphandlers.RunCommand = deploycommands.Run
+	}()
+
+	payload := map[string]any{}
+	s.NoError(json.Unmarshal([]byte(`{
+		"action": "created",
+		"issue": { "number": 53, "pull_request": {} },
+		"comment": {
+			"id": 1001,
+			"body": "/stormkit promote --to=production",
+			"user": { "login": "dlorenzo", "type": "User" }
+		},
+		"repository": { "full_name": "stormkit-test-acc/test-repo" }
+	}`), &payload))
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/webhooks/github",
+		payload,
+		map[string]string{
+			"X-Github-Event":  "issue_comment",
+			"X-Hub-Signature": fmt.Sprintf("sha1=%s", hex.EncodeToString(githubMac(payload).Sum(nil))),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.NotNil(request)
+	s.Equal("github/stormkit-test-acc/test-repo", request.Repo)
+	s.Equal(int64(53), request.PullRequestNumber)
+	s.Equal("dlorenzo", request.Commenter)
+	s.Equal(commands.ActionPromote, request.Command.Action)
+	s.Equal("production", request.Command.Flags["to"])
+	s.mockDeployer.AssertNotCalled(s.T(), "Deploy")
+}
+
+func (s *InboundGithubSuite) Test_PullRequestComment_NoCommand() {
+	payload := map[string]any{}
+	s.NoError(json.Unmarshal([]byte(`{
+		"action": "created",
+		"issue": { "number": 53, "pull_request": {} },
+		"comment": { "id": 1001, "body": "Looks good", "user": { "login": "dlorenzo" } },
+		"repository": { "full_name": "stormkit-test-acc/test-repo" }
+	}`), &payload))
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/webhooks/github",
+		payload,
+		map[string]string{
+			"X-Github-Event":  "issue_comment",
+			"X-Hub-Signature": fmt.Sprintf("sha1=%s", hex.EncodeToString(githubMac(payload).Sum(nil))),
+		},
+	)
+
+	s.Equal(http.StatusNoContent, response.Code)
+}
+
 func TestInboundGithub(t *testing.T) {
 	suite.Run(t, &InboundGithubSuite{})
 }
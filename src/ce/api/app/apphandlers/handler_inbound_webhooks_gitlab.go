@@ -2,8 +2,11 @@ package apphandlers
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"gopkg.in/go-playground/webhooks.v5/gitlab"
@@ -70,6 +73,29 @@ func processGitlabPayload(req *shttp.RequestContext) (*TriggerDeployInput, error
 		input.CommitSha = event.ObjectAttributes.LastCommit.ID
 		input.EventType = typePullRequest
 
+	// Merge request comment event
+	case gitlab.CommentEventPayload:
+		cmd := commands.Parse(event.ObjectAttributes.Note)
+
+		if cmd == nil || event.ObjectAttributes.NotebookType != "MergeRequest" || event.ObjectAttributes.System {
+			return nil, nil
+		}
+
+		input.Repo = fmt.Sprintf("gitlab/%s", event.Project.PathWithNamespace)
+		input.command = &deploycommands.Request{
+			Repo:              input.Repo,
+			CheckoutRepo:      fmt.Sprintf("gitlab/%s", event.MergeRequest.Source.PathWithNamespace),
+			Branch:            event.MergeRequest.SourceBranch,
+			CommitSha:         event.MergeRequest.LastCommit.ID,
+			PullRequestNumber: event.MergeRequest.IID,
+			CommentID:         event.ObjectAttributes.ID,
+			Commenter:         event.User.UserName,
+			CommenterID:       strconv.FormatInt(event.ObjectAttributes.AuthorID, 10),
+			Command:           cmd,
+		}
+
+		input.command.IsFork = !strings.EqualFold(input.command.CheckoutRepo, input.Repo)
+
 	default:
 		return nil, nil
 	}
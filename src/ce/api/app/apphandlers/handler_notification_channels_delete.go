@@ -0,0 +1,25 @@
+package apphandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type notificationChannelDeleteRequestData struct {
+	ChannelID types.ID `json:"channelId,string"`
+}
+
+func handlerNotificationChannelDelete(req *app.RequestContext) *shttp.Response {
+	data := &notificationChannelDeleteRequestData{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := app.NewStore().DeleteNotificationChannel(req.Context(), req.App.ID, data.ChannelID); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
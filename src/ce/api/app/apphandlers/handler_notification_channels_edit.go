@@ -0,0 +1,56 @@
+package apphandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+func handlerNotificationChannelUpdate(req *app.RequestContext) *shttp.Response {
+	data := &notificationChannelRequestData{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	store := app.NewStore()
+	nc, err := store.NotificationChannel(req.Context(), req.App.ID, data.ChannelID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if nc == nil {
+		return shttp.NotFound()
+	}
+
+	// Switching the provider requires new credentials.
+	if nc.Provider != data.Provider {
+		nc.Config = app.NotificationChannelConfig{}
+	}
+
+	nc.Name = data.Name
+	nc.Provider = data.Provider
+	nc.Events = data.Events
+	nc.Config.WebhookURL = utils.GetString(data.Config.WebhookURL, nc.Config.WebhookURL)
+	nc.Config.HomeserverURL = data.Config.HomeserverURL
+	nc.Config.RoomID = data.Config.RoomID
+	nc.Config.AccessToken = utils.GetString(data.Config.AccessToken, nc.Config.AccessToken)
+
+	if err := nc.Validate(true); err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := store.UpdateNotificationChannel(req.Context(), nc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"channel": notificationChannelResponse(nc),
+		},
+	}
+}
@@ -0,0 +1,59 @@
+package apphandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type notificationChannelRequestData struct {
+	model.Model
+	app.NotificationChannel
+
+	// ChannelID is used only for PUT requests
+	ChannelID types.ID `json:"channelId,string"`
+}
+
+// Validate implements model.Validate interface. Secrets can be omitted
+// when updating a channel, in which case the stored ones are kept.
+func (nc *notificationChannelRequestData) Validate() *shttperr.ValidationError {
+	return nc.NotificationChannel.Validate(nc.ChannelID == 0)
+}
+
+// notificationChannelResponse returns the channel without its secrets.
+func notificationChannelResponse(nc *app.NotificationChannel) *app.NotificationChannel {
+	public := *nc
+	public.Config = nc.Config.Public()
+	return &public
+}
+
+func handlerNotificationChannelInsert(req *app.RequestContext) *shttp.Response {
+	data := &notificationChannelRequestData{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	nc := &app.NotificationChannel{
+		AppID:    req.App.ID,
+		Name:     data.Name,
+		Provider: data.Provider,
+		Events:   data.Events,
+		Config:   data.Config,
+	}
+
+	if err := app.NewStore().InsertNotificationChannel(req.Context(), nc); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusCreated,
+		Data: map[string]any{
+			"channel": notificationChannelResponse(nc),
+		},
+	}
+}
@@ -0,0 +1,29 @@
+package apphandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+func handlerNotificationChannelList(req *app.RequestContext) *shttp.Response {
+	channels, err := app.NewStore().NotificationChannels(req.Context(), req.App.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	public := []*app.NotificationChannel{}
+
+	for _, nc := range channels {
+		public = append(public, notificationChannelResponse(nc))
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"channels": public,
+		},
+	}
+}
@@ -0,0 +1,37 @@
+package apphandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/config"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerNotificationChannelSample sends a sample message to the channel,
+// so that users can check the configuration.
+func handlerNotificationChannelSample(req *app.RequestContext) *shttp.Response {
+	nc, err := app.NewStore().NotificationChannel(req.Context(), req.App.ID, utils.StringToID(req.Vars()["cid"]))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if nc == nil {
+		return shttp.NotFound()
+	}
+
+	msg := app.NewNotificationMessage(app.TriggerOnDeploySuccess, app.OutboundWebhookSettings{
+		AppID:                  req.App.ID,
+		DeploymentID:           1,
+		EnvironmentName:        config.AppDefaultEnvironmentName,
+		DeploymentEndpoint:     "https://www.stormkit.io/examples/deployment",
+		DeploymentLogsEndpoint: "https://www.stormkit.io/examples/deployment/logs",
+		DeploymentStatus:       "success",
+	})
+
+	if err := nc.Send(msg); err != nil {
+		return shttp.BadRequest(map[string]any{"error": err.Error()})
+	}
+
+	return shttp.OK()
+}
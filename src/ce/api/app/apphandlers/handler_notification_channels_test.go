@@ -0,0 +1,202 @@
+package apphandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apphandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type NotificationChannelsHandlerSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *NotificationChannelsHandlerSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *NotificationChannelsHandlerSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *NotificationChannelsHandlerSuite) insert(appl *factory.MockApp) *app.NotificationChannel {
+	nc := &app.NotificationChannel{
+		AppID:    appl.ID,
+		Name:     "Deployments",
+		Provider: app.NotificationProviderSlack,
+		Events:   []string{app.TriggerOnDeployFailed},
+		Config:   app.NotificationChannelConfig{WebhookURL: "https://hooks.slack.com/services/T0/B0/X"},
+	}
+
+	s.NoError(app.NewStore().InsertNotificationChannel(context.Background(), nc))
+	return nc
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_Insert() {
+	appl := s.MockApp(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/notification-channels",
+		map[string]any{
+			"appId":    appl.ID.String(),
+			"name":     "Deployments",
+			"provider": "slack",
+			"events":   []string{app.TriggerOnDeployFailed, app.TriggerOnPublish},
+			"config":   map[string]string{"webhookUrl": "https://hooks.slack.com/services/T0/B0/X"},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusCreated, response.Code)
+	s.Contains(response.String(), `"webhookUrl":"https://hooks.slack.com/********"`)
+
+	channels, err := app.NewStore().NotificationChannels(context.Background(), appl.ID)
+	s.NoError(err)
+	s.Len(channels, 1)
+	s.Equal([]string{app.TriggerOnDeployFailed, app.TriggerOnPublish}, channels[0].Events)
+	s.Equal("https://hooks.slack.com/services/T0/B0/X", channels[0].Config.WebhookURL)
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_Insert_BadRequest() {
+	appl := s.MockApp(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/notification-channels",
+		map[string]any{
+			"appId":    appl.ID.String(),
+			"name":     "Deployments",
+			"provider": "irc",
+			"events":   []string{app.TriggerOnDeployFailed},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{
+		"errors": {
+			"provider": "Invalid provider value. Accepted values are: slack | teams | discord | matrix",
+			"webhookUrl": "Webhook URL is invalid."
+		}
+	}`, response.String())
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_List() {
+	appl := s.MockApp(nil)
+	s.insert(appl)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		"/app/"+appl.ID.String()+"/notification-channels",
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.Contains(response.String(), `"name":"Deployments"`)
+	s.NotContains(response.String(), "T0/B0/X")
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_Update_KeepsSecrets() {
+	appl := s.MockApp(nil)
+	nc := s.insert(appl)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/app/notification-channels",
+		map[string]any{
+			"appId":     appl.ID.String(),
+			"channelId": nc.ID.String(),
+			"name":      "Failures",
+			"provider":  "slack",
+			"events":    []string{app.TriggerOnDomainFailing},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	updated, err := app.NewStore().NotificationChannel(context.Background(), appl.ID, nc.ID)
+	s.NoError(err)
+	s.Equal("Failures", updated.Name)
+	s.Equal([]string{app.TriggerOnDomainFailing}, updated.Events)
+	s.Equal("https://hooks.slack.com/services/T0/B0/X", updated.Config.WebhookURL)
+	s.True(updated.UpdatedAt.Valid)
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_Update_ProviderRequiresSecrets() {
+	appl := s.MockApp(nil)
+	nc := s.insert(appl)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/app/notification-channels",
+		map[string]any{
+			"appId":     appl.ID.String(),
+			"channelId": nc.ID.String(),
+			"name":      "Deployments",
+			"provider":  "matrix",
+			"events":    []string{app.TriggerOnDeployFailed},
+			"config":    map[string]string{"homeserverUrl": "https://matrix.org", "roomId": "!abc:matrix.org"},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.Contains(response.String(), "accessToken")
+}
+
+func (s *NotificationChannelsHandlerSuite) Test_Delete() {
+	appl := s.MockApp(nil)
+	nc := s.insert(appl)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodDelete,
+		"/app/notification-channels",
+		map[string]any{
+			"appId":     appl.ID.String(),
+			"channelId": nc.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	channels, err := app.NewStore().NotificationChannels(context.Background(), appl.ID)
+	s.NoError(err)
+	s.Len(channels, 0)
+}
+
+func TestNotificationChannelsHandler(t *testing.T) {
+	suite.Run(t, &NotificationChannelsHandlerSuite{})
+}
@@ -36,7 +36,7 @@ func (s *OutboundWebhooksDeleteHandlerSuite) TestSuccess() {
 	store := app.NewStore()
 	appl := s.MockApp(nil)
 	err := store.InsertOutboundWebhook(
-		context.Background(), appl.ID, app.OutboundWebhook{
+		context.Background(), appl.ID, &app.OutboundWebhook{
 			RequestURL:    admin.MustConfig().AppURL("/"),
 			RequestMethod: "POST",
 			TriggerWhen:   app.TriggerOnDeploySuccess,
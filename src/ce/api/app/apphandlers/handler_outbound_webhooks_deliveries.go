@@ -0,0 +1,32 @@
+package apphandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerOutboundWebhookDeliveries returns the last 50 deliveries of the webhook.
+func handlerOutboundWebhookDeliveries(req *app.RequestContext) *shttp.Response {
+	store := app.NewStore()
+	wh := store.OutboundWebhook(req.Context(), req.App.ID, utils.StringToID(req.Vars()["wid"]))
+
+	if wh == nil {
+		return shttp.NotFound()
+	}
+
+	deliveries, err := store.OutboundWebhookDeliveries(req.Context(), req.App.ID, wh.WebhookID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"deliveries": deliveries,
+		},
+	}
+}
@@ -1,8 +1,11 @@
 package apphandlers
 
 import (
+	"net/http"
+
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
 func handlerOutboundWebhookUpdate(req *app.RequestContext) *shttp.Response {
@@ -24,10 +27,27 @@ func handlerOutboundWebhookUpdate(req *app.RequestContext) *shttp.Response {
 	wh.RequestPayload = whReq.RequestPayload
 	wh.RequestURL = whReq.RequestURL
 	wh.TriggerWhen = whReq.TriggerWhen
+	wh.PayloadFormat = whReq.PayloadFormat
+
+	if err := store.UpdateOutboundWebhook(req.Context(), req.App.ID, wh); err != nil {
+		return shttp.Error(err)
+	}
 
-	if err := app.NewStore().UpdateOutboundWebhook(req.Context(), req.App.ID, wh); err != nil {
+	if !whReq.RotateSecret {
+		return shttp.OK()
+	}
+
+	secret := utils.GenerateSigningSecret()
+
+	if err := store.UpdateOutboundWebhookSecret(req.Context(), req.App.ID, wh.WebhookID, secret); err != nil {
 		return shttp.Error(err)
 	}
 
-	return shttp.OK()
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"ok":     true,
+			"secret": secret,
+		},
+	}
 }
@@ -2,6 +2,7 @@ package apphandlers_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
 
@@ -35,7 +36,7 @@ func (s *OutboundWebhooksUpdateHandlerSuite) AfterTest(_, _ string) {
 func (s *OutboundWebhooksUpdateHandlerSuite) Test_Success() {
 	appl := s.MockApp(nil)
 	store := app.NewStore()
-	wh := app.OutboundWebhook{
+	wh := &app.OutboundWebhook{
 		WebhookID:      1, // This will be generated on the fly
 		RequestURL:     "https://api.discord.com/hooks/575VN415XU",
 		RequestMethod:  "POST",
@@ -97,6 +98,42 @@ func (s *OutboundWebhooksUpdateHandlerSuite) Test_404() {
 	s.Equal(response.Code, http.StatusNotFound)
 }
 
+func (s *OutboundWebhooksUpdateHandlerSuite) Test_RotateSecret() {
+	appl := s.MockApp(nil)
+	store := app.NewStore()
+	wh := &app.OutboundWebhook{
+		RequestURL:    "https://api.example.org/hooks/575VN415XU",
+		RequestMethod: "POST",
+		TriggerWhen:   "on_publish",
+		Secret:        "whsec_old",
+	}
+
+	s.NoError(store.InsertOutboundWebhook(context.Background(), appl.ID, wh))
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/app/outbound-webhooks",
+		map[string]any{
+			"appId":         appl.ID.String(),
+			"whId":          wh.WebhookID.String(),
+			"requestMethod": "POST",
+			"requestUrl":    "https://api.example.org/hooks/575VN415XU",
+			"triggerWhen":   "on_publish",
+			"rotateSecret":  true,
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	whUpdated := store.OutboundWebhook(context.Background(), appl.ID, wh.WebhookID)
+	s.NotEqual("whsec_old", whUpdated.Secret)
+	s.JSONEq(fmt.Sprintf(`{"ok":true,"secret":"%s"}`, whUpdated.Secret), response.String())
+}
+
 func TestOutboundWebhooksEdit(t *testing.T) {
 	suite.Run(t, &OutboundWebhooksUpdateHandlerSuite{})
 }
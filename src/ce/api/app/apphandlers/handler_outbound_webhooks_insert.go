@@ -22,17 +22,16 @@ type outboundWebhookRequestData struct {
 
 	// WebhookID is used only for PUT requests
 	WebhookID types.ID `json:"whId,string"`
+
+	// RotateSecret generates a new signing secret. It is used only for PUT requests.
+	RotateSecret bool `json:"rotateSecret"`
 }
 
 // Validate implements model.Validate interface.
 func (wh *outboundWebhookRequestData) Validate() *shttperr.ValidationError {
 	err := &shttperr.ValidationError{}
-	allowed := []string{
-		app.TriggerOnDeploySuccess,
-		app.TriggerOnDeployFailed,
-		app.TriggerOnPublish,
-		app.TriggerOnCachePurge,
-	}
+	allowed := app.OutboundWebhookEvents
+	formats := []string{app.PayloadFormatRaw, app.PayloadFormatJSON, app.PayloadFormatTemplate}
 
 	// Backwards compatibility
 	if wh.TriggerWhen == "on_deploy" {
@@ -51,6 +50,20 @@ func (wh *outboundWebhookRequestData) Validate() *shttperr.ValidationError {
 		err.SetError("requesUrl", uerr.Error())
 	}
 
+	if wh.PayloadFormat == "" {
+		wh.PayloadFormat = app.PayloadFormatRaw
+	}
+
+	if !utils.InSliceString(formats, wh.PayloadFormat) {
+		err.SetError("payloadFormat", fmt.Sprintf("Invalid payloadFormat value. Accepted values are: %s", strings.Join(formats, " | ")))
+	}
+
+	if wh.PayloadFormat == app.PayloadFormatTemplate {
+		if _, terr := app.ParseWebhookTemplate(wh.RequestPayload.ValueOrZero()); terr != nil {
+			err.SetError("requestPayload", fmt.Sprintf("Invalid template: %s", terr.Error()))
+		}
+	}
+
 	return err.ToError()
 }
 
@@ -83,9 +96,23 @@ func handlerOutboundWebhookInsert(req *app.RequestContext) *shttp.Response {
 		}
 	}
 
-	if err := app.NewStore().InsertOutboundWebhook(req.Context(), req.App.ID, whReq.OutboundWebhook); err != nil {
+	if strings.Contains(whReq.RequestURL, admin.MustConfig().ApiURL("/hooks/deploy/")) {
+		return shttp.Error(shttperr.New(http.StatusBadRequest, "Can't use Trigger deploy link as outbound request", ""))
+	}
+
+	wh := &whReq.OutboundWebhook
+	wh.Secret = utils.GenerateSigningSecret()
+
+	if err := app.NewStore().InsertOutboundWebhook(req.Context(), req.App.ID, wh); err != nil {
 		return shttp.Error(err)
 	}
 
-	return shttp.OK()
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"ok":     true,
+			"whId":   wh.WebhookID.String(),
+			"secret": wh.Secret,
+		},
+	}
 }
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"net/http"
+	"strings"
 	"testing"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
@@ -36,11 +37,15 @@ func (s *OutboundWebhooksSuite) AfterTest(suiteName, _ string) {
 // Payload not nil, headers nil
 func (s *OutboundWebhooksSuite) Test_Success() {
 	triggerWhen := map[string]string{
-		app.TriggerOnCachePurge:    "on_cache_purge",
-		app.TriggerOnPublish:       "on_publish",
-		app.TriggerOnDeployFailed:  "on_deploy_failed",
-		app.TriggerOnDeploySuccess: "on_deploy_success",
-		"on_deploy":                "on_deploy_success", // Backwards compatibility
+		app.TriggerOnCachePurge:       "on_cache_purge",
+		app.TriggerOnPublish:          "on_publish",
+		app.TriggerOnDeployFailed:     "on_deploy_failed",
+		app.TriggerOnDeploySuccess:    "on_deploy_success",
+		app.TriggerOnRolloutStep:      "on_rollout_step",
+		app.TriggerOnRollback:         "on_rollback",
+		app.TriggerOnApprovalRequest:  "on_approval_request",
+		app.TriggerOnApprovalDecision: "on_approval_decision",
+		"on_deploy":                   "on_deploy_success", // Backwards compatibility
 	}
 
 	for tw, expected := range triggerWhen {
@@ -72,6 +77,8 @@ func (s *OutboundWebhooksSuite) Test_Success() {
 		s.Equal(whs[0].RequestPayload.Valid, true)
 		s.Equal(whs[0].TriggerWhen, expected)
 		s.Equal(whs[0].RequestURL, "https://api.discord.com/hooks/575VN415XU")
+		s.True(strings.HasPrefix(whs[0].Secret, "whsec_"))
+		s.Contains(response.String(), whs[0].Secret)
 	}
 }
 
@@ -97,7 +104,7 @@ func (s *OutboundWebhooksSuite) Test_BadRequest() {
 		"errors": {
 			"requesUrl": "parse \"invalid_url\": invalid URI for request",
 			"requestMethod":"Invalid requestMethod value. Accepted values are: POST | GET | HEAD",
-			"triggerWhen":"Invalid triggerWhen value. Accepted values are: on_deploy_success | on_deploy_failed | on_publish | on_cache_purge"
+			"triggerWhen":"Invalid triggerWhen value. Accepted values are: on_deploy_success | on_deploy_failed | on_publish | on_cache_purge | on_rollout_step | on_rollback | on_approval_request | on_approval_decision | on_deploy_start | on_status_checks_completed | on_domain_verified | on_domain_failing | on_certificate_issued | on_certificate_expiring | on_function_trigger_failed | on_env_vars_changed"
 		}
 	}`
 
@@ -108,6 +115,40 @@ func (s *OutboundWebhooksSuite) Test_BadRequest() {
 	s.Equal(len(whs), 0)
 }
 
+func (s *OutboundWebhooksSuite) Test_PayloadFormat() {
+	mockApp := s.MockApp(nil)
+	handler := shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler()
+	headers := map[string]string{
+		"Authorization": usertest.Authorization(mockApp.User().ID),
+	}
+
+	response := shttptest.RequestWithHeaders(handler, shttp.MethodPost, "/app/outbound-webhooks", map[string]any{
+		"appId":          mockApp.ID.String(),
+		"requestUrl":     "https://example.org/webhooks",
+		"requestMethod":  "POST",
+		"triggerWhen":    app.TriggerOnDeployFailed,
+		"payloadFormat":  app.PayloadFormatTemplate,
+		"requestPayload": `{"text":{{ json .Deployment.Error }`,
+	}, headers)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.Contains(response.String(), "Invalid template")
+
+	response = shttptest.RequestWithHeaders(handler, shttp.MethodPost, "/app/outbound-webhooks", map[string]any{
+		"appId":         mockApp.ID.String(),
+		"requestUrl":    "https://example.org/webhooks",
+		"requestMethod": "POST",
+		"triggerWhen":   app.TriggerOnDeployStart,
+		"payloadFormat": app.PayloadFormatJSON,
+	}, headers)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	whs := app.NewStore().OutboundWebhooks(context.Background(), mockApp.ID)
+	s.Len(whs, 1)
+	s.Equal(app.PayloadFormatJSON, whs[0].PayloadFormat)
+}
+
 func (s *OutboundWebhooksSuite) Test_PreventLoop() {
 	mockApp := s.MockApp(nil, map[string]any{
 		"DeployTrigger": "zlhuejysosgxe7",
@@ -57,7 +57,7 @@ func (s *OutboundWebhooksListSuite) Test_HandlerOutboundWebhookList_NotEmpty() {
 	appl := s.MockApp(usr)
 
 	app.NewStore().InsertOutboundWebhook(
-		context.Background(), appl.ID, app.OutboundWebhook{
+		context.Background(), appl.ID, &app.OutboundWebhook{
 			RequestURL:    "https://discord.com/hooks/test",
 			RequestMethod: "GET",
 			TriggerWhen:   app.TriggerOnDeploySuccess,
@@ -0,0 +1,58 @@
+package apphandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type outboundWebhookRedeliverRequestData struct {
+	DeliveryID types.ID `json:"deliveryId,string"`
+}
+
+// handlerOutboundWebhookRedeliver enqueues the payload of a previous delivery
+// once again. The request is signed with the current secret of the webhook.
+func handlerOutboundWebhookRedeliver(req *app.RequestContext) *shttp.Response {
+	data := &outboundWebhookRedeliverRequestData{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	store := app.NewStore()
+	delivery, err := store.OutboundWebhookDelivery(req.Context(), req.App.ID, data.DeliveryID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if delivery == nil {
+		return shttp.NotFound()
+	}
+
+	wh := store.OutboundWebhook(req.Context(), req.App.ID, delivery.WebhookID)
+
+	if wh == nil {
+		return shttp.NotFound()
+	}
+
+	if wh.IsDisabled() {
+		return shttp.Error(shttperr.New(http.StatusBadRequest, "The webhook is disabled after sustained failures. Update the webhook to enable it again.", ""))
+	}
+
+	err = app.EnqueueOutboundWebhook(req.Context(), app.OutboundWebhookMessage{
+		AppID:       req.App.ID,
+		WebhookID:   wh.WebhookID,
+		TriggerWhen: delivery.TriggerWhen,
+		Payload:     delivery.Request.Payload,
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
@@ -0,0 +1,157 @@
+package apphandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apphandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type OutboundWebhooksRedeliverSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn           databasetest.TestDB
+	mockClient     *mocks.TaskClient
+	originalClient func() tasks.TaskClient
+}
+
+func (s *OutboundWebhooksRedeliverSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.mockClient = &mocks.TaskClient{}
+	s.originalClient = tasks.Client
+	tasks.Client = func() tasks.TaskClient {
+		return s.mockClient
+	}
+}
+
+func (s *OutboundWebhooksRedeliverSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	tasks.Client = s.originalClient
+}
+
+func (s *OutboundWebhooksRedeliverSuite) insertDelivery(appl *factory.MockApp) (*app.OutboundWebhook, *app.OutboundWebhookDelivery) {
+	ctx := context.Background()
+	store := app.NewStore()
+	wh := &app.OutboundWebhook{
+		RequestURL:    "https://example.org/webhooks",
+		RequestMethod: shttp.MethodPost,
+		TriggerWhen:   app.TriggerOnPublish,
+	}
+
+	s.NoError(store.InsertOutboundWebhook(ctx, appl.ID, wh))
+
+	delivery := &app.OutboundWebhookDelivery{
+		WebhookID:   wh.WebhookID,
+		TriggerWhen: app.TriggerOnPublish,
+		Attempt:     9,
+		Request: app.OutboundWebhookRequest{
+			URL:     wh.RequestURL,
+			Method:  wh.RequestMethod,
+			Payload: `{"hello":"world"}`,
+		},
+	}
+
+	s.NoError(store.InsertOutboundWebhookDelivery(ctx, delivery))
+	return wh, delivery
+}
+
+func (s *OutboundWebhooksRedeliverSuite) Test_Deliveries() {
+	appl := s.MockApp(nil)
+	wh, delivery := s.insertDelivery(appl)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		"/app/"+appl.ID.String()+"/outbound-webhooks/"+wh.WebhookID.String()+"/deliveries",
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	data := struct {
+		Deliveries []app.OutboundWebhookDelivery `json:"deliveries"`
+	}{}
+
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Len(data.Deliveries, 1)
+	s.Equal(delivery.ID, data.Deliveries[0].ID)
+	s.Equal(9, data.Deliveries[0].Attempt)
+}
+
+func (s *OutboundWebhooksRedeliverSuite) Test_Redeliver() {
+	appl := s.MockApp(nil)
+	wh, delivery := s.insertDelivery(appl)
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/outbound-webhooks/redeliver",
+		map[string]string{
+			"appId":      appl.ID.String(),
+			"deliveryId": delivery.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(appl.UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		msg := app.OutboundWebhookMessage{}
+		s.NoError(json.Unmarshal(task.Payload(), &msg))
+
+		return s.Equal(tasks.OutboundWebhookDelivery, task.Type()) &&
+			s.Equal(app.OutboundWebhookMessage{
+				AppID:       appl.ID,
+				WebhookID:   wh.WebhookID,
+				TriggerWhen: app.TriggerOnPublish,
+				Payload:     `{"hello":"world"}`,
+			}, msg)
+	}))
+}
+
+func (s *OutboundWebhooksRedeliverSuite) Test_Redeliver_OtherApp() {
+	appl := s.MockApp(nil)
+	_, delivery := s.insertDelivery(appl)
+	other := s.MockApp(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(apphandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/outbound-webhooks/redeliver",
+		map[string]string{
+			"appId":      other.ID.String(),
+			"deliveryId": delivery.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(other.UserID),
+		},
+	)
+
+	s.Equal(http.StatusNotFound, response.Code)
+	s.mockClient.AssertNotCalled(s.T(), "Enqueue", mock.Anything)
+}
+
+func TestHandlerOutboundWebhooksRedeliver(t *testing.T) {
+	suite.Run(t, &OutboundWebhooksRedeliverSuite{})
+}
@@ -56,7 +56,7 @@ func (s *OutboundWebhooksSampleSuite) TestOutboundWebhookSample_Success() {
 	ms.NewResponse("/", &mr)
 	defer ms.Close()
 
-	err := app.NewStore().InsertOutboundWebhook(context.Background(), appl.ID, app.OutboundWebhook{
+	err := app.NewStore().InsertOutboundWebhook(context.Background(), appl.ID, &app.OutboundWebhook{
 		TriggerWhen:    app.TriggerOnPublish,
 		RequestURL:     ms.URL(),
 		RequestMethod:  shttp.MethodGet,
@@ -28,9 +28,16 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodDelete, "/{did:[0-9]+}/deploy-trigger", app.WithApp(handlerAppDeployTriggerDelete)).
 		Handler(shttp.MethodGet, "/{did:[0-9]+}/outbound-webhooks", app.WithApp(handlerOutboundWebhookList)).
 		Handler(shttp.MethodGet, "/{did:[0-9]+}/outbound-webhooks/{wid:[0-9]+}/trigger", app.WithApp(handlerOutboundWebhookSample)).
+		Handler(shttp.MethodGet, "/{did:[0-9]+}/outbound-webhooks/{wid:[0-9]+}/deliveries", app.WithApp(handlerOutboundWebhookDeliveries)).
+		Handler(shttp.MethodPost, "/outbound-webhooks/redeliver", app.WithApp(handlerOutboundWebhookRedeliver)).
 		Handler(shttp.MethodPost, "/outbound-webhooks", app.WithApp(handlerOutboundWebhookInsert)).
 		Handler(shttp.MethodPut, "/outbound-webhooks", app.WithApp(handlerOutboundWebhookUpdate)).
-		Handler(shttp.MethodDelete, "/outbound-webhooks", app.WithApp(handlerOutboundWebhookDelete))
+		Handler(shttp.MethodDelete, "/outbound-webhooks", app.WithApp(handlerOutboundWebhookDelete)).
+		Handler(shttp.MethodGet, "/{did:[0-9]+}/notification-channels", app.WithApp(handlerNotificationChannelList)).
+		Handler(shttp.MethodGet, "/{did:[0-9]+}/notification-channels/{cid:[0-9]+}/trigger", app.WithApp(handlerNotificationChannelSample)).
+		Handler(shttp.MethodPost, "/notification-channels", app.WithApp(handlerNotificationChannelInsert)).
+		Handler(shttp.MethodPut, "/notification-channels", app.WithApp(handlerNotificationChannelUpdate)).
+		Handler(shttp.MethodDelete, "/notification-channels", app.WithApp(handlerNotificationChannelDelete))
 
 	s.NewEndpoint("/hooks").
 		Handler(shttp.MethodGet, "/app/{did:[0-9]+}/deploy/{hash}/{env}", app.WithAppNoAuth(handlerAppHooksDeploy)).
@@ -18,23 +18,30 @@ func (s *ServicesSuite) Test_Services() {
 
 	handlers := []string{
 		"DELETE:/app",
+		"DELETE:/app/notification-channels",
 		"DELETE:/app/outbound-webhooks",
 		"DELETE:/app/{did:[0-9]+}/deploy-trigger",
 		"GET:/app/{did:[0-9]+}",
+		"GET:/app/{did:[0-9]+}/notification-channels",
+		"GET:/app/{did:[0-9]+}/notification-channels/{cid:[0-9]+}/trigger",
 		"GET:/app/{did:[0-9]+}/outbound-webhooks",
+		"GET:/app/{did:[0-9]+}/outbound-webhooks/{wid:[0-9]+}/deliveries",
 		"GET:/app/{did:[0-9]+}/outbound-webhooks/{wid:[0-9]+}/trigger",
 		"GET:/app/{did:[0-9]+}/settings",
 		"GET:/apps",
 		"GET:/deploy",
 		"GET:/hooks/app/{did:[0-9]+}/deploy/{hash}/{env}",
 		"POST:/app",
+		"POST:/app/notification-channels",
 		"POST:/app/outbound-webhooks",
+		"POST:/app/outbound-webhooks/redeliver",
 		"POST:/app/proxy",
 		"POST:/app/webhooks/{provider:github|gitlab|bitbucket}",
 		"POST:/app/webhooks/{provider:github|gitlab|bitbucket}/{secret-id}",
 		"POST:/hooks/app/{did:[0-9]+}/deploy/{hash}/{env}",
 		"PUT:/app",
 		"PUT:/app/deploy-trigger",
+		"PUT:/app/notification-channels",
 		"PUT:/app/outbound-webhooks",
 	}
 
@@ -0,0 +1,107 @@
+package backgroundjob
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// Job statuses. A job is queued until its run date, running while the function
+// is invoked and succeeded or failed once the last attempt is over.
+const (
+	StatusQueued    = "queued"
+	StatusRunning   = "running"
+	StatusSucceeded = "succeeded"
+	StatusFailed    = "failed"
+)
+
+// MaxDelay is the maximum number of seconds a job can be delayed.
+const MaxDelay = 7 * 24 * 60 * 60
+
+// MaxRetries is the maximum number of times a failed job can be retried.
+const MaxRetries = 10
+
+// MaxPayloadSize is the maximum size of the payload in bytes.
+const MaxPayloadSize = 256 * 1024
+
+// MaxDedupKeyLength is the maximum length of the deduplication key.
+const MaxDedupKeyLength = 255
+
+// TokenService is the service of the function tokens that enqueue jobs.
+const TokenService = "jobs"
+
+var methods = []string{
+	shttp.MethodGet,
+	shttp.MethodPost,
+	shttp.MethodPut,
+	shttp.MethodPatch,
+	shttp.MethodDelete,
+}
+
+// Job invokes a function or API route of the environment's published
+// deployment in the background.
+type Job struct {
+	ID          types.ID       `json:"id,string"`
+	AppID       types.ID       `json:"appId,string"`
+	EnvID       types.ID       `json:"envId,string"`
+	Path        string         `json:"path"`
+	Method      string         `json:"method"`
+	Headers     shttp.Headers  `json:"headers,omitempty"`
+	Payload     string         `json:"payload,omitempty"`
+	DedupKey    string         `json:"dedupKey,omitempty"`
+	Status      string         `json:"status"`
+	Attempts    int            `json:"attempts"`
+	MaxRetries  int            `json:"maxRetries"`
+	Result      map[string]any `json:"result,omitempty"`
+	RunAt       utils.Unix     `json:"runAt"`
+	CreatedAt   utils.Unix     `json:"createdAt"`
+	UpdatedAt   utils.Unix     `json:"updatedAt"`
+	CompletedAt utils.Unix     `json:"completedAt"`
+}
+
+// Validate validates the job. The returned map is keyed by the invalid field.
+func (j *Job) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if !strings.HasPrefix(j.Path, "/") {
+		errs["path"] = "Path must start with a slash"
+	}
+
+	if !utils.InSliceStringCS(methods, j.Method) {
+		errs["method"] = fmt.Sprintf("Method must be one of: %s", strings.Join(methods, ", "))
+	}
+
+	if j.MaxRetries < 0 || j.MaxRetries > MaxRetries {
+		errs["maxRetries"] = fmt.Sprintf("Max retries must be between 0 and %d", MaxRetries)
+	}
+
+	if len(j.Payload) > MaxPayloadSize {
+		errs["payload"] = fmt.Sprintf("Payload cannot be larger than %d bytes", MaxPayloadSize)
+	}
+
+	if len(j.DedupKey) > MaxDedupKeyLength {
+		errs["dedupKey"] = fmt.Sprintf("Deduplication key cannot be longer than %d characters", MaxDedupKeyLength)
+	}
+
+	return errs
+}
+
+// IsDone returns true when the job will not run anymore.
+func (j *Job) IsDone() bool {
+	return j.Status == StatusSucceeded || j.Status == StatusFailed
+}
+
+// FunctionContext returns the context that functions receive to enqueue jobs
+// for their own environment.
+func FunctionContext(envID types.ID) map[string]any {
+	return map[string]any{
+		"endpoint": admin.MustConfig().ApiURL("/v1/jobs"),
+		"token":    apikey.FunctionToken(TokenService, envID, time.Now().Add(apikey.FunctionTokenTTL)),
+	}
+}
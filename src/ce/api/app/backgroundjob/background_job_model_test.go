@@ -0,0 +1,38 @@
+package backgroundjob_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stretchr/testify/suite"
+)
+
+type BackgroundJobModelSuite struct {
+	suite.Suite
+}
+
+func (s *BackgroundJobModelSuite) Test_Validate() {
+	job := &backgroundjob.Job{Path: "/api/job", Method: "POST", MaxRetries: 3}
+	s.Empty(job.Validate())
+
+	job = &backgroundjob.Job{
+		Path:       "api/job",
+		Method:     "OPTIONS",
+		MaxRetries: 11,
+		DedupKey:   strings.Repeat("a", 256),
+		Payload:    strings.Repeat("a", backgroundjob.MaxPayloadSize+1),
+	}
+
+	s.Equal(map[string]string{
+		"path":       "Path must start with a slash",
+		"method":     "Method must be one of: GET, POST, PUT, PATCH, DELETE",
+		"maxRetries": "Max retries must be between 0 and 10",
+		"payload":    "Payload cannot be larger than 262144 bytes",
+		"dedupKey":   "Deduplication key cannot be longer than 255 characters",
+	}, job.Validate())
+}
+
+func TestBackgroundJobModel(t *testing.T) {
+	suite.Run(t, &BackgroundJobModelSuite{})
+}
@@ -0,0 +1,267 @@
+package backgroundjob
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/database"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+var stmts = struct {
+	selectJobs    string
+	insertJob     string
+	markRunning   string
+	completeJob   string
+	removeOldJobs string
+}{
+	selectJobs: `
+		SELECT
+			job_id, app_id, env_id, job_path, job_method, job_headers,
+			COALESCE(job_payload, ''), COALESCE(dedup_key, ''), job_status,
+			attempts, max_retries, job_result, run_at, created_at,
+			updated_at, completed_at
+		FROM
+			background_jobs
+		WHERE
+			{{ .where }}
+		ORDER BY
+			job_id DESC
+		LIMIT
+			100;
+	`,
+
+	insertJob: `
+		INSERT INTO background_jobs
+			(app_id, env_id, job_path, job_method, job_headers,
+			 job_payload, dedup_key, max_retries, run_at)
+		VALUES
+			($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9)
+		ON CONFLICT (env_id, dedup_key)
+			WHERE dedup_key IS NOT NULL AND job_status IN ('queued', 'running')
+		DO NOTHING
+		RETURNING
+			job_id, job_status, created_at;
+	`,
+
+	markRunning: `
+		UPDATE
+			background_jobs
+		SET
+			job_status = 'running',
+			attempts = $1,
+			updated_at = timezone('utc', now())
+		WHERE
+			job_id = $2;
+	`,
+
+	completeJob: `
+		UPDATE
+			background_jobs
+		SET
+			job_status = $1,
+			job_result = $2,
+			updated_at = timezone('utc', now()),
+			completed_at = CASE WHEN $1 IN ('succeeded', 'failed') THEN timezone('utc', now()) ELSE NULL END
+		WHERE
+			job_id = $3;
+	`,
+
+	removeOldJobs: `
+		DELETE FROM
+			background_jobs
+		WHERE
+			completed_at < timezone('utc', now()) - INTERVAL '30 days';
+	`,
+}
+
+// Store handles the background jobs.
+type Store struct {
+	*database.Store
+	selectStmt *template.Template
+}
+
+// NewStore returns a new store instance.
+func NewStore() *Store {
+	return &Store{
+		Store: database.NewStore(),
+		selectStmt: template.Must(
+			template.New("select_background_jobs").
+				Parse(stmts.selectJobs),
+		),
+	}
+}
+
+// JobsQuery is the query used to filter the jobs of an environment.
+type JobsQuery struct {
+	EnvID  types.ID
+	JobID  types.ID
+	Status string
+}
+
+// Jobs returns the last 100 jobs of the environment that match the query.
+func (s *Store) Jobs(ctx context.Context, q JobsQuery) ([]*Job, error) {
+	where := []string{"env_id = $1"}
+	params := []any{q.EnvID}
+
+	if q.JobID != 0 {
+		params = append(params, q.JobID)
+		where = append(where, "job_id = $2")
+	}
+
+	if q.Status != "" {
+		params = append(params, q.Status)
+		where = append(where, fmt.Sprintf("job_status = $%d", len(params)))
+	}
+
+	return s.selectJobs(ctx, strings.Join(where, " AND "), params...)
+}
+
+// JobByID returns the job with the given ID.
+func (s *Store) JobByID(ctx context.Context, jobID types.ID) (*Job, error) {
+	jobs, err := s.selectJobs(ctx, "job_id = $1", jobID)
+
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return jobs[0], nil
+}
+
+// PendingJobByDedupKey returns the queued or running job of the environment
+// that has the given deduplication key.
+func (s *Store) PendingJobByDedupKey(ctx context.Context, envID types.ID, key string) (*Job, error) {
+	where := "env_id = $1 AND dedup_key = $2 AND job_status IN ('queued', 'running')"
+	jobs, err := s.selectJobs(ctx, where, envID, key)
+
+	if err != nil || len(jobs) == 0 {
+		return nil, err
+	}
+
+	return jobs[0], nil
+}
+
+// Insert inserts the given job. It returns false when a pending job
+// with the same deduplication key already exists.
+func (s *Store) Insert(ctx context.Context, job *Job) (bool, error) {
+	headers, err := json.Marshal(job.Headers)
+
+	if err != nil {
+		return false, err
+	}
+
+	if job.Headers == nil {
+		headers = []byte("{}")
+	}
+
+	row, err := s.QueryRow(
+		ctx,
+		stmts.insertJob,
+		job.AppID,
+		job.EnvID,
+		job.Path,
+		job.Method,
+		headers,
+		job.Payload,
+		job.DedupKey,
+		job.MaxRetries,
+		job.RunAt,
+	)
+
+	if err != nil {
+		return false, err
+	}
+
+	if err := row.Scan(&job.ID, &job.Status, &job.CreatedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, nil
+		}
+
+		return false, err
+	}
+
+	return true, nil
+}
+
+// MarkRunning marks the job as running and records the attempt number.
+func (s *Store) MarkRunning(ctx context.Context, jobID types.ID, attempt int) error {
+	_, err := s.Exec(ctx, stmts.markRunning, attempt, jobID)
+	return err
+}
+
+// Complete stores the result of the last attempt along with the new status.
+func (s *Store) Complete(ctx context.Context, jobID types.ID, status string, result map[string]any) error {
+	data, err := json.Marshal(result)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Exec(ctx, stmts.completeJob, status, data, jobID)
+	return err
+}
+
+// RemoveOldJobs removes the jobs that completed more than 30 days ago.
+func (s *Store) RemoveOldJobs(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmts.removeOldJobs)
+	return err
+}
+
+func (s *Store) selectJobs(ctx context.Context, where string, params ...any) ([]*Job, error) {
+	var qb strings.Builder
+
+	if err := s.selectStmt.Execute(&qb, map[string]any{"where": where}); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), params...)
+
+	if rows == nil || err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	jobs := []*Job{}
+
+	for rows.Next() {
+		job := &Job{}
+
+		var headers []byte
+		var result []byte
+
+		err := rows.Scan(
+			&job.ID, &job.AppID, &job.EnvID, &job.Path, &job.Method, &headers,
+			&job.Payload, &job.DedupKey, &job.Status,
+			&job.Attempts, &job.MaxRetries, &result, &job.RunAt, &job.CreatedAt,
+			&job.UpdatedAt, &job.CompletedAt,
+		)
+
+		if err != nil {
+			slog.Errorf("error while scanning background job: %s", err.Error())
+			continue
+		}
+
+		if len(headers) > 0 {
+			if err := json.Unmarshal(headers, &job.Headers); err != nil {
+				slog.Errorf("error while unmarshaling background job headers: %s", err.Error())
+			}
+		}
+
+		if len(result) > 0 {
+			if err := json.Unmarshal(result, &job.Result); err != nil {
+				slog.Errorf("error while unmarshaling background job result: %s", err.Error())
+			}
+		}
+
+		jobs = append(jobs, job)
+	}
+
+	return jobs, nil
+}
@@ -0,0 +1,145 @@
+package backgroundjobhandlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type JobEnqueueRequest struct {
+	Path    string          `json:"path"`
+	Method  string          `json:"method"`
+	Headers shttp.Headers   `json:"headers"`
+	Payload json.RawMessage `json:"payload"`
+
+	// Delay is the number of seconds to wait before running the job.
+	Delay int `json:"delay"`
+
+	// MaxRetries is the number of times a failed job is retried.
+	MaxRetries int `json:"maxRetries"`
+
+	// DedupKey prevents enqueuing the same job while a previous
+	// one with the same key is still queued or running.
+	DedupKey string `json:"dedupKey"`
+}
+
+// HandlerJobEnqueue enqueues a job that invokes the given path of the environment's
+// published deployment. When a pending job with the same deduplication key exists,
+// that job is returned instead.
+func HandlerJobEnqueue(req *app.RequestContext) *shttp.Response {
+	data := &JobEnqueueRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	job := &backgroundjob.Job{
+		AppID:      req.App.ID,
+		EnvID:      req.EnvID,
+		Path:       strings.TrimSpace(data.Path),
+		Method:     strings.ToUpper(utils.GetString(data.Method, shttp.MethodPost)),
+		Headers:    data.Headers,
+		DedupKey:   strings.TrimSpace(data.DedupKey),
+		MaxRetries: data.MaxRetries,
+		RunAt:      utils.UnixFrom(time.Now().Add(time.Duration(data.Delay) * time.Second)),
+	}
+
+	job.Payload = payload(data.Payload, job)
+
+	errs := job.Validate()
+
+	if data.Delay < 0 || data.Delay > backgroundjob.MaxDelay {
+		errs["delay"] = fmt.Sprintf("Delay must be between 0 and %d seconds", backgroundjob.MaxDelay)
+	}
+
+	if len(errs) > 0 {
+		return &shttp.Response{
+			Status: http.StatusBadRequest,
+			Data:   map[string]any{"errors": errs},
+		}
+	}
+
+	store := backgroundjob.NewStore()
+	inserted, err := store.Insert(req.Context(), job)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if !inserted {
+		existing, err := store.PendingJobByDedupKey(req.Context(), job.EnvID, job.DedupKey)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		// The pending job completed in the meantime.
+		if existing == nil {
+			return &shttp.Response{
+				Status: http.StatusConflict,
+				Data:   map[string]string{"error": "A job with the same deduplication key has just completed. Try again."},
+			}
+		}
+
+		return &shttp.Response{
+			Status: http.StatusOK,
+			Data:   map[string]any{"job": existing, "duplicate": true},
+		}
+	}
+
+	if err := jobs.EnqueueBackgroundJob(req.Context(), job); err != nil {
+		slog.Errorf("cannot enqueue background job: %v", err)
+
+		if err := store.Complete(req.Context(), job.ID, backgroundjob.StatusFailed, map[string]any{"error": "Job could not be queued"}); err != nil {
+			slog.Errorf("cannot mark background job as failed: %v", err)
+		}
+
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusCreated,
+		Data:   map[string]any{"job": job, "duplicate": false},
+	}
+}
+
+// payload returns the payload to send to the function. Strings are sent as they are,
+// any other JSON value is sent as JSON unless a content type is provided.
+func payload(raw json.RawMessage, job *backgroundjob.Job) string {
+	if len(raw) == 0 || string(raw) == "null" {
+		return ""
+	}
+
+	str := ""
+
+	if err := json.Unmarshal(raw, &str); err == nil {
+		return str
+	}
+
+	if job.Headers == nil {
+		job.Headers = shttp.Headers{}
+	}
+
+	hasContentType := false
+
+	for k := range job.Headers {
+		if strings.EqualFold(k, "content-type") {
+			hasContentType = true
+		}
+	}
+
+	if !hasContentType {
+		job.Headers["content-type"] = "application/json"
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,200 @@
+package backgroundjobhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerJobEnqueueSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn           databasetest.TestDB
+	mockClient     mocks.TaskClient
+	originalClient func() tasks.TaskClient
+}
+
+func (s *HandlerJobEnqueueSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.mockClient = mocks.TaskClient{}
+	s.originalClient = tasks.Client
+	tasks.Client = func() tasks.TaskClient {
+		return &s.mockClient
+	}
+}
+
+func (s *HandlerJobEnqueueSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	tasks.Client = s.originalClient
+}
+
+func (s *HandlerJobEnqueueSuite) request(token string, data map[string]any) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/v1/jobs",
+		data,
+		map[string]string{
+			"Authorization": token,
+		},
+	)
+}
+
+func (s *HandlerJobEnqueueSuite) Test_Success() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	s.mockClient.On("Enqueue", mock.MatchedBy(func(t *asynq.Task) bool {
+		return t.Type() == tasks.BackgroundJobInvoke
+	})).Return(&asynq.TaskInfo{}, nil).Once()
+
+	response := s.request(key.Value, map[string]any{
+		"path":       "/api/emails/send",
+		"payload":    map[string]any{"to": "joe@stormkit.io"},
+		"delay":      60,
+		"maxRetries": 3,
+	})
+
+	s.Equal(http.StatusCreated, response.Code)
+	s.mockClient.AssertExpectations(s.T())
+
+	data := struct {
+		Job       backgroundjob.Job `json:"job"`
+		Duplicate bool              `json:"duplicate"`
+	}{}
+
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.False(data.Duplicate)
+
+	job, err := backgroundjob.NewStore().JobByID(context.Background(), data.Job.ID)
+	s.NoError(err)
+	s.Equal(env.ID, job.EnvID)
+	s.Equal(env.AppID, job.AppID)
+	s.Equal("/api/emails/send", job.Path)
+	s.Equal(shttp.MethodPost, job.Method)
+	s.Equal(`{"to":"joe@stormkit.io"}`, job.Payload)
+	s.Equal("application/json", job.Headers["content-type"])
+	s.Equal(3, job.MaxRetries)
+	s.Equal(backgroundjob.StatusQueued, job.Status)
+	s.WithinDuration(time.Now().Add(time.Minute), job.RunAt.Time, 5*time.Second)
+}
+
+func (s *HandlerJobEnqueueSuite) Test_Success_JobToken() {
+	env := s.MockEnv(nil)
+	token := apikey.FunctionToken(backgroundjob.TokenService, env.ID, time.Now().Add(time.Hour))
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(&asynq.TaskInfo{}, nil).Once()
+
+	response := s.request("Bearer "+token, map[string]any{
+		"path":    "/api/report",
+		"method":  "put",
+		"payload": "plain text",
+	})
+
+	s.Equal(http.StatusCreated, response.Code)
+
+	jobs, err := backgroundjob.NewStore().Jobs(context.Background(), backgroundjob.JobsQuery{EnvID: env.ID})
+	s.NoError(err)
+	s.Len(jobs, 1)
+	s.Equal(shttp.MethodPut, jobs[0].Method)
+	s.Equal("plain text", jobs[0].Payload)
+	s.Empty(jobs[0].Headers)
+}
+
+func (s *HandlerJobEnqueueSuite) Test_InvalidJobToken() {
+	env := s.MockEnv(nil)
+	token := apikey.FunctionToken(backgroundjob.TokenService, env.ID, time.Now().Add(-time.Minute))
+
+	response := s.request("Bearer "+token, map[string]any{"path": "/api/report"})
+	s.Equal(http.StatusForbidden, response.Code)
+}
+
+func (s *HandlerJobEnqueueSuite) Test_Duplicate() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	job := s.MockBackgroundJob(env, map[string]any{"DedupKey": "user-1"})
+
+	response := s.request(key.Value, map[string]any{
+		"path":     "/api/job",
+		"dedupKey": "user-1",
+	})
+
+	s.Equal(http.StatusOK, response.Code)
+	s.mockClient.AssertNotCalled(s.T(), "Enqueue", mock.Anything)
+
+	data := map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Equal(true, data["duplicate"])
+	s.Equal(job.ID.String(), data["job"].(map[string]any)["id"])
+}
+
+func (s *HandlerJobEnqueueSuite) Test_DuplicateOfCompletedJob() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockBackgroundJob(env, map[string]any{"DedupKey": "user-1", "Status": backgroundjob.StatusSucceeded})
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(&asynq.TaskInfo{}, nil).Once()
+
+	response := s.request(key.Value, map[string]any{
+		"path":     "/api/job",
+		"dedupKey": "user-1",
+	})
+
+	s.Equal(http.StatusCreated, response.Code)
+}
+
+func (s *HandlerJobEnqueueSuite) Test_ValidationErrors() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	response := s.request(key.Value, map[string]any{
+		"path":       "api/job",
+		"delay":      backgroundjob.MaxDelay + 1,
+		"maxRetries": 20,
+	})
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(fmt.Sprintf(`{
+		"errors": {
+			"path": "Path must start with a slash",
+			"delay": "Delay must be between 0 and %d seconds",
+			"maxRetries": "Max retries must be between 0 and 10"
+		}
+	}`, backgroundjob.MaxDelay), response.String())
+}
+
+func (s *HandlerJobEnqueueSuite) Test_EnqueueFails() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, fmt.Errorf("redis is down")).Once()
+
+	response := s.request(key.Value, map[string]any{"path": "/api/job"})
+	s.Equal(http.StatusInternalServerError, response.Code)
+
+	jobs, err := backgroundjob.NewStore().Jobs(context.Background(), backgroundjob.JobsQuery{EnvID: env.ID})
+	s.NoError(err)
+	s.Len(jobs, 1)
+	s.Equal(backgroundjob.StatusFailed, jobs[0].Status)
+}
+
+func TestHandlerJobEnqueue(t *testing.T) {
+	suite.Run(t, &HandlerJobEnqueueSuite{})
+}
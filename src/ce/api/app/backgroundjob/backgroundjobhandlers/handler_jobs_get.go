@@ -0,0 +1,32 @@
+package backgroundjobhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// HandlerJobsGet returns the last 100 jobs of the environment. The list can be
+// narrowed down to a single job with the `jobId` query parameter, or to jobs
+// with the given `status`.
+func HandlerJobsGet(req *app.RequestContext) *shttp.Response {
+	jobs, err := backgroundjob.NewStore().Jobs(req.Context(), backgroundjob.JobsQuery{
+		EnvID:  req.EnvID,
+		JobID:  utils.StringToID(req.Query().Get("jobId")),
+		Status: req.Query().Get("status"),
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"jobs": jobs,
+		},
+	}
+}
@@ -0,0 +1,122 @@
+package backgroundjobhandlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob/backgroundjobhandlers"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerJobsGetSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerJobsGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerJobsGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerJobsGetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	job1 := s.MockBackgroundJob(env, map[string]any{"Status": backgroundjob.StatusSucceeded, "Attempts": 1})
+	job2 := s.MockBackgroundJob(env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(backgroundjobhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/jobs?appId=%d&envId=%d", app.ID, env.ID),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.JSONEq(fmt.Sprintf(`{
+		"jobs": [
+			{
+				"id": "%s",
+				"appId": "%s",
+				"envId": "%s",
+				"path": "/api/job",
+				"method": "POST",
+				"status": "queued",
+				"attempts": 0,
+				"maxRetries": 0,
+				"runAt": %d,
+				"createdAt": %d,
+				"updatedAt": null,
+				"completedAt": null
+			},
+			{
+				"id": "%s",
+				"appId": "%s",
+				"envId": "%s",
+				"path": "/api/job",
+				"method": "POST",
+				"status": "succeeded",
+				"attempts": 1,
+				"maxRetries": 0,
+				"runAt": %d,
+				"createdAt": %d,
+				"updatedAt": null,
+				"completedAt": null
+			}
+		]
+	}`,
+		job2.ID, app.ID, env.ID, job2.RunAt.Unix(), job2.CreatedAt.Unix(),
+		job1.ID, app.ID, env.ID, job1.RunAt.Unix(), job1.CreatedAt.Unix(),
+	), response.String())
+}
+
+func (s *HandlerJobsGetSuite) Test_SingleJob_APIKey() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	job := s.MockBackgroundJob(env)
+	s.MockBackgroundJob(env)
+
+	// A job of another environment is never returned
+	other := s.MockBackgroundJob(s.MockEnv(nil, map[string]any{"Name": "staging"}))
+
+	for _, id := range []string{job.ID.String(), other.ID.String()} {
+		response := shttptest.RequestWithHeaders(
+			shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+			shttp.MethodGet,
+			"/v1/jobs?jobId="+id,
+			nil,
+			map[string]string{
+				"Authorization": key.Value,
+			},
+		)
+
+		s.Equal(http.StatusOK, response.Code)
+
+		if id == job.ID.String() {
+			s.Contains(response.String(), fmt.Sprintf(`"id":"%s"`, job.ID))
+			s.Contains(response.String(), `"jobs":[{`)
+		} else {
+			s.JSONEq(`{ "jobs": [] }`, response.String())
+		}
+	}
+}
+
+func TestHandlerJobsGet(t *testing.T) {
+	suite.Run(t, &HandlerJobsGetSuite{})
+}
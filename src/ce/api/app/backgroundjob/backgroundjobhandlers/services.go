@@ -0,0 +1,16 @@
+package backgroundjobhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// Services sets the handlers for this service.
+func Services(r *shttp.Router) *shttp.Service {
+	s := r.NewService()
+
+	s.NewEndpoint("/apps").
+		Handler(shttp.MethodGet, "/jobs", app.WithApp(HandlerJobsGet, &app.Opts{Env: true}))
+
+	return s
+}
@@ -0,0 +1,28 @@
+package backgroundjobhandlers_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob/backgroundjobhandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServicesSuite struct {
+	suite.Suite
+}
+
+func (s *ServicesSuite) Test_Services() {
+	services := shttp.NewRouter().RegisterService(backgroundjobhandlers.Services)
+	s.NotNil(services)
+
+	handlers := []string{
+		"GET:/apps/jobs",
+	}
+
+	s.Equal(handlers, services.HandlerKeys())
+}
+
+func TestServices(t *testing.T) {
+	suite.Run(t, &ServicesSuite{})
+}
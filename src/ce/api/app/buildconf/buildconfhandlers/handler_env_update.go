@@ -111,5 +111,13 @@ func handlerEnvUpdate(req *app.RequestContext) *shttp.Response {
 		return shttp.Error(err)
 	}
 
+	if changed := buildconf.ChangedVars(env.Data.Vars, cnf.Data.Vars); len(changed) > 0 {
+		app.TriggerEvent(req.Context(), app.TriggerOnEnvVarsChanged, app.OutboundWebhookSettings{
+			AppID:           req.App.ID,
+			EnvironmentName: cnf.Env,
+			ChangedVars:     changed,
+		})
+	}
+
 	return shttp.OK()
 }
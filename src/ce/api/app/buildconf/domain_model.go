@@ -32,6 +32,10 @@ type PingResult struct {
 	Status     int        `json:"status"`
 	Error      string     `json:"error,omitempty"`
 	LastPingAt utils.Unix `json:"lastPingAt"`
+
+	// CertExpiresAt is the unix timestamp of the expiry date of the
+	// certificate that the domain served.
+	CertExpiresAt int64 `json:"certExpiresAt,omitempty"`
 }
 
 // Scan implements the Scanner interface.
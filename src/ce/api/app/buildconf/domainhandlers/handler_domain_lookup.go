@@ -71,6 +71,8 @@ func handlerDomainLookup(req *app.RequestContext) *shttp.Response {
 		if err := store.VerifyDomain(req.Context(), domain.ID); err != nil {
 			return shttp.Error(err)
 		}
+
+		app.TriggerDomainEvent(req.Context(), app.TriggerOnDomainVerified, domain, app.OutboundWebhookSettings{})
 	}
 
 	var tls *certInfo
@@ -3,12 +3,14 @@ package buildconf
 import (
 	"encoding/json"
 	"regexp"
+	"sort"
 	"strings"
 
 	"github.com/dlclark/regexp2"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/redirects"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/model"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
@@ -195,33 +197,145 @@ func (env *Env) Validate() *shttperr.ValidationError {
 		}
 	}
 
+	if env.Data != nil && env.Data.Rollout != nil {
+		if rerr := env.Data.Rollout.Validate(); rerr != nil {
+			err.SetError("rollout", rerr.Error())
+		}
+	}
+
+	if env.Data != nil && env.Data.Retention != nil {
+		if rerr := env.Data.Retention.Validate(); rerr != nil {
+			err.SetError("retention", rerr.Error())
+		}
+	}
+
+	if env.Data != nil && env.Data.Protection != nil {
+		if perr := env.Data.Protection.Validate(); perr != nil {
+			err.SetError("protection", perr.Error())
+		}
+	}
+
+	if env.Data != nil && len(env.Data.AgentLabels) > 0 {
+		if labels, lerr := buildagent.NormalizeLabels(env.Data.AgentLabels); lerr != nil {
+			err.SetError("agentLabels", lerr.Error())
+		} else {
+			env.Data.AgentLabels = labels
+		}
+	}
+
+	if env.Data != nil {
+		for _, w := range env.Data.FreezeWindows {
+			if werr := w.Validate(); werr != nil {
+				err.SetError("freezeWindows", werr.Error())
+				break
+			}
+		}
+	}
+
 	return err.ToError()
 }
 
+// RolloutStep is a single step of a progressive rollout.
+type RolloutStep struct {
+	Percentage float64 `json:"percentage"` // Percentage of the traffic that is routed to the new deployment.
+	Interval   int     `json:"interval"`   // Interval in minutes to wait before moving on to the next step.
+}
+
+// RolloutPlan describes how a new deployment is gradually published.
+// The new deployment is rolled back when any of the thresholds is exceeded.
+type RolloutPlan struct {
+	Steps             []RolloutStep `json:"steps"`
+	MaxErrorRate      float64       `json:"maxErrorRate,omitempty"`      // Maximum percentage of 5xx responses. Zero disables the check.
+	MaxFunctionErrors int64         `json:"maxFunctionErrors,omitempty"` // Maximum number of function errors within a step. Zero disables the check.
+	MinRequests       int64         `json:"minRequests,omitempty"`       // Minimum number of requests before the error rate is evaluated.
+}
+
+// Validate validates the rollout plan.
+func (p *RolloutPlan) Validate() error {
+	if len(p.Steps) == 0 {
+		return ErrRolloutMissingSteps
+	}
+
+	prev := float64(0)
+
+	for _, step := range p.Steps {
+		if step.Percentage <= prev || step.Percentage > 100 {
+			return ErrRolloutInvalidPercentage
+		}
+
+		if step.Interval < 0 {
+			return ErrRolloutInvalidInterval
+		}
+
+		prev = step.Percentage
+	}
+
+	if prev != 100 {
+		return ErrRolloutInvalidPercentage
+	}
+
+	if p.MaxErrorRate < 0 || p.MaxErrorRate > 100 || p.MaxFunctionErrors < 0 || p.MinRequests < 0 {
+		return ErrRolloutInvalidThreshold
+	}
+
+	return nil
+}
+
+// ProtectionPolicy describes the approvals that a publish to a protected
+// environment requires before going live.
+type ProtectionPolicy struct {
+	// RequiredApprovals is the number of team admins or owners that need to
+	// approve a publish. The requester cannot approve their own publish.
+	RequiredApprovals int `json:"requiredApprovals"`
+}
+
+// Validate validates the protection policy.
+func (p *ProtectionPolicy) Validate() error {
+	if p.RequiredApprovals < 1 {
+		return ErrProtectionInvalidApprovals
+	}
+
+	return nil
+}
+
+// IsProtected returns true when publishes require approvals.
+func (bc *BuildConf) IsProtected() bool {
+	return bc != nil && bc.Protection != nil
+}
+
 type StatusCheck struct {
 	Name        string `json:"name"`
 	Cmd         string `json:"cmd"`
 	Description string `json:"description"`
+	Timeout     int    `json:"timeout,omitempty"`  // Timeout in seconds. Zero means no timeout.
+	Optional    bool   `json:"optional,omitempty"` // Optional checks do not fail the deployment.
+	Group       string `json:"group,omitempty"`    // Checks within the same group run in parallel.
+	Report      string `json:"report,omitempty"`   // Path to a JUnit XML or TAP report, relative to the working directory.
 }
 
 // BuildConf is the struct that represents the JSON data
 type BuildConf struct {
-	PreviewLinks  null.Bool            `json:"previewLinks,omitempty"`  // Whether preview links are enabled or not.
-	Redirects     []redirects.Redirect `json:"redirects,omitempty"`     // The redirects defined from UI. When defined, this one will take precedence over redirects file.
-	APIFolder     string               `json:"apiFolder,omitempty"`     // Path to api folder (from repository root).
-	APIPathPrefix string               `json:"apiPathPrefix,omitempty"` // Path prefix in the URL that will be used to call api functions, default: /api
-	RedirectsFile string               `json:"redirectsFile,omitempty"` // Path to the redirects file.
-	ErrorFile     string               `json:"errorFile,omitempty"`     // When specified, we'll load this file instead of the default 404.html or error.html
-	Headers       string               `json:"headers,omitempty"`       // Custom headers set from the UI.
-	HeadersFile   string               `json:"headersFile,omitempty"`   // Path to the headers file. The path is relative to working dir.
-	DistFolder    string               `json:"distFolder,omitempty"`    // DistFolder is the client dist folder.
-	ServerFolder  string               `json:"serverFolder,omitempty"`  // The server folder to upload to the server side
-	Cmd           string               `json:"cmd,omitempty"`           // Deprecated. Declared only for retro compability.
-	InstallCmd    string               `json:"installCmd,omitempty"`    // The install command to install the dependencies.
-	BuildCmd      string               `json:"buildCmd,omitempty"`      // The build command to build the application.
-	ServerCmd     string               `json:"serverCmd,omitempty"`     // The command to spawn the server. This is a self-hosted only feature.
-	Vars          map[string]string    `json:"vars,omitempty"`          // The environment variables that will be injected to the application.
-	StatusChecks  []StatusCheck        `json:"statusChecks,omitempty"`  // StatusChecks is an array of commands that will be executed after the deployment is complete.
+	PreviewLinks  null.Bool              `json:"previewLinks,omitempty"`  // Whether preview links are enabled or not.
+	Redirects     []redirects.Redirect   `json:"redirects,omitempty"`     // The redirects defined from UI. When defined, this one will take precedence over redirects file.
+	APIFolder     string                 `json:"apiFolder,omitempty"`     // Path to api folder (from repository root).
+	APIPathPrefix string                 `json:"apiPathPrefix,omitempty"` // Path prefix in the URL that will be used to call api functions, default: /api
+	RedirectsFile string                 `json:"redirectsFile,omitempty"` // Path to the redirects file.
+	ErrorFile     string                 `json:"errorFile,omitempty"`     // When specified, we'll load this file instead of the default 404.html or error.html
+	Headers       string                 `json:"headers,omitempty"`       // Custom headers set from the UI.
+	HeadersFile   string                 `json:"headersFile,omitempty"`   // Path to the headers file. The path is relative to working dir.
+	DistFolder    string                 `json:"distFolder,omitempty"`    // DistFolder is the client dist folder.
+	ServerFolder  string                 `json:"serverFolder,omitempty"`  // The server folder to upload to the server side
+	Cmd           string                 `json:"cmd,omitempty"`           // Deprecated. Declared only for retro compability.
+	InstallCmd    string                 `json:"installCmd,omitempty"`    // The install command to install the dependencies.
+	BuildCmd      string                 `json:"buildCmd,omitempty"`      // The build command to build the application.
+	ServerCmd     string                 `json:"serverCmd,omitempty"`     // The command to spawn the server. This is a self-hosted only feature.
+	Vars          map[string]string      `json:"vars,omitempty"`          // The environment variables that will be injected to the application.
+	StatusChecks  []StatusCheck          `json:"statusChecks,omitempty"`  // StatusChecks is an array of commands that will be executed after the deployment is complete.
+	Rollout       *RolloutPlan           `json:"rollout,omitempty"`       // Rollout is the plan used to gradually publish new deployments.
+	FreezeWindows []FreezeWindow         `json:"freezeWindows,omitempty"` // FreezeWindows are the periods during which deployments cannot be published.
+	Protection    *ProtectionPolicy      `json:"protection,omitempty"`    // Protection requires publishes to be approved before they go live.
+	Retention     *admin.RetentionPolicy `json:"retention,omitempty"`     // Retention overwrites the default retention policy of the instance.
+	AgentLabels   []string               `json:"agentLabels,omitempty"`   // AgentLabels restricts the builds to the build agents that have all of the labels.
 }
 
 type InterpolatedVarsOpts struct {
@@ -230,6 +344,10 @@ type InterpolatedVarsOpts struct {
 	DeploymentID string
 	Env          string
 	EnvID        string
+
+	// Secrets are the decrypted secrets of the environment. They take precedence
+	// over the variables with the same name, and are never interpolated.
+	Secrets map[string]string
 }
 
 func systmVars() map[string]string {
@@ -242,10 +360,14 @@ func systmVars() map[string]string {
 
 func (bc *BuildConf) InterpolatedVars(opts InterpolatedVarsOpts) map[string]string {
 	conf := admin.MustConfig()
-	vars := bc.Vars
+	vars := map[string]string{}
 
-	if vars == nil {
-		vars = map[string]string{}
+	for k, v := range bc.Vars {
+		vars[k] = v
+	}
+
+	for k, v := range opts.Secrets {
+		vars[k] = v
 	}
 
 	vars["SK_APP_ID"] = opts.AppID
@@ -261,6 +383,10 @@ func (bc *BuildConf) InterpolatedVars(opts InterpolatedVarsOpts) map[string]stri
 	// Interpolate variables like:
 	// NEXT_PUBLIC_SITE_URL = $SK_ENV_URL
 	for k, v := range vars {
+		if _, ok := opts.Secrets[k]; ok {
+			continue
+		}
+
 		// If the variable starts with $,
 		// we assume it's a reference to another variable
 		// and we replace it with the value of that variable.
@@ -282,6 +408,28 @@ func (bc *BuildConf) InterpolatedVars(opts InterpolatedVarsOpts) map[string]stri
 	return vars
 }
 
+// ChangedVars returns the sorted names of the variables that were added,
+// removed or updated. Values are never returned.
+func ChangedVars(old, new map[string]string) []string {
+	changed := []string{}
+
+	for k, v := range new {
+		if ov, ok := old[k]; !ok || ov != v {
+			changed = append(changed, k)
+		}
+	}
+
+	for k := range old {
+		if _, ok := new[k]; !ok {
+			changed = append(changed, k)
+		}
+	}
+
+	sort.Strings(changed)
+
+	return changed
+}
+
 // DefaultConfig returns the default configuration.
 func DefaultConfig(appID types.ID) *Env {
 	env := &Env{
@@ -47,6 +47,52 @@ func (s *EnvModelSuite) TestConfig_Validation() {
 	s.Equal(res.String(), exp)
 }
 
+func (s *EnvModelSuite) TestRolloutPlan_Validation() {
+	plan := &buildconf.RolloutPlan{}
+	s.Equal(buildconf.ErrRolloutMissingSteps, plan.Validate())
+
+	plan.Steps = []buildconf.RolloutStep{{Percentage: 25, Interval: 10}, {Percentage: 5, Interval: 10}, {Percentage: 100}}
+	s.Equal(buildconf.ErrRolloutInvalidPercentage, plan.Validate())
+
+	plan.Steps = []buildconf.RolloutStep{{Percentage: 5, Interval: 10}, {Percentage: 50, Interval: 10}}
+	s.Equal(buildconf.ErrRolloutInvalidPercentage, plan.Validate())
+
+	plan.Steps = []buildconf.RolloutStep{{Percentage: 5, Interval: -1}, {Percentage: 100}}
+	s.Equal(buildconf.ErrRolloutInvalidInterval, plan.Validate())
+
+	plan.Steps = []buildconf.RolloutStep{{Percentage: 5, Interval: 10}, {Percentage: 100}}
+	plan.MaxErrorRate = 150
+	s.Equal(buildconf.ErrRolloutInvalidThreshold, plan.Validate())
+
+	plan.MaxErrorRate = 2.5
+	s.NoError(plan.Validate())
+
+	config := &buildconf.Env{Env: "production", Branch: "main", Data: &buildconf.BuildConf{
+		Rollout: &buildconf.RolloutPlan{},
+	}}
+
+	res := shttp.Error(config.Validate())
+	exp := fmt.Sprintf(`{"errors":{"rollout":"%s"}}`, buildconf.ErrRolloutMissingSteps.Error())
+	s.Equal(exp, res.String())
+}
+
+func (s *EnvModelSuite) TestProtectionPolicy_Validation() {
+	s.Equal(buildconf.ErrProtectionInvalidApprovals, (&buildconf.ProtectionPolicy{}).Validate())
+	s.NoError((&buildconf.ProtectionPolicy{RequiredApprovals: 2}).Validate())
+
+	s.False((&buildconf.BuildConf{}).IsProtected())
+	s.True((&buildconf.BuildConf{Protection: &buildconf.ProtectionPolicy{RequiredApprovals: 1}}).IsProtected())
+}
+
+func (s *EnvModelSuite) TestChangedVars() {
+	old := map[string]string{"NODE_ENV": "production", "API_KEY": "abc", "REMOVED": "1"}
+	new := map[string]string{"NODE_ENV": "production", "API_KEY": "def", "ADDED": "1"}
+
+	s.Equal([]string{"ADDED", "API_KEY", "REMOVED"}, buildconf.ChangedVars(old, new))
+	s.Empty(buildconf.ChangedVars(old, old))
+	s.Empty(buildconf.ChangedVars(nil, map[string]string{}))
+}
+
 func TestEnvModelSuite(t *testing.T) {
 	suite.Run(t, &EnvModelSuite{})
 }
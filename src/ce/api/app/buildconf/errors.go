@@ -8,16 +8,26 @@ import (
 
 // Errors list
 var (
-	ErrMissingEnv             = shttperr.New(http.StatusBadRequest, "Environment is missing", "env-missing")
-	ErrInvalidEnv             = shttperr.New(http.StatusBadRequest, "Environment can only contain alphanumeric characters and hypens.", "env-invalid")
-	ErrInvalidEnvDoubleHypens = shttperr.New(http.StatusBadRequest, "Double hypens (--) are not allowed as they are reserved for Stormkit.", "env-invalid")
-	ErrInvalidBranch          = shttperr.New(http.StatusBadRequest, "Branch name is required and can only contain following characters: alphanumeric, -, +, /, ., and =", "branch-invalid") // See https://wincent.com/wiki/Legal_Git_branch_names for more details.
-	ErrCantRenameProd         = shttperr.New(http.StatusBadRequest, "Production environments cannot be renamed. Create a new environment instead.", "rename-prod")
-	ErrProdEnvironmentInUse   = shttperr.New(http.StatusBadRequest, "Cannot create another production environment", "prod-already-exists")
-	ErrCantRemoveProd         = shttperr.New(http.StatusBadRequest, "Cannot remove production environments", "remove-prod")
-	ErrDomainInvalidFormat    = shttperr.New(http.StatusBadRequest, "Domain format is not correct", "invalid-domain")
-	ErrDomainInvalidToken     = shttperr.New(http.StatusBadRequest, "The verification token is not found. Please start the verification process by setting a domain first.", "invalid-token")
-	ErrInvalidPercentage      = shttperr.New(http.StatusBadRequest, "The sum of percentages should be 100 in order to publish.", "invalid-percentage")
-	ErrLambdaAlreadyExists    = shttperr.New(http.StatusBadRequest, "Lambda function name already exists.", "lambda-already-exists")
-	ErrDuplicateEnvName       = shttperr.New(http.StatusBadRequest, "Environment name is duplicate. Choose a different name.", "duplicate-env")
+	ErrMissingEnv                  = shttperr.New(http.StatusBadRequest, "Environment is missing", "env-missing")
+	ErrInvalidEnv                  = shttperr.New(http.StatusBadRequest, "Environment can only contain alphanumeric characters and hypens.", "env-invalid")
+	ErrInvalidEnvDoubleHypens      = shttperr.New(http.StatusBadRequest, "Double hypens (--) are not allowed as they are reserved for Stormkit.", "env-invalid")
+	ErrInvalidBranch               = shttperr.New(http.StatusBadRequest, "Branch name is required and can only contain following characters: alphanumeric, -, +, /, ., and =", "branch-invalid") // See https://wincent.com/wiki/Legal_Git_branch_names for more details.
+	ErrCantRenameProd              = shttperr.New(http.StatusBadRequest, "Production environments cannot be renamed. Create a new environment instead.", "rename-prod")
+	ErrProdEnvironmentInUse        = shttperr.New(http.StatusBadRequest, "Cannot create another production environment", "prod-already-exists")
+	ErrCantRemoveProd              = shttperr.New(http.StatusBadRequest, "Cannot remove production environments", "remove-prod")
+	ErrDomainInvalidFormat         = shttperr.New(http.StatusBadRequest, "Domain format is not correct", "invalid-domain")
+	ErrDomainInvalidToken          = shttperr.New(http.StatusBadRequest, "The verification token is not found. Please start the verification process by setting a domain first.", "invalid-token")
+	ErrInvalidPercentage           = shttperr.New(http.StatusBadRequest, "The sum of percentages should be 100 in order to publish.", "invalid-percentage")
+	ErrLambdaAlreadyExists         = shttperr.New(http.StatusBadRequest, "Lambda function name already exists.", "lambda-already-exists")
+	ErrDuplicateEnvName            = shttperr.New(http.StatusBadRequest, "Environment name is duplicate. Choose a different name.", "duplicate-env")
+	ErrRolloutMissingSteps         = shttperr.New(http.StatusBadRequest, "Rollout plan requires at least one step.", "rollout-missing-steps")
+	ErrRolloutInvalidPercentage    = shttperr.New(http.StatusBadRequest, "Rollout percentages should be increasing, between 0 and 100, and the last step should be 100.", "rollout-invalid-percentage")
+	ErrRolloutInvalidInterval      = shttperr.New(http.StatusBadRequest, "Rollout intervals cannot be negative.", "rollout-invalid-interval")
+	ErrFreezeWindowMissingName     = shttperr.New(http.StatusBadRequest, "Freeze window name is required.", "freeze-window-missing-name")
+	ErrFreezeWindowInvalidCron     = shttperr.New(http.StatusBadRequest, "Freeze window cron expression is invalid.", "freeze-window-invalid-cron")
+	ErrFreezeWindowInvalidDuration = shttperr.New(http.StatusBadRequest, "Recurring freeze windows require a positive duration in minutes.", "freeze-window-invalid-duration")
+	ErrFreezeWindowInvalidTimezone = shttperr.New(http.StatusBadRequest, "Freeze window timezone is invalid.", "freeze-window-invalid-timezone")
+	ErrFreezeWindowInvalidRange    = shttperr.New(http.StatusBadRequest, "One-off freeze windows require a start date that is before the end date.", "freeze-window-invalid-range")
+	ErrRolloutInvalidThreshold     = shttperr.New(http.StatusBadRequest, "Rollout thresholds cannot be negative and the error rate cannot exceed 100.", "rollout-invalid-threshold")
+	ErrProtectionInvalidApprovals  = shttperr.New(http.StatusBadRequest, "Protected environments require at least one approval.", "protection-invalid-approvals")
 )
@@ -0,0 +1,97 @@
+package buildconf
+
+import (
+	"time"
+
+	"github.com/adhocore/gronx"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// FreezeWindow is a period of time during which deployments cannot be published.
+// A freeze window is either a one-off window between StartsAt and EndsAt, or a
+// recurring window that starts on each tick of the Cron expression and lasts for
+// Duration minutes.
+type FreezeWindow struct {
+	Name     string     `json:"name"`
+	StartsAt utils.Unix `json:"startsAt,omitempty"` // Start of a one-off window.
+	EndsAt   utils.Unix `json:"endsAt,omitempty"`   // End of a one-off window.
+	Cron     string     `json:"cron,omitempty"`     // Start of a recurring window, e.g. "0 18 * * 5" for Fridays at 18:00.
+	Duration int        `json:"duration,omitempty"` // Duration of a recurring window in minutes.
+	Timezone string     `json:"timezone,omitempty"` // Timezone used to evaluate the cron expression. Defaults to UTC.
+}
+
+// Validate validates the freeze window.
+func (w *FreezeWindow) Validate() error {
+	if w.Name == "" {
+		return ErrFreezeWindowMissingName
+	}
+
+	if w.Cron != "" {
+		if !gronx.IsValid(w.Cron) {
+			return ErrFreezeWindowInvalidCron
+		}
+
+		if w.Duration <= 0 {
+			return ErrFreezeWindowInvalidDuration
+		}
+
+		if _, err := time.LoadLocation(w.Timezone); err != nil {
+			return ErrFreezeWindowInvalidTimezone
+		}
+
+		return nil
+	}
+
+	if !w.StartsAt.Valid || !w.EndsAt.Valid || !w.StartsAt.Before(w.EndsAt.Time) {
+		return ErrFreezeWindowInvalidRange
+	}
+
+	return nil
+}
+
+// ActiveUntil returns the end of the freeze window when the window
+// is active at the given time.
+func (w *FreezeWindow) ActiveUntil(now time.Time) (time.Time, bool) {
+	if w.Cron == "" {
+		if w.StartsAt.Valid && w.EndsAt.Valid && !now.Before(w.StartsAt.Time) && now.Before(w.EndsAt.Time) {
+			return w.EndsAt.Time, true
+		}
+
+		return time.Time{}, false
+	}
+
+	loc, err := time.LoadLocation(w.Timezone)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	start, err := gronx.PrevTickBefore(w.Cron, now.In(loc), true)
+
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	end := start.Add(time.Duration(w.Duration) * time.Minute)
+
+	if now.Before(end) {
+		return end, true
+	}
+
+	return time.Time{}, false
+}
+
+// ActiveFreezeWindow returns the freeze window that is active at the given time.
+func (bc *BuildConf) ActiveFreezeWindow(now time.Time) (*FreezeWindow, time.Time) {
+	if bc == nil {
+		return nil, time.Time{}
+	}
+
+	for i := range bc.FreezeWindows {
+		if until, ok := bc.FreezeWindows[i].ActiveUntil(now); ok {
+			return &bc.FreezeWindows[i], until
+		}
+	}
+
+	return nil, time.Time{}
+}
@@ -0,0 +1,99 @@
+package buildconf_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type FreezeWindowSuite struct {
+	suite.Suite
+}
+
+func (s *FreezeWindowSuite) Test_Validate() {
+	w := &buildconf.FreezeWindow{}
+	s.Equal(buildconf.ErrFreezeWindowMissingName, w.Validate())
+
+	w.Name = "Black Friday"
+	s.Equal(buildconf.ErrFreezeWindowInvalidRange, w.Validate())
+
+	w.StartsAt = utils.UnixFrom(time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC))
+	w.EndsAt = utils.UnixFrom(time.Date(2026, 11, 26, 0, 0, 0, 0, time.UTC))
+	s.Equal(buildconf.ErrFreezeWindowInvalidRange, w.Validate())
+
+	w.EndsAt = utils.UnixFrom(time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC))
+	s.NoError(w.Validate())
+
+	w = &buildconf.FreezeWindow{Name: "Weekends", Cron: "invalid"}
+	s.Equal(buildconf.ErrFreezeWindowInvalidCron, w.Validate())
+
+	w.Cron = "0 18 * * 5"
+	s.Equal(buildconf.ErrFreezeWindowInvalidDuration, w.Validate())
+
+	w.Duration = 60 * 62
+	w.Timezone = "Mars/Olympus"
+	s.Equal(buildconf.ErrFreezeWindowInvalidTimezone, w.Validate())
+
+	w.Timezone = "Europe/Berlin"
+	s.NoError(w.Validate())
+}
+
+func (s *FreezeWindowSuite) Test_ActiveUntil_OneOff() {
+	w := &buildconf.FreezeWindow{
+		Name:     "Black Friday",
+		StartsAt: utils.UnixFrom(time.Date(2026, 11, 27, 0, 0, 0, 0, time.UTC)),
+		EndsAt:   utils.UnixFrom(time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC)),
+	}
+
+	until, active := w.ActiveUntil(time.Date(2026, 11, 28, 12, 0, 0, 0, time.UTC))
+	s.True(active)
+	s.Equal(w.EndsAt.Time, until)
+
+	_, active = w.ActiveUntil(time.Date(2026, 11, 30, 0, 0, 0, 0, time.UTC))
+	s.False(active)
+}
+
+func (s *FreezeWindowSuite) Test_ActiveUntil_Recurring() {
+	// Fridays 18:00 until Monday 08:00 in Berlin (CET, UTC+1)
+	w := &buildconf.FreezeWindow{
+		Name:     "Weekends",
+		Cron:     "0 18 * * 5",
+		Duration: 62 * 60,
+		Timezone: "Europe/Berlin",
+	}
+
+	// Saturday, 2026-11-28 10:00 UTC
+	until, active := w.ActiveUntil(time.Date(2026, 11, 28, 10, 0, 0, 0, time.UTC))
+	s.True(active)
+	s.Equal(time.Date(2026, 11, 30, 7, 0, 0, 0, time.UTC), until.UTC())
+
+	// Friday, 2026-11-27 16:59 UTC is 17:59 in Berlin
+	_, active = w.ActiveUntil(time.Date(2026, 11, 27, 16, 59, 0, 0, time.UTC))
+	s.False(active)
+
+	// Monday, 2026-11-30 07:00 UTC is 08:00 in Berlin
+	_, active = w.ActiveUntil(time.Date(2026, 11, 30, 7, 0, 0, 0, time.UTC))
+	s.False(active)
+}
+
+func (s *FreezeWindowSuite) Test_ActiveFreezeWindow() {
+	bc := &buildconf.BuildConf{
+		FreezeWindows: []buildconf.FreezeWindow{
+			{Name: "Maintenance", Cron: "0 3 * * *", Duration: 30},
+		},
+	}
+
+	w, _ := bc.ActiveFreezeWindow(time.Date(2026, 11, 28, 3, 15, 0, 0, time.UTC))
+	s.NotNil(w)
+	s.Equal("Maintenance", w.Name)
+
+	w, _ = bc.ActiveFreezeWindow(time.Date(2026, 11, 28, 4, 0, 0, 0, time.UTC))
+	s.Nil(w)
+}
+
+func TestFreezeWindowSuite(t *testing.T) {
+	suite.Run(t, &FreezeWindowSuite{})
+}
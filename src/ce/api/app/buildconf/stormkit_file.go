@@ -0,0 +1,320 @@
+package buildconf
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/adhocore/gronx"
+	"github.com/goccy/go-yaml"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/redirects"
+)
+
+// StormkitFileNames are the file names that are looked up, in order,
+// to find the repository level configuration.
+var StormkitFileNames = []string{
+	"stormkit.config.yml",
+	"stormkit.config.yaml",
+	"stormkit.config.json",
+	"stormkit.config.toml",
+}
+
+// StormkitFileTrigger is a function trigger that is declared in the config file.
+type StormkitFileTrigger struct {
+	Cron     string            `json:"cron"`
+	TimeZone string            `json:"timeZone,omitempty"` // IANA time zone, defaults to UTC
+	Method   string            `json:"method,omitempty"`
+	URL      string            `json:"url,omitempty"`
+	Path     string            `json:"path,omitempty"` // Invokes a published function instead of the url
+	Payload  string            `json:"payload,omitempty"`
+	Headers  map[string]string `json:"headers,omitempty"`
+	Status   *bool             `json:"status,omitempty"` // Defaults to true
+
+	Timeout           int    `json:"timeout,omitempty"`           // Seconds to wait for a response
+	MaxRetries        int    `json:"maxRetries,omitempty"`        // Number of retries for failed runs
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"` // allow | forbid | replace
+}
+
+// StormkitFileConfig contains the settings that can be declared in the config
+// file, either at the top level or for a specific environment.
+type StormkitFileConfig struct {
+	InstallCmd    string                `json:"installCmd,omitempty"`
+	BuildCmd      string                `json:"buildCmd,omitempty"`
+	ServerCmd     string                `json:"serverCmd,omitempty"`
+	DistFolder    string                `json:"distFolder,omitempty"`
+	ServerFolder  string                `json:"serverFolder,omitempty"`
+	APIFolder     string                `json:"apiFolder,omitempty"`
+	HeadersFile   string                `json:"headersFile,omitempty"`
+	RedirectsFile string                `json:"redirectsFile,omitempty"`
+	Headers       string                `json:"headers,omitempty"`
+	Redirects     []redirects.Redirect  `json:"redirects,omitempty"`
+	StatusChecks  []StatusCheck         `json:"statusChecks,omitempty"`
+	Vars          map[string]string     `json:"vars,omitempty"`
+	Triggers      []StormkitFileTrigger `json:"triggers,omitempty"`
+}
+
+// StormkitFile represents the stormkit.config.yml file that lives in the repository.
+//
+// The precedence rules, from highest to lowest, are:
+//
+//   - `environments.<name>` settings of the environment that is being deployed
+//   - Top-level settings of the file
+//   - Settings configured from the UI or the API
+//
+// Environment variables are the exception: variables configured from the UI or the
+// API always win, so that secrets never need to be committed. The file can only
+// provide defaults for them.
+type StormkitFile struct {
+	StormkitFileConfig
+
+	// Name is the name of the file that was parsed, relative to the repository.
+	Name string `json:"name,omitempty"`
+
+	// Environments contains per environment overrides, keyed by the environment name.
+	Environments map[string]StormkitFileConfig `json:"environments,omitempty"`
+}
+
+// FindStormkitFile looks for a config file in the given directories
+// and returns the path of the first one that is found.
+func FindStormkitFile(dirs ...string) string {
+	for _, dir := range dirs {
+		for _, name := range StormkitFileNames {
+			if _, err := os.Stat(filepath.Join(dir, name)); err == nil {
+				return filepath.Join(dir, name)
+			}
+		}
+	}
+
+	return ""
+}
+
+// ParseStormkitFile parses the given config file. The format is
+// determined by the file extension.
+func ParseStormkitFile(name string, data []byte) (*StormkitFile, error) {
+	var err error
+
+	switch filepath.Ext(name) {
+	case ".yml", ".yaml":
+		if len(bytes.TrimSpace(data)) == 0 {
+			data = []byte("{}")
+		} else if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, fmt.Errorf("%s: invalid yaml: %s", name, err.Error())
+		}
+	case ".toml":
+		values := map[string]any{}
+
+		if err := toml.Unmarshal(data, &values); err != nil {
+			return nil, fmt.Errorf("%s: invalid toml: %s", name, err.Error())
+		}
+
+		if data, err = json.Marshal(values); err != nil {
+			return nil, err
+		}
+	case ".json":
+	default:
+		return nil, fmt.Errorf("%s: unsupported file format", name)
+	}
+
+	file := &StormkitFile{}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(file); err != nil {
+		return nil, fmt.Errorf("%s: %s", name, strings.TrimPrefix(err.Error(), "json: "))
+	}
+
+	file.Name = name
+
+	if err := file.Validate(); err != nil {
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// Validate validates the config file and returns an error
+// describing all problems that were found.
+func (f *StormkitFile) Validate() error {
+	errs := f.StormkitFileConfig.validate("")
+
+	for env, cnf := range f.Environments {
+		if env == "" {
+			errs = append(errs, "environments: environment name cannot be empty")
+			continue
+		}
+
+		errs = append(errs, cnf.validate(fmt.Sprintf("environments.%s.", env))...)
+	}
+
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return fmt.Errorf("%s is invalid:\n- %s", f.Name, strings.Join(errs, "\n- "))
+}
+
+func (c StormkitFileConfig) validate(prefix string) []string {
+	errs := []string{}
+
+	for i, check := range c.StatusChecks {
+		if strings.TrimSpace(check.Cmd) == "" {
+			errs = append(errs, fmt.Sprintf("%sstatusChecks[%d].cmd is required", prefix, i))
+		}
+
+		if check.Timeout < 0 {
+			errs = append(errs, fmt.Sprintf("%sstatusChecks[%d].timeout cannot be negative", prefix, i))
+		}
+	}
+
+	for i, redirect := range c.Redirects {
+		if redirect.From == "" {
+			errs = append(errs, fmt.Sprintf("%sredirects[%d].from is required", prefix, i))
+		}
+	}
+
+	for i, trigger := range c.Triggers {
+		if !gronx.New().IsValid(trigger.Cron) {
+			errs = append(errs, fmt.Sprintf("%striggers[%d].cron is not a valid cron expression", prefix, i))
+		}
+
+		if _, err := functiontrigger.Location(trigger.TimeZone); err != nil {
+			errs = append(errs, fmt.Sprintf("%striggers[%d].timeZone is not a valid IANA time zone", prefix, i))
+		}
+
+		if trigger.Path != "" {
+			if trigger.URL != "" {
+				errs = append(errs, fmt.Sprintf("%striggers[%d] cannot have both a url and a path", prefix, i))
+			} else if !strings.HasPrefix(trigger.Path, "/") {
+				errs = append(errs, fmt.Sprintf("%striggers[%d].path must start with a slash", prefix, i))
+			}
+		} else if parsed, err := url.Parse(trigger.URL); err != nil || parsed.Host == "" ||
+			!strings.EqualFold(parsed.Scheme, "http") && !strings.EqualFold(parsed.Scheme, "https") {
+			errs = append(errs, fmt.Sprintf("%striggers[%d].url must be an http or https url", prefix, i))
+		}
+
+		policy := functiontrigger.Options{
+			Timeout:           trigger.Timeout,
+			MaxRetries:        trigger.MaxRetries,
+			ConcurrencyPolicy: trigger.ConcurrencyPolicy,
+		}.ValidatePolicy()
+
+		for _, field := range []string{"timeout", "maxRetries", "concurrencyPolicy"} {
+			if msg, ok := policy[field]; ok {
+				errs = append(errs, fmt.Sprintf("%striggers[%d].%s is invalid: %s", prefix, i, field, msg))
+			}
+		}
+	}
+
+	return errs
+}
+
+// Config returns the settings for the given environment
+// with the environment overrides applied.
+func (f *StormkitFile) Config(env string) StormkitFileConfig {
+	cnf := f.StormkitFileConfig
+	override, ok := f.Environments[env]
+
+	if !ok {
+		return cnf
+	}
+
+	cnf.InstallCmd = firstNonEmpty(override.InstallCmd, cnf.InstallCmd)
+	cnf.BuildCmd = firstNonEmpty(override.BuildCmd, cnf.BuildCmd)
+	cnf.ServerCmd = firstNonEmpty(override.ServerCmd, cnf.ServerCmd)
+	cnf.DistFolder = firstNonEmpty(override.DistFolder, cnf.DistFolder)
+	cnf.ServerFolder = firstNonEmpty(override.ServerFolder, cnf.ServerFolder)
+	cnf.APIFolder = firstNonEmpty(override.APIFolder, cnf.APIFolder)
+	cnf.HeadersFile = firstNonEmpty(override.HeadersFile, cnf.HeadersFile)
+	cnf.RedirectsFile = firstNonEmpty(override.RedirectsFile, cnf.RedirectsFile)
+	cnf.Headers = firstNonEmpty(override.Headers, cnf.Headers)
+
+	if override.Redirects != nil {
+		cnf.Redirects = override.Redirects
+	}
+
+	if override.StatusChecks != nil {
+		cnf.StatusChecks = override.StatusChecks
+	}
+
+	if override.Triggers != nil {
+		cnf.Triggers = override.Triggers
+	}
+
+	if override.Vars != nil {
+		vars := map[string]string{}
+
+		for k, v := range cnf.Vars {
+			vars[k] = v
+		}
+
+		for k, v := range override.Vars {
+			vars[k] = v
+		}
+
+		cnf.Vars = vars
+	}
+
+	return cnf
+}
+
+// Apply returns a copy of the given build configuration with the
+// settings of the file applied for the given environment.
+func (f *StormkitFile) Apply(conf *BuildConf, env string) *BuildConf {
+	merged := BuildConf{}
+
+	if conf != nil {
+		merged = *conf
+	}
+
+	cnf := f.Config(env)
+
+	merged.InstallCmd = firstNonEmpty(cnf.InstallCmd, merged.InstallCmd)
+	merged.BuildCmd = firstNonEmpty(cnf.BuildCmd, merged.BuildCmd)
+	merged.ServerCmd = firstNonEmpty(cnf.ServerCmd, merged.ServerCmd)
+	merged.DistFolder = firstNonEmpty(cnf.DistFolder, merged.DistFolder)
+	merged.ServerFolder = firstNonEmpty(cnf.ServerFolder, merged.ServerFolder)
+	merged.APIFolder = firstNonEmpty(cnf.APIFolder, merged.APIFolder)
+	merged.HeadersFile = firstNonEmpty(cnf.HeadersFile, merged.HeadersFile)
+	merged.RedirectsFile = firstNonEmpty(cnf.RedirectsFile, merged.RedirectsFile)
+	merged.Headers = firstNonEmpty(cnf.Headers, merged.Headers)
+
+	if cnf.Redirects != nil {
+		merged.Redirects = cnf.Redirects
+	}
+
+	if cnf.StatusChecks != nil {
+		merged.StatusChecks = cnf.StatusChecks
+	}
+
+	if len(cnf.Vars) > 0 {
+		vars := map[string]string{}
+
+		for k, v := range cnf.Vars {
+			vars[k] = v
+		}
+
+		for k, v := range merged.Vars {
+			vars[k] = v
+		}
+
+		merged.Vars = vars
+	}
+
+	return &merged
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,150 @@
+package buildconf_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/redirects"
+	"github.com/stretchr/testify/suite"
+)
+
+type StormkitFileSuite struct {
+	suite.Suite
+}
+
+const stormkitFileYAML = `
+buildCmd: npm run build
+distFolder: dist
+vars:
+  NODE_ENV: production
+  API_URL: https://api.example.org
+redirects:
+  - from: /old
+    to: /new
+    status: 301
+statusChecks:
+  - name: E2E
+    cmd: npm run e2e
+    timeout: 300
+environments:
+  staging:
+    buildCmd: npm run build:staging
+    vars:
+      API_URL: https://staging.example.org
+`
+
+const stormkitFileTOML = `
+buildCmd = "npm run build"
+distFolder = 'dist'
+
+[vars]
+NODE_ENV = "production" # Inline comment
+API_URL = "https://api.example.org"
+
+[[redirects]]
+from = "/old"
+to = "/new"
+status = 301
+
+[[statusChecks]]
+name = "E2E"
+cmd = "npm run e2e"
+timeout = 300
+
+[environments.staging]
+buildCmd = "npm run build:staging"
+vars = { API_URL = "https://staging.example.org" }
+`
+
+func (s *StormkitFileSuite) expected() *buildconf.StormkitFile {
+	return &buildconf.StormkitFile{
+		StormkitFileConfig: buildconf.StormkitFileConfig{
+			BuildCmd:   "npm run build",
+			DistFolder: "dist",
+			Vars: map[string]string{
+				"NODE_ENV": "production",
+				"API_URL":  "https://api.example.org",
+			},
+			Redirects: []redirects.Redirect{
+				{From: "/old", To: "/new", Status: 301},
+			},
+			StatusChecks: []buildconf.StatusCheck{
+				{Name: "E2E", Cmd: "npm run e2e", Timeout: 300},
+			},
+		},
+		Environments: map[string]buildconf.StormkitFileConfig{
+			"staging": {
+				BuildCmd: "npm run build:staging",
+				Vars: map[string]string{
+					"API_URL": "https://staging.example.org",
+				},
+			},
+		},
+	}
+}
+
+func (s *StormkitFileSuite) Test_Parse_YAML() {
+	file, err := buildconf.ParseStormkitFile("stormkit.config.yml", []byte(stormkitFileYAML))
+	expected := s.expected()
+	expected.Name = "stormkit.config.yml"
+
+	s.NoError(err)
+	s.Equal(expected, file)
+}
+
+func (s *StormkitFileSuite) Test_Parse_TOML() {
+	file, err := buildconf.ParseStormkitFile("stormkit.config.toml", []byte(stormkitFileTOML))
+	expected := s.expected()
+	expected.Name = "stormkit.config.toml"
+
+	s.NoError(err)
+	s.Equal(expected, file)
+}
+
+func (s *StormkitFileSuite) Test_Parse_SchemaErrors() {
+	_, err := buildconf.ParseStormkitFile("stormkit.config.json", []byte(`{ "buildCommand": "npm run build" }`))
+	s.EqualError(err, `stormkit.config.json: unknown field "buildCommand"`)
+
+	_, err = buildconf.ParseStormkitFile("stormkit.config.yml", []byte("statusChecks:\n  - name: E2E\ntriggers:\n  - cron: invalid\n    url: ftp://example.org\n    concurrencyPolicy: queue\n  - cron: \"0 * * * *\"\n    path: cron\n    timeZone: Mars/Olympus\n"))
+	s.EqualError(err, "stormkit.config.yml is invalid:\n"+
+		"- statusChecks[0].cmd is required\n"+
+		"- triggers[0].cron is not a valid cron expression\n"+
+		"- triggers[0].url must be an http or https url\n"+
+		"- triggers[0].concurrencyPolicy is invalid: Concurrency policy must be one of: allow, forbid, replace\n"+
+		"- triggers[1].timeZone is not a valid IANA time zone\n"+
+		"- triggers[1].path must start with a slash")
+}
+
+func (s *StormkitFileSuite) Test_Apply() {
+	file := s.expected()
+
+	merged := file.Apply(&buildconf.BuildConf{
+		BuildCmd:   "npm run build:ui",
+		InstallCmd: "npm ci",
+		Vars: map[string]string{
+			"API_URL": "https://ui.example.org",
+			"SECRET":  "my-secret",
+		},
+	}, "staging")
+
+	s.Equal(&buildconf.BuildConf{
+		BuildCmd:   "npm run build:staging",
+		InstallCmd: "npm ci",
+		DistFolder: "dist",
+		Redirects: []redirects.Redirect{
+			{From: "/old", To: "/new", Status: 301},
+		},
+		StatusChecks: []buildconf.StatusCheck{
+			{Name: "E2E", Cmd: "npm run e2e", Timeout: 300},
+		},
+		Vars: map[string]string{
+			"NODE_ENV": "production",
+			"API_URL":  "https://ui.example.org",
+			"SECRET":   "my-secret",
+		},
+	}, merged)
+}
+
+func TestStormkitFileSuite(t *testing.T) {
+	suite.Run(t, &StormkitFileSuite{})
+}
@@ -0,0 +1,274 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const (
+	ApprovalStatusPending   = "pending"
+	ApprovalStatusApproved  = "approved"
+	ApprovalStatusRejected  = "rejected"
+	ApprovalStatusCancelled = "cancelled"
+)
+
+const (
+	ReviewDecisionApprove = "approve"
+	ReviewDecisionReject  = "reject"
+)
+
+// ApprovalReview is the decision of a team member on a publish approval.
+type ApprovalReview struct {
+	UserID    types.ID   `json:"userId,string"`
+	Decision  string     `json:"decision"`
+	Comment   string     `json:"comment,omitempty"`
+	CreatedAt utils.Unix `json:"createdAt"`
+}
+
+// PublishApproval is a publish to a protected environment that waits for
+// the approval of team admins before going live.
+type PublishApproval struct {
+	ID                types.ID         `json:"id,string"`
+	AppID             types.ID         `json:"appId,string"`
+	EnvID             types.ID         `json:"envId,string"`
+	Config            []PublishConfig  `json:"config"`
+	RequiredApprovals int              `json:"requiredApprovals"`
+	Status            string           `json:"status"`
+	RequestedBy       types.ID         `json:"requestedBy,string"`
+	Reviews           []ApprovalReview `json:"reviews"`
+	UpdatedAt         utils.Unix       `json:"updatedAt"`
+	CreatedAt         utils.Unix       `json:"createdAt"`
+}
+
+// Approvals returns the number of approvals that the publish received.
+func (a *PublishApproval) Approvals() int {
+	count := 0
+
+	for _, r := range a.Reviews {
+		if r.Decision == ReviewDecisionApprove {
+			count = count + 1
+		}
+	}
+
+	return count
+}
+
+// DeploymentIDs returns the ids of the deployments that are published.
+func (a *PublishApproval) DeploymentIDs() []string {
+	ids := []string{}
+
+	for _, c := range a.Config {
+		ids = append(ids, c.DeploymentID.String())
+	}
+
+	return ids
+}
+
+// RequestApproval creates a publish approval for the protected environment and
+// notifies the approvers. Pending approvals of the environment are superseded
+// by the new one.
+func RequestApproval(ctx context.Context, env *buildconf.Env, config []PublishConfig, requestedBy types.ID) (*PublishApproval, error) {
+	if !env.Data.IsProtected() {
+		return nil, ErrApprovalNotRequired
+	}
+
+	store := NewStore()
+
+	if err := store.CancelPublishApprovals(ctx, env.ID); err != nil {
+		return nil, err
+	}
+
+	a := &PublishApproval{
+		AppID:             env.AppID,
+		EnvID:             env.ID,
+		Config:            config,
+		RequiredApprovals: env.Data.Protection.RequiredApprovals,
+		Status:            ApprovalStatusPending,
+		RequestedBy:       requestedBy,
+		Reviews:           []ApprovalReview{},
+	}
+
+	if err := store.InsertPublishApproval(ctx, a); err != nil {
+		return nil, err
+	}
+
+	notifyApproval(ctx, a, env, app.TriggerOnApprovalRequest)
+	return a, nil
+}
+
+// ReviewApproval records the decision of a team member. A rejection rejects the
+// publish, while the publish goes live once it receives the required number of
+// approvals. Team members cannot review their own publishes, and a member who
+// reviews again replaces their previous decision. Approvals are counted in the
+// database, and only the review that approves the publish publishes it.
+func ReviewApproval(ctx context.Context, a *PublishApproval, review ApprovalReview) error {
+	if a.Status != ApprovalStatusPending {
+		return ErrApprovalNotPending
+	}
+
+	if review.UserID == a.RequestedBy {
+		return ErrApprovalSelfReview
+	}
+
+	store := NewStore()
+	status, err := store.ReviewPublishApproval(ctx, a.ID, review)
+
+	if err != nil {
+		return err
+	}
+
+	if stored, err := store.PublishApprovalByID(ctx, a.ID); err == nil && stored != nil {
+		a.Reviews = stored.Reviews
+	}
+
+	if status == ApprovalStatusPending {
+		return nil
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, a.EnvID)
+
+	if err != nil {
+		return err
+	}
+
+	if env == nil {
+		return ErrApprovalNotPending
+	}
+
+	if status == ApprovalStatusApproved {
+		if err := publishApproved(ctx, a, env); err != nil {
+			// Let the reviewers approve again once the problem is solved.
+			if err := store.ReopenPublishApproval(ctx, a.ID); err != nil {
+				slog.Errorf("error while reopening approval %s: %v", a.ID.String(), err)
+			}
+
+			return err
+		}
+	}
+
+	a.Status = status
+	notifyApproval(ctx, a, env, app.TriggerOnApprovalDecision)
+	return nil
+}
+
+// publishApproved publishes the deployments of an approved publish. When a single
+// deployment is published and the environment has a rollout plan, the deployment
+// is rolled out progressively.
+func publishApproved(ctx context.Context, a *PublishApproval, env *buildconf.Env) error {
+	store := NewStore()
+
+	if len(a.Config) == 1 && a.Config[0].Percentage == 100 && env.Data != nil && env.Data.Rollout != nil {
+		d, err := store.DeploymentByID(ctx, a.Config[0].DeploymentID)
+
+		if err != nil {
+			return err
+		}
+
+		if d != nil {
+			_, err = StartRollout(ctx, d, *env.Data.Rollout)
+			return err
+		}
+	}
+
+	if err := Publish(ctx, publishSettingsFromConfig(a.EnvID, a.Config)); err != nil {
+		return err
+	}
+
+	return store.CancelRollouts(ctx, a.EnvID, "Published after approval")
+}
+
+// notifyApproval dispatches the outbound webhooks of the trigger and emails the
+// team members. Approvers are notified of new requests, and the requester is
+// notified of the decision.
+func notifyApproval(ctx context.Context, a *PublishApproval, env *buildconf.Env, trigger string) {
+	var deploymentID types.ID
+
+	if len(a.Config) > 0 {
+		deploymentID = a.Config[0].DeploymentID
+	}
+
+	cnf := admin.MustConfig()
+
+	app.TriggerEvent(ctx, trigger, app.OutboundWebhookSettings{
+		AppID:                  a.AppID,
+		DeploymentID:           deploymentID,
+		EnvironmentName:        env.Name,
+		DeploymentLogsEndpoint: cnf.DeploymentLogsURL(a.AppID, deploymentID),
+		ApprovalID:             a.ID.String(),
+		ApprovalStatus:         a.Status,
+	})
+
+	if err := emailApproval(ctx, a, env, trigger); err != nil {
+		slog.Errorf("error while sending approval emails for approval %s: %v", a.ID.String(), err)
+	}
+}
+
+// emailApproval emails the team members using the SMTP configuration of the
+// instance. Nothing is sent when SMTP is not enabled.
+func emailApproval(ctx context.Context, a *PublishApproval, env *buildconf.Env, trigger string) error {
+	config := mailer.InstanceConfig()
+
+	if config == nil {
+		return nil
+	}
+
+	appl, err := app.NewStore().AppByID(ctx, a.AppID)
+
+	if err != nil || appl == nil {
+		return err
+	}
+
+	members, err := team.NewStore().TeamMembers(ctx, appl.TeamID)
+
+	if err != nil {
+		return err
+	}
+
+	to := []string{}
+
+	for _, m := range members {
+		if trigger == app.TriggerOnApprovalRequest && m.UserID != a.RequestedBy && team.HasWriteAccess(m.Role) {
+			to = append(to, m.Email)
+		}
+
+		if trigger == app.TriggerOnApprovalDecision && m.UserID == a.RequestedBy {
+			to = append(to, m.Email)
+		}
+	}
+
+	if len(to) == 0 {
+		return nil
+	}
+
+	link := admin.MustConfig().AppURL(path.Join("apps", a.AppID.String(), "environments", env.ID.String(), "deployments"))
+	ids := strings.Join(a.DeploymentIDs(), ", ")
+	email := mailer.Email{
+		Subject: fmt.Sprintf("Publish to %s requires approval", env.Name),
+		Body:    fmt.Sprintf("<p>Deployment %s is waiting for %d approval(s) before it is published to %s.</p><p><a href=\"%s\">Review the request</a></p>", ids, a.RequiredApprovals, env.Name, link),
+	}
+
+	if trigger == app.TriggerOnApprovalDecision {
+		email.Subject = fmt.Sprintf("Publish to %s is %s", env.Name, a.Status)
+		email.Body = fmt.Sprintf("<p>The publish of deployment %s to %s is %s.</p><p><a href=\"%s\">View the deployments</a></p>", ids, env.Name, a.Status, link)
+	}
+
+	// Send each email separately, so that recipients do not see each other.
+	for _, addr := range to {
+		if err := mailer.Send(config, email, []string{addr}); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
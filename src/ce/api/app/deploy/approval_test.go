@@ -0,0 +1,197 @@
+package deploy_test
+
+import (
+	"context"
+	"net/smtp"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type ApprovalSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn       databasetest.TestDB
+	env        *factory.MockEnv
+	deployment *factory.MockDeployment
+}
+
+func (s *ApprovalSuite) BeforeTest(suiteName, testName string) {
+	s.conn = databasetest.InitTx(suiteName + "_" + testName)
+	s.Factory = factory.New(s.conn)
+
+	cache := &mocks.CacheInterface{}
+	cache.On("Reset", mock.Anything).Return(nil)
+	appcache.DefaultCacheService = cache
+
+	s.env = s.MockEnv(nil, map[string]any{
+		"Data": &buildconf.BuildConf{
+			Protection: &buildconf.ProtectionPolicy{RequiredApprovals: 2},
+		},
+	})
+
+	s.deployment = s.MockDeployment(s.env, map[string]any{"ExitCode": null.IntFrom(0)})
+}
+
+func (s *ApprovalSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	appcache.DefaultCacheService = nil
+	admin.ResetCache(context.Background())
+	mailer.SendMail = smtp.SendMail
+}
+
+func (s *ApprovalSuite) request() *deploy.PublishApproval {
+	a, err := deploy.RequestApproval(context.Background(), s.env.Env, []deploy.PublishConfig{
+		{DeploymentID: s.deployment.ID, Percentage: 100},
+	}, types.ID(1))
+
+	s.NoError(err)
+	return a
+}
+
+func (s *ApprovalSuite) published() bool {
+	var count int
+	s.NoError(s.conn.QueryRow(`SELECT COUNT(*) FROM deployments_published WHERE deployment_id = $1`, s.deployment.ID).Scan(&count))
+	return count > 0
+}
+
+func (s *ApprovalSuite) review(a *deploy.PublishApproval, userID types.ID, decision string) error {
+	return deploy.ReviewApproval(context.Background(), a, deploy.ApprovalReview{
+		UserID:   userID,
+		Decision: decision,
+		Comment:  "Looks good",
+	})
+}
+
+func (s *ApprovalSuite) Test_RequestApproval_SupersedesPending() {
+	first := s.request()
+	second := s.request()
+
+	approvals, err := deploy.NewStore().PublishApprovals(context.Background(), deploy.PublishApprovalFilters{EnvID: s.env.ID})
+	s.NoError(err)
+	s.Len(approvals, 2)
+	s.Equal(second.ID, approvals[0].ID)
+	s.Equal(deploy.ApprovalStatusPending, approvals[0].Status)
+	s.Equal(first.ID, approvals[1].ID)
+	s.Equal(deploy.ApprovalStatusCancelled, approvals[1].Status)
+}
+
+func (s *ApprovalSuite) Test_RequestApproval_NotProtected() {
+	env := s.MockEnv(nil)
+	_, err := deploy.RequestApproval(context.Background(), env.Env, nil, types.ID(1))
+	s.Equal(deploy.ErrApprovalNotRequired, err)
+}
+
+func (s *ApprovalSuite) Test_ReviewApproval_Publishes() {
+	a := s.request()
+
+	s.Equal(deploy.ErrApprovalSelfReview, s.review(a, types.ID(1), deploy.ReviewDecisionApprove))
+
+	s.NoError(s.review(a, types.ID(2), deploy.ReviewDecisionApprove))
+	s.False(s.published())
+
+	// Reviewing twice does not count as another approval
+	s.NoError(s.review(a, types.ID(2), deploy.ReviewDecisionApprove))
+	s.False(s.published())
+
+	s.NoError(s.review(a, types.ID(3), deploy.ReviewDecisionApprove))
+	s.True(s.published())
+
+	stored, err := deploy.NewStore().PublishApprovalByID(context.Background(), a.ID)
+	s.NoError(err)
+	s.Equal(deploy.ApprovalStatusApproved, stored.Status)
+	s.Len(stored.Reviews, 2)
+	s.Equal("Looks good", stored.Reviews[0].Comment)
+
+	s.Equal(deploy.ErrApprovalNotPending, s.review(stored, types.ID(4), deploy.ReviewDecisionApprove))
+}
+
+func (s *ApprovalSuite) Test_ReviewApproval_ConcurrentReviewers() {
+	a := s.request()
+
+	// Each reviewer loaded the approval before the other one reviewed it.
+	first, second := *a, *a
+
+	s.NoError(s.review(&first, types.ID(2), deploy.ReviewDecisionApprove))
+	s.NoError(s.review(&second, types.ID(3), deploy.ReviewDecisionApprove))
+	s.True(s.published())
+	s.Equal(deploy.ApprovalStatusApproved, second.Status)
+
+	// A stale copy cannot publish again.
+	stale := *a
+	s.Equal(deploy.ErrApprovalNotPending, s.review(&stale, types.ID(4), deploy.ReviewDecisionApprove))
+}
+
+func (s *ApprovalSuite) Test_ReviewApproval_Rejects() {
+	a := s.request()
+
+	s.NoError(s.review(a, types.ID(2), deploy.ReviewDecisionReject))
+	s.False(s.published())
+
+	stored, err := deploy.NewStore().PublishApprovalByID(context.Background(), a.ID)
+	s.NoError(err)
+	s.Equal(deploy.ApprovalStatusRejected, stored.Status)
+}
+
+func (s *ApprovalSuite) Test_AutoPublish_RequestsApproval() {
+	d := s.MockDeployment(s.env, map[string]any{
+		"ExitCode":      null.IntFrom(0),
+		"ShouldPublish": true,
+	})
+
+	s.NoError(deploy.AutoPublishIfNecessary(context.Background(), d.Deployment))
+
+	approvals, err := deploy.NewStore().PublishApprovals(context.Background(), deploy.PublishApprovalFilters{
+		EnvID:  s.env.ID,
+		Status: deploy.ApprovalStatusPending,
+	})
+
+	s.NoError(err)
+	s.Len(approvals, 1)
+	s.Equal(d.ID, approvals[0].Config[0].DeploymentID)
+}
+
+func (s *ApprovalSuite) Test_RequestApproval_EmailsApproversWithInstanceSMTP() {
+	ctx := context.Background()
+	requester := s.MockUser()
+	recipients := []string{}
+
+	s.NoError(admin.Store().UpsertConfig(ctx, admin.InstanceConfig{
+		SMTPConfig: &admin.SMTPConfig{
+			Host:     "smtp.example.org",
+			Username: "notifications@example.org",
+			Password: "secret",
+			From:     "Stormkit <notifications@example.org>",
+		},
+	}))
+
+	mailer.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		s.Equal("smtp.example.org:587", addr)
+		s.Contains(string(msg), "From: Stormkit <notifications@example.org>")
+		recipients = append(recipients, to...)
+		return nil
+	}
+
+	_, err := deploy.RequestApproval(ctx, s.env.Env, []deploy.PublishConfig{
+		{DeploymentID: s.deployment.ID, Percentage: 100},
+	}, requester.ID)
+
+	s.NoError(err)
+	s.Equal([]string{s.GetUser().PrimaryEmail()}, recipients)
+}
+
+func TestApprovalSuite(t *testing.T) {
+	suite.Run(t, &ApprovalSuite{})
+}
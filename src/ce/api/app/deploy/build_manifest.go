@@ -67,6 +67,7 @@ type Redirect = redirects.Redirect
 type CDNFile struct {
 	Name    string            `json:"fileName"`
 	Headers map[string]string `json:"headers,omitempty"`
+	Size    int64             `json:"size,omitempty"` // Size of the file in bytes
 }
 
 type APIFile struct {
@@ -0,0 +1,502 @@
+package deploycommands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+// DefaultFreezeDuration is the duration of freeze windows created by the
+// freeze command, when no duration is given.
+const DefaultFreezeDuration = time.Hour
+
+// MaxFreezeDuration is the maximum duration of freeze windows created by
+// the freeze command.
+const MaxFreezeDuration = 7 * 24 * time.Hour
+
+var (
+	errEnvNotFound        = errors.New("the environment is not found")
+	errDeploymentNotFound = errors.New("the deployment is not found")
+	errNoDeployment       = errors.New("this pull request has no successful deployment yet")
+	errMissingBranch      = errors.New("the branch of the pull request is unknown")
+	errMissingTargetEnv   = errors.New("the target environment is required, e.g. `--to=production`")
+	errInvalidDuration    = errors.New("the duration must be between 1 minute and 7 days, e.g. `--duration=90`")
+)
+
+type executor struct {
+	ctx    context.Context
+	req    Request
+	app    *app.App
+	member *team.Member
+}
+
+// env returns the environment that is specified by the given flag, or the first
+// argument. It falls back to the default environment of the app.
+func (ex *executor) env(flag string) (*buildconf.Env, error) {
+	name := ex.req.Command.Flags[flag]
+
+	if name == "" && len(ex.req.Command.Arguments) > 0 {
+		name = ex.req.Command.Arguments[0]
+	}
+
+	if name == "" {
+		name = ex.app.DefaultEnv
+	}
+
+	env, err := buildconf.NewStore().Environment(ex.ctx, ex.app.ID, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if env == nil {
+		return nil, errEnvNotFound
+	}
+
+	return env, nil
+}
+
+// deployments returns the deployments of the pull request, the latest first.
+func (ex *executor) deployments(includeLogs bool) ([]*deploy.Deployment, error) {
+	return deploy.NewStore().PullRequestDeployments(ex.ctx, ex.app.ID, ex.req.PullRequestNumber, includeLogs)
+}
+
+// deployment returns the deployment whose id is given as the first argument.
+// It falls back to the latest deployment of the pull request, which is
+// successful when onlySuccessful is true.
+func (ex *executor) deployment(onlySuccessful bool) (*deploy.Deployment, error) {
+	args := ex.req.Command.Arguments
+
+	if len(args) > 0 {
+		id, err := strconv.ParseInt(strings.TrimPrefix(args[0], "#"), 10, 64)
+
+		if err != nil {
+			return nil, errDeploymentNotFound
+		}
+
+		d, err := deploy.NewStore().MyDeployment(ex.ctx, &deploy.DeploymentsQueryFilters{
+			DeploymentID: types.ID(id),
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		if d == nil || d.AppID != ex.app.ID {
+			return nil, errDeploymentNotFound
+		}
+
+		return d, nil
+	}
+
+	ds, err := ex.deployments(false)
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, d := range ds {
+		if !onlySuccessful || d.Status() == "success" {
+			return d, nil
+		}
+	}
+
+	return nil, errNoDeployment
+}
+
+// start builds the deployment and returns the reply message.
+func (ex *executor) start(d *deploy.Deployment) (string, error) {
+	if err := deployservice.New().Deploy(ex.ctx, ex.app, d); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"Deployment `%s` started on the `%s` environment. [View logs](%s)",
+		d.ID.String(), d.Env, admin.MustConfig().DeploymentLogsURL(ex.app.ID, d.ID),
+	), nil
+}
+
+// deploy builds the head branch of the pull request.
+func (ex *executor) deploy() (string, error) {
+	if ex.req.Branch == "" {
+		return "", errMissingBranch
+	}
+
+	env, err := ex.env("env")
+
+	if err != nil {
+		return "", err
+	}
+
+	d := deploy.New(ex.app.ID)
+	d.Branch = ex.req.Branch
+	d.Env = env.Name
+	d.EnvID = env.ID
+	d.EnvBranchName = env.Branch
+	d.CheckoutRepo = ex.req.CheckoutRepo
+	d.IsFork = ex.req.IsFork
+	d.BuildConfig = env.Data
+	d.ShouldPublish = ex.req.Command.Flags["publish"] == "true" && !ex.req.IsFork
+	d.PullRequestNumber = null.NewInt(ex.req.PullRequestNumber, true)
+	d.Commit.ID = null.NewString(ex.req.CommitSha, ex.req.CommitSha != "")
+
+	return ex.start(d)
+}
+
+// redeploy builds the commit of the latest deployment of the pull request again.
+func (ex *executor) redeploy() (string, error) {
+	ds, err := ex.deployments(false)
+
+	if err != nil {
+		return "", err
+	}
+
+	envName := ex.req.Command.Flags["env"]
+
+	for _, prev := range ds {
+		if envName != "" && prev.Env != envName {
+			continue
+		}
+
+		env, err := buildconf.NewStore().EnvironmentByID(ex.ctx, prev.EnvID)
+
+		if err != nil {
+			return "", err
+		}
+
+		if env == nil {
+			return "", errEnvNotFound
+		}
+
+		d := deploy.New(ex.app.ID)
+		d.Branch = prev.Branch
+		d.Env = env.Name
+		d.EnvID = env.ID
+		d.EnvBranchName = env.Branch
+		d.CheckoutRepo = utils.GetString(prev.CheckoutRepo, ex.req.CheckoutRepo)
+		d.IsFork = ex.req.IsFork
+		d.BuildConfig = env.Data
+		d.PullRequestNumber = prev.PullRequestNumber
+		d.Commit.ID = prev.Commit.ID
+
+		return ex.start(d)
+	}
+
+	return "", errors.New("this pull request has no deployment to redeploy")
+}
+
+// publish publishes the deployment to all of the traffic of its environment.
+// Protected environments receive an approval request instead.
+func (ex *executor) publish() (string, error) {
+	d, err := ex.deployment(true)
+
+	if err != nil {
+		return "", err
+	}
+
+	if d.Status() != "success" {
+		return "", errors.New("only successful deployments can be published")
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(ex.ctx, d.EnvID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if env == nil {
+		return "", errEnvNotFound
+	}
+
+	return ex.publishToEnv(env, d.ID, "Published from pull request")
+}
+
+// publishToEnv publishes the deployment, or requests an approval when the
+// environment is protected.
+func (ex *executor) publishToEnv(env *buildconf.Env, deploymentID types.ID, reason string) (string, error) {
+	if env.Data.IsProtected() {
+		config := []deploy.PublishConfig{{DeploymentID: deploymentID, Percentage: 100}}
+
+		if _, err := deploy.RequestApproval(ex.ctx, env, config, ex.member.UserID); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf(
+			"The `%s` environment is protected. Publishing deployment `%s` is waiting for the approval of team admins.",
+			env.Name, deploymentID.String(),
+		), nil
+	}
+
+	err := deploy.Publish(ex.ctx, []*deploy.PublishSettings{
+		{EnvID: env.ID, DeploymentID: deploymentID, Percentage: 100},
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if err := deploy.NewStore().CancelRollouts(ex.ctx, env.ID, reason); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Deployment `%s` is published on the `%s` environment.", deploymentID.String(), env.Name), nil
+}
+
+// rollback rolls back the running rollout of the environment. When there is
+// no running rollout, it publishes the deployment that precedes the published one.
+func (ex *executor) rollback() (string, error) {
+	env, err := ex.env("env")
+
+	if err != nil {
+		return "", err
+	}
+
+	store := deploy.NewStore()
+	rollout, err := store.RolloutByEnvID(ex.ctx, env.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if rollout != nil && rollout.Status == deploy.RolloutStatusRunning {
+		reason := fmt.Sprintf("Rolled back from pull request #%d", ex.req.PullRequestNumber)
+
+		if err := deploy.Rollback(ex.ctx, rollout, reason); err != nil {
+			return "", err
+		}
+
+		return fmt.Sprintf("The rollout on the `%s` environment is rolled back.", env.Name), nil
+	}
+
+	publishedID, err := store.PublishedDeploymentID(ex.ctx, env.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	if publishedID == 0 {
+		return "", fmt.Errorf("the `%s` environment has no published deployment", env.Name)
+	}
+
+	prev, err := store.PreviousDeployment(ex.ctx, &deploy.Deployment{ID: publishedID, EnvID: env.ID})
+
+	if err != nil {
+		return "", err
+	}
+
+	if prev == nil {
+		return "", fmt.Errorf("the `%s` environment has no deployment to roll back to", env.Name)
+	}
+
+	msg, err := ex.publishToEnv(env, prev.ID, "Rolled back manually")
+
+	if err != nil {
+		return "", err
+	}
+
+	// Protected environments wait for an approval, the rollback is not executed yet.
+	if !env.Data.IsProtected() {
+		cnf := admin.MustConfig()
+
+		app.TriggerEvent(ex.ctx, app.TriggerOnRollback, app.OutboundWebhookSettings{
+			AppID:                  env.AppID,
+			DeploymentID:           prev.ID,
+			EnvironmentName:        env.Name,
+			DeploymentLogsEndpoint: cnf.DeploymentLogsURL(env.AppID, prev.ID),
+			RolloutStatus:          deploy.RolloutStatusRolledBack,
+			RolloutReason:          fmt.Sprintf("Rolled back from pull request #%d", ex.req.PullRequestNumber),
+		})
+	}
+
+	return msg, nil
+}
+
+// promote promotes the latest successful deployment of the pull request
+// to the target environment, without rebuilding it.
+func (ex *executor) promote() (string, error) {
+	if ex.req.Command.Flags["to"] == "" {
+		return "", errMissingTargetEnv
+	}
+
+	env, err := ex.env("to")
+
+	if err != nil {
+		return "", err
+	}
+
+	source, err := ex.deployment(true)
+
+	if err != nil {
+		return "", err
+	}
+
+	d, err := deploy.Promote(ex.ctx, deploy.PromoteArgs{
+		Source:      source,
+		Env:         env,
+		RequestedBy: ex.member.UserID,
+	})
+
+	if err != nil {
+		return "", err
+	}
+
+	if env.Data.IsProtected() {
+		return fmt.Sprintf(
+			"Deployment `%s` is promoted to the `%s` environment as deployment `%s`. Publishing it is waiting for the approval of team admins.",
+			source.ID.String(), env.Name, d.ID.String(),
+		), nil
+	}
+
+	return fmt.Sprintf(
+		"Deployment `%s` is promoted to the `%s` environment as deployment `%s`.",
+		source.ID.String(), env.Name, d.ID.String(),
+	), nil
+}
+
+// freeze adds a freeze window to the environment that starts now.
+func (ex *executor) freeze() (string, error) {
+	duration := DefaultFreezeDuration
+
+	if val := ex.req.Command.Flags["duration"]; val != "" {
+		minutes, err := strconv.Atoi(val)
+
+		if err != nil {
+			return "", errInvalidDuration
+		}
+
+		duration = time.Duration(minutes) * time.Minute
+	}
+
+	if duration < time.Minute || duration > MaxFreezeDuration {
+		return "", errInvalidDuration
+	}
+
+	env, err := ex.env("env")
+
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now().UTC()
+	until := now.Add(duration)
+
+	env.Data.FreezeWindows = append(env.Data.FreezeWindows, buildconf.FreezeWindow{
+		Name:     fmt.Sprintf("Frozen by @%s in pull request #%d", ex.req.Commenter, ex.req.PullRequestNumber),
+		StartsAt: utils.UnixFrom(now),
+		EndsAt:   utils.UnixFrom(until),
+	})
+
+	if verr := env.Validate(); verr != nil {
+		return "", fmt.Errorf("the environment configuration is invalid: %s", verr.Error())
+	}
+
+	if err := buildconf.NewStore().Update(ex.ctx, env); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf(
+		"Publishing to the `%s` environment is frozen until %s.",
+		env.Name, until.Format("2006-01-02 15:04 MST"),
+	), nil
+}
+
+// status returns the latest deployment of the pull request for each environment.
+func (ex *executor) status() (string, error) {
+	ds, err := ex.deployments(false)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(ds) == 0 {
+		return "This pull request has no deployments yet.", nil
+	}
+
+	cnf := admin.MustConfig()
+	seen := map[types.ID]bool{}
+	rows := []string{
+		"| Environment | Deployment | Status | Published | Preview |",
+		"| --- | --- | --- | --- | --- |",
+	}
+
+	for _, d := range ds {
+		if seen[d.EnvID] {
+			continue
+		}
+
+		seen[d.EnvID] = true
+		published := "No"
+
+		for _, p := range d.PublishedV2 {
+			if p.EnvID == d.EnvID && p.Percentage > 0 {
+				published = fmt.Sprintf("%.0f%%", p.Percentage)
+			}
+		}
+
+		preview := "-"
+
+		if d.Status() == "success" {
+			preview = fmt.Sprintf("[Preview](%s)", cnf.PreviewURL(ex.app.DisplayName, d.ID.String()))
+		}
+
+		rows = append(rows, fmt.Sprintf(
+			"| %s | [`%s`](%s) | %s | %s | %s |",
+			d.Env, d.ID.String(), cnf.DeploymentLogsURL(ex.app.ID, d.ID), d.Status(), published, preview,
+		))
+	}
+
+	return "Latest deployments of this pull request:\n\n" + strings.Join(rows, "\n"), nil
+}
+
+// logs returns the steps of the deployment with a link to the full logs.
+// The log output is not posted, as pull request comments can be public.
+func (ex *executor) logs() (string, error) {
+	d, err := ex.deployment(false)
+
+	if err != nil {
+		return "", err
+	}
+
+	full, err := deploy.NewStore().DeploymentByIDWithLogs(ex.ctx, d.ID, ex.app.ID)
+
+	if err != nil {
+		return "", err
+	}
+
+	lines := []string{
+		fmt.Sprintf("Deployment `%s` on the `%s` environment is **%s**.", d.ID.String(), d.Env, d.Status()),
+		"",
+	}
+
+	if full != nil {
+		for _, step := range full.PrepareLogs(full.Logs.ValueOrZero(), false) {
+			check := " "
+
+			if step.Status {
+				check = "x"
+			}
+
+			lines = append(lines, fmt.Sprintf("- [%s] %s (%ds)", check, step.Title, step.Duration))
+		}
+	}
+
+	if msg := d.Error.ValueOrZero(); msg != "" {
+		lines = append(lines, "", fmt.Sprintf("Error: %s", msg))
+	}
+
+	lines = append(lines, "", fmt.Sprintf("[View logs](%s)", admin.MustConfig().DeploymentLogsURL(ex.app.ID, d.ID)))
+	return strings.Join(lines, "\n"), nil
+}
@@ -0,0 +1,219 @@
+package deploycommands
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/bitbucket"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/github"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/gitlab"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// Request is a command that is posted as a pull request comment.
+type Request struct {
+	Repo              string // Repo is the base repository, e.g. github/owner/repo.
+	CheckoutRepo      string // CheckoutRepo is the head repository of the pull request.
+	Branch            string // Branch is the head branch of the pull request.
+	CommitSha         string // CommitSha is the latest commit of the head branch.
+	IsFork            bool
+	PullRequestNumber int64
+	CommentID         int64  // CommentID is the id of the comment that contains the command.
+	Commenter         string // Commenter is the login of the comment author on the git provider.
+	CommenterID       string // CommenterID is the immutable account id of the comment author on the git provider.
+	Command           *commands.CommandOutput
+}
+
+// Provider returns the git provider of the repository.
+func (r Request) Provider() string {
+	return strings.SplitN(r.Repo, "/", 2)[0]
+}
+
+// writeActions are the actions that change what is served to the visitors.
+// They require team admins, while the other actions are allowed to developers.
+var writeActions = map[string]bool{
+	commands.ActionPublish:  true,
+	commands.ActionRollback: true,
+	commands.ActionPromote:  true,
+	commands.ActionFreeze:   true,
+}
+
+// hasPermission checks whether the given team role is allowed to run the command.
+func hasPermission(role string, cmd *commands.CommandOutput) bool {
+	if writeActions[cmd.Action] || (cmd.Action == commands.ActionDeploy && cmd.Flags["publish"] == "true") {
+		return team.HasWriteAccess(role)
+	}
+
+	return team.IsValidRole(role)
+}
+
+// Run executes the command for the apps that are connected to the repository,
+// and replies to the comment with the results.
+func Run(ctx context.Context, req Request) error {
+	apps, err := app.NewStore().AppsByRepo(ctx, req.Repo)
+
+	if err != nil {
+		return err
+	}
+
+	if name := req.Command.Flags["app"]; name != "" {
+		filtered := []*app.App{}
+
+		for _, a := range apps {
+			if strings.EqualFold(a.DisplayName, name) || a.ID.String() == name {
+				filtered = append(filtered, a)
+			}
+		}
+
+		apps = filtered
+	}
+
+	if len(apps) == 0 {
+		return nil
+	}
+
+	if req.Provider() == "github" && req.Branch == "" {
+		head, err := PullRequestHead(req.Repo, req.PullRequestNumber)
+
+		if err != nil {
+			slog.Errorf("error while fetching pull request head: %v", err)
+		}
+
+		if head != nil {
+			req.Branch = head.Branch
+			req.CommitSha = head.Sha
+			req.CheckoutRepo = fmt.Sprintf("github/%s", head.Repo)
+			req.IsFork = !strings.EqualFold(req.CheckoutRepo, req.Repo)
+		}
+	}
+
+	sections := []string{}
+
+	for _, a := range apps {
+		message := Execute(ctx, req, a)
+
+		if len(apps) > 1 {
+			message = fmt.Sprintf("**%s:** %s", a.DisplayName, message)
+		}
+
+		sections = append(sections, message)
+	}
+
+	body := fmt.Sprintf("> %s\n\n@%s %s", commandLine(req.Command), req.Commenter, strings.Join(sections, "\n\n"))
+	return Reply(req, apps[0], body)
+}
+
+// Execute checks the permissions of the commenter and runs the command for the
+// given app. It returns the message that is posted back to the pull request.
+func Execute(ctx context.Context, req Request, a *app.App) string {
+	member, err := team.NewStore().TeamMemberByAccount(ctx, a.TeamID, req.Provider(), req.CommenterID)
+
+	if err != nil {
+		slog.Errorf("error while fetching team member by account: %v", err)
+		return "Something went wrong while checking your permissions."
+	}
+
+	if member == nil {
+		return fmt.Sprintf("Your %s account is not connected to a member of the team that owns this app on Stormkit. If you are a member, log in to Stormkit with %s again to connect it.", req.Provider(), req.Provider())
+	}
+
+	if !hasPermission(member.Role, req.Command) {
+		return fmt.Sprintf("You do not have permission to run `%s`. Ask a team admin to run it.", req.Command.Name())
+	}
+
+	ex := &executor{ctx: ctx, req: req, app: a, member: member}
+	var message string
+
+	switch req.Command.Action {
+	case commands.ActionDeploy:
+		message, err = ex.deploy()
+	case commands.ActionRedeploy:
+		message, err = ex.redeploy()
+	case commands.ActionPublish:
+		message, err = ex.publish()
+	case commands.ActionRollback:
+		message, err = ex.rollback()
+	case commands.ActionPromote:
+		message, err = ex.promote()
+	case commands.ActionFreeze:
+		message, err = ex.freeze()
+	case commands.ActionStatus:
+		message, err = ex.status()
+	case commands.ActionLogs:
+		message, err = ex.logs()
+	}
+
+	if err != nil {
+		slog.Errorf("error while running %s command: %v", req.Command.Name(), err)
+		return fmt.Sprintf("`%s` failed: %s", req.Command.Name(), err.Error())
+	}
+
+	return message
+}
+
+// commandLine returns the command as it is typed in comments.
+func commandLine(cmd *commands.CommandOutput) string {
+	pieces := append([]string{"/stormkit", cmd.Name()}, cmd.Arguments...)
+
+	keys := []string{}
+
+	for key := range cmd.Flags {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		if val := cmd.Flags[key]; val == "true" {
+			pieces = append(pieces, "--"+key)
+		} else {
+			pieces = append(pieces, fmt.Sprintf("--%s=%s", key, val))
+		}
+	}
+
+	return strings.Join(pieces, " ")
+}
+
+// PullRequestHead returns the head of a GitHub pull request.
+var PullRequestHead = github.GetPullRequestHead
+
+// Reply posts the body as a reply to the comment that contains the command.
+var Reply = func(req Request, a *app.App, body string) error {
+	switch req.Provider() {
+	case "github":
+		return github.CreatePullRequestComment(req.Repo, req.PullRequestNumber, body)
+
+	case "gitlab":
+		client, err := gitlab.NewClient(a.UserID)
+
+		if err != nil || client == nil {
+			return err
+		}
+
+		_, _, err = client.Notes.CreateMergeRequestNote(
+			client.SanitizeRepo(req.Repo),
+			int(req.PullRequestNumber),
+			&gitlab.CreateMergeRequestNoteOptions{Body: &body},
+		)
+
+		return err
+
+	case "bitbucket":
+		client, err := bitbucket.NewClientWithScope(a.UserID, []string{
+			bitbucket.PermissionRepositoryWrite,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		return client.PullRequestReply(&bitbucket.App{Repo: req.Repo}, body, req.PullRequestNumber, req.CommentID)
+	}
+
+	return nil
+}
@@ -0,0 +1,245 @@
+package deploycommands_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deploycommands"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/commands"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type CommandsSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn    databasetest.TestDB
+	owner   *factory.MockUser
+	app     *factory.MockApp
+	env     *factory.MockEnv
+	replies []string
+}
+
+func (s *CommandsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.replies = []string{}
+
+	s.owner = s.MockUser(map[string]any{"DisplayName": "owner-login"})
+	s.app = s.MockApp(s.owner, map[string]any{"Repo": "github/stormkit-io/sample"})
+	s.env = s.MockEnv(s.app)
+
+	deploycommands.Reply = func(req deploycommands.Request, a *app.App, body string) error {
+		s.replies = append(s.replies, body)
+		return nil
+	}
+}
+
+func (s *CommandsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	deployservice.MockDeployer = nil
+}
+
+func (s *CommandsSuite) run(commenter *factory.MockUser, command string) string {
+	err := deploycommands.Run(context.Background(), deploycommands.Request{
+		Repo:              "github/stormkit-io/sample",
+		CheckoutRepo:      "github/stormkit-io/sample",
+		Branch:            "feature",
+		CommitSha:         "8a6d1c2",
+		PullRequestNumber: 53,
+		CommentID:         1001,
+		Commenter:         commenter.DisplayName,
+		CommenterID:       commenter.ID.String(),
+		Command:           commands.Parse(command),
+	})
+
+	s.NoError(err)
+	s.Len(s.replies, 1)
+	return s.replies[0]
+}
+
+func (s *CommandsSuite) Test_Status() {
+	d := s.MockDeployment(s.env, map[string]any{
+		"PullRequestNumber": null.IntFrom(53),
+		"ExitCode":          null.IntFrom(0),
+	})
+
+	body := s.run(s.owner, "/stormkit status")
+
+	s.Contains(body, "> /stormkit status\n\n@owner-login Latest deployments of this pull request:")
+	s.Contains(body, "| production | [`"+d.ID.String()+"`]")
+	s.Contains(body, "| success | No |")
+}
+
+func (s *CommandsSuite) Test_NotMember() {
+	outsider := s.MockUser(map[string]any{"DisplayName": "someone-else"})
+	body := s.run(outsider, "/stormkit status")
+	s.Contains(body, "Your github account is not connected to a member of the team")
+}
+
+func (s *CommandsSuite) Test_MatchesAccountIDOnly() {
+	ctx := context.Background()
+
+	run := func(commenter, commenterID string) string {
+		s.replies = []string{}
+
+		s.NoError(deploycommands.Run(ctx, deploycommands.Request{
+			Repo:              "github/stormkit-io/sample",
+			CheckoutRepo:      "github/stormkit-io/sample",
+			Branch:            "feature",
+			CommitSha:         "8a6d1c2",
+			PullRequestNumber: 53,
+			CommentID:         1001,
+			Commenter:         commenter,
+			CommenterID:       commenterID,
+			Command:           commands.Parse("/stormkit status"),
+		}))
+
+		s.Len(s.replies, 1)
+		return s.replies[0]
+	}
+
+	// The login changed, but the account id still matches
+	s.NotContains(run("renamed-login", s.owner.ID.String()), "is not connected")
+
+	// Another account that took over the login is rejected
+	s.Contains(run("owner-login", "98765"), "Your github account is not connected to a member of the team")
+
+	// Members whose account id is not stored yet are rejected until they log in again
+	_, err := s.conn.Exec(`UPDATE user_access_tokens SET account_id = NULL WHERE user_id = $1`, s.owner.ID)
+	s.NoError(err)
+	s.Contains(run("owner-login", s.owner.ID.String()), "log in to Stormkit with github again")
+}
+
+func (s *CommandsSuite) Test_Publish() {
+	d := s.MockDeployment(s.env, map[string]any{
+		"PullRequestNumber": null.IntFrom(53),
+		"ExitCode":          null.IntFrom(0),
+	})
+
+	body := s.run(s.owner, "/stormkit publish")
+	s.Contains(body, "Deployment `"+d.ID.String()+"` is published on the `production` environment.")
+
+	publishedID, err := deploy.NewStore().PublishedDeploymentID(context.Background(), s.env.ID)
+	s.NoError(err)
+	s.Equal(d.ID, publishedID)
+}
+
+func (s *CommandsSuite) Test_Publish_DeveloperForbidden() {
+	dev := s.MockUser(map[string]any{"DisplayName": "dev-login"})
+
+	s.NoError(team.NewStore().AddMemberToTeam(context.Background(), &team.Member{
+		TeamID: s.app.TeamID,
+		UserID: dev.ID,
+		Role:   team.ROLE_DEVELOPER,
+		Status: true,
+	}))
+
+	s.MockDeployment(s.env, map[string]any{
+		"PullRequestNumber": null.IntFrom(53),
+		"ExitCode":          null.IntFrom(0),
+	})
+
+	body := s.run(dev, "/stormkit publish")
+	s.Contains(body, "You do not have permission to run `publish`.")
+
+	publishedID, err := deploy.NewStore().PublishedDeploymentID(context.Background(), s.env.ID)
+	s.NoError(err)
+	s.Equal(0, int(publishedID))
+}
+
+func (s *CommandsSuite) Test_Deploy() {
+	mockDeployer := &mocks.Deployer{}
+	mockDeployer.On("Deploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deployservice.MockDeployer = mockDeployer
+
+	body := s.run(s.owner, "/stormkit deploy production")
+	s.Contains(body, "started on the `production` environment.")
+
+	mockDeployer.AssertCalled(s.T(), "Deploy",
+		mock.Anything,
+		mock.MatchedBy(func(a *app.App) bool { return a.ID == s.app.ID }),
+		mock.MatchedBy(func(d *deploy.Deployment) bool {
+			return s.Equal("feature", d.Branch) &&
+				s.Equal(s.env.ID, d.EnvID) &&
+				s.Equal(int64(53), d.PullRequestNumber.ValueOrZero()) &&
+				s.Equal("8a6d1c2", d.Commit.ID.ValueOrZero()) &&
+				s.False(d.ShouldPublish)
+		}),
+	)
+}
+
+func (s *CommandsSuite) Test_Freeze() {
+	body := s.run(s.owner, "/stormkit freeze --duration=30")
+	s.Contains(body, "Publishing to the `production` environment is frozen until")
+
+	env, err := buildconf.NewStore().EnvironmentByID(context.Background(), s.env.ID)
+	s.NoError(err)
+
+	w, until := env.Data.ActiveFreezeWindow(time.Now())
+	s.NotNil(w)
+	s.Equal("Frozen by @owner-login in pull request #53", w.Name)
+	s.WithinDuration(time.Now().Add(30*time.Minute), until, time.Minute)
+}
+
+func (s *CommandsSuite) Test_Freeze_InvalidEnvironment() {
+	s.env.Data.FreezeWindows = []buildconf.FreezeWindow{{Name: "Broken", Cron: "not a cron", Duration: 60}}
+	s.NoError(buildconf.NewStore().Update(context.Background(), s.env.Env))
+
+	body := s.run(s.owner, "/stormkit freeze --duration=30")
+	s.Contains(body, "`freeze` failed: the environment configuration is invalid")
+
+	env, err := buildconf.NewStore().EnvironmentByID(context.Background(), s.env.ID)
+	s.NoError(err)
+	s.Len(env.Data.FreezeWindows, 1)
+}
+
+func (s *CommandsSuite) Test_Bitbucket_MatchesAccountID() {
+	ctx := context.Background()
+
+	_, err := s.conn.Exec(`UPDATE apps SET repo = 'bitbucket/stormkit-io/sample' WHERE app_id = $1`, s.app.ID)
+	s.NoError(err)
+
+	_, err = s.conn.Exec(`UPDATE user_access_tokens SET account_id = '{owner-uuid}' WHERE provider = 'bitbucket' AND display_name = 'owner-login'`)
+	s.NoError(err)
+
+	run := func(commenter, commenterID string) string {
+		s.replies = []string{}
+
+		s.NoError(deploycommands.Run(ctx, deploycommands.Request{
+			Repo:              "bitbucket/stormkit-io/sample",
+			CheckoutRepo:      "bitbucket/stormkit-io/sample",
+			Branch:            "feature",
+			CommitSha:         "8a6d1c2",
+			PullRequestNumber: 53,
+			CommentID:         1001,
+			Commenter:         commenter,
+			CommenterID:       commenterID,
+			Command:           commands.Parse("/stormkit status"),
+		}))
+
+		s.Len(s.replies, 1)
+		return s.replies[0]
+	}
+
+	// The nickname changed, but the account id still matches
+	s.NotContains(run("renamed-login", "{owner-uuid}"), "is not connected")
+
+	// Another account that took over the nickname is rejected
+	s.Contains(run("owner-login", "{someone-else}"), "Your bitbucket account is not connected to a member of the team")
+}
+
+func TestCommandsSuite(t *testing.T) {
+	suite.Run(t, &CommandsSuite{})
+}
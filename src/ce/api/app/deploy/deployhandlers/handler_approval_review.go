@@ -0,0 +1,112 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/audit"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type approvalReviewRequest struct {
+	model.Model
+
+	// ID is the id of the publish approval.
+	ID types.ID `json:"id,string"`
+
+	// Decision is either approve or reject.
+	Decision string `json:"decision"`
+
+	// Comment is an optional note that is recorded in the audit logs.
+	Comment string `json:"comment"`
+}
+
+// Validate implements model.Validate interface.
+func (ar *approvalReviewRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if ar.ID == 0 {
+		err.SetError("id", "Approval id is a required field")
+	}
+
+	if ar.Decision != deploy.ReviewDecisionApprove && ar.Decision != deploy.ReviewDecisionReject {
+		err.SetError("decision", deploy.ErrInvalidReviewDecision.Error())
+	}
+
+	return err.ToError()
+}
+
+// handlerApprovalReview approves or rejects a publish to a protected environment.
+// Only team admins and owners can review publishes, and the publish goes live
+// once it receives the required number of approvals.
+func handlerApprovalReview(req *app.RequestContext) *shttp.Response {
+	data := &approvalReviewRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	approval, err := deploy.NewStore().PublishApprovalByID(req.Context(), data.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if approval == nil || approval.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	t, err := team.NewStore().Team(req.Context(), req.App.TeamID, req.User.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if t == nil || !team.HasWriteAccess(t.CurrentUserRole) {
+		return shttp.Error(deploy.ErrApprovalForbidden)
+	}
+
+	err = ReviewApproval(req.Context(), approval, deploy.ApprovalReview{
+		UserID:   req.User.ID,
+		Decision: data.Decision,
+		Comment:  data.Comment,
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if req.License().Enterprise {
+		action := audit.ApproveAction
+
+		if data.Decision == deploy.ReviewDecisionReject {
+			action = audit.RejectAction
+		}
+
+		err := audit.FromRequestContext(req).
+			WithAction(action, audit.TypePublishApproval).
+			WithDiff(&audit.Diff{New: audit.DiffFields{
+				EnvID:                  approval.EnvID.String(),
+				ApprovalID:             approval.ID.String(),
+				ApprovalComment:        data.Comment,
+				PublishedDeploymentIDs: approval.DeploymentIDs(),
+			}}).
+			WithEnvID(approval.EnvID).
+			Insert()
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"approval": approval,
+		},
+	}
+}
+
+var ReviewApproval = deploy.ReviewApproval
@@ -0,0 +1,44 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerApprovalsGet returns the most recent publish approvals of the environment.
+// Use the `status` query parameter to filter them, e.g. `?status=pending`.
+func handlerApprovalsGet(req *app.RequestContext) *shttp.Response {
+	envID := utils.StringToID(req.Query().Get("envId"))
+
+	if envID == 0 {
+		return shttp.Error(deploy.ErrMissingEnvID)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), envID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	approvals, err := deploy.NewStore().PublishApprovals(req.Context(), deploy.PublishApprovalFilters{
+		EnvID:  env.ID,
+		Status: req.Query().Get("status"),
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"approvals": approvals,
+		},
+	}
+}
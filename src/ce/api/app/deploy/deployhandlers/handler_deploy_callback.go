@@ -2,13 +2,18 @@ package deployhandlers
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhooks"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
@@ -32,6 +37,12 @@ type deployCallbackRequest struct {
 	// Logs related information
 	Logs string `json:"logs"`
 
+	// The config file that was found in the repository
+	StormkitFile *buildconf.StormkitFile `json:"stormkitFile"`
+
+	// The software bill of materials of the deployment
+	SBOM *deploy.SBOM `json:"sbom"`
+
 	// Deployment result related information
 	Result          integrations.UploadResult `json:"result"`  // The upload result
 	UploadError     string                    `json:"error"`   // This field is generated when an error occurs during the upload
@@ -40,7 +51,8 @@ type deployCallbackRequest struct {
 	HasStatusChecks bool                      `json:"hasStatusChecks"`
 
 	// Final call
-	Lock bool `json:"lock"`
+	Lock               bool                      `json:"lock"`
+	StatusCheckResults deploy.StatusCheckResults `json:"statusCheckResults"`
 
 	deployment *deploy.Deployment
 }
@@ -59,6 +71,21 @@ func handlerDeployCallback(req *shttp.RequestContext) *shttp.Response {
 		return shttp.NotAllowed()
 	}
 
+	// Deployments that are built by agents accept callbacks only from the
+	// runner of the current claim. A job that was handed to another agent
+	// stops the runner of the previous claim.
+	claimed, err := buildagent.NewStore().HoldsClaim(req.Context(), deployID, req.Headers().Get("X-Stormkit-Job-Token"))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if !claimed {
+		return &shttp.Response{
+			Status: http.StatusConflict,
+		}
+	}
+
 	depl, err := deploy.NewStore().MyDeployment(req.Context(), &deploy.DeploymentsQueryFilters{
 		DeploymentID: deployID,
 		IncludeLogs:  aws.Bool(true),
@@ -92,6 +119,14 @@ func handlerDeployCallback(req *shttp.RequestContext) *shttp.Response {
 		return updateCommit(req, data)
 	}
 
+	if data.StormkitFile != nil {
+		return updateStormkitFile(req, data)
+	}
+
+	if data.SBOM != nil {
+		return updateSBOM(req, data)
+	}
+
 	if data.Logs != "" {
 		if data.deployment.ExitCode.Valid {
 			return updateStatusCheckLogs(req, data)
@@ -115,6 +150,8 @@ func updateLogs(req *shttp.RequestContext, data deployCallbackRequest) *shttp.Re
 		return shttp.Error(err)
 	}
 
+	deploy.PublishLogs(data.deployment.ID, data.deployment.Logs.ValueOrZero(), data.Logs, false)
+
 	return shttp.OK()
 }
 
@@ -126,9 +163,120 @@ func updateStatusCheckLogs(req *shttp.RequestContext, data deployCallbackRequest
 		return shttp.Error(err)
 	}
 
+	deploy.PublishLogs(data.deployment.ID, data.deployment.StatusChecks.ValueOrZero(), data.Logs, true)
+
+	return shttp.OK()
+}
+
+// updateStormkitFile records the effective configuration of the deployment,
+// which is the environment configuration merged with the repository config file.
+func updateStormkitFile(req *shttp.RequestContext, data deployCallbackRequest) *shttp.Response {
+	snapshot := deploy.ConfigSnapshot{}
+
+	if len(data.deployment.ConfigCopy) > 0 {
+		if err := json.Unmarshal(data.deployment.ConfigCopy, &snapshot); err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	file := data.StormkitFile
+	env := utils.GetString(snapshot.EnvName, data.deployment.Env)
+
+	snapshot.BuildConfig = file.Apply(snapshot.BuildConfig, env)
+	snapshot.StormkitFile = file.Name
+	snapshot.Triggers = file.Config(env).Triggers
+
+	snapshotData, err := json.Marshal(snapshot)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if err := deploy.NewStore().UpdateConfigSnapshot(req.Context(), data.deployment.ID, snapshotData); err != nil {
+		return shttp.Error(err)
+	}
+
 	return shttp.OK()
 }
 
+// updateSBOM stores the software bill of materials of the deployment.
+func updateSBOM(req *shttp.RequestContext, data deployCallbackRequest) *shttp.Response {
+	if err := deploy.NewStore().UpdateSBOM(req.Context(), data.deployment.ID, data.SBOM); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
+
+// syncStormkitFileTriggers replaces the function triggers of the environment that
+// were declared in the repository config file with the ones of the deployment.
+// Only deployments of the environment branch, or published ones, are synced so
+// that previews do not modify the triggers of the environment.
+func syncStormkitFileTriggers(ctx context.Context, d *deploy.Deployment) error {
+	snapshot := deploy.ConfigSnapshot{}
+
+	if len(d.ConfigCopy) == 0 {
+		return nil
+	}
+
+	if err := json.Unmarshal(d.ConfigCopy, &snapshot); err != nil {
+		return err
+	}
+
+	// Environments without a config file manage their triggers through the API.
+	if snapshot.StormkitFile == "" {
+		return nil
+	}
+
+	if sync, err := shouldSyncTriggers(ctx, d); err != nil || !sync {
+		return err
+	}
+
+	triggers := []*functiontrigger.FunctionTrigger{}
+
+	for _, trigger := range snapshot.Triggers {
+		triggers = append(triggers, &functiontrigger.FunctionTrigger{
+			Cron:     trigger.Cron,
+			TimeZone: trigger.TimeZone,
+			Status:   trigger.Status == nil || *trigger.Status,
+			Options: functiontrigger.Options{
+				Method:            utils.GetString(trigger.Method, shttp.MethodGet),
+				URL:               trigger.URL,
+				Path:              trigger.Path,
+				Payload:           []byte(trigger.Payload),
+				Headers:           trigger.Headers,
+				Timeout:           trigger.Timeout,
+				MaxRetries:        trigger.MaxRetries,
+				ConcurrencyPolicy: trigger.ConcurrencyPolicy,
+			},
+		})
+	}
+
+	return functiontrigger.NewStore().ReplaceSourceTriggers(ctx, d.EnvID, functiontrigger.SourceFile, triggers)
+}
+
+// shouldSyncTriggers returns true when the deployment is built from the environment
+// branch, or when it is the published deployment of the environment.
+func shouldSyncTriggers(ctx context.Context, d *deploy.Deployment) (bool, error) {
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, d.EnvID)
+
+	if err != nil || env == nil {
+		return false, err
+	}
+
+	if d.PullRequestNumber.ValueOrZero() == 0 && d.Branch == env.Branch {
+		return true, nil
+	}
+
+	publishedID, err := deploy.NewStore().PublishedDeploymentID(ctx, d.EnvID)
+
+	if err != nil {
+		return false, err
+	}
+
+	return publishedID == d.ID, nil
+}
+
 func updateCommit(req *shttp.RequestContext, data deployCallbackRequest) *shttp.Response {
 	store := deploy.NewStore()
 	ctx := req.Context()
@@ -211,6 +359,15 @@ func lockDeployment(req *shttp.RequestContext, d deployCallbackRequest) *shttp.R
 
 	if isSuccess {
 		if err := deploy.AutoPublishIfNecessary(req.Context(), d.deployment); err != nil {
+			// The deployment itself succeeded, it is just not published.
+			if !deploy.IsFreezeWindowError(err) {
+				return shttp.Error(err)
+			}
+
+			slog.Infof("deployment %s is not published: %s", d.deployment.ID.String(), err.Error())
+		}
+
+		if err := syncStormkitFileTriggers(req.Context(), d.deployment); err != nil {
 			return shttp.Error(err)
 		}
 	}
@@ -221,9 +378,51 @@ func lockDeployment(req *shttp.RequestContext, d deployCallbackRequest) *shttp.R
 		statusChecksPassed = null.BoolFrom(isSuccess)
 	}
 
-	if err := deploy.NewStore().LockDeployment(req.Context(), d.deployment.ID, statusChecksPassed); err != nil {
+	store := deploy.NewStore()
+
+	if len(d.StatusCheckResults) > 0 {
+		if err := store.UpdateStatusCheckResults(req.Context(), d.deployment.ID, d.StatusCheckResults); err != nil {
+			return shttp.Error(err)
+		}
+
+		d.deployment.StatusCheckResults = d.StatusCheckResults
+		deployhooks.StatusCheckResults(req.Context(), d.deployment)
+	}
+
+	if err := store.LockDeployment(req.Context(), d.deployment.ID, statusChecksPassed); err != nil {
 		return shttp.Error(err)
 	}
 
+	status := "failed"
+
+	if isSuccess {
+		status = "success"
+	}
+
+	deploy.PublishDone(d.deployment.ID, status)
+
+	if d.HasStatusChecks {
+		triggerStatusChecksWebhooks(req.Context(), d.deployment, isSuccess)
+	}
+
 	return shttp.OK()
 }
+
+// triggerStatusChecksWebhooks dispatches the outbound webhooks that are
+// subscribed to the status checks completed event.
+func triggerStatusChecksWebhooks(ctx context.Context, d *deploy.Deployment, passed bool) {
+	status := "failed"
+
+	if passed {
+		status = "passed"
+	}
+
+	app.TriggerEvent(ctx, app.TriggerOnStatusChecks, app.OutboundWebhookSettings{
+		AppID:                  d.AppID,
+		DeploymentID:           d.ID,
+		EnvironmentName:        d.Env,
+		Branch:                 d.Branch,
+		DeploymentLogsEndpoint: admin.MustConfig().DeploymentLogsURL(d.AppID, d.ID),
+		StatusChecksStatus:     status,
+	})
+}
@@ -2,15 +2,18 @@ package deployhandlers_test
 
 import (
 	"context"
+	"encoding/json"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhooks"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
@@ -463,6 +466,173 @@ func (s *HandlerDeployCallbackSuite) Test_LockDeployment_StatusChecksNotPassed()
 	s.False(ds[0].StatusChecksPassed.ValueOrZero())
 }
 
+func (s *HandlerDeployCallbackSuite) Test_LockDeployment_StatusCheckResults() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	depl := s.MockDeployment(env, map[string]any{
+		"ExitCode": null.IntFrom(0),
+	})
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/deploy/callback",
+		map[string]any{
+			"deployId":        utils.EncryptID(depl.ID),
+			"outcome":         "success",
+			"hasStatusChecks": true,
+			"lock":            true,
+			"statusCheckResults": []map[string]any{
+				{"name": "E2E", "cmd": "npm run e2e", "status": "passed", "required": true, "duration": 1200, "tests": 12},
+				{"name": "Lint", "cmd": "npm run lint", "status": "failed", "required": false, "duration": 300, "excerpt": "1 problem"},
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	ds, err := deploy.NewStore().MyDeployments(context.Background(), &deploy.DeploymentsQueryFilters{
+		DeploymentID: depl.ID,
+	})
+
+	s.NoError(err)
+	s.Len(ds, 1)
+	s.True(ds[0].IsLocked())
+	s.True(ds[0].StatusChecksPassed.ValueOrZero())
+	s.Equal(deploy.StatusCheckResults{
+		{Name: "E2E", Cmd: "npm run e2e", Status: deploy.StatusCheckPassed, Required: true, Duration: 1200, Tests: 12},
+		{Name: "Lint", Cmd: "npm run lint", Status: deploy.StatusCheckFailed, Required: false, Duration: 300, Excerpt: "1 problem"},
+	}, ds[0].StatusCheckResults)
+}
+
+func (s *HandlerDeployCallbackSuite) Test_StormkitFile() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	depl := s.MockDeployment(env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/deploy/callback",
+		map[string]any{
+			"deployId": utils.EncryptID(depl.ID),
+			"stormkitFile": map[string]any{
+				"name":     "stormkit.config.yml",
+				"buildCmd": "npm run build:file",
+				"vars":     map[string]string{"NODE_ENV": "development", "API_URL": "https://api.example.org"},
+				"triggers": []map[string]any{{"cron": "0 * * * *", "url": "https://example.org/api/cron"}},
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	d, err := deploy.NewStore().DeploymentByID(context.Background(), depl.ID)
+	s.NoError(err)
+	s.Equal("npm run build:file", d.BuildConfig.BuildCmd)
+	s.Equal("build", d.BuildConfig.DistFolder)
+	s.Equal(map[string]string{"NODE_ENV": "production", "API_URL": "https://api.example.org"}, d.BuildConfig.Vars)
+	s.Equal("stormkit.config.yml", d.Snapshot()["stormkitFile"])
+	s.Len(d.Snapshot()["triggers"], 1)
+}
+
+func (s *HandlerDeployCallbackSuite) Test_StormkitFile_SyncTriggers() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	ui := s.MockTriggerFunction(env)
+	s.MockTriggerFunction(env, map[string]any{"Cron": "0 0 * * *", "Source": functiontrigger.SourceFile})
+
+	snapshot, err := json.Marshal(deploy.ConfigSnapshot{
+		StormkitFile: "stormkit.config.yml",
+		Triggers: []buildconf.StormkitFileTrigger{
+			{Cron: "0 * * * *", URL: "https://example.org/api/cron"},
+		},
+	})
+
+	s.NoError(err)
+
+	preview := s.MockDeployment(env, map[string]any{"Branch": "feature", "ConfigCopy": snapshot})
+	production := s.MockDeployment(env, map[string]any{"ConfigCopy": snapshot})
+
+	crons := func() []string {
+		triggers, err := functiontrigger.NewStore().List(context.Background(), env.ID)
+		s.NoError(err)
+
+		crons := []string{}
+
+		for _, trigger := range triggers {
+			crons = append(crons, trigger.Cron+":"+trigger.Source)
+		}
+
+		return crons
+	}
+
+	for i, depl := range []*factory.MockDeployment{preview, production} {
+		response := shttptest.RequestWithHeaders(
+			shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+			shttp.MethodPost,
+			"/app/deploy/callback",
+			map[string]any{
+				"deployId": utils.EncryptID(depl.ID),
+				"outcome":  "success",
+			},
+			map[string]string{
+				"Authorization": usertest.Authorization(usr.ID),
+			},
+		)
+
+		s.Equal(http.StatusOK, response.Code)
+
+		// Previews do not modify the triggers of the environment
+		if i == 0 {
+			s.ElementsMatch([]string{ui.Cron + ":", "0 0 * * *:file"}, crons())
+		}
+	}
+
+	s.ElementsMatch([]string{ui.Cron + ":", "0 * * * *:file"}, crons())
+}
+
+func (s *HandlerDeployCallbackSuite) Test_SBOM() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	depl := s.MockDeployment(env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/deploy/callback",
+		map[string]any{
+			"deployId": utils.EncryptID(depl.ID),
+			"sbom": map[string]any{
+				"source":     "package-lock.json",
+				"components": []map[string]any{{"name": "lodash", "version": "4.17.21"}},
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	sbom, err := deploy.NewStore().SBOMByDeploymentID(context.Background(), depl.ID, app.ID)
+	s.NoError(err)
+	s.Equal(&deploy.SBOM{
+		Source:     "package-lock.json",
+		Components: []deploy.SBOMComponent{{Name: "lodash", Version: "4.17.21"}},
+	}, sbom)
+}
+
 func (s *HandlerDeployCallbackSuite) Test_ShouldNotOverwrite() {
 	usr := s.MockUser()
 	app := s.MockApp(usr)
@@ -0,0 +1,53 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerDeployDiffGet compares the deployment with the base deployment, which
+// is specified with the `base` query parameter. When omitted, the previous
+// successful deployment of the same environment is used.
+func handlerDeployDiffGet(req *app.RequestContext) *shttp.Response {
+	store := deploy.NewStore()
+	head, err := store.MyDeployment(req.Context(), &deploy.DeploymentsQueryFilters{
+		DeploymentID: utils.StringToID(req.Vars()["deploymentId"]),
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if head == nil || head.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	var base *deploy.Deployment
+
+	if baseID := utils.StringToID(req.Query().Get("base")); baseID != 0 {
+		base, err = store.MyDeployment(req.Context(), &deploy.DeploymentsQueryFilters{
+			DeploymentID: baseID,
+		})
+	} else {
+		base, err = store.PreviousDeployment(req.Context(), head)
+	}
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if base == nil || base.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	diff := deploy.Diff(base, head)
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"diff":    diff,
+			"summary": diff.Summary(),
+		},
+	}
+}
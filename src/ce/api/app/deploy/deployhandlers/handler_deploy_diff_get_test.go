@@ -0,0 +1,105 @@
+package deployhandlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type HandlerDeployDiffGetSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerDeployDiffGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerDeployDiffGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerDeployDiffGetSuite) request(url string, userID types.ID) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		url,
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(userID),
+		},
+	)
+}
+
+func (s *HandlerDeployDiffGetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	base := s.MockDeployment(env, map[string]any{
+		"ExitCode": null.IntFrom(0),
+		"BuildManifest": &deploy.BuildManifest{
+			CDNFiles: []deploy.CDNFile{
+				{Name: "/index.html", Headers: map[string]string{"etag": "a"}, Size: 100},
+			},
+		},
+	})
+
+	head := s.MockDeployment(env, map[string]any{
+		"ExitCode": null.IntFrom(0),
+		"BuildManifest": &deploy.BuildManifest{
+			CDNFiles: []deploy.CDNFile{
+				{Name: "/index.html", Headers: map[string]string{"etag": "b"}, Size: 120},
+			},
+		},
+	})
+
+	for _, url := range []string{
+		fmt.Sprintf("/app/%d/deploy/%d/diff", app.ID, head.ID),
+		fmt.Sprintf("/app/%d/deploy/%d/diff?base=%d", app.ID, head.ID, base.ID),
+	} {
+		response := s.request(url, usr.ID)
+		s.Equal(http.StatusOK, response.Code)
+
+		data := struct {
+			Diff    deploy.DeploymentDiff `json:"diff"`
+			Summary string                `json:"summary"`
+		}{}
+
+		s.NoError(json.Unmarshal(response.Byte(), &data))
+		s.Equal(base.ID, data.Diff.BaseID)
+		s.Equal(head.ID, data.Diff.HeadID)
+		s.Equal([]deploy.FileDiff{
+			{Name: "/index.html", Status: deploy.DiffStatusModified, OldETag: "a", NewETag: "b", OldSize: 100, NewSize: 120, SizeDelta: 20},
+		}, data.Diff.Files)
+		s.Contains(data.Summary, "0 added, 0 removed, 1 modified (+20 B)")
+	}
+}
+
+func (s *HandlerDeployDiffGetSuite) Test_NoPreviousDeployment() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	head := s.MockDeployment(env, map[string]any{"ExitCode": null.IntFrom(0)})
+
+	response := s.request(fmt.Sprintf("/app/%d/deploy/%d/diff", app.ID, head.ID), usr.ID)
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestHandlerDeployDiffGetSuite(t *testing.T) {
+	suite.Run(t, &HandlerDeployDiffGetSuite{})
+}
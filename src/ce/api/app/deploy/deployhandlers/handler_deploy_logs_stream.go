@@ -0,0 +1,129 @@
+package deployhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// logStreamHeartbeat is the interval to send keep-alive comments
+// so that proxies do not close idle connections.
+var logStreamHeartbeat = 15 * time.Second
+
+// handlerDeployLogsStream streams the deployment logs using server-sent events.
+// It first sends the logs that have been received so far, and then forwards
+// new lines and step transitions as they are reported by the runner. Since events
+// are fanned out through redis, any api replica can serve the stream.
+func handlerDeployLogsStream(req *app.RequestContext) *shttp.Response {
+	ctx := req.Context()
+	id := utils.StringToID(req.Vars()["deploymentId"])
+
+	rc := http.NewResponseController(req.Writer())
+
+	// Subscribe before fetching the deployment to make sure no event
+	// is lost between reading the logs and listening for new ones.
+	events, err := deploy.SubscribeLogs(ctx, id)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	depl, err := deploy.NewStore().MyDeployment(ctx, &deploy.DeploymentsQueryFilters{
+		AppID:        req.App.ID,
+		DeploymentID: id,
+		IncludeLogs:  aws.Bool(true),
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if depl == nil {
+		return shttp.NotFound()
+	}
+
+	// The stream lives longer than the server's write timeout
+	if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+		return shttp.Error(err)
+	}
+
+	w := req.Writer()
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	logs := depl.Logs.ValueOrZero()
+	statusChecks := depl.StatusChecks.ValueOrZero()
+
+	snapshot := append(
+		deploy.LogStreamEvents(logs, 0, false),
+		deploy.LogStreamEvents(statusChecks, 0, true)...,
+	)
+
+	for _, event := range snapshot {
+		writeLogStreamEvent(w, event)
+	}
+
+	if status := depl.Status(); status != "running" {
+		writeLogStreamEvent(w, deploy.LogStreamEvent{Type: deploy.LogStreamEventDone, Status: status})
+		rc.Flush()
+		return nil
+	}
+
+	rc.Flush()
+
+	ticker := time.NewTicker(logStreamHeartbeat)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			rc.Flush()
+		case event, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			// Skip events that were already part of the snapshot
+			if event.Type != deploy.LogStreamEventDone {
+				if (!event.StatusChecks && event.Offset < len(logs)) ||
+					(event.StatusChecks && event.Offset < len(statusChecks)) {
+					continue
+				}
+			}
+
+			writeLogStreamEvent(w, event)
+			rc.Flush()
+
+			if event.Type == deploy.LogStreamEventDone {
+				return nil
+			}
+		}
+	}
+}
+
+func writeLogStreamEvent(w io.Writer, event deploy.LogStreamEvent) {
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		slog.Errorf("cannot marshal log stream event: %v", err)
+		return
+	}
+
+	fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data)
+}
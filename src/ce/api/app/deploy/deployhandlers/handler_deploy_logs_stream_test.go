@@ -0,0 +1,84 @@
+package deployhandlers_test
+
+import (
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type HandlerDeployLogsStreamSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerDeployLogsStreamSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerDeployLogsStreamSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerDeployLogsStreamSuite) Test_CompletedDeployment() {
+	usr := s.MockUser()
+	appl := s.MockApp(usr)
+	env := s.MockEnv(appl)
+	depl := s.MockDeployment(env, map[string]any{
+		"ExitCode": null.NewInt(1, true),
+		"Logs":     null.NewString("[sk-step] npm run build [ts:1700000000]\nbuild failed\n", true),
+	})
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/app/%s/deploy/%s/logs/stream", appl.ID.String(), depl.ID.String()),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal("text/event-stream", response.Header().Get("Content-Type"))
+	s.Equal(
+		"event: step\ndata: {\"type\":\"step\",\"offset\":0,\"step\":\"npm run build\",\"ts\":1700000000}\n\n"+
+			"event: lines\ndata: {\"type\":\"lines\",\"offset\":40,\"lines\":[\"build failed\"]}\n\n"+
+			"event: done\ndata: {\"type\":\"done\",\"offset\":0,\"status\":\"failed\"}",
+		response.String(),
+	)
+}
+
+func (s *HandlerDeployLogsStreamSuite) Test_Fail404() {
+	usr := s.MockUser()
+	usr2 := s.MockUser()
+	appl := s.MockApp(usr)
+	depl := s.MockDeployment(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/app/%s/deploy/%s/logs/stream", appl.ID.String(), depl.ID.String()),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr2.ID),
+		},
+	)
+
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestHandlerDeployLogsStream(t *testing.T) {
+	suite.Run(t, &HandlerDeployLogsStreamSuite{})
+}
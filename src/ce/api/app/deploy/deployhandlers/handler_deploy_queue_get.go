@@ -0,0 +1,37 @@
+package deployhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerDeployQueueGet returns the state of the deployment in the build queue,
+// including its position.
+func handlerDeployQueueGet(req *app.RequestContext) *shttp.Response {
+	id := utils.StringToID(req.Vars()["deploymentId"])
+	qi, err := deploy.NewStore().QueueInfo(req.Context(), id, req.App.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if qi == nil {
+		return shttp.NotFound()
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"queue": map[string]any{
+				"status":   qi.Status,
+				"position": qi.Position,
+				"queuedAt": qi.QueuedAt,
+				"waitTime": qi.WaitTime,
+			},
+		},
+	}
+}
@@ -0,0 +1,85 @@
+package deployhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerDeployQueueGetSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerDeployQueueGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerDeployQueueGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerDeployQueueGetSuite) request(url string, userID types.ID) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		url,
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(userID),
+		},
+	)
+}
+
+func (s *HandlerDeployQueueGetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	ctx := context.Background()
+	store := deploy.NewStore()
+
+	first := s.MockDeployment(env)
+	second := s.MockDeployment(env, map[string]any{"Branch": "feature"})
+
+	for _, d := range []*factory.MockDeployment{first, second} {
+		s.NoError(store.EnqueueDeployment(ctx, &deploy.QueuedDeployment{
+			DeploymentID: d.ID,
+			AppID:        app.ID,
+			EnvID:        env.ID,
+			TeamID:       app.TeamID,
+			Branch:       d.Branch,
+			Payload:      "encrypted",
+		}))
+	}
+
+	response := s.request(fmt.Sprintf("/app/%d/deploy/%d/queue", app.ID, second.ID), usr.ID)
+	s.Equal(http.StatusOK, response.Code)
+
+	data := map[string]map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Equal("queued", data["queue"]["status"])
+	s.Equal(float64(2), data["queue"]["position"])
+
+	// Deployments that did not go through the queue
+	response = s.request(fmt.Sprintf("/app/%d/deploy/%d/queue", app.ID, s.MockDeployment(env).ID), usr.ID)
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestHandlerDeployQueueGet(t *testing.T) {
+	suite.Run(t, &HandlerDeployQueueGetSuite{})
+}
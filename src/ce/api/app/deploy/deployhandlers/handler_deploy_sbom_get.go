@@ -0,0 +1,53 @@
+package deployhandlers
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerDeploySBOMGet returns the software bill of materials of the deployment.
+// The format is specified with the `format` query parameter and can be either
+// cyclonedx (default) or spdx.
+func handlerDeploySBOMGet(req *app.RequestContext) *shttp.Response {
+	format := utils.GetString(req.Query().Get("format"), deploy.SBOMFormatCycloneDX)
+
+	if format != deploy.SBOMFormatCycloneDX && format != deploy.SBOMFormatSPDX {
+		return shttp.BadRequest(map[string]any{
+			"error": "Invalid format. Supported formats are cyclonedx and spdx.",
+		})
+	}
+
+	id := utils.StringToID(req.Vars()["deploymentId"])
+	sbom, err := deploy.NewStore().SBOMByDeploymentID(req.Context(), id, req.App.ID)
+
+	if err != nil {
+		return shttp.UnexpectedError(err)
+	}
+
+	if sbom == nil {
+		return shttp.NotFound()
+	}
+
+	deploymentID := id.String()
+	data := sbom.CycloneDX(req.App.DisplayName, deploymentID)
+	fileName := fmt.Sprintf("%s-%s.cdx.json", req.App.DisplayName, deploymentID)
+
+	if format == deploy.SBOMFormatSPDX {
+		data = sbom.SPDX(req.App.DisplayName, deploymentID)
+		fileName = fmt.Sprintf("%s-%s.spdx.json", req.App.DisplayName, deploymentID)
+	}
+
+	headers := make(http.Header)
+	headers.Set("Content-Disposition", "attachment; filename="+fileName)
+
+	return &shttp.Response{
+		Status:  http.StatusOK,
+		Headers: headers,
+		Data:    data,
+	}
+}
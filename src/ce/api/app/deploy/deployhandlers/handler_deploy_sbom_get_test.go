@@ -0,0 +1,96 @@
+package deployhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerDeploySBOMGetSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerDeploySBOMGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerDeploySBOMGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerDeploySBOMGetSuite) request(url string, userID types.ID) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		url,
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(userID),
+		},
+	)
+}
+
+func (s *HandlerDeploySBOMGetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	depl := s.MockDeployment(env)
+
+	s.NoError(deploy.NewStore().UpdateSBOM(context.Background(), depl.ID, &deploy.SBOM{
+		Source:      "package-lock.json",
+		GeneratedAt: 1700000000,
+		Components:  []deploy.SBOMComponent{{Name: "@babel/core", Version: "7.23.0"}},
+	}))
+
+	response := s.request(fmt.Sprintf("/app/%d/deploy/%d/sbom", app.ID, depl.ID), usr.ID)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal(
+		fmt.Sprintf("attachment; filename=%s-%d.cdx.json", app.DisplayName, depl.ID),
+		response.Header().Get("Content-Disposition"),
+	)
+
+	data := map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Equal("CycloneDX", data["bomFormat"])
+	s.Equal("pkg:npm/%40babel/core@7.23.0", data["components"].([]any)[0].(map[string]any)["purl"])
+
+	response = s.request(fmt.Sprintf("/app/%d/deploy/%d/sbom?format=spdx", app.ID, depl.ID), usr.ID)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Equal("SPDX-2.3", data["spdxVersion"])
+}
+
+func (s *HandlerDeploySBOMGetSuite) Test_NotFound() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	depl := s.MockDeployment(env)
+
+	response := s.request(fmt.Sprintf("/app/%d/deploy/%d/sbom", app.ID, depl.ID), usr.ID)
+	s.Equal(http.StatusNotFound, response.Code)
+
+	response = s.request(fmt.Sprintf("/app/%d/deploy/%d/sbom?format=xml", app.ID, depl.ID), usr.ID)
+	s.Equal(http.StatusBadRequest, response.Code)
+}
+
+func TestHandlerDeploySBOMGet(t *testing.T) {
+	suite.Run(t, &HandlerDeploySBOMGetSuite{})
+}
@@ -239,6 +239,10 @@ func (s *DeployStartTestSuite) Test_Zip() {
 	  "createdAt": "{{ .createdAt }}",
 	  "stoppedAt": "{{ .stoppedAt }}",
 	  "status": "success",
+	  "promotedFrom": "",
+	  "pinned": false,
+	  "tag": "",
+	  "queue": null,
 	  "commit": {
 		"author": "David Lorenzo",
 		"message": "",
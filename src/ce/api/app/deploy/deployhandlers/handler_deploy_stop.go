@@ -51,5 +51,7 @@ func handlerDeployStop(req *app.RequestContext) *shttp.Response {
 		_ = deployservice.Github().StopDeployment(depl.GithubRunID.ValueOrZero())
 	}
 
+	deploy.PublishDone(depl.ID, "failed")
+
 	return shttp.OK()
 }
@@ -8,6 +8,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
@@ -93,6 +94,10 @@ func jsonResponse(d *deploy.Deployment, withLogs bool) map[string]any {
 		"statusChecks":       statusChecksLogs,
 		"statusChecksPassed": d.StatusChecksPassed,
 		"duration":           calculateDuration(d.CreatedAt, d.StoppedAt),
+		"promotedFrom":       types.ID(d.PromotedFrom.ValueOrZero()),
+		"pinned":             d.Pinned,
+		"tag":                d.Tag.ValueOrZero(),
+		"queue":              d.Queue,
 		"commit": map[string]any{
 			"sha":     d.Commit.ID.ValueOrZero(),
 			"author":  d.Commit.Author.ValueOrZero(),
@@ -91,7 +91,11 @@ func (s *HandlerMyDeploymentsSuite) responseTemplate() (*template.Template, erro
 					"published": [],
 					"statusChecksPassed": null,
 					"statusChecks": null,
-					"duration": 0
+					"duration": 0,
+					"promotedFrom": "",
+					"pinned": false,
+					"tag": "",
+					"queue": null
 				}
 				{{ if not (last $i $.records) }}, {{ end }}
 			{{ end }}
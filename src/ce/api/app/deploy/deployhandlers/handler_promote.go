@@ -0,0 +1,102 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type promoteRequest struct {
+	model.Model
+
+	// DeploymentID is the id of the deployment that will be promoted.
+	DeploymentID types.ID `json:"deploymentId,string"`
+
+	// EnvID is the id of the environment that the deployment is promoted to.
+	EnvID types.ID `json:"envId,string"`
+
+	// ReinjectEnvVars specifies whether the runtime environment variables
+	// of the target environment should be used instead of the source ones.
+	ReinjectEnvVars bool `json:"reinjectEnvVars"`
+
+	// Rollout overwrites the rollout plan of the environment.
+	Rollout *buildconf.RolloutPlan `json:"rollout"`
+}
+
+// Validate implements model.Validate interface.
+func (pr *promoteRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if pr.EnvID == 0 {
+		err.SetError("envId", deploy.ErrMissingEnvID.Error())
+	}
+
+	if pr.DeploymentID == 0 {
+		err.SetError("deploymentId", deploy.ErrMissingDeploymentID.Error())
+	}
+
+	if pr.Rollout != nil {
+		if perr := pr.Rollout.Validate(); perr != nil {
+			err.SetError("rollout", perr.Error())
+		}
+	}
+
+	return err.ToError()
+}
+
+// handlerPromote promotes a deployment to another environment of the same
+// application without rebuilding it, and publishes it.
+func handlerPromote(req *app.RequestContext) *shttp.Response {
+	data := &promoteRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), data.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	source, err := deploy.NewStore().DeploymentByID(req.Context(), data.DeploymentID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if source == nil || source.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	d, err := Promote(req.Context(), deploy.PromoteArgs{
+		Source:          source,
+		Env:             env,
+		ReinjectEnvVars: data.ReinjectEnvVars,
+		RequestedBy:     req.User.ID,
+		Rollout:         data.Rollout,
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"deploymentId": d.ID.String(),
+			"promotedFrom": source.ID.String(),
+			"envId":        env.ID.String(),
+			"approval":     env.Data.IsProtected(),
+		},
+	}
+}
+
+var Promote = deploy.Promote
@@ -1,12 +1,18 @@
 package deployhandlers
 
 import (
+	"net/http"
+	"time"
+
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/audit"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
 	"github.com/stormkit-io/stormkit-io/src/lib/model"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
 )
 
@@ -26,6 +32,11 @@ type publishRequest struct {
 
 	// Publish holds a map of ids with their respective percentage to deploy.
 	Publish []publishSettings `json:"publish"`
+
+	// OverrideFreeze publishes the deployments even when the environment is in
+	// a freeze window. Only team admins can override freeze windows, and the
+	// override is recorded in the audit logs.
+	OverrideFreeze bool `json:"overrideFreeze"`
 }
 
 // Validate impleents model.Validate interface.
@@ -83,6 +94,16 @@ func handlerPublish(req *app.RequestContext) *shttp.Response {
 		return shttp.NotFound()
 	}
 
+	if env.Data.IsProtected() {
+		return requestApproval(req, env, data)
+	}
+
+	if data.OverrideFreeze {
+		if res := overrideFreezeWindow(req, env, data); res != nil {
+			return res
+		}
+	}
+
 	settings := []*deploy.PublishSettings{}
 
 	for _, publishDetails := range data.Publish {
@@ -90,6 +111,7 @@ func handlerPublish(req *app.RequestContext) *shttp.Response {
 			EnvID:        env.ID,
 			DeploymentID: publishDetails.DeploymentID,
 			Percentage:   publishDetails.Percentage,
+			IgnoreFreeze: data.OverrideFreeze,
 		})
 	}
 
@@ -97,6 +119,11 @@ func handlerPublish(req *app.RequestContext) *shttp.Response {
 		return shttp.Error(err)
 	}
 
+	// Publishing manually takes precedence over a running rollout.
+	if err := deploy.NewStore().CancelRollouts(req.Context(), env.ID, "Published manually"); err != nil {
+		return shttp.Error(err)
+	}
+
 	var publishConfig []any
 
 	if data.Publish != nil {
@@ -119,4 +146,96 @@ func handlerPublish(req *app.RequestContext) *shttp.Response {
 	}
 }
 
+// requestApproval creates a publish approval for the protected environment
+// instead of publishing the deployments.
+func requestApproval(req *app.RequestContext, env *buildconf.Env, data *publishRequest) *shttp.Response {
+	config := []deploy.PublishConfig{}
+
+	for _, p := range data.Publish {
+		config = append(config, deploy.PublishConfig{
+			DeploymentID: p.DeploymentID,
+			Percentage:   p.Percentage,
+		})
+	}
+
+	approval, err := deploy.RequestApproval(req.Context(), env, config, req.User.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if req.License().Enterprise {
+		err := audit.FromRequestContext(req).
+			WithAction(audit.CreateAction, audit.TypePublishApproval).
+			WithDiff(&audit.Diff{New: audit.DiffFields{
+				EnvID:                  env.ID.String(),
+				EnvName:                env.Name,
+				ApprovalID:             approval.ID.String(),
+				PublishedDeploymentIDs: approval.DeploymentIDs(),
+			}}).
+			WithEnvID(env.ID).
+			Insert()
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return &shttp.Response{
+		Status: http.StatusAccepted,
+		Data: map[string]any{
+			"approval": approval,
+		},
+	}
+}
+
+// overrideFreezeWindow checks that the user is allowed to publish during a freeze
+// window, and records the override in the audit logs.
+func overrideFreezeWindow(req *app.RequestContext, env *buildconf.Env, data *publishRequest) *shttp.Response {
+	w, _ := env.Data.ActiveFreezeWindow(time.Now())
+
+	if w == nil {
+		return nil
+	}
+
+	if !req.User.IsAdmin {
+		t, err := team.NewStore().Team(req.Context(), req.App.TeamID, req.User.ID)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if t == nil || !team.HasWriteAccess(t.CurrentUserRole) {
+			return shttp.Error(deploy.ErrFreezeOverrideForbidden)
+		}
+	}
+
+	ids := []string{}
+
+	for _, p := range data.Publish {
+		ids = append(ids, p.DeploymentID.String())
+	}
+
+	slog.Infof("user %s overrode the %s freeze window of env %s", req.User.ID.String(), w.Name, env.ID.String())
+
+	if req.License().Enterprise {
+		err := audit.FromRequestContext(req).
+			WithAction(audit.OverrideAction, audit.TypeFreezeWindow).
+			WithDiff(&audit.Diff{New: audit.DiffFields{
+				EnvID:                  env.ID.String(),
+				EnvName:                env.Name,
+				FreezeWindowName:       w.Name,
+				PublishedDeploymentIDs: ids,
+			}}).
+			WithEnvID(env.ID).
+			Insert()
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return nil
+}
+
 var Publish = deploy.Publish
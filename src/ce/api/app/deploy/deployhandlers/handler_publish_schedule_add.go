@@ -0,0 +1,101 @@
+package deployhandlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type publishScheduleAddRequest struct {
+	publishRequest
+
+	// PublishAt is the time when the deployments are published.
+	PublishAt utils.Unix `json:"publishAt"`
+}
+
+// Validate implements model.Validate interface.
+func (pr *publishScheduleAddRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if verr := pr.publishRequest.Validate(); verr != nil {
+		for key, value := range verr.Errors {
+			err.SetError(key, value)
+		}
+	}
+
+	if !pr.PublishAt.Valid || !pr.PublishAt.After(time.Now()) {
+		err.SetError("publishAt", deploy.ErrInvalidPublishAt.Error())
+	}
+
+	return err.ToError()
+}
+
+// handlerPublishScheduleAdd schedules a publish for the given environment.
+// The deployments are published by a worker once the publish date is reached.
+func handlerPublishScheduleAdd(req *app.RequestContext) *shttp.Response {
+	data := &publishScheduleAddRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), data.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	if env.Data.IsProtected() {
+		return shttp.Error(deploy.ErrProtectedEnv)
+	}
+
+	store := deploy.NewStore()
+	config := []deploy.PublishConfig{}
+
+	for _, p := range data.Publish {
+		d, err := store.DeploymentByID(req.Context(), p.DeploymentID)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if d == nil || d.AppID != req.App.ID {
+			return shttp.NotFound()
+		}
+
+		config = append(config, deploy.PublishConfig{
+			DeploymentID: p.DeploymentID,
+			Percentage:   p.Percentage,
+		})
+	}
+
+	schedule := &deploy.ScheduledPublish{
+		AppID:     req.App.ID,
+		EnvID:     env.ID,
+		Config:    config,
+		PublishAt: utils.UnixFrom(data.PublishAt.Time),
+		Status:    deploy.ScheduledPublishStatusPending,
+		CreatedBy: req.User.ID,
+	}
+
+	if err := store.InsertScheduledPublish(req.Context(), schedule); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusCreated,
+		Data: map[string]any{
+			"schedule": schedule,
+		},
+	}
+}
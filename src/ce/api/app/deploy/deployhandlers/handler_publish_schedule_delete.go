@@ -0,0 +1,64 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type publishScheduleDeleteRequest struct {
+	model.Model
+
+	EnvID types.ID `json:"envId,string"`
+	ID    types.ID `json:"id,string"`
+}
+
+// Validate implements model.Validate interface.
+func (pr *publishScheduleDeleteRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if pr.EnvID == 0 {
+		err.SetError("envId", deploy.ErrMissingEnvID.Error())
+	}
+
+	if pr.ID == 0 {
+		err.SetError("id", "Schedule id is a required field")
+	}
+
+	return err.ToError()
+}
+
+// handlerPublishScheduleDelete cancels a pending scheduled publish.
+func handlerPublishScheduleDelete(req *app.RequestContext) *shttp.Response {
+	data := &publishScheduleDeleteRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), data.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	cancelled, err := deploy.NewStore().CancelScheduledPublish(req.Context(), data.ID, env.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if !cancelled {
+		return shttp.NotFound()
+	}
+
+	return shttp.OK()
+}
@@ -0,0 +1,43 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerPublishScheduleGet returns the pending scheduled publishes of the environment.
+func handlerPublishScheduleGet(req *app.RequestContext) *shttp.Response {
+	envID := utils.StringToID(req.Query().Get("envId"))
+
+	if envID == 0 {
+		return shttp.Error(deploy.ErrMissingEnvID)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), envID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	schedules, err := deploy.NewStore().ScheduledPublishes(req.Context(), deploy.ScheduledPublishFilters{
+		EnvID:  env.ID,
+		Status: deploy.ScheduledPublishStatusPending,
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"schedules": schedules,
+		},
+	}
+}
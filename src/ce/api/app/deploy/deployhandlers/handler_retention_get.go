@@ -0,0 +1,58 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerRetentionGet returns the retention policy of the environment and the
+// deployments whose artifacts would be removed by it. Nothing is removed.
+func handlerRetentionGet(req *app.RequestContext) *shttp.Response {
+	envID := utils.StringToID(req.Query().Get("envId"))
+
+	if envID == 0 {
+		return shttp.Error(deploy.ErrMissingEnvID)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), envID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	policy := admin.MustConfig().Retention()
+
+	if env.Data != nil && env.Data.Retention != nil {
+		policy = *env.Data.Retention
+	}
+
+	deployments, err := deploy.NewStore().ExpiredDeployments(req.Context(), deploy.ExpiredDeploymentsFilters{
+		EnvID:  env.ID,
+		Policy: policy,
+	})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	ids := []string{}
+
+	for _, d := range deployments {
+		ids = append(ids, d.ID.String())
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"retention":   policy,
+			"deployments": ids,
+		},
+	}
+}
@@ -0,0 +1,67 @@
+package deployhandlers
+
+import (
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"gopkg.in/guregu/null.v3"
+)
+
+type retentionUpdateRequest struct {
+	model.Model
+
+	DeploymentID types.ID `json:"deploymentId,string"`
+	Pinned       bool     `json:"pinned"`
+	Tag          string   `json:"tag"`
+}
+
+// Validate implements model.Validate interface.
+func (rr *retentionUpdateRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+	rr.Tag = strings.TrimSpace(rr.Tag)
+
+	if rr.DeploymentID == 0 {
+		err.SetError("deploymentId", "Deployment id is a required field")
+	}
+
+	if len(rr.Tag) > 64 {
+		err.SetError("tag", "Tag cannot be longer than 64 characters")
+	}
+
+	return err.ToError()
+}
+
+// handlerRetentionUpdate pins or tags a deployment. Pinned and tagged deployments
+// are never removed by the retention policies.
+func handlerRetentionUpdate(req *app.RequestContext) *shttp.Response {
+	data := &retentionUpdateRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	store := deploy.NewStore()
+	d, err := store.DeploymentByID(req.Context(), data.DeploymentID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if d == nil || d.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	d.Pinned = data.Pinned
+	d.Tag = null.NewString(data.Tag, data.Tag != "")
+
+	if err := store.UpdateRetention(req.Context(), d); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
@@ -0,0 +1,92 @@
+package deployhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerRetentionUpdateSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerRetentionUpdateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerRetentionUpdateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerRetentionUpdateSuite) Test_Success() {
+	usr := s.MockUser()
+	appl := s.MockApp(usr)
+	env := s.MockEnv(appl)
+	depl := s.MockDeployment(env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/deployments/retention",
+		map[string]any{
+			"appId":        appl.ID.String(),
+			"deploymentId": depl.ID.String(),
+			"pinned":       true,
+			"tag":          " v1.0.0 ",
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	d, err := deploy.NewStore().MyDeployment(context.Background(), &deploy.DeploymentsQueryFilters{
+		DeploymentID: depl.ID,
+	})
+
+	s.NoError(err)
+	s.NotNil(d)
+	s.True(d.Pinned)
+	s.Equal("v1.0.0", d.Tag.ValueOrZero())
+}
+
+func (s *HandlerRetentionUpdateSuite) Test_NotFound() {
+	usr := s.MockUser()
+	appl := s.MockApp(usr)
+	depl := s.MockDeployment(s.MockEnv(s.MockApp(s.MockUser())))
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deployhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/app/deployments/retention",
+		map[string]any{
+			"appId":        appl.ID.String(),
+			"deploymentId": depl.ID.String(),
+			"pinned":       true,
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestHandlerRetentionUpdateSuite(t *testing.T) {
+	suite.Run(t, &HandlerRetentionUpdateSuite{})
+}
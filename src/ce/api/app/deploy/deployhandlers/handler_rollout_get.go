@@ -0,0 +1,47 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerRolloutGet returns the most recent rollout of the environment
+// along with the metrics collected during the current step.
+func handlerRolloutGet(req *app.RequestContext) *shttp.Response {
+	envID := utils.StringToID(req.Query().Get("envId"))
+
+	if envID == 0 {
+		return shttp.Error(deploy.ErrMissingEnvID)
+	}
+
+	rollout, err := deploy.NewStore().RolloutByEnvID(req.Context(), envID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if rollout == nil || rollout.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	var metrics *deploy.RolloutMetrics
+
+	if rollout.Status == deploy.RolloutStatusRunning {
+		m, err := deploy.RolloutMetricsByDeploymentID(req.Context(), rollout.DeploymentID)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		metrics = &m
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"rollout": rollout,
+			"metrics": metrics,
+		},
+	}
+}
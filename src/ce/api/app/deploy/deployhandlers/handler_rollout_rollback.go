@@ -0,0 +1,58 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type rolloutRollbackRequest struct {
+	model.Model
+
+	// EnvID is the id of the environment whose running rollout is rolled back.
+	EnvID types.ID `json:"envId,string"`
+}
+
+// Validate implements model.Validate interface.
+func (rr *rolloutRollbackRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if rr.EnvID == 0 {
+		err.SetError("envId", deploy.ErrMissingEnvID.Error())
+	}
+
+	return err.ToError()
+}
+
+// handlerRolloutRollback rolls back the running rollout of the environment
+// and publishes the previous deployment to 100%.
+func handlerRolloutRollback(req *app.RequestContext) *shttp.Response {
+	data := &rolloutRollbackRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	rollout, err := deploy.NewStore().RolloutByEnvID(req.Context(), data.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if rollout == nil || rollout.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	if err := deploy.Rollback(req.Context(), rollout, "Rolled back manually"); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"rollout": rollout,
+		},
+	}
+}
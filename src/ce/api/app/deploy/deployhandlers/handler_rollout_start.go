@@ -0,0 +1,105 @@
+package deployhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/model"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type rolloutStartRequest struct {
+	model.Model
+
+	// DeploymentID is the id of the deployment that will be rolled out.
+	DeploymentID types.ID `json:"deploymentId,string"`
+
+	// EnvID is the id of the environment. The rollout plan of the
+	// environment is used unless a plan is provided in the request.
+	EnvID types.ID `json:"envId,string"`
+
+	// Plan overwrites the rollout plan of the environment.
+	Plan *buildconf.RolloutPlan `json:"plan"`
+}
+
+// Validate implements model.Validate interface.
+func (rr *rolloutStartRequest) Validate() *shttperr.ValidationError {
+	err := &shttperr.ValidationError{}
+
+	if rr.EnvID == 0 {
+		err.SetError("envId", deploy.ErrMissingEnvID.Error())
+	}
+
+	if rr.DeploymentID == 0 {
+		err.SetError("deploymentId", deploy.ErrMissingDeploymentID.Error())
+	}
+
+	if rr.Plan != nil {
+		if perr := rr.Plan.Validate(); perr != nil {
+			err.SetError("plan", perr.Error())
+		}
+	}
+
+	return err.ToError()
+}
+
+// handlerRolloutStart publishes a deployment progressively according
+// to the rollout plan.
+func handlerRolloutStart(req *app.RequestContext) *shttp.Response {
+	data := &rolloutStartRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), data.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil || env.AppID != req.App.ID {
+		return shttp.NotFound()
+	}
+
+	// Protected environments roll out deployments once they are approved.
+	if env.Data.IsProtected() {
+		return shttp.Error(deploy.ErrProtectedEnv)
+	}
+
+	d, err := deploy.NewStore().DeploymentByID(req.Context(), data.DeploymentID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if d == nil || d.AppID != req.App.ID || d.EnvID != env.ID {
+		return shttp.NotFound()
+	}
+
+	plan := data.Plan
+
+	if plan == nil && env.Data != nil {
+		plan = env.Data.Rollout
+	}
+
+	if plan == nil {
+		return shttp.Error(deploy.ErrRolloutMissingPlan)
+	}
+
+	rollout, err := StartRollout(req.Context(), d, *plan)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Data: map[string]any{
+			"rollout": rollout,
+		},
+	}
+}
+
+var StartRollout = deploy.StartRollout
@@ -21,7 +21,17 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodPost, "/stop", shttp.WithRateLimit(app.WithApp(handlerDeployStop), nil))
 
 	s.NewEndpoint("/app/{did:[0-9]+}/deploy").
-		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}", app.WithApp(handlerDeployGet))
+		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}", app.WithApp(handlerDeployGet)).
+		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}/diff", shttp.WithRateLimit(
+			app.WithApp(handlerDeployDiffGet),
+			nil,
+		)).
+		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}/logs/stream", app.WithApp(handlerDeployLogsStream)).
+		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}/queue", app.WithApp(handlerDeployQueueGet)).
+		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}/sbom", shttp.WithRateLimit(
+			app.WithApp(handlerDeploySBOMGet),
+			nil,
+		))
 
 	s.NewEndpoint("/app/{did:[0-9]+}/manifest").
 		Handler(shttp.MethodGet, "/{deploymentId:[0-9]+}", shttp.WithRateLimit(
@@ -37,6 +47,38 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodPost, "/publish", shttp.WithRateLimit(
 			app.WithApp(handlerPublish),
 			nil,
+		)).
+		Handler(shttp.MethodGet, "/publish/schedule", app.WithApp(handlerPublishScheduleGet)).
+		Handler(shttp.MethodPost, "/publish/schedule", shttp.WithRateLimit(
+			app.WithApp(handlerPublishScheduleAdd),
+			nil,
+		)).
+		Handler(shttp.MethodDelete, "/publish/schedule", shttp.WithRateLimit(
+			app.WithApp(handlerPublishScheduleDelete),
+			nil,
+		)).
+		Handler(shttp.MethodGet, "/approvals", app.WithApp(handlerApprovalsGet)).
+		Handler(shttp.MethodPost, "/approvals/review", shttp.WithRateLimit(
+			app.WithApp(handlerApprovalReview),
+			nil,
+		)).
+		Handler(shttp.MethodPost, "/promote", shttp.WithRateLimit(
+			app.WithApp(handlerPromote),
+			nil,
+		)).
+		Handler(shttp.MethodGet, "/retention", app.WithApp(handlerRetentionGet)).
+		Handler(shttp.MethodPost, "/retention", shttp.WithRateLimit(
+			app.WithApp(handlerRetentionUpdate),
+			nil,
+		)).
+		Handler(shttp.MethodGet, "/rollout", app.WithApp(handlerRolloutGet)).
+		Handler(shttp.MethodPost, "/rollout", shttp.WithRateLimit(
+			app.WithApp(handlerRolloutStart),
+			nil,
+		)).
+		Handler(shttp.MethodDelete, "/rollout", shttp.WithRateLimit(
+			app.WithApp(handlerRolloutRollback),
+			nil,
 		))
 
 	return s
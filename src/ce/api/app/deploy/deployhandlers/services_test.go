@@ -17,7 +17,17 @@ func (s *ServicesSuite) TestServices() {
 
 	handlers := []string{
 		"DELETE:/app/deploy",
+		"DELETE:/app/deployments/publish/schedule",
+		"DELETE:/app/deployments/rollout",
+		"GET:/app/deployments/approvals",
+		"GET:/app/deployments/publish/schedule",
+		"GET:/app/deployments/retention",
+		"GET:/app/deployments/rollout",
 		"GET:/app/{did:[0-9]+}/deploy/{deploymentId:[0-9]+}",
+		"GET:/app/{did:[0-9]+}/deploy/{deploymentId:[0-9]+}/diff",
+		"GET:/app/{did:[0-9]+}/deploy/{deploymentId:[0-9]+}/logs/stream",
+		"GET:/app/{did:[0-9]+}/deploy/{deploymentId:[0-9]+}/queue",
+		"GET:/app/{did:[0-9]+}/deploy/{deploymentId:[0-9]+}/sbom",
 		"GET:/app/{did:[0-9]+}/manifest/{deploymentId:[0-9]+}",
 		"GET:/my/deployments",
 		"POST:/app/deploy",
@@ -25,7 +35,12 @@ func (s *ServicesSuite) TestServices() {
 		"POST:/app/deploy/restart",
 		"POST:/app/deploy/stop",
 		"POST:/app/deployments",
+		"POST:/app/deployments/approvals/review",
+		"POST:/app/deployments/promote",
 		"POST:/app/deployments/publish",
+		"POST:/app/deployments/publish/schedule",
+		"POST:/app/deployments/retention",
+		"POST:/app/deployments/rollout",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
@@ -33,11 +33,12 @@ var Exec = func(ctx context.Context, d *deploy.Deployment) {
 		return
 	}
 
-	whs := app.NewStore().OutboundWebhooks(ctx, d.AppID)
 	status := "success"
+	event := app.TriggerOnDeploySuccess
 
 	if d.ExitCode.ValueOrZero() != 0 {
 		status = "failed"
+		event = app.TriggerOnDeployFailed
 	}
 
 	cnf := admin.MustConfig()
@@ -45,21 +46,15 @@ var Exec = func(ctx context.Context, d *deploy.Deployment) {
 		AppID:                  d.AppID,
 		DeploymentID:           d.ID,
 		DeploymentStatus:       status,
+		DeploymentAuthor:       d.Commit.Author.ValueOrZero(),
 		EnvironmentName:        d.Env,
 		DeploymentError:        d.Error.ValueOrZero(),
 		DeploymentLogsEndpoint: cnf.DeploymentLogsURL(d.AppID, d.ID),
 		DeploymentEndpoint:     cnf.PreviewURL(d.DisplayName, d.ID.String()),
+		Branch:                 d.Branch,
 	}
 
-	for _, wh := range whs {
-		if status == "success" && wh.TriggerOnDeploySuccess() {
-			wh.Dispatch(args)
-		}
-
-		if status == "failed" && wh.TriggerOnDeployFailed() {
-			wh.Dispatch(args)
-		}
-	}
+	app.TriggerEvent(ctx, event, args)
 
 	if StatusChecksEnabled {
 		statusChecks(details, d)
@@ -36,6 +36,10 @@ func pullRequestPreview(details *AppDetails, d *deploy.Deployment) {
 			"#### Deployment completed\n\n" +
 				"This pull request was successfully built by **[Stormkit](https://www.stormkit.io)**. You can preview it using the following link.\n" +
 				fmt.Sprintf("> %s", cnf.PreviewURL(details.DisplayName, d.ID.String()))
+
+		if summary := deploymentDiffSummary(d); summary != "" {
+			body = body + "\n\n<details>\n<summary>Changes</summary>\n\n" + summary + "\n\n</details>"
+		}
 	default:
 		body =
 			"#### Deployment failed\n\n" +
@@ -53,6 +57,32 @@ func pullRequestPreview(details *AppDetails, d *deploy.Deployment) {
 	}
 }
 
+// deploymentDiffSummary compares the deployment with the published deployment of
+// the environment. It returns an empty string when there is nothing to compare.
+func deploymentDiffSummary(d *deploy.Deployment) string {
+	ctx := context.Background()
+	store := deploy.NewStore()
+	publishedID, err := store.PublishedDeploymentID(ctx, d.EnvID)
+
+	if err != nil || publishedID == 0 || publishedID == d.ID {
+		return ""
+	}
+
+	base, err := store.MyDeployment(ctx, &deploy.DeploymentsQueryFilters{DeploymentID: publishedID})
+
+	if err != nil || base == nil {
+		return ""
+	}
+
+	head, err := store.MyDeployment(ctx, &deploy.DeploymentsQueryFilters{DeploymentID: d.ID})
+
+	if err != nil || head == nil {
+		return ""
+	}
+
+	return deploy.Diff(base, head).Summary()
+}
+
 // PullRequestPreviewGithub creates a pull request preview for Github projects.
 var PullRequestPreviewGithub = func(details *AppDetails, body string) {
 	client, err := github.NewApp(details.Repo)
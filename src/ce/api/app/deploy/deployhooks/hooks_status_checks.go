@@ -0,0 +1,173 @@
+package deployhooks
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/bitbucket"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/github"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth/gitlab"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// StatusCheckResults posts the result of each status check as a separate
+// commit status to the git provider.
+var StatusCheckResults = func(ctx context.Context, d *deploy.Deployment) {
+	sha := d.Commit.ID.ValueOrZero()
+
+	if !StatusChecksEnabled || sha == "" || len(d.StatusCheckResults) == 0 {
+		return
+	}
+
+	details, err := NewStore().AppDetailsForHooks(d.ID)
+
+	if err != nil {
+		slog.Errorf("failed while fetching details for provider: %v", err)
+		return
+	}
+
+	if details == nil {
+		return
+	}
+
+	url := admin.MustConfig().DeploymentLogsURL(d.AppID, d.ID)
+
+	switch {
+	case strings.HasPrefix(details.Repo, "github/"):
+		statusCheckResultsGithub(details, sha, url, d.StatusCheckResults)
+	case strings.HasPrefix(details.Repo, "gitlab"):
+		statusCheckResultsGitlab(details, sha, url, d.StatusCheckResults)
+	case strings.HasPrefix(details.Repo, "bitbucket"):
+		statusCheckResultsBitbucket(details, sha, url, d.StatusCheckResults)
+	}
+}
+
+// statusCheckDescription returns a short description of the result.
+func statusCheckDescription(result deploy.StatusCheckResult) string {
+	var description string
+
+	switch result.Status {
+	case deploy.StatusCheckPassed:
+		description = "Passed"
+	case deploy.StatusCheckTimeout:
+		description = "Timed out"
+	case deploy.StatusCheckSkipped:
+		description = "Skipped"
+	default:
+		description = "Failed"
+	}
+
+	if result.Tests > 0 {
+		description = fmt.Sprintf("%s: %d/%d tests passed", description, result.Tests-result.Failures, result.Tests)
+	} else if result.Status != deploy.StatusCheckSkipped {
+		description = fmt.Sprintf("%s in %.1fs", description, float64(result.Duration)/1000)
+	}
+
+	if !result.Required {
+		description = description + " (optional)"
+	}
+
+	return description
+}
+
+// statusCheckContext returns the name that is used to distinguish the checks.
+func statusCheckContext(result deploy.StatusCheckResult) string {
+	return fmt.Sprintf("Stormkit / %s", result.Name)
+}
+
+func statusCheckResultsGithub(details *AppDetails, sha, url string, results deploy.StatusCheckResults) {
+	if !admin.MustConfig().IsGithubEnabled() {
+		return
+	}
+
+	for _, result := range results {
+		status := github.StatusFailure
+
+		// Optional checks should not block the pull request
+		if result.IsSuccess() || !result.Required {
+			status = github.StatusSuccess
+		}
+
+		err := github.CreateCommitStatus(details.Repo, sha, url, status, statusCheckContext(result), statusCheckDescription(result))
+
+		if err != nil {
+			slog.Errorf("error while creating github status check result: %v", err)
+			return
+		}
+	}
+}
+
+func statusCheckResultsGitlab(details *AppDetails, sha, url string, results deploy.StatusCheckResults) {
+	client, err := gitlab.NewClient(details.UserID)
+
+	if err != nil || client == nil {
+		slog.Errorf("failed while creating gitlab client: %v", err)
+		return
+	}
+
+	pid := client.SanitizeRepo(details.Repo)
+
+	for _, result := range results {
+		name := statusCheckContext(result)
+		description := statusCheckDescription(result)
+		opts := &gitlab.SetCommitStatusOptions{
+			State:       gitlab.Failed,
+			Name:        &name,
+			TargetURL:   &url,
+			Description: &description,
+		}
+
+		if result.IsSuccess() || !result.Required {
+			opts.State = gitlab.Success
+		}
+
+		if result.Status == deploy.StatusCheckSkipped {
+			opts.State = gitlab.Skipped
+		}
+
+		if _, _, err := client.Commits.SetCommitStatus(pid, sha, opts); err != nil {
+			slog.Errorf("error while creating gitlab status check result: %v", err)
+			return
+		}
+	}
+}
+
+func statusCheckResultsBitbucket(details *AppDetails, sha, url string, results deploy.StatusCheckResults) {
+	client, err := bitbucket.NewClientWithScope(details.UserID, []string{
+		bitbucket.PermissionRepositoryWrite,
+	})
+
+	if err != nil || client == nil {
+		slog.Errorf("failed while creating bitbucket client: %v", err)
+		return
+	}
+
+	app := &bitbucket.App{Repo: details.Repo}
+
+	for i, result := range results {
+		status := bitbucket.CommitStatus{
+			// Keys are limited to 40 characters, use the index to keep them unique
+			Key:         fmt.Sprintf("stormkit-status-check-%d", i),
+			State:       bitbucket.CommitStatusFailed,
+			Name:        statusCheckContext(result),
+			URL:         url,
+			Description: statusCheckDescription(result),
+		}
+
+		if result.IsSuccess() || !result.Required {
+			status.State = bitbucket.CommitStatusSuccessful
+		}
+
+		if result.Status == deploy.StatusCheckSkipped {
+			status.State = bitbucket.CommitStatusStopped
+		}
+
+		if err := client.CreateCommitStatus(app, sha, status); err != nil {
+			slog.Errorf("error while creating bitbucket status check result: %v", err)
+			return
+		}
+	}
+}
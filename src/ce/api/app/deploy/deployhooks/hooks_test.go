@@ -2,14 +2,17 @@ package deployhooks_test
 
 import (
 	"context"
-	"io"
-	"net/http"
+	"encoding/json"
+	"fmt"
 	"testing"
 
+	"github.com/hibiken/asynq"
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
 	"github.com/stormkit-io/stormkit-io/src/mocks"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
@@ -19,7 +22,6 @@ import (
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
-	"github.com/stormkit-io/stormkit-io/src/lib/testutils"
 )
 
 type HooksSuite struct {
@@ -28,6 +30,8 @@ type HooksSuite struct {
 
 	conn             databasetest.TestDB
 	mockCacheService *mocks.CacheInterface
+	mockClient       *mocks.TaskClient
+	originalClient   func() tasks.TaskClient
 	calledSettings   []*deploy.PublishSettings
 	originalPublish  func(ctx context.Context, settings []*deploy.PublishSettings) error
 }
@@ -41,6 +45,11 @@ func (s *HooksSuite) BeforeTest(suiteName, _ string) {
 	s.Factory = factory.New(s.conn)
 	s.mockCacheService = &mocks.CacheInterface{}
 	appcache.DefaultCacheService = s.mockCacheService
+	s.mockClient = &mocks.TaskClient{}
+	s.originalClient = tasks.Client
+	tasks.Client = func() tasks.TaskClient {
+		return s.mockClient
+	}
 
 	deployhooks.Publish = func(ctx context.Context, settings []*deploy.PublishSettings) error {
 		s.calledSettings = settings
@@ -51,26 +60,12 @@ func (s *HooksSuite) BeforeTest(suiteName, _ string) {
 func (s *HooksSuite) AfterTest(_, _ string) {
 	appcache.DefaultCacheService = nil
 	deployhooks.Publish = nil
+	tasks.Client = s.originalClient
 	s.conn.CloseTx()
 }
 
 func (s *HooksSuite) TestOutboundWebhooks() {
 	a := assert.New(s.T())
-	ms := testutils.MockServer()
-	mr := testutils.MockResponse{
-		Status: 200,
-		Method: shttp.MethodPost,
-		Expect: func(req *http.Request) {
-			b, _ := io.ReadAll(req.Body)
-			post := string(b)
-
-			a.Equal(req.Header.Get("Content-Type"), "application/json")
-			a.JSONEq(post, `{"deployment_id": "1", "env_name": "production"}`)
-		},
-	}
-
-	ms.NewResponse("/", &mr)
-	defer ms.Close()
 
 	depl := s.MockDeployment(nil, map[string]interface{}{
 		"ExitCode":          null.NewInt(0, true),
@@ -78,17 +73,19 @@ func (s *HooksSuite) TestOutboundWebhooks() {
 		"ShouldPublish":     true,
 	})
 
-	err := app.NewStore().InsertOutboundWebhook(context.Background(), 1, app.OutboundWebhook{
+	wh := &app.OutboundWebhook{
 		TriggerWhen:    app.TriggerOnDeploySuccess,
-		RequestURL:     ms.URL(),
+		RequestURL:     "https://example.org/webhooks",
 		RequestMethod:  shttp.MethodPost,
 		RequestPayload: null.NewString(`{ "deployment_id": "$SK_DEPLOYMENT_ID", "env_name": "$SK_ENVIRONMENT" }`, true),
 		RequestHeaders: map[string]string{
 			"Content-Type": "application/json",
 		},
-	})
+	}
 
-	a.NoError(err)
+	a.NoError(app.NewStore().InsertOutboundWebhook(context.Background(), s.GetApp().ID, wh))
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
 
 	deployhooks.Exec(context.Background(), &deploy.Deployment{
 		ID:            depl.ID,
@@ -100,7 +97,15 @@ func (s *HooksSuite) TestOutboundWebhooks() {
 		ExitCode:      null.NewInt(0, true),
 	})
 
-	a.Equal(mr.NumberOfCalls, 1)
+	s.mockClient.AssertNumberOfCalls(s.T(), "Enqueue", 1)
+	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		msg := app.OutboundWebhookMessage{}
+		a.NoError(json.Unmarshal(task.Payload(), &msg))
+
+		return a.Equal(tasks.OutboundWebhookDelivery, task.Type()) &&
+			a.Equal(wh.WebhookID, msg.WebhookID) &&
+			a.JSONEq(fmt.Sprintf(`{"deployment_id": "%s", "env_name": "production"}`, depl.ID.String()), msg.Payload)
+	}))
 }
 
 func (s *HooksSuite) TestShouldNotPublish_WhenShouldPublishIsFalse() {
@@ -53,42 +53,58 @@ type APIDeployResponse struct {
 	Published         []map[string]any `json:"published,omitempty"`
 	Branch            string           `json:"branch"`
 	Logs              []*Log           `json:"logs"`
+
+	StatusCheckResults StatusCheckResults `json:"statusCheckResults,omitempty"`
 }
 
 // Deployment represents a deployment.
 type Deployment struct {
-	ID                 types.ID       `json:"id,string,omitempty" db:"deployment_id"`
-	AppID              types.ID       `json:"appId,string,omitempty" db:"app_id"`
-	EnvID              types.ID       `json:"envId,string,omitempty" db:"env_id"`
-	Env                string         `json:"env,omitempty" db:"env_name"` // @deprecated: Env is the environment name used for this deployment. Will be replaced with EnvID
-	Branch             string         `json:"branch" db:"branch"`          // Branch is name of the branch that was deployed.
-	ConfigCopy         []byte         `json:"-" db:"config_snapshot"`
-	configCopyCached   map[string]any `json:"-"`
-	S3NumberOfFiles    null.Int       `json:"numberOfFiles" db:"s3_number_of_files"`
-	S3TotalSizeInBytes null.Int       `json:"totalSizeInBytes"`
-	ServerPackageSize  null.Int       `json:"serverPackageSize,omitempty" db:"server_package_size"`
-	ExitCode           null.Int       `json:"exit" db:"exit_code"`
-	Logs               null.String    `json:"-" db:"logs"`
-	PullRequestNumber  null.Int       `json:"pullRequestNumber" db:"pull_request_number"`
-	IsFork             bool           `json:"isFork" db:"is_fork"`
-	CheckoutRepo       string         `json:"checkoutRepo" db:"checkout_repo"` // CheckoutRepo is the repository used to check out while deploying the application.
-	BuildManifest      *BuildManifest `json:"buildManifest,omitempty" db:"build_manifest"`
-	ShouldPublish      bool           `json:"shouldPublish" db:"auto_publish"` // ShouldPublish is boolean value which stores an overwrite for the AutoPublish field of an environment.
-	IsAutoDeploy       bool           `json:"isAutoDeploy" db:"auto_deploy"`
-	IsImmutable        null.Bool      `json:"-"`
-	StatusChecks       null.String    `json:"-"`
-	StatusChecksPassed null.Bool      `json:"statusChecksPassed,omitempty"`
-	CreatedAt          utils.Unix     `json:"createdAt,omitempty" db:"created_at"`
-	StoppedAt          utils.Unix     `json:"stoppedAt,omitempty" db:"stopped_at"`
-	DeletedAt          utils.Unix     `json:"deletedAt,omitempty" db:"deleted_at"`
-	Commit             CommitInfo     `json:"commit" db:"commit_id, commit_author, commit_message"`
-	Error              null.String    `json:"-" db:"error"` // Error represents the deployment error. It's for internal use only.
-	StorageLocation    null.String    `json:"storageLocation,omitempty" db:"storage_location"`
-	FunctionLocation   null.String    `json:"functionLocation,omitempty" db:"function_location"` // aws:<function-arn>/<version>
-	APILocation        null.String    `json:"apiLocation,omitempty" db:"api_location"`           // aws:<function-arn>/<version>
-	APIPathPrefix      null.String    `json:"apiPathPrefix,omitempty"`
-	APIPackageSize     null.Int       `json:"apiPackageSize,omitempty" db:"api_package_size"`
-	WebhookEvent       any            `json:"-"` // The webhook event that triggers the deployment
+	ID                 types.ID           `json:"id,string,omitempty" db:"deployment_id"`
+	AppID              types.ID           `json:"appId,string,omitempty" db:"app_id"`
+	EnvID              types.ID           `json:"envId,string,omitempty" db:"env_id"`
+	Env                string             `json:"env,omitempty" db:"env_name"` // @deprecated: Env is the environment name used for this deployment. Will be replaced with EnvID
+	Branch             string             `json:"branch" db:"branch"`          // Branch is name of the branch that was deployed.
+	ConfigCopy         []byte             `json:"-" db:"config_snapshot"`
+	configCopyCached   map[string]any     `json:"-"`
+	S3NumberOfFiles    null.Int           `json:"numberOfFiles" db:"s3_number_of_files"`
+	S3TotalSizeInBytes null.Int           `json:"totalSizeInBytes"`
+	ServerPackageSize  null.Int           `json:"serverPackageSize,omitempty" db:"server_package_size"`
+	ExitCode           null.Int           `json:"exit" db:"exit_code"`
+	Logs               null.String        `json:"-" db:"logs"`
+	PullRequestNumber  null.Int           `json:"pullRequestNumber" db:"pull_request_number"`
+	IsFork             bool               `json:"isFork" db:"is_fork"`
+	CheckoutRepo       string             `json:"checkoutRepo" db:"checkout_repo"` // CheckoutRepo is the repository used to check out while deploying the application.
+	BuildManifest      *BuildManifest     `json:"buildManifest,omitempty" db:"build_manifest"`
+	ShouldPublish      bool               `json:"shouldPublish" db:"auto_publish"` // ShouldPublish is boolean value which stores an overwrite for the AutoPublish field of an environment.
+	IsAutoDeploy       bool               `json:"isAutoDeploy" db:"auto_deploy"`
+	IsImmutable        null.Bool          `json:"-"`
+	StatusChecks       null.String        `json:"-"`
+	StatusChecksPassed null.Bool          `json:"statusChecksPassed,omitempty"`
+	StatusCheckResults StatusCheckResults `json:"statusCheckResults,omitempty" db:"status_check_results"`
+	CreatedAt          utils.Unix         `json:"createdAt,omitempty" db:"created_at"`
+	StoppedAt          utils.Unix         `json:"stoppedAt,omitempty" db:"stopped_at"`
+	DeletedAt          utils.Unix         `json:"deletedAt,omitempty" db:"deleted_at"`
+	Commit             CommitInfo         `json:"commit" db:"commit_id, commit_author, commit_message"`
+	Error              null.String        `json:"-" db:"error"` // Error represents the deployment error. It's for internal use only.
+	StorageLocation    null.String        `json:"storageLocation,omitempty" db:"storage_location"`
+	FunctionLocation   null.String        `json:"functionLocation,omitempty" db:"function_location"` // aws:<function-arn>/<version>
+	APILocation        null.String        `json:"apiLocation,omitempty" db:"api_location"`           // aws:<function-arn>/<version>
+	APIPathPrefix      null.String        `json:"apiPathPrefix,omitempty"`
+	APIPackageSize     null.Int           `json:"apiPackageSize,omitempty" db:"api_package_size"`
+	WebhookEvent       any                `json:"-"` // The webhook event that triggers the deployment
+
+	// PromotedFrom is the id of the deployment whose artifacts were promoted
+	// to create this deployment. It is not set for deployments that were built.
+	PromotedFrom null.Int `json:"promotedFrom,omitempty" db:"promoted_from"`
+
+	// Pinned deployments and deployments with a tag are never removed
+	// by the retention policy.
+	Pinned bool        `json:"pinned" db:"is_pinned"`
+	Tag    null.String `json:"tag,omitempty" db:"deployment_tag"`
+
+	// Queue is the state of the deployment in the build queue. It is nil
+	// for deployments that were not queued.
+	Queue *QueueInfo `json:"queue,omitempty"`
 
 	// GithubRunID is the associated run id with the deployment.
 	// It is obtained by printing $GITHUB_RUN_ID in GitHub actions.
@@ -109,6 +125,7 @@ type Deployment struct {
 	LambdaRuntime string               `json:"-"` // LambdaRuntime specifies the default runtime for the application.
 	AppPackage    string               `json:"-"` // The application package (free, starter, medium, enterprise)
 	IsRestart     bool                 `json:"-"`
+	PinnedCommit  string               `json:"-"` // PinnedCommit is the commit to check out instead of the head of the branch. It is set by deploy hooks.
 }
 
 // PublishedInfo represents information on the publish details
@@ -210,6 +227,8 @@ func (d *Deployment) MarshalJSON() ([]byte, error) {
 		TotalSizeInBytes:  int(d.S3TotalSizeInBytes.ValueOrZero()),
 		ServerPackageSize: int(d.ServerPackageSize.ValueOrZero()),
 		IsRunning:         exitCode == 0 && !d.ExitCode.Valid,
+
+		StatusCheckResults: d.StatusCheckResults,
 	}
 
 	if d.ExitCode.Valid {
@@ -11,28 +11,61 @@ var (
 )
 
 type statement struct {
-	selectDeployment         string
-	selectDeployments        string
-	selectDeploymentsV2      string
-	selectDeploymentWithLogs string
-	selectBuildManifest      string
-	insertDeployment         string
-	restartDeployment        string
-	updateExitCode           string
-	updateCommitInfo         string
-	updateLogs               string
-	updateStatusChecks       string
-	lockDeployment           string
-	markDeploymentsAsDeleted string
-	isDeploymentAlreadyBuilt string
-	stopDeployment           string
-	stopStatusChecks         string
-	selectExitCode           string
-	updateGithubRunID        string
-	updateDeploymentResult   string
-	markArtifactsAsDeleted   string
-	publish                  string
-	updateUserMetrics        string
+	selectDeployment          string
+	selectDeployments         string
+	selectDeploymentsV2       string
+	selectDeploymentWithLogs  string
+	selectBuildManifest       string
+	selectSBOM                string
+	insertDeployment          string
+	deletePromotedDeployment  string
+	insertPromotedDeployment  string
+	restartDeployment         string
+	updateExitCode            string
+	updateCommitInfo          string
+	updateLogs                string
+	updateStatusChecks        string
+	lockDeployment            string
+	updateStatusCheckResults  string
+	updateSBOM                string
+	updateConfigSnapshot      string
+	markDeploymentsAsDeleted  string
+	isDeploymentAlreadyBuilt  string
+	stopDeployment            string
+	stopStatusChecks          string
+	selectExitCode            string
+	updateGithubRunID         string
+	updateDeploymentResult    string
+	markArtifactsAsDeleted    string
+	publish                   string
+	updateUserMetrics         string
+	selectPublishedDeployment string
+	selectRollouts            string
+	insertRollout             string
+	updateRollout             string
+	cancelRollouts            string
+	selectScheduledPublishes  string
+	insertScheduledPublish    string
+	updateScheduledPublish    string
+	cancelScheduledPublish    string
+	selectPublishApprovals    string
+	insertPublishApproval     string
+	lockPublishApproval       string
+	countApprovalReviews      string
+	updatePublishApproval     string
+	reopenPublishApproval     string
+	cancelPublishApprovals    string
+	upsertApprovalReview      string
+	updateDeploymentRetention string
+	selectExpiredDeployments  string
+	upsertQueuedDeployment    string
+	cancelSupersededQueue     string
+	cancelStoppedQueue        string
+	selectQueuedDeployments   string
+	selectQueueInfo           string
+	selectRunningQueueCounts  string
+	markQueuedDispatched      string
+	removeOldQueueEntries     string
 }
 
 var stmt = &statement{
@@ -67,7 +100,13 @@ var stmt = &statement{
 			d.api_location, d.api_package_size, d.server_package_size,
 			d.s3_number_of_files, d.client_package_size,
 			d.api_path_prefix, d.is_immutable,
-			d.status_checks_passed,
+			d.status_checks_passed, d.status_check_results, d.promoted_from,
+			d.is_pinned, d.deployment_tag,
+			(SELECT jsonb_build_object(
+				'status', q.queue_status,
+				'queuedAt', EXTRACT(EPOCH FROM q.queued_at)::bigint,
+				'waitTime', EXTRACT(EPOCH FROM COALESCE(q.dispatched_at, NOW() AT TIME ZONE 'UTC') - q.queued_at)::bigint
+			) FROM deployments_queue q WHERE q.deployment_id = d.deployment_id) as queue,
 			{{ if .logs }} d.status_checks, d.logs {{ else }} '', '' {{ end }},
 			a.display_name, COALESCE(a.repo, ''),
 			(SELECT json_agg(
@@ -112,7 +151,8 @@ var stmt = &statement{
 			d.exit_code, d.server_package_size, d.config_snapshot,
 			d.s3_number_of_files, d.client_package_size, d.stopped_at, d.logs,
 			d.commit_id, d.commit_author, d.commit_message, COALESCE(d.is_fork, FALSE),
-			d.github_run_id, d.error, d.api_package_size
+			d.github_run_id, d.error, d.api_package_size,
+			d.status_check_results
 		FROM %s d
 		WHERE
 			d.deployment_id = $1 AND
@@ -127,6 +167,15 @@ var stmt = &statement{
 			d.app_id = $2
 	`, tableDeploys),
 
+	selectSBOM: fmt.Sprintf(`
+		SELECT
+		    d.sbom
+		FROM %s d
+		WHERE
+			d.deployment_id = $1 AND
+			d.app_id = $2
+	`, tableDeploys),
+
 	insertDeployment: `
 		INSERT INTO deployments (
 			app_id, config_snapshot, branch, env_name, env_id,
@@ -145,6 +194,46 @@ var stmt = &statement{
 			created_at;
 	`,
 
+	insertPromotedDeployment: `
+		INSERT INTO deployments (
+			app_id, env_id, env_name, config_snapshot, runtime_vars, promoted_from,
+			branch, checkout_repo, is_fork, pull_request_number,
+			commit_id, commit_author, commit_message,
+			storage_location, function_location, api_location, api_path_prefix,
+			build_manifest, sbom, s3_number_of_files, client_package_size,
+			server_package_size, api_package_size,
+			status_checks_passed, status_check_results,
+			exit_code, stopped_at, is_immutable
+		)
+		SELECT
+			d.app_id, $2, $3, $4, $5, d.deployment_id,
+			d.branch, d.checkout_repo, d.is_fork, d.pull_request_number,
+			d.commit_id, d.commit_author, d.commit_message,
+			d.storage_location, d.function_location, d.api_location, d.api_path_prefix,
+			d.build_manifest, d.sbom, d.s3_number_of_files, d.client_package_size,
+			d.server_package_size, d.api_package_size,
+			d.status_checks_passed, d.status_check_results,
+			0, NOW() AT TIME ZONE 'UTC', TRUE
+		FROM deployments d
+		WHERE
+			d.deployment_id = $1 AND
+			d.deleted_at IS NULL
+		RETURNING
+			deployment_id,
+			created_at;
+	`,
+
+	deletePromotedDeployment: `
+		DELETE FROM deployments d
+		WHERE
+			d.deployment_id = $1 AND
+			d.promoted_from IS NOT NULL AND
+			NOT EXISTS (
+				SELECT 1 FROM deployments_published dp
+				WHERE dp.deployment_id = d.deployment_id
+			);
+	`,
+
 	restartDeployment: `
 		UPDATE deployments SET
 			created_at = NOW() AT TIME ZONE 'UTC',
@@ -198,6 +287,30 @@ var stmt = &statement{
 			is_immutable IS NOT TRUE;
 	`,
 
+	updateConfigSnapshot: `
+		UPDATE deployments SET
+			config_snapshot = $1
+		WHERE
+			deployment_id = $2 AND
+			is_immutable IS NOT TRUE;
+	`,
+
+	updateStatusCheckResults: `
+		UPDATE deployments SET
+			status_check_results = $1
+		WHERE
+			deployment_id = $2 AND
+			is_immutable IS NOT TRUE;
+	`,
+
+	updateSBOM: `
+		UPDATE deployments SET
+			sbom = $1
+		WHERE
+			deployment_id = $2 AND
+			is_immutable IS NOT TRUE;
+	`,
+
 	markDeploymentsAsDeleted: fmt.Sprintf(`
 		UPDATE %s
 		SET
@@ -279,6 +392,10 @@ var stmt = &statement{
 		update_ts AS (
 			UPDATE apps_build_conf e SET updated_at = NOW()
 			WHERE e.env_id = ANY({{ .envIDsParam }})
+		),
+		update_last_published AS (
+			UPDATE deployments d SET last_published_at = NOW() AT TIME ZONE 'UTC'
+			WHERE d.deployment_id = ANY({{ .deploymentIDsParam }})
 		)
 		INSERT INTO deployments_published
 			(env_id, deployment_id, percentage_released)
@@ -312,4 +429,412 @@ var stmt = &statement{
 			build_minutes = user_metrics.build_minutes + EXCLUDED.build_minutes,
 			storage_bytes = user_metrics.storage_bytes + EXCLUDED.storage_bytes;
 	`,
+
+	selectPublishedDeployment: `
+		SELECT dp.deployment_id
+		FROM deployments_published dp
+		WHERE dp.env_id = $1
+		ORDER BY dp.percentage_released DESC, dp.created_at DESC
+		LIMIT 1;
+	`,
+
+	selectRollouts: `
+		SELECT
+			r.rollout_id, r.app_id, r.env_id, r.deployment_id,
+			COALESCE(r.previous_deployment_id, 0), r.rollout_plan,
+			r.current_step, r.rollout_status, r.status_reason,
+			r.next_step_at, r.updated_at, r.created_at
+		FROM rollouts r
+		{{ .where }}
+		ORDER BY r.rollout_id DESC
+		LIMIT {{ .limit }};
+	`,
+
+	insertRollout: `
+		INSERT INTO rollouts (
+			app_id, env_id, deployment_id, previous_deployment_id,
+			rollout_plan, current_step, rollout_status, status_reason,
+			next_step_at
+		)
+		VALUES (
+			$1, $2, $3, NULLIF($4, 0),
+			$5, $6, $7, $8,
+			$9
+		)
+		RETURNING
+			rollout_id, created_at;
+	`,
+
+	updateRollout: `
+		UPDATE rollouts SET
+			current_step = $1,
+			rollout_status = $2,
+			status_reason = $3,
+			next_step_at = $4,
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			rollout_id = $5 AND
+			rollout_status = 'running';
+	`,
+
+	cancelRollouts: `
+		UPDATE rollouts SET
+			rollout_status = 'cancelled',
+			status_reason = $2,
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			env_id = $1 AND
+			rollout_status = 'running';
+	`,
+
+	selectScheduledPublishes: `
+		SELECT
+			sp.schedule_id, sp.app_id, sp.env_id, sp.publish_config,
+			sp.publish_at, sp.schedule_status, sp.status_reason,
+			COALESCE(sp.created_by, 0), sp.executed_at, sp.created_at
+		FROM scheduled_publishes sp
+		{{ .where }}
+		ORDER BY sp.publish_at ASC
+		LIMIT {{ .limit }};
+	`,
+
+	insertScheduledPublish: `
+		INSERT INTO scheduled_publishes (
+			app_id, env_id, publish_config, publish_at,
+			schedule_status, created_by
+		)
+		VALUES (
+			$1, $2, $3, $4,
+			$5, NULLIF($6, 0)
+		)
+		RETURNING
+			schedule_id, created_at;
+	`,
+
+	updateScheduledPublish: `
+		UPDATE scheduled_publishes SET
+			schedule_status = $1,
+			status_reason = $2,
+			executed_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			schedule_id = $3 AND
+			schedule_status = 'pending';
+	`,
+
+	cancelScheduledPublish: `
+		UPDATE scheduled_publishes SET
+			schedule_status = 'cancelled'
+		WHERE
+			schedule_id = $1 AND
+			env_id = $2 AND
+			schedule_status = 'pending';
+	`,
+
+	selectPublishApprovals: `
+		SELECT
+			pa.approval_id, pa.app_id, pa.env_id, pa.publish_config,
+			pa.required_approvals, pa.approval_status, COALESCE(pa.requested_by, 0),
+			COALESCE((
+				SELECT json_agg(json_build_object(
+					'userId', r.user_id::text,
+					'decision', r.review_decision,
+					'comment', COALESCE(r.review_comment, ''),
+					'createdAt', EXTRACT(EPOCH FROM r.created_at)::bigint
+				) ORDER BY r.created_at)
+				FROM publish_approval_reviews r
+				WHERE r.approval_id = pa.approval_id
+			), '[]'),
+			pa.updated_at, pa.created_at
+		FROM publish_approvals pa
+		{{ .where }}
+		ORDER BY pa.approval_id DESC
+		LIMIT {{ .limit }};
+	`,
+
+	insertPublishApproval: `
+		INSERT INTO publish_approvals (
+			app_id, env_id, publish_config, required_approvals,
+			approval_status, requested_by
+		)
+		VALUES (
+			$1, $2, $3, $4,
+			$5, NULLIF($6, 0)
+		)
+		RETURNING
+			approval_id, created_at;
+	`,
+
+	lockPublishApproval: `
+		SELECT
+			approval_status, required_approvals
+		FROM publish_approvals
+		WHERE
+			approval_id = $1
+		FOR UPDATE;
+	`,
+
+	countApprovalReviews: `
+		SELECT
+			COUNT(*)
+		FROM publish_approval_reviews
+		WHERE
+			approval_id = $1 AND
+			review_decision = 'approve';
+	`,
+
+	updatePublishApproval: `
+		UPDATE publish_approvals SET
+			approval_status = $1,
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			approval_id = $2 AND
+			approval_status = 'pending'
+		RETURNING
+			approval_id;
+	`,
+
+	reopenPublishApproval: `
+		UPDATE publish_approvals SET
+			approval_status = 'pending',
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			approval_id = $1 AND
+			approval_status = 'approved';
+	`,
+
+	cancelPublishApprovals: `
+		UPDATE publish_approvals SET
+			approval_status = 'cancelled',
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			env_id = $1 AND
+			approval_status = 'pending';
+	`,
+
+	upsertApprovalReview: `
+		INSERT INTO publish_approval_reviews (
+			approval_id, user_id, review_decision, review_comment
+		)
+		VALUES (
+			$1, $2, $3, NULLIF($4, '')
+		)
+		ON CONFLICT (approval_id, user_id) DO UPDATE SET
+			review_decision = EXCLUDED.review_decision,
+			review_comment = EXCLUDED.review_comment,
+			created_at = NOW() AT TIME ZONE 'UTC';
+	`,
+
+	updateDeploymentRetention: `
+		UPDATE deployments SET
+			is_pinned = $1,
+			deployment_tag = NULLIF($2, '')
+		WHERE
+			deployment_id = $3;
+	`,
+
+	// The retention policy of the environment takes precedence over the default
+	// policy, which is passed as a json parameter. Currently published, pinned
+	// and tagged deployments are never selected. Promoted deployments share the
+	// artifacts of their source, so a deployment is not selected either while
+	// another deployment that is not expired uses one of its locations. Each
+	// location is checked separately, so that its own index can be used. Only
+	// the environments that have a deployment old enough to expire are ranked.
+	selectExpiredDeployments: `
+		WITH envs AS (
+			SELECT
+				e.env_id, e.branch,
+				COALESCE(e.build_conf->'retention', $1::jsonb) AS policy
+			FROM apps_build_conf e
+			{{ if .envID }} WHERE e.env_id = {{ .envID }} {{ end }}
+		),
+		affected_envs AS (
+			SELECT envs.env_id, envs.branch, envs.policy
+			FROM envs
+			WHERE EXISTS (
+				SELECT 1 FROM deployments d
+				WHERE
+					d.env_id = envs.env_id AND
+					d.artifacts_deleted IS NOT TRUE AND
+					(
+						d.deleted_at IS NOT NULL OR
+						d.created_at < NOW() - make_interval(days => LEAST(
+							NULLIF((envs.policy->>'previewMaxAgeDays')::int, 0),
+							COALESCE(NULLIF((envs.policy->>'maxAgeDays')::int, 0), {{ .maxAgeDays }})
+						))
+					)
+			)
+		),
+		candidates AS (
+			SELECT
+				d.deployment_id, d.app_id, d.env_id, d.storage_location,
+				d.function_location, d.api_location, d.created_at,
+				d.last_published_at, d.deleted_at, d.is_pinned, d.deployment_tag,
+				(d.pull_request_number IS NOT NULL OR COALESCE(d.branch, '') <> COALESCE(ae.branch, '')) AS is_preview,
+				ROW_NUMBER() OVER (
+					PARTITION BY d.env_id, d.deleted_at IS NULL
+					ORDER BY d.deployment_id DESC
+				) AS position,
+				ae.policy
+			FROM affected_envs ae
+			JOIN deployments d ON d.env_id = ae.env_id
+			WHERE d.artifacts_deleted IS NOT TRUE
+		),
+		expired AS (
+			SELECT
+				c.deployment_id, c.app_id, c.env_id, c.storage_location,
+				c.function_location, c.api_location, c.created_at
+			FROM candidates c
+			LEFT JOIN deployments_published dp ON dp.deployment_id = c.deployment_id
+			WHERE
+				dp.deployment_id IS NULL AND
+				c.is_pinned IS NOT TRUE AND
+				c.deployment_tag IS NULL AND
+				(
+					c.deleted_at IS NOT NULL OR
+					(
+						c.position > COALESCE((c.policy->>'keepLast')::int, 0) AND
+						(
+							c.last_published_at IS NULL OR
+							c.last_published_at < NOW() - make_interval(days => COALESCE((c.policy->>'keepPublishedDays')::int, 0))
+						) AND
+						c.created_at < NOW() - make_interval(days => CASE
+							WHEN c.is_preview AND COALESCE((c.policy->>'previewMaxAgeDays')::int, 0) > 0
+							THEN (c.policy->>'previewMaxAgeDays')::int
+							ELSE COALESCE(NULLIF((c.policy->>'maxAgeDays')::int, 0), {{ .maxAgeDays }})
+						END)
+					)
+				)
+		)
+		SELECT
+			e.deployment_id, e.app_id, e.env_id, e.storage_location,
+			e.function_location, e.api_location, e.created_at
+		FROM expired e
+		WHERE
+			NOT EXISTS (
+				SELECT 1 FROM deployments o
+				WHERE
+					o.storage_location = e.storage_location AND
+					o.artifacts_deleted IS NOT TRUE AND
+					o.deployment_id <> e.deployment_id AND
+					NOT EXISTS (SELECT 1 FROM expired x WHERE x.deployment_id = o.deployment_id)
+			) AND
+			NOT EXISTS (
+				SELECT 1 FROM deployments o
+				WHERE
+					o.function_location = e.function_location AND
+					o.artifacts_deleted IS NOT TRUE AND
+					o.deployment_id <> e.deployment_id AND
+					NOT EXISTS (SELECT 1 FROM expired x WHERE x.deployment_id = o.deployment_id)
+			) AND
+			NOT EXISTS (
+				SELECT 1 FROM deployments o
+				WHERE
+					o.api_location = e.api_location AND
+					o.artifacts_deleted IS NOT TRUE AND
+					o.deployment_id <> e.deployment_id AND
+					NOT EXISTS (SELECT 1 FROM expired x WHERE x.deployment_id = o.deployment_id)
+			)
+		ORDER BY e.deployment_id ASC
+		LIMIT {{ .limit }};
+	`,
+
+	upsertQueuedDeployment: `
+		INSERT INTO deployments_queue (
+			deployment_id, app_id, env_id, team_id, branch,
+			queue_priority, queue_status, deployment_payload
+		) VALUES ($1, $2, $3, $4, $5, $6, 'queued', $7)
+		ON CONFLICT (deployment_id) DO UPDATE SET
+			queue_priority = EXCLUDED.queue_priority,
+			queue_status = 'queued',
+			deployment_payload = EXCLUDED.deployment_payload,
+			queued_at = NOW() AT TIME ZONE 'UTC',
+			dispatched_at = NULL;
+	`,
+
+	cancelSupersededQueue: `
+		WITH cancelled AS (
+			UPDATE deployments_queue SET
+				queue_status = 'cancelled'
+			WHERE
+				env_id = $1 AND
+				branch = $2 AND
+				deployment_id < $3 AND
+				queue_status = 'queued'
+			RETURNING deployment_id
+		)
+		UPDATE deployments SET
+			stopped_at = NOW() AT TIME ZONE 'UTC',
+			exit_code = -1,
+			error = $4
+		WHERE
+			deployment_id IN (SELECT deployment_id FROM cancelled) AND
+			exit_code IS NULL
+		RETURNING deployment_id;
+	`,
+
+	cancelStoppedQueue: `
+		UPDATE deployments_queue q SET
+			queue_status = 'cancelled'
+		FROM deployments d
+		WHERE
+			d.deployment_id = q.deployment_id AND
+			q.queue_status = 'queued' AND
+			(d.exit_code IS NOT NULL OR d.deleted_at IS NOT NULL);
+	`,
+
+	selectQueuedDeployments: `
+		SELECT
+			q.deployment_id, q.app_id, q.team_id, q.deployment_payload
+		FROM deployments_queue q
+		WHERE q.queue_status = 'queued'
+		ORDER BY q.queue_priority DESC, q.queued_at ASC, q.deployment_id ASC
+		LIMIT $1;
+	`,
+
+	selectQueueInfo: `
+		SELECT
+			q.queue_status,
+			CASE WHEN q.queue_status = 'queued' THEN (
+				SELECT COUNT(*) + 1 FROM deployments_queue qq
+				WHERE
+					qq.queue_status = 'queued' AND
+					(qq.queue_priority > q.queue_priority OR
+					(qq.queue_priority = q.queue_priority AND qq.queued_at < q.queued_at))
+			) ELSE 0 END,
+			EXTRACT(EPOCH FROM q.queued_at)::bigint,
+			EXTRACT(EPOCH FROM COALESCE(q.dispatched_at, NOW() AT TIME ZONE 'UTC') - q.queued_at)::bigint
+		FROM deployments_queue q
+		WHERE
+			q.deployment_id = $1 AND
+			q.app_id = $2;
+	`,
+
+	selectRunningQueueCounts: `
+		SELECT
+			q.team_id, COUNT(*)
+		FROM deployments_queue q
+		LEFT JOIN deployments d ON d.deployment_id = q.deployment_id
+		WHERE
+			q.queue_status = 'dispatched' AND
+			d.exit_code IS NULL AND
+			d.deleted_at IS NULL AND
+			q.dispatched_at > NOW() AT TIME ZONE 'UTC' - INTERVAL '6 hours'
+		GROUP BY q.team_id;
+	`,
+
+	markQueuedDispatched: `
+		UPDATE deployments_queue SET
+			queue_status = 'dispatched',
+			dispatched_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			deployment_id = $1 AND
+			queue_status = 'queued';
+	`,
+
+	removeOldQueueEntries: `
+		DELETE FROM deployments_queue
+		WHERE
+			queue_status <> 'queued' AND
+			queued_at < NOW() AT TIME ZONE 'UTC' - INTERVAL '7 days';
+	`,
 }
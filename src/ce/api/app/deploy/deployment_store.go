@@ -5,12 +5,14 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"math"
 	"strings"
 	"text/template"
 
 	"github.com/lib/pq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/database"
@@ -43,10 +45,43 @@ func (s *Store) ManifestByDeploymentID(ctx context.Context, deploymentID, appID
 	return d, err
 }
 
+// SBOMByDeploymentID returns the software bill of materials of the deployment.
+// When the deployment does not have an SBOM, nil is returned.
+func (s *Store) SBOMByDeploymentID(ctx context.Context, deploymentID, appID types.ID) (*SBOM, error) {
+	var data []byte
+
+	row, err := s.QueryRow(ctx, stmt.selectSBOM, deploymentID, appID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := row.Scan(&data); err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	if data == nil {
+		return nil, nil
+	}
+
+	sbom := &SBOM{}
+	return sbom, sbom.Scan(data)
+}
+
 type ConfigSnapshot struct {
 	BuildConfig *buildconf.BuildConf `json:"build"`
 	EnvName     string               `json:"env"`
 	EnvID       string               `json:"envId"`
+
+	// StormkitFile is the name of the config file that was found in the repository.
+	StormkitFile string `json:"stormkitFile,omitempty"`
+
+	// Triggers are the function triggers declared in the config file.
+	Triggers []buildconf.StormkitFileTrigger `json:"triggers,omitempty"`
 }
 
 // DeploymentByID returns a deployment.
@@ -134,6 +169,7 @@ func (s *Store) DeploymentByIDWithLogs(ctx context.Context, id, appID types.ID)
 			&d.S3TotalSizeInBytes, &d.StoppedAt, &d.Logs,
 			&d.Commit.ID, &d.Commit.Author, &d.Commit.Message,
 			&d.IsFork, &d.GithubRunID, &d.Error, &d.APIPackageSize,
+			&d.StatusCheckResults,
 		)
 
 		if err != nil {
@@ -227,7 +263,9 @@ func (s *Store) scanRows(rows *sql.Rows, err error) ([]*Deployment, error) {
 			&d.FunctionLocation, &d.StorageLocation, &d.APILocation,
 			&d.APIPackageSize, &d.ServerPackageSize, &d.S3NumberOfFiles,
 			&d.S3TotalSizeInBytes, &d.APIPathPrefix, &d.IsImmutable,
-			&d.StatusChecksPassed, &d.StatusChecks, &d.Logs,
+			&d.StatusChecksPassed, &d.StatusCheckResults, &d.PromotedFrom,
+			&d.Pinned, &d.Tag, &d.Queue,
+			&d.StatusChecks, &d.Logs,
 			&d.DisplayName, &d.CheckoutRepo,
 			&d.PublishedV2,
 		)
@@ -311,6 +349,43 @@ func (s *Store) MyDeployments(ctx context.Context, filters *DeploymentsQueryFilt
 	return s.scanRows(s.Query(ctx, query, params...))
 }
 
+// PreviousDeployment returns the latest successful deployment of the environment
+// that was created before the given deployment.
+func (s *Store) PreviousDeployment(ctx context.Context, d *Deployment) (*Deployment, error) {
+	query, err := s.prepareSelectDeploymentsQuery(map[string]any{
+		"where": "d.env_id = $1 AND d.deployment_id < $2 AND d.exit_code = 0",
+		"limit": 1,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	ds, err := s.scanRows(s.Query(ctx, query, d.EnvID, d.ID))
+
+	if len(ds) == 1 {
+		return ds[0], nil
+	}
+
+	return nil, err
+}
+
+// PullRequestDeployments returns the most recent deployments of the pull request,
+// the latest first.
+func (s *Store) PullRequestDeployments(ctx context.Context, appID types.ID, prNumber int64, includeLogs bool) ([]*Deployment, error) {
+	query, err := s.prepareSelectDeploymentsQuery(map[string]any{
+		"where": "d.app_id = $1 AND d.pull_request_number = $2",
+		"logs":  includeLogs,
+		"limit": 25,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.scanRows(s.Query(ctx, query, appID, prNumber))
+}
+
 // Deployments returns deployments based on the filters.
 func (s *Store) Deployments(ctx context.Context, filters *DeploymentsQueryFilters) ([]*Deployment, error) {
 	where := []string{"WHERE d.app_id = $1", "d.deleted_at IS NULL"}
@@ -424,6 +499,87 @@ func (s *Store) InsertDeployment(ctx context.Context, d *Deployment) error {
 	return row.Scan(&d.ID, &d.CreatedAt)
 }
 
+// InsertPromotedDeployment clones the artifacts of the given deployment into a
+// new deployment under the given environment. The new deployment is immutable.
+// When runtimeVars is not nil, they are used instead of the environment variables
+// at runtime. They are stored encrypted, as they may contain secrets.
+func (s *Store) InsertPromotedDeployment(ctx context.Context, sourceID types.ID, d *Deployment, runtimeVars map[string]string) error {
+	var vars []byte
+
+	if runtimeVars != nil {
+		var err error
+
+		if vars, err = sealRuntimeVars(runtimeVars); err != nil {
+			return err
+		}
+	}
+
+	row, err := s.QueryRow(ctx, stmt.insertPromotedDeployment, sourceID, d.EnvID, d.Env, d.ConfigCopy, vars)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&d.ID, &d.CreatedAt)
+}
+
+// DeletePromotedDeployment removes the given promoted deployment unless it has been
+// published. The artifacts belong to the source deployment, so they are left untouched.
+func (s *Store) DeletePromotedDeployment(ctx context.Context, id types.ID) error {
+	_, err := s.Exec(ctx, stmt.deletePromotedDeployment, id)
+	return err
+}
+
+// sealRuntimeVars encrypts the runtime variables and returns them as a JSON string.
+func sealRuntimeVars(vars map[string]string) ([]byte, error) {
+	data, err := json.Marshal(vars)
+
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted, err := utils.Encrypt(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(utils.EncodeToString(encrypted))
+}
+
+// OpenRuntimeVars returns the runtime variables of a promoted deployment. Rows
+// that were written before the variables were encrypted hold a plain JSON object.
+func OpenRuntimeVars(data []byte) (map[string]string, error) {
+	vars := map[string]string{}
+	sealed := ""
+
+	if err := json.Unmarshal(data, &sealed); err != nil {
+		if err := json.Unmarshal(data, &vars); err != nil {
+			return nil, err
+		}
+
+		return vars, nil
+	}
+
+	decoded, err := utils.DecodeString(sealed)
+
+	if err != nil {
+		return nil, err
+	}
+
+	decrypted, err := utils.Decrypt(decoded)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(decrypted, &vars); err != nil {
+		return nil, err
+	}
+
+	return vars, nil
+}
+
 // UpdateLogs will update the deployment logs for the given deployment id.
 func (s *Store) UpdateLogs(ctx context.Context, did types.ID, logs string) error {
 	if logs == "" {
@@ -588,6 +744,24 @@ func (s *Store) MarkArtifactsAsDeleted(ctx context.Context, ids []types.ID) erro
 	return err
 }
 
+// UpdateConfigSnapshot updates the config snapshot of the deployment.
+func (s *Store) UpdateConfigSnapshot(ctx context.Context, id types.ID, snapshot []byte) error {
+	_, err := s.Exec(ctx, stmt.updateConfigSnapshot, snapshot, id)
+	return err
+}
+
+// UpdateStatusCheckResults stores the individual status check results of the deployment.
+func (s *Store) UpdateStatusCheckResults(ctx context.Context, id types.ID, results StatusCheckResults) error {
+	_, err := s.Exec(ctx, stmt.updateStatusCheckResults, results, id)
+	return err
+}
+
+// UpdateSBOM stores the software bill of materials of the deployment.
+func (s *Store) UpdateSBOM(ctx context.Context, id types.ID, sbom *SBOM) error {
+	_, err := s.Exec(ctx, stmt.updateSBOM, sbom, id)
+	return err
+}
+
 // LockDeployment locks a deployment so that it becomes immutable and updates the status checks result.
 func (s *Store) LockDeployment(ctx context.Context, id types.ID, statusChecksPassed null.Bool) error {
 	_, err := s.Exec(ctx, stmt.lockDeployment, statusChecksPassed, id)
@@ -622,6 +796,7 @@ func (s *Store) Publish(ctx context.Context, settings ...*PublishSettings) error
 
 	checks := map[types.ID]types.ID{}
 	envIDs := []types.ID{}
+	deploymentIDs := []types.ID{}
 
 	tmpl, err := template.New("publish").
 		Funcs(template.FuncMap{"generateValues": utils.GenerateValues}).
@@ -641,14 +816,16 @@ func (s *Store) Publish(ctx context.Context, settings ...*PublishSettings) error
 
 		params = append(params, record.EnvID, record.DeploymentID, record.Percentage)
 		envIDs = append(envIDs, record.EnvID)
+		deploymentIDs = append(deploymentIDs, record.DeploymentID)
 		checks[record.DeploymentID] = record.EnvID
 	}
 
 	var qb strings.Builder
 
 	data := map[string]any{
-		"envIDsParam": fmt.Sprintf("$%d", len(params)+1),
-		"records":     settings,
+		"envIDsParam":        fmt.Sprintf("$%d", len(params)+1),
+		"deploymentIDsParam": fmt.Sprintf("$%d", len(params)+2),
+		"records":            settings,
 	}
 
 	if err = tmpl.Execute(&qb, data); err != nil {
@@ -656,7 +833,716 @@ func (s *Store) Publish(ctx context.Context, settings ...*PublishSettings) error
 		return err
 	}
 
-	params = append(params, pq.Array(envIDs))
+	params = append(params, pq.Array(envIDs), pq.Array(deploymentIDs))
 	_, err = s.Exec(ctx, qb.String(), params...)
 	return err
 }
+
+// PublishedDeploymentID returns the id of the deployment that receives the
+// largest share of the traffic in the given environment.
+func (s *Store) PublishedDeploymentID(ctx context.Context, envID types.ID) (types.ID, error) {
+	var id types.ID
+
+	row, err := s.QueryRow(ctx, stmt.selectPublishedDeployment, envID)
+
+	if err != nil {
+		return 0, err
+	}
+
+	if err := row.Scan(&id); err != nil && err != sql.ErrNoRows {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// RolloutFilters are the filters to query rollouts.
+type RolloutFilters struct {
+	EnvID  types.ID
+	Status string
+	Limit  int
+}
+
+// Rollouts returns the rollouts that match the given filters, the most recent first.
+func (s *Store) Rollouts(ctx context.Context, filters RolloutFilters) ([]*Rollout, error) {
+	where := []string{}
+	params := []any{}
+
+	if filters.EnvID != 0 {
+		params = append(params, filters.EnvID)
+		where = append(where, fmt.Sprintf("r.env_id = $%d", len(params)))
+	}
+
+	if filters.Status != "" {
+		params = append(params, filters.Status)
+		where = append(where, fmt.Sprintf("r.rollout_status = $%d", len(params)))
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 100
+	}
+
+	data := map[string]any{
+		"where": "",
+		"limit": filters.Limit,
+	}
+
+	if len(where) > 0 {
+		data["where"] = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	tmpl, err := template.New("selectRollouts").Parse(stmt.selectRollouts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var qb strings.Builder
+
+	if err := tmpl.Execute(&qb, data); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	rollouts := []*Rollout{}
+
+	for rows.Next() {
+		r := &Rollout{}
+		plan := []byte{}
+
+		err := rows.Scan(
+			&r.ID, &r.AppID, &r.EnvID, &r.DeploymentID,
+			&r.PreviousDeploymentID, &plan,
+			&r.CurrentStep, &r.Status, &r.Reason,
+			&r.NextStepAt, &r.UpdatedAt, &r.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(plan, &r.Plan); err != nil {
+			return nil, err
+		}
+
+		rollouts = append(rollouts, r)
+	}
+
+	return rollouts, rows.Err()
+}
+
+// RolloutByEnvID returns the most recent rollout of the given environment.
+func (s *Store) RolloutByEnvID(ctx context.Context, envID types.ID) (*Rollout, error) {
+	rollouts, err := s.Rollouts(ctx, RolloutFilters{EnvID: envID, Limit: 1})
+
+	if err != nil || len(rollouts) == 0 {
+		return nil, err
+	}
+
+	return rollouts[0], nil
+}
+
+// InsertRollout inserts a new rollout.
+func (s *Store) InsertRollout(ctx context.Context, r *Rollout) error {
+	plan, err := json.Marshal(r.Plan)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(
+		ctx, stmt.insertRollout,
+		r.AppID, r.EnvID, r.DeploymentID, r.PreviousDeploymentID,
+		plan, r.CurrentStep, r.Status, r.Reason,
+		r.NextStepAt,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&r.ID, &r.CreatedAt)
+}
+
+// UpdateRollout updates the step and the status of a running rollout.
+func (s *Store) UpdateRollout(ctx context.Context, r *Rollout) error {
+	_, err := s.Exec(ctx, stmt.updateRollout, r.CurrentStep, r.Status, r.Reason, r.NextStepAt, r.ID)
+	return err
+}
+
+// CancelRollouts cancels the running rollouts of the given environment.
+func (s *Store) CancelRollouts(ctx context.Context, envID types.ID, reason string) error {
+	_, err := s.Exec(ctx, stmt.cancelRollouts, envID, reason)
+	return err
+}
+
+// ScheduledPublishFilters are the filters to query scheduled publishes.
+type ScheduledPublishFilters struct {
+	EnvID  types.ID
+	Status string
+	Due    bool // Due returns the scheduled publishes whose publish date has passed.
+	Limit  int
+}
+
+// ScheduledPublishes returns the scheduled publishes that match the given filters,
+// the earliest first.
+func (s *Store) ScheduledPublishes(ctx context.Context, filters ScheduledPublishFilters) ([]*ScheduledPublish, error) {
+	where := []string{}
+	params := []any{}
+
+	if filters.EnvID != 0 {
+		params = append(params, filters.EnvID)
+		where = append(where, fmt.Sprintf("sp.env_id = $%d", len(params)))
+	}
+
+	if filters.Status != "" {
+		params = append(params, filters.Status)
+		where = append(where, fmt.Sprintf("sp.schedule_status = $%d", len(params)))
+	}
+
+	if filters.Due {
+		where = append(where, "sp.publish_at <= NOW() AT TIME ZONE 'UTC'")
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 100
+	}
+
+	data := map[string]any{
+		"where": "",
+		"limit": filters.Limit,
+	}
+
+	if len(where) > 0 {
+		data["where"] = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	tmpl, err := template.New("selectScheduledPublishes").Parse(stmt.selectScheduledPublishes)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var qb strings.Builder
+
+	if err := tmpl.Execute(&qb, data); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	schedules := []*ScheduledPublish{}
+
+	for rows.Next() {
+		sp := &ScheduledPublish{}
+		config := []byte{}
+
+		err := rows.Scan(
+			&sp.ID, &sp.AppID, &sp.EnvID, &config,
+			&sp.PublishAt, &sp.Status, &sp.Reason,
+			&sp.CreatedBy, &sp.ExecutedAt, &sp.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(config, &sp.Config); err != nil {
+			return nil, err
+		}
+
+		schedules = append(schedules, sp)
+	}
+
+	return schedules, rows.Err()
+}
+
+// InsertScheduledPublish inserts a new scheduled publish.
+func (s *Store) InsertScheduledPublish(ctx context.Context, sp *ScheduledPublish) error {
+	config, err := json.Marshal(sp.Config)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(
+		ctx, stmt.insertScheduledPublish,
+		sp.AppID, sp.EnvID, config, sp.PublishAt,
+		sp.Status, sp.CreatedBy,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&sp.ID, &sp.CreatedAt)
+}
+
+// UpdateScheduledPublish records the outcome of a pending scheduled publish.
+func (s *Store) UpdateScheduledPublish(ctx context.Context, sp *ScheduledPublish) error {
+	_, err := s.Exec(ctx, stmt.updateScheduledPublish, sp.Status, sp.Reason, sp.ID)
+	return err
+}
+
+// CancelScheduledPublish cancels a pending scheduled publish. It returns false
+// when there is no pending scheduled publish with the given id.
+func (s *Store) CancelScheduledPublish(ctx context.Context, id, envID types.ID) (bool, error) {
+	result, err := s.Exec(ctx, stmt.cancelScheduledPublish, id, envID)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// PublishApprovalFilters are the filters to query publish approvals.
+type PublishApprovalFilters struct {
+	ID     types.ID
+	EnvID  types.ID
+	Status string
+	Limit  int
+}
+
+// PublishApprovals returns the publish approvals that match the given filters,
+// the most recent first.
+func (s *Store) PublishApprovals(ctx context.Context, filters PublishApprovalFilters) ([]*PublishApproval, error) {
+	where := []string{}
+	params := []any{}
+
+	if filters.ID != 0 {
+		params = append(params, filters.ID)
+		where = append(where, fmt.Sprintf("pa.approval_id = $%d", len(params)))
+	}
+
+	if filters.EnvID != 0 {
+		params = append(params, filters.EnvID)
+		where = append(where, fmt.Sprintf("pa.env_id = $%d", len(params)))
+	}
+
+	if filters.Status != "" {
+		params = append(params, filters.Status)
+		where = append(where, fmt.Sprintf("pa.approval_status = $%d", len(params)))
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 50
+	}
+
+	data := map[string]any{
+		"where": "",
+		"limit": filters.Limit,
+	}
+
+	if len(where) > 0 {
+		data["where"] = "WHERE " + strings.Join(where, " AND ")
+	}
+
+	tmpl, err := template.New("selectPublishApprovals").Parse(stmt.selectPublishApprovals)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var qb strings.Builder
+
+	if err := tmpl.Execute(&qb, data); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	approvals := []*PublishApproval{}
+
+	for rows.Next() {
+		a := &PublishApproval{}
+		config := []byte{}
+		reviews := []byte{}
+
+		err := rows.Scan(
+			&a.ID, &a.AppID, &a.EnvID, &config,
+			&a.RequiredApprovals, &a.Status, &a.RequestedBy,
+			&reviews, &a.UpdatedAt, &a.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(config, &a.Config); err != nil {
+			return nil, err
+		}
+
+		if err := json.Unmarshal(reviews, &a.Reviews); err != nil {
+			return nil, err
+		}
+
+		approvals = append(approvals, a)
+	}
+
+	return approvals, rows.Err()
+}
+
+// PublishApprovalByID returns the publish approval with the given id.
+func (s *Store) PublishApprovalByID(ctx context.Context, id types.ID) (*PublishApproval, error) {
+	approvals, err := s.PublishApprovals(ctx, PublishApprovalFilters{ID: id, Limit: 1})
+
+	if err != nil || len(approvals) == 0 {
+		return nil, err
+	}
+
+	return approvals[0], nil
+}
+
+// InsertPublishApproval inserts a new publish approval.
+func (s *Store) InsertPublishApproval(ctx context.Context, a *PublishApproval) error {
+	config, err := json.Marshal(a.Config)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(
+		ctx, stmt.insertPublishApproval,
+		a.AppID, a.EnvID, config, a.RequiredApprovals,
+		a.Status, a.RequestedBy,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&a.ID, &a.CreatedAt)
+}
+
+// ReviewPublishApproval records the review and decides the status of the pending
+// publish approval in a single transaction. The approval is locked while the
+// approvals are counted, so that concurrent reviews see each other. It returns
+// the new status, which is approved or rejected only for the review that moved
+// the approval out of pending, and pending otherwise.
+func (s *Store) ReviewPublishApproval(ctx context.Context, approvalID types.ID, review ApprovalReview) (string, error) {
+	tx, err := s.Conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	errFn := func(err error) (string, error) {
+		_ = tx.Rollback()
+		return "", err
+	}
+
+	var status string
+	var required int
+
+	if err := tx.QueryRowContext(ctx, stmt.lockPublishApproval, approvalID).Scan(&status, &required); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return errFn(ErrApprovalNotPending)
+		}
+
+		return errFn(err)
+	}
+
+	if status != ApprovalStatusPending {
+		return errFn(ErrApprovalNotPending)
+	}
+
+	if _, err := tx.ExecContext(ctx, stmt.upsertApprovalReview, approvalID, review.UserID, review.Decision, review.Comment); err != nil {
+		return errFn(err)
+	}
+
+	var approvals int
+
+	if err := tx.QueryRowContext(ctx, stmt.countApprovalReviews, approvalID).Scan(&approvals); err != nil {
+		return errFn(err)
+	}
+
+	status = ApprovalStatusPending
+
+	if review.Decision == ReviewDecisionReject {
+		status = ApprovalStatusRejected
+	} else if approvals >= required {
+		status = ApprovalStatusApproved
+	}
+
+	if status != ApprovalStatusPending {
+		var id types.ID
+
+		if err := tx.QueryRowContext(ctx, stmt.updatePublishApproval, status, approvalID).Scan(&id); err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return errFn(ErrApprovalNotPending)
+			}
+
+			return errFn(err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return "", err
+	}
+
+	return status, nil
+}
+
+// ReopenPublishApproval moves an approved publish approval back to pending.
+// It is used when publishing the approved deployments fails.
+func (s *Store) ReopenPublishApproval(ctx context.Context, approvalID types.ID) error {
+	_, err := s.Exec(ctx, stmt.reopenPublishApproval, approvalID)
+	return err
+}
+
+// CancelPublishApprovals cancels the pending publish approvals of the environment.
+func (s *Store) CancelPublishApprovals(ctx context.Context, envID types.ID) error {
+	_, err := s.Exec(ctx, stmt.cancelPublishApprovals, envID)
+	return err
+}
+
+// ExpiredDeploymentsFilters are the filters to query expired deployments.
+type ExpiredDeploymentsFilters struct {
+	// EnvID limits the query to a single environment.
+	EnvID types.ID
+
+	// Policy is the default retention policy. It is used for environments
+	// that do not configure their own retention policy.
+	Policy admin.RetentionPolicy
+
+	Limit int
+}
+
+// ExpiredDeployments returns the deployments whose artifacts can be removed
+// according to the retention policies. Deleted deployments are always expired,
+// unless they are pinned or tagged.
+func (s *Store) ExpiredDeployments(ctx context.Context, filters ExpiredDeploymentsFilters) ([]*Deployment, error) {
+	policy, err := json.Marshal(filters.Policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if filters.Limit <= 0 {
+		filters.Limit = 100
+	}
+
+	if filters.Policy.MaxAgeDays <= 0 {
+		filters.Policy.MaxAgeDays = admin.DefaultRetentionMaxAgeDays
+	}
+
+	data := map[string]any{
+		"limit":      filters.Limit,
+		"maxAgeDays": filters.Policy.MaxAgeDays,
+	}
+
+	params := []any{policy}
+
+	if filters.EnvID != 0 {
+		params = append(params, filters.EnvID)
+		data["envID"] = fmt.Sprintf("$%d", len(params))
+	}
+
+	tmpl, err := template.New("selectExpiredDeployments").Parse(stmt.selectExpiredDeployments)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var qb strings.Builder
+
+	if err := tmpl.Execute(&qb, data); err != nil {
+		return nil, err
+	}
+
+	rows, err := s.Query(ctx, qb.String(), params...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	deployments := []*Deployment{}
+
+	for rows.Next() {
+		d := &Deployment{}
+
+		err := rows.Scan(
+			&d.ID, &d.AppID, &d.EnvID, &d.StorageLocation,
+			&d.FunctionLocation, &d.APILocation, &d.CreatedAt,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		deployments = append(deployments, d)
+	}
+
+	return deployments, rows.Err()
+}
+
+// UpdateRetention updates whether the deployment is pinned and its tag.
+func (s *Store) UpdateRetention(ctx context.Context, d *Deployment) error {
+	_, err := s.Exec(ctx, stmt.updateDeploymentRetention, d.Pinned, d.Tag.ValueOrZero(), d.ID)
+	return err
+}
+
+// EnqueueDeployment adds the deployment to the build queue. Deployments that are
+// queued again, such as restarted deployments, move to the end of the queue.
+func (s *Store) EnqueueDeployment(ctx context.Context, q *QueuedDeployment) error {
+	_, err := s.Exec(
+		ctx, stmt.upsertQueuedDeployment,
+		q.DeploymentID, q.AppID, q.EnvID, q.TeamID, q.Branch,
+		q.Priority, q.Payload,
+	)
+
+	return err
+}
+
+// CancelSupersededDeployments cancels the queued deployments of the same
+// environment and branch that are older than the given deployment. The
+// cancelled deployments are marked as stopped.
+func (s *Store) CancelSupersededDeployments(ctx context.Context, q *QueuedDeployment) ([]types.ID, error) {
+	reason := fmt.Sprintf("Superseded by deployment %s.", q.DeploymentID.String())
+	rows, err := s.Query(ctx, stmt.cancelSupersededQueue, q.EnvID, q.Branch, q.DeploymentID, reason)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	ids := []types.ID{}
+
+	for rows.Next() {
+		var id types.ID
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+// CancelStoppedQueuedDeployments removes the deployments that were stopped or
+// deleted while waiting in the build queue.
+func (s *Store) CancelStoppedQueuedDeployments(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmt.cancelStoppedQueue)
+	return err
+}
+
+// QueuedDeployments returns the queued deployments in the order they should be built.
+func (s *Store) QueuedDeployments(ctx context.Context, limit int) ([]*QueuedDeployment, error) {
+	rows, err := s.Query(ctx, stmt.selectQueuedDeployments, limit)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	queued := []*QueuedDeployment{}
+
+	for rows.Next() {
+		q := &QueuedDeployment{}
+
+		if err := rows.Scan(&q.DeploymentID, &q.AppID, &q.TeamID, &q.Payload); err != nil {
+			return nil, err
+		}
+
+		queued = append(queued, q)
+	}
+
+	return queued, rows.Err()
+}
+
+// QueueInfo returns the state of the deployment in the build queue, including its
+// position. It returns nil when the deployment did not go through the queue.
+func (s *Store) QueueInfo(ctx context.Context, deploymentID, appID types.ID) (*QueueInfo, error) {
+	row, err := s.QueryRow(ctx, stmt.selectQueueInfo, deploymentID, appID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	qi := &QueueInfo{}
+
+	if err := row.Scan(&qi.Status, &qi.Position, &qi.QueuedAt, &qi.WaitTime); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return qi, nil
+}
+
+// RunningDeploymentsByTeam returns the number of dispatched deployments that
+// are still running, grouped by team.
+func (s *Store) RunningDeploymentsByTeam(ctx context.Context) (map[types.ID]int, error) {
+	rows, err := s.Query(ctx, stmt.selectRunningQueueCounts)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	counts := map[types.ID]int{}
+
+	for rows.Next() {
+		var teamID types.ID
+		var count int
+
+		if err := rows.Scan(&teamID, &count); err != nil {
+			return nil, err
+		}
+
+		counts[teamID] = count
+	}
+
+	return counts, rows.Err()
+}
+
+// MarkQueuedDeploymentDispatched marks the queued deployment as dispatched. It
+// returns false when the deployment is no longer queued, for instance because
+// another worker dispatched it already.
+func (s *Store) MarkQueuedDeploymentDispatched(ctx context.Context, deploymentID types.ID) (bool, error) {
+	result, err := s.Exec(ctx, stmt.markQueuedDispatched, deploymentID)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RemoveOldQueueEntries removes the dispatched and cancelled queue entries
+// that are older than a week.
+func (s *Store) RemoveOldQueueEntries(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmt.removeOldQueueEntries)
+	return err
+}
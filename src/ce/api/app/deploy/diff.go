@@ -0,0 +1,459 @@
+package deploy
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+const (
+	DiffStatusAdded    = "added"
+	DiffStatusRemoved  = "removed"
+	DiffStatusModified = "modified"
+)
+
+// FileDiff is a static file that differs between two deployments.
+// Files are compared by their ETag.
+type FileDiff struct {
+	Name      string `json:"name"`
+	Status    string `json:"status"`
+	OldETag   string `json:"oldETag,omitempty"`
+	NewETag   string `json:"newETag,omitempty"`
+	OldSize   int64  `json:"oldSize"`
+	NewSize   int64  `json:"newSize"`
+	SizeDelta int64  `json:"sizeDelta"`
+}
+
+// FunctionDiff is a function bundle that differs between two deployments.
+type FunctionDiff struct {
+	Name       string `json:"name"`
+	Status     string `json:"status"`
+	OldHandler string `json:"oldHandler,omitempty"`
+	NewHandler string `json:"newHandler,omitempty"`
+	OldSize    int64  `json:"oldSize"`
+	NewSize    int64  `json:"newSize"`
+	SizeDelta  int64  `json:"sizeDelta"`
+}
+
+// ValueDiff is a keyed value that differs between two deployments. Old and
+// New are omitted when the value is redacted.
+type ValueDiff struct {
+	Key    string `json:"key"`
+	Status string `json:"status"`
+	Old    string `json:"old,omitempty"`
+	New    string `json:"new,omitempty"`
+}
+
+// DeploymentDiff describes what changed from the base deployment to the head deployment.
+type DeploymentDiff struct {
+	BaseID        types.ID       `json:"baseId,string"`
+	HeadID        types.ID       `json:"headId,string"`
+	Files         []FileDiff     `json:"files"`
+	Redirects     []ValueDiff    `json:"redirects"`
+	Headers       []ValueDiff    `json:"headers"`
+	Functions     []FunctionDiff `json:"functions"`
+	APIRoutes     []ValueDiff    `json:"apiRoutes"`
+	EnvVars       []ValueDiff    `json:"envVars"`
+	BuildSettings []ValueDiff    `json:"buildSettings"`
+}
+
+// IsEmpty returns true when the deployments do not differ.
+func (dd *DeploymentDiff) IsEmpty() bool {
+	return len(dd.Files) == 0 &&
+		len(dd.Redirects) == 0 &&
+		len(dd.Headers) == 0 &&
+		len(dd.Functions) == 0 &&
+		len(dd.APIRoutes) == 0 &&
+		len(dd.EnvVars) == 0 &&
+		len(dd.BuildSettings) == 0
+}
+
+// Diff compares the build manifests and the config snapshots of two deployments.
+// Environment variable values are never included in the result.
+func Diff(base, head *Deployment) *DeploymentDiff {
+	baseConf := diffBuildConf(base)
+	headConf := diffBuildConf(head)
+
+	return &DeploymentDiff{
+		BaseID:        base.ID,
+		HeadID:        head.ID,
+		Files:         diffFiles(base.BuildManifest, head.BuildManifest),
+		Redirects:     diffRedirects(base, baseConf, head, headConf),
+		Headers:       diffHeaders(base.BuildManifest, baseConf, head.BuildManifest, headConf),
+		Functions:     diffFunctions(base, head),
+		APIRoutes:     diffAPIRoutes(base.BuildManifest, head.BuildManifest),
+		EnvVars:       diffValues(baseConf.Vars, headConf.Vars, true),
+		BuildSettings: diffBuildSettings(baseConf, headConf),
+	}
+}
+
+// Summary returns a markdown summary of the diff.
+func (dd *DeploymentDiff) Summary() string {
+	if dd.IsEmpty() {
+		return fmt.Sprintf("No changes compared to deployment `%s`.", dd.BaseID.String())
+	}
+
+	counts := map[string]int{}
+	var sizeDelta int64
+
+	for _, f := range dd.Files {
+		counts[f.Status] = counts[f.Status] + 1
+		sizeDelta = sizeDelta + f.SizeDelta
+	}
+
+	lines := []string{
+		fmt.Sprintf("Changes compared to deployment `%s`:", dd.BaseID.String()),
+		"",
+	}
+
+	if len(dd.Files) > 0 {
+		lines = append(lines, fmt.Sprintf(
+			"- **Files:** %d added, %d removed, %d modified (%s)",
+			counts[DiffStatusAdded], counts[DiffStatusRemoved], counts[DiffStatusModified], signedBytes(sizeDelta),
+		))
+	}
+
+	for _, fn := range dd.Functions {
+		lines = append(lines, fmt.Sprintf("- **Function `%s`:** %s (%s)", fn.Name, fn.Status, signedBytes(fn.SizeDelta)))
+	}
+
+	sections := []struct {
+		name  string
+		diffs []ValueDiff
+	}{
+		{"Redirects", dd.Redirects},
+		{"Headers", dd.Headers},
+		{"API routes", dd.APIRoutes},
+		{"Environment variables", dd.EnvVars},
+		{"Build settings", dd.BuildSettings},
+	}
+
+	for _, section := range sections {
+		if len(section.diffs) == 0 {
+			continue
+		}
+
+		keys := []string{}
+
+		for _, d := range section.diffs {
+			keys = append(keys, fmt.Sprintf("`%s` (%s)", d.Key, d.Status))
+		}
+
+		lines = append(lines, fmt.Sprintf("- **%s:** %s", section.name, strings.Join(keys, ", ")))
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func signedBytes(size int64) string {
+	if size < 0 {
+		return "-" + byteCountDecimal(-size)
+	}
+
+	return "+" + byteCountDecimal(size)
+}
+
+func diffBuildConf(d *Deployment) *buildconf.BuildConf {
+	snapshot := ConfigSnapshot{}
+
+	if len(d.ConfigCopy) > 0 {
+		_ = json.Unmarshal(d.ConfigCopy, &snapshot)
+	}
+
+	if snapshot.BuildConfig == nil {
+		return &buildconf.BuildConf{}
+	}
+
+	return snapshot.BuildConfig
+}
+
+type diffFile struct {
+	etag string
+	size int64
+}
+
+func manifestFiles(manifest *BuildManifest) map[string]diffFile {
+	files := map[string]diffFile{}
+
+	if manifest == nil {
+		return files
+	}
+
+	for _, f := range manifest.CDNFiles {
+		files[f.Name] = diffFile{etag: f.Headers["etag"], size: f.Size}
+	}
+
+	// Static files do not include the size, keep the one from the cdn files if any.
+	for name, headers := range manifest.StaticFiles {
+		f := files[name]
+		f.etag = headers["etag"]
+		files[name] = f
+	}
+
+	return files
+}
+
+func diffFiles(base, head *BuildManifest) []FileDiff {
+	baseFiles := manifestFiles(base)
+	headFiles := manifestFiles(head)
+	diffs := []FileDiff{}
+
+	for name, hf := range headFiles {
+		bf, ok := baseFiles[name]
+
+		if !ok {
+			diffs = append(diffs, FileDiff{Name: name, Status: DiffStatusAdded, NewETag: hf.etag, NewSize: hf.size, SizeDelta: hf.size})
+		} else if bf.etag != hf.etag {
+			diffs = append(diffs, FileDiff{Name: name, Status: DiffStatusModified, OldETag: bf.etag, NewETag: hf.etag, OldSize: bf.size, NewSize: hf.size, SizeDelta: hf.size - bf.size})
+		}
+	}
+
+	for name, bf := range baseFiles {
+		if _, ok := headFiles[name]; !ok {
+			diffs = append(diffs, FileDiff{Name: name, Status: DiffStatusRemoved, OldETag: bf.etag, OldSize: bf.size, SizeDelta: -bf.size})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Name < diffs[j].Name
+	})
+
+	return diffs
+}
+
+// diffValues compares two maps. When redact is true, the values are not
+// included in the result.
+func diffValues(base, head map[string]string, redact bool) []ValueDiff {
+	diffs := []ValueDiff{}
+
+	for key, hv := range head {
+		bv, ok := base[key]
+
+		if ok && bv == hv {
+			continue
+		}
+
+		diff := ValueDiff{Key: key, Status: DiffStatusModified}
+
+		if !ok {
+			diff.Status = DiffStatusAdded
+		}
+
+		if !redact {
+			diff.Old = bv
+			diff.New = hv
+		}
+
+		diffs = append(diffs, diff)
+	}
+
+	for key, bv := range base {
+		if _, ok := head[key]; !ok {
+			diff := ValueDiff{Key: key, Status: DiffStatusRemoved}
+
+			if !redact {
+				diff.Old = bv
+			}
+
+			diffs = append(diffs, diff)
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+
+	return diffs
+}
+
+func redirectsMap(d *Deployment, bc *buildconf.BuildConf) map[string]string {
+	reds := append([]Redirect{}, bc.Redirects...)
+
+	if d.BuildManifest != nil {
+		reds = append(reds, d.BuildManifest.Redirects...)
+	}
+
+	m := map[string]string{}
+
+	for _, r := range reds {
+		key := r.From
+
+		if len(r.Hosts) > 0 {
+			key = fmt.Sprintf("%s [%s]", r.From, strings.Join(r.Hosts, ", "))
+		}
+
+		// Only the first matching redirect is applied, so ignore the rest.
+		if _, ok := m[key]; ok {
+			continue
+		}
+
+		data, _ := json.Marshal(r)
+		m[key] = string(data)
+	}
+
+	return m
+}
+
+func diffRedirects(base *Deployment, baseConf *buildconf.BuildConf, head *Deployment, headConf *buildconf.BuildConf) []ValueDiff {
+	return diffValues(redirectsMap(base, baseConf), redirectsMap(head, headConf), false)
+}
+
+func headersMap(manifest *BuildManifest, bc *buildconf.BuildConf) map[string]string {
+	headers := []CustomHeader{}
+
+	if manifest != nil {
+		headers = append(headers, manifest.Headers...)
+	}
+
+	if bc.Headers != "" {
+		if parsed, err := ParseHeaders(bc.Headers); err == nil {
+			headers = append(headers, parsed...)
+		}
+	}
+
+	m := map[string]string{}
+
+	for _, h := range headers {
+		m[fmt.Sprintf("%s %s", h.Location, strings.ToLower(h.Key))] = h.Value
+	}
+
+	return m
+}
+
+func diffHeaders(baseManifest *BuildManifest, baseConf *buildconf.BuildConf, headManifest *BuildManifest, headConf *buildconf.BuildConf) []ValueDiff {
+	return diffValues(headersMap(baseManifest, baseConf), headersMap(headManifest, headConf), false)
+}
+
+func diffFunctions(base, head *Deployment) []FunctionDiff {
+	type bundle struct {
+		handler  string
+		location string
+		size     int64
+	}
+
+	bundles := func(d *Deployment) map[string]bundle {
+		m := map[string]bundle{}
+
+		if d.FunctionLocation.ValueOrZero() != "" {
+			b := bundle{location: d.FunctionLocation.ValueOrZero(), size: d.ServerPackageSize.ValueOrZero()}
+
+			if d.BuildManifest != nil {
+				b.handler = d.BuildManifest.FunctionHandler
+			}
+
+			m["server"] = b
+		}
+
+		if d.APILocation.ValueOrZero() != "" {
+			b := bundle{location: d.APILocation.ValueOrZero(), size: d.APIPackageSize.ValueOrZero()}
+
+			if d.BuildManifest != nil {
+				b.handler = d.BuildManifest.APIHandler
+			}
+
+			m["api"] = b
+		}
+
+		return m
+	}
+
+	baseBundles := bundles(base)
+	headBundles := bundles(head)
+	diffs := []FunctionDiff{}
+
+	for _, name := range []string{"server", "api"} {
+		bb, inBase := baseBundles[name]
+		hb, inHead := headBundles[name]
+
+		switch {
+		case inHead && !inBase:
+			diffs = append(diffs, FunctionDiff{Name: name, Status: DiffStatusAdded, NewHandler: hb.handler, NewSize: hb.size, SizeDelta: hb.size})
+		case inBase && !inHead:
+			diffs = append(diffs, FunctionDiff{Name: name, Status: DiffStatusRemoved, OldHandler: bb.handler, OldSize: bb.size, SizeDelta: -bb.size})
+		case inBase && inHead && (bb.handler != hb.handler || bb.size != hb.size):
+			diffs = append(diffs, FunctionDiff{
+				Name:       name,
+				Status:     DiffStatusModified,
+				OldHandler: bb.handler,
+				NewHandler: hb.handler,
+				OldSize:    bb.size,
+				NewSize:    hb.size,
+				SizeDelta:  hb.size - bb.size,
+			})
+		}
+	}
+
+	return diffs
+}
+
+func diffAPIRoutes(base, head *BuildManifest) []ValueDiff {
+	routes := func(manifest *BuildManifest) map[string]string {
+		m := map[string]string{}
+
+		if manifest != nil {
+			for _, r := range manifest.APIRoutes {
+				m[r] = r
+			}
+		}
+
+		return m
+	}
+
+	return diffValues(routes(base), routes(head), true)
+}
+
+// diffBuildSettings compares the build configurations, except the environment
+// variables, redirects and headers which are compared separately.
+func diffBuildSettings(base, head *buildconf.BuildConf) []ValueDiff {
+	settings := func(bc *buildconf.BuildConf) map[string]any {
+		m := map[string]any{}
+		data, _ := json.Marshal(bc)
+		_ = json.Unmarshal(data, &m)
+
+		delete(m, "vars")
+		delete(m, "redirects")
+		delete(m, "headers")
+
+		return m
+	}
+
+	baseSettings := settings(base)
+	headSettings := settings(head)
+	diffs := []ValueDiff{}
+
+	stringify := func(v any) string {
+		if s, ok := v.(string); ok {
+			return s
+		}
+
+		data, _ := json.Marshal(v)
+		return string(data)
+	}
+
+	for key, hv := range headSettings {
+		bv, ok := baseSettings[key]
+
+		if !ok {
+			diffs = append(diffs, ValueDiff{Key: key, Status: DiffStatusAdded, New: stringify(hv)})
+		} else if !reflect.DeepEqual(bv, hv) {
+			diffs = append(diffs, ValueDiff{Key: key, Status: DiffStatusModified, Old: stringify(bv), New: stringify(hv)})
+		}
+	}
+
+	for key, bv := range baseSettings {
+		if _, ok := headSettings[key]; !ok {
+			diffs = append(diffs, ValueDiff{Key: key, Status: DiffStatusRemoved, Old: stringify(bv)})
+		}
+	}
+
+	sort.Slice(diffs, func(i, j int) bool {
+		return diffs[i].Key < diffs[j].Key
+	})
+
+	return diffs
+}
@@ -0,0 +1,134 @@
+package deploy_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/redirects"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type DiffSuite struct {
+	suite.Suite
+}
+
+func (s *DiffSuite) snapshot(bc *buildconf.BuildConf) []byte {
+	data, err := json.Marshal(deploy.ConfigSnapshot{BuildConfig: bc})
+	s.NoError(err)
+	return data
+}
+
+func (s *DiffSuite) Test_Diff() {
+	base := &deploy.Deployment{
+		ID:                types.ID(1),
+		FunctionLocation:  null.StringFrom("aws:arn/1"),
+		ServerPackageSize: null.IntFrom(1000),
+		BuildManifest: &deploy.BuildManifest{
+			FunctionHandler: "server.js:handler",
+			APIRoutes:       []string{"/api/users"},
+			Redirects:       []redirects.Redirect{{From: "/old", To: "/new", Status: 301}},
+			CDNFiles: []deploy.CDNFile{
+				{Name: "/index.html", Headers: map[string]string{"etag": "a"}, Size: 100},
+				{Name: "/main.js", Headers: map[string]string{"etag": "b"}, Size: 500},
+				{Name: "/logo.png", Headers: map[string]string{"etag": "c"}, Size: 300},
+			},
+		},
+		ConfigCopy: s.snapshot(&buildconf.BuildConf{
+			BuildCmd: "npm run build",
+			Headers:  "/*\n  X-Frame-Options: DENY",
+			Vars:     map[string]string{"API_KEY": "secret", "NODE_ENV": "production"},
+		}),
+	}
+
+	head := &deploy.Deployment{
+		ID:                types.ID(2),
+		FunctionLocation:  null.StringFrom("aws:arn/2"),
+		ServerPackageSize: null.IntFrom(1200),
+		APILocation:       null.StringFrom("aws:arn/api"),
+		APIPackageSize:    null.IntFrom(50),
+		BuildManifest: &deploy.BuildManifest{
+			FunctionHandler: "server.js:handler",
+			APIHandler:      "api.js:handler",
+			APIRoutes:       []string{"/api/users", "/api/posts"},
+			Redirects:       []redirects.Redirect{{From: "/old", To: "/newer", Status: 301}},
+			CDNFiles: []deploy.CDNFile{
+				{Name: "/index.html", Headers: map[string]string{"etag": "a"}, Size: 100},
+				{Name: "/main.js", Headers: map[string]string{"etag": "d"}, Size: 450},
+				{Name: "/about.html", Headers: map[string]string{"etag": "e"}, Size: 80},
+			},
+		},
+		ConfigCopy: s.snapshot(&buildconf.BuildConf{
+			BuildCmd: "npm run build:prod",
+			Headers:  "/*\n  X-Frame-Options: SAMEORIGIN",
+			Vars:     map[string]string{"API_KEY": "new-secret", "DEBUG": "1"},
+		}),
+	}
+
+	diff := deploy.Diff(base, head)
+
+	s.Equal([]deploy.FileDiff{
+		{Name: "/about.html", Status: deploy.DiffStatusAdded, NewETag: "e", NewSize: 80, SizeDelta: 80},
+		{Name: "/logo.png", Status: deploy.DiffStatusRemoved, OldETag: "c", OldSize: 300, SizeDelta: -300},
+		{Name: "/main.js", Status: deploy.DiffStatusModified, OldETag: "b", NewETag: "d", OldSize: 500, NewSize: 450, SizeDelta: -50},
+	}, diff.Files)
+
+	s.Equal([]deploy.FunctionDiff{
+		{Name: "server", Status: deploy.DiffStatusModified, OldHandler: "server.js:handler", NewHandler: "server.js:handler", OldSize: 1000, NewSize: 1200, SizeDelta: 200},
+		{Name: "api", Status: deploy.DiffStatusAdded, NewHandler: "api.js:handler", NewSize: 50, SizeDelta: 50},
+	}, diff.Functions)
+
+	s.Equal([]deploy.ValueDiff{
+		{Key: "API_KEY", Status: deploy.DiffStatusModified},
+		{Key: "DEBUG", Status: deploy.DiffStatusAdded},
+		{Key: "NODE_ENV", Status: deploy.DiffStatusRemoved},
+	}, diff.EnvVars)
+
+	s.Equal([]deploy.ValueDiff{
+		{Key: "/api/posts", Status: deploy.DiffStatusAdded},
+	}, diff.APIRoutes)
+
+	s.Equal([]deploy.ValueDiff{
+		{Key: "/old", Status: deploy.DiffStatusModified, Old: `{"from":"/old","to":"/new","status":301}`, New: `{"from":"/old","to":"/newer","status":301}`},
+	}, diff.Redirects)
+
+	s.Equal([]deploy.ValueDiff{
+		{Key: "/* x-frame-options", Status: deploy.DiffStatusModified, Old: "DENY", New: "SAMEORIGIN"},
+	}, diff.Headers)
+
+	s.Equal([]deploy.ValueDiff{
+		{Key: "buildCmd", Status: deploy.DiffStatusModified, Old: "npm run build", New: "npm run build:prod"},
+	}, diff.BuildSettings)
+
+	s.False(diff.IsEmpty())
+	s.Equal("Changes compared to deployment `1`:\n\n"+
+		"- **Files:** 1 added, 1 removed, 1 modified (-270 B)\n"+
+		"- **Function `server`:** modified (+200 B)\n"+
+		"- **Function `api`:** added (+50 B)\n"+
+		"- **Redirects:** `/old` (modified)\n"+
+		"- **Headers:** `/* x-frame-options` (modified)\n"+
+		"- **API routes:** `/api/posts` (added)\n"+
+		"- **Environment variables:** `API_KEY` (modified), `DEBUG` (added), `NODE_ENV` (removed)\n"+
+		"- **Build settings:** `buildCmd` (modified)", diff.Summary())
+}
+
+func (s *DiffSuite) Test_Diff_NoChanges() {
+	d := &deploy.Deployment{
+		ID: types.ID(1),
+		BuildManifest: &deploy.BuildManifest{
+			CDNFiles: []deploy.CDNFile{{Name: "/index.html", Headers: map[string]string{"etag": "a"}}},
+		},
+		ConfigCopy: s.snapshot(&buildconf.BuildConf{BuildCmd: "npm run build"}),
+	}
+
+	diff := deploy.Diff(d, d)
+	s.True(diff.IsEmpty())
+	s.Equal("No changes compared to deployment `1`.", diff.Summary())
+}
+
+func TestDiffSuite(t *testing.T) {
+	suite.Run(t, &DiffSuite{})
+}
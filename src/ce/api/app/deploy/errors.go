@@ -1,18 +1,33 @@
 package deploy
 
 import (
+	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttperr"
 )
 
 // Http errors
 var (
-	ErrMissingEnv          = shttperr.New(http.StatusBadRequest, "Environment name is a required field", "missing-env")
-	ErrMissingEnvID        = shttperr.New(http.StatusBadRequest, "Environment ID is a required field", "missing-env-id")
-	ErrMissingDeploymentID = shttperr.New(http.StatusBadRequest, "Deployment id is a required field", "missing-id")
-	ErrRequestDataMerge    = shttperr.New(http.StatusBadRequest, "Unable to merge request data.", "merge-data")
-	ErrDeployServer        = shttperr.New(http.StatusServiceUnavailable, "Deploy server is not reachable", "deploy-server")
+	ErrMissingEnv              = shttperr.New(http.StatusBadRequest, "Environment name is a required field", "missing-env")
+	ErrMissingEnvID            = shttperr.New(http.StatusBadRequest, "Environment ID is a required field", "missing-env-id")
+	ErrMissingDeploymentID     = shttperr.New(http.StatusBadRequest, "Deployment id is a required field", "missing-id")
+	ErrRequestDataMerge        = shttperr.New(http.StatusBadRequest, "Unable to merge request data.", "merge-data")
+	ErrDeployServer            = shttperr.New(http.StatusServiceUnavailable, "Deploy server is not reachable", "deploy-server")
+	ErrPromoteNotSucceeded     = shttperr.New(http.StatusBadRequest, "Only successful deployments can be promoted", "promote-not-succeeded")
+	ErrPromoteSameEnv          = shttperr.New(http.StatusBadRequest, "The deployment already belongs to this environment", "promote-same-env")
+	ErrRolloutNotSucceeded     = shttperr.New(http.StatusBadRequest, "Only successful deployments can be rolled out", "rollout-not-succeeded")
+	ErrRolloutNotRunning       = shttperr.New(http.StatusBadRequest, "There is no running rollout", "rollout-not-running")
+	ErrFreezeOverrideForbidden = shttperr.New(http.StatusForbidden, "Only team admins can publish during a freeze window", "freeze-override-forbidden")
+	ErrRolloutMissingPlan      = shttperr.New(http.StatusBadRequest, "The environment has no rollout plan", "rollout-missing-plan")
+	ErrInvalidPublishAt        = shttperr.New(http.StatusBadRequest, "Publish date must be in the future", "invalid-publish-at")
+	ErrApprovalNotRequired     = shttperr.New(http.StatusBadRequest, "The environment is not protected", "approval-not-required")
+	ErrApprovalNotPending      = shttperr.New(http.StatusBadRequest, "The publish is no longer waiting for approval", "approval-not-pending")
+	ErrApprovalSelfReview      = shttperr.New(http.StatusForbidden, "Publishes cannot be reviewed by their requester", "approval-self-review")
+	ErrApprovalForbidden       = shttperr.New(http.StatusForbidden, "Only team admins can review publishes", "approval-forbidden")
+	ErrInvalidReviewDecision   = shttperr.New(http.StatusBadRequest, "Decision must be either approve or reject", "invalid-review-decision")
+	ErrProtectedEnv            = shttperr.New(http.StatusBadRequest, "Publishes to protected environments require approval", "protected-env")
 )
 
 // Deployment errors
@@ -26,3 +41,22 @@ var (
 	ErrDeployClient          = "An error occurred while uploading files to the CDN. Please retry again or reach us out at hello@stormkit.io"
 	ErrRequestEntityTooLarge = "Your deployment package is too large. It can be maximum 50MB zipped and 250MB unzipped."
 )
+
+// ErrFreezeWindowCode is the code of the error returned when publishing during a freeze window.
+const ErrFreezeWindowCode = "freeze-window"
+
+// ErrFreezeWindow returns the error that is returned when publishing during a freeze window.
+func ErrFreezeWindow(envName, windowName string, until time.Time) *shttperr.Error {
+	msg := fmt.Sprintf(
+		"Publishing to %s is not allowed during the %s freeze window, which ends at %s.",
+		envName, windowName, until.UTC().Format(time.RFC3339),
+	)
+
+	return shttperr.New(http.StatusConflict, msg, ErrFreezeWindowCode)
+}
+
+// IsFreezeWindowError returns true when the error is returned because of a freeze window.
+func IsFreezeWindowError(err error) bool {
+	serr, ok := err.(*shttperr.Error)
+	return ok && serr.Code() == ErrFreezeWindowCode
+}
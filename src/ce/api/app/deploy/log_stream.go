@@ -0,0 +1,195 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const (
+	LogStreamEventLines = "lines"
+	LogStreamEventStep  = "step"
+	LogStreamEventDone  = "done"
+)
+
+// LogStreamEvent represents a chunk of deployment logs that is streamed
+// to clients while the deployment is running.
+type LogStreamEvent struct {
+	// Type is one of LogStreamEventLines, LogStreamEventStep or LogStreamEventDone.
+	Type string `json:"type"`
+
+	// Offset is the byte offset of the event within the raw logs. Clients
+	// can use it to discard events they have already received.
+	Offset int `json:"offset"`
+
+	// Lines contains the log lines when Type is LogStreamEventLines.
+	Lines []string `json:"lines,omitempty"`
+
+	// Step is the step title when Type is LogStreamEventStep.
+	Step string `json:"step,omitempty"`
+
+	// Timestamp is the unix timestamp of the step.
+	Timestamp int64 `json:"ts,omitempty"`
+
+	// StatusChecks is true when the event belongs to the status checks logs.
+	StatusChecks bool `json:"statusChecks,omitempty"`
+
+	// Status is the deployment status when Type is LogStreamEventDone.
+	Status string `json:"status,omitempty"`
+}
+
+// LogStreamChannel returns the pub/sub channel name for the given deployment.
+func LogStreamChannel(deploymentID types.ID) string {
+	return fmt.Sprintf("deployment_logs:%s", deploymentID.String())
+}
+
+// LogStreamEvents parses the raw logs starting from the given offset
+// and returns the events. Consecutive lines are batched into a single event.
+func LogStreamEvents(logs string, offset int, isStatusChecks bool) []LogStreamEvent {
+	events := []LogStreamEvent{}
+
+	if offset < 0 || offset >= len(logs) {
+		return events
+	}
+
+	var current *LogStreamEvent
+
+	for _, line := range strings.SplitAfter(logs[offset:], "\n") {
+		lineOffset := offset
+		offset += len(line)
+		line = strings.TrimSuffix(line, "\n")
+
+		if line == "" {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[sk-step] ") {
+			if current != nil {
+				events = append(events, *current)
+				current = nil
+			}
+
+			title, ts := parseStepLine(line)
+
+			// System steps are not displayed as steps, but they mark
+			// the end of the build process.
+			if strings.HasPrefix(title, "[system] ") {
+				continue
+			}
+
+			events = append(events, LogStreamEvent{
+				Type:         LogStreamEventStep,
+				Offset:       lineOffset,
+				Step:         title,
+				Timestamp:    ts,
+				StatusChecks: isStatusChecks,
+			})
+
+			continue
+		}
+
+		if current == nil {
+			current = &LogStreamEvent{
+				Type:         LogStreamEventLines,
+				Offset:       lineOffset,
+				StatusChecks: isStatusChecks,
+			}
+		}
+
+		current.Lines = append(current.Lines, line)
+	}
+
+	if current != nil {
+		events = append(events, *current)
+	}
+
+	return events
+}
+
+// parseStepLine parses a line such as `[sk-step] npm run build [ts:1700000000]`
+// and returns the title and the timestamp.
+func parseStepLine(line string) (string, int64) {
+	pieces := strings.Split(strings.TrimPrefix(line, "[sk-step] "), " [ts:")
+
+	if len(pieces) > 1 {
+		return pieces[0], utils.StringToInt64(strings.TrimSuffix(pieces[1], "]"))
+	}
+
+	return pieces[0], 0
+}
+
+// PublishLogs publishes the difference between the previous and the next
+// logs to the subscribers of the deployment log stream.
+func PublishLogs(deploymentID types.ID, prev, next string, isStatusChecks bool) {
+	offset := len(prev)
+
+	// Logs are sent cumulatively by the runner. If for some reason the new logs
+	// do not start with the previous logs, stream the whole thing again.
+	if !strings.HasPrefix(next, prev) {
+		offset = 0
+	}
+
+	for _, event := range LogStreamEvents(next, offset, isStatusChecks) {
+		publishLogStreamEvent(deploymentID, event)
+	}
+}
+
+// PublishDone notifies the subscribers that the deployment has completed.
+func PublishDone(deploymentID types.ID, status string) {
+	publishLogStreamEvent(deploymentID, LogStreamEvent{
+		Type:   LogStreamEventDone,
+		Status: status,
+	})
+}
+
+func publishLogStreamEvent(deploymentID types.ID, event LogStreamEvent) {
+	data, err := json.Marshal(event)
+
+	if err != nil {
+		slog.Errorf("cannot marshal log stream event: %v", err)
+		return
+	}
+
+	if err := rediscache.Broadcast(LogStreamChannel(deploymentID), string(data)); err != nil {
+		slog.Errorf("cannot publish log stream event for deployment %s: %v", deploymentID.String(), err)
+	}
+}
+
+// SubscribeLogs subscribes to the log stream of the given deployment
+// until the context is cancelled.
+func SubscribeLogs(ctx context.Context, deploymentID types.ID) (<-chan LogStreamEvent, error) {
+	payloads, err := rediscache.SubscribeContext(ctx, LogStreamChannel(deploymentID))
+
+	if err != nil {
+		return nil, err
+	}
+
+	events := make(chan LogStreamEvent)
+
+	go func() {
+		defer close(events)
+
+		for payload := range payloads {
+			event := LogStreamEvent{}
+
+			if err := json.Unmarshal([]byte(payload), &event); err != nil {
+				slog.Errorf("cannot unmarshal log stream event: %v", err)
+				continue
+			}
+
+			select {
+			case events <- event:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return events, nil
+}
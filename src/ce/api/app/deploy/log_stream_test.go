@@ -0,0 +1,44 @@
+package deploy_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stretchr/testify/suite"
+)
+
+type LogStreamSuite struct {
+	suite.Suite
+}
+
+const streamLogs = "[sk-step] checkout main [ts:1700000000]\n" +
+	"Cloning into 'repo'...\n" +
+	"[sk-step] npm run build [ts:1700000005]\n" +
+	"> vite build\n" +
+	"built in 2s\n" +
+	"[sk-step] [system] building finished [ts:1700000010]\n"
+
+func (s *LogStreamSuite) Test_LogStreamEvents() {
+	events := deploy.LogStreamEvents(streamLogs, 0, false)
+
+	s.Equal([]deploy.LogStreamEvent{
+		{Type: deploy.LogStreamEventStep, Offset: 0, Step: "checkout main", Timestamp: 1700000000},
+		{Type: deploy.LogStreamEventLines, Offset: 40, Lines: []string{"Cloning into 'repo'..."}},
+		{Type: deploy.LogStreamEventStep, Offset: 63, Step: "npm run build", Timestamp: 1700000005},
+		{Type: deploy.LogStreamEventLines, Offset: 103, Lines: []string{"> vite build", "built in 2s"}},
+	}, events)
+}
+
+func (s *LogStreamSuite) Test_LogStreamEvents_Offset() {
+	events := deploy.LogStreamEvents(streamLogs, 103, true)
+
+	s.Equal([]deploy.LogStreamEvent{
+		{Type: deploy.LogStreamEventLines, Offset: 103, Lines: []string{"> vite build", "built in 2s"}, StatusChecks: true},
+	}, events)
+
+	s.Empty(deploy.LogStreamEvents(streamLogs, len(streamLogs), false))
+}
+
+func TestLogStreamSuite(t *testing.T) {
+	suite.Run(t, &LogStreamSuite{})
+}
@@ -0,0 +1,163 @@
+package deploy
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"gopkg.in/guregu/null.v3"
+)
+
+// PromoteArgs are the arguments to promote a deployment.
+type PromoteArgs struct {
+	// Source is the deployment whose artifacts will be promoted.
+	Source *Deployment
+
+	// Env is the environment that the deployment is promoted to.
+	Env *buildconf.Env
+
+	// ReinjectEnvVars specifies whether the runtime environment variables of the
+	// target environment should be used. When false, the promoted deployment keeps
+	// using the variables of the source deployment, so that it behaves exactly
+	// like the one that was tested.
+	ReinjectEnvVars bool
+
+	// RequestedBy is the id of the user who promotes the deployment. When the
+	// target environment is protected, the promoted deployment waits for the
+	// approval of team admins other than this user.
+	RequestedBy types.ID
+
+	// Rollout overwrites the rollout plan of the target environment. When neither
+	// is set, the promoted deployment is published to 100% right away.
+	Rollout *buildconf.RolloutPlan
+}
+
+// Promote creates a new deployment under the target environment that points
+// to the same storage, function and API locations as the source deployment,
+// and publishes it. The source deployment is not rebuilt. When the environment
+// is protected, an approval is requested instead of publishing the deployment.
+func Promote(ctx context.Context, args PromoteArgs) (*Deployment, error) {
+	source := args.Source
+
+	if !source.ExitCode.Valid || source.ExitCode.ValueOrZero() != int64(ExitCodeSuccess) || source.Error.ValueOrZero() != "" {
+		return nil, ErrPromoteNotSucceeded
+	}
+
+	if source.EnvID == args.Env.ID {
+		return nil, ErrPromoteSameEnv
+	}
+
+	protected := args.Env.Data.IsProtected()
+
+	// Protected environments check the freeze windows once the publish is approved.
+	if !protected {
+		if w, until := args.Env.Data.ActiveFreezeWindow(time.Now()); w != nil {
+			return nil, ErrFreezeWindow(args.Env.Name, w.Name, until)
+		}
+	}
+
+	snapshot := ConfigSnapshot{}
+
+	if len(source.ConfigCopy) > 0 {
+		if err := json.Unmarshal(source.ConfigCopy, &snapshot); err != nil {
+			return nil, err
+		}
+	}
+
+	var runtimeVars map[string]string
+
+	if snapshot.BuildConfig == nil {
+		snapshot.BuildConfig = &buildconf.BuildConf{}
+	}
+
+	if args.ReinjectEnvVars {
+		if args.Env.Data != nil {
+			snapshot.BuildConfig.Vars = args.Env.Data.Vars
+		}
+	} else {
+		runtimeVars = snapshot.BuildConfig.Vars
+
+		if runtimeVars == nil {
+			runtimeVars = map[string]string{}
+		}
+	}
+
+	snapshot.EnvName = args.Env.Name
+	snapshot.EnvID = args.Env.ID.String()
+
+	data, err := json.Marshal(snapshot)
+
+	if err != nil {
+		return nil, err
+	}
+
+	d := &Deployment{
+		AppID:        source.AppID,
+		EnvID:        args.Env.ID,
+		Env:          args.Env.Name,
+		Branch:       source.Branch,
+		ConfigCopy:   data,
+		Commit:       source.Commit,
+		ExitCode:     null.IntFrom(int64(ExitCodeSuccess)),
+		IsImmutable:  null.BoolFrom(true),
+		PromotedFrom: null.IntFrom(int64(source.ID)),
+		DisplayName:  source.DisplayName,
+	}
+
+	store := NewStore()
+
+	if err := store.InsertPromotedDeployment(ctx, source.ID, d, runtimeVars); err != nil {
+		return nil, err
+	}
+
+	if protected {
+		_, err = RequestApproval(ctx, args.Env, []PublishConfig{{DeploymentID: d.ID, Percentage: 100}}, args.RequestedBy)
+	} else {
+		err = publishPromoted(ctx, d, args)
+	}
+
+	// Do not leave behind a deployment that was never published, for instance
+	// when a freeze window started in the meantime.
+	if err != nil {
+		if derr := store.DeletePromotedDeployment(ctx, d.ID); derr != nil {
+			slog.Errorf("cannot delete promoted deployment %s: %v", d.ID.String(), derr)
+		}
+
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// publishPromoted publishes the promoted deployment according to the rollout plan
+// of the request or the environment, or to 100% when there is none.
+func publishPromoted(ctx context.Context, d *Deployment, args PromoteArgs) error {
+	plan := args.Rollout
+
+	if plan == nil && args.Env.Data != nil {
+		plan = args.Env.Data.Rollout
+	}
+
+	if plan != nil {
+		_, err := StartRollout(ctx, d, *plan)
+		return err
+	}
+
+	err := Publish(ctx, []*PublishSettings{
+		{
+			EnvID:        d.EnvID,
+			DeploymentID: d.ID,
+			Percentage:   100,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	return NewStore().CancelRollouts(ctx, d.EnvID, fmt.Sprintf("Superseded by deployment %s", d.ID.String()))
+}
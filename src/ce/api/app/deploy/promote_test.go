@@ -0,0 +1,173 @@
+package deploy_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type PromoteSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn             databasetest.TestDB
+	mockCacheService *mocks.CacheInterface
+	production       *factory.MockEnv
+}
+
+func (s *PromoteSuite) BeforeTest(suiteName, testName string) {
+	s.conn = databasetest.InitTx(suiteName + "_" + testName)
+	s.Factory = factory.New(s.conn)
+	s.mockCacheService = &mocks.CacheInterface{}
+	appcache.DefaultCacheService = s.mockCacheService
+}
+
+func (s *PromoteSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	appcache.DefaultCacheService = nil
+}
+
+func (s *PromoteSuite) source() (*deploy.Deployment, *buildconf.Env) {
+	app := s.MockApp(nil)
+	staging := s.MockEnv(app, map[string]any{"Name": "staging"})
+	production := s.MockEnv(app, map[string]any{
+		"Name": "production",
+		"Data": &buildconf.BuildConf{Vars: map[string]string{"API_URL": "https://api.example.org"}},
+	})
+
+	depl := s.MockDeployment(staging, map[string]any{
+		"ExitCode":         null.IntFrom(0),
+		"StorageLocation":  null.StringFrom("aws:my-bucket/staging/1"),
+		"FunctionLocation": null.StringFrom("aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1"),
+	})
+
+	source, err := deploy.NewStore().DeploymentByID(context.Background(), depl.ID)
+	s.NoError(err)
+
+	s.production = production
+	return source, production.Env
+}
+
+func (s *PromoteSuite) Test_Promote() {
+	source, env := s.source()
+
+	s.mockCacheService.On("Reset", env.ID).Return(nil).Once()
+
+	d, err := deploy.Promote(context.Background(), deploy.PromoteArgs{Source: source, Env: env})
+	s.NoError(err)
+	s.NotZero(d.ID)
+
+	var storageLocation, functionLocation string
+	var runtimeVars []byte
+	var promotedFrom, exitCode int64
+	var percentage float64
+
+	s.NoError(s.conn.QueryRow(`
+		SELECT
+			d.storage_location, d.function_location, d.runtime_vars::text,
+			d.promoted_from, d.exit_code, dp.percentage_released
+		FROM deployments d
+		INNER JOIN deployments_published dp ON dp.deployment_id = d.deployment_id
+		WHERE d.deployment_id = $1 AND d.env_id = $2 AND d.is_immutable IS TRUE`, d.ID, env.ID,
+	).Scan(&storageLocation, &functionLocation, &runtimeVars, &promotedFrom, &exitCode, &percentage))
+
+	s.Equal("aws:my-bucket/staging/1", storageLocation)
+	s.Equal("aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1", functionLocation)
+	s.NotContains(string(runtimeVars), "NODE_ENV")
+	s.Equal(int64(source.ID), promotedFrom)
+	s.Equal(int64(0), exitCode)
+	s.Equal(float64(100), percentage)
+	s.Equal("production", d.Snapshot()["env"])
+
+	vars, err := deploy.OpenRuntimeVars(runtimeVars)
+	s.NoError(err)
+	s.Equal(map[string]string{"NODE_ENV": "production"}, vars)
+}
+
+func (s *PromoteSuite) Test_Promote_ReinjectEnvVars() {
+	source, env := s.source()
+
+	s.mockCacheService.On("Reset", env.ID).Return(nil).Once()
+
+	d, err := deploy.Promote(context.Background(), deploy.PromoteArgs{Source: source, Env: env, ReinjectEnvVars: true})
+	s.NoError(err)
+
+	var runtimeVars sql.NullString
+
+	s.NoError(s.conn.QueryRow(`SELECT runtime_vars::text FROM deployments WHERE deployment_id = $1`, d.ID).Scan(&runtimeVars))
+	s.False(runtimeVars.Valid)
+	s.Equal(map[string]any{"API_URL": "https://api.example.org"}, d.Snapshot()["build"].(map[string]any)["vars"])
+}
+
+func (s *PromoteSuite) Test_Promote_Errors() {
+	source, env := s.source()
+
+	source.ExitCode = null.IntFrom(1)
+	_, err := deploy.Promote(context.Background(), deploy.PromoteArgs{Source: source, Env: env})
+	s.Equal(deploy.ErrPromoteNotSucceeded, err)
+
+	source.ExitCode = null.IntFrom(0)
+	source.EnvID = env.ID
+	_, err = deploy.Promote(context.Background(), deploy.PromoteArgs{Source: source, Env: env})
+	s.Equal(deploy.ErrPromoteSameEnv, err)
+}
+
+func (s *PromoteSuite) Test_Promote_FreezeWindow() {
+	source, env := s.source()
+
+	env.Data.FreezeWindows = []buildconf.FreezeWindow{
+		{Name: "Holidays", StartsAt: utils.UnixFrom(time.Now().Add(-time.Hour)), EndsAt: utils.UnixFrom(time.Now().Add(time.Hour))},
+	}
+
+	_, err := deploy.Promote(context.Background(), deploy.PromoteArgs{Source: source, Env: env})
+	s.ErrorContains(err, "Holidays freeze window")
+
+	var count int
+	s.NoError(s.conn.QueryRow(`SELECT COUNT(*) FROM deployments WHERE promoted_from = $1`, source.ID).Scan(&count))
+	s.Equal(0, count)
+}
+
+func (s *PromoteSuite) Test_Promote_Rollout() {
+	source, env := s.source()
+
+	s.MockDeployment(s.production, map[string]any{
+		"ExitCode":  null.IntFrom(0),
+		"Published": []deploy.PublishedInfo{{EnvID: env.ID, Percentage: 100}},
+	})
+
+	s.mockCacheService.On("Reset", env.ID).Return(nil)
+
+	d, err := deploy.Promote(context.Background(), deploy.PromoteArgs{
+		Source: source,
+		Env:    env,
+		Rollout: &buildconf.RolloutPlan{
+			Steps: []buildconf.RolloutStep{
+				{Percentage: 10, Interval: 10},
+				{Percentage: 100, Interval: 10},
+			},
+		},
+	})
+
+	s.NoError(err)
+
+	r, err := deploy.NewStore().RolloutByEnvID(context.Background(), env.ID)
+	s.NoError(err)
+	s.Equal(d.ID, r.DeploymentID)
+	s.Equal(deploy.RolloutStatusRunning, r.Status)
+	s.Equal(float64(10), r.Percentage())
+}
+
+func TestPromoteSuite(t *testing.T) {
+	suite.Run(t, &PromoteSuite{})
+}
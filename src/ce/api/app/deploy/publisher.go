@@ -2,6 +2,7 @@ package deploy
 
 import (
 	"context"
+	"time"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
@@ -16,6 +17,32 @@ type PublishSettings struct {
 	EnvID        types.ID
 	Percentage   float64
 	NoCacheReset bool
+
+	// IgnoreFreeze publishes the deployment even when the environment is in a
+	// freeze window. It is used for rollbacks and audited admin overrides.
+	IgnoreFreeze bool
+}
+
+// PublishConfig is the percentage that a deployment is published with. It is
+// stored for publishes that are executed later, such as scheduled publishes.
+type PublishConfig struct {
+	DeploymentID types.ID `json:"deploymentId,string"`
+	Percentage   float64  `json:"percentage"`
+}
+
+// publishSettingsFromConfig returns the publish settings for the given environment.
+func publishSettingsFromConfig(envID types.ID, config []PublishConfig) []*PublishSettings {
+	settings := []*PublishSettings{}
+
+	for _, c := range config {
+		settings = append(settings, &PublishSettings{
+			EnvID:        envID,
+			DeploymentID: c.DeploymentID,
+			Percentage:   c.Percentage,
+		})
+	}
+
+	return settings
 }
 
 // AutoPublish automatically publishes successful deployments if the
@@ -25,6 +52,24 @@ func AutoPublishIfNecessary(ctx context.Context, d *Deployment) error {
 		return nil
 	}
 
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, d.EnvID)
+
+	if err != nil {
+		return err
+	}
+
+	// Publishes to protected environments wait for the approval of team admins.
+	if env != nil && env.Data.IsProtected() {
+		_, err := RequestApproval(ctx, env, []PublishConfig{{DeploymentID: d.ID, Percentage: 100}}, 0)
+		return err
+	}
+
+	// Environments with a rollout plan publish new deployments progressively.
+	if env != nil && env.Data != nil && env.Data.Rollout != nil {
+		_, err := StartRollout(ctx, d, *env.Data.Rollout)
+		return err
+	}
+
 	settings := []*PublishSettings{
 		{
 			EnvID:        d.EnvID,
@@ -38,6 +83,10 @@ func AutoPublishIfNecessary(ctx context.Context, d *Deployment) error {
 
 // Publish publishes a new deployment.
 func Publish(ctx context.Context, settings []*PublishSettings) error {
+	if err := checkFreezeWindows(ctx, settings); err != nil {
+		return err
+	}
+
 	if err := NewStore().Publish(ctx, settings...); err != nil {
 		return err
 	}
@@ -70,24 +119,50 @@ func Publish(ctx context.Context, settings []*PublishSettings) error {
 			}
 		}
 
-		whs := app.NewStore().OutboundWebhooks(ctx, env.AppID)
 		cnf := admin.MustConfig()
 
-		for _, wh := range whs {
-			if wh.TriggerOnPublish() {
-				wh.Dispatch(app.OutboundWebhookSettings{
-					AppID:                  env.AppID,
-					DeploymentID:           s.DeploymentID,
-					DeploymentStatus:       "success",
-					EnvironmentName:        env.Name,
-					DeploymentEndpoint:     cnf.PreviewURL(appl.DisplayName, s.DeploymentID.String()),
-					DeploymentLogsEndpoint: cnf.DeploymentLogsURL(env.AppID, s.DeploymentID),
-				})
-			}
-		}
+		app.TriggerEvent(ctx, app.TriggerOnPublish, app.OutboundWebhookSettings{
+			AppID:                  env.AppID,
+			DeploymentID:           s.DeploymentID,
+			DeploymentStatus:       "success",
+			EnvironmentName:        env.Name,
+			EnvironmentProtected:   env.Data.IsProtected(),
+			DeploymentEndpoint:     cnf.PreviewURL(appl.DisplayName, s.DeploymentID.String()),
+			DeploymentLogsEndpoint: cnf.DeploymentLogsURL(env.AppID, s.DeploymentID),
+		})
 
 		envIDs[envID] = true
 	}
 
 	return nil
 }
+
+// checkFreezeWindows returns an error when any of the environments is in a freeze window.
+func checkFreezeWindows(ctx context.Context, settings []*PublishSettings) error {
+	checked := map[types.ID]bool{}
+	store := buildconf.NewStore()
+
+	for _, s := range settings {
+		if s.IgnoreFreeze || checked[s.EnvID] {
+			continue
+		}
+
+		checked[s.EnvID] = true
+
+		env, err := store.EnvironmentByID(ctx, s.EnvID)
+
+		if err != nil {
+			return err
+		}
+
+		if env == nil {
+			continue
+		}
+
+		if w, until := env.Data.ActiveFreezeWindow(time.Now()); w != nil {
+			return ErrFreezeWindow(env.Name, w.Name, until)
+		}
+	}
+
+	return nil
+}
@@ -2,19 +2,25 @@ package deploy_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
-	"net/http"
 	"testing"
+	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/hibiken/asynq"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 	"gopkg.in/guregu/null.v3"
 )
@@ -26,6 +32,8 @@ type PublisherSuite struct {
 	conn             databasetest.TestDB
 	mockRequest      *mocks.RequestInterface
 	mockCacheService *mocks.CacheInterface
+	mockClient       *mocks.TaskClient
+	originalClient   func() tasks.TaskClient
 }
 
 func (s *PublisherSuite) BeforeTest(suiteName, testName string) {
@@ -33,8 +41,13 @@ func (s *PublisherSuite) BeforeTest(suiteName, testName string) {
 	s.Factory = factory.New(s.conn)
 	s.mockRequest = &mocks.RequestInterface{}
 	s.mockCacheService = &mocks.CacheInterface{}
+	s.mockClient = &mocks.TaskClient{}
+	s.originalClient = tasks.Client
 	shttp.DefaultRequest = s.mockRequest
 	appcache.DefaultCacheService = s.mockCacheService
+	tasks.Client = func() tasks.TaskClient {
+		return s.mockClient
+	}
 }
 
 func (s *PublisherSuite) AfterTest(_, _ string) {
@@ -42,6 +55,7 @@ func (s *PublisherSuite) AfterTest(_, _ string) {
 	s.mockCacheService = nil
 	shttp.DefaultRequest = nil
 	appcache.DefaultCacheService = nil
+	tasks.Client = s.originalClient
 }
 
 func (s *PublisherSuite) Test_PublishingMultiple() {
@@ -143,27 +157,32 @@ func (s *PublisherSuite) Test_PublishOutboundWebhooks() {
 		"Content-Type": "application/json",
 	}
 
-	// Required for webhook dispatch
-	s.mockRequest.On("Method", http.MethodPost).Return(s.mockRequest).Once()
-	s.mockRequest.On("URL", "http://example.org/webhooks/publish").Return(s.mockRequest).Once()
-	s.mockRequest.On("Headers", shttp.HeadersFromMap(headers)).Return(s.mockRequest).Once()
-	s.mockRequest.On("Do").Return(nil, nil).Once()
-	s.mockRequest.On("Payload",
-		fmt.Sprintf(`{ "deployment_id": "%s" }`, depl.ID.String()),
-	).Return(s.mockRequest).Once()
-
-	err := app.NewStore().InsertOutboundWebhook(context.Background(), appl.ID, app.OutboundWebhook{
+	wh := &app.OutboundWebhook{
 		TriggerWhen:    app.TriggerOnPublish,
 		RequestURL:     "http://example.org/webhooks/publish",
 		RequestMethod:  shttp.MethodPost,
 		RequestPayload: null.NewString(`{ "deployment_id": "$SK_DEPLOYMENT_ID" }`, true),
 		RequestHeaders: headers,
-	})
+	}
 
+	s.NoError(app.NewStore().InsertOutboundWebhook(context.Background(), appl.ID, wh))
 	s.mockCacheService.On("Reset", env.ID).Return(nil)
-
-	s.NoError(err)
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
 	s.Nil(deploy.Publish(context.Background(), settings))
+
+	// The webhook is delivered by the worker server
+	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		msg := app.OutboundWebhookMessage{}
+		s.NoError(json.Unmarshal(task.Payload(), &msg))
+
+		return s.Equal(tasks.OutboundWebhookDelivery, task.Type()) &&
+			s.Equal(app.OutboundWebhookMessage{
+				AppID:       appl.ID,
+				WebhookID:   wh.WebhookID,
+				TriggerWhen: app.TriggerOnPublish,
+				Payload:     fmt.Sprintf(`{ "deployment_id": "%s" }`, depl.ID.String()),
+			}, msg)
+	}))
 }
 
 func (s *PublisherSuite) Test_AutoPublish() {
@@ -191,6 +210,30 @@ func (s *PublisherSuite) Test_AutoPublish() {
 	s.Equal(depl.ID, depls[0].ID)
 }
 
+func (s *PublisherSuite) Test_PublishDuringFreezeWindow() {
+	env := s.MockEnv(nil, map[string]any{
+		"Data": &buildconf.BuildConf{
+			FreezeWindows: []buildconf.FreezeWindow{{
+				Name:     "Holiday freeze",
+				StartsAt: utils.UnixFrom(time.Now().Add(-time.Hour)),
+				EndsAt:   utils.UnixFrom(time.Now().Add(time.Hour)),
+			}},
+		},
+	})
+
+	depl := s.MockDeployment(env, map[string]any{"ExitCode": null.NewInt(0, true)})
+	settings := []*deploy.PublishSettings{{EnvID: env.ID, DeploymentID: depl.ID, Percentage: 100}}
+
+	err := deploy.Publish(context.Background(), settings)
+	s.True(deploy.IsFreezeWindowError(err))
+	s.Contains(err.Error(), "Holiday freeze")
+
+	// Overriding the freeze window publishes the deployment
+	s.mockCacheService.On("Reset", env.ID).Return(nil).Once()
+	settings[0].IgnoreFreeze = true
+	s.NoError(deploy.Publish(context.Background(), settings))
+}
+
 func TestPublisher(t *testing.T) {
 	suite.Run(t, &PublisherSuite{})
 }
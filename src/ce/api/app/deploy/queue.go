@@ -0,0 +1,58 @@
+package deploy
+
+import (
+	"encoding/json"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+const (
+	QueueStatusQueued     = "queued"
+	QueueStatusDispatched = "dispatched"
+	QueueStatusCancelled  = "cancelled"
+)
+
+// Deployments with a higher priority leave the build queue first.
+const (
+	QueuePriorityPreview    = 0
+	QueuePriorityProduction = 10
+)
+
+// QueueInfo describes the state of a deployment in the build queue.
+type QueueInfo struct {
+	Status   string `json:"status"`
+	Position int    `json:"position,omitempty"` // 1-based position among the queued deployments, 0 once dispatched. Only set by Store.QueueInfo.
+	QueuedAt int64  `json:"queuedAt"`           // Unix timestamp in seconds.
+	WaitTime int64  `json:"waitTime"`           // Seconds spent in the queue so far.
+}
+
+// Scan implements the Scanner interface.
+func (qi *QueueInfo) Scan(value any) error {
+	if b, ok := value.([]byte); ok {
+		return json.Unmarshal(b, qi)
+	}
+
+	return nil
+}
+
+// QueuedDeployment is a deployment that waits in the build queue until there
+// is enough capacity to build it.
+type QueuedDeployment struct {
+	DeploymentID types.ID
+	AppID        types.ID
+	EnvID        types.ID
+	TeamID       types.ID
+	Branch       string
+	Priority     int
+	Payload      string // The encrypted deployment message.
+}
+
+// QueuePriority returns the priority of the deployment in the build queue.
+// Deployments of the environment branch are built before previews.
+func QueuePriority(d *Deployment) int {
+	if d.PullRequestNumber.ValueOrZero() == 0 && (d.EnvBranchName == "" || d.Branch == d.EnvBranchName) {
+		return QueuePriorityProduction
+	}
+
+	return QueuePriorityPreview
+}
@@ -0,0 +1,371 @@
+package deploy
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	RolloutStatusRunning    = "running"
+	RolloutStatusCompleted  = "completed"
+	RolloutStatusRolledBack = "rolled_back"
+	RolloutStatusCancelled  = "cancelled"
+)
+
+// rolloutMetricsTTL is the expiry of the metrics of a rollout step. It is
+// refreshed on each step, and prevents leaking keys of abandoned rollouts.
+const rolloutMetricsTTL = 24 * time.Hour
+
+// Rollout represents the progressive rollout of a deployment. The deployment
+// receives an increasing percentage of the traffic, while the remaining traffic
+// is routed to the previously published deployment.
+type Rollout struct {
+	ID                   types.ID              `json:"id,string"`
+	AppID                types.ID              `json:"appId,string"`
+	EnvID                types.ID              `json:"envId,string"`
+	DeploymentID         types.ID              `json:"deploymentId,string"`
+	PreviousDeploymentID types.ID              `json:"previousDeploymentId,string"`
+	Plan                 buildconf.RolloutPlan `json:"plan"`
+	CurrentStep          int                   `json:"currentStep"`
+	Status               string                `json:"status"`
+	Reason               null.String           `json:"reason"`
+	NextStepAt           utils.Unix            `json:"nextStepAt"`
+	UpdatedAt            utils.Unix            `json:"updatedAt"`
+	CreatedAt            utils.Unix            `json:"createdAt"`
+}
+
+// RolloutMetrics are the metrics collected for the candidate deployment
+// during the current step of a rollout.
+type RolloutMetrics struct {
+	Requests       int64 `json:"requests"`
+	Errors5xx      int64 `json:"errors5xx"`
+	FunctionErrors int64 `json:"functionErrors"`
+}
+
+// ErrorRate returns the percentage of 5xx responses.
+func (m RolloutMetrics) ErrorRate() float64 {
+	if m.Requests == 0 {
+		return 0
+	}
+
+	return float64(m.Errors5xx) * 100 / float64(m.Requests)
+}
+
+// Percentage returns the traffic percentage of the current step.
+func (r *Rollout) Percentage() float64 {
+	if r.CurrentStep < 0 || r.CurrentStep >= len(r.Plan.Steps) {
+		return 0
+	}
+
+	return r.Plan.Steps[r.CurrentStep].Percentage
+}
+
+// IsLastStep returns true when the current step is the last one of the plan.
+func (r *Rollout) IsLastStep() bool {
+	return r.CurrentStep >= len(r.Plan.Steps)-1
+}
+
+// ExceedsThresholds checks the given metrics against the thresholds of the plan.
+// When a threshold is exceeded, the reason is returned.
+func (r *Rollout) ExceedsThresholds(m RolloutMetrics) (string, bool) {
+	plan := r.Plan
+
+	if plan.MaxFunctionErrors > 0 && m.FunctionErrors > plan.MaxFunctionErrors {
+		return fmt.Sprintf("Function errors (%d) exceeded the threshold (%d)", m.FunctionErrors, plan.MaxFunctionErrors), true
+	}
+
+	if plan.MaxErrorRate > 0 && m.Requests > 0 && m.Requests >= plan.MinRequests && m.ErrorRate() > plan.MaxErrorRate {
+		return fmt.Sprintf("Error rate (%.2f%%) exceeded the threshold (%.2f%%)", m.ErrorRate(), plan.MaxErrorRate), true
+	}
+
+	return "", false
+}
+
+// publishSettings returns the publish settings for the current step.
+func (r *Rollout) publishSettings() []*PublishSettings {
+	percentage := r.Percentage()
+	settings := []*PublishSettings{
+		{
+			EnvID:        r.EnvID,
+			DeploymentID: r.DeploymentID,
+			Percentage:   percentage,
+		},
+	}
+
+	if percentage < 100 {
+		settings = append(settings, &PublishSettings{
+			EnvID:        r.EnvID,
+			DeploymentID: r.PreviousDeploymentID,
+			Percentage:   100 - percentage,
+		})
+	}
+
+	return settings
+}
+
+// nextStepAt returns the time when the current step is over.
+func (r *Rollout) nextStepAt() utils.Unix {
+	interval := time.Duration(r.Plan.Steps[r.CurrentStep].Interval) * time.Minute
+	return utils.UnixFrom(time.Now().Add(interval))
+}
+
+// StartRollout starts publishing the given deployment progressively according to
+// the given plan. Any running rollout in the same environment is cancelled. When
+// there is no previously published deployment, the deployment is published to 100%
+// right away.
+func StartRollout(ctx context.Context, d *Deployment, plan buildconf.RolloutPlan) (*Rollout, error) {
+	if !d.ExitCode.Valid || d.ExitCode.ValueOrZero() != int64(ExitCodeSuccess) || d.Error.ValueOrZero() != "" {
+		return nil, ErrRolloutNotSucceeded
+	}
+
+	store := NewStore()
+	current, err := store.RolloutByEnvID(ctx, d.EnvID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var previousID types.ID
+
+	// When a rollout is already running, the previous deployment is still the
+	// stable one. Otherwise, it is the one that receives most of the traffic.
+	if current != nil && current.Status == RolloutStatusRunning {
+		previousID = current.PreviousDeploymentID
+	} else if previousID, err = store.PublishedDeploymentID(ctx, d.EnvID); err != nil {
+		return nil, err
+	}
+
+	r := &Rollout{
+		AppID:                d.AppID,
+		EnvID:                d.EnvID,
+		DeploymentID:         d.ID,
+		PreviousDeploymentID: previousID,
+		Plan:                 plan,
+		Status:               RolloutStatusRunning,
+	}
+
+	if previousID == 0 || previousID == d.ID {
+		r.PreviousDeploymentID = 0
+		r.CurrentStep = len(plan.Steps) - 1
+		r.Status = RolloutStatusCompleted
+		r.Reason = null.StringFrom("There is no previously published deployment")
+	} else {
+		r.NextStepAt = r.nextStepAt()
+	}
+
+	if err := Publish(ctx, r.publishSettings()); err != nil {
+		return nil, err
+	}
+
+	// The running rollout is cancelled only once the new one is published, so that
+	// it keeps going when the publish is rejected, for instance by a freeze window.
+	if err := store.CancelRollouts(ctx, d.EnvID, fmt.Sprintf("Superseded by deployment %s", d.ID.String())); err != nil {
+		return nil, err
+	}
+
+	if err := store.InsertRollout(ctx, r); err != nil {
+		return nil, err
+	}
+
+	if r.Status == RolloutStatusRunning {
+		ResetRolloutMetrics(ctx, r.DeploymentID)
+	}
+
+	dispatchRolloutWebhooks(ctx, r, app.TriggerOnRolloutStep)
+	return r, nil
+}
+
+// AdvanceRollout rolls back the rollout when the metrics of the candidate deployment
+// exceed the thresholds. Otherwise, it moves on to the next step when the current
+// one is over, and completes the rollout after the last step.
+func AdvanceRollout(ctx context.Context, r *Rollout) error {
+	metrics, err := RolloutMetricsByDeploymentID(ctx, r.DeploymentID)
+
+	if err != nil {
+		return err
+	}
+
+	if reason, ok := r.ExceedsThresholds(metrics); ok {
+		return Rollback(ctx, r, reason)
+	}
+
+	if r.NextStepAt.Valid && r.NextStepAt.After(time.Now()) {
+		return nil
+	}
+
+	store := NewStore()
+
+	if r.IsLastStep() {
+		r.Status = RolloutStatusCompleted
+
+		if err := store.UpdateRollout(ctx, r); err != nil {
+			return err
+		}
+
+		deleteRolloutMetrics(ctx, r.DeploymentID)
+		dispatchRolloutWebhooks(ctx, r, app.TriggerOnRolloutStep)
+		return nil
+	}
+
+	r.CurrentStep = r.CurrentStep + 1
+	r.NextStepAt = r.nextStepAt()
+
+	if err := Publish(ctx, r.publishSettings()); err != nil {
+		return err
+	}
+
+	if err := store.UpdateRollout(ctx, r); err != nil {
+		return err
+	}
+
+	ResetRolloutMetrics(ctx, r.DeploymentID)
+	dispatchRolloutWebhooks(ctx, r, app.TriggerOnRolloutStep)
+	return nil
+}
+
+// Rollback publishes the previous deployment to 100% and marks the rollout as rolled back.
+func Rollback(ctx context.Context, r *Rollout, reason string) error {
+	if r.Status != RolloutStatusRunning {
+		return ErrRolloutNotRunning
+	}
+
+	err := Publish(ctx, []*PublishSettings{
+		{
+			EnvID:        r.EnvID,
+			DeploymentID: r.PreviousDeploymentID,
+			Percentage:   100,
+			IgnoreFreeze: true,
+		},
+	})
+
+	if err != nil {
+		return err
+	}
+
+	r.Status = RolloutStatusRolledBack
+	r.Reason = null.StringFrom(reason)
+
+	if err := NewStore().UpdateRollout(ctx, r); err != nil {
+		return err
+	}
+
+	deleteRolloutMetrics(ctx, r.DeploymentID)
+	dispatchRolloutWebhooks(ctx, r, app.TriggerOnRollback)
+	return nil
+}
+
+func rolloutMetricsKey(deploymentID types.ID) string {
+	return fmt.Sprintf("rollout_metrics:%s", deploymentID.String())
+}
+
+// ResetRolloutMetrics resets the metrics of the given deployment. Metrics are
+// only collected for deployments that have been reset, that is, deployments that
+// are being rolled out.
+func ResetRolloutMetrics(ctx context.Context, deploymentID types.ID) {
+	key := rolloutMetricsKey(deploymentID)
+	pipe := rediscache.Client().TxPipeline()
+	pipe.HSet(ctx, key, "requests", 0, "errors5xx", 0, "functionErrors", 0)
+	pipe.Expire(ctx, key, rolloutMetricsTTL)
+
+	if _, err := pipe.Exec(ctx); err != nil {
+		slog.Errorf("cannot reset rollout metrics for deployment %s: %v", deploymentID.String(), err)
+	}
+}
+
+func deleteRolloutMetrics(ctx context.Context, deploymentID types.ID) {
+	if err := rediscache.Client().Del(ctx, rolloutMetricsKey(deploymentID)).Err(); err != nil {
+		slog.Errorf("cannot delete rollout metrics for deployment %s: %v", deploymentID.String(), err)
+	}
+}
+
+// IncrementRolloutMetrics adds the given metrics to the deployments that are
+// being rolled out. Metrics of other deployments are discarded.
+func IncrementRolloutMetrics(ctx context.Context, metrics map[types.ID]RolloutMetrics) error {
+	client := rediscache.Client()
+
+	for deploymentID, m := range metrics {
+		key := rolloutMetricsKey(deploymentID)
+		exists, err := client.Exists(ctx, key).Result()
+
+		if err != nil {
+			return err
+		}
+
+		if exists == 0 {
+			continue
+		}
+
+		pipe := client.Pipeline()
+		pipe.HIncrBy(ctx, key, "requests", m.Requests)
+		pipe.HIncrBy(ctx, key, "errors5xx", m.Errors5xx)
+		pipe.HIncrBy(ctx, key, "functionErrors", m.FunctionErrors)
+
+		if _, err := pipe.Exec(ctx); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RolloutMetricsByDeploymentID returns the metrics collected during the current
+// step of the rollout.
+func RolloutMetricsByDeploymentID(ctx context.Context, deploymentID types.ID) (RolloutMetrics, error) {
+	m := RolloutMetrics{}
+	values, err := rediscache.Client().HGetAll(ctx, rolloutMetricsKey(deploymentID)).Result()
+
+	if err != nil {
+		return m, err
+	}
+
+	m.Requests, _ = strconv.ParseInt(values["requests"], 10, 64)
+	m.Errors5xx, _ = strconv.ParseInt(values["errors5xx"], 10, 64)
+	m.FunctionErrors, _ = strconv.ParseInt(values["functionErrors"], 10, 64)
+	return m, nil
+}
+
+func dispatchRolloutWebhooks(ctx context.Context, r *Rollout, trigger string) {
+	whs := app.NewStore().OutboundWebhooks(ctx, r.AppID)
+
+	if len(whs) == 0 {
+		return
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, r.EnvID)
+
+	if err != nil || env == nil {
+		slog.Errorf("cannot fetch environment for rollout webhooks: %v", err)
+		return
+	}
+
+	appl, err := app.NewStore().AppByEnvID(ctx, r.EnvID)
+
+	if err != nil || appl == nil {
+		slog.Errorf("cannot fetch app for rollout webhooks: %v", err)
+		return
+	}
+
+	cnf := admin.MustConfig()
+
+	app.TriggerEvent(ctx, trigger, app.OutboundWebhookSettings{
+		AppID:                  r.AppID,
+		DeploymentID:           r.DeploymentID,
+		EnvironmentName:        env.Name,
+		DeploymentEndpoint:     cnf.PreviewURL(appl.DisplayName, r.DeploymentID.String()),
+		DeploymentLogsEndpoint: cnf.DeploymentLogsURL(r.AppID, r.DeploymentID),
+		RolloutPercentage:      strconv.FormatFloat(r.Percentage(), 'f', -1, 64),
+		RolloutStatus:          r.Status,
+		RolloutReason:          r.Reason.ValueOrZero(),
+	})
+}
@@ -0,0 +1,224 @@
+package deploy_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type RolloutSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn             databasetest.TestDB
+	mockCacheService *mocks.CacheInterface
+	env              *factory.MockEnv
+	previous         *factory.MockDeployment
+	candidate        *deploy.Deployment
+	plan             buildconf.RolloutPlan
+}
+
+func (s *RolloutSuite) BeforeTest(suiteName, testName string) {
+	s.conn = databasetest.InitTx(suiteName + "_" + testName)
+	s.Factory = factory.New(s.conn)
+	s.mockCacheService = &mocks.CacheInterface{}
+	s.mockCacheService.On("Reset", mock.Anything).Return(nil)
+	appcache.DefaultCacheService = s.mockCacheService
+
+	s.env = s.MockEnv(nil)
+	s.previous = s.MockDeployment(s.env, map[string]any{
+		"ExitCode":  null.IntFrom(0),
+		"Published": []deploy.PublishedInfo{{EnvID: s.env.ID, Percentage: 100}},
+	})
+
+	candidate := s.MockDeployment(s.env, map[string]any{"ExitCode": null.IntFrom(0)})
+	s.candidate = candidate.Deployment
+	s.plan = buildconf.RolloutPlan{
+		Steps: []buildconf.RolloutStep{
+			{Percentage: 5, Interval: 10},
+			{Percentage: 50, Interval: 10},
+			{Percentage: 100, Interval: 10},
+		},
+		MaxErrorRate:      5,
+		MaxFunctionErrors: 10,
+		MinRequests:       100,
+	}
+}
+
+func (s *RolloutSuite) AfterTest(_, _ string) {
+	rediscache.Client().Del(context.Background(), "rollout_metrics:"+s.candidate.ID.String())
+	s.conn.CloseTx()
+	appcache.DefaultCacheService = nil
+}
+
+func (s *RolloutSuite) published() map[types.ID]float64 {
+	rows, err := s.conn.Query(`SELECT deployment_id, percentage_released FROM deployments_published WHERE env_id = $1`, s.env.ID)
+	s.NoError(err)
+	defer rows.Close()
+
+	published := map[types.ID]float64{}
+
+	for rows.Next() {
+		var id types.ID
+		var percentage float64
+		s.NoError(rows.Scan(&id, &percentage))
+		published[id] = percentage
+	}
+
+	return published
+}
+
+func (s *RolloutSuite) rollout() *deploy.Rollout {
+	r, err := deploy.NewStore().RolloutByEnvID(context.Background(), s.env.ID)
+	s.NoError(err)
+	s.NotNil(r)
+	return r
+}
+
+func (s *RolloutSuite) Test_StartRollout() {
+	r, err := deploy.StartRollout(context.Background(), s.candidate, s.plan)
+	s.NoError(err)
+	s.NotZero(r.ID)
+	s.Equal(deploy.RolloutStatusRunning, r.Status)
+	s.Equal(s.previous.ID, r.PreviousDeploymentID)
+	s.Equal(map[types.ID]float64{s.candidate.ID: 5, s.previous.ID: 95}, s.published())
+
+	stored := s.rollout()
+	s.Equal(r.ID, stored.ID)
+	s.Equal(s.plan, stored.Plan)
+	s.Equal(0, stored.CurrentStep)
+	s.True(stored.NextStepAt.After(time.Now()))
+}
+
+func (s *RolloutSuite) Test_StartRollout_NoPreviousDeployment() {
+	_, err := s.conn.Exec(`DELETE FROM deployments_published WHERE env_id = $1`, s.env.ID)
+	s.NoError(err)
+
+	r, err := deploy.StartRollout(context.Background(), s.candidate, s.plan)
+	s.NoError(err)
+	s.Equal(deploy.RolloutStatusCompleted, r.Status)
+	s.Equal(map[types.ID]float64{s.candidate.ID: 100}, s.published())
+}
+
+func (s *RolloutSuite) Test_StartRollout_NotSucceeded() {
+	s.candidate.ExitCode = null.IntFrom(1)
+	_, err := deploy.StartRollout(context.Background(), s.candidate, s.plan)
+	s.Equal(deploy.ErrRolloutNotSucceeded, err)
+}
+
+func (s *RolloutSuite) Test_AdvanceRollout() {
+	ctx := context.Background()
+	_, err := deploy.StartRollout(ctx, s.candidate, s.plan)
+	s.NoError(err)
+
+	// The step is not over yet
+	s.NoError(deploy.AdvanceRollout(ctx, s.rollout()))
+	s.Equal(0, s.rollout().CurrentStep)
+
+	for _, percentage := range []float64{50, 100} {
+		r := s.rollout()
+		r.NextStepAt = utils.UnixFrom(time.Now().Add(-time.Minute))
+		s.NoError(deploy.AdvanceRollout(ctx, r))
+		s.Equal(percentage, s.rollout().Percentage())
+	}
+
+	s.Equal(map[types.ID]float64{s.candidate.ID: 100}, s.published())
+
+	r := s.rollout()
+	r.NextStepAt = utils.UnixFrom(time.Now().Add(-time.Minute))
+	s.NoError(deploy.AdvanceRollout(ctx, r))
+	s.Equal(deploy.RolloutStatusCompleted, s.rollout().Status)
+}
+
+func (s *RolloutSuite) Test_AdvanceRollout_Rollback() {
+	ctx := context.Background()
+	_, err := deploy.StartRollout(ctx, s.candidate, s.plan)
+	s.NoError(err)
+
+	s.NoError(deploy.IncrementRolloutMetrics(ctx, map[types.ID]deploy.RolloutMetrics{
+		s.candidate.ID: {Requests: 200, Errors5xx: 20},
+	}))
+
+	s.NoError(deploy.AdvanceRollout(ctx, s.rollout()))
+
+	r := s.rollout()
+	s.Equal(deploy.RolloutStatusRolledBack, r.Status)
+	s.Equal("Error rate (10.00%) exceeded the threshold (5.00%)", r.Reason.ValueOrZero())
+	s.Equal(map[types.ID]float64{s.previous.ID: 100}, s.published())
+}
+
+func (s *RolloutSuite) Test_ExceedsThresholds() {
+	r := &deploy.Rollout{Plan: s.plan}
+
+	// Not enough requests to evaluate the error rate
+	_, exceeds := r.ExceedsThresholds(deploy.RolloutMetrics{Requests: 50, Errors5xx: 50})
+	s.False(exceeds)
+
+	reason, exceeds := r.ExceedsThresholds(deploy.RolloutMetrics{Requests: 50, FunctionErrors: 11})
+	s.True(exceeds)
+	s.Equal("Function errors (11) exceeded the threshold (10)", reason)
+
+	_, exceeds = r.ExceedsThresholds(deploy.RolloutMetrics{Requests: 100, Errors5xx: 5})
+	s.False(exceeds)
+}
+
+func (s *RolloutSuite) Test_StartRollout_CancelsRunningRollout() {
+	ctx := context.Background()
+	first, err := deploy.StartRollout(ctx, s.candidate, s.plan)
+	s.NoError(err)
+
+	next := s.MockDeployment(s.env, map[string]any{"ExitCode": null.IntFrom(0)})
+	second, err := deploy.StartRollout(ctx, next.Deployment, s.plan)
+	s.NoError(err)
+
+	// The previous deployment is still the stable one
+	s.Equal(s.previous.ID, second.PreviousDeploymentID)
+
+	rollouts, err := deploy.NewStore().Rollouts(ctx, deploy.RolloutFilters{EnvID: s.env.ID})
+	s.NoError(err)
+	s.Len(rollouts, 2)
+	s.Equal(first.ID, rollouts[1].ID)
+	s.Equal(deploy.RolloutStatusCancelled, rollouts[1].Status)
+
+	rediscache.Client().Del(ctx, "rollout_metrics:"+next.ID.String())
+}
+
+func (s *RolloutSuite) Test_StartRollout_FreezeWindowKeepsRunningRollout() {
+	ctx := context.Background()
+	first, err := deploy.StartRollout(ctx, s.candidate, s.plan)
+	s.NoError(err)
+
+	s.env.Data.FreezeWindows = []buildconf.FreezeWindow{{
+		Name:     "Holiday freeze",
+		StartsAt: utils.UnixFrom(time.Now().Add(-time.Hour)),
+		EndsAt:   utils.UnixFrom(time.Now().Add(time.Hour)),
+	}}
+
+	s.NoError(buildconf.NewStore().Update(ctx, s.env.Env))
+
+	next := s.MockDeployment(s.env, map[string]any{"ExitCode": null.IntFrom(0)})
+	_, err = deploy.StartRollout(ctx, next.Deployment, s.plan)
+	s.True(deploy.IsFreezeWindowError(err))
+
+	stored := s.rollout()
+	s.Equal(first.ID, stored.ID)
+	s.Equal(deploy.RolloutStatusRunning, stored.Status)
+	s.Equal(map[types.ID]float64{s.candidate.ID: 5, s.previous.ID: 95}, s.published())
+}
+
+func TestRolloutSuite(t *testing.T) {
+	suite.Run(t, &RolloutSuite{})
+}
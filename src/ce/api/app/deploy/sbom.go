@@ -0,0 +1,285 @@
+package deploy
+
+import (
+	"crypto/sha1"
+	"database/sql/driver"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	SBOMFormatCycloneDX = "cyclonedx"
+	SBOMFormatSPDX      = "spdx"
+)
+
+// Severity levels of the vulnerabilities, from lowest to highest.
+const (
+	SeverityUnknown  = "unknown"
+	SeverityLow      = "low"
+	SeverityModerate = "moderate"
+	SeverityHigh     = "high"
+	SeverityCritical = "critical"
+)
+
+var severityLevels = map[string]int{
+	SeverityUnknown:  0,
+	SeverityLow:      1,
+	SeverityModerate: 2,
+	SeverityHigh:     3,
+	SeverityCritical: 4,
+}
+
+// SeverityLevel returns a number that can be used to compare severities.
+func SeverityLevel(severity string) int {
+	severity = strings.ToLower(severity)
+
+	// OSV databases use medium instead of moderate at times
+	if severity == "medium" {
+		severity = SeverityModerate
+	}
+
+	return severityLevels[severity]
+}
+
+// SBOMComponent is a dependency that was shipped with the deployment.
+type SBOMComponent struct {
+	Name      string `json:"name"`
+	Version   string `json:"version"`
+	Integrity string `json:"integrity,omitempty"`
+	Dev       bool   `json:"dev,omitempty"`
+}
+
+// PURL returns the package url of the component.
+// See https://github.com/package-url/purl-spec for more information.
+func (c SBOMComponent) PURL() string {
+	name := url.PathEscape(c.Name)
+
+	// Scoped packages keep the slash between the scope and the name,
+	// and the @ sign of the scope is percent-encoded.
+	if strings.HasPrefix(c.Name, "@") {
+		if pieces := strings.SplitN(c.Name, "/", 2); len(pieces) == 2 {
+			name = "%40" + url.PathEscape(pieces[0][1:]) + "/" + url.PathEscape(pieces[1])
+		}
+	}
+
+	return fmt.Sprintf("pkg:npm/%s@%s", name, c.Version)
+}
+
+// SBOMVulnerability is a known vulnerability that affects one of the components.
+type SBOMVulnerability struct {
+	ID       string `json:"id"`
+	Package  string `json:"package"`
+	Version  string `json:"version"`
+	Severity string `json:"severity"`
+	Summary  string `json:"summary,omitempty"`
+	FixedIn  string `json:"fixedIn,omitempty"`
+}
+
+// SBOM is the software bill of materials of a deployment.
+type SBOM struct {
+	// Source is the file the components were resolved from, such as package-lock.json.
+	Source string `json:"source"`
+
+	// GeneratedAt is the unix timestamp of the generation time.
+	GeneratedAt int64 `json:"generatedAt"`
+
+	Components      []SBOMComponent     `json:"components"`
+	Vulnerabilities []SBOMVulnerability `json:"vulnerabilities,omitempty"`
+}
+
+// Scan implements the Scanner interface.
+func (s *SBOM) Scan(value any) error {
+	if value != nil {
+		if b, ok := value.([]byte); ok {
+			return json.Unmarshal(b, s)
+		}
+	}
+
+	return nil
+}
+
+// Value implements the Sql Driver interface.
+func (s *SBOM) Value() (driver.Value, error) {
+	if s == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(s)
+}
+
+// CycloneDX returns the SBOM in CycloneDX 1.5 JSON format.
+func (s *SBOM) CycloneDX(appName, deploymentID string) map[string]any {
+	components := []map[string]any{}
+
+	for _, c := range s.Components {
+		component := map[string]any{
+			"type":    "library",
+			"bom-ref": c.PURL(),
+			"name":    c.Name,
+			"version": c.Version,
+			"purl":    c.PURL(),
+			"scope":   "required",
+		}
+
+		if c.Dev {
+			component["scope"] = "optional"
+		}
+
+		if alg, content, ok := parseIntegrity(c.Integrity); ok {
+			component["hashes"] = []map[string]string{{"alg": alg, "content": content}}
+		}
+
+		components = append(components, component)
+	}
+
+	bom := map[string]any{
+		"bomFormat":    "CycloneDX",
+		"specVersion":  "1.5",
+		"serialNumber": fmt.Sprintf("urn:uuid:%s", sbomUUID(appName, deploymentID)),
+		"version":      1,
+		"metadata": map[string]any{
+			"timestamp": time.Unix(s.GeneratedAt, 0).UTC().Format(time.RFC3339),
+			"tools":     []map[string]string{{"vendor": "Stormkit", "name": "stormkit-runner"}},
+			"component": map[string]any{
+				"type":    "application",
+				"name":    appName,
+				"version": deploymentID,
+			},
+		},
+		"components": components,
+	}
+
+	if len(s.Vulnerabilities) > 0 {
+		vulnerabilities := []map[string]any{}
+
+		for _, v := range s.Vulnerabilities {
+			vulnerabilities = append(vulnerabilities, map[string]any{
+				"id":          v.ID,
+				"description": v.Summary,
+				"ratings":     []map[string]string{{"severity": cycloneDXSeverity(v.Severity)}},
+				"affects":     []map[string]string{{"ref": SBOMComponent{Name: v.Package, Version: v.Version}.PURL()}},
+			})
+		}
+
+		bom["vulnerabilities"] = vulnerabilities
+	}
+
+	return bom
+}
+
+// SPDX returns the SBOM in SPDX 2.3 JSON format.
+func (s *SBOM) SPDX(appName, deploymentID string) map[string]any {
+	packages := []map[string]any{}
+	relationships := []map[string]string{}
+
+	for i, c := range s.Components {
+		id := fmt.Sprintf("SPDXRef-Package-%d", i+1)
+
+		pkg := map[string]any{
+			"SPDXID":           id,
+			"name":             c.Name,
+			"versionInfo":      c.Version,
+			"downloadLocation": "NOASSERTION",
+			"licenseConcluded": "NOASSERTION",
+			"licenseDeclared":  "NOASSERTION",
+			"copyrightText":    "NOASSERTION",
+			"externalRefs": []map[string]string{
+				{
+					"referenceCategory": "PACKAGE-MANAGER",
+					"referenceType":     "purl",
+					"referenceLocator":  c.PURL(),
+				},
+			},
+		}
+
+		if alg, content, ok := parseIntegrity(c.Integrity); ok {
+			pkg["checksums"] = []map[string]string{
+				{"algorithm": strings.ReplaceAll(alg, "-", ""), "checksumValue": content},
+			}
+		}
+
+		packages = append(packages, pkg)
+		relationships = append(relationships, map[string]string{
+			"spdxElementId":      "SPDXRef-DOCUMENT",
+			"relationshipType":   "DESCRIBES",
+			"relatedSpdxElement": id,
+		})
+	}
+
+	return map[string]any{
+		"spdxVersion":       "SPDX-2.3",
+		"dataLicense":       "CC0-1.0",
+		"SPDXID":            "SPDXRef-DOCUMENT",
+		"name":              fmt.Sprintf("%s-%s", appName, deploymentID),
+		"documentNamespace": fmt.Sprintf("https://stormkit.io/spdx/%s", sbomUUID(appName, deploymentID)),
+		"creationInfo": map[string]any{
+			"created":  time.Unix(s.GeneratedAt, 0).UTC().Format(time.RFC3339),
+			"creators": []string{"Tool: stormkit-runner"},
+		},
+		"packages":      packages,
+		"relationships": relationships,
+	}
+}
+
+// parseIntegrity parses subresource integrity strings such as
+// sha512-<base64>. The content is returned as hex, as both formats require.
+func parseIntegrity(integrity string) (string, string, bool) {
+	pieces := strings.SplitN(integrity, "-", 2)
+
+	if len(pieces) != 2 {
+		return "", "", false
+	}
+
+	content, err := decodeBase64Hex(pieces[1])
+
+	if err != nil {
+		return "", "", false
+	}
+
+	switch pieces[0] {
+	case "sha1":
+		return "SHA-1", content, true
+	case "sha256":
+		return "SHA-256", content, true
+	case "sha512":
+		return "SHA-512", content, true
+	}
+
+	return "", "", false
+}
+
+func cycloneDXSeverity(severity string) string {
+	switch strings.ToLower(severity) {
+	case SeverityModerate, "medium":
+		return "medium"
+	case SeverityLow, SeverityHigh, SeverityCritical:
+		return strings.ToLower(severity)
+	}
+
+	return SeverityUnknown
+}
+
+// sbomUUID returns a deterministic uuid for the given deployment, so that
+// downloading the same SBOM twice yields the same serial number.
+func sbomUUID(appName, deploymentID string) string {
+	sum := sha1.Sum([]byte(appName + "/" + deploymentID))
+	sum[6] = (sum[6] & 0x0f) | 0x50 // Version 5
+	sum[8] = (sum[8] & 0x3f) | 0x80 // RFC 4122 variant
+	h := hex.EncodeToString(sum[:16])
+	return fmt.Sprintf("%s-%s-%s-%s-%s", h[0:8], h[8:12], h[12:16], h[16:20], h[20:32])
+}
+
+func decodeBase64Hex(value string) (string, error) {
+	data, err := base64.StdEncoding.DecodeString(value)
+
+	if err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(data), nil
+}
@@ -0,0 +1,66 @@
+package deploy_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stretchr/testify/suite"
+)
+
+type SBOMSuite struct {
+	suite.Suite
+}
+
+func (s *SBOMSuite) sbom() *deploy.SBOM {
+	return &deploy.SBOM{
+		Source:      "package-lock.json",
+		GeneratedAt: 1700000000,
+		Components: []deploy.SBOMComponent{
+			{Name: "@babel/core", Version: "7.23.0", Dev: true},
+			{Name: "lodash", Version: "4.17.20", Integrity: "sha1-AAEC"},
+		},
+		Vulnerabilities: []deploy.SBOMVulnerability{
+			{ID: "GHSA-35jh-r3h4-6jhm", Package: "lodash", Version: "4.17.20", Severity: "moderate", Summary: "Command Injection in lodash"},
+		},
+	}
+}
+
+func (s *SBOMSuite) Test_PURL() {
+	s.Equal("pkg:npm/lodash@4.17.20", deploy.SBOMComponent{Name: "lodash", Version: "4.17.20"}.PURL())
+	s.Equal("pkg:npm/%40babel/core@7.23.0", deploy.SBOMComponent{Name: "@babel/core", Version: "7.23.0"}.PURL())
+}
+
+func (s *SBOMSuite) Test_CycloneDX() {
+	bom := s.sbom().CycloneDX("my-app", "15")
+	components := bom["components"].([]map[string]any)
+
+	s.Equal("CycloneDX", bom["bomFormat"])
+	s.Equal(bom["serialNumber"], s.sbom().CycloneDX("my-app", "15")["serialNumber"])
+	s.Equal("2023-11-14T22:13:20Z", bom["metadata"].(map[string]any)["timestamp"])
+	s.Equal("optional", components[0]["scope"])
+	s.Equal([]map[string]string{{"alg": "SHA-1", "content": "000102"}}, components[1]["hashes"])
+	s.Equal([]map[string]any{
+		{
+			"id":          "GHSA-35jh-r3h4-6jhm",
+			"description": "Command Injection in lodash",
+			"ratings":     []map[string]string{{"severity": "medium"}},
+			"affects":     []map[string]string{{"ref": "pkg:npm/lodash@4.17.20"}},
+		},
+	}, bom["vulnerabilities"])
+}
+
+func (s *SBOMSuite) Test_SPDX() {
+	doc := s.sbom().SPDX("my-app", "15")
+	packages := doc["packages"].([]map[string]any)
+
+	s.Equal("SPDX-2.3", doc["spdxVersion"])
+	s.Equal("my-app-15", doc["name"])
+	s.Len(packages, 2)
+	s.Equal("SPDXRef-Package-2", packages[1]["SPDXID"])
+	s.Equal([]map[string]string{{"algorithm": "SHA1", "checksumValue": "000102"}}, packages[1]["checksums"])
+	s.Len(doc["relationships"], 2)
+}
+
+func TestSBOMSuite(t *testing.T) {
+	suite.Run(t, &SBOMSuite{})
+}
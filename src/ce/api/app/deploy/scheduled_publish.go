@@ -0,0 +1,70 @@
+package deploy
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+const (
+	ScheduledPublishStatusPending           = "pending"
+	ScheduledPublishStatusPublished         = "published"
+	ScheduledPublishStatusFailed            = "failed"
+	ScheduledPublishStatusCancelled         = "cancelled"
+	ScheduledPublishStatusApprovalRequested = "approval-requested"
+)
+
+// ScheduledPublish represents a publish that is executed at a given time.
+type ScheduledPublish struct {
+	ID         types.ID        `json:"id,string"`
+	AppID      types.ID        `json:"appId,string"`
+	EnvID      types.ID        `json:"envId,string"`
+	Config     []PublishConfig `json:"config"`
+	PublishAt  utils.Unix      `json:"publishAt"`
+	Status     string          `json:"status"`
+	Reason     null.String     `json:"reason"`
+	CreatedBy  types.ID        `json:"-"`
+	ExecutedAt utils.Unix      `json:"executedAt"`
+	CreatedAt  utils.Unix      `json:"createdAt"`
+}
+
+// ExecuteScheduledPublish publishes the deployments of the scheduled publish and
+// records the outcome. When the environment is in a freeze window, the scheduled
+// publish fails and the reason is recorded. The environment is checked at the time
+// of the publish: when it is protected, an approval is requested instead.
+func ExecuteScheduledPublish(ctx context.Context, sp *ScheduledPublish) error {
+	store := NewStore()
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, sp.EnvID)
+
+	if err != nil {
+		return err
+	}
+
+	if env != nil && env.Data.IsProtected() {
+		if _, err := RequestApproval(ctx, env, sp.Config, sp.CreatedBy); err != nil {
+			sp.Status = ScheduledPublishStatusFailed
+			sp.Reason = null.StringFrom(err.Error())
+		} else {
+			sp.Status = ScheduledPublishStatusApprovalRequested
+			sp.Reason = null.StringFrom("The environment is protected, the publish is waiting for approval")
+		}
+
+		return store.UpdateScheduledPublish(ctx, sp)
+	}
+
+	if err := Publish(ctx, publishSettingsFromConfig(sp.EnvID, sp.Config)); err != nil {
+		sp.Status = ScheduledPublishStatusFailed
+		sp.Reason = null.StringFrom(err.Error())
+	} else {
+		sp.Status = ScheduledPublishStatusPublished
+
+		if err := store.CancelRollouts(ctx, sp.EnvID, "Published by a scheduled publish"); err != nil {
+			return err
+		}
+	}
+
+	return store.UpdateScheduledPublish(ctx, sp)
+}
@@ -0,0 +1,64 @@
+package deploy
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+)
+
+const (
+	StatusCheckPassed  = "passed"
+	StatusCheckFailed  = "failed"
+	StatusCheckTimeout = "timeout"
+	StatusCheckSkipped = "skipped"
+)
+
+// StatusCheckResult represents the outcome of a single status check.
+type StatusCheckResult struct {
+	Name     string `json:"name"`
+	Cmd      string `json:"cmd"`
+	Status   string `json:"status"`             // passed | failed | timeout | skipped
+	Required bool   `json:"required"`           // Whether a failure fails the deployment
+	Duration int64  `json:"duration"`           // in milliseconds
+	Tests    int    `json:"tests,omitempty"`    // Number of tests parsed from the report
+	Failures int    `json:"failures,omitempty"` // Number of failed tests parsed from the report
+	Excerpt  string `json:"excerpt,omitempty"`  // Failure excerpt, either from the report or the output
+}
+
+// IsSuccess returns true when the check passed.
+func (r StatusCheckResult) IsSuccess() bool {
+	return r.Status == StatusCheckPassed
+}
+
+// StatusCheckResults is a list of status check results.
+type StatusCheckResults []StatusCheckResult
+
+// Passed returns true when none of the required checks failed.
+func (r StatusCheckResults) Passed() bool {
+	for _, result := range r {
+		if result.Required && !result.IsSuccess() {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Scan implements the Scanner interface.
+func (r *StatusCheckResults) Scan(value any) error {
+	if value != nil {
+		if b, ok := value.([]byte); ok {
+			return json.Unmarshal(b, r)
+		}
+	}
+
+	return nil
+}
+
+// Value implements the Sql Driver interface.
+func (r StatusCheckResults) Value() (driver.Value, error) {
+	if len(r) == 0 {
+		return nil, nil
+	}
+
+	return json.Marshal(r)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"fmt"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
@@ -72,6 +74,14 @@ func (dd *DefaultDeployer) Deploy(ctx context.Context, a *app.App, d *deploy.Dep
 		true,
 	)
 
+	// Secrets are loaded before the deployment is inserted, so that a deployment
+	// is not started without the secrets that cannot be decrypted.
+	secrets, err := envsecret.NewStore().Values(ctx, d.EnvID, envsecret.ScopeBuild)
+
+	if err != nil {
+		return err
+	}
+
 	if !d.IsRestart {
 		store := deploy.NewStore()
 
@@ -91,6 +101,7 @@ func (dd *DefaultDeployer) Deploy(ctx context.Context, a *app.App, d *deploy.Dep
 		Build: BuildConfig{
 			Env:           d.Env,
 			Branch:        d.Branch,
+			Commit:        d.PinnedCommit,
 			ShouldPublish: d.ShouldPublish,
 			BuildCmd:      d.BuildConfig.BuildCmd,
 			ServerCmd:     d.BuildConfig.ServerCmd,
@@ -104,6 +115,8 @@ func (dd *DefaultDeployer) Deploy(ctx context.Context, a *app.App, d *deploy.Dep
 			RedirectsFile: d.BuildConfig.RedirectsFile,
 			APIFolder:     utils.GetString(d.BuildConfig.APIFolder, "/api"),
 			StatusChecks:  d.BuildConfig.StatusChecks,
+			AgentLabels:   d.BuildConfig.AgentLabels,
+			Secrets:       envsecret.Names(secrets),
 			Vars: d.BuildConfig.InterpolatedVars(
 				buildconf.InterpolatedVarsOpts{
 					DeploymentID: d.ID.String(),
@@ -111,6 +124,7 @@ func (dd *DefaultDeployer) Deploy(ctx context.Context, a *app.App, d *deploy.Dep
 					EnvID:        d.EnvID.String(),
 					Env:          d.Env,
 					DisplayName:  a.DisplayName,
+					Secrets:      secrets,
 				},
 			),
 		},
@@ -118,10 +132,10 @@ func (dd *DefaultDeployer) Deploy(ctx context.Context, a *app.App, d *deploy.Dep
 		Config: config.Get().Runner,
 	}
 
-	return dd.sendPayloadToRedis(ctx, payload)
+	return enqueue(ctx, a, d, payload)
 }
 
-func (dd *DefaultDeployer) sendPayloadToRedis(ctx context.Context, message DeploymentMessage) error {
+func sendPayloadToRedis(ctx context.Context, message DeploymentMessage) error {
 	encrypted, err := message.Encrypt()
 
 	if err != nil {
@@ -136,6 +150,8 @@ func (dd *DefaultDeployer) sendPayloadToRedis(ctx context.Context, message Deplo
 
 	if err != nil {
 		slog.Errorf("could not enqueue task: %v", err)
+	} else {
+		triggerDeployStartWebhooks(ctx, message)
 	}
 
 	if info != nil {
@@ -151,3 +167,20 @@ func (dd *DefaultDeployer) sendPayloadToRedis(ctx context.Context, message Deplo
 
 	return err
 }
+
+// triggerDeployStartWebhooks dispatches the outbound webhooks that are
+// subscribed to the deployment start event.
+func triggerDeployStartWebhooks(ctx context.Context, message DeploymentMessage) {
+	appID := utils.StringToID(message.Build.AppID)
+	deploymentID := utils.StringToID(message.Build.DeploymentID)
+	cnf := admin.MustConfig()
+
+	app.TriggerEvent(ctx, app.TriggerOnDeployStart, app.OutboundWebhookSettings{
+		AppID:                  appID,
+		DeploymentID:           deploymentID,
+		DeploymentStatus:       "running",
+		EnvironmentName:        message.Build.Env,
+		Branch:                 message.Build.Branch,
+		DeploymentLogsEndpoint: cnf.DeploymentLogsURL(appID, deploymentID),
+	})
+}
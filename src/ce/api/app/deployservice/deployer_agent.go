@@ -0,0 +1,36 @@
+package deployservice
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+)
+
+type agentService struct{}
+
+// Agent creates a new client that hands deployments to the remote build agents.
+func Agent() DeployerService {
+	return &agentService{}
+}
+
+// SendPayload adds the deployment to the agent queue. The deployment
+// is built by the first agent that has all of the required labels.
+func (as *agentService) SendPayload(payload SendPayloadArgs) error {
+	msg, err := FromEncrypted(payload.EncryptedMsg)
+
+	if err != nil {
+		return err
+	}
+
+	return buildagent.NewStore().InsertJob(context.Background(), &buildagent.Job{
+		DeploymentID:   payload.DeploymentID,
+		RequiredLabels: msg.Build.AgentLabels,
+		Payload:        payload.EncryptedMsg,
+	})
+}
+
+// StopDeployment is a no-op. Agents learn about stopped deployments
+// through their heartbeats.
+func (as *agentService) StopDeployment(runID int64) error {
+	return nil
+}
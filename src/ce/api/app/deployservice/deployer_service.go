@@ -48,6 +48,10 @@ type BuildConfig struct {
 	// The branch to deploy.
 	Branch string `json:"branch"`
 
+	// Commit is the commit that a deploy hook pinned. When empty, or when the commit cannot
+	// be fetched, the latest commit of the branch is deployed.
+	Commit string `json:"commit,omitempty"`
+
 	// ShouldPublish specifies whether the deployment should be published to external storages
 	// when they are enabled. If they are not enabled this has no effect.
 	ShouldPublish bool `json:"shouldPublish"`
@@ -67,6 +71,10 @@ type BuildConfig struct {
 	// Vars is the environment variables that will be passed to the node builders.
 	Vars map[string]string `json:"vars"`
 
+	// Secrets is the names of the variables whose values come from secrets.
+	// Their values are always masked in the logs.
+	Secrets []string `json:"secrets,omitempty"`
+
 	// The obfuscated id of the current deployment.
 	DeploymentID string `json:"deploymentId"`
 
@@ -93,6 +101,9 @@ type BuildConfig struct {
 
 	// List of status check commands to execute after the deployment is complete.
 	StatusChecks []buildconf.StatusCheck `json:"statusChecks"`
+
+	// AgentLabels are the labels that a build agent needs to have to build this deployment.
+	AgentLabels []string `json:"agentLabels,omitempty"`
 }
 
 // DeploymentMessage represents a deployment payload.
@@ -169,8 +180,10 @@ func Service() DeployerService {
 	}
 
 	switch config.Get().Deployer.Service {
-	case "local":
+	case config.DeployerServiceLocal:
 		return Local()
+	case config.DeployerServiceAgent:
+		return Agent()
 	default:
 		return Github()
 	}
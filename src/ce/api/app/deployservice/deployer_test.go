@@ -8,6 +8,7 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
@@ -151,6 +152,35 @@ func (s *DeploySuite) Test_Deployment_NoMoreBuildMinutes() {
 	s.Equal(deployservice.ErrBuildMinutesExceeded, err)
 }
 
+func (s *DeploySuite) Test_Deployment_CannotDecryptSecrets() {
+	ctx := context.Background()
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+
+	_, err := envsecret.NewStore().Set(ctx, envsecret.SetArgs{EnvID: env.ID, Name: "NPM_TOKEN", Value: "npm-token", Scope: envsecret.ScopeBuild})
+	s.NoError(err)
+
+	_, err = s.conn.Exec("UPDATE skitapi.env_secret_versions SET encrypted_key = 'not-a-key'")
+	s.NoError(err)
+
+	deployer := &deployservice.DefaultDeployer{}
+	err = deployer.Deploy(ctx, app.App, &deploy.Deployment{
+		AppID:       app.ID,
+		EnvID:       env.ID,
+		Env:         env.Name,
+		Branch:      "main",
+		BuildConfig: &buildconf.BuildConf{},
+	})
+
+	s.ErrorContains(err, "cannot decrypt secret NPM_TOKEN")
+
+	// The deployment is not started without the secret.
+	var count int
+	s.NoError(s.conn.QueryRow("SELECT COUNT(*) FROM skitapi.deployments WHERE env_id = $1", env.ID).Scan(&count))
+	s.Equal(0, count)
+}
+
 func TestAppDeploy(t *testing.T) {
 	suite.Run(t, &DeploySuite{})
 }
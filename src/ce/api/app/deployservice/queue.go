@@ -0,0 +1,192 @@
+package deployservice
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/bsm/redislock"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// maxDispatchBatch is the number of queued deployments that are inspected
+// in a single dispatch.
+const maxDispatchBatch = 100
+
+// dispatchLockKey is the Redis key of the lock that makes sure that only one
+// process dispatches the build queue at a time.
+const dispatchLockKey = "build-queue:dispatch"
+
+// dispatchLockTTL is the time after which the lock is released, in case the
+// process holding it dies before releasing it. The lock is refreshed before
+// each deployment is dispatched.
+const dispatchLockTTL = time.Minute
+
+// enqueue adds the deployment to the build queue, cancels the queued deployments
+// that it supersedes and dispatches the queue right away.
+func enqueue(ctx context.Context, a *app.App, d *deploy.Deployment, message DeploymentMessage) error {
+	encrypted, err := message.Encrypt()
+
+	if err != nil {
+		return err
+	}
+
+	store := deploy.NewStore()
+	queued := &deploy.QueuedDeployment{
+		DeploymentID: d.ID,
+		AppID:        d.AppID,
+		EnvID:        d.EnvID,
+		TeamID:       a.TeamID,
+		Branch:       d.Branch,
+		Priority:     deploy.QueuePriority(d),
+		Payload:      encrypted,
+	}
+
+	if err := store.EnqueueDeployment(ctx, queued); err != nil {
+		return err
+	}
+
+	cancelled, err := store.CancelSupersededDeployments(ctx, queued)
+
+	if err != nil {
+		return err
+	}
+
+	if len(cancelled) > 0 {
+		slog.Infof("deployment %s superseded %d queued deployment(s)", d.ID.String(), len(cancelled))
+	}
+
+	// The deployment stays in the queue when the dispatch fails,
+	// so it will be picked up by the next dispatch.
+	if err := DispatchQueue(ctx); err != nil {
+		slog.Errorf("error while dispatching the build queue: %v", err)
+	}
+
+	return nil
+}
+
+// DispatchQueue sends the queued deployments to the deployer service, highest
+// priority first, as long as the concurrency limits of the instance and of the
+// teams allow it. The queue is dispatched by one process at a time; when the
+// lock cannot be obtained, the queue is left to the next dispatch, which is
+// scheduled every few seconds.
+func DispatchQueue(ctx context.Context) error {
+	lock, err := redislock.New(rediscache.Client()).Obtain(ctx, dispatchLockKey, dispatchLockTTL, nil)
+
+	if errors.Is(err, redislock.ErrNotObtained) {
+		slog.Infof("the build queue is being dispatched by another process, skipping")
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	defer lock.Release(context.Background())
+
+	store := deploy.NewStore()
+
+	if err := store.CancelStoppedQueuedDeployments(ctx); err != nil {
+		return err
+	}
+
+	limits := admin.MustConfig().BuildQueue()
+	running, err := store.RunningDeploymentsByTeam(ctx)
+
+	if err != nil {
+		return err
+	}
+
+	total := 0
+
+	for _, count := range running {
+		total = total + count
+	}
+
+	if total >= limits.MaxConcurrency {
+		return nil
+	}
+
+	queued, err := store.QueuedDeployments(ctx, maxDispatchBatch)
+
+	if err != nil {
+		return err
+	}
+
+	for _, q := range queued {
+		if total >= limits.MaxConcurrency {
+			break
+		}
+
+		if limits.MaxConcurrencyPerTeam > 0 && running[q.TeamID] >= limits.MaxConcurrencyPerTeam {
+			continue
+		}
+
+		// Dispatching a deployment refreshes the git credentials, which takes a
+		// network call. Stop when the lock expired in the meantime, so that two
+		// processes never exceed the concurrency limits together.
+		if err := lock.Refresh(ctx, dispatchLockTTL, nil); err != nil {
+			if errors.Is(err, redislock.ErrNotObtained) {
+				slog.Infof("the build queue lock expired while dispatching, skipping")
+				return nil
+			}
+
+			return err
+		}
+
+		// Another worker may have dispatched the deployment in the meantime.
+		dispatched, err := store.MarkQueuedDeploymentDispatched(ctx, q.DeploymentID)
+
+		if err != nil {
+			return err
+		}
+
+		if !dispatched {
+			continue
+		}
+
+		running[q.TeamID] = running[q.TeamID] + 1
+		total = total + 1
+
+		if err := dispatch(ctx, q); err != nil {
+			slog.Errorf("error while dispatching deployment %s: %v", q.DeploymentID.String(), err)
+
+			if err := store.UpdateExitCode(ctx, q.DeploymentID, deploy.ExitCodeFailed); err != nil {
+				slog.Errorf("error while marking deployment %s as failed: %v", q.DeploymentID.String(), err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// dispatch sends the queued deployment to the deployer service. The git
+// credentials are refreshed, since they may have expired while waiting.
+func dispatch(ctx context.Context, q *deploy.QueuedDeployment) error {
+	message, err := FromEncrypted(q.Payload)
+
+	if err != nil {
+		return err
+	}
+
+	a, err := app.NewStore().AppByID(ctx, q.AppID)
+
+	if err != nil {
+		return err
+	}
+
+	if a == nil {
+		return fmt.Errorf("app %s not found", q.AppID.String())
+	}
+
+	if message.Client.AccessToken, err = a.GitCreds(ctx); err != nil {
+		return err
+	}
+
+	return sendPayloadToRedis(ctx, *message)
+}
@@ -0,0 +1,170 @@
+package deployservice_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/bsm/redislock"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type QueueSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *QueueSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+
+	vc := admin.MustConfig()
+	vc.BuildQueueConfig = &admin.BuildQueueConfig{MaxConcurrency: 1}
+	s.NoError(admin.Store().UpsertConfig(context.Background(), vc))
+
+	tasks.Inspector().DeleteQueue(tasks.QueueDeployService, true)
+}
+
+func (s *QueueSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	tasks.Inspector().DeleteQueue(tasks.QueueDeployService, true)
+}
+
+func (s *QueueSuite) isDispatched(d *deploy.Deployment) bool {
+	_, err := tasks.Inspector().GetTaskInfo(tasks.QueueDeployService, fmt.Sprintf("deployment-%s", d.ID.String()))
+	return err == nil
+}
+
+func (s *QueueSuite) deployment(id *deploy.Deployment) *deploy.Deployment {
+	d, err := deploy.NewStore().MyDeployment(context.Background(), &deploy.DeploymentsQueryFilters{DeploymentID: id.ID})
+	s.NoError(err)
+	s.NotNil(d)
+	return d
+}
+
+func (s *QueueSuite) position(d *deploy.Deployment) int {
+	qi, err := deploy.NewStore().QueueInfo(context.Background(), d.ID, d.AppID)
+	s.NoError(err)
+	s.NotNil(qi)
+	return qi.Position
+}
+
+func (s *QueueSuite) Test_ConcurrencyAndPriority() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	ctx := context.Background()
+
+	first := s.MockDeployment(env).Deployment
+	preview := s.MockDeployment(env, map[string]any{"Branch": "feature", "PullRequestNumber": null.IntFrom(5)}).Deployment
+	production := s.MockDeployment(env, map[string]any{"Branch": "release"}).Deployment
+	production.EnvBranchName = "release"
+
+	for _, d := range []*deploy.Deployment{first, preview, production} {
+		s.NoError(deployservice.New().Deploy(ctx, app.App, d))
+	}
+
+	// Only one deployment can run at a time
+	s.True(s.isDispatched(first))
+	s.False(s.isDispatched(preview))
+	s.False(s.isDispatched(production))
+
+	// Production deployments are ahead of previews
+	s.Equal(deploy.QueueStatusQueued, s.deployment(preview).Queue.Status)
+	s.Equal(2, s.position(preview))
+	s.Equal(1, s.position(production))
+	s.Equal(deploy.QueueStatusDispatched, s.deployment(first).Queue.Status)
+
+	// Once the first deployment is completed, the production deployment is dispatched
+	s.NoError(deploy.NewStore().UpdateExitCode(ctx, first.ID, deploy.ExitCodeSuccess))
+	s.NoError(deployservice.DispatchQueue(ctx))
+	s.True(s.isDispatched(production))
+	s.False(s.isDispatched(preview))
+	s.Equal(1, s.position(preview))
+}
+
+func (s *QueueSuite) Test_SupersededDeployments() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	ctx := context.Background()
+
+	running := s.MockDeployment(env).Deployment
+	older := s.MockDeployment(env).Deployment
+	newer := s.MockDeployment(env).Deployment
+
+	for _, d := range []*deploy.Deployment{running, older, newer} {
+		s.NoError(deployservice.New().Deploy(ctx, app.App, d))
+	}
+
+	s.True(s.isDispatched(running))
+
+	// The running deployment is not affected, the queued one is cancelled
+	s.False(s.deployment(running).ExitCode.Valid)
+	s.Equal(deploy.QueueStatusCancelled, s.deployment(older).Queue.Status)
+	s.Equal(int64(deploy.ExitCodeStopped), s.deployment(older).ExitCode.ValueOrZero())
+	s.Equal(fmt.Sprintf("Superseded by deployment %s.", newer.ID.String()), s.deployment(older).Error.ValueOrZero())
+	s.Equal(deploy.QueueStatusQueued, s.deployment(newer).Queue.Status)
+	s.Equal(1, s.position(newer))
+}
+
+func (s *QueueSuite) Test_TeamConcurrency() {
+	vc := admin.MustConfig()
+	vc.BuildQueueConfig = &admin.BuildQueueConfig{MaxConcurrency: 5, MaxConcurrencyPerTeam: 1}
+	s.NoError(admin.Store().UpsertConfig(context.Background(), vc))
+
+	ctx := context.Background()
+	app1 := s.MockApp(s.MockUser())
+	env1 := s.MockEnv(app1)
+	app2 := s.MockApp(s.MockUser())
+	env2 := s.MockEnv(app2)
+
+	d1 := s.MockDeployment(env1).Deployment
+	d2 := s.MockDeployment(env1, map[string]any{"Branch": "feature"}).Deployment
+	d3 := s.MockDeployment(env2).Deployment
+
+	s.NoError(deployservice.New().Deploy(ctx, app1.App, d1))
+	s.NoError(deployservice.New().Deploy(ctx, app1.App, d2))
+	s.NoError(deployservice.New().Deploy(ctx, app2.App, d3))
+
+	s.True(s.isDispatched(d1))
+	s.False(s.isDispatched(d2))
+	s.True(s.isDispatched(d3))
+}
+
+func (s *QueueSuite) Test_DispatchQueue_LockedByAnotherProcess() {
+	ctx := context.Background()
+	app := s.MockApp(s.MockUser())
+	env := s.MockEnv(app)
+	d := s.MockDeployment(env).Deployment
+
+	lock, err := redislock.New(rediscache.Client()).Obtain(ctx, "build-queue:dispatch", time.Minute, nil)
+	s.NoError(err)
+
+	// Another process is dispatching the queue, so the deployment stays queued
+	timeout, cancel := context.WithTimeout(ctx, time.Second)
+	defer cancel()
+
+	s.NoError(deployservice.New().Deploy(timeout, app.App, d))
+	s.False(s.isDispatched(d))
+	s.Equal(deploy.QueueStatusQueued, s.deployment(d).Queue.Status)
+
+	s.NoError(lock.Release(ctx))
+	s.NoError(deployservice.DispatchQueue(ctx))
+	s.True(s.isDispatched(d))
+}
+
+func TestQueueSuite(t *testing.T) {
+	suite.Run(t, &QueueSuite{})
+}
@@ -0,0 +1,44 @@
+package deploytrigger
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// rateLimitWindow is the window in which the rate limit of a trigger applies.
+const rateLimitWindow = time.Hour
+
+// AllowInvocation counts the invocation towards the rate limit of the trigger and
+// returns false when the limit of the current hour is exceeded. The counter is
+// incremented atomically, so that concurrent requests cannot exceed the limit.
+func AllowInvocation(ctx context.Context, triggerID types.ID, limit int, now time.Time) (bool, error) {
+	window := now.Truncate(rateLimitWindow).Unix()
+	key := fmt.Sprintf("deploy-trigger:%s:rate:%d", triggerID.String(), window)
+
+	var count *redis.IntCmd
+
+	_, err := rediscache.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		count = pipe.Incr(ctx, key)
+		pipe.Expire(ctx, key, rateLimitWindow)
+		return nil
+	})
+
+	if err != nil {
+		return false, err
+	}
+
+	return count.Val() <= int64(limit), nil
+}
+
+// ClaimSignature marks the signature of a request as used. It returns false
+// when the signature was used before, so that signed requests cannot be
+// replayed. Signatures are kept as long as their timestamp is accepted.
+func ClaimSignature(ctx context.Context, triggerID types.ID, signature string) (bool, error) {
+	key := fmt.Sprintf("deploy-trigger:%s:signature:%s", triggerID.String(), signature)
+	return rediscache.Client().SetNX(ctx, key, 1, 2*SignatureTolerance).Result()
+}
@@ -0,0 +1,190 @@
+package deploytrigger
+
+import (
+	"crypto/hmac"
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+// DefaultRateLimit is the number of invocations that a trigger accepts per hour
+// when no rate limit is specified.
+const DefaultRateLimit = 60
+
+// MaxRateLimit is the highest rate limit that can be configured for a trigger.
+const MaxRateLimit = 3600
+
+// SignatureTolerance is the maximum age of a signed request.
+const SignatureTolerance = 5 * time.Minute
+
+const (
+	HeaderSignature = shttp.HeaderSignature
+	HeaderTimestamp = shttp.HeaderTimestamp
+)
+
+var (
+	ErrMissingSignature = errors.New("The request is not signed.")
+	ErrInvalidSignature = errors.New("The request signature does not match.")
+	ErrExpiredSignature = errors.New("The request timestamp is too old.")
+	ErrReplayedRequest  = errors.New("The request was already received.")
+)
+
+// Options are the default parameters of a deployment that is started by the trigger.
+type Options struct {
+	// Branch is the branch to deploy. Defaults to the environment branch.
+	Branch string `json:"branch,omitempty"`
+
+	// Publish specifies whether the deployment is published when it succeeds.
+	// Defaults to the auto publish setting of the environment.
+	Publish null.Bool `json:"publish"`
+
+	// Vars are merged into the environment variables of the environment.
+	Vars map[string]string `json:"vars,omitempty"`
+}
+
+func (o Options) Value() (driver.Value, error) {
+	return json.Marshal(o)
+}
+
+func (o *Options) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, o)
+}
+
+// Trigger is a named url that starts a deployment on an environment.
+type Trigger struct {
+	ID        types.ID   `json:"id,string"`
+	EnvID     types.ID   `json:"envId,string"`
+	Name      string     `json:"name"`
+	Token     string     `json:"-"`
+	Secret    string     `json:"-"` // Secret is the decrypted signing secret.
+	Options   Options    `json:"options"`
+	RateLimit int        `json:"rateLimit"`
+	CreatedAt utils.Unix `json:"createdAt"`
+	UpdatedAt utils.Unix `json:"updatedAt"`
+}
+
+// IsSigned returns true when requests to the trigger need to be signed.
+func (t *Trigger) IsSigned() bool {
+	return t.Secret != ""
+}
+
+// ToMap returns the trigger as a map that is returned by the API.
+func (t *Trigger) ToMap(url string) map[string]any {
+	return map[string]any{
+		"id":        t.ID.String(),
+		"envId":     t.EnvID.String(),
+		"name":      t.Name,
+		"url":       url,
+		"signed":    t.IsSigned(),
+		"options":   t.Options,
+		"rateLimit": t.RateLimit,
+		"createdAt": t.CreatedAt,
+		"updatedAt": t.UpdatedAt,
+	}
+}
+
+// Params are the parameters that are provided when the trigger is invoked.
+// They override the options of the trigger.
+type Params struct {
+	Branch  string            `json:"branch,omitempty"`
+	Commit  string            `json:"commit,omitempty"`
+	Publish null.Bool         `json:"publish"`
+	Vars    map[string]string `json:"vars,omitempty"`
+}
+
+func (p Params) Value() (driver.Value, error) {
+	return json.Marshal(p)
+}
+
+func (p *Params) Scan(value any) error {
+	if value == nil {
+		return nil
+	}
+
+	b, ok := value.([]byte)
+
+	if !ok {
+		return errors.New("type assertion to []byte failed")
+	}
+
+	return json.Unmarshal(b, p)
+}
+
+// Masked returns a copy of the parameters without the values of the
+// environment variables, which may contain secrets.
+func (p Params) Masked() Params {
+	if len(p.Vars) == 0 {
+		return p
+	}
+
+	vars := map[string]string{}
+
+	for key := range p.Vars {
+		vars[key] = "********"
+	}
+
+	p.Vars = vars
+	return p
+}
+
+// Invocation is a record of a call to the trigger.
+type Invocation struct {
+	ID           types.ID    `json:"id,string"`
+	TriggerID    types.ID    `json:"triggerId,string"`
+	DeploymentID types.ID    `json:"deploymentId,string,omitempty"`
+	Status       int         `json:"status"`
+	Error        null.String `json:"error"`
+	Params       Params      `json:"params"`
+	CreatedAt    utils.Unix  `json:"createdAt"`
+}
+
+// GenerateToken returns a new trigger token.
+func GenerateToken() string {
+	return strings.ToLower(utils.RandomToken(48))
+}
+
+// Sign returns the signature of the body for the given timestamp.
+func Sign(secret string, timestamp int64, body []byte) string {
+	return utils.SignPayload(secret, timestamp, body)
+}
+
+// Verify checks the signature and the timestamp of a signed request.
+func Verify(secret, signature, timestamp string, body []byte, now time.Time) error {
+	if signature == "" || timestamp == "" {
+		return ErrMissingSignature
+	}
+
+	ts, err := strconv.ParseInt(timestamp, 10, 64)
+
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	if age := now.Sub(time.Unix(ts, 0)); age > SignatureTolerance || age < -SignatureTolerance {
+		return ErrExpiredSignature
+	}
+
+	if !hmac.Equal([]byte(Sign(secret, ts, body)), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
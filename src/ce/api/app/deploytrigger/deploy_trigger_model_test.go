@@ -0,0 +1,32 @@
+package deploytrigger_test
+
+import (
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerify(t *testing.T) {
+	now := time.Now()
+	body := []byte(`{"branch":"main"}`)
+	timestamp := strconv.FormatInt(now.Unix(), 10)
+	signature := deploytrigger.Sign("secret", now.Unix(), body)
+
+	assert.NoError(t, deploytrigger.Verify("secret", signature, timestamp, body, now))
+	assert.ErrorIs(t, deploytrigger.Verify("secret", "", timestamp, body, now), deploytrigger.ErrMissingSignature)
+	assert.ErrorIs(t, deploytrigger.Verify("another-secret", signature, timestamp, body, now), deploytrigger.ErrInvalidSignature)
+	assert.ErrorIs(t, deploytrigger.Verify("secret", signature, timestamp, []byte(`{"branch":"dev"}`), now), deploytrigger.ErrInvalidSignature)
+	assert.ErrorIs(t, deploytrigger.Verify("secret", signature, timestamp, body, now.Add(10*time.Minute)), deploytrigger.ErrExpiredSignature)
+}
+
+func TestParams_Masked(t *testing.T) {
+	params := deploytrigger.Params{Branch: "main", Vars: map[string]string{"TOKEN": "abc"}}
+	masked := params.Masked()
+
+	assert.Equal(t, "main", masked.Branch)
+	assert.Equal(t, "********", masked.Vars["TOKEN"])
+	assert.Equal(t, "abc", params.Vars["TOKEN"])
+}
@@ -0,0 +1,279 @@
+package deploytrigger
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/database"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+var triggerColumns = `
+	trigger_id, env_id, trigger_name, trigger_token, signing_secret,
+	trigger_options, rate_limit, created_at, updated_at
+`
+
+var stmts = struct {
+	selectTriggers       string
+	selectTriggerByID    string
+	selectTriggerByToken string
+	insertTrigger        string
+	updateTrigger        string
+	deleteTrigger        string
+	insertInvocation     string
+	selectInvocations    string
+	removeOldInvocations string
+}{
+	selectTriggers: fmt.Sprintf(`
+		SELECT %s FROM deploy_triggers
+		WHERE env_id = $1
+		ORDER BY trigger_id ASC
+		LIMIT 100;
+	`, triggerColumns),
+
+	selectTriggerByID: fmt.Sprintf(`
+		SELECT %s FROM deploy_triggers WHERE trigger_id = $1;
+	`, triggerColumns),
+
+	selectTriggerByToken: fmt.Sprintf(`
+		SELECT %s FROM deploy_triggers WHERE trigger_token = $1;
+	`, triggerColumns),
+
+	insertTrigger: `
+		INSERT INTO deploy_triggers
+			(env_id, trigger_name, trigger_token, signing_secret, trigger_options, rate_limit)
+		VALUES
+			($1, $2, $3, $4, $5, $6)
+		RETURNING
+			trigger_id, created_at;
+	`,
+
+	updateTrigger: `
+		UPDATE deploy_triggers SET
+			trigger_name = $2,
+			signing_secret = $3,
+			trigger_options = $4,
+			rate_limit = $5,
+			updated_at = NOW() AT TIME ZONE 'UTC'
+		WHERE trigger_id = $1;
+	`,
+
+	deleteTrigger: `
+		DELETE FROM deploy_triggers WHERE trigger_id = $1;
+	`,
+
+	insertInvocation: `
+		INSERT INTO deploy_trigger_invocations
+			(trigger_id, deployment_id, status_code, invocation_error, invocation_params)
+		VALUES
+			($1, $2, $3, $4, $5)
+		RETURNING
+			invocation_id, created_at;
+	`,
+
+	selectInvocations: `
+		SELECT
+			invocation_id, trigger_id, COALESCE(deployment_id, 0),
+			status_code, invocation_error, invocation_params, created_at
+		FROM deploy_trigger_invocations
+		WHERE trigger_id = $1
+		ORDER BY invocation_id DESC
+		LIMIT 50;
+	`,
+
+	removeOldInvocations: `
+		DELETE FROM deploy_trigger_invocations
+		WHERE created_at < NOW() AT TIME ZONE 'UTC' - INTERVAL '30 days';
+	`,
+}
+
+// Store is the store to handle deploy triggers and their invocations.
+type Store struct {
+	*database.Store
+}
+
+// NewStore returns a store instance.
+func NewStore() *Store {
+	return &Store{database.NewStore()}
+}
+
+func (s *Store) scanTrigger(scanner interface{ Scan(...any) error }) (*Trigger, error) {
+	t := &Trigger{}
+
+	var secret sql.NullString
+
+	err := scanner.Scan(
+		&t.ID, &t.EnvID, &t.Name, &t.Token, &secret,
+		&t.Options, &t.RateLimit, &t.CreatedAt, &t.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if secret.Valid && secret.String != "" {
+		t.Secret = utils.DecryptToString(secret.String)
+	}
+
+	return t, nil
+}
+
+// encryptSecret returns the value that is stored in the signing_secret column.
+func encryptSecret(secret string) (any, error) {
+	if secret == "" {
+		return nil, nil
+	}
+
+	encrypted, err := utils.Encrypt([]byte(secret))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return utils.EncodeToString(encrypted), nil
+}
+
+// List returns the deploy triggers of the environment.
+func (s *Store) List(ctx context.Context, envID types.ID) ([]*Trigger, error) {
+	rows, err := s.Query(ctx, stmts.selectTriggers, envID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	triggers := []*Trigger{}
+
+	for rows.Next() {
+		t, err := s.scanTrigger(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		triggers = append(triggers, t)
+	}
+
+	return triggers, rows.Err()
+}
+
+// ByID returns the deploy trigger with the given id.
+func (s *Store) ByID(ctx context.Context, id types.ID) (*Trigger, error) {
+	return s.selectTrigger(ctx, stmts.selectTriggerByID, id)
+}
+
+// ByToken returns the deploy trigger with the given token.
+func (s *Store) ByToken(ctx context.Context, token string) (*Trigger, error) {
+	return s.selectTrigger(ctx, stmts.selectTriggerByToken, token)
+}
+
+func (s *Store) selectTrigger(ctx context.Context, query string, args ...any) (*Trigger, error) {
+	row, err := s.QueryRow(ctx, query, args...)
+
+	if err != nil {
+		return nil, err
+	}
+
+	t, err := s.scanTrigger(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return t, err
+}
+
+// Insert inserts a new deploy trigger. The signing secret is encrypted before it is stored.
+func (s *Store) Insert(ctx context.Context, t *Trigger) error {
+	secret, err := encryptSecret(t.Secret)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(ctx, stmts.insertTrigger, t.EnvID, t.Name, t.Token, secret, t.Options, t.RateLimit)
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&t.ID, &t.CreatedAt)
+}
+
+// Update updates the name, the signing secret, the options and the rate limit of the trigger.
+func (s *Store) Update(ctx context.Context, t *Trigger) error {
+	secret, err := encryptSecret(t.Secret)
+
+	if err != nil {
+		return err
+	}
+
+	_, err = s.Exec(ctx, stmts.updateTrigger, t.ID, t.Name, secret, t.Options, t.RateLimit)
+	return err
+}
+
+// Delete removes the deploy trigger and its invocations.
+func (s *Store) Delete(ctx context.Context, id types.ID) error {
+	_, err := s.Exec(ctx, stmts.deleteTrigger, id)
+	return err
+}
+
+// InsertInvocation records a call to the trigger. The values of the
+// environment variables are not stored.
+func (s *Store) InsertInvocation(ctx context.Context, inv *Invocation) error {
+	var deploymentID any
+
+	if inv.DeploymentID != 0 {
+		deploymentID = inv.DeploymentID
+	}
+
+	row, err := s.QueryRow(ctx, stmts.insertInvocation, inv.TriggerID, deploymentID, inv.Status, inv.Error, inv.Params.Masked())
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&inv.ID, &inv.CreatedAt)
+}
+
+// Invocations returns the last 50 invocations of the trigger.
+func (s *Store) Invocations(ctx context.Context, triggerID types.ID) ([]*Invocation, error) {
+	rows, err := s.Query(ctx, stmts.selectInvocations, triggerID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	invocations := []*Invocation{}
+
+	for rows.Next() {
+		inv := &Invocation{}
+
+		err := rows.Scan(
+			&inv.ID, &inv.TriggerID, &inv.DeploymentID,
+			&inv.Status, &inv.Error, &inv.Params, &inv.CreatedAt,
+		)
+
+		if err != nil {
+			slog.Errorf("error while scanning deploy trigger invocation: %s", err.Error())
+			continue
+		}
+
+		invocations = append(invocations, inv)
+	}
+
+	return invocations, rows.Err()
+}
+
+// RemoveOldInvocations removes the invocations that are older than 30 days.
+func (s *Store) RemoveOldInvocations(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmts.removeOldInvocations)
+	return err
+}
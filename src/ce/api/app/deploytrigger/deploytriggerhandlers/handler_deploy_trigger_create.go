@@ -0,0 +1,160 @@
+package deploytriggerhandlers
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+var varNameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+type DeployTriggerRequest struct {
+	ID           types.ID              `json:"id,string"`
+	EnvID        types.ID              `json:"envId,string"`
+	Name         string                `json:"name"`
+	Options      deploytrigger.Options `json:"options"`
+	RateLimit    int                   `json:"rateLimit"`
+	Signed       bool                  `json:"signed"`
+	RotateSecret bool                  `json:"rotateSecret"`
+}
+
+func validate(data *DeployTriggerRequest) map[string]string {
+	errors := map[string]string{}
+
+	data.Name = strings.TrimSpace(data.Name)
+	data.Options.Branch = strings.TrimSpace(data.Options.Branch)
+
+	if data.Name == "" || len(data.Name) > 64 {
+		errors["name"] = "Name is required and can be at most 64 characters long."
+	}
+
+	if data.Options.Branch != "" && !branchRegex.MatchString(data.Options.Branch) {
+		errors["branch"] = "Invalid branch name."
+	}
+
+	if data.RateLimit == 0 {
+		data.RateLimit = deploytrigger.DefaultRateLimit
+	}
+
+	if data.RateLimit < 0 || data.RateLimit > deploytrigger.MaxRateLimit {
+		errors["rateLimit"] = fmt.Sprintf("Rate limit has to be between 1 and %d.", deploytrigger.MaxRateLimit)
+	}
+
+	if err := validateVars(data.Options.Vars); err != "" {
+		errors["vars"] = err
+	}
+
+	if len(errors) == 0 {
+		return nil
+	}
+
+	return errors
+}
+
+func validateVars(vars map[string]string) string {
+	for key := range vars {
+		if !varNameRegex.MatchString(key) {
+			return fmt.Sprintf("Invalid environment variable name: %s", key)
+		}
+	}
+
+	return ""
+}
+
+// environment returns the environment when it belongs to the app.
+func environment(ctx context.Context, a *app.App, envID types.ID) (*buildconf.Env, error) {
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, envID)
+
+	if err != nil || env == nil || env.AppID != a.ID {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// triggerByID returns the trigger when it belongs to the environment of the request.
+func triggerByID(req *app.RequestContext, id types.ID) (*deploytrigger.Trigger, error) {
+	env, err := environment(req.Context(), req.App, req.EnvID)
+
+	if err != nil || env == nil || id == 0 {
+		return nil, err
+	}
+
+	trigger, err := deploytrigger.NewStore().ByID(req.Context(), id)
+
+	if err != nil || trigger == nil || trigger.EnvID != env.ID {
+		return nil, err
+	}
+
+	return trigger, nil
+}
+
+// triggerURL returns the url that invokes the trigger.
+func triggerURL(t *deploytrigger.Trigger) string {
+	return admin.MustConfig().ApiURL(fmt.Sprintf("/hooks/deploy/%s", t.Token))
+}
+
+func handlerDeployTriggerCreate(req *app.RequestContext) *shttp.Response {
+	data := &DeployTriggerRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if errs := validate(data); errs != nil {
+		return &shttp.Response{
+			Status: http.StatusBadRequest,
+			Data:   errs,
+		}
+	}
+
+	env, err := environment(req.Context(), req.App, req.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil {
+		return shttp.NotFound()
+	}
+
+	trigger := &deploytrigger.Trigger{
+		EnvID:     env.ID,
+		Name:      data.Name,
+		Token:     deploytrigger.GenerateToken(),
+		Options:   data.Options,
+		RateLimit: data.RateLimit,
+	}
+
+	if data.Signed {
+		trigger.Secret = utils.GenerateSigningSecret()
+	}
+
+	if err := deploytrigger.NewStore().Insert(req.Context(), trigger); err != nil {
+		return shttp.Error(err)
+	}
+
+	out := trigger.ToMap(triggerURL(trigger))
+
+	// The secret is only returned when it is generated.
+	if trigger.IsSigned() {
+		out["secret"] = trigger.Secret
+	}
+
+	return &shttp.Response{
+		Status: http.StatusCreated,
+		Data: map[string]any{
+			"trigger": out,
+		},
+	}
+}
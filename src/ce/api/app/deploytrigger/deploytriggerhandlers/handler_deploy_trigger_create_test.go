@@ -0,0 +1,130 @@
+package deploytriggerhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger/deploytriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerDeployTriggerCreateSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerDeployTriggerCreateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerDeployTriggerCreateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerDeployTriggerCreateSuite) Test_Success() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/deploy-trigger",
+		map[string]any{
+			"appId":  env.AppID.String(),
+			"envId":  env.ID.String(),
+			"name":   "CMS",
+			"signed": true,
+			"options": map[string]any{
+				"branch":  "content",
+				"publish": true,
+				"vars":    map[string]string{"CONTENT_VERSION": "draft"},
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusCreated, response.Code)
+
+	data := map[string]map[string]any{}
+	s.NoError(json.Unmarshal([]byte(response.String()), &data))
+
+	triggers, err := deploytrigger.NewStore().List(context.Background(), env.ID)
+	s.NoError(err)
+	s.Len(triggers, 1)
+
+	trigger := triggers[0]
+	s.Equal("CMS", trigger.Name)
+	s.Equal("content", trigger.Options.Branch)
+	s.True(trigger.Options.Publish.ValueOrZero())
+	s.Equal(map[string]string{"CONTENT_VERSION": "draft"}, trigger.Options.Vars)
+	s.Equal(deploytrigger.DefaultRateLimit, trigger.RateLimit)
+	s.Equal(trigger.Secret, data["trigger"]["secret"])
+	s.Contains(data["trigger"]["url"], "/hooks/deploy/"+trigger.Token)
+	s.True(data["trigger"]["signed"].(bool))
+}
+
+func (s *HandlerDeployTriggerCreateSuite) Test_FailValidation() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/deploy-trigger",
+		map[string]any{
+			"appId":     env.AppID.String(),
+			"envId":     env.ID.String(),
+			"name":      "",
+			"rateLimit": 100000,
+			"options": map[string]any{
+				"vars": map[string]string{"INVALID-NAME": "value"},
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{
+		"name": "Name is required and can be at most 64 characters long.",
+		"rateLimit": "Rate limit has to be between 1 and 3600.",
+		"vars": "Invalid environment variable name: INVALID-NAME"
+	}`, response.String())
+}
+
+func (s *HandlerDeployTriggerCreateSuite) Test_EnvOfAnotherApp() {
+	env := s.MockEnv(nil)
+	other := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/deploy-trigger",
+		map[string]any{
+			"appId": env.AppID.String(),
+			"envId": other.ID.String(),
+			"name":  "CMS",
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func TestHandlerDeployTriggerCreate(t *testing.T) {
+	suite.Run(t, &HandlerDeployTriggerCreateSuite{})
+}
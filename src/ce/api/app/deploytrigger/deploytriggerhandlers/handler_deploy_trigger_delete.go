@@ -0,0 +1,28 @@
+package deploytriggerhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+func handlerDeployTriggerDelete(req *app.RequestContext) *shttp.Response {
+	trigger, err := triggerByID(req, utils.StringToID(req.Query().Get("triggerId")))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if trigger == nil {
+		return shttp.NotFound()
+	}
+
+	store := deploytrigger.NewStore()
+
+	if err := store.Delete(req.Context(), trigger.ID); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
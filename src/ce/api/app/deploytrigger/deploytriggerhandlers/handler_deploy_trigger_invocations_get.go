@@ -0,0 +1,37 @@
+package deploytriggerhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+func handlerDeployTriggerInvocationsGet(req *app.RequestContext) *shttp.Response {
+	trigger, err := triggerByID(req, utils.StringToID(req.Query().Get("triggerId")))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if trigger == nil {
+		return shttp.NotFound()
+	}
+
+	store := deploytrigger.NewStore()
+
+	invocations, err := store.Invocations(req.Context(), trigger.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"invocations": invocations,
+		},
+	}
+}
@@ -0,0 +1,257 @@
+package deploytriggerhandlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"gopkg.in/guregu/null.v3"
+)
+
+// maxBodySize is the maximum size of the request body that is read.
+const maxBodySize = 64 * 1024
+
+var (
+	commitRegex = regexp.MustCompile(`^[0-9a-fA-F]{7,40}$`)
+	branchRegex = regexp.MustCompile(`^[a-zA-Z0-9._/][a-zA-Z0-9._/-]*$`)
+)
+
+// invocationError is an error that is returned to the caller with the given status.
+type invocationError struct {
+	status int
+	err    error
+}
+
+func (e *invocationError) Error() string {
+	return e.err.Error()
+}
+
+func newInvocationError(status int, msg string) *invocationError {
+	return &invocationError{status: status, err: errors.New(msg)}
+}
+
+// handlerDeployTriggerInvoke starts a deployment on the environment of the trigger.
+// Parameters are read from the JSON body of POST requests, or from the query string
+// of unsigned triggers. Signed triggers only accept parameters from the body, since
+// the query string is not part of the signature.
+func handlerDeployTriggerInvoke(req *shttp.RequestContext) *shttp.Response {
+	store := deploytrigger.NewStore()
+	trigger, err := store.ByToken(req.Context(), req.Vars()["token"])
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if trigger == nil {
+		return shttp.NotFound()
+	}
+
+	inv := &deploytrigger.Invocation{TriggerID: trigger.ID, Status: http.StatusOK}
+	depl, err := invoke(req, trigger, inv)
+
+	if err != nil {
+		inv.Status = http.StatusInternalServerError
+		inv.Error = null.StringFrom(err.Error())
+
+		if ierr, ok := err.(*invocationError); ok {
+			inv.Status = ierr.status
+		}
+	}
+
+	if depl != nil {
+		inv.DeploymentID = depl.ID
+	}
+
+	if err := store.InsertInvocation(req.Context(), inv); err != nil {
+		slog.Errorf("error while inserting deploy trigger invocation: %v", err)
+	}
+
+	if inv.Status != http.StatusOK {
+		return &shttp.Response{
+			Status: inv.Status,
+			Data: map[string]string{
+				"error": inv.Error.ValueOrZero(),
+			},
+		}
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"deploymentId": inv.DeploymentID.String(),
+		},
+	}
+}
+
+func invoke(req *shttp.RequestContext, trigger *deploytrigger.Trigger, inv *deploytrigger.Invocation) (*deploy.Deployment, error) {
+	// The rate limit is checked before anything else, so that invalid
+	// requests cannot be sent at an unlimited rate either.
+	allowed, err := deploytrigger.AllowInvocation(req.Context(), trigger.ID, trigger.RateLimit, time.Now())
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !allowed {
+		return nil, newInvocationError(http.StatusTooManyRequests, fmt.Sprintf("This trigger can be invoked at most %d times per hour.", trigger.RateLimit))
+	}
+
+	var body []byte
+
+	if req.Request.Body != nil {
+		if body, err = io.ReadAll(io.LimitReader(req.Request.Body, maxBodySize+1)); err != nil {
+			return nil, err
+		}
+
+		if len(body) > maxBodySize {
+			return nil, newInvocationError(http.StatusRequestEntityTooLarge, "The request body is too large.")
+		}
+	}
+
+	if trigger.IsSigned() {
+		headers := req.Headers()
+		signature := headers.Get(deploytrigger.HeaderSignature)
+		timestamp := headers.Get(deploytrigger.HeaderTimestamp)
+
+		if err := deploytrigger.Verify(trigger.Secret, signature, timestamp, body, time.Now()); err != nil {
+			return nil, &invocationError{status: http.StatusUnauthorized, err: err}
+		}
+
+		// The signature is claimed only once it is verified, so that invalid
+		// requests cannot block the signature of a valid one.
+		claimed, err := deploytrigger.ClaimSignature(req.Context(), trigger.ID, signature)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !claimed {
+			return nil, &invocationError{status: http.StatusUnauthorized, err: deploytrigger.ErrReplayedRequest}
+		}
+	}
+
+	if req.Method == shttp.MethodPost && len(body) > 0 {
+		if err := json.Unmarshal(body, &inv.Params); err != nil {
+			return nil, newInvocationError(http.StatusBadRequest, "The request body is not valid JSON.")
+		}
+	} else if !trigger.IsSigned() {
+		inv.Params = paramsFromQuery(req)
+	}
+
+	if err := validateParams(inv.Params); err != nil {
+		return nil, err
+	}
+
+	env, err := buildconf.NewStore().EnvironmentByID(req.Context(), trigger.EnvID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if env == nil {
+		return nil, newInvocationError(http.StatusNotFound, "The environment of this trigger does not exist.")
+	}
+
+	a, err := app.NewStore().AppByID(req.Context(), env.AppID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if a == nil {
+		return nil, newInvocationError(http.StatusNotFound, "The app of this trigger does not exist.")
+	}
+
+	depl := deploy.New(a.ID)
+	depl.Env = env.Env
+	depl.EnvBranchName = env.Branch
+	depl.EnvID = env.ID
+	depl.CheckoutRepo = a.Repo
+	depl.IsAutoDeploy = true
+	depl.Branch = utils.GetString(inv.Params.Branch, trigger.Options.Branch, env.Branch)
+	depl.Commit.ID = null.NewString(inv.Params.Commit, inv.Params.Commit != "")
+	depl.PinnedCommit = inv.Params.Commit
+	depl.ShouldPublish = env.AutoPublish
+
+	if inv.Params.Publish.Valid {
+		depl.ShouldPublish = inv.Params.Publish.ValueOrZero()
+	} else if trigger.Options.Publish.Valid {
+		depl.ShouldPublish = trigger.Options.Publish.ValueOrZero()
+	}
+
+	depl.BuildConfig = env.Data
+
+	if env.Data != nil && (len(trigger.Options.Vars) > 0 || len(inv.Params.Vars) > 0) {
+		cnf := *env.Data
+		cnf.Vars = map[string]string{}
+
+		for _, vars := range []map[string]string{env.Data.Vars, trigger.Options.Vars, inv.Params.Vars} {
+			for key, val := range vars {
+				cnf.Vars[key] = val
+			}
+		}
+
+		depl.BuildConfig = &cnf
+	}
+
+	if err := deployservice.New().Deploy(req.Context(), a, depl); err != nil {
+		return nil, err
+	}
+
+	return depl, nil
+}
+
+// paramsFromQuery returns the parameters that are provided in the query string.
+// Environment variables are provided with the `var.` prefix, e.g. ?var.API_URL=...
+func paramsFromQuery(req *shttp.RequestContext) deploytrigger.Params {
+	query := req.Query()
+	params := deploytrigger.Params{
+		Branch: query.Get("branch"),
+		Commit: query.Get("commit"),
+	}
+
+	if publish := query.Get("publish"); publish == "true" || publish == "false" {
+		params.Publish = null.BoolFrom(publish == "true")
+	}
+
+	for key := range query {
+		if name, ok := strings.CutPrefix(key, "var."); ok {
+			if params.Vars == nil {
+				params.Vars = map[string]string{}
+			}
+
+			params.Vars[name] = query.Get(key)
+		}
+	}
+
+	return params
+}
+
+func validateParams(params deploytrigger.Params) error {
+	if params.Branch != "" && !branchRegex.MatchString(params.Branch) {
+		return newInvocationError(http.StatusBadRequest, "Invalid branch name.")
+	}
+
+	if params.Commit != "" && !commitRegex.MatchString(params.Commit) {
+		return newInvocationError(http.StatusBadRequest, "Invalid commit sha.")
+	}
+
+	if err := validateVars(params.Vars); err != "" {
+		return newInvocationError(http.StatusBadRequest, err)
+	}
+
+	return nil
+}
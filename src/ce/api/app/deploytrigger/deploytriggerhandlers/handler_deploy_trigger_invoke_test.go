@@ -0,0 +1,283 @@
+package deploytriggerhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger/deploytriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type HandlerDeployTriggerInvokeSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn         databasetest.TestDB
+	env          *factory.MockEnv
+	triggers     []*deploytrigger.Trigger
+	mockDeployer *mocks.Deployer
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.env = s.MockEnv(nil)
+	s.triggers = nil
+	s.mockDeployer = &mocks.Deployer{}
+	s.mockDeployer.On("Deploy", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	deployservice.MockDeployer = s.mockDeployer
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) AfterTest(_, _ string) {
+	ctx := context.Background()
+
+	// Trigger ids are reused when the test database is recreated.
+	for _, trigger := range s.triggers {
+		keys := rediscache.Client().Keys(ctx, fmt.Sprintf("deploy-trigger:%s:*", trigger.ID.String())).Val()
+
+		if len(keys) > 0 {
+			rediscache.Client().Del(ctx, keys...)
+		}
+	}
+
+	s.conn.CloseTx()
+	deployservice.MockDeployer = nil
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) trigger(secret string, rateLimit int) *deploytrigger.Trigger {
+	trigger := &deploytrigger.Trigger{
+		EnvID:     s.env.ID,
+		Name:      "CMS",
+		Token:     deploytrigger.GenerateToken(),
+		Secret:    secret,
+		RateLimit: rateLimit,
+		Options: deploytrigger.Options{
+			Branch:  "content",
+			Publish: null.BoolFrom(true),
+			Vars:    map[string]string{"CONTENT_VERSION": "draft"},
+		},
+	}
+
+	s.NoError(deploytrigger.NewStore().Insert(context.Background(), trigger))
+	s.triggers = append(s.triggers, trigger)
+	return trigger
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) invocations(trigger *deploytrigger.Trigger) []*deploytrigger.Invocation {
+	invocations, err := deploytrigger.NewStore().Invocations(context.Background(), trigger.ID)
+	s.NoError(err)
+	return invocations
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_QueryParams() {
+	trigger := s.trigger("", 10)
+
+	response := shttptest.Request(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/hooks/deploy/%s?branch=release&commit=8a6d1c2e&publish=false&var.API_URL=https://staging", trigger.Token),
+		nil,
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	s.mockDeployer.AssertCalled(s.T(), "Deploy",
+		mock.Anything,
+		mock.MatchedBy(func(a *app.App) bool { return a.ID == s.env.AppID }),
+		mock.MatchedBy(func(d *deploy.Deployment) bool {
+			return s.Equal("release", d.Branch) &&
+				s.Equal(s.env.ID, d.EnvID) &&
+				s.Equal("8a6d1c2e", d.Commit.ID.ValueOrZero()) &&
+				s.Equal("8a6d1c2e", d.PinnedCommit) &&
+				s.False(d.ShouldPublish) &&
+				s.Equal("https://staging", d.BuildConfig.Vars["API_URL"]) &&
+				s.Equal("draft", d.BuildConfig.Vars["CONTENT_VERSION"])
+		}),
+	)
+
+	// The environment configuration is not modified.
+	s.Empty(s.env.Data.Vars["API_URL"])
+
+	invocations := s.invocations(trigger)
+	s.Len(invocations, 1)
+	s.Equal(http.StatusOK, invocations[0].Status)
+	s.Equal("release", invocations[0].Params.Branch)
+	s.Equal("********", invocations[0].Params.Vars["API_URL"])
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_Defaults() {
+	trigger := s.trigger("", 10)
+
+	response := shttptest.Request(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		fmt.Sprintf("/hooks/deploy/%s", trigger.Token),
+		nil,
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	s.mockDeployer.AssertCalled(s.T(), "Deploy",
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(d *deploy.Deployment) bool {
+			return s.Equal("content", d.Branch) &&
+				s.False(d.Commit.ID.Valid) &&
+				s.True(d.ShouldPublish)
+		}),
+	)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_Signed() {
+	trigger := s.trigger(utils.GenerateSigningSecret(), 10)
+	body := map[string]any{"branch": "release", "publish": false}
+	data, err := json.Marshal(body)
+	s.NoError(err)
+
+	now := time.Now().Unix()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		fmt.Sprintf("/hooks/deploy/%s", trigger.Token),
+		body,
+		map[string]string{
+			deploytrigger.HeaderTimestamp: strconv.FormatInt(now, 10),
+			deploytrigger.HeaderSignature: deploytrigger.Sign(trigger.Secret, now, data),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	s.mockDeployer.AssertCalled(s.T(), "Deploy",
+		mock.Anything,
+		mock.Anything,
+		mock.MatchedBy(func(d *deploy.Deployment) bool {
+			return s.Equal("release", d.Branch) && s.False(d.ShouldPublish)
+		}),
+	)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_Signed_Replayed() {
+	trigger := s.trigger(utils.GenerateSigningSecret(), 10)
+	handler := shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler()
+	body := map[string]any{"branch": "release"}
+	data, err := json.Marshal(body)
+	s.NoError(err)
+
+	now := time.Now().Unix()
+	headers := map[string]string{
+		deploytrigger.HeaderTimestamp: strconv.FormatInt(now, 10),
+		deploytrigger.HeaderSignature: deploytrigger.Sign(trigger.Secret, now, data),
+	}
+
+	target := fmt.Sprintf("/hooks/deploy/%s", trigger.Token)
+
+	s.Equal(http.StatusOK, shttptest.RequestWithHeaders(handler, shttp.MethodPost, target, body, headers).Code)
+
+	response := shttptest.RequestWithHeaders(handler, shttp.MethodPost, target, body, headers)
+	s.Equal(http.StatusUnauthorized, response.Code)
+	s.JSONEq(`{ "error": "The request was already received." }`, response.String())
+	s.mockDeployer.AssertNumberOfCalls(s.T(), "Deploy", 1)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_Signed_InvalidSignature() {
+	trigger := s.trigger(utils.GenerateSigningSecret(), 10)
+	now := time.Now().Unix()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		fmt.Sprintf("/hooks/deploy/%s", trigger.Token),
+		map[string]any{"branch": "release"},
+		map[string]string{
+			deploytrigger.HeaderTimestamp: strconv.FormatInt(now, 10),
+			deploytrigger.HeaderSignature: deploytrigger.Sign("another-secret", now, []byte(`{"branch":"release"}`)),
+		},
+	)
+
+	s.Equal(http.StatusUnauthorized, response.Code)
+	s.JSONEq(`{ "error": "The request signature does not match." }`, response.String())
+	s.mockDeployer.AssertNotCalled(s.T(), "Deploy", mock.Anything, mock.Anything, mock.Anything)
+
+	invocations := s.invocations(trigger)
+	s.Len(invocations, 1)
+	s.Equal(http.StatusUnauthorized, invocations[0].Status)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_RateLimit() {
+	trigger := s.trigger("", 1)
+	handler := shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler()
+	target := fmt.Sprintf("/hooks/deploy/%s", trigger.Token)
+
+	s.Equal(http.StatusOK, shttptest.Request(handler, shttp.MethodPost, target, nil).Code)
+
+	response := shttptest.Request(handler, shttp.MethodPost, target, nil)
+	s.Equal(http.StatusTooManyRequests, response.Code)
+	s.JSONEq(`{ "error": "This trigger can be invoked at most 1 times per hour." }`, response.String())
+	s.mockDeployer.AssertNumberOfCalls(s.T(), "Deploy", 1)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_RateLimit_FailedInvocations() {
+	trigger := s.trigger(utils.GenerateSigningSecret(), 2)
+	handler := shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler()
+	target := fmt.Sprintf("/hooks/deploy/%s", trigger.Token)
+
+	// Requests without a signature count towards the limit as well.
+	s.Equal(http.StatusUnauthorized, shttptest.Request(handler, shttp.MethodPost, target, nil).Code)
+	s.Equal(http.StatusUnauthorized, shttptest.Request(handler, shttp.MethodPost, target, nil).Code)
+	s.Equal(http.StatusTooManyRequests, shttptest.Request(handler, shttp.MethodPost, target, nil).Code)
+	s.mockDeployer.AssertNotCalled(s.T(), "Deploy", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_BodyTooLarge() {
+	trigger := s.trigger("", 10)
+
+	response := shttptest.Request(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		fmt.Sprintf("/hooks/deploy/%s", trigger.Token),
+		map[string]any{"branch": strings.Repeat("a", 64*1024)},
+	)
+
+	s.Equal(http.StatusRequestEntityTooLarge, response.Code)
+	s.JSONEq(`{ "error": "The request body is too large." }`, response.String())
+	s.mockDeployer.AssertNotCalled(s.T(), "Deploy", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func (s *HandlerDeployTriggerInvokeSuite) Test_InvalidParams() {
+	trigger := s.trigger("", 10)
+
+	response := shttptest.Request(
+		shttp.NewRouter().RegisterService(deploytriggerhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/hooks/deploy/%s?commit=not-a-sha", trigger.Token),
+		nil,
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{ "error": "Invalid commit sha." }`, response.String())
+}
+
+func TestHandlerDeployTriggerInvoke(t *testing.T) {
+	suite.Run(t, &HandlerDeployTriggerInvokeSuite{})
+}
@@ -0,0 +1,66 @@
+package deploytriggerhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+func handlerDeployTriggerUpdate(req *app.RequestContext) *shttp.Response {
+	data := &DeployTriggerRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if errs := validate(data); errs != nil {
+		return &shttp.Response{
+			Status: http.StatusBadRequest,
+			Data:   errs,
+		}
+	}
+
+	trigger, err := triggerByID(req, data.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if trigger == nil {
+		return shttp.NotFound()
+	}
+
+	trigger.Name = data.Name
+	trigger.Options = data.Options
+	trigger.RateLimit = data.RateLimit
+
+	generated := false
+
+	if !data.Signed {
+		trigger.Secret = ""
+	} else if !trigger.IsSigned() || data.RotateSecret {
+		trigger.Secret = utils.GenerateSigningSecret()
+		generated = true
+	}
+
+	if err := deploytrigger.NewStore().Update(req.Context(), trigger); err != nil {
+		return shttp.Error(err)
+	}
+
+	out := trigger.ToMap(triggerURL(trigger))
+
+	// The secret is only returned when it is generated.
+	if generated {
+		out["secret"] = trigger.Secret
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"trigger": out,
+		},
+	}
+}
@@ -0,0 +1,40 @@
+package deploytriggerhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+func handlerDeployTriggersGet(req *app.RequestContext) *shttp.Response {
+	env, err := environment(req.Context(), req.App, req.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if env == nil {
+		return shttp.NotFound()
+	}
+
+	triggers, err := deploytrigger.NewStore().List(req.Context(), env.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	data := []map[string]any{}
+
+	for _, trigger := range triggers {
+		data = append(data, trigger.ToMap(triggerURL(trigger)))
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"triggers": data,
+		},
+	}
+}
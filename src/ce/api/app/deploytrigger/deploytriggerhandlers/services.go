@@ -0,0 +1,24 @@
+package deploytriggerhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// Services sets the handlers for this service.
+func Services(r *shttp.Router) *shttp.Service {
+	s := r.NewService()
+
+	s.NewEndpoint("/apps").
+		Handler(shttp.MethodGet, "/deploy-triggers", app.WithApp(handlerDeployTriggersGet, &app.Opts{Env: true})).
+		Handler(shttp.MethodPost, "/deploy-trigger", app.WithApp(handlerDeployTriggerCreate, &app.Opts{Env: true})).
+		Handler(shttp.MethodPatch, "/deploy-trigger", app.WithApp(handlerDeployTriggerUpdate, &app.Opts{Env: true})).
+		Handler(shttp.MethodDelete, "/deploy-trigger", app.WithApp(handlerDeployTriggerDelete, &app.Opts{Env: true})).
+		Handler(shttp.MethodGet, "/deploy-trigger/invocations", app.WithApp(handlerDeployTriggerInvocationsGet, &app.Opts{Env: true}))
+
+	s.NewEndpoint("/hooks/deploy").
+		Handler(shttp.MethodGet, "/{token}", shttp.WithRateLimit(handlerDeployTriggerInvoke)).
+		Handler(shttp.MethodPost, "/{token}", shttp.WithRateLimit(handlerDeployTriggerInvoke))
+
+	return s
+}
@@ -0,0 +1,34 @@
+package deploytriggerhandlers_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger/deploytriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServicesSuite struct {
+	suite.Suite
+}
+
+func (s *ServicesSuite) Test_Services() {
+	services := shttp.NewRouter().RegisterService(deploytriggerhandlers.Services)
+	s.NotNil(services)
+
+	handlers := []string{
+		"DELETE:/apps/deploy-trigger",
+		"GET:/apps/deploy-trigger/invocations",
+		"GET:/apps/deploy-triggers",
+		"GET:/hooks/deploy/{token}",
+		"PATCH:/apps/deploy-trigger",
+		"POST:/apps/deploy-trigger",
+		"POST:/hooks/deploy/{token}",
+	}
+
+	s.Equal(handlers, services.HandlerKeys())
+}
+
+func TestServices(t *testing.T) {
+	suite.Run(t, &ServicesSuite{})
+}
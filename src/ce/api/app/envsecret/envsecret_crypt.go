@@ -0,0 +1,104 @@
+package envsecret
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/config"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// sealed is a value encrypted with its own data key. The data key is stored next
+// to the value, encrypted with the key of the instance (envelope encryption).
+// Rotating the instance key only requires re-encrypting the data keys.
+type sealed struct {
+	Value string `json:"value"`
+	Key   string `json:"key"`
+}
+
+// instanceKey returns the key that encrypts the data keys.
+func instanceKey() []byte {
+	sum := sha256.Sum256([]byte(config.Get().SecretsKey))
+	return sum[:]
+}
+
+// seal encrypts the plaintext with a newly generated data key.
+func seal(plaintext string) (sealed, error) {
+	dataKey := make([]byte, 32)
+
+	if _, err := rand.Read(dataKey); err != nil {
+		return sealed{}, err
+	}
+
+	value, err := utils.Encrypt([]byte(plaintext), dataKey)
+
+	if err != nil {
+		return sealed{}, err
+	}
+
+	key, err := utils.Encrypt(dataKey, instanceKey())
+
+	if err != nil {
+		return sealed{}, err
+	}
+
+	return sealed{
+		Value: utils.EncodeToString(value),
+		Key:   utils.EncodeToString(key),
+	}, nil
+}
+
+// open decrypts the sealed value.
+func open(s sealed) (string, error) {
+	key, err := utils.DecodeString(s.Key)
+
+	if err != nil {
+		return "", err
+	}
+
+	dataKey, err := utils.Decrypt(key, instanceKey())
+
+	if err != nil {
+		return "", err
+	}
+
+	value, err := utils.DecodeString(s.Value)
+
+	if err != nil {
+		return "", err
+	}
+
+	plaintext, err := utils.Decrypt(value, dataKey)
+	return string(plaintext), err
+}
+
+// OpenAll decrypts a JSON object of sealed values keyed by secret name, as
+// returned by the queries that load secrets along with other records. It fails
+// when any of the values cannot be decrypted, rather than leaving it out.
+func OpenAll(data []byte) (map[string]string, error) {
+	values := map[string]string{}
+
+	if len(data) == 0 {
+		return values, nil
+	}
+
+	secrets := map[string]sealed{}
+
+	if err := json.Unmarshal(data, &secrets); err != nil {
+		return nil, fmt.Errorf("cannot unmarshal secrets: %w", err)
+	}
+
+	for name, s := range secrets {
+		value, err := open(s)
+
+		if err != nil {
+			return nil, fmt.Errorf("cannot decrypt secret %s: %w", name, err)
+		}
+
+		values[name] = value
+	}
+
+	return values, nil
+}
@@ -0,0 +1,91 @@
+package envsecret
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// Scopes define when a secret is injected as an environment variable.
+const (
+	ScopeBuild   = "build"
+	ScopeRuntime = "runtime"
+	ScopeBoth    = "both"
+)
+
+// MaxValueSize is the maximum size of a secret value in bytes.
+const MaxValueSize = 64 * 1024
+
+var nameRegex = regexp.MustCompile(`^[a-zA-Z_][a-zA-Z0-9_]*$`)
+
+var (
+	ErrSecretNotFound  = errors.New("Secret does not exist.")
+	ErrVersionNotFound = errors.New("Secret version does not exist.")
+)
+
+// Secret is an environment variable whose value is encrypted at rest. Values are
+// never returned by the API, only the metadata of the secret and its versions.
+type Secret struct {
+	ID        types.ID   `json:"id,string"`
+	EnvID     types.ID   `json:"envId,string"`
+	Name      string     `json:"name"`
+	Scope     string     `json:"scope"`
+	Version   int        `json:"version"`
+	CreatedAt utils.Unix `json:"createdAt"`
+	UpdatedAt utils.Unix `json:"updatedAt"`
+}
+
+// Version is a value that a secret had at some point. Rolling back to a
+// version creates a new version that restores the value of the old one.
+type Version struct {
+	Version      int        `json:"version"`
+	RestoredFrom int        `json:"restoredFrom,omitempty"`
+	CreatedBy    types.ID   `json:"createdBy,string,omitempty"`
+	CreatedAt    utils.Unix `json:"createdAt"`
+}
+
+// SetArgs are the arguments to create a secret, or to add a new version to it.
+type SetArgs struct {
+	EnvID  types.ID
+	Name   string
+	Value  string
+	Scope  string
+	UserID types.ID
+}
+
+// Validate returns the validation errors of the arguments, keyed by field name.
+func (a *SetArgs) Validate() map[string]string {
+	errs := map[string]string{}
+
+	if !nameRegex.MatchString(a.Name) {
+		errs["name"] = "Name must start with a letter or an underscore and contain only letters, numbers and underscores"
+	}
+
+	if a.Value == "" {
+		errs["value"] = "Value is required"
+	} else if len(a.Value) > MaxValueSize {
+		errs["value"] = fmt.Sprintf("Value cannot be larger than %d bytes", MaxValueSize)
+	}
+
+	if !utils.InSliceStringCS([]string{ScopeBuild, ScopeRuntime, ScopeBoth}, a.Scope) {
+		errs["scope"] = "Scope must be one of: build, runtime, both"
+	}
+
+	return errs
+}
+
+// Scopes returns the scopes of the secrets that are injected at the
+// given stage, which is either ScopeBuild or ScopeRuntime.
+func Scopes(stage string) []string {
+	return []string{stage, ScopeBoth}
+}
+
+// Names returns the sorted names of the given secret values.
+func Names(values map[string]string) []string {
+	return slices.Sorted(maps.Keys(values))
+}
@@ -0,0 +1,302 @@
+package envsecret
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/lib/pq"
+	"github.com/stormkit-io/stormkit-io/src/lib/database"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+var stmts = struct {
+	selectSecrets  string
+	selectSecret   string
+	selectVersions string
+	selectValues   string
+	insertSecret   string
+	restoreVersion string
+	deleteSecret   string
+}{
+	selectSecrets: `
+		SELECT
+			secret_id, env_id, secret_name, secret_scope,
+			current_version, created_at, updated_at
+		FROM
+			env_secrets
+		WHERE
+			env_id = $1
+		ORDER BY
+			secret_name ASC;
+	`,
+
+	selectSecret: `
+		SELECT
+			secret_id, env_id, secret_name, secret_scope,
+			current_version, created_at, updated_at
+		FROM
+			env_secrets
+		WHERE
+			env_id = $1 AND
+			secret_name = $2;
+	`,
+
+	selectVersions: `
+		SELECT
+			v.secret_version, COALESCE(v.restored_from, 0),
+			COALESCE(v.created_by, 0), v.created_at
+		FROM
+			env_secret_versions v
+		INNER JOIN
+			env_secrets s ON s.secret_id = v.secret_id
+		WHERE
+			s.env_id = $1 AND
+			s.secret_name = $2
+		ORDER BY
+			v.secret_version DESC;
+	`,
+
+	selectValues: `
+		SELECT
+			json_object_agg(
+				s.secret_name,
+				json_build_object('value', v.encrypted_value, 'key', v.encrypted_key)
+			)
+		FROM
+			env_secrets s
+		INNER JOIN
+			env_secret_versions v ON v.secret_id = s.secret_id AND v.secret_version = s.current_version
+		WHERE
+			s.env_id = $1 AND
+			s.secret_scope = ANY($2);
+	`,
+
+	insertSecret: `
+		WITH secret AS (
+			INSERT INTO env_secrets AS s
+				(env_id, secret_name, secret_scope)
+			VALUES
+				($1, $2, $3)
+			ON CONFLICT (env_id, secret_name) DO UPDATE SET
+				secret_scope = EXCLUDED.secret_scope,
+				current_version = s.current_version + 1,
+				updated_at = timezone('utc', now())
+			RETURNING
+				s.secret_id, s.current_version
+		)
+		INSERT INTO env_secret_versions
+			(secret_id, secret_version, encrypted_value, encrypted_key, created_by)
+		SELECT
+			secret_id, current_version, $4, $5, NULLIF($6, 0)
+		FROM
+			secret;
+	`,
+
+	restoreVersion: `
+		WITH source AS (
+			SELECT
+				v.secret_id, v.secret_version, v.encrypted_value, v.encrypted_key
+			FROM
+				env_secret_versions v
+			INNER JOIN
+				env_secrets s ON s.secret_id = v.secret_id
+			WHERE
+				s.env_id = $1 AND
+				s.secret_name = $2 AND
+				v.secret_version = $3
+		), secret AS (
+			UPDATE
+				env_secrets s
+			SET
+				current_version = s.current_version + 1,
+				updated_at = timezone('utc', now())
+			FROM
+				source
+			WHERE
+				s.secret_id = source.secret_id
+			RETURNING
+				s.secret_id, s.current_version
+		)
+		INSERT INTO env_secret_versions
+			(secret_id, secret_version, encrypted_value, encrypted_key, restored_from, created_by)
+		SELECT
+			secret.secret_id, secret.current_version, source.encrypted_value,
+			source.encrypted_key, source.secret_version, NULLIF($4, 0)
+		FROM
+			secret, source;
+	`,
+
+	deleteSecret: `
+		DELETE FROM
+			env_secrets
+		WHERE
+			env_id = $1 AND
+			secret_name = $2;
+	`,
+}
+
+// Store handles the secrets of the environments.
+type Store struct {
+	*database.Store
+}
+
+// NewStore returns a new store instance.
+func NewStore() *Store {
+	return &Store{
+		Store: database.NewStore(),
+	}
+}
+
+// Secrets returns the secrets of the environment, sorted by name.
+func (s *Store) Secrets(ctx context.Context, envID types.ID) ([]*Secret, error) {
+	rows, err := s.Query(ctx, stmts.selectSecrets, envID)
+
+	if rows == nil || err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	secrets := []*Secret{}
+
+	for rows.Next() {
+		secret, err := scanSecret(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		secrets = append(secrets, secret)
+	}
+
+	return secrets, nil
+}
+
+// SecretByName returns the secret of the environment with the given name.
+func (s *Store) SecretByName(ctx context.Context, envID types.ID, name string) (*Secret, error) {
+	row, err := s.QueryRow(ctx, stmts.selectSecret, envID, name)
+
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := scanSecret(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return secret, err
+}
+
+// Versions returns the versions of the secret, latest first.
+func (s *Store) Versions(ctx context.Context, envID types.ID, name string) ([]*Version, error) {
+	rows, err := s.Query(ctx, stmts.selectVersions, envID, name)
+
+	if rows == nil || err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	versions := []*Version{}
+
+	for rows.Next() {
+		v := &Version{}
+
+		if err := rows.Scan(&v.Version, &v.RestoredFrom, &v.CreatedBy, &v.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		versions = append(versions, v)
+	}
+
+	return versions, nil
+}
+
+// Values returns the decrypted values of the secrets that are injected at the
+// given stage, keyed by secret name. Stage is either ScopeBuild or ScopeRuntime.
+// It fails when any of the values cannot be decrypted.
+func (s *Store) Values(ctx context.Context, envID types.ID, stage string) (map[string]string, error) {
+	row, err := s.QueryRow(ctx, stmts.selectValues, envID, pq.Array(Scopes(stage)))
+
+	if err != nil {
+		return nil, err
+	}
+
+	var data []byte
+
+	if err := row.Scan(&data); err != nil {
+		return nil, err
+	}
+
+	return OpenAll(data)
+}
+
+// Set creates the secret, or adds a new version to it when it already exists.
+func (s *Store) Set(ctx context.Context, args SetArgs) (*Secret, error) {
+	sealed, err := seal(args.Value)
+
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = s.Exec(ctx, stmts.insertSecret, args.EnvID, args.Name, args.Scope, sealed.Value, sealed.Key, args.UserID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return s.SecretByName(ctx, args.EnvID, args.Name)
+}
+
+// Rollback restores the value of the given version as a new version.
+func (s *Store) Rollback(ctx context.Context, envID types.ID, name string, version int, userID types.ID) (*Secret, error) {
+	result, err := s.Exec(ctx, stmts.restoreVersion, envID, name, version, userID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if affected, err := result.RowsAffected(); err != nil || affected == 0 {
+		if err == nil {
+			err = ErrVersionNotFound
+		}
+
+		return nil, err
+	}
+
+	return s.SecretByName(ctx, envID, name)
+}
+
+// Delete removes the secret along with its versions. It returns false
+// when the secret does not exist.
+func (s *Store) Delete(ctx context.Context, envID types.ID, name string) (bool, error) {
+	result, err := s.Exec(ctx, stmts.deleteSecret, envID, name)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanSecret(row scanner) (*Secret, error) {
+	secret := &Secret{}
+	err := row.Scan(
+		&secret.ID, &secret.EnvID, &secret.Name, &secret.Scope,
+		&secret.Version, &secret.CreatedAt, &secret.UpdatedAt,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return secret, nil
+}
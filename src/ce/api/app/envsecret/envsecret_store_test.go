@@ -0,0 +1,109 @@
+package envsecret_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stretchr/testify/suite"
+)
+
+type EnvSecretStoreSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *EnvSecretStoreSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *EnvSecretStoreSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *EnvSecretStoreSuite) Test_SetAndValues() {
+	ctx := context.Background()
+	env := s.MockEnv(nil)
+	store := envsecret.NewStore()
+
+	for _, args := range []envsecret.SetArgs{
+		{EnvID: env.ID, Name: "DB_PASSWORD", Value: "s3cr3t-v1", Scope: envsecret.ScopeRuntime},
+		{EnvID: env.ID, Name: "DB_PASSWORD", Value: "s3cr3t-v2", Scope: envsecret.ScopeBoth},
+		{EnvID: env.ID, Name: "NPM_TOKEN", Value: "npm-token", Scope: envsecret.ScopeBuild},
+	} {
+		_, err := store.Set(ctx, args)
+		s.NoError(err)
+	}
+
+	// Values are encrypted at rest
+	var value, key string
+	row := s.conn.QueryRow("SELECT encrypted_value, encrypted_key FROM skitapi.env_secret_versions LIMIT 1")
+	s.NoError(row.Scan(&value, &key))
+	s.NotContains(value, "s3cr3t")
+	s.NotEmpty(key)
+
+	build, err := store.Values(ctx, env.ID, envsecret.ScopeBuild)
+	s.NoError(err)
+	s.Equal(map[string]string{"DB_PASSWORD": "s3cr3t-v2", "NPM_TOKEN": "npm-token"}, build)
+
+	runtime, err := store.Values(ctx, env.ID, envsecret.ScopeRuntime)
+	s.NoError(err)
+	s.Equal(map[string]string{"DB_PASSWORD": "s3cr3t-v2"}, runtime)
+
+	secret, err := store.SecretByName(ctx, env.ID, "DB_PASSWORD")
+	s.NoError(err)
+	s.Equal(2, secret.Version)
+	s.Equal(envsecret.ScopeBoth, secret.Scope)
+}
+
+func (s *EnvSecretStoreSuite) Test_Values_CannotDecrypt() {
+	ctx := context.Background()
+	env := s.MockEnv(nil)
+	store := envsecret.NewStore()
+
+	_, err := store.Set(ctx, envsecret.SetArgs{EnvID: env.ID, Name: "DB_PASSWORD", Value: "s3cr3t", Scope: envsecret.ScopeBoth})
+	s.NoError(err)
+
+	_, err = s.conn.Exec("UPDATE skitapi.env_secret_versions SET encrypted_key = 'not-a-key'")
+	s.NoError(err)
+
+	values, err := store.Values(ctx, env.ID, envsecret.ScopeRuntime)
+	s.ErrorContains(err, "cannot decrypt secret DB_PASSWORD")
+	s.Nil(values)
+}
+
+func (s *EnvSecretStoreSuite) Test_Rollback() {
+	ctx := context.Background()
+	env := s.MockEnv(nil)
+	store := envsecret.NewStore()
+
+	for _, value := range []string{"value-1", "value-2"} {
+		_, err := store.Set(ctx, envsecret.SetArgs{EnvID: env.ID, Name: "API_TOKEN", Value: value, Scope: envsecret.ScopeBoth})
+		s.NoError(err)
+	}
+
+	secret, err := store.Rollback(ctx, env.ID, "API_TOKEN", 1, 0)
+	s.NoError(err)
+	s.Equal(3, secret.Version)
+
+	values, err := store.Values(ctx, env.ID, envsecret.ScopeRuntime)
+	s.NoError(err)
+	s.Equal("value-1", values["API_TOKEN"])
+
+	versions, err := store.Versions(ctx, env.ID, "API_TOKEN")
+	s.NoError(err)
+	s.Len(versions, 3)
+	s.Equal(3, versions[0].Version)
+	s.Equal(1, versions[0].RestoredFrom)
+
+	_, err = store.Rollback(ctx, env.ID, "API_TOKEN", 10, 0)
+	s.ErrorIs(err, envsecret.ErrVersionNotFound)
+}
+
+func TestEnvSecretStore(t *testing.T) {
+	suite.Run(t, &EnvSecretStoreSuite{})
+}
@@ -0,0 +1,32 @@
+package envsecrethandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/audit"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// HandlerSecretDelete removes the secret with the given `name`, along with its versions.
+func HandlerSecretDelete(req *app.RequestContext) *shttp.Response {
+	store := envsecret.NewStore()
+	secret, err := store.SecretByName(req.Context(), req.EnvID, req.Query().Get("name"))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if secret == nil {
+		return shttp.NotFound()
+	}
+
+	if _, err := store.Delete(req.Context(), req.EnvID, secret.Name); err != nil {
+		return shttp.Error(err)
+	}
+
+	if res := secretChanged(req, audit.DeleteAction, secret); res != nil {
+		return res
+	}
+
+	return shttp.OK()
+}
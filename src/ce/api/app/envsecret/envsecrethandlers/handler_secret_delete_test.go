@@ -0,0 +1,66 @@
+package envsecrethandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerSecretDeleteSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerSecretDeleteSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerSecretDeleteSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerSecretDeleteSuite) Test_Success() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	_, err := envsecret.NewStore().Set(context.Background(), envsecret.SetArgs{
+		EnvID: env.ID,
+		Name:  "API_TOKEN",
+		Value: "my-api-token",
+		Scope: envsecret.ScopeBoth,
+	})
+
+	s.NoError(err)
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+		response := shttptest.RequestWithHeaders(
+			shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+			shttp.MethodDelete,
+			"/v1/secrets?name=API_TOKEN",
+			nil,
+			map[string]string{
+				"Authorization": key.Value,
+			},
+		)
+
+		s.Equal(status, response.Code)
+	}
+
+	secrets, err := envsecret.NewStore().Secrets(context.Background(), env.ID)
+	s.NoError(err)
+	s.Empty(secrets)
+}
+
+func TestHandlerSecretDelete(t *testing.T) {
+	suite.Run(t, &HandlerSecretDeleteSuite{})
+}
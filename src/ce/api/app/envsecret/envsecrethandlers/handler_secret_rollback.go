@@ -0,0 +1,48 @@
+package envsecrethandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/audit"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type SecretRollbackRequest struct {
+	Name    string `json:"name"`
+	Version int    `json:"version"`
+}
+
+// HandlerSecretRollback restores the value of a previous version. The value is
+// restored as a new version, so that the rollback can be reverted as well.
+func HandlerSecretRollback(req *app.RequestContext) *shttp.Response {
+	data := &SecretRollbackRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	secret, err := envsecret.NewStore().Rollback(req.Context(), req.EnvID, data.Name, data.Version, userID(req))
+
+	if errors.Is(err, envsecret.ErrVersionNotFound) {
+		return &shttp.Response{
+			Status: http.StatusNotFound,
+			Data:   map[string]string{"error": err.Error()},
+		}
+	}
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if res := secretChanged(req, audit.UpdateAction, secret); res != nil {
+		return res
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data:   map[string]any{"secret": secret},
+	}
+}
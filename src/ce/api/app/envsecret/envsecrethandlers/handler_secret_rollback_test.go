@@ -0,0 +1,95 @@
+package envsecrethandlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerSecretRollbackSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerSecretRollbackSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerSecretRollbackSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerSecretRollbackSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	store := envsecret.NewStore()
+
+	for _, value := range []string{"old-api-token", "new-api-token"} {
+		_, err := store.Set(context.Background(), envsecret.SetArgs{
+			EnvID:  env.ID,
+			Name:   "API_TOKEN",
+			Value:  value,
+			Scope:  envsecret.ScopeBoth,
+			UserID: usr.ID,
+		})
+
+		s.NoError(err)
+	}
+
+	handler := shttp.NewRouter().RegisterService(envsecrethandlers.Services).Router().Handler()
+	headers := map[string]string{"Authorization": usertest.Authorization(usr.ID)}
+
+	response := shttptest.RequestWithHeaders(handler, shttp.MethodPost, "/apps/secrets/rollback", map[string]any{
+		"appId":   app.ID.String(),
+		"envId":   env.ID.String(),
+		"name":    "API_TOKEN",
+		"version": 1,
+	}, headers)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.Contains(response.String(), `"version":3`)
+
+	values, err := store.Values(context.Background(), env.ID, envsecret.ScopeRuntime)
+	s.NoError(err)
+	s.Equal("old-api-token", values["API_TOKEN"])
+
+	response = shttptest.RequestWithHeaders(
+		handler,
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/secrets/versions?appId=%d&envId=%d&name=API_TOKEN", app.ID, env.ID),
+		nil,
+		headers,
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.NotContains(response.String(), "api-token")
+	s.Contains(response.String(), fmt.Sprintf(`{"version":3,"restoredFrom":1,"createdBy":"%s"`, usr.ID))
+
+	// Unknown versions cannot be restored
+	response = shttptest.RequestWithHeaders(handler, shttp.MethodPost, "/apps/secrets/rollback", map[string]any{
+		"appId":   app.ID.String(),
+		"envId":   env.ID.String(),
+		"name":    "API_TOKEN",
+		"version": 7,
+	}, headers)
+
+	s.Equal(http.StatusNotFound, response.Code)
+	s.JSONEq(`{ "error": "Secret version does not exist." }`, response.String())
+}
+
+func TestHandlerSecretRollback(t *testing.T) {
+	suite.Run(t, &HandlerSecretRollbackSuite{})
+}
@@ -0,0 +1,103 @@
+package envsecrethandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/audit"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type SecretSetRequest struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+
+	// Scope is either build, runtime or both. Defaults to both.
+	Scope string `json:"scope"`
+}
+
+// HandlerSecretSet creates a secret, or adds a new version to an existing one.
+func HandlerSecretSet(req *app.RequestContext) *shttp.Response {
+	data := &SecretSetRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	args := envsecret.SetArgs{
+		EnvID:  req.EnvID,
+		Name:   strings.TrimSpace(data.Name),
+		Value:  data.Value,
+		Scope:  strings.ToLower(utils.GetString(data.Scope, envsecret.ScopeBoth)),
+		UserID: userID(req),
+	}
+
+	if errs := args.Validate(); len(errs) > 0 {
+		return &shttp.Response{
+			Status: http.StatusBadRequest,
+			Data:   map[string]any{"errors": errs},
+		}
+	}
+
+	secret, err := envsecret.NewStore().Set(req.Context(), args)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	action := audit.UpdateAction
+
+	if secret.Version == 1 {
+		action = audit.CreateAction
+	}
+
+	if res := secretChanged(req, action, secret); res != nil {
+		return res
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data:   map[string]any{"secret": secret},
+	}
+}
+
+// secretChanged resets the cached runtime configuration of the environment, so that
+// the new value is used by the next function invocation, and records the change.
+func secretChanged(req *app.RequestContext, action string, secret *envsecret.Secret) *shttp.Response {
+	if err := appcache.Service().Reset(req.EnvID); err != nil {
+		return shttp.Error(err)
+	}
+
+	if req.License().Enterprise {
+		err := audit.FromRequestContext(req).
+			WithAction(action, audit.TypeSecret).
+			WithDiff(&audit.Diff{New: audit.DiffFields{
+				SecretName:    secret.Name,
+				SecretScope:   secret.Scope,
+				SecretVersion: secret.Version,
+			}}).
+			WithEnvID(req.EnvID).
+			Insert()
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return nil
+}
+
+// userID returns the ID of the user who makes the request. Requests
+// authenticated with API keys do not have a user.
+func userID(req *app.RequestContext) types.ID {
+	if req.User != nil {
+		return req.User.ID
+	}
+
+	return 0
+}
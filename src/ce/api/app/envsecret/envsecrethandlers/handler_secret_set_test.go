@@ -0,0 +1,121 @@
+package envsecrethandlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerSecretSetSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerSecretSetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerSecretSetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerSecretSetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+
+	for version, value := range []string{"my-first-password", "my-second-password"} {
+		response := shttptest.RequestWithHeaders(
+			shttp.NewRouter().RegisterService(envsecrethandlers.Services).Router().Handler(),
+			shttp.MethodPut,
+			"/apps/secrets",
+			map[string]any{
+				"appId": app.ID.String(),
+				"envId": env.ID.String(),
+				"name":  "DB_PASSWORD",
+				"value": value,
+			},
+			map[string]string{
+				"Authorization": usertest.Authorization(usr.ID),
+			},
+		)
+
+		s.Equal(http.StatusOK, response.Code)
+		s.NotContains(response.String(), value)
+		s.Contains(response.String(), fmt.Sprintf(`"version":%d`, version+1))
+		s.Contains(response.String(), `"scope":"both"`)
+	}
+
+	values, err := envsecret.NewStore().Values(context.Background(), env.ID, envsecret.ScopeBuild)
+	s.NoError(err)
+	s.Equal(map[string]string{"DB_PASSWORD": "my-second-password"}, values)
+}
+
+func (s *HandlerSecretSetSuite) Test_APIKey() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/v1/secrets",
+		map[string]any{
+			"name":  "NPM_TOKEN",
+			"value": "npm_abcdefghijkl",
+			"scope": "build",
+		},
+		map[string]string{
+			"Authorization": key.Value,
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	values, err := envsecret.NewStore().Values(context.Background(), env.ID, envsecret.ScopeRuntime)
+	s.NoError(err)
+	s.Empty(values)
+}
+
+func (s *HandlerSecretSetSuite) Test_InvalidRequest() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/v1/secrets",
+		map[string]any{
+			"name":  "1-INVALID",
+			"scope": "deploy",
+		},
+		map[string]string{
+			"Authorization": key.Value,
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{
+		"errors": {
+			"name": "Name must start with a letter or an underscore and contain only letters, numbers and underscores",
+			"value": "Value is required",
+			"scope": "Scope must be one of: build, runtime, both"
+		}
+	}`, response.String())
+}
+
+func TestHandlerSecretSet(t *testing.T) {
+	suite.Run(t, &HandlerSecretSetSuite{})
+}
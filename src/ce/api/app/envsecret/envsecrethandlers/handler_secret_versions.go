@@ -0,0 +1,30 @@
+package envsecrethandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// HandlerSecretVersionsGet returns the versions of the secret with the given `name`,
+// latest first. Secret values are write-only and never returned.
+func HandlerSecretVersionsGet(req *app.RequestContext) *shttp.Response {
+	versions, err := envsecret.NewStore().Versions(req.Context(), req.EnvID, req.Query().Get("name"))
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if len(versions) == 0 {
+		return shttp.NotFound()
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"versions": versions,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package envsecrethandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// HandlerSecretsGet returns the secrets of the environment. Secret values
+// are write-only and never returned.
+func HandlerSecretsGet(req *app.RequestContext) *shttp.Response {
+	secrets, err := envsecret.NewStore().Secrets(req.Context(), req.EnvID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"secrets": secrets,
+		},
+	}
+}
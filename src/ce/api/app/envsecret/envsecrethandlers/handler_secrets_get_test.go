@@ -0,0 +1,76 @@
+package envsecrethandlers_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerSecretsGetSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerSecretsGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerSecretsGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerSecretsGetSuite) Test_Success() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+
+	secret, err := envsecret.NewStore().Set(context.Background(), envsecret.SetArgs{
+		EnvID: env.ID,
+		Name:  "STRIPE_KEY",
+		Value: "sk_live_1234567890",
+		Scope: envsecret.ScopeRuntime,
+	})
+
+	s.NoError(err)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(envsecrethandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/secrets?appId=%d&envId=%d", app.ID, env.ID),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.JSONEq(fmt.Sprintf(`{
+		"secrets": [
+			{
+				"id": "%s",
+				"envId": "%s",
+				"name": "STRIPE_KEY",
+				"scope": "runtime",
+				"version": 1,
+				"createdAt": %d,
+				"updatedAt": null
+			}
+		]
+	}`, secret.ID, env.ID, secret.CreatedAt.Unix()), response.String())
+}
+
+func TestHandlerSecretsGet(t *testing.T) {
+	suite.Run(t, &HandlerSecretsGetSuite{})
+}
@@ -0,0 +1,20 @@
+package envsecrethandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// Services sets the handlers for this service.
+func Services(r *shttp.Router) *shttp.Service {
+	s := r.NewService()
+
+	s.NewEndpoint("/apps/secrets").
+		Handler(shttp.MethodGet, "", app.WithApp(HandlerSecretsGet, &app.Opts{Env: true})).
+		Handler(shttp.MethodPut, "", app.WithApp(HandlerSecretSet, &app.Opts{Env: true})).
+		Handler(shttp.MethodDelete, "", app.WithApp(HandlerSecretDelete, &app.Opts{Env: true})).
+		Handler(shttp.MethodGet, "/versions", app.WithApp(HandlerSecretVersionsGet, &app.Opts{Env: true})).
+		Handler(shttp.MethodPost, "/rollback", app.WithApp(HandlerSecretRollback, &app.Opts{Env: true}))
+
+	return s
+}
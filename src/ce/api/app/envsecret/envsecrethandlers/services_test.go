@@ -0,0 +1,32 @@
+package envsecrethandlers_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServicesSuite struct {
+	suite.Suite
+}
+
+func (s *ServicesSuite) Test_Services() {
+	services := shttp.NewRouter().RegisterService(envsecrethandlers.Services)
+	s.NotNil(services)
+
+	handlers := []string{
+		"DELETE:/apps/secrets",
+		"GET:/apps/secrets",
+		"GET:/apps/secrets/versions",
+		"POST:/apps/secrets/rollback",
+		"PUT:/apps/secrets",
+	}
+
+	s.Equal(handlers, services.HandlerKeys())
+}
+
+func TestServices(t *testing.T) {
+	suite.Run(t, &ServicesSuite{})
+}
@@ -1,20 +1,131 @@
 package functiontrigger
 
 import (
+	"crypto/sha256"
 	"database/sql/driver"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
 
+	"github.com/adhocore/gronx"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
+// Concurrency policies decide what happens when a trigger is due while
+// its previous run is still in progress.
+const (
+	ConcurrencyAllow   = "allow"   // Runs overlap.
+	ConcurrencyForbid  = "forbid"  // The new run is skipped.
+	ConcurrencyReplace = "replace" // The previous run is cancelled.
+)
+
+var ConcurrencyPolicies = []string{ConcurrencyAllow, ConcurrencyForbid, ConcurrencyReplace}
+
+// SourceFile marks the triggers that are declared in the repository config file.
+// These are replaced on each deployment of the environment branch, while the
+// triggers created through the API are left untouched.
+const SourceFile = "file"
+
+// DefaultTimeout is the number of seconds to wait for a response when
+// the trigger has no timeout.
+const DefaultTimeout = 10
+
+// MaxTimeout is the maximum number of seconds a trigger can wait for a response.
+const MaxTimeout = 300
+
+// MaxRetries is the maximum number of times a failed run can be retried.
+const MaxRetries = 10
+
 type Options struct {
 	Method  string        `json:"method"`
 	URL     string        `json:"url"`
 	Payload []byte        `json:"payload,omitempty"`
 	Headers shttp.Headers `json:"headers,omitempty"`
+
+	// Path is the path of a function or API route of the environment's
+	// published deployment, such as /api/cron. When provided, the function is
+	// invoked internally instead of sending a request to the URL.
+	Path string `json:"path,omitempty"`
+
+	// Timeout is the number of seconds to wait for a response.
+	// Defaults to DefaultTimeout.
+	Timeout int `json:"timeout,omitempty"`
+
+	// MaxRetries is the number of times a failed run is retried,
+	// with an exponential backoff. Defaults to 0.
+	MaxRetries int `json:"maxRetries,omitempty"`
+
+	// ConcurrencyPolicy is one of the Concurrency* constants.
+	// Defaults to ConcurrencyAllow.
+	ConcurrencyPolicy string `json:"concurrencyPolicy,omitempty"`
+}
+
+// InvokesFunction returns true when the trigger invokes a published
+// function of the environment, rather than requesting a public URL.
+func (a Options) InvokesFunction() bool {
+	return a.Path != ""
+}
+
+// ValidateTarget validates the url, or the path when the trigger invokes
+// a function. The returned map is keyed by the invalid field.
+func (a Options) ValidateTarget() map[string]string {
+	errs := map[string]string{}
+
+	if a.InvokesFunction() {
+		if a.URL != "" {
+			errs["path"] = "Path and URL cannot be used together"
+		} else if !strings.HasPrefix(a.Path, "/") {
+			errs["path"] = "Path must start with a slash"
+		}
+
+		return errs
+	}
+
+	parsedURL, err := url.Parse(a.URL)
+
+	if err != nil ||
+		parsedURL.Host == "" ||
+		parsedURL.Scheme == "" ||
+		!strings.EqualFold(parsedURL.Scheme, "http") && !strings.EqualFold(parsedURL.Scheme, "https") {
+		errs["url"] = "Invalid URL"
+	}
+
+	return errs
+}
+
+// ValidatePolicy validates the timeout, retry and concurrency settings.
+// The returned map is keyed by the invalid field.
+func (a Options) ValidatePolicy() map[string]string {
+	errs := map[string]string{}
+
+	if a.Timeout < 0 || a.Timeout > MaxTimeout {
+		errs["timeout"] = fmt.Sprintf("Timeout must be between 0 and %d seconds", MaxTimeout)
+	}
+
+	if a.MaxRetries < 0 || a.MaxRetries > MaxRetries {
+		errs["maxRetries"] = fmt.Sprintf("Max retries must be between 0 and %d", MaxRetries)
+	}
+
+	if a.ConcurrencyPolicy != "" && !utils.InSliceString(ConcurrencyPolicies, a.ConcurrencyPolicy) {
+		errs["concurrencyPolicy"] = fmt.Sprintf("Concurrency policy must be one of: %s", strings.Join(ConcurrencyPolicies, ", "))
+	}
+
+	return errs
+}
+
+// TimeoutDuration returns the time to wait for a response.
+func (a Options) TimeoutDuration() time.Duration {
+	if a.Timeout <= 0 {
+		return DefaultTimeout * time.Second
+	}
+
+	return time.Duration(a.Timeout) * time.Second
 }
 
 func (a Options) Value() (driver.Value, error) {
@@ -39,28 +150,171 @@ type FunctionTrigger struct {
 	ID        types.ID   `json:"id,string"`
 	EnvID     types.ID   `json:"envId,string"`
 	Cron      string     `json:"cron"`
+	TimeZone  string     `json:"timeZone,omitempty"` // IANA time zone, defaults to UTC
 	Status    bool       `json:"status"`
 	Options   Options    `json:"options,omitempty"`
 	NextRunAt utils.Unix `json:"nextRunAt,omitempty"`
+	Source    string     `json:"source,omitempty"` // SourceFile or empty when created through the API
 	CreatedAt utils.Unix `json:"-"`
 	UpdatedAt utils.Unix `json:"-"`
+
+	// SigningSecret signs the trigger context that is passed to functions.
+	SigningSecret string `json:"-"`
+}
+
+// Signature returns the signature of the trigger context that is passed to
+// functions. It covers `<timestamp>.<id>.<sha256 of the payload>`.
+func (t *FunctionTrigger) Signature(timestamp int64, payload []byte) string {
+	hash := sha256.Sum256(payload)
+	return utils.SignPayload(t.SigningSecret, timestamp, []byte(t.ID.String()+"."+hex.EncodeToString(hash[:])))
+}
+
+// NextRunAfter returns the first run time of the trigger after the given time.
+func (t *FunctionTrigger) NextRunAfter(after time.Time) (time.Time, error) {
+	return NextRunAfter(t.Cron, t.TimeZone, after)
 }
 
 // MarshalJSON implements the marshaler interface.
 func (t *FunctionTrigger) ToMap() map[string]any {
-	return map[string]any{
+	data := map[string]any{
 		"id":        t.ID.String(),
 		"envId":     t.EnvID.String(),
 		"cron":      t.Cron,
+		"timeZone":  utils.GetString(t.TimeZone, "UTC"),
 		"status":    t.Status,
 		"nextRunAt": t.NextRunAt.Unix(),
 		"options": map[string]any{
-			"url":     t.Options.URL,
-			"headers": t.Options.Headers,
-			"method":  t.Options.Method,
-			"payload": string(t.Options.Payload),
+			"url":               t.Options.URL,
+			"path":              t.Options.Path,
+			"headers":           t.Options.Headers,
+			"method":            t.Options.Method,
+			"payload":           string(t.Options.Payload),
+			"timeout":           t.Options.Timeout,
+			"maxRetries":        t.Options.MaxRetries,
+			"concurrencyPolicy": utils.GetString(t.Options.ConcurrencyPolicy, ConcurrencyAllow),
 		},
 	}
+
+	// The secret is only returned when it is generated, see RotateSigningSecret.
+	if t.Options.InvokesFunction() && t.SigningSecret != "" {
+		data["signingSecret"] = utils.SecretMask
+	}
+
+	return data
+}
+
+// ErrInvalidTimeZone is returned when the time zone is not a valid IANA time zone.
+var ErrInvalidTimeZone = errors.New("Time zone must be a valid IANA time zone, such as Europe/Dublin")
+
+// Location returns the location for the given IANA time zone. An empty
+// time zone is evaluated as UTC.
+func Location(timeZone string) (*time.Location, error) {
+	if timeZone == "" {
+		return time.UTC, nil
+	}
+
+	loc, err := time.LoadLocation(timeZone)
+
+	if err != nil {
+		return nil, ErrInvalidTimeZone
+	}
+
+	return loc, nil
+}
+
+// NextRunAfter returns the first time after the given time that matches the cron
+// expression, evaluated in the wall clock of the time zone. When clocks move forward
+// and skip the matching time, the run happens right after the change. When clocks
+// move back and repeat the matching time, the run happens at the first occurrence
+// that is after the given time, so a time that already ran is not repeated.
+func NextRunAfter(cron, timeZone string, after time.Time) (time.Time, error) {
+	loc, err := Location(timeZone)
+
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	// Cron expressions are matched against the wall clock, which is represented
+	// in UTC so that daylight saving changes do not affect the calculation.
+	local := after.In(loc)
+	wall := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), local.Minute(), local.Second(), 0, time.UTC)
+	limit := wall.Add(maxClockShift)
+
+	// Wall clock times which are repeated when clocks move back can be before the
+	// given time, so step through them until one of the occurrences is after it.
+	// Times that are more than a clock shift away are always after the given time.
+	for {
+		next, err := gronx.NextTickAfter(cron, wall, false)
+
+		if err != nil {
+			return time.Time{}, err
+		}
+
+		for _, run := range wallClockOccurrences(next, loc) {
+			if run.After(after) {
+				return run.UTC(), nil
+			}
+		}
+
+		if next.After(limit) {
+			return time.Time{}, fmt.Errorf("cannot find the next run time for %s", cron)
+		}
+
+		wall = next
+	}
+}
+
+// maxClockShift is the largest daylight saving shift in the time zone database.
+const maxClockShift = 2 * time.Hour
+
+// wallClockOccurrences returns the times, in chronological order, at which the
+// wall clock of the location shows the given time. There are two of them when
+// clocks move back and repeat the time. When clocks move forward and skip the
+// time, it returns the time right after the change.
+func wallClockOccurrences(wall time.Time, loc *time.Location) []time.Time {
+	run := time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), 0, 0, loc)
+
+	// The time is skipped, use the offset before the change so that the run
+	// is moved forward by the length of the shift.
+	if !sameWallClock(run.In(loc), wall) {
+		_, offset := run.Add(-maxClockShift).Zone()
+		return []time.Time{wall.Add(-time.Duration(offset) * time.Second)}
+	}
+
+	runs := []time.Time{}
+
+	for _, shift := range []time.Duration{-maxClockShift, -time.Hour, -30 * time.Minute, 0, 30 * time.Minute, time.Hour, maxClockShift} {
+		if t := run.Add(shift); sameWallClock(t.In(loc), wall) {
+			runs = append(runs, t)
+		}
+	}
+
+	return runs
+}
+
+func sameWallClock(t, wall time.Time) bool {
+	return t.Year() == wall.Year() &&
+		t.YearDay() == wall.YearDay() &&
+		t.Hour() == wall.Hour() &&
+		t.Minute() == wall.Minute()
+}
+
+// NextRuns returns the next n run times after the given time.
+func NextRuns(cron, timeZone string, after time.Time, n int) ([]time.Time, error) {
+	runs := []time.Time{}
+
+	for range n {
+		next, err := NextRunAfter(cron, timeZone, after)
+
+		if err != nil {
+			return nil, err
+		}
+
+		runs = append(runs, next)
+		after = next
+	}
+
+	return runs, nil
 }
 
 type TriggerLog struct {
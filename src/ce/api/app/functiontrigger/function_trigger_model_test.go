@@ -3,6 +3,7 @@ package functiontrigger_test
 import (
 	"context"
 	"testing"
+	"time"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
@@ -57,6 +58,72 @@ func (s *TriggerFunctionModelSuite) Test_FetchingStringHeadersFromDB() {
 	s.Equal(tf.Options.Headers.String(), "name:joe;surname:doe")
 }
 
+func (s *TriggerFunctionModelSuite) Test_NextRunAfter_TimeZone() {
+	after := time.Date(2026, time.July, 1, 10, 0, 0, 0, time.UTC)
+
+	// 09:00 in Dublin is 08:00 UTC during summer time
+	next, err := functiontrigger.NextRunAfter("0 9 * * *", "Europe/Dublin", after)
+	s.NoError(err)
+	s.Equal(time.Date(2026, time.July, 2, 8, 0, 0, 0, time.UTC), next)
+
+	next, err = functiontrigger.NextRunAfter("0 9 * * *", "", after)
+	s.NoError(err)
+	s.Equal(time.Date(2026, time.July, 2, 9, 0, 0, 0, time.UTC), next)
+
+	_, err = functiontrigger.NextRunAfter("0 9 * * *", "Europe/Atlantis", after)
+	s.ErrorIs(err, functiontrigger.ErrInvalidTimeZone)
+}
+
+func (s *TriggerFunctionModelSuite) Test_NextRunAfter_DaylightSaving() {
+	// Clocks move back from 02:00 to 01:00 on the 25th of October, 01:30 happens twice.
+	runs, err := functiontrigger.NextRuns("30 1 * * *", "Europe/Dublin", time.Date(2026, time.October, 24, 12, 0, 0, 0, time.UTC), 3)
+	s.NoError(err)
+	s.Len(runs, 3)
+	s.Equal(time.Date(2026, time.October, 25, 0, 30, 0, 0, time.UTC), runs[0])
+	s.Equal(time.Date(2026, time.October, 26, 1, 30, 0, 0, time.UTC), runs[1])
+	s.Equal(time.Date(2026, time.October, 27, 1, 30, 0, 0, time.UTC), runs[2])
+
+	// Clocks move forward from 01:00 to 02:00 on the 29th of March, 01:30 does not exist.
+	runs, err = functiontrigger.NextRuns("30 1 * * *", "Europe/Dublin", time.Date(2026, time.March, 28, 12, 0, 0, 0, time.UTC), 2)
+	s.NoError(err)
+	s.Equal(time.Date(2026, time.March, 29, 1, 30, 0, 0, time.UTC), runs[0])
+	s.Equal(time.Date(2026, time.March, 30, 0, 30, 0, 0, time.UTC), runs[1])
+}
+
+func (s *TriggerFunctionModelSuite) Test_NextRunAfter_DaylightSavingTable() {
+	newYork, err := time.LoadLocation("America/New_York")
+	s.NoError(err)
+
+	// Clocks move back from 02:00 EDT to 01:00 EST on the 1st of November.
+	firstFold := time.Date(2026, time.November, 1, 5, 10, 0, 0, time.UTC)  // 01:10 EDT
+	secondFold := time.Date(2026, time.November, 1, 6, 10, 0, 0, time.UTC) // 01:10 EST
+
+	// Clocks move forward from 02:00 EST to 03:00 EDT on the 8th of March.
+	beforeGap := time.Date(2026, time.March, 8, 6, 50, 0, 0, time.UTC) // 01:50 EST
+
+	tests := []struct {
+		name     string
+		cron     string
+		after    time.Time
+		expected time.Time
+	}{
+		{"every 15 minutes in the first occurrence", "*/15 * * * *", firstFold, time.Date(2026, time.November, 1, 5, 15, 0, 0, time.UTC)},
+		{"every 15 minutes in the second occurrence", "*/15 * * * *", secondFold, time.Date(2026, time.November, 1, 6, 15, 0, 0, time.UTC)},
+		{"every minute in the second occurrence", "* * * * *", secondFold, time.Date(2026, time.November, 1, 6, 11, 0, 0, time.UTC)},
+		{"daily in the second occurrence", "30 1 * * *", secondFold, time.Date(2026, time.November, 1, 6, 30, 0, 0, time.UTC)},
+		{"daily after the first occurrence", "30 1 * * *", time.Date(2026, time.November, 1, 5, 30, 0, 0, time.UTC), time.Date(2026, time.November, 2, 6, 30, 0, 0, time.UTC)},
+		{"hourly after the repeated hour", "0 * * * *", time.Date(2026, time.November, 1, 6, 30, 0, 0, time.UTC), time.Date(2026, time.November, 1, 7, 0, 0, 0, time.UTC)},
+		{"every 15 minutes before the gap", "*/15 * * * *", beforeGap, time.Date(2026, time.March, 8, 7, 0, 0, 0, time.UTC)},
+		{"daily in the skipped hour", "30 2 * * *", beforeGap, time.Date(2026, time.March, 8, 7, 30, 0, 0, time.UTC)},
+	}
+
+	for _, test := range tests {
+		next, err := functiontrigger.NextRunAfter(test.cron, newYork.String(), test.after)
+		s.NoError(err, test.name)
+		s.Equal(test.expected, next, test.name)
+	}
+}
+
 func TestHandlerTrigger(t *testing.T) {
 	suite.Run(t, &TriggerFunctionModelSuite{})
 }
@@ -2,12 +2,12 @@ package functiontrigger
 
 import (
 	"context"
+	"database/sql"
 	"encoding/json"
 	"strings"
 	"text/template"
 	"time"
 
-	"github.com/adhocore/gronx"
 	"github.com/stormkit-io/stormkit-io/src/lib/database"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
@@ -15,18 +15,22 @@ import (
 )
 
 var stmts = struct {
-	selectTriggers    string
-	selectTriggerLogs string
-	deleteTrigger     string
-	insertTrigger     string
-	updateTrigger     string
-	updateNextRunAt   string
-	insertTriggerLogs string
+	selectTriggers      string
+	selectTriggerLogs   string
+	deleteTrigger       string
+	deleteBySource      string
+	insertTrigger       string
+	updateTrigger       string
+	updateNextRunAt     string
+	insertTriggerLogs   string
+	setSigningSecret    string
+	rotateSigningSecret string
 }{
 	selectTriggers: `
 		SELECT
-			trigger_id, env_id, cron, trigger_options, 
-			trigger_status, created_at, next_run_at, updated_at
+			trigger_id, env_id, cron, COALESCE(time_zone, ''), trigger_options,
+			trigger_status, COALESCE(trigger_source, ''), created_at, next_run_at, updated_at,
+			signing_secret
         FROM
 			function_triggers
 		WHERE
@@ -52,11 +56,20 @@ var stmts = struct {
 		WHERE
 			trigger_id = $1;
 	`,
+	deleteBySource: `
+		DELETE FROM
+			function_triggers
+		WHERE
+			env_id = $1 AND
+			trigger_source = $2
+		RETURNING
+			COALESCE(trigger_options->>'path', ''), signing_secret;
+	`,
 	insertTrigger: `
 		INSERT INTO function_triggers
-		    (env_id, cron, next_run_at, trigger_options, trigger_status)
+		    (env_id, cron, time_zone, next_run_at, trigger_options, trigger_status, trigger_source, signing_secret)
 		VALUES
-			($1, $2, $3, $4, $5)
+			($1, $2, NULLIF($3, ''), $4, $5, $6, NULLIF($7, ''), $8)
     	RETURNING
 			trigger_id;
 	`,
@@ -65,12 +78,13 @@ var stmts = struct {
 			function_triggers
 		SET
 			cron = $1,
-			trigger_options = $2,
-			trigger_status = $3,
-			next_run_at = $4,
+			time_zone = NULLIF($2, ''),
+			trigger_options = $3,
+			trigger_status = $4,
+			next_run_at = $5,
 			updated_at = timezone('utc', now())
 		WHERE
-			trigger_id = $5;
+			trigger_id = $6;
 	`,
 	updateNextRunAt: `
 		UPDATE
@@ -91,6 +105,24 @@ var stmts = struct {
 		VALUES
 			{{ generateValues 3 (len .) }};
 	`,
+	setSigningSecret: `
+		UPDATE
+			function_triggers
+		SET
+			signing_secret = COALESCE(signing_secret, $1)
+		WHERE
+			trigger_id = $2
+		RETURNING
+			signing_secret;
+	`,
+	rotateSigningSecret: `
+		UPDATE
+			function_triggers
+		SET
+			signing_secret = $1
+		WHERE
+			trigger_id = $2;
+	`,
 }
 
 type Store struct {
@@ -177,39 +209,99 @@ func (s *Store) DueTriggers(ctx context.Context) ([]*FunctionTrigger, error) {
 
 // Insert the given trigger function into the database.
 func (s *Store) Insert(ctx context.Context, ft *FunctionTrigger) error {
+	params, err := insertParams(ft)
+
+	if err != nil {
+		return err
+	}
+
+	row, err := s.QueryRow(ctx, stmts.insertTrigger, params...)
+
+	if err != nil {
+		return err
+	}
+
+	if err := row.Scan(&ft.ID); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func insertParams(ft *FunctionTrigger) ([]any, error) {
 	opts, err := json.Marshal(ft.Options)
 
 	if err != nil {
 		slog.Errorf("error while marshaling function trigger options: %s", err.Error())
-		return err
+		return nil, err
 	}
 
 	if ft.Status {
-		nextRunAt, err := gronx.NextTickAfter(ft.Cron, time.Now().UTC(), true)
+		nextRunAt, err := ft.NextRunAfter(time.Now())
 
 		if err == nil {
 			ft.NextRunAt = utils.UnixFrom(nextRunAt)
 		}
 	}
 
-	row, err := s.QueryRow(
-		ctx,
-		stmts.insertTrigger,
-		ft.EnvID,
-		ft.Cron,
-		ft.NextRunAt,
-		opts,
-		ft.Status,
-	)
+	if ft.SigningSecret == "" {
+		ft.SigningSecret = utils.GenerateSigningSecret()
+	}
+
+	secret, err := utils.Encrypt([]byte(ft.SigningSecret))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return []any{ft.EnvID, ft.Cron, ft.TimeZone, ft.NextRunAt, opts, ft.Status, ft.Source, utils.EncodeToString(secret)}, nil
+}
+
+// EnsureSigningSecret sets the signing secret of the trigger. Triggers that were
+// created before signing secrets were stored are given a new secret when they are
+// invoked. Their owners rotate the secret to obtain it.
+func (s *Store) EnsureSigningSecret(ctx context.Context, ft *FunctionTrigger) error {
+	if ft.SigningSecret != "" {
+		return nil
+	}
+
+	encrypted, err := utils.Encrypt([]byte(utils.GenerateSigningSecret()))
 
 	if err != nil {
 		return err
 	}
 
-	if err := row.Scan(&ft.ID); err != nil {
+	row, err := s.QueryRow(ctx, stmts.setSigningSecret, utils.EncodeToString(encrypted), ft.ID)
+
+	if err != nil {
+		return err
+	}
+
+	var secret string
+
+	// A concurrent call may have set the secret first, in which case that one is returned.
+	if err := row.Scan(&secret); err != nil {
 		return err
 	}
 
+	ft.SigningSecret = utils.DecryptToString(secret)
+	return nil
+}
+
+// RotateSigningSecret replaces the signing secret of the trigger with a new one.
+func (s *Store) RotateSigningSecret(ctx context.Context, ft *FunctionTrigger) error {
+	secret := utils.GenerateSigningSecret()
+	encrypted, err := utils.Encrypt([]byte(secret))
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := s.Exec(ctx, stmts.rotateSigningSecret, utils.EncodeToString(encrypted), ft.ID); err != nil {
+		return err
+	}
+
+	ft.SigningSecret = secret
 	return nil
 }
 
@@ -244,7 +336,7 @@ func (s *Store) Update(ctx context.Context, ft *FunctionTrigger) error {
 	}
 
 	if ft.Status {
-		nextRunAt, err := gronx.NextTick(ft.Cron, true)
+		nextRunAt, err := ft.NextRunAfter(time.Now())
 
 		if err == nil {
 			ft.NextRunAt = utils.UnixFrom(nextRunAt)
@@ -253,7 +345,7 @@ func (s *Store) Update(ctx context.Context, ft *FunctionTrigger) error {
 		ft.NextRunAt = utils.Unix{Valid: false}
 	}
 
-	_, err = s.Exec(ctx, stmts.updateTrigger, ft.Cron, opts, ft.Status, ft.NextRunAt, ft.ID)
+	_, err = s.Exec(ctx, stmts.updateTrigger, ft.Cron, ft.TimeZone, opts, ft.Status, ft.NextRunAt, ft.ID)
 	return err
 }
 
@@ -282,6 +374,71 @@ func (s *Store) Delete(ctx context.Context, id types.ID) error {
 	return err
 }
 
+// ReplaceSourceTriggers replaces the triggers of the environment that come from
+// the given source with the given ones, in a single transaction. Triggers from
+// other sources are left untouched. A new trigger keeps the signing secret of the
+// replaced trigger with the same path, so that functions keep verifying it.
+func (s *Store) ReplaceSourceTriggers(ctx context.Context, envID types.ID, source string, triggers []*FunctionTrigger) error {
+	tx, err := s.Conn.BeginTx(ctx, nil)
+
+	if err != nil {
+		return err
+	}
+
+	errFn := func(err error) error {
+		_ = tx.Rollback()
+		return err
+	}
+
+	rows, err := tx.QueryContext(ctx, stmts.deleteBySource, envID, source)
+
+	if err != nil {
+		return errFn(err)
+	}
+
+	secrets := map[string][]string{}
+
+	for rows.Next() {
+		var path string
+		var secret sql.NullString
+
+		if err := rows.Scan(&path, &secret); err != nil {
+			rows.Close()
+			return errFn(err)
+		}
+
+		if path != "" && secret.Valid {
+			secrets[path] = append(secrets[path], utils.DecryptToString(secret.String))
+		}
+	}
+
+	if err := rows.Close(); err != nil {
+		return errFn(err)
+	}
+
+	for _, trigger := range triggers {
+		trigger.EnvID = envID
+		trigger.Source = source
+
+		if prev := secrets[trigger.Options.Path]; trigger.Options.Path != "" && len(prev) > 0 {
+			trigger.SigningSecret = prev[0]
+			secrets[trigger.Options.Path] = prev[1:]
+		}
+
+		params, err := insertParams(trigger)
+
+		if err != nil {
+			return errFn(err)
+		}
+
+		if err := tx.QueryRowContext(ctx, stmts.insertTrigger, params...).Scan(&trigger.ID); err != nil {
+			return errFn(err)
+		}
+	}
+
+	return tx.Commit()
+}
+
 // Logs return the last 25 trigger logs for the given trigger ID.
 func (s *Store) Logs(ctx context.Context, id types.ID) ([]TriggerLog, error) {
 	rows, err := s.Query(ctx, stmts.selectTriggerLogs, id)
@@ -322,10 +479,13 @@ func (s *Store) selectRows(ctx context.Context, query string, args ...any) ([]*F
 
 	for rows.Next() {
 		tmp := &FunctionTrigger{}
+
+		var secret sql.NullString
+
 		err := rows.Scan(
-			&tmp.ID, &tmp.EnvID, &tmp.Cron,
-			&tmp.Options, &tmp.Status, &tmp.CreatedAt,
-			&tmp.NextRunAt, &tmp.UpdatedAt,
+			&tmp.ID, &tmp.EnvID, &tmp.Cron, &tmp.TimeZone,
+			&tmp.Options, &tmp.Status, &tmp.Source, &tmp.CreatedAt,
+			&tmp.NextRunAt, &tmp.UpdatedAt, &secret,
 		)
 
 		if err != nil {
@@ -333,6 +493,10 @@ func (s *Store) selectRows(ctx context.Context, query string, args ...any) ([]*F
 			continue
 		}
 
+		if secret.Valid && secret.String != "" {
+			tmp.SigningSecret = utils.DecryptToString(secret.String)
+		}
+
 		tfs = append(tfs, tmp)
 	}
 
@@ -2,8 +2,6 @@ package functiontriggerhandlers
 
 import (
 	"net/http"
-	"net/url"
-	"strings"
 
 	"github.com/adhocore/gronx"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
@@ -13,18 +11,38 @@ import (
 )
 
 type FunctionTriggerRequest struct {
-	ID      types.ID `json:"id,string"`
-	EnvID   types.ID `json:"envId,string"`
-	Cron    string   `json:"cron"`
-	Status  bool     `json:"status"`
-	Options struct {
+	ID       types.ID `json:"id,string"`
+	EnvID    types.ID `json:"envId,string"`
+	Cron     string   `json:"cron"`
+	TimeZone string   `json:"timeZone"`
+	Status   bool     `json:"status"`
+	Options  struct {
 		Headers shttp.Headers `json:"headers"`
 		Method  string        `json:"method"`
 		Payload string        `json:"payload"`
 		URL     string        `json:"url"`
+		Path    string        `json:"path"`
+
+		Timeout           int    `json:"timeout"`
+		MaxRetries        int    `json:"maxRetries"`
+		ConcurrencyPolicy string `json:"concurrencyPolicy"`
 	} `json:"options"`
 }
 
+// options returns the trigger options from the request.
+func (data *FunctionTriggerRequest) options() functiontrigger.Options {
+	return functiontrigger.Options{
+		Method:            data.Options.Method,
+		Headers:           data.Options.Headers,
+		URL:               data.Options.URL,
+		Path:              data.Options.Path,
+		Payload:           []byte(data.Options.Payload),
+		Timeout:           data.Options.Timeout,
+		MaxRetries:        data.Options.MaxRetries,
+		ConcurrencyPolicy: data.Options.ConcurrencyPolicy,
+	}
+}
+
 func validate(data *FunctionTriggerRequest) map[string]string {
 	errors := map[string]string{}
 
@@ -32,14 +50,18 @@ func validate(data *FunctionTriggerRequest) map[string]string {
 		errors["cron"] = "Invalid cron format"
 	}
 
-	// Parse the URL
-	parsedURL, err := url.Parse(data.Options.URL)
+	if _, err := functiontrigger.Location(data.TimeZone); err != nil {
+		errors["timeZone"] = err.Error()
+	}
+
+	options := data.options()
 
-	if err != nil ||
-		parsedURL.Host == "" ||
-		parsedURL.Scheme == "" ||
-		!strings.EqualFold(parsedURL.Scheme, "http") && !strings.EqualFold(parsedURL.Scheme, "https") {
-		errors["url"] = "Invalid URL"
+	for field, msg := range options.ValidateTarget() {
+		errors[field] = msg
+	}
+
+	for field, msg := range options.ValidatePolicy() {
+		errors[field] = msg
 	}
 
 	if len(errors) == 0 {
@@ -64,20 +86,27 @@ func handlerFunctionTriggerCreate(req *app.RequestContext) *shttp.Response {
 	}
 
 	record := &functiontrigger.FunctionTrigger{
-		Cron:   tf.Cron,
-		EnvID:  tf.EnvID,
-		Status: tf.Status,
-		Options: functiontrigger.Options{
-			Method:  tf.Options.Method,
-			Headers: tf.Options.Headers,
-			URL:     tf.Options.URL,
-			Payload: []byte(tf.Options.Payload),
-		},
+		Cron:     tf.Cron,
+		TimeZone: tf.TimeZone,
+		EnvID:    tf.EnvID,
+		Status:   tf.Status,
+		Options:  tf.options(),
 	}
 
 	if err := functiontrigger.NewStore().Insert(req.Context(), record); err != nil {
 		return shttp.Error(err)
 	}
 
+	// The signing secret is returned only once. It can be rotated later on.
+	if record.Options.InvokesFunction() {
+		return &shttp.Response{
+			Status: http.StatusCreated,
+			Data: map[string]any{
+				"id":            record.ID.String(),
+				"signingSecret": record.SigningSecret,
+			},
+		}
+	}
+
 	return shttp.Created()
 }
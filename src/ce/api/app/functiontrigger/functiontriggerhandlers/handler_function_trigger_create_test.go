@@ -2,8 +2,10 @@ package functiontriggerhandlers_test
 
 import (
 	"context"
+	"fmt"
 	"net/http"
 	"testing"
+	"time"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
@@ -203,6 +205,104 @@ func (s *HandlerFunctionTriggerCreateSuite) Test_FailValidation() {
 	s.Equal(http.StatusBadRequest, response.Code)
 }
 
+func (s *HandlerFunctionTriggerCreateSuite) Test_Success_FunctionPath() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger",
+		map[string]any{
+			"appId":    env.AppID.String(),
+			"envId":    env.ID.String(),
+			"cron":     "0 9 * * *",
+			"timeZone": "Europe/Dublin",
+			"status":   true,
+			"options": map[string]any{
+				"method": "POST",
+				"path":   "/api/cron",
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusCreated, response.Code)
+
+	tfs, err := functiontrigger.NewStore().List(context.Background(), env.ID)
+	s.NoError(err)
+	s.Len(tfs, 1)
+	s.Equal("/api/cron", tfs[0].Options.Path)
+	s.Equal("Europe/Dublin", tfs[0].TimeZone)
+
+	// The signing secret is returned only once, when the trigger is created
+	s.JSONEq(fmt.Sprintf(`{ "id": "%s", "signingSecret": "%s" }`, tfs[0].ID.String(), tfs[0].SigningSecret), response.String())
+
+	loc, err := time.LoadLocation("Europe/Dublin")
+	s.NoError(err)
+	s.Equal(9, tfs[0].NextRunAt.In(loc).Hour())
+	s.True(tfs[0].Options.InvokesFunction())
+}
+
+func (s *HandlerFunctionTriggerCreateSuite) Test_FailValidation_PathAndURL() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger",
+		map[string]any{
+			"appId": env.AppID.String(),
+			"envId": env.ID.String(),
+			"cron":  "0 * * * *",
+			"options": map[string]any{
+				"method": "POST",
+				"url":    "https://can.com/",
+				"path":   "/api/cron",
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{ "path": "Path and URL cannot be used together" }`, response.String())
+}
+
+func (s *HandlerFunctionTriggerCreateSuite) Test_FailValidation_Policy() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger",
+		map[string]any{
+			"appId": env.AppID.String(),
+			"envId": env.ID.String(),
+			"cron":  "*/5 * * * *",
+			"options": map[string]any{
+				"method":            "GET",
+				"url":               "https://can.com/",
+				"timeout":           600,
+				"maxRetries":        20,
+				"concurrencyPolicy": "queue",
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{
+		"timeout": "Timeout must be between 0 and 300 seconds",
+		"maxRetries": "Max retries must be between 0 and 10",
+		"concurrencyPolicy": "Concurrency policy must be one of: allow, forbid, replace"
+	}`, response.String())
+}
+
 func TestHandlerCreateTrigger(t *testing.T) {
 	suite.Run(t, &HandlerFunctionTriggerCreateSuite{})
 }
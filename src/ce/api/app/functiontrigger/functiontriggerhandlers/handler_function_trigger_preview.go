@@ -0,0 +1,61 @@
+package functiontriggerhandlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/adhocore/gronx"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const defaultPreviewRuns = 5
+const maxPreviewRuns = 20
+
+// handlerFunctionTriggerPreview returns the next run times of the given cron
+// expression, so that users can check the schedule before saving it.
+func handlerFunctionTriggerPreview(req *app.RequestContext) *shttp.Response {
+	query := req.Query()
+	cron := query.Get("cron")
+	timeZone := query.Get("timeZone")
+	count := utils.StringToInt(query.Get("count"))
+
+	if count <= 0 || count > maxPreviewRuns {
+		count = defaultPreviewRuns
+	}
+
+	if !gronx.New().IsValid(cron) {
+		return shttp.BadRequest(map[string]any{"cron": "Invalid cron format"})
+	}
+
+	loc, err := functiontrigger.Location(timeZone)
+
+	if err != nil {
+		return shttp.BadRequest(map[string]any{"timeZone": err.Error()})
+	}
+
+	runs, err := functiontrigger.NextRuns(cron, timeZone, time.Now(), count)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	nextRuns := []map[string]any{}
+
+	for _, run := range runs {
+		nextRuns = append(nextRuns, map[string]any{
+			"time":    run.Unix(),
+			"display": run.In(loc).Format("Mon, 02 Jan 2006 15:04 MST"),
+		})
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"timeZone": loc.String(),
+			"nextRuns": nextRuns,
+		},
+	}
+}
@@ -0,0 +1,87 @@
+package functiontriggerhandlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger/functiontriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerFunctionTriggerPreviewSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerFunctionTriggerPreviewSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	admin.SetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerPreviewSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	admin.ResetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerPreviewSuite) Test_Preview() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/trigger/preview?appId=%d&envId=%d&cron=%s&timeZone=%s&count=3", env.AppID, env.ID, url.QueryEscape("0 9 * * 1"), url.QueryEscape("Asia/Tokyo")),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	data := struct {
+		TimeZone string `json:"timeZone"`
+		NextRuns []struct {
+			Time    int64  `json:"time"`
+			Display string `json:"display"`
+		} `json:"nextRuns"`
+	}{}
+
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Equal("Asia/Tokyo", data.TimeZone)
+	s.Len(data.NextRuns, 3)
+	s.Regexp(`^Mon, \d{2} \w{3} \d{4} 09:00 JST$`, data.NextRuns[0].Display)
+	s.Equal(int64(7*24*60*60), data.NextRuns[1].Time-data.NextRuns[0].Time)
+}
+
+func (s *HandlerFunctionTriggerPreviewSuite) Test_InvalidTimeZone() {
+	env := s.MockEnv(nil)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/trigger/preview?appId=%d&envId=%d&cron=%s&timeZone=Mars", env.AppID, env.ID, url.QueryEscape("0 9 * * 1")),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(env.GetApp().UserID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.JSONEq(`{ "timeZone": "Time zone must be a valid IANA time zone, such as Europe/Dublin" }`, response.String())
+}
+
+func TestHandlerPreviewTrigger(t *testing.T) {
+	suite.Run(t, &HandlerFunctionTriggerPreviewSuite{})
+}
@@ -0,0 +1,43 @@
+package functiontriggerhandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type FunctionTriggerRunRequest struct {
+	TriggerID types.ID `json:"triggerId,string"`
+}
+
+// handlerFunctionTriggerRun enqueues an immediate run of the trigger. The run
+// is logged as a manual run and does not change the next scheduled run.
+func handlerFunctionTriggerRun(req *app.RequestContext) *shttp.Response {
+	data := &FunctionTriggerRunRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if data.TriggerID == 0 {
+		return shttp.NotFound()
+	}
+
+	tf, err := functiontrigger.NewStore().ByID(req.Context(), data.TriggerID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if tf == nil || tf.EnvID != req.EnvID {
+		return shttp.NotFound()
+	}
+
+	if err := jobs.RunFunctionTriggerNow(req.Context(), tf); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
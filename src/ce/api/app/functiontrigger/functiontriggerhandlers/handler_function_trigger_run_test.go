@@ -0,0 +1,109 @@
+package functiontriggerhandlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger/functiontriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerFunctionTriggerRunSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn           databasetest.TestDB
+	mockClient     mocks.TaskClient
+	originalClient func() tasks.TaskClient
+}
+
+func (s *HandlerFunctionTriggerRunSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.mockClient = mocks.TaskClient{}
+	s.originalClient = tasks.Client
+	tasks.Client = func() tasks.TaskClient {
+		return &s.mockClient
+	}
+
+	admin.SetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerRunSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	tasks.Client = s.originalClient
+	admin.ResetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerRunSuite) Test_Run() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	tf := s.MockTriggerFunction(env)
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger/run",
+		map[string]any{
+			"appId":     app.ID.String(),
+			"envId":     env.ID.String(),
+			"triggerId": tf.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		messages := []jobs.FunctionTriggerMessage{}
+		s.NoError(json.Unmarshal(task.Payload(), &messages))
+		return task.Type() == tasks.TriggerFunctionHttp && len(messages) == 1 && messages[0].ID == tf.ID && messages[0].Manual
+	}))
+}
+
+func (s *HandlerFunctionTriggerRunSuite) Test_Permission() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+
+	app2 := s.MockApp(usr)
+	env2 := s.MockEnv(app2)
+	tf2 := s.MockTriggerFunction(env2)
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger/run",
+		map[string]any{
+			"appId":     app.ID.String(),
+			"envId":     env.ID.String(),
+			"triggerId": tf2.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusNotFound, response.Code)
+	s.mockClient.AssertNotCalled(s.T(), "Enqueue", mock.Anything)
+}
+
+func TestHandlerRunTrigger(t *testing.T) {
+	suite.Run(t, &HandlerFunctionTriggerRunSuite{})
+}
@@ -0,0 +1,64 @@
+package functiontriggerhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type FunctionTriggerSecretRotateRequest struct {
+	TriggerID types.ID `json:"triggerId,string"`
+}
+
+// handlerFunctionTriggerSecretRotate generates a new signing secret for the trigger
+// and returns it. This is the only time the secret is returned, so it is limited
+// to the team members with write access.
+func handlerFunctionTriggerSecretRotate(req *app.RequestContext) *shttp.Response {
+	data := &FunctionTriggerSecretRotateRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	if data.TriggerID == 0 {
+		return shttp.NotFound()
+	}
+
+	if !req.User.IsAdmin {
+		t, err := team.NewStore().Team(req.Context(), req.App.TeamID, req.User.ID)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if t == nil || !team.HasWriteAccess(t.CurrentUserRole) {
+			return shttp.Forbidden()
+		}
+	}
+
+	store := functiontrigger.NewStore()
+	tf, err := store.ByID(req.Context(), data.TriggerID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if tf == nil || tf.EnvID != req.EnvID {
+		return shttp.NotFound()
+	}
+
+	if err := store.RotateSigningSecret(req.Context(), tf); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"signingSecret": tf.SigningSecret,
+		},
+	}
+}
@@ -0,0 +1,113 @@
+package functiontriggerhandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger/functiontriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerFunctionTriggerSecretRotateSuite struct {
+	suite.Suite
+	*factory.Factory
+
+	conn databasetest.TestDB
+}
+
+func (s *HandlerFunctionTriggerSecretRotateSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	admin.SetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerSecretRotateSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	admin.ResetMockLicense()
+}
+
+func (s *HandlerFunctionTriggerSecretRotateSuite) Test_Rotate() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	tf := s.MockTriggerFunction(env, map[string]any{
+		"Options": functiontrigger.Options{Path: "/api/cron"},
+	})
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger/secret",
+		map[string]any{
+			"appId":     app.ID.String(),
+			"envId":     env.ID.String(),
+			"triggerId": tf.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	data := map[string]string{}
+
+	s.Equal(http.StatusOK, response.Code)
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.NotEmpty(data["signingSecret"])
+	s.NotEqual(tf.SigningSecret, data["signingSecret"])
+
+	stored, err := functiontrigger.NewStore().ByID(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Equal(data["signingSecret"], stored.SigningSecret)
+}
+
+func (s *HandlerFunctionTriggerSecretRotateSuite) Test_DeveloperForbidden() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+	tf := s.MockTriggerFunction(env, map[string]any{
+		"Options": functiontrigger.Options{Path: "/api/cron"},
+	})
+
+	dev := s.MockUser()
+
+	s.NoError(team.NewStore().AddMemberToTeam(context.Background(), &team.Member{
+		TeamID: app.TeamID,
+		UserID: dev.ID,
+		Role:   team.ROLE_DEVELOPER,
+		Status: true,
+	}))
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/apps/trigger/secret",
+		map[string]any{
+			"appId":     app.ID.String(),
+			"envId":     env.ID.String(),
+			"triggerId": tf.ID.String(),
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(dev.ID),
+		},
+	)
+
+	s.Equal(http.StatusForbidden, response.Code)
+
+	stored, err := functiontrigger.NewStore().ByID(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Equal(tf.SigningSecret, stored.SigningSecret)
+}
+
+func TestHandlerFunctionTriggerSecretRotate(t *testing.T) {
+	suite.Run(t, &HandlerFunctionTriggerSecretRotateSuite{})
+}
@@ -1,6 +1,8 @@
 package functiontriggerhandlers
 
 import (
+	"net/http"
+
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
@@ -13,6 +15,13 @@ func handlerFunctionTriggerUpdate(req *app.RequestContext) *shttp.Response {
 		return shttp.Error(err)
 	}
 
+	if errs := validate(tf); errs != nil {
+		return &shttp.Response{
+			Status: http.StatusBadRequest,
+			Data:   errs,
+		}
+	}
+
 	store := functiontrigger.NewStore()
 	existing, err := store.ByID(req.Context(), tf.ID)
 
@@ -25,15 +34,11 @@ func handlerFunctionTriggerUpdate(req *app.RequestContext) *shttp.Response {
 	}
 
 	record := &functiontrigger.FunctionTrigger{
-		ID:     tf.ID,
-		Cron:   tf.Cron,
-		Status: tf.Status,
-		Options: functiontrigger.Options{
-			Method:  tf.Options.Method,
-			Headers: tf.Options.Headers,
-			URL:     tf.Options.URL,
-			Payload: []byte(tf.Options.Payload),
-		},
+		ID:       tf.ID,
+		Cron:     tf.Cron,
+		TimeZone: tf.TimeZone,
+		Status:   tf.Status,
+		Options:  tf.options(),
 	}
 
 	if err := functiontrigger.NewStore().Update(req.Context(), record); err != nil {
@@ -1,6 +1,7 @@
 package functiontriggerhandlers_test
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
@@ -64,12 +65,17 @@ func (s *HandlerTriggerFunctionGetSuite) Test_Success() {
 			"id": "1",
 			"envId": "1",
 			"cron": "*/1 * * * *",
+			"timeZone": "UTC",
 			"nextRunAt": 1712418330,
 			"options": {
 				"method": "POST",
 				"payload": "{ \"hello\": \"world\" }",
 				"headers": null,
-				"url": ""
+				"url": "",
+				"path": "",
+				"timeout": 0,
+				"maxRetries": 0,
+				"concurrencyPolicy": "allow"
 			},
 			"status": true
 		}]
@@ -79,6 +85,45 @@ func (s *HandlerTriggerFunctionGetSuite) Test_Success() {
 	s.JSONEq(expected, response.String())
 }
 
+func (s *HandlerTriggerFunctionGetSuite) Test_SigningSecret() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app)
+
+	tf := s.MockTriggerFunction(env, map[string]any{
+		"Options": functiontrigger.Options{Path: "/api/cron"},
+	})
+
+	another := s.MockTriggerFunction(env, map[string]any{
+		"Options": functiontrigger.Options{Path: "/api/cron"},
+	})
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(functiontriggerhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		fmt.Sprintf("/apps/triggers?appId=%d&envId=%d", app.ID, env.ID),
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	data := struct {
+		Triggers []map[string]any `json:"triggers"`
+	}{}
+
+	s.Equal(http.StatusOK, response.Code)
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Len(data.Triggers, 2)
+
+	// Signing secrets are masked, they are only returned when they are generated
+	for _, t := range data.Triggers {
+		s.Equal(utils.SecretMask, t["signingSecret"])
+		s.NotContains(response.String(), tf.SigningSecret)
+		s.NotContains(response.String(), another.SigningSecret)
+	}
+}
+
 func TestHandlerTrigger(t *testing.T) {
 	suite.Run(t, &HandlerTriggerFunctionGetSuite{})
 }
@@ -14,7 +14,10 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodPatch, "/trigger", app.WithApp(handlerFunctionTriggerUpdate)).
 		Handler(shttp.MethodPost, "/trigger", app.WithApp(handlerFunctionTriggerCreate)).
 		Handler(shttp.MethodGet, "/triggers", app.WithApp(handlerFunctionTriggersGet)).
-		Handler(shttp.MethodGet, "/trigger/logs", app.WithApp(handleTriggerLogsGet))
+		Handler(shttp.MethodGet, "/trigger/logs", app.WithApp(handleTriggerLogsGet)).
+		Handler(shttp.MethodGet, "/trigger/preview", app.WithApp(handlerFunctionTriggerPreview)).
+		Handler(shttp.MethodPost, "/trigger/run", app.WithApp(handlerFunctionTriggerRun)).
+		Handler(shttp.MethodPost, "/trigger/secret", app.WithApp(handlerFunctionTriggerSecretRotate))
 
 	return s
 }
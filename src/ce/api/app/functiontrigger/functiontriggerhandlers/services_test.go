@@ -19,9 +19,12 @@ func (s *ServicesSuite) Test_Services() {
 	handlers := []string{
 		"DELETE:/apps/trigger",
 		"GET:/apps/trigger/logs",
+		"GET:/apps/trigger/preview",
 		"GET:/apps/triggers",
 		"PATCH:/apps/trigger",
 		"POST:/apps/trigger",
+		"POST:/apps/trigger/run",
+		"POST:/apps/trigger/secret",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
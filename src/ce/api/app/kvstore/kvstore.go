@@ -0,0 +1,208 @@
+package kvstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// cacheTTL is the maximum duration for which a read is cached in Redis.
+const cacheTTL = time.Minute
+
+// versionTTL is the duration for which the version of a key is kept after
+// the last write. It only needs to outlive the reads that started before it.
+const versionTTL = 10 * time.Minute
+
+func cacheKey(envID types.ID, key string) string {
+	return fmt.Sprintf("kv:%s:%s", envID.String(), key)
+}
+
+func versionKey(envID types.ID, key string) string {
+	return fmt.Sprintf("kv-version:%s:%s", envID.String(), key)
+}
+
+// cacheScript caches the entry only if the key was not written since its version
+// was read, so that a read that started before a write cannot cache the old value.
+var cacheScript = redis.NewScript(`
+if (redis.call("GET", KEYS[2]) or "") == ARGV[1] then
+	return redis.call("SET", KEYS[1], ARGV[2], "PX", ARGV[3])
+end
+return 0
+`)
+
+// Get returns the entry with the given key, or nil when the key does not exist.
+// When the Redis cache is enabled, reads are served from the cache first. The
+// cache is eventually consistent: a write invalidates it once it is committed,
+// and when Redis cannot be reached, reads may return the previous value until
+// the cached entry expires.
+func Get(ctx context.Context, envID types.ID, key string) (*Entry, error) {
+	cached := admin.MustConfig().KV().RedisCache
+	version := ""
+
+	if cached {
+		client := rediscache.Client()
+
+		if data, err := client.Get(ctx, cacheKey(envID, key)).Bytes(); err == nil {
+			entry := &Entry{}
+
+			if err := json.Unmarshal(data, entry); err == nil {
+				return entry, nil
+			}
+		}
+
+		// The version is read before the database, see cacheScript.
+		v, err := client.Get(ctx, versionKey(envID, key)).Result()
+
+		if err != nil && !errors.Is(err, redis.Nil) {
+			cached = false
+		}
+
+		version = v
+	}
+
+	entry, err := NewStore().Entry(ctx, envID, key)
+
+	if err != nil || entry == nil {
+		return entry, err
+	}
+
+	if cached {
+		ttl := cacheTTL
+
+		if !entry.ExpiresAt.IsZero() {
+			ttl = min(ttl, time.Until(entry.ExpiresAt.Time))
+		}
+
+		if data, err := json.Marshal(entry); err == nil && ttl > 0 {
+			keys := []string{cacheKey(envID, key), versionKey(envID, key)}
+
+			if err := cacheScript.Run(ctx, rediscache.Client(), keys, version, data, ttl.Milliseconds()).Err(); err != nil && !errors.Is(err, redis.Nil) {
+				slog.Errorf("cannot cache kv entry: %v", err)
+			}
+		}
+	}
+
+	return entry, nil
+}
+
+// List returns the entries whose key starts with the given prefix.
+func List(ctx context.Context, envID types.ID, prefix, afterKey string, limit int) ([]*Entry, error) {
+	if limit <= 0 || limit > MaxListLimit {
+		limit = MaxListLimit
+	}
+
+	return NewStore().Entries(ctx, envID, prefix, afterKey, limit)
+}
+
+// Set writes the given key after checking the quotas of the environment.
+func Set(ctx context.Context, args SetArgs) (*Entry, error) {
+	if err := ValidateKey(args.Key); err != nil {
+		return nil, err
+	}
+
+	if args.TTL < 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	if err := checkQuota(ctx, args.EnvID, args.Key, args.Value); err != nil {
+		return nil, err
+	}
+
+	entry, err := NewStore().Set(ctx, args)
+
+	if err == nil {
+		invalidate(ctx, args.EnvID, args.Key)
+	}
+
+	return entry, err
+}
+
+// Increment adds the delta to the integer value of the key.
+func Increment(ctx context.Context, args IncrementArgs) (*Entry, error) {
+	if err := ValidateKey(args.Key); err != nil {
+		return nil, err
+	}
+
+	if args.TTL < 0 {
+		return nil, ErrInvalidTTL
+	}
+
+	// Integer values are at most 20 bytes long.
+	if err := checkQuota(ctx, args.EnvID, args.Key, "-9223372036854775808"); err != nil {
+		return nil, err
+	}
+
+	entry, err := NewStore().Increment(ctx, args)
+
+	if err == nil {
+		invalidate(ctx, args.EnvID, args.Key)
+	}
+
+	return entry, err
+}
+
+// Delete removes the key. It returns false when the key does not exist.
+func Delete(ctx context.Context, envID types.ID, key string) (bool, error) {
+	deleted, err := NewStore().Delete(ctx, envID, key)
+
+	if err == nil {
+		invalidate(ctx, envID, key)
+	}
+
+	return deleted, err
+}
+
+// checkQuota returns an error when writing the value to the key would exceed
+// the quotas of the environment. Quotas are checked before the write, so
+// concurrent writes may exceed them slightly.
+func checkQuota(ctx context.Context, envID types.ID, key, value string) error {
+	cnf := admin.MustConfig().KV()
+
+	if len(value) > cnf.MaxValueSize {
+		return ErrValueTooLarge
+	}
+
+	usage, err := NewStore().Usage(ctx, envID, key)
+
+	if err != nil {
+		return err
+	}
+
+	if usage.Keys+1 > cnf.MaxKeys {
+		return ErrTooManyKeys
+	}
+
+	if usage.Size+int64(len(value)) > int64(cnf.MaxTotalSize) {
+		return ErrStorageFull
+	}
+
+	return nil
+}
+
+// invalidate removes the cached entry after a write is committed. The version of
+// the key is bumped as well, so that reads that started before the write do not
+// cache the previous value again.
+func invalidate(ctx context.Context, envID types.ID, key string) {
+	if !admin.MustConfig().KV().RedisCache {
+		return
+	}
+
+	_, err := rediscache.Client().TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Incr(ctx, versionKey(envID, key))
+		pipe.Expire(ctx, versionKey(envID, key), versionTTL)
+		pipe.Del(ctx, cacheKey(envID, key))
+		return nil
+	})
+
+	if err != nil {
+		slog.Errorf("cannot invalidate kv entry: %v", err)
+	}
+}
@@ -0,0 +1,106 @@
+package kvstore
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// MaxKeyLength is the maximum length of a key.
+const MaxKeyLength = 512
+
+// MaxListLimit is the maximum number of entries returned by a list request.
+const MaxListLimit = 1000
+
+// TokenService is the service of the function tokens that access the store.
+const TokenService = "kv"
+
+var (
+	ErrMissingKey      = errors.New("Key is required.")
+	ErrKeyTooLong      = fmt.Errorf("Key cannot be longer than %d characters.", MaxKeyLength)
+	ErrInvalidTTL      = errors.New("TTL cannot be negative.")
+	ErrVersionMismatch = errors.New("Key has been modified or does not match the expected version.")
+	ErrNotANumber      = errors.New("Value is not an integer.")
+	ErrValueTooLarge   = errors.New("Value exceeds the maximum value size.")
+	ErrTooManyKeys     = errors.New("Environment reached the maximum number of keys.")
+	ErrStorageFull     = errors.New("Environment reached the maximum storage size.")
+)
+
+// Entry is a key-value pair of an environment. The version is incremented on each
+// write, and is used to update a key only when it has not changed in the meantime.
+type Entry struct {
+	Key       string     `json:"key"`
+	Value     string     `json:"value"`
+	Version   int64      `json:"version"`
+	ExpiresAt utils.Unix `json:"expiresAt"`
+	CreatedAt utils.Unix `json:"createdAt"`
+	UpdatedAt utils.Unix `json:"updatedAt"`
+}
+
+// SetArgs are the arguments to write a key.
+type SetArgs struct {
+	EnvID types.ID
+	Key   string
+	Value string
+
+	// TTL is the number of seconds after which the key expires.
+	// Zero means the key does not expire.
+	TTL int
+
+	// IfVersion writes the key only when its current version matches. Zero
+	// writes the key only when it does not exist. Nil writes the key regardless.
+	IfVersion *int64
+}
+
+// IncrementArgs are the arguments to increment a key.
+type IncrementArgs struct {
+	EnvID types.ID
+	Key   string
+	Delta int64
+
+	// TTL is the number of seconds after which the key expires, when the
+	// increment creates the key. Existing keys keep their expiry date.
+	TTL int
+}
+
+// Usage is the number of keys and the size of the values of an environment.
+type Usage struct {
+	Keys int   `json:"keys"`
+	Size int64 `json:"size"`
+}
+
+// ValidateKey returns an error when the key cannot be stored.
+func ValidateKey(key string) error {
+	if key == "" {
+		return ErrMissingKey
+	}
+
+	if len(key) > MaxKeyLength {
+		return ErrKeyTooLong
+	}
+
+	return nil
+}
+
+// expiresAt returns the expiry date for the given ttl.
+func expiresAt(ttl int) utils.Unix {
+	if ttl <= 0 {
+		return utils.Unix{}
+	}
+
+	return utils.UnixFrom(time.Now().Add(time.Duration(ttl) * time.Second))
+}
+
+// FunctionContext returns the context that functions receive to access
+// the store of their own environment.
+func FunctionContext(envID types.ID) map[string]any {
+	return map[string]any{
+		"endpoint": admin.MustConfig().ApiURL("/v1/kv"),
+		"token":    apikey.FunctionToken(TokenService, envID, time.Now().Add(apikey.FunctionTokenTTL)),
+	}
+}
@@ -0,0 +1,302 @@
+package kvstore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/database"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// live matches the entries that have not expired yet. Expired entries are
+// treated as missing until they are removed by the workerserver.
+const live = "(kv.expires_at IS NULL OR kv.expires_at > timezone('utc', now()))"
+
+const returning = "kv.kv_key, kv.kv_value, kv.kv_version, kv.expires_at, kv.created_at, kv.updated_at"
+
+var stmts = struct {
+	selectEntry   string
+	selectEntries string
+	selectUsage   string
+	upsertEntry   string
+	insertEntry   string
+	updateEntry   string
+	incrEntry     string
+	deleteEntry   string
+	removeExpired string
+}{
+	selectEntry: `
+		SELECT
+			` + returning + `
+		FROM
+			kv_entries kv
+		WHERE
+			kv.env_id = $1 AND
+			kv.kv_key = $2 AND
+			` + live + `;
+	`,
+
+	// The prefix is matched with a range, so that the (env_id, kv_key) index can be
+	// used. Keys are compared byte by byte, since a range does not match a prefix
+	// reliably with the collation of the database.
+	selectEntries: `
+		SELECT
+			` + returning + `
+		FROM
+			kv_entries kv
+		WHERE
+			kv.env_id = $1 AND
+			kv.kv_key COLLATE "C" >= $2 AND
+			kv.kv_key COLLATE "C" < $2 || chr(1114111) AND
+			kv.kv_key COLLATE "C" > $3 AND
+			` + live + `
+		ORDER BY
+			kv.kv_key COLLATE "C" ASC
+		LIMIT
+			$4;
+	`,
+
+	selectUsage: `
+		SELECT
+			COUNT(*), COALESCE(SUM(octet_length(kv.kv_value)), 0)
+		FROM
+			kv_entries kv
+		WHERE
+			kv.env_id = $1 AND
+			kv.kv_key <> $2 AND
+			` + live + `;
+	`,
+
+	upsertEntry: `
+		INSERT INTO kv_entries AS kv
+			(env_id, kv_key, kv_value, expires_at)
+		VALUES
+			($1, $2, $3, $4)
+		ON CONFLICT (env_id, kv_key) DO UPDATE SET
+			kv_value = EXCLUDED.kv_value,
+			kv_version = CASE WHEN ` + live + ` THEN kv.kv_version + 1 ELSE 1 END,
+			expires_at = EXCLUDED.expires_at,
+			created_at = CASE WHEN ` + live + ` THEN kv.created_at ELSE timezone('utc', now()) END,
+			updated_at = timezone('utc', now())
+		RETURNING
+			` + returning + `;
+	`,
+
+	// Same as upsertEntry, but replaces only expired entries.
+	insertEntry: `
+		INSERT INTO kv_entries AS kv
+			(env_id, kv_key, kv_value, expires_at)
+		VALUES
+			($1, $2, $3, $4)
+		ON CONFLICT (env_id, kv_key) DO UPDATE SET
+			kv_value = EXCLUDED.kv_value,
+			kv_version = 1,
+			expires_at = EXCLUDED.expires_at,
+			created_at = timezone('utc', now()),
+			updated_at = NULL
+		WHERE
+			NOT ` + live + `
+		RETURNING
+			` + returning + `;
+	`,
+
+	updateEntry: `
+		UPDATE
+			kv_entries kv
+		SET
+			kv_value = $3,
+			kv_version = kv.kv_version + 1,
+			expires_at = $4,
+			updated_at = timezone('utc', now())
+		WHERE
+			kv.env_id = $1 AND
+			kv.kv_key = $2 AND
+			kv.kv_version = $5 AND
+			` + live + `
+		RETURNING
+			` + returning + `;
+	`,
+
+	incrEntry: `
+		INSERT INTO kv_entries AS kv
+			(env_id, kv_key, kv_value, expires_at)
+		VALUES
+			($1, $2, ($3::bigint)::text, $4)
+		ON CONFLICT (env_id, kv_key) DO UPDATE SET
+			kv_value = ((CASE WHEN ` + live + ` THEN kv.kv_value ELSE '0' END)::bigint + $3::bigint)::text,
+			kv_version = CASE WHEN ` + live + ` THEN kv.kv_version + 1 ELSE 1 END,
+			expires_at = CASE WHEN ` + live + ` THEN kv.expires_at ELSE EXCLUDED.expires_at END,
+			created_at = CASE WHEN ` + live + ` THEN kv.created_at ELSE timezone('utc', now()) END,
+			updated_at = timezone('utc', now())
+		RETURNING
+			` + returning + `;
+	`,
+
+	deleteEntry: `
+		DELETE FROM
+			kv_entries kv
+		WHERE
+			kv.env_id = $1 AND
+			kv.kv_key = $2 AND
+			` + live + `;
+	`,
+
+	removeExpired: `
+		DELETE FROM
+			kv_entries
+		WHERE
+			expires_at <= timezone('utc', now());
+	`,
+}
+
+// Store handles the key-value entries of the environments.
+type Store struct {
+	*database.Store
+}
+
+// NewStore returns a new store instance.
+func NewStore() *Store {
+	return &Store{
+		Store: database.NewStore(),
+	}
+}
+
+// Entry returns the entry with the given key, or nil when the key does not exist.
+func (s *Store) Entry(ctx context.Context, envID types.ID, key string) (*Entry, error) {
+	row, err := s.QueryRow(ctx, stmts.selectEntry, envID, key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEntry(row)
+}
+
+// Entries returns the entries whose key starts with the given prefix, sorted by key.
+// Entries are returned after the given key, which allows paginating through them.
+func (s *Store) Entries(ctx context.Context, envID types.ID, prefix, afterKey string, limit int) ([]*Entry, error) {
+	rows, err := s.Query(ctx, stmts.selectEntries, envID, prefix, afterKey, limit)
+
+	if rows == nil || err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	entries := []*Entry{}
+
+	for rows.Next() {
+		entry, err := scanEntry(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+// Usage returns the number of keys and the size of the values of the environment,
+// excluding the given key.
+func (s *Store) Usage(ctx context.Context, envID types.ID, excludeKey string) (Usage, error) {
+	usage := Usage{}
+	row, err := s.QueryRow(ctx, stmts.selectUsage, envID, excludeKey)
+
+	if err != nil {
+		return usage, err
+	}
+
+	err = row.Scan(&usage.Keys, &usage.Size)
+	return usage, err
+}
+
+// Set writes the given key. When the key cannot be written because of the
+// version condition, ErrVersionMismatch is returned.
+func (s *Store) Set(ctx context.Context, args SetArgs) (*Entry, error) {
+	var row *sql.Row
+	var err error
+
+	exp := expiresAt(args.TTL)
+
+	switch {
+	case args.IfVersion == nil:
+		row, err = s.QueryRow(ctx, stmts.upsertEntry, args.EnvID, args.Key, args.Value, exp)
+	case *args.IfVersion == 0:
+		row, err = s.QueryRow(ctx, stmts.insertEntry, args.EnvID, args.Key, args.Value, exp)
+	default:
+		row, err = s.QueryRow(ctx, stmts.updateEntry, args.EnvID, args.Key, args.Value, exp, *args.IfVersion)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := scanEntry(row)
+
+	if err == nil && entry == nil {
+		return nil, ErrVersionMismatch
+	}
+
+	return entry, err
+}
+
+// Increment atomically adds the delta to the integer value of the key. Keys
+// that do not exist are created with the delta as value.
+func (s *Store) Increment(ctx context.Context, args IncrementArgs) (*Entry, error) {
+	row, err := s.QueryRow(ctx, stmts.incrEntry, args.EnvID, args.Key, args.Delta, expiresAt(args.TTL))
+
+	if err != nil {
+		return nil, err
+	}
+
+	entry, err := scanEntry(row)
+
+	if database.IsInvalidNumber(err) {
+		return nil, ErrNotANumber
+	}
+
+	return entry, err
+}
+
+// Delete removes the key. It returns false when the key does not exist.
+func (s *Store) Delete(ctx context.Context, envID types.ID, key string) (bool, error) {
+	result, err := s.Exec(ctx, stmts.deleteEntry, envID, key)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := result.RowsAffected()
+	return affected > 0, err
+}
+
+// RemoveExpired removes the entries that have expired.
+func (s *Store) RemoveExpired(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmts.removeExpired)
+	return err
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+func scanEntry(row scanner) (*Entry, error) {
+	entry := &Entry{}
+	err := row.Scan(
+		&entry.Key, &entry.Value, &entry.Version,
+		&entry.ExpiresAt, &entry.CreatedAt, &entry.UpdatedAt,
+	)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
@@ -0,0 +1,28 @@
+package kvstorehandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// HandlerKVDelete removes the given `key`.
+func HandlerKVDelete(req *app.RequestContext) *shttp.Response {
+	key := req.Query().Get("key")
+
+	if err := kvstore.ValidateKey(key); err != nil {
+		return errorResponse(err)
+	}
+
+	deleted, err := kvstore.Delete(req.Context(), req.EnvID, key)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	if !deleted {
+		return shttp.NotFound()
+	}
+
+	return shttp.OK()
+}
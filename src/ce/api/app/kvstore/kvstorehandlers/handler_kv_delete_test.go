@@ -0,0 +1,52 @@
+package kvstorehandlers_test
+
+import (
+	"net/http"
+	"testing"
+
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerKVDeleteSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerKVDeleteSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerKVDeleteSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerKVDeleteSuite) Test_Success() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env)
+
+	for _, status := range []int{http.StatusOK, http.StatusNotFound} {
+		response := shttptest.RequestWithHeaders(
+			shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+			shttp.MethodDelete,
+			"/v1/kv?key=my-key",
+			nil,
+			map[string]string{
+				"Authorization": key.Value,
+			},
+		)
+
+		s.Equal(status, response.Code)
+	}
+}
+
+func TestHandlerKVDelete(t *testing.T) {
+	suite.Run(t, &HandlerKVDeleteSuite{})
+}
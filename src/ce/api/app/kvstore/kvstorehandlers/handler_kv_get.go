@@ -0,0 +1,57 @@
+package kvstorehandlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// HandlerKVGet returns the entry with the given `key`. When no key is provided,
+// it lists the entries whose key starts with the `prefix` query parameter. Lists
+// are paginated with the `after` and `limit` query parameters.
+func HandlerKVGet(req *app.RequestContext) *shttp.Response {
+	query := req.Query()
+
+	if key := query.Get("key"); key != "" {
+		entry, err := kvstore.Get(req.Context(), req.EnvID, key)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if entry == nil {
+			return shttp.NotFound()
+		}
+
+		return &shttp.Response{
+			Status: http.StatusOK,
+			Data:   map[string]any{"entry": entry},
+		}
+	}
+
+	limit, _ := strconv.Atoi(query.Get("limit"))
+
+	if limit <= 0 || limit > kvstore.MaxListLimit {
+		limit = kvstore.MaxListLimit
+	}
+
+	entries, err := kvstore.List(req.Context(), req.EnvID, query.Get("prefix"), query.Get("after"), limit)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	data := map[string]any{"entries": entries}
+
+	if len(entries) == limit {
+		data["after"] = entries[len(entries)-1].Key
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data:   data,
+	}
+}
@@ -0,0 +1,120 @@
+package kvstorehandlers_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerKVGetSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerKVGetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerKVGetSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerKVGetSuite) get(url, token string) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodGet,
+		url,
+		nil,
+		map[string]string{
+			"Authorization": token,
+		},
+	)
+}
+
+func (s *HandlerKVGetSuite) Test_Single_FunctionToken() {
+	env := s.MockEnv(nil)
+	entry := s.MockKVEntry(env, map[string]any{"Key": "feature:dark-mode", "Value": "on"})
+	token := "Bearer " + apikey.FunctionToken(kvstore.TokenService, env.ID, time.Now().Add(time.Minute))
+
+	response := s.get("/v1/kv?key=feature:dark-mode", token)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.JSONEq(fmt.Sprintf(`{
+		"entry": {
+			"key": "feature:dark-mode",
+			"value": "on",
+			"version": 1,
+			"expiresAt": null,
+			"createdAt": %d,
+			"updatedAt": null
+		}
+	}`, entry.CreatedAt.Unix()), response.String())
+
+	// Tokens of other services are rejected
+	token = "Bearer " + apikey.FunctionToken("jobs", env.ID, time.Now().Add(time.Minute))
+	s.Equal(http.StatusForbidden, s.get("/v1/kv?key=feature:dark-mode", token).Code)
+}
+
+func (s *HandlerKVGetSuite) Test_Single_Expired() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env, map[string]any{
+		"ExpiresAt": utils.UnixFrom(time.Now().Add(-time.Minute)),
+	})
+
+	response := s.get("/v1/kv?key=my-key", key.Value)
+	s.Equal(http.StatusNotFound, response.Code)
+}
+
+func (s *HandlerKVGetSuite) Test_List_Prefix() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env, map[string]any{"Key": "rate:b"})
+	s.MockKVEntry(env, map[string]any{"Key": "rate:a"})
+	s.MockKVEntry(env, map[string]any{"Key": "rate:c"})
+	s.MockKVEntry(env, map[string]any{"Key": "other"})
+
+	// Keys next to the prefix range are not returned
+	s.MockKVEntry(env, map[string]any{"Key": "rate"})
+	s.MockKVEntry(env, map[string]any{"Key": "rate;"})
+	s.MockKVEntry(env, map[string]any{"Key": "rate:expired", "ExpiresAt": utils.UnixFrom(time.Now().Add(-time.Minute))})
+
+	// A key of another environment is never returned
+	s.MockKVEntry(s.MockEnv(nil, map[string]any{"Name": "staging"}), map[string]any{"Key": "rate:0"})
+
+	response := s.get("/v1/kv?prefix=rate:&limit=2", key.Value)
+	s.Equal(http.StatusOK, response.Code)
+
+	data := map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Len(data["entries"], 2)
+	s.Equal("rate:a", data["entries"].([]any)[0].(map[string]any)["key"])
+	s.Equal("rate:b", data["after"])
+
+	response = s.get("/v1/kv?prefix=rate:&limit=2&after=rate:b", key.Value)
+	s.Equal(http.StatusOK, response.Code)
+
+	data = map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	s.Len(data["entries"], 1)
+	s.Equal("rate:c", data["entries"].([]any)[0].(map[string]any)["key"])
+	s.Nil(data["after"])
+}
+
+func TestHandlerKVGet(t *testing.T) {
+	suite.Run(t, &HandlerKVGetSuite{})
+}
@@ -0,0 +1,52 @@
+package kvstorehandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type KVIncrementRequest struct {
+	Key string `json:"key"`
+
+	// Delta is the number to add to the value. Defaults to 1.
+	Delta *int64 `json:"delta"`
+
+	// TTL is the number of seconds after which the key expires,
+	// when the increment creates the key.
+	TTL int `json:"ttl"`
+}
+
+// HandlerKVIncrement atomically adds the delta to the integer value of the key.
+// Keys that do not exist are created.
+func HandlerKVIncrement(req *app.RequestContext) *shttp.Response {
+	data := &KVIncrementRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	delta := int64(1)
+
+	if data.Delta != nil {
+		delta = *data.Delta
+	}
+
+	entry, err := kvstore.Increment(req.Context(), kvstore.IncrementArgs{
+		EnvID: req.EnvID,
+		Key:   data.Key,
+		Delta: delta,
+		TTL:   data.TTL,
+	})
+
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data:   map[string]any{"entry": entry},
+	}
+}
@@ -0,0 +1,74 @@
+package kvstorehandlers_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerKVIncrementSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerKVIncrementSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerKVIncrementSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerKVIncrementSuite) increment(token string, body map[string]any) (shttptest.Response, map[string]any) {
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/v1/kv/increment",
+		body,
+		map[string]string{
+			"Authorization": token,
+		},
+	)
+
+	data := map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	return response, data
+}
+
+func (s *HandlerKVIncrementSuite) Test_Success() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	response, data := s.increment(key.Value, map[string]any{"key": "visits"})
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal("1", data["entry"].(map[string]any)["value"])
+	s.Equal(float64(1), data["entry"].(map[string]any)["version"])
+
+	response, data = s.increment(key.Value, map[string]any{"key": "visits", "delta": -5})
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal("-4", data["entry"].(map[string]any)["value"])
+	s.Equal(float64(2), data["entry"].(map[string]any)["version"])
+}
+
+func (s *HandlerKVIncrementSuite) Test_NotANumber() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env, map[string]any{"Key": "visits", "Value": "many"})
+
+	response, data := s.increment(key.Value, map[string]any{"key": "visits"})
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.Equal("Value is not an integer.", data["error"])
+}
+
+func TestHandlerKVIncrement(t *testing.T) {
+	suite.Run(t, &HandlerKVIncrementSuite{})
+}
@@ -0,0 +1,75 @@
+package kvstorehandlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type KVSetRequest struct {
+	Key   string `json:"key"`
+	Value string `json:"value"`
+
+	// TTL is the number of seconds after which the key expires.
+	TTL int `json:"ttl"`
+
+	// IfVersion writes the key only when its current version matches.
+	// Use 0 to write the key only when it does not exist.
+	IfVersion *int64 `json:"ifVersion"`
+}
+
+// HandlerKVSet writes the given key. When `ifVersion` is provided, the key is
+// written only when it has not been modified since that version was read.
+func HandlerKVSet(req *app.RequestContext) *shttp.Response {
+	data := &KVSetRequest{}
+
+	if err := req.Post(data); err != nil {
+		return shttp.Error(err)
+	}
+
+	entry, err := kvstore.Set(req.Context(), kvstore.SetArgs{
+		EnvID:     req.EnvID,
+		Key:       data.Key,
+		Value:     data.Value,
+		TTL:       data.TTL,
+		IfVersion: data.IfVersion,
+	})
+
+	if err != nil {
+		return errorResponse(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data:   map[string]any{"entry": entry},
+	}
+}
+
+// errorResponse maps the errors of the store to their status codes.
+func errorResponse(err error) *shttp.Response {
+	status := http.StatusInternalServerError
+
+	switch {
+	case errors.Is(err, kvstore.ErrMissingKey),
+		errors.Is(err, kvstore.ErrKeyTooLong),
+		errors.Is(err, kvstore.ErrInvalidTTL),
+		errors.Is(err, kvstore.ErrNotANumber):
+		status = http.StatusBadRequest
+	case errors.Is(err, kvstore.ErrVersionMismatch):
+		status = http.StatusConflict
+	case errors.Is(err, kvstore.ErrValueTooLarge),
+		errors.Is(err, kvstore.ErrTooManyKeys),
+		errors.Is(err, kvstore.ErrStorageFull):
+		status = http.StatusRequestEntityTooLarge
+	default:
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: status,
+		Data:   map[string]string{"error": err.Error()},
+	}
+}
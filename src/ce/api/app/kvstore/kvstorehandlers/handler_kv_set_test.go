@@ -0,0 +1,128 @@
+package kvstorehandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerKVSetSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerKVSetSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerKVSetSuite) AfterTest(_, _ string) {
+	cnf := admin.MustConfig()
+	cnf.KVConfig = nil
+	s.NoError(admin.Store().UpsertConfig(context.Background(), cnf))
+	s.conn.CloseTx()
+}
+
+func (s *HandlerKVSetSuite) put(token string, body map[string]any) (shttptest.Response, map[string]any) {
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/v1/kv",
+		body,
+		map[string]string{
+			"Authorization": token,
+		},
+	)
+
+	data := map[string]any{}
+	s.NoError(json.Unmarshal(response.Byte(), &data))
+	return response, data
+}
+
+func (s *HandlerKVSetSuite) Test_Success() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+
+	response, data := s.put(key.Value, map[string]any{"key": "cache:users", "value": `[1,2]`, "ttl": 60})
+	s.Equal(http.StatusOK, response.Code)
+
+	entry := data["entry"].(map[string]any)
+	s.Equal("cache:users", entry["key"])
+	s.Equal("[1,2]", entry["value"])
+	s.Equal(float64(1), entry["version"])
+	s.NotNil(entry["expiresAt"])
+
+	response, data = s.put(key.Value, map[string]any{"key": "cache:users", "value": `[1,2,3]`})
+	s.Equal(http.StatusOK, response.Code)
+
+	entry = data["entry"].(map[string]any)
+	s.Equal("[1,2,3]", entry["value"])
+	s.Equal(float64(2), entry["version"])
+	s.Nil(entry["expiresAt"])
+}
+
+func (s *HandlerKVSetSuite) Test_CompareAndSet() {
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env, map[string]any{"Key": "toggle", "Version": int64(3)})
+
+	// Create-only writes fail for existing keys
+	response, data := s.put(key.Value, map[string]any{"key": "toggle", "value": "on", "ifVersion": 0})
+	s.Equal(http.StatusConflict, response.Code)
+	s.Equal("Key has been modified or does not match the expected version.", data["error"])
+
+	response, _ = s.put(key.Value, map[string]any{"key": "toggle", "value": "on", "ifVersion": 2})
+	s.Equal(http.StatusConflict, response.Code)
+
+	response, data = s.put(key.Value, map[string]any{"key": "toggle", "value": "on", "ifVersion": 3})
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal(float64(4), data["entry"].(map[string]any)["version"])
+
+	response, data = s.put(key.Value, map[string]any{"key": "new-toggle", "value": "off", "ifVersion": 0})
+	s.Equal(http.StatusOK, response.Code)
+	s.Equal(float64(1), data["entry"].(map[string]any)["version"])
+}
+
+func (s *HandlerKVSetSuite) Test_Quotas() {
+	cnf := admin.MustConfig()
+	cnf.KVConfig = &admin.KVConfig{MaxKeys: 1, MaxValueSize: 5}
+	s.NoError(admin.Store().UpsertConfig(context.Background(), cnf))
+
+	env := s.MockEnv(nil)
+	key := s.MockAPIKey(nil, env)
+	s.MockKVEntry(env, map[string]any{"Key": "first", "Value": "1"})
+
+	response, data := s.put(key.Value, map[string]any{"key": "first", "value": "too-long"})
+	s.Equal(http.StatusRequestEntityTooLarge, response.Code)
+	s.Equal("Value exceeds the maximum value size.", data["error"])
+
+	response, data = s.put(key.Value, map[string]any{"key": "second", "value": "2"})
+	s.Equal(http.StatusRequestEntityTooLarge, response.Code)
+	s.Equal("Environment reached the maximum number of keys.", data["error"])
+
+	// Overwriting an existing key does not count as a new key
+	response, _ = s.put(key.Value, map[string]any{"key": "first", "value": "2"})
+	s.Equal(http.StatusOK, response.Code)
+}
+
+func (s *HandlerKVSetSuite) Test_InvalidKey() {
+	key := s.MockAPIKey(nil, nil)
+
+	response, data := s.put(key.Value, map[string]any{"value": "1"})
+	s.Equal(http.StatusBadRequest, response.Code)
+	s.Equal("Key is required.", data["error"])
+}
+
+func TestHandlerKVSet(t *testing.T) {
+	suite.Run(t, &HandlerKVSetSuite{})
+}
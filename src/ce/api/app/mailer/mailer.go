@@ -0,0 +1,46 @@
+package mailer
+
+import (
+	"fmt"
+	"net/smtp"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// SendMail sends the email through the SMTP server.
+var SendMail = smtp.SendMail
+
+// Send sends an html email to the given recipients using the mailer configuration.
+// When the email has no sender, the sender of the configuration or its username is used.
+func Send(config *Config, email Email, to []string) error {
+	from := utils.GetString(email.From, config.From, config.Username)
+	fromHeader := fmt.Sprintf("From: %s\n", from)
+	subject := fmt.Sprintf("Subject: %s\n", email.Subject)
+	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
+	body := fmt.Sprintf("<html><body>%s</body></html>", email.Body)
+	msg := []byte(fromHeader + subject + mime + body)
+
+	addr := config.Host + ":" + utils.GetString(config.Port, "587")
+	auth := smtp.PlainAuth("", config.Username, config.Password, config.Host)
+
+	return SendMail(addr, auth, config.Username, to, msg)
+}
+
+// InstanceConfig returns the SMTP configuration of the instance, which is used
+// to send platform emails to the users. It returns nil when SMTP is not enabled.
+func InstanceConfig() *Config {
+	cnf := admin.MustConfig()
+
+	if !cnf.IsSMTPEnabled() {
+		return nil
+	}
+
+	return &Config{
+		Host:     cnf.SMTPConfig.Host,
+		Port:     cnf.SMTPConfig.Port,
+		Username: cnf.SMTPConfig.Username,
+		Password: cnf.SMTPConfig.Password,
+		From:     cnf.SMTPConfig.From,
+	}
+}
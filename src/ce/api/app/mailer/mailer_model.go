@@ -11,6 +11,7 @@ type Config struct {
 	Port     string   `json:"port"`
 	Username string   `json:"username"`
 	Password string   `json:"password"`
+	From     string   `json:"-"` // The default sender. Set only for the instance configuration.
 }
 
 type Email struct {
@@ -1,9 +1,7 @@
 package mailerhandlers
 
 import (
-	"fmt"
 	"net/http"
-	"net/smtp"
 	"strings"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
@@ -19,8 +17,6 @@ type RequestData struct {
 	Subject string `json:"subject"`
 }
 
-var SendMail = smtp.SendMail
-
 func HandlerMail(req *app.RequestContext) *shttp.Response {
 	data := RequestData{}
 
@@ -69,18 +65,16 @@ func HandlerMail(req *app.RequestContext) *shttp.Response {
 		to[i] = strings.TrimSpace(to[i])
 	}
 
-	fromHeader := fmt.Sprintf("From: %s\n", utils.GetString(data.From, config.Username))
-	subject := fmt.Sprintf("Subject: %s\n", data.Subject)
-	mime := "MIME-version: 1.0;\nContent-Type: text/html; charset=\"UTF-8\";\n\n"
-	body := fmt.Sprintf("<html><body>%s</body></html>", data.Body)
-	msg := []byte(fromHeader + subject + mime + body)
-
-	// Combine the host and port
-	addr := config.Host + ":" + utils.GetString(config.Port, "587")
-	auth := smtp.PlainAuth("", config.Username, config.Password, config.Host)
+	email := mailer.Email{
+		EnvID:   req.EnvID,
+		From:    utils.GetString(data.From, config.Username),
+		To:      data.To,
+		Body:    data.Body,
+		Subject: data.Subject,
+	}
 
 	// Send the email
-	if err = SendMail(addr, auth, config.Username, to, msg); err != nil {
+	if err = mailer.Send(config, email, to); err != nil {
 		return &shttp.Response{
 			Status: http.StatusInternalServerError,
 			Data: map[string]string{
@@ -90,14 +84,6 @@ func HandlerMail(req *app.RequestContext) *shttp.Response {
 	}
 
 	// Store the sent email in the database
-	email := mailer.Email{
-		EnvID:   req.EnvID,
-		From:    utils.GetString(data.From, config.Username),
-		To:      data.To,
-		Body:    data.Body,
-		Subject: data.Subject,
-	}
-
 	if err := store.InsertEmail(req.Context(), email); err != nil {
 		return shttp.Error(err)
 	}
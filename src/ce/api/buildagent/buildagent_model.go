@@ -0,0 +1,105 @@
+package buildagent
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+const (
+	JobStatusPending   = "pending"
+	JobStatusClaimed   = "claimed"
+	JobStatusCompleted = "completed"
+	JobStatusCancelled = "cancelled"
+)
+
+// HeartbeatInterval is the interval at which agents are expected to send heartbeats.
+const HeartbeatInterval = 15 * time.Second
+
+// HeartbeatTimeout is the duration after which an agent is considered lost.
+// The jobs of lost agents are put back to the queue.
+const HeartbeatTimeout = 4 * HeartbeatInterval
+
+// MaxJobAttempts is the number of times a job is handed to an agent before
+// the deployment is marked as failed.
+const MaxJobAttempts = 3
+
+var ErrInvalidLabel = errors.New("Labels can only contain lowercase letters, numbers, dots, underscores and hyphens.")
+
+var labelRegex = regexp.MustCompile(`^[a-z0-9._-]+$`)
+
+// Agent is a remote machine that pulls deployments from the API and builds them.
+type Agent struct {
+	ID              types.ID   `json:"id"`
+	Name            string     `json:"name"`
+	Labels          []string   `json:"labels"`
+	Version         string     `json:"version"`
+	Token           string     `json:"token,omitempty"` // Token is only returned when the agent is created.
+	LastHeartbeatAt utils.Unix `json:"lastHeartbeatAt"`
+	CreatedAt       utils.Unix `json:"createdAt"`
+}
+
+// IsOnline returns true when the agent has sent a heartbeat recently.
+func (a *Agent) IsOnline() bool {
+	return a.LastHeartbeatAt.Valid && time.Since(a.LastHeartbeatAt.Time) < HeartbeatTimeout
+}
+
+// Job is a deployment that waits to be built, or is being built, by an agent.
+type Job struct {
+	DeploymentID   types.ID `json:"deploymentId"`
+	AgentID        types.ID `json:"agentId,omitempty"`
+	RequiredLabels []string `json:"requiredLabels"`
+	Payload        string   `json:"-"` // Payload is the encrypted deployment message.
+	Status         string   `json:"status"`
+
+	// Token authenticates the runner that builds the job while the job is
+	// claimed. It is only set when the job is claimed, and only its hash is stored.
+	Token string `json:"-"`
+}
+
+// GenerateToken returns a new agent token.
+func GenerateToken() string {
+	return fmt.Sprintf("SKA_%s", utils.RandomToken(62))
+}
+
+// GenerateJobToken returns a new token for a claimed job.
+func GenerateJobToken() string {
+	return utils.RandomToken(64)
+}
+
+// HashToken returns the hash of the token. Only hashes are stored in the database.
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// NormalizeLabels trims, lowercases and deduplicates the given labels.
+// It returns an error when a label contains invalid characters.
+func NormalizeLabels(labels []string) ([]string, error) {
+	normalized := []string{}
+	seen := map[string]bool{}
+
+	for _, label := range labels {
+		label = strings.ToLower(strings.TrimSpace(label))
+
+		if label == "" || seen[label] {
+			continue
+		}
+
+		if !labelRegex.MatchString(label) {
+			return nil, ErrInvalidLabel
+		}
+
+		seen[label] = true
+		normalized = append(normalized, label)
+	}
+
+	return normalized, nil
+}
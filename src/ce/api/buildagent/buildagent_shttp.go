@@ -0,0 +1,41 @@
+package buildagent
+
+import (
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// RequestContext is the request context of endpoints that are called by agents.
+type RequestContext struct {
+	*shttp.RequestContext
+
+	Agent *Agent
+}
+
+// WithAgent authenticates the agent through the agent token
+// provided in the Authorization header.
+func WithAgent(handler func(*RequestContext) *shttp.Response) shttp.RequestFunc {
+	return func(req *shttp.RequestContext) *shttp.Response {
+		token := strings.TrimSpace(strings.Replace(req.Headers().Get("Authorization"), "Bearer ", "", 1))
+
+		if !strings.HasPrefix(token, "SKA_") {
+			return shttp.NotAllowed()
+		}
+
+		agent, err := NewStore().AgentByToken(req.Context(), token)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if agent == nil {
+			return shttp.NotAllowed()
+		}
+
+		return handler(&RequestContext{
+			RequestContext: req,
+			Agent:          agent,
+		})
+	}
+}
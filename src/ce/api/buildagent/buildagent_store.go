@@ -0,0 +1,427 @@
+package buildagent
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+
+	"github.com/lib/pq"
+	"github.com/stormkit-io/stormkit-io/src/lib/database"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+var agentColumns = `
+	agent_id, agent_name, agent_labels, agent_version,
+	last_heartbeat_at, created_at
+`
+
+var stmt = struct {
+	insertAgent        string
+	removeAgent        string
+	selectAgents       string
+	selectAgentByToken string
+	updateAgent        string
+	updateHeartbeat    string
+	insertJob          string
+	claimJob           string
+	completeJob        string
+	selectJobClaim     string
+	cancelledJobs      string
+	cancelStoppedJobs  string
+	requeueLostJobs    string
+	failAbandonedJobs  string
+	removeOldJobs      string
+}{
+	insertAgent: `
+		INSERT INTO build_agents (agent_name, agent_labels, token_hash)
+		VALUES ($1, $2, $3)
+		RETURNING agent_id, created_at;
+	`,
+
+	removeAgent: `
+		DELETE FROM build_agents WHERE agent_id = $1;
+	`,
+
+	selectAgents: fmt.Sprintf(`
+		SELECT %s FROM build_agents ORDER BY agent_id ASC;
+	`, agentColumns),
+
+	selectAgentByToken: fmt.Sprintf(`
+		SELECT %s FROM build_agents WHERE token_hash = $1;
+	`, agentColumns),
+
+	updateAgent: `
+		UPDATE build_agents SET
+			agent_labels = $2,
+			agent_version = $3,
+			last_heartbeat_at = NOW() AT TIME ZONE 'UTC'
+		WHERE agent_id = $1;
+	`,
+
+	updateHeartbeat: `
+		WITH agent AS (
+			UPDATE build_agents SET
+				last_heartbeat_at = NOW() AT TIME ZONE 'UTC'
+			WHERE agent_id = $1
+		)
+		UPDATE build_agent_jobs SET
+			heartbeat_at = NOW() AT TIME ZONE 'UTC'
+		WHERE
+			agent_id = $1 AND
+			deployment_id = ANY($2) AND
+			job_status = 'claimed';
+	`,
+
+	insertJob: `
+		INSERT INTO build_agent_jobs (deployment_id, required_labels, deployment_payload, job_status)
+		VALUES ($1, $2, $3, 'pending')
+		ON CONFLICT (deployment_id) DO UPDATE SET
+			required_labels = EXCLUDED.required_labels,
+			deployment_payload = EXCLUDED.deployment_payload,
+			job_status = 'pending',
+			agent_id = NULL,
+			attempts = 0,
+			claimed_at = NULL,
+			heartbeat_at = NULL,
+			claim_token_hash = NULL;
+	`,
+
+	claimJob: `
+		UPDATE build_agent_jobs SET
+			job_status = 'claimed',
+			agent_id = $1,
+			attempts = attempts + 1,
+			claimed_at = NOW() AT TIME ZONE 'UTC',
+			heartbeat_at = NOW() AT TIME ZONE 'UTC',
+			claim_token_hash = $3
+		WHERE deployment_id = (
+			SELECT j.deployment_id FROM build_agent_jobs j
+			INNER JOIN deployments d ON d.deployment_id = j.deployment_id
+			WHERE
+				j.job_status = 'pending' AND
+				j.required_labels <@ $2 AND
+				d.exit_code IS NULL AND
+				d.deleted_at IS NULL
+			ORDER BY j.created_at ASC, j.deployment_id ASC
+			LIMIT 1
+			FOR UPDATE OF j SKIP LOCKED
+		)
+		RETURNING deployment_id, required_labels, deployment_payload;
+	`,
+
+	completeJob: `
+		UPDATE build_agent_jobs SET
+			job_status = 'completed'
+		WHERE
+			deployment_id = $1 AND
+			agent_id = $2 AND
+			job_status = 'claimed';
+	`,
+
+	selectJobClaim: `
+		SELECT job_status, COALESCE(claim_token_hash, '')
+		FROM build_agent_jobs
+		WHERE deployment_id = $1;
+	`,
+
+	cancelledJobs: `
+		SELECT j.deployment_id FROM build_agent_jobs j
+		INNER JOIN deployments d ON d.deployment_id = j.deployment_id
+		WHERE
+			j.agent_id = $1 AND
+			j.deployment_id = ANY($2) AND
+			(j.job_status <> 'claimed' OR d.exit_code IS NOT NULL OR d.deleted_at IS NOT NULL);
+	`,
+
+	cancelStoppedJobs: `
+		UPDATE build_agent_jobs j SET
+			job_status = 'cancelled'
+		FROM deployments d
+		WHERE
+			d.deployment_id = j.deployment_id AND
+			j.job_status = 'pending' AND
+			(d.exit_code IS NOT NULL OR d.deleted_at IS NOT NULL);
+	`,
+
+	requeueLostJobs: `
+		UPDATE build_agent_jobs SET
+			job_status = 'pending',
+			agent_id = NULL,
+			claimed_at = NULL,
+			heartbeat_at = NULL,
+			claim_token_hash = NULL
+		WHERE
+			job_status = 'claimed' AND
+			attempts < $2 AND
+			(agent_id IS NULL OR heartbeat_at < NOW() AT TIME ZONE 'UTC' - $1 * INTERVAL '1 second')
+		RETURNING deployment_id;
+	`,
+
+	failAbandonedJobs: `
+		WITH abandoned AS (
+			UPDATE build_agent_jobs SET
+				job_status = 'cancelled'
+			WHERE
+				job_status = 'claimed' AND
+				attempts >= $2 AND
+				(agent_id IS NULL OR heartbeat_at < NOW() AT TIME ZONE 'UTC' - $1 * INTERVAL '1 second')
+			RETURNING deployment_id
+		)
+		UPDATE deployments SET
+			stopped_at = NOW() AT TIME ZONE 'UTC',
+			exit_code = 1,
+			error = $3
+		WHERE
+			deployment_id IN (SELECT deployment_id FROM abandoned) AND
+			exit_code IS NULL
+		RETURNING deployment_id;
+	`,
+
+	removeOldJobs: `
+		DELETE FROM build_agent_jobs
+		WHERE
+			job_status IN ('completed', 'cancelled') AND
+			created_at < NOW() AT TIME ZONE 'UTC' - INTERVAL '7 days';
+	`,
+}
+
+// Store is the store to handle build agents and their jobs.
+type Store struct {
+	*database.Store
+}
+
+// NewStore returns a store instance.
+func NewStore() *Store {
+	return &Store{database.NewStore()}
+}
+
+func (s *Store) scanAgent(scanner interface{ Scan(...any) error }) (*Agent, error) {
+	a := &Agent{}
+
+	err := scanner.Scan(
+		&a.ID, &a.Name, pq.Array(&a.Labels), &a.Version,
+		&a.LastHeartbeatAt, &a.CreatedAt,
+	)
+
+	if a.Labels == nil {
+		a.Labels = []string{}
+	}
+
+	return a, err
+}
+
+// InsertAgent inserts a new agent. The token is hashed before it is stored.
+func (s *Store) InsertAgent(ctx context.Context, a *Agent) error {
+	row, err := s.QueryRow(ctx, stmt.insertAgent, a.Name, pq.Array(a.Labels), HashToken(a.Token))
+
+	if err != nil {
+		return err
+	}
+
+	return row.Scan(&a.ID, &a.CreatedAt)
+}
+
+// RemoveAgent removes the agent. The jobs claimed by the agent are
+// put back to the queue by the next requeue.
+func (s *Store) RemoveAgent(ctx context.Context, id types.ID) error {
+	_, err := s.Exec(ctx, stmt.removeAgent, id)
+	return err
+}
+
+// Agents returns the list of registered agents.
+func (s *Store) Agents(ctx context.Context) ([]*Agent, error) {
+	rows, err := s.Query(ctx, stmt.selectAgents)
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	agents := []*Agent{}
+
+	for rows.Next() {
+		a, err := s.scanAgent(rows)
+
+		if err != nil {
+			return nil, err
+		}
+
+		agents = append(agents, a)
+	}
+
+	return agents, rows.Err()
+}
+
+// AgentByToken returns the agent that owns the given token.
+func (s *Store) AgentByToken(ctx context.Context, token string) (*Agent, error) {
+	row, err := s.QueryRow(ctx, stmt.selectAgentByToken, HashToken(token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	a, err := s.scanAgent(row)
+
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+
+	return a, err
+}
+
+// UpdateAgent updates the labels and the version that the agent reports
+// when it registers.
+func (s *Store) UpdateAgent(ctx context.Context, a *Agent) error {
+	_, err := s.Exec(ctx, stmt.updateAgent, a.ID, pq.Array(a.Labels), a.Version)
+	return err
+}
+
+// Heartbeat updates the last heartbeat of the agent and of the jobs
+// that it is currently building.
+func (s *Store) Heartbeat(ctx context.Context, agentID types.ID, deploymentIDs []types.ID) error {
+	_, err := s.Exec(ctx, stmt.updateHeartbeat, agentID, pq.Array(deploymentIDs))
+	return err
+}
+
+// InsertJob adds the deployment to the agent queue.
+func (s *Store) InsertJob(ctx context.Context, job *Job) error {
+	_, err := s.Exec(ctx, stmt.insertJob, job.DeploymentID, pq.Array(job.RequiredLabels), job.Payload)
+	return err
+}
+
+// ClaimJob assigns the oldest pending job, whose required labels are all
+// provided by the agent, to the agent. It returns nil when there is no such job.
+// The returned job contains a new token, which authenticates the runner callbacks
+// of this claim.
+func (s *Store) ClaimJob(ctx context.Context, a *Agent) (*Job, error) {
+	token := GenerateJobToken()
+	row, err := s.QueryRow(ctx, stmt.claimJob, a.ID, pq.Array(a.Labels), HashToken(token))
+
+	if err != nil {
+		return nil, err
+	}
+
+	job := &Job{AgentID: a.ID, Status: JobStatusClaimed, Token: token}
+
+	if err := row.Scan(&job.DeploymentID, pq.Array(&job.RequiredLabels), &job.Payload); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// CompleteJob marks the job as completed. It returns false when the job
+// is not claimed by the given agent.
+func (s *Store) CompleteJob(ctx context.Context, deploymentID, agentID types.ID) (bool, error) {
+	res, err := s.Exec(ctx, stmt.completeJob, deploymentID, agentID)
+
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	return affected > 0, err
+}
+
+// HoldsClaim returns true when the given token belongs to the current claim
+// of the deployment's job. Deployments that are not built by agents have no
+// job, in which case it returns true as well.
+func (s *Store) HoldsClaim(ctx context.Context, deploymentID types.ID, token string) (bool, error) {
+	row, err := s.QueryRow(ctx, stmt.selectJobClaim, deploymentID)
+
+	if err != nil {
+		return false, err
+	}
+
+	var status, tokenHash string
+
+	if err := row.Scan(&status, &tokenHash); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return true, nil
+		}
+
+		return false, err
+	}
+
+	return status == JobStatusClaimed && token != "" && tokenHash == HashToken(token), nil
+}
+
+// CancelledJobs returns the deployments, out of the given ones, that the
+// agent should no longer build. This happens when a deployment is stopped,
+// deleted or handed to another agent.
+func (s *Store) CancelledJobs(ctx context.Context, agentID types.ID, deploymentIDs []types.ID) ([]types.ID, error) {
+	rows, err := s.Query(ctx, stmt.cancelledJobs, agentID, pq.Array(deploymentIDs))
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scanIDs(rows)
+}
+
+// CancelStoppedJobs cancels the pending jobs of deployments that were
+// stopped or deleted before an agent picked them up.
+func (s *Store) CancelStoppedJobs(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmt.cancelStoppedJobs)
+	return err
+}
+
+// RequeueLostJobs puts the jobs of agents that stopped sending heartbeats
+// back to the queue. Jobs that were lost too many times fail the deployment.
+// It returns the requeued and the failed deployment ids.
+func (s *Store) RequeueLostJobs(ctx context.Context) ([]types.ID, []types.ID, error) {
+	timeout := int(HeartbeatTimeout.Seconds())
+	rows, err := s.Query(ctx, stmt.failAbandonedJobs, timeout, MaxJobAttempts, "The build agent stopped responding.")
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	failed, err := scanIDs(rows)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if rows, err = s.Query(ctx, stmt.requeueLostJobs, timeout, MaxJobAttempts); err != nil {
+		return nil, nil, err
+	}
+
+	requeued, err := scanIDs(rows)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return requeued, failed, nil
+}
+
+// RemoveOldJobs removes the completed and cancelled jobs that are older than a week.
+func (s *Store) RemoveOldJobs(ctx context.Context) error {
+	_, err := s.Exec(ctx, stmt.removeOldJobs)
+	return err
+}
+
+func scanIDs(rows *sql.Rows) ([]types.ID, error) {
+	defer rows.Close()
+
+	ids := []types.ID{}
+
+	for rows.Next() {
+		var id types.ID
+
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
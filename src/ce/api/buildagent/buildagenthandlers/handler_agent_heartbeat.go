@@ -0,0 +1,46 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type AgentHeartbeatRequest struct {
+	DeploymentIDs []types.ID `json:"deploymentIds"`
+}
+
+// handlerAgentHeartbeat keeps the agent and the deployments that it is building
+// alive. The response contains the deployments that the agent should stop.
+func handlerAgentHeartbeat(req *buildagent.RequestContext) *shttp.Response {
+	data := AgentHeartbeatRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	store := buildagent.NewStore()
+
+	if err := store.Heartbeat(req.Context(), req.Agent.ID, data.DeploymentIDs); err != nil {
+		return shttp.Error(err)
+	}
+
+	cancelled := []types.ID{}
+
+	if len(data.DeploymentIDs) > 0 {
+		var err error
+
+		if cancelled, err = store.CancelledJobs(req.Context(), req.Agent.ID, data.DeploymentIDs); err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"cancelled": cancelled,
+		},
+	}
+}
@@ -0,0 +1,102 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// claimTimeout is the maximum duration that a claim request waits for a job.
+const claimTimeout = 20 * time.Second
+
+// claimPollInterval is the interval at which the queue is checked for new jobs.
+const claimPollInterval = time.Second
+
+type AgentJobClaimRequest struct {
+	// Timeout is the number of seconds to wait for a job.
+	// Defaults to, and cannot exceed, the claim timeout.
+	Timeout *int `json:"timeout"`
+}
+
+// handlerAgentJobClaim long-polls the agent queue and hands the oldest job
+// that matches the labels of the agent to the agent. When no job is found
+// within the claim timeout, the endpoint returns no content and the agent
+// is expected to send a new claim request.
+//
+// Agents do not know the app secret. The deployment message is encrypted with
+// a key that is generated for the claim, and the runner authenticates its
+// callbacks with the job token of the claim.
+func handlerAgentJobClaim(req *buildagent.RequestContext) *shttp.Response {
+	data := AgentJobClaimRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	timeout := claimTimeout
+
+	if data.Timeout != nil && *data.Timeout >= 0 && time.Duration(*data.Timeout)*time.Second < claimTimeout {
+		timeout = time.Duration(*data.Timeout) * time.Second
+	}
+
+	store := buildagent.NewStore()
+	deadline := time.Now().Add(timeout)
+
+	// Claiming a job counts as a heartbeat
+	if err := store.Heartbeat(req.Context(), req.Agent.ID, nil); err != nil {
+		return shttp.Error(err)
+	}
+
+	for {
+		job, err := store.ClaimJob(req.Context(), req.Agent)
+
+		if err != nil {
+			return shttp.Error(err)
+		}
+
+		if job != nil {
+			return claimResponse(job)
+		}
+
+		if time.Now().Add(claimPollInterval).After(deadline) {
+			return shttp.NoContent()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return shttp.NoContent()
+		case <-time.After(claimPollInterval):
+		}
+	}
+}
+
+func claimResponse(job *buildagent.Job) *shttp.Response {
+	msg, err := deployservice.FromEncrypted(job.Payload)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	// Agents do not know the app secret, so the deployment message is sent as is.
+	// It is protected by TLS, like the agent token that authenticates the request.
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"job": map[string]any{
+				"deploymentId": job.DeploymentID.String(),
+				"payload": map[string]any{
+					"baseUrl":         admin.MustConfig().ApiURL(""),
+					"deploymentId":    job.DeploymentID.String(),
+					"deploymentIdEnc": utils.EncryptID(job.DeploymentID),
+					"deployment":      msg,
+					"jobToken":        job.Token,
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,226 @@
+package buildagenthandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent/buildagenthandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type claimedJob struct {
+	Job struct {
+		DeploymentID string `json:"deploymentId"`
+		Payload      struct {
+			BaseURL         string                          `json:"baseUrl"`
+			DeploymentID    string                          `json:"deploymentId"`
+			DeploymentIDEnc string                          `json:"deploymentIdEnc"`
+			Deployment      deployservice.DeploymentMessage `json:"deployment"`
+			JobToken        string                          `json:"jobToken"`
+		} `json:"payload"`
+	} `json:"job"`
+}
+
+type HandlerAgentJobClaimSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerAgentJobClaimSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerAgentJobClaimSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerAgentJobClaimSuite) mockAgent(labels []string) *buildagent.Agent {
+	agent := &buildagent.Agent{Name: "builder", Labels: labels, Token: buildagent.GenerateToken()}
+	s.NoError(buildagent.NewStore().InsertAgent(context.Background(), agent))
+	return agent
+}
+
+func (s *HandlerAgentJobClaimSuite) mockJob(labels []string) *deploy.Deployment {
+	env := s.MockEnv(nil)
+	depl := s.MockDeployment(env).Deployment
+	msg, err := deployservice.DeploymentMessage{
+		Build: deployservice.BuildConfig{DeploymentID: depl.ID.String(), Branch: "main"},
+	}.Encrypt()
+
+	s.NoError(err)
+	s.NoError(buildagent.NewStore().InsertJob(context.Background(), &buildagent.Job{
+		DeploymentID:   depl.ID,
+		RequiredLabels: labels,
+		Payload:        msg,
+	}))
+
+	return depl
+}
+
+func (s *HandlerAgentJobClaimSuite) claim(agent *buildagent.Agent) shttptest.Response {
+	return shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/agents/jobs/claim",
+		map[string]any{"timeout": 0},
+		map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", agent.Token),
+		},
+	)
+}
+
+func (s *HandlerAgentJobClaimSuite) Test_ClaimMatchingLabels() {
+	agent := s.mockAgent([]string{"arm64"})
+	large := s.mockJob([]string{"large"})
+	arm64 := s.mockJob([]string{"arm64"})
+
+	resp := s.claim(agent)
+	s.Equal(http.StatusOK, resp.Code)
+
+	data := claimedJob{}
+	s.NoError(json.Unmarshal(resp.Byte(), &data))
+	s.Equal(arm64.ID.String(), data.Job.DeploymentID)
+	s.Equal("http://api.stormkit:8888", data.Job.Payload.BaseURL)
+	s.Equal(arm64.ID.String(), data.Job.Payload.DeploymentID)
+	s.NotEmpty(data.Job.Payload.JobToken)
+
+	// The deployment id and message are readable without the app secret
+	id, err := utils.DecryptID(data.Job.Payload.DeploymentIDEnc)
+	s.NoError(err)
+	s.Equal(arm64.ID, id)
+
+	s.Equal(arm64.ID.String(), data.Job.Payload.Deployment.Build.DeploymentID)
+
+	// The remaining job requires a label that the agent does not have
+	resp = s.claim(agent)
+	s.Equal(http.StatusNoContent, resp.Code)
+
+	resp = s.claim(s.mockAgent([]string{"large", "amd64"}))
+	s.Equal(http.StatusOK, resp.Code)
+	s.Contains(resp.String(), large.ID.String())
+}
+
+func (s *HandlerAgentJobClaimSuite) Test_Heartbeat_And_Complete() {
+	agent := s.mockAgent(nil)
+	depl := s.mockJob(nil)
+	router := shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler()
+	headers := map[string]string{"Authorization": fmt.Sprintf("Bearer %s", agent.Token)}
+
+	s.Equal(http.StatusOK, s.claim(agent).Code)
+
+	resp := shttptest.RequestWithHeaders(router, shttp.MethodPost, "/agents/heartbeat", map[string]any{
+		"deploymentIds": []string{depl.ID.String()},
+	}, headers)
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(`{ "cancelled": [] }`, resp.String())
+
+	// Stopped deployments are cancelled through the heartbeat
+	s.NoError(deploy.NewStore().StopDeployment(context.Background(), depl.ID))
+
+	resp = shttptest.RequestWithHeaders(router, shttp.MethodPost, "/agents/heartbeat", map[string]any{
+		"deploymentIds": []string{depl.ID.String()},
+	}, headers)
+
+	s.Equal(http.StatusOK, resp.Code)
+	s.JSONEq(fmt.Sprintf(`{ "cancelled": ["%s"] }`, depl.ID.String()), resp.String())
+
+	resp = shttptest.RequestWithHeaders(router, shttp.MethodPost, "/agents/jobs/complete", map[string]any{
+		"deploymentId": depl.ID.String(),
+	}, headers)
+
+	s.Equal(http.StatusOK, resp.Code)
+
+	// The job is no longer claimed by the agent
+	resp = shttptest.RequestWithHeaders(router, shttp.MethodPost, "/agents/jobs/complete", map[string]any{
+		"deploymentId": depl.ID.String(),
+	}, headers)
+
+	s.Equal(http.StatusConflict, resp.Code)
+}
+
+func (s *HandlerAgentJobClaimSuite) Test_Complete_Failed() {
+	agent := s.mockAgent(nil)
+	depl := s.mockJob(nil)
+
+	s.Equal(http.StatusOK, s.claim(agent).Code)
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/agents/jobs/complete",
+		map[string]any{"deploymentId": depl.ID.String(), "failed": true},
+		map[string]string{"Authorization": fmt.Sprintf("Bearer %s", agent.Token)},
+	)
+
+	s.Equal(http.StatusOK, resp.Code)
+
+	d, err := deploy.NewStore().DeploymentByID(context.Background(), depl.ID)
+	s.NoError(err)
+	s.Equal(int64(deploy.ExitCodeFailed), d.ExitCode.ValueOrZero())
+}
+
+func (s *HandlerAgentJobClaimSuite) Test_HoldsClaim_RequeuedJob() {
+	ctx := context.Background()
+	store := buildagent.NewStore()
+	depl := s.mockJob(nil)
+
+	// Deployments without a job are not built by agents
+	held, err := store.HoldsClaim(ctx, s.MockDeployment(s.MockEnv(nil)).ID, "")
+	s.NoError(err)
+	s.True(held)
+
+	resp := s.claim(s.mockAgent(nil))
+	s.Equal(http.StatusOK, resp.Code)
+
+	data := claimedJob{}
+	s.NoError(json.Unmarshal(resp.Byte(), &data))
+
+	held, err = store.HoldsClaim(ctx, depl.ID, data.Job.Payload.JobToken)
+	s.NoError(err)
+	s.True(held)
+
+	held, err = store.HoldsClaim(ctx, depl.ID, "")
+	s.NoError(err)
+	s.False(held)
+
+	// Once the job is handed to another agent, the previous claim is no longer valid
+	_, err = s.conn.Exec(`UPDATE build_agent_jobs SET heartbeat_at = NOW() - INTERVAL '1 hour' WHERE deployment_id = $1`, depl.ID)
+	s.NoError(err)
+
+	_, _, err = store.RequeueLostJobs(ctx)
+	s.NoError(err)
+
+	held, err = store.HoldsClaim(ctx, depl.ID, data.Job.Payload.JobToken)
+	s.NoError(err)
+	s.False(held)
+
+	s.Equal(http.StatusOK, s.claim(s.mockAgent(nil)).Code)
+
+	held, err = store.HoldsClaim(ctx, depl.ID, data.Job.Payload.JobToken)
+	s.NoError(err)
+	s.False(held)
+}
+
+func (s *HandlerAgentJobClaimSuite) Test_InvalidToken() {
+	resp := s.claim(&buildagent.Agent{Token: buildagent.GenerateToken()})
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerAgentJobClaimSuite(t *testing.T) {
+	suite.Run(t, &HandlerAgentJobClaimSuite{})
+}
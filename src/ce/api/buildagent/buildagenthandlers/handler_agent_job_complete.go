@@ -0,0 +1,48 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type AgentJobCompleteRequest struct {
+	DeploymentID types.ID `json:"deploymentId"`
+
+	// Failed is set when the runner exits without reporting the result,
+	// for instance when it crashes. The deployment is then marked as failed.
+	Failed bool `json:"failed"`
+}
+
+// handlerAgentJobComplete is called by the agent once the runner exits.
+func handlerAgentJobComplete(req *buildagent.RequestContext) *shttp.Response {
+	data := AgentJobCompleteRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	completed, err := buildagent.NewStore().CompleteJob(req.Context(), data.DeploymentID, req.Agent.ID)
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	// The job was handed to another agent in the meantime
+	if !completed {
+		return &shttp.Response{
+			Status: http.StatusConflict,
+		}
+	}
+
+	if data.Failed {
+		if err := deploy.NewStore().UpdateExitCode(req.Context(), data.DeploymentID, deploy.ExitCodeFailed); err != nil {
+			return shttp.Error(err)
+		}
+	}
+
+	return shttp.OK()
+}
@@ -0,0 +1,46 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type AgentRegisterRequest struct {
+	Labels  []string `json:"labels"`
+	Version string   `json:"version"`
+}
+
+// handlerAgentRegister is called by the agent when it starts. The labels
+// reported by the agent replace the existing labels.
+func handlerAgentRegister(req *buildagent.RequestContext) *shttp.Response {
+	data := AgentRegisterRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	labels, err := buildagent.NormalizeLabels(data.Labels)
+
+	if err != nil {
+		return shttp.BadRequest(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	req.Agent.Labels = labels
+	req.Agent.Version = data.Version
+
+	if err := buildagent.NewStore().UpdateAgent(req.Context(), req.Agent); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"agent":             req.Agent,
+			"heartbeatInterval": int(buildagent.HeartbeatInterval.Seconds()),
+		},
+	}
+}
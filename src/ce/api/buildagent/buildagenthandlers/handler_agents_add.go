@@ -0,0 +1,58 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type AgentsAddRequest struct {
+	Name   string   `json:"name"`
+	Labels []string `json:"labels"`
+}
+
+// handlerAgentsAdd creates a new build agent and returns its token.
+// The token is only returned once, since only its hash is stored.
+func handlerAgentsAdd(req *user.RequestContext) *shttp.Response {
+	data := AgentsAddRequest{}
+
+	if err := req.Post(&data); err != nil {
+		return shttp.Error(err)
+	}
+
+	data.Name = strings.TrimSpace(data.Name)
+
+	if data.Name == "" {
+		return shttp.BadRequest(map[string]any{
+			"error": "Agent name is a required field.",
+		})
+	}
+
+	labels, err := buildagent.NormalizeLabels(data.Labels)
+
+	if err != nil {
+		return shttp.BadRequest(map[string]any{
+			"error": err.Error(),
+		})
+	}
+
+	agent := &buildagent.Agent{
+		Name:   data.Name,
+		Labels: labels,
+		Token:  buildagent.GenerateToken(),
+	}
+
+	if err := buildagent.NewStore().InsertAgent(req.Context(), agent); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusCreated,
+		Data: map[string]any{
+			"agent": agent,
+		},
+	}
+}
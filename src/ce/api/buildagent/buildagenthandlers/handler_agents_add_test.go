@@ -0,0 +1,107 @@
+package buildagenthandlers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/suite"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent/buildagenthandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+)
+
+type HandlerAgentsAddSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerAgentsAddSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerAgentsAddSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerAgentsAddSuite) Test_Success() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/admin/agents",
+		map[string]any{
+			"name":   "builder-1",
+			"labels": []string{"ARM64", " large ", "arm64"},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusCreated, resp.Code)
+
+	data := struct {
+		Agent buildagent.Agent `json:"agent"`
+	}{}
+
+	s.NoError(json.Unmarshal(resp.Byte(), &data))
+	s.Equal("builder-1", data.Agent.Name)
+	s.Equal([]string{"arm64", "large"}, data.Agent.Labels)
+	s.True(strings.HasPrefix(data.Agent.Token, "SKA_"))
+
+	agent, err := buildagent.NewStore().AgentByToken(context.Background(), data.Agent.Token)
+	s.NoError(err)
+	s.NotNil(agent)
+	s.Equal(data.Agent.ID, agent.ID)
+}
+
+func (s *HandlerAgentsAddSuite) Test_InvalidLabels() {
+	usr := s.MockUser(map[string]any{"IsAdmin": true})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/admin/agents",
+		map[string]any{
+			"name":   "builder-1",
+			"labels": []string{"arm 64"},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusBadRequest, resp.Code)
+	s.JSONEq(`{ "error": "Labels can only contain lowercase letters, numbers, dots, underscores and hyphens." }`, resp.String())
+}
+
+func (s *HandlerAgentsAddSuite) Test_Unauthorized_NonAdmin() {
+	usr := s.MockUser(map[string]any{"IsAdmin": false})
+
+	resp := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(buildagenthandlers.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/admin/agents",
+		map[string]any{"name": "builder-1"},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusUnauthorized, resp.Code)
+}
+
+func TestHandlerAgentsAddSuite(t *testing.T) {
+	suite.Run(t, &HandlerAgentsAddSuite{})
+}
@@ -0,0 +1,39 @@
+package buildagenthandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// handlerAgentsGet returns the list of registered build agents.
+func handlerAgentsGet(req *user.RequestContext) *shttp.Response {
+	agents, err := buildagent.NewStore().Agents(req.Context())
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	data := []map[string]any{}
+
+	for _, a := range agents {
+		data = append(data, map[string]any{
+			"id":              a.ID.String(),
+			"name":            a.Name,
+			"labels":          a.Labels,
+			"version":         a.Version,
+			"online":          a.IsOnline(),
+			"lastHeartbeatAt": a.LastHeartbeatAt,
+			"createdAt":       a.CreatedAt,
+		})
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"agents": data,
+		},
+	}
+}
@@ -0,0 +1,26 @@
+package buildagenthandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// handlerAgentsRemove removes the build agent and revokes its token. The
+// deployments that the agent was building are picked up by other agents.
+func handlerAgentsRemove(req *user.RequestContext) *shttp.Response {
+	id := utils.StringToID(req.Query().Get("id"))
+
+	if id == 0 {
+		return shttp.BadRequest(map[string]any{
+			"error": "Agent id is a required field.",
+		})
+	}
+
+	if err := buildagent.NewStore().RemoveAgent(req.Context(), id); err != nil {
+		return shttp.Error(err)
+	}
+
+	return shttp.OK()
+}
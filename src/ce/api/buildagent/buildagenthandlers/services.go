@@ -0,0 +1,27 @@
+package buildagenthandlers
+
+import (
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+// Services sets the handlers for this service.
+func Services(r *shttp.Router) *shttp.Service {
+	s := r.NewService()
+
+	// Endpoints called by the build agents
+	s.NewEndpoint("/agents").
+		Handler(shttp.MethodPost, "/register", buildagent.WithAgent(handlerAgentRegister)).
+		Handler(shttp.MethodPost, "/heartbeat", buildagent.WithAgent(handlerAgentHeartbeat)).
+		Handler(shttp.MethodPost, "/jobs/claim", buildagent.WithAgent(handlerAgentJobClaim)).
+		Handler(shttp.MethodPost, "/jobs/complete", buildagent.WithAgent(handlerAgentJobComplete))
+
+	// Endpoints to manage the build agents
+	s.NewEndpoint("/admin/agents").
+		Handler(shttp.MethodGet, "", user.WithAdmin(handlerAgentsGet)).
+		Handler(shttp.MethodPost, "", user.WithAdmin(handlerAgentsAdd)).
+		Handler(shttp.MethodDelete, "", user.WithAdmin(handlerAgentsRemove))
+
+	return s
+}
@@ -0,0 +1,34 @@
+package buildagenthandlers_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent/buildagenthandlers"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stretchr/testify/suite"
+)
+
+type ServicesSuite struct {
+	suite.Suite
+}
+
+func (s *ServicesSuite) Test_Services() {
+	services := shttp.NewRouter().RegisterService(buildagenthandlers.Services)
+	s.NotNil(services)
+
+	handlers := []string{
+		"DELETE:/admin/agents",
+		"GET:/admin/agents",
+		"POST:/admin/agents",
+		"POST:/agents/heartbeat",
+		"POST:/agents/jobs/claim",
+		"POST:/agents/jobs/complete",
+		"POST:/agents/register",
+	}
+
+	s.Equal(handlers, services.HandlerKeys())
+}
+
+func TestServices(t *testing.T) {
+	suite.Run(t, &ServicesSuite{})
+}
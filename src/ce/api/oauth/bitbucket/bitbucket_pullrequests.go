@@ -13,10 +13,16 @@ type PullRequestCommentBody struct {
 	Raw string `json:"raw"`
 }
 
+// PullRequestCommentParent represents the comment that is replied to.
+type PullRequestCommentParent struct {
+	ID int64 `json:"id"`
+}
+
 // PullRequestCommentRequest represents a pull request comment payload
 // that is going to be sent to the bitbucket API.
 type PullRequestCommentRequest struct {
-	Body PullRequestCommentBody `json:"content"`
+	Body   PullRequestCommentBody    `json:"content"`
+	Parent *PullRequestCommentParent `json:"parent,omitempty"`
 }
 
 // PullRequestCommentResponse represents a pull request comment
@@ -30,7 +36,7 @@ func (b *Bitbucket) PullRequestComment(a *App, body string, prNumber int64) (*Pu
 	owner, repo := oauth.ParseRepo(a.Repo)
 
 	res, err := b.post(fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/comments", owner, repo, prNumber), PullRequestCommentRequest{
-		PullRequestCommentBody{
+		Body: PullRequestCommentBody{
 			Raw: body,
 		},
 	})
@@ -48,6 +54,22 @@ func (b *Bitbucket) PullRequestComment(a *App, body string, prNumber int64) (*Pu
 	return comment, nil
 }
 
+// PullRequestReply replies to the given comment on the pull request.
+func (b *Bitbucket) PullRequestReply(a *App, body string, prNumber, parentID int64) error {
+	owner, repo := oauth.ParseRepo(a.Repo)
+
+	res, err := b.post(fmt.Sprintf("/repositories/%s/%s/pullrequests/%d/comments", owner, repo, prNumber), PullRequestCommentRequest{
+		Body:   PullRequestCommentBody{Raw: body},
+		Parent: &PullRequestCommentParent{ID: parentID},
+	})
+
+	if res != nil {
+		res.Body.Close()
+	}
+
+	return err
+}
+
 // PullRequestRemoveComment creates a new comment on the pull request.
 func (b *Bitbucket) PullRequestRemoveComment(a *App, prNumber, commentID int64) error {
 	owner, repo := oauth.ParseRepo(a.Repo)
@@ -0,0 +1,38 @@
+package bitbucket
+
+import (
+	"fmt"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth"
+)
+
+// Commit status states that are accepted by the Bitbucket API.
+const (
+	CommitStatusSuccessful = "SUCCESSFUL"
+	CommitStatusFailed     = "FAILED"
+	CommitStatusInProgress = "INPROGRESS"
+	CommitStatusStopped    = "STOPPED"
+)
+
+// CommitStatus represents a build status that is attached to a commit.
+type CommitStatus struct {
+	Key         string `json:"key"`
+	State       string `json:"state"`
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Description string `json:"description"`
+}
+
+// CreateCommitStatus creates or updates the build status of the given commit.
+// Statuses are identified by their key, so each key is displayed separately.
+func (b *Bitbucket) CreateCommitStatus(a *App, sha string, status CommitStatus) error {
+	owner, repo := oauth.ParseRepo(a.Repo)
+
+	res, err := b.post(fmt.Sprintf("/repositories/%s/%s/commit/%s/statuses/build", owner, repo, sha), status)
+
+	if res != nil {
+		res.Body.Close()
+	}
+
+	return err
+}
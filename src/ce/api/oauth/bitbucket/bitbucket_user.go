@@ -25,6 +25,7 @@ type EmailsResponse struct {
 
 // ProfileResponse represents a user profile response.
 type ProfileResponse struct {
+	UUID        string `json:"uuid"`         // This is the account id which never changes
 	UserName    string `json:"username"`     // This is the username which is unique and will not change
 	NickName    string `json:"nickname"`     // This is the nickname the user wants to display to others
 	DisplayName string `json:"display_name"` // This is the full name
@@ -80,6 +81,7 @@ func (ui *UserInterface) Profile() (*oauth.User, error) {
 	}
 
 	ui.b.user.AccountURI = login.Links.HTML.Href
+	ui.b.user.AccountID = login.UUID
 	ui.b.user.AvatarURI = login.Links.AvatarURI.Href
 	ui.b.user.DisplayName = login.NickName
 	ui.b.user.FullName = login.DisplayName
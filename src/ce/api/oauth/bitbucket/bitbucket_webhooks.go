@@ -50,6 +50,7 @@ func (b *Bitbucket) InstallWebhooks(a *App) error {
 			"repo:push",
 			"pullrequest:created",
 			"pullrequest:fulfilled",
+			"pullrequest:comment_created",
 		},
 	})
 
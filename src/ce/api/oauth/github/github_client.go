@@ -3,6 +3,7 @@ package github
 import (
 	"context"
 	"net/http"
+	"strconv"
 
 	"github.com/bradleyfalzon/ghinstallation"
 	"github.com/google/go-github/v71/github"
@@ -100,6 +101,10 @@ func (g *githubClient) UserProfile() (*oauth.User, error) {
 		g.user.DisplayName = *login.Login
 	}
 
+	if login.ID != nil {
+		g.user.AccountID = strconv.FormatInt(*login.ID, 10)
+	}
+
 	if login.Name != nil {
 		g.user.FullName = *login.Name
 	}
@@ -0,0 +1,58 @@
+package github
+
+import (
+	"context"
+	"errors"
+)
+
+// PullRequestHead is the head of a pull request.
+type PullRequestHead struct {
+	Branch string // Branch is the name of the head branch.
+	Sha    string // Sha is the latest commit of the head branch.
+	Repo   string // Repo is the full name of the head repository, e.g. owner/repo.
+}
+
+// GetPullRequestHead returns the head of the given pull request. Issue comment
+// events do not contain the head branch, so it is fetched from the API.
+func GetPullRequestHead(repo string, number int64) (*PullRequestHead, error) {
+	client, err := NewApp(repo)
+
+	if err != nil || client == nil {
+		return nil, err
+	}
+
+	pr, _, err := client.PullRequests.Get(context.Background(), client.Owner, client.Repo, int(number))
+
+	if err != nil {
+		return nil, err
+	}
+
+	if pr == nil || pr.Head == nil {
+		return nil, errors.New("pull request head is not found")
+	}
+
+	return &PullRequestHead{
+		Branch: pr.Head.GetRef(),
+		Sha:    pr.Head.GetSHA(),
+		Repo:   pr.Head.GetRepo().GetFullName(),
+	}, nil
+}
+
+// CreatePullRequestComment posts a new comment on the given pull request.
+func CreatePullRequestComment(repo string, number int64, body string) error {
+	client, err := NewApp(repo)
+
+	if err != nil || client == nil {
+		return err
+	}
+
+	_, _, err = client.Issues.CreateComment(
+		context.Background(),
+		client.Owner,
+		client.Repo,
+		int(number),
+		&IssueComment{Body: &body},
+	)
+
+	return err
+}
@@ -54,3 +54,27 @@ func CreateStatus(repo, branch, url, status string) error {
 
 	return err
 }
+
+// CreateCommitStatus creates a status for the given commit. Statuses with
+// different contexts are displayed separately on the pull request screen.
+func CreateCommitStatus(repo, sha, url, status, statusContext, description string) error {
+	gh, err := NewApp(repo)
+
+	if err != nil || gh == nil {
+		return err
+	}
+
+	_, _, err = gh.Repositories.CreateStatus(
+		context.Background(),
+		gh.Owner,
+		gh.Repo,
+		sha,
+		&github.RepoStatus{
+			Description: aws.String(description),
+			TargetURL:   aws.String(url),
+			State:       aws.String(status),
+			Context:     aws.String(statusContext),
+		})
+
+	return err
+}
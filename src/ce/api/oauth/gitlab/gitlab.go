@@ -24,6 +24,21 @@ type CreateMergeRequestDiscussionOptions = gitlab.CreateMergeRequestDiscussionOp
 // gitlab.ResolveMergeRequestDiscussionOptions.
 type ResolveMergeRequestDiscussionOptions = gitlab.ResolveMergeRequestDiscussionOptions
 
+// CreateMergeRequestNoteOptions is a shorthand export for
+// gitlab.CreateMergeRequestNoteOptions.
+type CreateMergeRequestNoteOptions = gitlab.CreateMergeRequestNoteOptions
+
+// SetCommitStatusOptions is a shorthand export for
+// gitlab.SetCommitStatusOptions.
+type SetCommitStatusOptions = gitlab.SetCommitStatusOptions
+
+// Commit status states that are accepted by the Gitlab API.
+const (
+	Success = gitlab.Success
+	Failed  = gitlab.Failed
+	Skipped = gitlab.Skipped
+)
+
 // Gitlab is a wrapper around the github client to provide
 // access to additional information.
 type Gitlab struct {
@@ -1,6 +1,8 @@
 package gitlab
 
 import (
+	"strconv"
+
 	"github.com/stormkit-io/stormkit-io/src/ce/api/oauth"
 )
 
@@ -14,6 +16,7 @@ func (g *Gitlab) UserProfile() (*oauth.User, error) {
 
 	g.user.AvatarURI = usr.AvatarURL
 	g.user.DisplayName = usr.Username
+	g.user.AccountID = strconv.Itoa(usr.ID)
 	g.user.FullName = usr.Name
 
 	// TODO: list all emails
@@ -25,6 +25,7 @@ type User struct {
 	*oauth2.Token
 
 	AccountURI   string
+	AccountID    string // The immutable id of the account on the provider.
 	AvatarURI    string
 	Emails       []Email
 	DisplayName  string
@@ -21,9 +21,9 @@ var ustmt = &userStatement{
 	upsertToken: fmt.Sprintf(`
 		INSERT INTO %s
 			(user_id, display_name, account_uri, provider,
-			 token_value, token_refresh, token_type, expire_at)
+			 token_value, token_refresh, token_type, expire_at, account_id)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7, $8)
+			($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($15, ''))
 		ON CONFLICT(user_id, provider) DO UPDATE
 		SET
 			display_name = $9,
@@ -31,6 +31,7 @@ var ustmt = &userStatement{
 			token_value = $11,
 			token_refresh = $12,
 			token_type = $13,
-			expire_at = $14;
+			expire_at = $14,
+			account_id = EXCLUDED.account_id;
 	`, tableAccessTokens),
 }
@@ -93,6 +93,7 @@ func (s *Store) UpsertToken(ID types.ID, user *User) error {
 		// On conflict
 		user.DisplayName, user.AccountURI,
 		t.AccessToken, t.RefreshToken, t.TokenType, t.Expiry,
+		user.AccountID,
 	)
 
 	return err
@@ -137,6 +137,14 @@ func handlerEnvAdd(req *app.RequestContext) *shttp.Response {
 		}
 	}
 
+	if changed := buildconf.ChangedVars(nil, cnf.Data.Vars); len(changed) > 0 {
+		app.TriggerEvent(req.Context(), app.TriggerOnEnvVarsChanged, app.OutboundWebhookSettings{
+			AppID:           req.App.ID,
+			EnvironmentName: cnf.Name,
+			ChangedVars:     changed,
+		})
+	}
+
 	return &shttp.Response{
 		Status: http.StatusCreated,
 		Data: map[string]any{
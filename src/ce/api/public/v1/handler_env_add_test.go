@@ -2,12 +2,15 @@ package publicapiv1_test
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"net/http"
 	"testing"
 	"time"
 
+	"github.com/hibiken/asynq"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
@@ -18,7 +21,10 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
 )
 
 type HandlerEnvAddSuite struct {
@@ -153,6 +159,54 @@ func (s *HandlerEnvAddSuite) Test_Success() {
 	}, audits[0])
 }
 
+func (s *HandlerEnvAddSuite) Test_EnvVarsChangedEvent() {
+	appl := s.MockApp(nil)
+	key := s.MockAPIKey(appl, nil, map[string]any{"Scope": apikey.SCOPE_APP, "EnvID": types.ID(0), "AppID": appl.ID})
+	mockClient := &mocks.TaskClient{}
+	originalClient := tasks.Client
+	tasks.Client = func() tasks.TaskClient { return mockClient }
+
+	defer func() { tasks.Client = originalClient }()
+
+	s.NoError(app.NewStore().InsertOutboundWebhook(context.Background(), appl.ID, &app.OutboundWebhook{
+		RequestURL:    "https://example.org/webhooks",
+		RequestMethod: shttp.MethodPost,
+		TriggerWhen:   app.TriggerOnEnvVarsChanged,
+		PayloadFormat: app.PayloadFormatJSON,
+	}))
+
+	mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(publicapiv1.Services).Router().Handler(),
+		shttp.MethodPost,
+		"/v1/env",
+		map[string]any{
+			"branch": "my-branch",
+			"name":   "development",
+			"envVars": map[string]string{
+				"NODE_ENV": "production",
+				"API_URL":  "https://api.my-app.com",
+			},
+		},
+		map[string]string{
+			"Authorization": key.Value,
+		},
+	)
+
+	s.Equal(http.StatusCreated, response.Code)
+	mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		msg := app.OutboundWebhookMessage{}
+		s.NoError(json.Unmarshal(task.Payload(), &msg))
+
+		return s.Equal(tasks.OutboundWebhookDelivery, task.Type()) &&
+			s.Equal(app.TriggerOnEnvVarsChanged, msg.TriggerWhen) &&
+			s.Contains(msg.Payload, `"API_URL"`) &&
+			s.Contains(msg.Payload, `"NODE_ENV"`) &&
+			s.Contains(msg.Payload, `"development"`)
+	}))
+}
+
 func TestHandlerEnvInsert(t *testing.T) {
 	suite.Run(t, &HandlerEnvAddSuite{})
 }
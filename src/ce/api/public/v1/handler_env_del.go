@@ -52,5 +52,15 @@ func handlerEnvDel(req *app.RequestContext) *shttp.Response {
 		}
 	}
 
+	if env.Data != nil {
+		if changed := buildconf.ChangedVars(env.Data.Vars, nil); len(changed) > 0 {
+			app.TriggerEvent(req.Context(), app.TriggerOnEnvVarsChanged, app.OutboundWebhookSettings{
+				AppID:           req.App.ID,
+				EnvironmentName: env.Name,
+				ChangedVars:     changed,
+			})
+		}
+	}
+
 	return shttp.OK()
 }
@@ -2,8 +2,13 @@ package publicapiv1
 
 import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob/backgroundjobhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf/domainhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf/snippetshandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore/kvstorehandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer/mailerhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
@@ -18,6 +23,13 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodDelete, "", app.WithAPIKey(handlerEnvDel, &app.Opts{Env: true})).
 		Handler(shttp.MethodGet, "/pull", app.WithAPIKey(handlerEnvPull, &app.Opts{Env: true}))
 
+	s.NewEndpoint("/v1/secrets").
+		Handler(shttp.MethodGet, "", app.WithAPIKey(envsecrethandlers.HandlerSecretsGet, &app.Opts{Env: true})).
+		Handler(shttp.MethodPut, "", app.WithAPIKey(envsecrethandlers.HandlerSecretSet, &app.Opts{Env: true})).
+		Handler(shttp.MethodDelete, "", app.WithAPIKey(envsecrethandlers.HandlerSecretDelete, &app.Opts{Env: true})).
+		Handler(shttp.MethodGet, "/versions", app.WithAPIKey(envsecrethandlers.HandlerSecretVersionsGet, &app.Opts{Env: true})).
+		Handler(shttp.MethodPost, "/rollback", app.WithAPIKey(envsecrethandlers.HandlerSecretRollback, &app.Opts{Env: true}))
+
 	s.NewEndpoint("/v1/snippets").
 		Handler(shttp.MethodGet, "", app.WithAPIKey(snippetshandlers.HandlerSnippetsGet, &app.Opts{Env: true})).
 		Handler(shttp.MethodPost, "", app.WithAPIKey(snippetshandlers.HandlerSnippetsAdd, &app.Opts{Env: true})).
@@ -44,6 +56,16 @@ func Services(r *shttp.Router) *shttp.Service {
 	s.NewEndpoint("/v1/mail").
 		Handler(shttp.MethodPost, "", app.WithAPIKey(mailerhandlers.HandlerMail, &app.Opts{Env: true}))
 
+	s.NewEndpoint("/v1/jobs").
+		Handler(shttp.MethodGet, "", app.WithFunctionToken(backgroundjob.TokenService, backgroundjobhandlers.HandlerJobsGet)).
+		Handler(shttp.MethodPost, "", app.WithFunctionToken(backgroundjob.TokenService, backgroundjobhandlers.HandlerJobEnqueue))
+
+	s.NewEndpoint("/v1/kv").
+		Handler(shttp.MethodGet, "", app.WithFunctionToken(kvstore.TokenService, kvstorehandlers.HandlerKVGet)).
+		Handler(shttp.MethodPut, "", app.WithFunctionToken(kvstore.TokenService, kvstorehandlers.HandlerKVSet)).
+		Handler(shttp.MethodDelete, "", app.WithFunctionToken(kvstore.TokenService, kvstorehandlers.HandlerKVDelete)).
+		Handler(shttp.MethodPost, "/increment", app.WithFunctionToken(kvstore.TokenService, kvstorehandlers.HandlerKVIncrement))
+
 	s.NewEndpoint("/v1/license").
 		// Temporary solution until we migrate previous licenses
 		Handler(shttp.MethodGet, "", func(rc *shttp.RequestContext) *shttp.Response { return shttp.OK() }).
@@ -21,20 +21,31 @@ func (s *ServicesSuite) Test_Services() {
 		"DELETE:/v1/domains",
 		"DELETE:/v1/domains/cert",
 		"DELETE:/v1/env",
+		"DELETE:/v1/kv",
+		"DELETE:/v1/secrets",
 		"DELETE:/v1/snippets",
 		"GET:/v1/app/config",
 		"GET:/v1/domains",
 		"GET:/v1/env/pull",
+		"GET:/v1/jobs",
+		"GET:/v1/kv",
 		"GET:/v1/license",
 		"GET:/v1/license/check",
 		"GET:/v1/redirects",
+		"GET:/v1/secrets",
+		"GET:/v1/secrets/versions",
 		"GET:/v1/snippets",
 		"POST:/v1/domains",
 		"POST:/v1/env",
+		"POST:/v1/jobs",
+		"POST:/v1/kv/increment",
 		"POST:/v1/mail",
 		"POST:/v1/redirects",
+		"POST:/v1/secrets/rollback",
 		"POST:/v1/snippets",
 		"PUT:/v1/domains/cert",
+		"PUT:/v1/kv",
+		"PUT:/v1/secrets",
 		"PUT:/v1/snippets",
 	}
 
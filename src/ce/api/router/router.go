@@ -6,16 +6,20 @@ import (
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey/apikeyhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apphandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/authwall/authwallhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob/backgroundjobhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf/buildconfhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf/domainhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf/snippetshandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy/deployhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger/deploytriggerhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/envsecret/envsecrethandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger/functiontriggerhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer/mailerhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/providerhandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/redirects/redirectshandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/volumes/volumeshandlers"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/applog/apploghandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent/buildagenthandlers"
 	publicapiv1 "github.com/stormkit-io/stormkit-io/src/ce/api/public/v1"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/status"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user/authhandlers"
@@ -60,6 +64,10 @@ func Get() *shttp.Router {
 	r.RegisterService(snippetshandlers.Services)
 	r.RegisterService(volumeshandlers.Services)
 	r.RegisterService(functiontriggerhandlers.Services)
+	r.RegisterService(backgroundjobhandlers.Services)
+	r.RegisterService(deploytriggerhandlers.Services)
+	r.RegisterService(envsecrethandlers.Services)
+	r.RegisterService(buildagenthandlers.Services)
 
 	// Enterprise handlers
 	r.RegisterService(authwallhandlers.Services)
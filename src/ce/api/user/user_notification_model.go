@@ -0,0 +1,50 @@
+package user
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+)
+
+// NotificationPreferences are the platform emails that a user receives.
+// Users receive all emails unless they opt out.
+type NotificationPreferences struct {
+	DeployFailed        bool `json:"deployFailed"`        // Deployments authored by the user fail.
+	ProtectedPublish    bool `json:"protectedPublish"`    // A deployment is published to a protected environment.
+	DomainFailing       bool `json:"domainFailing"`       // A domain of the team stops responding.
+	CertificateExpiring bool `json:"certificateExpiring"` // A certificate of the team is about to expire.
+}
+
+// DefaultNotificationPreferences returns the preferences of users
+// who did not configure them.
+func DefaultNotificationPreferences() NotificationPreferences {
+	return NotificationPreferences{
+		DeployFailed:        true,
+		ProtectedPublish:    true,
+		DomainFailing:       true,
+		CertificateExpiring: true,
+	}
+}
+
+// Scan implements the sql.Scanner interface for NotificationPreferences.
+// Preferences that are missing in the stored value keep their defaults.
+func (np *NotificationPreferences) Scan(value any) error {
+	*np = DefaultNotificationPreferences()
+
+	if value == nil {
+		return nil
+	}
+
+	bytes, ok := value.([]byte)
+
+	if !ok {
+		return fmt.Errorf("cannot scan %T into NotificationPreferences", value)
+	}
+
+	return json.Unmarshal(bytes, np)
+}
+
+// Value implements the driver.Valuer interface for NotificationPreferences.
+func (np NotificationPreferences) Value() (driver.Value, error) {
+	return json.Marshal(np)
+}
@@ -27,6 +27,8 @@ type userStatement struct {
 	userMetrics               string
 	updateUsageMetrics        string
 	updateApprovalStatus      string
+	selectNotificationPrefs   string
+	upsertNotificationPrefs   string
 }
 
 var ustmt = &userStatement{
@@ -246,4 +248,19 @@ var ustmt = &userStatement{
 	updateApprovalStatus: `
 		UPDATE users SET is_approved = $1 WHERE user_id = ANY($2);
 	`,
+
+	selectNotificationPrefs: `
+		SELECT user_id, preferences FROM user_notification_preferences WHERE user_id = ANY($1);
+	`,
+
+	upsertNotificationPrefs: `
+		INSERT INTO user_notification_preferences
+			(user_id, preferences)
+		VALUES
+			($1, $2)
+		ON CONFLICT (user_id)
+		DO UPDATE SET
+			preferences = EXCLUDED.preferences,
+			updated_at = NOW() AT TIME ZONE 'UTC';
+	`,
 }
@@ -158,6 +158,47 @@ func (s *Store) UpdateApprovalStatus(ctx context.Context, userIDs []types.ID, ap
 	return err
 }
 
+// NotificationPreferences returns the notification preferences of the given
+// users. Users who did not configure their preferences get the defaults.
+func (s *Store) NotificationPreferences(ctx context.Context, userIDs []types.ID) (map[types.ID]NotificationPreferences, error) {
+	prefs := map[types.ID]NotificationPreferences{}
+
+	for _, id := range userIDs {
+		prefs[id] = DefaultNotificationPreferences()
+	}
+
+	rows, err := s.Query(ctx, ustmt.selectNotificationPrefs, pq.Array(userIDs))
+
+	if err == sql.ErrNoRows {
+		return prefs, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer rows.Close()
+
+	for rows.Next() {
+		var userID types.ID
+		var np NotificationPreferences
+
+		if err := rows.Scan(&userID, &np); err != nil {
+			return nil, err
+		}
+
+		prefs[userID] = np
+	}
+
+	return prefs, nil
+}
+
+// UpdateNotificationPreferences stores the notification preferences of the user.
+func (s *Store) UpdateNotificationPreferences(ctx context.Context, userID types.ID, prefs NotificationPreferences) error {
+	_, err := s.Exec(ctx, ustmt.upsertNotificationPrefs, userID, prefs)
+	return err
+}
+
 // TeamOwner returns the owner user of a team.
 func (s *Store) TeamOwner(teamID types.ID) (*User, error) {
 	var wr bytes.Buffer
@@ -0,0 +1,25 @@
+package userhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// handlerNotificationPreferences returns the platform emails that the user receives.
+func handlerNotificationPreferences(req *user.RequestContext) *shttp.Response {
+	prefs, err := user.NewStore().NotificationPreferences(req.Context(), []types.ID{req.User.ID})
+
+	if err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"preferences": prefs[req.User.ID],
+		},
+	}
+}
@@ -0,0 +1,90 @@
+package userhandlers_test
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/userhandlers"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user/usertest"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp/shttptest"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stretchr/testify/suite"
+)
+
+type HandlerNotificationPreferencesSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *HandlerNotificationPreferencesSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *HandlerNotificationPreferencesSuite) AfterTest(suiteName, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *HandlerNotificationPreferencesSuite) Test_Get_Defaults() {
+	usr := s.MockUser()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(userhandlers.Services).Router().Handler(),
+		shttp.MethodGet,
+		"/user/notification-preferences",
+		nil,
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+	s.JSONEq(`{
+		"preferences": {
+			"deployFailed": true,
+			"protectedPublish": true,
+			"domainFailing": true,
+			"certificateExpiring": true
+		}
+	}`, response.String())
+}
+
+func (s *HandlerNotificationPreferencesSuite) Test_Update() {
+	usr := s.MockUser()
+
+	response := shttptest.RequestWithHeaders(
+		shttp.NewRouter().RegisterService(userhandlers.Services).Router().Handler(),
+		shttp.MethodPut,
+		"/user/notification-preferences",
+		map[string]any{
+			"preferences": map[string]bool{
+				"deployFailed":  true,
+				"domainFailing": false,
+			},
+		},
+		map[string]string{
+			"Authorization": usertest.Authorization(usr.ID),
+		},
+	)
+
+	s.Equal(http.StatusOK, response.Code)
+
+	prefs, err := user.NewStore().NotificationPreferences(context.Background(), []types.ID{usr.ID})
+	s.NoError(err)
+	s.Equal(user.NotificationPreferences{
+		DeployFailed:        true,
+		ProtectedPublish:    true,
+		DomainFailing:       false,
+		CertificateExpiring: true,
+	}, prefs[usr.ID])
+}
+
+func TestHandlerNotificationPreferencesSuite(t *testing.T) {
+	suite.Run(t, &HandlerNotificationPreferencesSuite{})
+}
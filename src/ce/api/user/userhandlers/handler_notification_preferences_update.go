@@ -0,0 +1,34 @@
+package userhandlers
+
+import (
+	"net/http"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+)
+
+type notificationPreferencesRequest struct {
+	Preferences user.NotificationPreferences `json:"preferences"`
+}
+
+// handlerNotificationPreferencesUpdate updates the platform emails that the user receives.
+func handlerNotificationPreferencesUpdate(req *user.RequestContext) *shttp.Response {
+	data := &notificationPreferencesRequest{
+		Preferences: user.DefaultNotificationPreferences(),
+	}
+
+	if err := req.Post(data); err != nil {
+		return shttp.ValidationError(err)
+	}
+
+	if err := user.NewStore().UpdateNotificationPreferences(req.Context(), req.User.ID, data.Preferences); err != nil {
+		return shttp.Error(err)
+	}
+
+	return &shttp.Response{
+		Status: http.StatusOK,
+		Data: map[string]any{
+			"preferences": data.Preferences,
+		},
+	}
+}
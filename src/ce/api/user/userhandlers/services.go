@@ -15,6 +15,8 @@ func Services(r *shttp.Router) *shttp.Service {
 		Handler(shttp.MethodGet, "", user.WithAuth(handlerUserSession)).
 		Handler(shttp.MethodGet, "/emails", user.WithAuth(handlerUserEmails)).
 		Handler(shttp.MethodPut, "/access-token", user.WithAuth(handlerUpdatePersonalAccessToken)).
+		Handler(shttp.MethodGet, "/notification-preferences", user.WithAuth(handlerNotificationPreferences)).
+		Handler(shttp.MethodPut, "/notification-preferences", user.WithAuth(handlerNotificationPreferencesUpdate)).
 		Handler(shttp.MethodDelete, "", user.WithAuth(handlerUserDelete))
 
 	if config.IsStormkitCloud() {
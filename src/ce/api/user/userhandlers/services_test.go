@@ -25,7 +25,9 @@ func (s *ServicesSuite) TestServices_SelfHosted() {
 		"DELETE:/user",
 		"GET:/user",
 		"GET:/user/emails",
+		"GET:/user/notification-preferences",
 		"PUT:/user/access-token",
+		"PUT:/user/notification-preferences",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
@@ -44,8 +46,10 @@ func (s *ServicesSuite) TestServices_StormkitCloud() {
 		"GET:/user",
 		"GET:/user/emails",
 		"GET:/user/license",
+		"GET:/user/notification-preferences",
 		"POST:/user/license",
 		"PUT:/user/access-token",
+		"PUT:/user/notification-preferences",
 	}
 
 	s.Equal(handlers, services.HandlerKeys())
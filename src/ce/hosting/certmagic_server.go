@@ -11,7 +11,9 @@ import (
 
 	"github.com/caddyserver/certmagic"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
@@ -29,6 +31,37 @@ func storage(logger *zap.Logger) certmagic.Storage {
 	return storage
 }
 
+// onCertificateEvent dispatches the outbound webhooks of the custom domain
+// when a certificate is issued or renewed for it. Certificates of the
+// managed domains are not tied to an app, so they are skipped.
+func onCertificateEvent(ctx context.Context, event string, data map[string]any) error {
+	if event != "cert_obtained" {
+		return nil
+	}
+
+	name, ok := data["identifier"].(string)
+
+	if !ok || name == "" {
+		return nil
+	}
+
+	go func() {
+		ctx := context.Background()
+		domain, err := buildconf.DomainStore().DomainByName(ctx, name)
+
+		if err != nil {
+			slog.Errorf("cannot fetch domain for certificate webhooks: %v", err)
+			return
+		}
+
+		if domain != nil {
+			app.TriggerDomainEvent(ctx, app.TriggerOnCertificateIssued, domain, app.OutboundWebhookSettings{})
+		}
+	}()
+
+	return nil
+}
+
 type MagicOpts struct {
 	Handler      http.Handler
 	FetchAppConf func(hostName string) ([]*appconf.Config, error)
@@ -62,6 +95,8 @@ func Magic(opts MagicOpts) {
 		DisableStapling: true,
 	}
 
+	certmagic.Default.OnEvent = onCertificateEvent
+
 	server := certmagic.NewDefault()
 	server.Logger = logger
 
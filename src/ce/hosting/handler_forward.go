@@ -12,6 +12,8 @@ import (
 	"github.com/redis/go-redis/v9"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
 	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
 	"github.com/stormkit-io/stormkit-io/src/ee/api/analytics"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
@@ -83,6 +85,7 @@ type RequestServer struct {
 	logs      []integrations.Log
 	record    *analytics.Record
 	fnInvoked bool
+	fnError   bool
 }
 
 func NewRequestServer(req *RequestContext) *RequestServer {
@@ -113,6 +116,8 @@ func (r *RequestServer) artifacts() {
 		HostName:        r.req.Host.Name,
 		BillingUserID:   r.req.Host.Config.BillingUserID,
 		FunctionInvoked: r.fnInvoked,
+		FunctionError:   r.fnError,
+		StatusCode:      r.res.Status,
 		Logs:            r.logs,
 		Analytics:       r.record,
 		TotalBandwidth:  int64(len(data)) + headersSize(r.res.Headers),
@@ -250,6 +255,12 @@ func (r *RequestServer) Dynamic() *shttp.Response {
 		arn = cnf.APILocation
 	}
 
+	masker := utils.NewSecretMasker(cnf.EnvVariables)
+
+	for _, name := range cnf.SecretVars {
+		masker.AddSecret(cnf.EnvVariables[name])
+	}
+
 	result, err := integrations.Client().Invoke(integrations.InvokeArgs{
 		URL:          url,
 		ARN:          arn,
@@ -265,6 +276,8 @@ func (r *RequestServer) Dynamic() *shttp.Response {
 		IsPublished:  cnf.Percentage > 0,
 		CaptureLogs:  true,
 		QueueLog: func(log *integrations.Log) {
+			log.Message = masker.MaskString(log.Message)
+
 			Queue(&jobs.HostingRecord{
 				AppID:         r.req.Host.Config.AppID,
 				EnvID:         r.req.Host.Config.EnvID,
@@ -276,16 +289,19 @@ func (r *RequestServer) Dynamic() *shttp.Response {
 		},
 		Context: map[string]any{
 			"apiPrefix": cnf.APIPathPrefix,
+			"jobs":      backgroundjob.FunctionContext(cnf.EnvID),
+			"kv":        kvstore.FunctionContext(cnf.EnvID),
 		},
 	})
 
 	r.fnInvoked = true
 
 	if result != nil && len(result.Logs) > 0 {
-		r.logs = result.Logs
+		r.logs = maskLogs(masker, result.Logs)
 	}
 
 	if err != nil {
+		r.fnError = true
 		return r.Error(err)
 	}
 
@@ -293,6 +309,8 @@ func (r *RequestServer) Dynamic() *shttp.Response {
 		return shttp.NoContent()
 	}
 
+	r.fnError = result.ErrorMessage != ""
+
 	if result.ErrorMessage != "" && result.StatusCode == 0 {
 		result.StatusCode = http.StatusInternalServerError
 		result.Body = []byte(result.ErrorMessage)
@@ -307,6 +325,19 @@ func (r *RequestServer) Dynamic() *shttp.Response {
 	return r.res
 }
 
+// maskLogs replaces secret values in function logs before they are
+// queued for insertion.
+func maskLogs(masker *utils.SecretMasker, logs []integrations.Log) []integrations.Log {
+	masked := make([]integrations.Log, 0, len(logs))
+
+	for _, log := range logs {
+		log.Message = masker.MaskString(log.Message)
+		masked = append(masked, log)
+	}
+
+	return masked
+}
+
 func (r *RequestServer) Error(requestErr error) *shttp.Response {
 	cnf := r.req.Host.Config
 	r.res = &shttp.Response{
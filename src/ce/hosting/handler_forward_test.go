@@ -556,6 +556,7 @@ func (s *HandlerForwardSuite) Test_Analytics() {
 				UserAgent:   null.StringFrom("mozilla test agent"),
 			},
 			TotalBandwidth: 112,
+			StatusCode:     http.StatusOK,
 		}, item)
 
 		return true
@@ -0,0 +1,377 @@
+package runner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/exec"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// ExitCodeStopped is the exit code of the runner when the deployment is stopped.
+const ExitCodeStopped = 128
+
+var ErrAgentUnauthorized = errors.New("agent token is invalid or revoked")
+
+// agentRetryInterval is the interval to wait before retrying a failed request.
+var agentRetryInterval = 5 * time.Second
+
+// agentClaimTimeout is the client timeout of claim requests. It needs to be
+// longer than the duration that the API holds a claim request.
+var agentClaimTimeout = 30 * time.Second
+
+// AgentOpts configures the agent mode of the runner.
+type AgentOpts struct {
+	URL         string   // The API url, for instance https://api.stormkit.io
+	Token       string   // The agent token created by the instance administrator
+	Labels      []string // Labels that environments can use to target this agent
+	Version     string   // The runner version that is reported to the API
+	RootDir     string   // The directory where deployments are checked out and built
+	Concurrency int      // The number of deployments built in parallel
+	Executable  string   // The runner executable that builds a deployment. Defaults to the current executable.
+}
+
+type agentJob struct {
+	DeploymentID string  `json:"deploymentId"`
+	Payload      Payload `json:"payload"`
+}
+
+// Agent pulls queued deployments from the API and builds them. Each deployment
+// is built in a separate runner process so that a failing or stopped build
+// does not affect the agent.
+type Agent struct {
+	opts              AgentOpts
+	heartbeatInterval time.Duration
+	mux               sync.Mutex
+	running           map[string]context.CancelFunc
+}
+
+// NewAgent returns a new agent instance.
+func NewAgent(opts AgentOpts) *Agent {
+	opts.URL = strings.TrimSuffix(opts.URL, "/")
+
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 1
+	}
+
+	if opts.RootDir == "" {
+		opts.RootDir = os.TempDir()
+	}
+
+	return &Agent{
+		opts:              opts,
+		heartbeatInterval: 15 * time.Second,
+		running:           map[string]context.CancelFunc{},
+	}
+}
+
+// Start registers the agent and builds the claimed deployments
+// until the context is cancelled.
+func (a *Agent) Start(ctx context.Context) error {
+	if a.opts.Executable == "" {
+		executable, err := os.Executable()
+
+		if err != nil {
+			return err
+		}
+
+		a.opts.Executable = executable
+	}
+
+	if err := a.register(); err != nil {
+		return err
+	}
+
+	slog.Infof("agent registered with labels: %s", strings.Join(a.opts.Labels, ", "))
+
+	go a.heartbeats(ctx)
+
+	wg := sync.WaitGroup{}
+	errs := make(chan error, a.opts.Concurrency)
+
+	for i := 0; i < a.opts.Concurrency; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			if err := a.work(ctx); err != nil {
+				errs <- err
+			}
+		}()
+	}
+
+	wg.Wait()
+	close(errs)
+
+	return <-errs
+}
+
+func (a *Agent) register() error {
+	data := struct {
+		HeartbeatInterval int `json:"heartbeatInterval"`
+	}{}
+
+	status, err := a.request("/agents/register", 0, map[string]any{
+		"labels":  a.opts.Labels,
+		"version": a.opts.Version,
+	}, &data)
+
+	if err != nil {
+		return err
+	}
+
+	if status == http.StatusUnauthorized || status == http.StatusForbidden {
+		return ErrAgentUnauthorized
+	}
+
+	if status != http.StatusOK {
+		return fmt.Errorf("agent registration failed with status %d", status)
+	}
+
+	if data.HeartbeatInterval > 0 {
+		a.heartbeatInterval = time.Duration(data.HeartbeatInterval) * time.Second
+	}
+
+	return nil
+}
+
+// work claims and builds deployments one at a time.
+func (a *Agent) work(ctx context.Context) error {
+	for ctx.Err() == nil {
+		job, err := a.claim()
+
+		if errors.Is(err, ErrAgentUnauthorized) {
+			return err
+		}
+
+		if err != nil {
+			slog.Errorf("error while claiming a job: %v", err)
+			wait(ctx, agentRetryInterval)
+			continue
+		}
+
+		if job != nil {
+			a.run(ctx, job)
+		}
+	}
+
+	return nil
+}
+
+func (a *Agent) claim() (*agentJob, error) {
+	data := struct {
+		Job *agentJob `json:"job"`
+	}{}
+
+	status, err := a.request("/agents/jobs/claim", agentClaimTimeout, map[string]any{}, &data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	switch status {
+	case http.StatusOK:
+		return data.Job, nil
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusUnauthorized, http.StatusForbidden:
+		return nil, ErrAgentUnauthorized
+	default:
+		return nil, fmt.Errorf("claim failed with status %d", status)
+	}
+}
+
+// run builds the deployment in a new runner process and reports back
+// to the API once the process exits.
+func (a *Agent) run(ctx context.Context, job *agentJob) {
+	jobCtx, cancel := context.WithCancel(ctx)
+
+	a.mux.Lock()
+	a.running[job.DeploymentID] = cancel
+	a.mux.Unlock()
+
+	defer func() {
+		cancel()
+
+		a.mux.Lock()
+		delete(a.running, job.DeploymentID)
+		a.mux.Unlock()
+	}()
+
+	job.Payload.RootDir = path.Join(a.opts.RootDir, fmt.Sprintf("deployment-%s", job.DeploymentID))
+
+	defer func() {
+		if err := os.RemoveAll(job.Payload.RootDir); err != nil {
+			slog.Errorf("could not remove root dir: %v", err)
+		}
+	}()
+
+	payload, err := json.Marshal(job.Payload)
+
+	if err != nil {
+		slog.Errorf("could not marshal payload: %v", err)
+		a.complete(job.DeploymentID, true)
+		return
+	}
+
+	slog.Infof("building deployment %s", job.DeploymentID)
+
+	// The payload contains the deployment configuration and the job token. It is
+	// passed through the standard input, as the arguments of a process are
+	// visible to the other processes on the machine.
+	cmd := exec.CommandContext(jobCtx, a.opts.Executable, "--payload", "-")
+	cmd.Stdin = bytes.NewReader(payload)
+	cmd.Env = a.env()
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.SysProcAttr = agentSysProcAttr()
+	cmd.WaitDelay = 10 * time.Second
+	cmd.Cancel = func() error {
+		return killProcessGroup(cmd)
+	}
+
+	err = cmd.Run()
+
+	// The agent is shutting down. The deployment is handed to another
+	// agent once the heartbeat times out.
+	if ctx.Err() != nil {
+		return
+	}
+
+	// The runner reports the result of the deployment by itself. It is
+	// only considered failed when the process exits unexpectedly.
+	failed := err != nil && jobCtx.Err() == nil && !isStopped(err)
+
+	if failed {
+		slog.Errorf("runner exited unexpectedly for deployment %s: %v", job.DeploymentID, err)
+	}
+
+	a.complete(job.DeploymentID, failed)
+}
+
+func (a *Agent) complete(deploymentID string, failed bool) {
+	status, err := a.request("/agents/jobs/complete", 0, map[string]any{
+		"deploymentId": deploymentID,
+		"failed":       failed,
+	}, nil)
+
+	if err != nil {
+		slog.Errorf("error while completing deployment %s: %v", deploymentID, err)
+	} else if status != http.StatusOK {
+		slog.Infof("deployment %s was not completed, status: %d", deploymentID, status)
+	}
+}
+
+// heartbeats keeps the agent alive and stops the deployments
+// that were stopped or handed to another agent.
+func (a *Agent) heartbeats(ctx context.Context) {
+	ticker := time.NewTicker(a.heartbeatInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			a.heartbeat()
+		}
+	}
+}
+
+func (a *Agent) heartbeat() {
+	a.mux.Lock()
+	ids := []string{}
+
+	for id := range a.running {
+		ids = append(ids, id)
+	}
+
+	a.mux.Unlock()
+
+	data := struct {
+		Cancelled []string `json:"cancelled"`
+	}{}
+
+	status, err := a.request("/agents/heartbeat", 0, map[string]any{"deploymentIds": ids}, &data)
+
+	if err != nil || status != http.StatusOK {
+		slog.Errorf("heartbeat failed, status: %d, error: %v", status, err)
+		return
+	}
+
+	a.mux.Lock()
+	defer a.mux.Unlock()
+
+	for _, id := range data.Cancelled {
+		if cancel, ok := a.running[id]; ok {
+			slog.Infof("stopping deployment %s", id)
+			cancel()
+		}
+	}
+}
+
+// env returns the environment variables of the runner process.
+// The agent token is not passed to the builds.
+func (a *Agent) env() []string {
+	env := []string{}
+
+	for _, v := range os.Environ() {
+		if !strings.HasPrefix(v, "STORMKIT_AGENT_TOKEN=") {
+			env = append(env, v)
+		}
+	}
+
+	return env
+}
+
+func (a *Agent) request(endpoint string, timeout time.Duration, payload, out any) (int, error) {
+	req := shttp.NewRequestV2(shttp.MethodPost, a.opts.URL+endpoint).
+		Headers(shttp.HeadersFromMap(map[string]string{
+			"Authorization": fmt.Sprintf("Bearer %s", a.opts.Token),
+			"Content-Type":  "application/json",
+		})).
+		Payload(payload)
+
+	if timeout > 0 {
+		req = req.WithTimeout(timeout)
+	}
+
+	res, err := req.Do()
+
+	if err != nil {
+		return 0, err
+	}
+
+	defer res.Body.Close()
+
+	if out != nil && res.StatusCode == http.StatusOK {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			return res.StatusCode, err
+		}
+	}
+
+	return res.StatusCode, nil
+}
+
+func isStopped(err error) bool {
+	var exitErr *exec.ExitError
+	return errors.As(err, &exitErr) && exitErr.ExitCode() == ExitCodeStopped
+}
+
+func wait(ctx context.Context, d time.Duration) {
+	select {
+	case <-ctx.Done():
+	case <-time.After(d):
+	}
+}
@@ -0,0 +1,207 @@
+package runner_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/runner"
+	"github.com/stretchr/testify/suite"
+)
+
+type AgentSuite struct {
+	suite.Suite
+
+	mux       sync.Mutex
+	tmpDir    string
+	jobs      []string
+	cancelled []string
+	completed chan map[string]any
+	requests  []string
+}
+
+func (s *AgentSuite) BeforeTest(_, _ string) {
+	tmpDir, err := os.MkdirTemp("", "tmp-test-agent-")
+	s.NoError(err)
+
+	s.tmpDir = tmpDir
+	s.jobs = []string{}
+	s.cancelled = []string{}
+	s.requests = []string{}
+	s.completed = make(chan map[string]any, 10)
+}
+
+func (s *AgentSuite) AfterTest(_, _ string) {
+	os.RemoveAll(s.tmpDir)
+}
+
+// executable creates a fake runner that executes the given script.
+func (s *AgentSuite) executable(script string) string {
+	file := path.Join(s.tmpDir, "runner.sh")
+	s.NoError(os.WriteFile(file, []byte("#!/bin/sh\n"+script), 0755))
+	return file
+}
+
+func (s *AgentSuite) server() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.mux.Lock()
+		defer s.mux.Unlock()
+
+		if r.Header.Get("Authorization") != "Bearer SKA_token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		s.requests = append(s.requests, r.URL.Path)
+		body := map[string]any{}
+		_ = json.NewDecoder(r.Body).Decode(&body)
+
+		switch r.URL.Path {
+		case "/agents/register":
+			_ = json.NewEncoder(w).Encode(map[string]any{"heartbeatInterval": 1})
+		case "/agents/jobs/claim":
+			if len(s.jobs) == 0 {
+				w.WriteHeader(http.StatusNoContent)
+				return
+			}
+
+			id := s.jobs[0]
+			s.jobs = s.jobs[1:]
+
+			_ = json.NewEncoder(w).Encode(map[string]any{
+				"job": map[string]any{
+					"deploymentId": id,
+					"payload": map[string]any{
+						"baseUrl":      "http://localhost",
+						"deploymentId": id,
+						"jobToken":     "job-token-" + id,
+					},
+				},
+			})
+		case "/agents/heartbeat":
+			_ = json.NewEncoder(w).Encode(map[string]any{"cancelled": s.cancelled})
+		case "/agents/jobs/complete":
+			s.completed <- body
+			_ = json.NewEncoder(w).Encode(map[string]any{"ok": true})
+		}
+	}))
+}
+
+func (s *AgentSuite) start(ctx context.Context, srv *httptest.Server, script string) chan error {
+	agent := runner.NewAgent(runner.AgentOpts{
+		URL:        srv.URL,
+		Token:      "SKA_token",
+		Labels:     []string{"arm64"},
+		RootDir:    s.tmpDir,
+		Executable: s.executable(script),
+	})
+
+	errs := make(chan error, 1)
+
+	go func() {
+		errs <- agent.Start(ctx)
+	}()
+
+	return errs
+}
+
+func (s *AgentSuite) waitForCompletion() map[string]any {
+	select {
+	case body := <-s.completed:
+		return body
+	case <-time.After(10 * time.Second):
+		s.FailNow("deployment was not completed")
+		return nil
+	}
+}
+
+func (s *AgentSuite) Test_RunsClaimedJobs() {
+	srv := s.server()
+	defer srv.Close()
+
+	s.jobs = []string{"1", "2"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The second deployment crashes without reporting back
+	errs := s.start(ctx, srv, `grep -q '"deploymentId":"2"' && exit 1; exit 0`)
+
+	s.Equal(map[string]any{"deploymentId": "1", "failed": false}, s.waitForCompletion())
+	s.Equal(map[string]any{"deploymentId": "2", "failed": true}, s.waitForCompletion())
+
+	cancel()
+	s.NoError(<-errs)
+}
+
+func (s *AgentSuite) Test_PassesPayloadThroughStdin() {
+	srv := s.server()
+	defer srv.Close()
+
+	s.jobs = []string{"1"}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	argsFile := path.Join(s.tmpDir, "args")
+	payloadFile := path.Join(s.tmpDir, "payload")
+	errs := s.start(ctx, srv, fmt.Sprintf(`echo "$@" > %s; cat > %s`, argsFile, payloadFile))
+
+	s.Equal(map[string]any{"deploymentId": "1", "failed": false}, s.waitForCompletion())
+
+	// The job token is not visible in the arguments of the runner process
+	args, err := os.ReadFile(argsFile)
+	s.NoError(err)
+	s.Equal("--payload -\n", string(args))
+
+	payload, err := os.ReadFile(payloadFile)
+	s.NoError(err)
+	s.Contains(string(payload), `"jobToken":"job-token-1"`)
+
+	cancel()
+	s.NoError(<-errs)
+}
+
+func (s *AgentSuite) Test_StoppedDeployments() {
+	srv := s.server()
+	defer srv.Close()
+
+	s.jobs = []string{"1"}
+	s.cancelled = []string{"1"}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	errs := s.start(ctx, srv, fmt.Sprintf("sleep 30; exit %d", 0))
+
+	// The deployment is stopped through the heartbeat, which is not a failure
+	s.Equal(map[string]any{"deploymentId": "1", "failed": false}, s.waitForCompletion())
+	s.mux.Lock()
+	s.Contains(s.requests, "/agents/heartbeat")
+	s.mux.Unlock()
+
+	cancel()
+	s.NoError(<-errs)
+}
+
+func (s *AgentSuite) Test_InvalidToken() {
+	srv := s.server()
+	defer srv.Close()
+
+	agent := runner.NewAgent(runner.AgentOpts{
+		URL:        srv.URL,
+		Token:      "SKA_invalid",
+		Executable: s.executable("exit 0"),
+	})
+
+	s.ErrorIs(agent.Start(context.Background()), runner.ErrAgentUnauthorized)
+}
+
+func TestAgentSuite(t *testing.T) {
+	suite.Run(t, &AgentSuite{})
+}
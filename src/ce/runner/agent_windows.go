@@ -0,0 +1,24 @@
+//go:build windows
+
+package runner
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// agentSysProcAttr starts the runner in a new process group.
+func agentSysProcAttr() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{
+		CreationFlags: syscall.CREATE_NEW_PROCESS_GROUP,
+	}
+}
+
+// killProcessGroup kills the runner process.
+func killProcessGroup(cmd *exec.Cmd) error {
+	if cmd.Process == nil {
+		return nil
+	}
+
+	return cmd.Process.Kill()
+}
@@ -55,16 +55,18 @@ type BundlerInterface interface {
 var DefaultBundler BundlerInterface
 
 type Bundler struct {
-	workDir       string   // Absolute path
-	repoDir       string   // Absolute path
-	distDir       string   // Absolute path where the zip files will be uploaded (not the same with Build.DistDir)
-	clientDirs    []string // The directories to look for client-side files
-	serverDirs    []string // The directories to look for server-side files
-	apiDirs       []string // The directories to look for api files
-	serverCmd     string   // The command to spin up the Node.js server
-	headersFile   string   // Relative path to the headers file (from working dir)
-	redirectsFile string   // Relative path to the redirects file (from working dir)
-	apiFolder     string   // Relative path to the api dir (from working dir)
+	workDir       string            // Absolute path
+	repoDir       string            // Absolute path
+	distDir       string            // Absolute path where the zip files will be uploaded (not the same with Build.DistDir)
+	clientDirs    []string          // The directories to look for client-side files
+	serverDirs    []string          // The directories to look for server-side files
+	apiDirs       []string          // The directories to look for api files
+	serverCmd     string            // The command to spin up the Node.js server
+	headersFile   string            // Relative path to the headers file (from working dir)
+	redirectsFile string            // Relative path to the redirects file (from working dir)
+	headers       string            // Headers declared in the stormkit config file
+	redirects     []deploy.Redirect // Redirects declared in the stormkit config file
+	apiFolder     string            // Relative path to the api dir (from working dir)
 	packageJson   *PackageJson
 	reporter      *ReporterModel
 }
@@ -197,9 +199,16 @@ func (a *Artifacts) CDNFiles() []deploy.CDNFile {
 				a.Headers,
 			)
 
+			var size int64
+
+			if fi, err := info.Info(); err == nil {
+				size = fi.Size()
+			}
+
 			files = append(files, deploy.CDNFile{
 				Name:    fileName,
 				Headers: headers,
+				Size:    size,
 			})
 
 			// This will prevent adding the same file
@@ -327,6 +336,8 @@ func NewBundler(opts RunnerOpts) BundlerInterface {
 		apiDirs:       apiDirs,
 		headersFile:   opts.Build.HeadersFile,
 		redirectsFile: opts.Build.RedirectsFile,
+		headers:       opts.Build.Headers,
+		redirects:     opts.Build.Redirects,
 		serverCmd:     opts.Build.ServerCmd,
 		apiFolder:     opts.Build.APIFolder,
 		packageJson:   opts.Repo.PackageJson,
@@ -799,6 +810,21 @@ func (b Bundler) Zip(artifacts *Artifacts) error {
 // artifacts objects with the headers. This requires the
 // `headersFile` property to be set on the deployment object.
 func (b Bundler) ParseHeaders(artifacts *Artifacts) error {
+	// Headers declared in the stormkit config file
+	if b.headers != "" {
+		headers, err := deploy.ParseHeaders(b.headers)
+
+		if err != nil {
+			b.reporter.AddStep("parsing headers failed")
+			b.reporter.AddLine(err.Error())
+			return err
+		}
+
+		defer func() {
+			artifacts.Headers = append(artifacts.Headers, headers...)
+		}()
+	}
+
 	if b.headersFile == "" {
 		return nil
 	}
@@ -869,10 +895,13 @@ func (b Bundler) ParseRedirects(artifacts *Artifacts) error {
 				return err
 			}
 
-			return nil
+			break
 		}
 	}
 
+	// Redirects declared in the stormkit config file
+	artifacts.Redirects = append(artifacts.Redirects, b.redirects...)
+
 	return nil
 }
 
@@ -491,6 +491,7 @@ func (s *BundlerSuite) Test_CDNFiles() {
 	s.Equal([]deploy.CDNFile{
 		{
 			Name: "/templates/index.html",
+			Size: 11,
 			Headers: map[string]string{
 				"x-fr": "DENY",
 				"x-pr": "1; mode=block",
@@ -499,6 +500,7 @@ func (s *BundlerSuite) Test_CDNFiles() {
 		},
 		{
 			Name: "/templates/index2.html",
+			Size: 13,
 			Headers: map[string]string{
 				"x-fr": "SAMEORIGIN",
 				"etag": `"20-3d0b9a7a7d1882824e95fcc401aedf12d9fc7106"`,
@@ -506,6 +508,7 @@ func (s *BundlerSuite) Test_CDNFiles() {
 		},
 		{
 			Name: "/templates/my.jpg",
+			Size: 11,
 			Headers: map[string]string{
 				"x-sk": "",
 				"etag": `"20-9f879f26935916f6950366bc0bbd977effaded83"`,
@@ -7,6 +7,7 @@ import (
 	"sync"
 
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
 const ansi = "[\u001B\u009B][[\\]()#;?]*(?:(?:(?:[a-zA-Z\\d]*(?:;[a-zA-Z\\d]*)*)?\u0007)|(?:(?:\\d{1,4}(?:;\\d{0,4})*)?[\\dA-PRZcf-ntqry=><~]))"
@@ -17,6 +18,8 @@ type CustomBuffer struct {
 	ansiRegexp      *regexp.Regexp
 	mu              sync.Mutex
 	isStormkitCloud bool
+	masker          *utils.SecretMasker
+	pending         []byte
 }
 
 func NewCustomBuffer() *CustomBuffer {
@@ -27,6 +30,25 @@ func NewCustomBuffer() *CustomBuffer {
 	}
 }
 
+// SetMasker sets the masker that is used to hide secrets before
+// they are written into the buffer.
+func (cb *CustomBuffer) SetMasker(masker *utils.SecretMasker) {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+	cb.masker = masker
+}
+
+// Flush writes the bytes that were held back by the previous write.
+func (cb *CustomBuffer) Flush() {
+	cb.mu.Lock()
+	defer cb.mu.Unlock()
+
+	if len(cb.pending) > 0 {
+		cb.write(cb.masker.Mask(cb.pending))
+		cb.pending = nil
+	}
+}
+
 func (cb *CustomBuffer) Write(p []byte) (n int, err error) {
 	cb.mu.Lock()
 	defer cb.mu.Unlock()
@@ -37,6 +59,26 @@ func (cb *CustomBuffer) Write(p []byte) (n int, err error) {
 		input = bytes.Replace(input, []byte("/home/runner/work/deployer-service/deployer-service"), []byte("/home/app"), -1)
 	}
 
+	input = append(cb.pending, input...)
+	cb.pending = nil
+
+	// A secret may be split across writes: when the input ends with the beginning
+	// of a secret, hold back its last line until the next write completes it.
+	if n := cb.masker.Pending(input); n > 0 {
+		start := bytes.LastIndexByte(input[:len(input)-n], '\n') + 1
+		cb.pending = bytes.Clone(input[start:])
+		input = input[:start]
+	}
+
+	// Never leak secrets into the deployment logs
+	cb.write(cb.masker.Mask(input))
+
+	// We always need to return the length of `p`:
+	// See https://www.reddit.com/r/golang/comments/2xufjb/cmdrun_returning_short_write_as_error_why_does
+	return len(p), nil
+}
+
+func (cb *CustomBuffer) write(input []byte) {
 	lines := bytes.Split(input, []byte("\n"))
 
 	// Clean carriage returns
@@ -53,10 +95,6 @@ func (cb *CustomBuffer) Write(p []byte) (n int, err error) {
 		line = bytes.ReplaceAll(line, []byte("\x00"), []byte("")) // Remove null bytes
 		cb.output = append(cb.output, line...)
 	}
-
-	// We always need to return the length of `p`:
-	// See https://www.reddit.com/r/golang/comments/2xufjb/cmdrun_returning_short_write_as_error_why_does
-	return len(p), nil
 }
 
 func (cb *CustomBuffer) Read(p []byte) (int, error) {
@@ -28,6 +28,7 @@ type Repo struct {
 	accessToken string
 	provider    string
 	branch      string // The branch to checkout
+	commit      string // The commit to checkout, defaults to the head of the branch
 	workDir     string
 	vars        map[string]string
 	varsRaw     []string
@@ -52,6 +53,7 @@ func NewRepo(opts RunnerOpts) RepoInterface {
 		address:     opts.Repo.Address,
 		accessToken: opts.Repo.AccessToken,
 		branch:      opts.Repo.Branch,
+		commit:      opts.Repo.Commit,
 		workDir:     opts.WorkDir,
 		vars:        opts.Build.EnvVars,
 		varsRaw:     opts.Build.EnvVarsRaw,
@@ -135,6 +137,43 @@ func (r Repo) Checkout(ctx context.Context) error {
 		return err
 	}
 
+	if r.commit == "" || strings.HasPrefix(r.HeadSHA(), r.commit) {
+		return nil
+	}
+
+	// The clone is shallow, therefore the commit needs to be fetched separately.
+	for _, args := range [][]string{
+		{"fetch", "--depth", "1", "origin", r.commit},
+		{"-c", "advice.detachedHead=false", "checkout", "FETCH_HEAD"},
+	} {
+		cmd = sys.Command(ctx, sys.CommandOpts{
+			Name:   "git",
+			Args:   args,
+			Dir:    r.dir,
+			Env:    r.varsRaw,
+			Stdout: r.reporter.File(),
+			Stderr: r.reporter.File(),
+		})
+
+		if ssh != "" {
+			cmd = sys.Command(ctx, sys.CommandOpts{
+				Name:   "sh",
+				Args:   []string{"-c", fmt.Sprintf("%s %s", ssh, cmd.String())},
+				Dir:    r.dir,
+				Env:    r.varsRaw,
+				Stdout: r.reporter.File(),
+				Stderr: r.reporter.File(),
+			})
+		}
+
+		// Some providers do not allow fetching commits by their SHA. In that
+		// case the head of the branch that is already checked out is deployed.
+		if err := cmd.Run(); err != nil {
+			r.reporter.AddLine(fmt.Sprintf("cannot checkout commit %s, deploying the latest commit of %s instead: %s", r.commit, r.branch, err.Error()))
+			return nil
+		}
+	}
+
 	return nil
 }
 
@@ -2,6 +2,7 @@ package runner_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
 	"strings"
@@ -123,6 +124,110 @@ func (s *RepoSuite) Test_Checkout_Github() {
 	s.NoError(r.Checkout(context.Background()))
 }
 
+func (s *RepoSuite) Test_Checkout_Commit() {
+	opts := s.config
+	opts.Repo.Address = "https://github.com/stormkit-dev/e2e-npm"
+	opts.Repo.Branch = "main"
+	opts.Repo.Commit = "8a6d1c2e"
+
+	r := runner.NewRepo(opts)
+
+	s.mockCmd.On("SetOpts", sys.CommandOpts{
+		Name: "git",
+		Args: []string{
+			"clone",
+			"https://github.com/stormkit-dev/e2e-npm",
+			"--depth", "1",
+			"--progress",
+			"--single-branch",
+			"--branch", "main",
+			s.config.Repo.Dir,
+		},
+		Env:    s.config.Build.EnvVarsRaw,
+		Stderr: s.config.Reporter.File(),
+		Stdout: s.config.Reporter.File(),
+	}).Return(s.mockCmd).Once()
+
+	s.mockCmd.On("SetOpts", sys.CommandOpts{
+		Name: "git",
+		Args: []string{"rev-parse", "HEAD"},
+		Dir:  s.config.Repo.Dir,
+		Env:  s.config.Build.EnvVarsRaw,
+	}).Return(s.mockCmd).Once()
+
+	s.mockCmd.On("Output").Return([]byte("790dcef2a8c61ff6011a4b595cdcb2f0de6c4e2b\n"), nil).Once()
+
+	for _, args := range [][]string{
+		{"fetch", "--depth", "1", "origin", "8a6d1c2e"},
+		{"-c", "advice.detachedHead=false", "checkout", "FETCH_HEAD"},
+	} {
+		s.mockCmd.On("SetOpts", sys.CommandOpts{
+			Name:   "git",
+			Args:   args,
+			Dir:    s.config.Repo.Dir,
+			Env:    s.config.Build.EnvVarsRaw,
+			Stderr: s.config.Reporter.File(),
+			Stdout: s.config.Reporter.File(),
+		}).Return(s.mockCmd).Once()
+	}
+
+	s.mockCmd.On("Run").Return(nil, nil).Times(3)
+
+	s.NoError(r.Checkout(context.Background()))
+	s.mockCmd.AssertExpectations(s.T())
+}
+
+func (s *RepoSuite) Test_Checkout_Commit_Fallback() {
+	opts := s.config
+	opts.Repo.Address = "https://github.com/stormkit-dev/e2e-npm"
+	opts.Repo.Branch = "main"
+	opts.Repo.Commit = "8a6d1c2e"
+
+	r := runner.NewRepo(opts)
+
+	s.mockCmd.On("SetOpts", sys.CommandOpts{
+		Name: "git",
+		Args: []string{
+			"clone",
+			"https://github.com/stormkit-dev/e2e-npm",
+			"--depth", "1",
+			"--progress",
+			"--single-branch",
+			"--branch", "main",
+			s.config.Repo.Dir,
+		},
+		Env:    s.config.Build.EnvVarsRaw,
+		Stderr: s.config.Reporter.File(),
+		Stdout: s.config.Reporter.File(),
+	}).Return(s.mockCmd).Once()
+
+	s.mockCmd.On("SetOpts", sys.CommandOpts{
+		Name: "git",
+		Args: []string{"rev-parse", "HEAD"},
+		Dir:  s.config.Repo.Dir,
+		Env:  s.config.Build.EnvVarsRaw,
+	}).Return(s.mockCmd).Once()
+
+	s.mockCmd.On("Output").Return([]byte("790dcef2a8c61ff6011a4b595cdcb2f0de6c4e2b\n"), nil).Once()
+
+	s.mockCmd.On("SetOpts", sys.CommandOpts{
+		Name:   "git",
+		Args:   []string{"fetch", "--depth", "1", "origin", "8a6d1c2e"},
+		Dir:    s.config.Repo.Dir,
+		Env:    s.config.Build.EnvVarsRaw,
+		Stderr: s.config.Reporter.File(),
+		Stdout: s.config.Reporter.File(),
+	}).Return(s.mockCmd).Once()
+
+	s.mockCmd.On("Run").Return(nil).Once()
+	s.mockCmd.On("Run").Return(errors.New("couldn't find remote ref")).Once()
+
+	// The head of the branch is deployed when the commit cannot be fetched
+	s.NoError(r.Checkout(context.Background()))
+	s.mockCmd.AssertExpectations(s.T())
+	s.Contains(s.config.Reporter.Logs(), "cannot checkout commit 8a6d1c2e, deploying the latest commit of main instead")
+}
+
 func (s *RepoSuite) Test_CommitInfo() {
 	// Head SHA
 	s.mockCmd.On("SetOpts", sys.CommandOpts{
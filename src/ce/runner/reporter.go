@@ -7,14 +7,17 @@ import (
 	"sync"
 	"time"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
 type ReporterModel struct {
 	file        *CustomBuffer
+	masker      *utils.SecretMasker
 	baseURL     string
 	CallbackURL string
 	done        chan struct{}
@@ -24,6 +27,7 @@ type ReporterModel struct {
 
 // These are manipulated by external packages
 var DeploymentIDEnc string
+var JobToken string
 var FlagPrintLogs bool
 
 func NewReporter(baseURL string) *ReporterModel {
@@ -35,6 +39,26 @@ func NewReporter(baseURL string) *ReporterModel {
 	}
 }
 
+// MaskSecrets makes sure that the values of the given secret variables
+// are replaced in the logs. Additional values can be provided to be masked
+// as well, such as access tokens.
+func (r *ReporterModel) MaskSecrets(vars map[string]string, values ...string) {
+	r.masker = utils.NewSecretMasker(vars)
+
+	for _, value := range values {
+		r.masker.AddSecret(value)
+	}
+
+	if r.file != nil {
+		r.file.SetMasker(r.masker)
+	}
+}
+
+// Mask replaces the secrets in the given text, in the same way as in the logs.
+func (r *ReporterModel) Mask(text string) string {
+	return r.masker.MaskString(text)
+}
+
 func (r *ReporterModel) request(payload map[string]any) error {
 	res, err := shttp.NewRequestV2(shttp.MethodPost, r.CallbackURL).
 		WithExponentialBackoff(time.Second*30, 5).
@@ -47,7 +71,7 @@ func (r *ReporterModel) request(payload map[string]any) error {
 
 	if res.StatusCode == http.StatusConflict {
 		slog.Infof("received exit signal - quitting")
-		os.Exit(128)
+		os.Exit(ExitCodeStopped)
 	}
 
 	return err
@@ -61,6 +85,11 @@ func (r *ReporterModel) headers() http.Header {
 	headers := make(http.Header)
 	headers.Add("Accept", "application/json")
 	headers.Add("Content-Type", "application/json")
+
+	if JobToken != "" {
+		headers.Add("X-Stormkit-Job-Token", JobToken)
+	}
+
 	return headers
 }
 
@@ -117,24 +146,51 @@ func (r *ReporterModel) SendCommitInfo(info map[string]string) error {
 	})
 }
 
+// SendStormkitFile sends the parsed stormkit config file so that the
+// effective configuration is recorded on the deployment.
+func (r *ReporterModel) SendStormkitFile(file *buildconf.StormkitFile) error {
+	if r.baseURL == "" {
+		return nil
+	}
+
+	return r.request(map[string]any{
+		"deployId":     DeploymentIDEnc,
+		"stormkitFile": file,
+	})
+}
+
+// SendSBOM sends the software bill of materials of the deployment.
+func (r *ReporterModel) SendSBOM(sbom *deploy.SBOM) error {
+	if r.baseURL == "" || sbom == nil {
+		return nil
+	}
+
+	return r.request(map[string]any{
+		"deployId": DeploymentIDEnc,
+		"sbom":     sbom,
+	})
+}
+
 // LockDeployment should be called only after status checks are called.
 // If a deployment has no status checks, the exit callback will lock
 // the deployment automatically.
-func (r *ReporterModel) LockDeployment(isSuccess bool) error {
+func (r *ReporterModel) LockDeployment(results deploy.StatusCheckResults) error {
 	outcome := "failure"
 
-	if isSuccess {
+	if results.Passed() {
 		outcome = "success"
 	}
 
 	// Send remaining logs
+	r.file.Flush()
 	r.sendLogs()
 
 	return r.request(map[string]any{
-		"deployId":        DeploymentIDEnc,
-		"outcome":         outcome,
-		"hasStatusChecks": true,
-		"lock":            true,
+		"deployId":           DeploymentIDEnc,
+		"outcome":            outcome,
+		"hasStatusChecks":    true,
+		"lock":               true,
+		"statusCheckResults": results,
 	})
 }
 
@@ -156,10 +212,12 @@ func (r *ReporterModel) SendExit(manifest *deploy.BuildManifest, result *integra
 	}
 
 	// Send last remaining bits
+	r.file.Flush()
 	r.sendLogs()
 
 	// Create a new buffer for status checks
 	r.file = NewCustomBuffer()
+	r.file.SetMasker(r.masker)
 
 	err := r.request(map[string]any{
 		"deployId":        DeploymentIDEnc,
@@ -7,7 +7,6 @@ import (
 	"log"
 	"os"
 	"path"
-	"regexp"
 	"sort"
 	"strings"
 
@@ -59,10 +58,8 @@ func normalize(msg *deployservice.DeploymentMessage) *deployservice.DeploymentMe
 func printEnvVariables(opts RunnerOpts) error {
 	vars := []string{}
 
-	obfuscate := regexp.MustCompile("(?i)secret|_key|_token|password")
-
 	for k, v := range opts.Build.EnvVars {
-		if obfuscate.Match([]byte(k)) {
+		if utils.IsSecretKey(k) || utils.InSliceStringCS(opts.Build.Secrets, k) {
 			vars = append(vars, fmt.Sprintf("%s=***************", k))
 		} else {
 			vars = append(vars, fmt.Sprintf("%s=%s", k, v))
@@ -85,12 +82,19 @@ type Payload struct {
 	RootDir       string `json:"rootDir"`
 	DeploymentID  string `json:"deploymentId"`
 	DeploymentMsg string `json:"deploymentMsg"` // Encrypted
+
+	// The following fields are set by build agents, which do not know the
+	// app secret. When empty, the app secret is used instead.
+	DeploymentIDEnc string                           `json:"deploymentIdEnc,omitempty"` // The encrypted deployment id
+	Deployment      *deployservice.DeploymentMessage `json:"deployment,omitempty"`      // The deployment message, received over TLS
+	JobToken        string                           `json:"jobToken,omitempty"`        // Authenticates the callbacks of the claimed job
 }
 
 type RepoOpts struct {
 	Dir             string
 	Address         string
 	Branch          string
+	Commit          string
 	AccessToken     string
 	PackageJson     *PackageJson
 	PackageLockFile bool
@@ -129,6 +133,7 @@ func parsePackageJson(packageJsonPath string) *PackageJson {
 }
 
 type BuildOpts struct {
+	Env           string
 	BuildCmd      string
 	InstallCmd    string
 	ServerCmd     string
@@ -137,8 +142,11 @@ type BuildOpts struct {
 	RedirectsFile string
 	APIFolder     string            // Relative path to the API folder (trimmed)
 	DistFolder    string            // Relative path to the distribution folder (trimmed)
+	Headers       string            // Custom headers declared in the stormkit config file
+	Redirects     []deploy.Redirect // Redirects declared in the stormkit config file
 	EnvVars       map[string]string // Normalized environment variables
 	EnvVarsRaw    []string          // Raw environment variables in KEY=VALUE format
+	Secrets       []string          // Names of the environment variables that come from secrets
 	DeploymentID  string
 	AppID         string
 	EnvID         string
@@ -190,13 +198,18 @@ func Start(payload, rootDir string) error {
 		p.RootDir = rootDir
 	}
 
-	msg, err := deployservice.FromEncrypted(p.DeploymentMsg)
+	msg := p.Deployment
 
-	if err != nil {
-		return err
+	if msg == nil {
+		var err error
+
+		if msg, err = deployservice.FromEncrypted(p.DeploymentMsg); err != nil {
+			return err
+		}
 	}
 
-	DeploymentIDEnc = utils.EncryptID(utils.StringToID(p.DeploymentID))
+	DeploymentIDEnc = utils.GetString(p.DeploymentIDEnc, utils.EncryptID(utils.StringToID(p.DeploymentID)))
+	JobToken = p.JobToken
 
 	msg = normalize(msg)
 	repoDir := path.Join(p.RootDir, "repo")
@@ -212,11 +225,13 @@ func Start(payload, rootDir string) error {
 			Dir:         repoDir,
 			Address:     msg.Client.Repo,
 			Branch:      msg.Build.Branch,
+			Commit:      msg.Build.Commit,
 			AccessToken: msg.Client.AccessToken,
 			PackageJson: nil, // will be determined later
 		},
 		Build: BuildOpts{
 			DeploymentID:  p.DeploymentID,
+			Env:           msg.Build.Env,
 			AppID:         msg.Build.AppID,
 			EnvID:         msg.Build.EnvID,
 			BuildCmd:      msg.Build.BuildCmd,
@@ -229,6 +244,7 @@ func Start(payload, rootDir string) error {
 			DistFolder:    trim(msg.Build.DistFolder),
 			StatusChecks:  msg.Build.StatusChecks,
 			EnvVars:       msg.Build.Vars,
+			Secrets:       msg.Build.Secrets,
 			EnvVarsRaw: []string{
 				"CI=true",
 				fmt.Sprintf("PATH=%s", os.Getenv("PATH")),
@@ -246,6 +262,14 @@ func Start(payload, rootDir string) error {
 		opts.Build.EnvVars = make(map[string]string)
 	}
 
+	masked := []string{opts.Repo.AccessToken}
+
+	for _, name := range opts.Build.Secrets {
+		masked = append(masked, opts.Build.EnvVars[name])
+	}
+
+	opts.Reporter.MaskSecrets(opts.Build.EnvVars, masked...)
+
 	if err := opts.MkdirAll(); err != nil {
 		return err
 	}
@@ -326,6 +350,20 @@ func Run(opts RunnerOpts) *RunResult {
 		return &RunResult{opts: opts, err: err}
 	}
 
+	// The config file may override the build settings, so it is
+	// read before the dependencies are installed.
+	stormkitFile, err := LoadStormkitFile(&opts)
+
+	if err != nil {
+		return &RunResult{opts: opts, err: err}
+	}
+
+	if stormkitFile != nil {
+		if err := opts.Reporter.SendStormkitFile(stormkitFile); err != nil {
+			return &RunResult{opts: opts, err: err}
+		}
+	}
+
 	// Start sending the logs now (we first need to wait for commit info)
 	opts.Reporter.SendLogs()
 
@@ -353,6 +391,10 @@ func Run(opts RunnerOpts) *RunResult {
 		return &RunResult{opts: opts, err: err}
 	}
 
+	if err := generateSBOM(opts); err != nil {
+		return &RunResult{opts: opts, err: err}
+	}
+
 	builder := NewBuilder(opts)
 
 	if err := builder.ExecCommands(ctx); err != nil {
@@ -421,6 +463,31 @@ func Run(opts RunnerOpts) *RunResult {
 	return &RunResult{opts: opts, result: result, manifest: manifest}
 }
 
+// generateSBOM records the installed dependencies and checks them against
+// the advisory database, if one is configured.
+func generateSBOM(opts RunnerOpts) error {
+	sbom := NewSBOM(opts)
+	bom, err := sbom.Generate()
+
+	// A lockfile that cannot be parsed should not fail the deployment
+	if err != nil {
+		opts.Reporter.AddLine(fmt.Sprintf("[warning] cannot generate sbom: %s", err.Error()))
+		return nil
+	}
+
+	if bom == nil {
+		return nil
+	}
+
+	checkErr := sbom.CheckVulnerabilities(bom)
+
+	if err := opts.Reporter.SendSBOM(bom); err != nil {
+		return err
+	}
+
+	return checkErr
+}
+
 // GetRuntimeStringForLambdas returns the runtime string for the uploader based on
 // the given runtime and mise output.
 func GetRuntimeStringForLambdas(runtime string, miseOutput []string) string {
@@ -477,20 +544,12 @@ func PostRun(ctx context.Context, args *RunResult) {
 	}
 
 	if args.result != nil && hasStatusChecks {
-		checks := NewStatusChecks(args.opts)
-		success := true
-
-		for _, check := range args.opts.Build.StatusChecks {
-			if err := checks.Run(ctx, check.Cmd); err != nil {
-				success = false
-				break
-			}
-		}
+		results := NewStatusChecks(args.opts).RunAll(ctx, args.opts.Build.StatusChecks)
 
 		// Locking deployment is only necessary when there are status checks
 		// because if a deployment has no status checks, the exit callback will
 		// lock it automatically.
-		if err := args.opts.Reporter.LockDeployment(success); err != nil {
+		if err := args.opts.Reporter.LockDeployment(results); err != nil {
 			log.Fatalf("cannot lock deployment: %s", err.Error())
 		}
 	}
@@ -0,0 +1,478 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/goccy/go-yaml"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+)
+
+// SBOM is responsible for generating the software bill of materials
+// of the deployment and checking it against an advisory database.
+//
+// The following environment variables control the behaviour:
+//
+//   - SK_SBOM: set to "off" to disable generating the SBOM
+//   - SK_OSV_DATABASE: path to an OSV export (a json file or a folder of json files)
+//   - SK_VULNERABILITY_FAIL_LEVEL: low, moderate, high or critical. When a vulnerability
+//     with the given severity or above is found, the deployment fails. Otherwise,
+//     vulnerabilities are only reported as warnings.
+type SBOM struct {
+	opts RunnerOpts
+}
+
+// NewSBOM returns a new SBOM instance.
+func NewSBOM(opts RunnerOpts) *SBOM {
+	return &SBOM{opts: opts}
+}
+
+// Generate resolves the dependencies from the lockfile. When no lockfile
+// is found, it falls back to walking the node_modules folder.
+// It returns nil when the repository has no dependencies.
+func (s *SBOM) Generate() (*deploy.SBOM, error) {
+	if s.opts.Build.EnvVars["SK_SBOM"] == "off" {
+		return nil, nil
+	}
+
+	parsers := []struct {
+		name  string
+		parse func([]byte) ([]deploy.SBOMComponent, error)
+	}{
+		{name: "package-lock.json", parse: parsePackageLock},
+		{name: "yarn.lock", parse: parseYarnLock},
+		{name: "pnpm-lock.yaml", parse: parsePnpmLock},
+	}
+
+	for _, dir := range []string{s.opts.WorkDir, s.opts.Repo.Dir} {
+		if dir == "" {
+			continue
+		}
+
+		for _, parser := range parsers {
+			data, err := os.ReadFile(filepath.Join(dir, parser.name))
+
+			if err != nil {
+				continue
+			}
+
+			components, err := parser.parse(data)
+
+			if err != nil {
+				return nil, fmt.Errorf("cannot parse %s: %s", parser.name, err.Error())
+			}
+
+			return newSBOM(parser.name, components), nil
+		}
+	}
+
+	if components := s.nodeModules(); len(components) > 0 {
+		return newSBOM("node_modules", components), nil
+	}
+
+	return nil, nil
+}
+
+// nodeModules walks the node_modules folder starting from the dependencies
+// declared in the package.json file.
+func (s *SBOM) nodeModules() []deploy.SBOMComponent {
+	pkg := s.opts.Repo.PackageJson
+
+	if pkg == nil || len(pkg.Dependencies) == 0 {
+		return nil
+	}
+
+	deps := []string{}
+
+	for name := range pkg.Dependencies {
+		deps = append(deps, name)
+	}
+
+	tree := NewDepedencyTree(deps, filepath.Join(s.opts.WorkDir, "node_modules"))
+	tree.Walk()
+
+	components := []deploy.SBOMComponent{}
+
+	for _, dep := range tree.ResolvedDepedencies() {
+		pj := parsePackageJson(filepath.Join(dep.FullPath, "package.json"))
+
+		if pj == nil || pj.Version == "" {
+			continue
+		}
+
+		components = append(components, deploy.SBOMComponent{
+			Name:    dep.Name,
+			Version: pj.Version,
+		})
+	}
+
+	return components
+}
+
+// CheckVulnerabilities matches the components against the advisory database
+// that is specified with the SK_OSV_DATABASE environment variable. Found
+// vulnerabilities are recorded on the SBOM and logged as warnings. An error
+// is returned when a vulnerability reaches the configured fail level.
+func (s *SBOM) CheckVulnerabilities(sbom *deploy.SBOM) error {
+	dbPath := s.opts.Build.EnvVars["SK_OSV_DATABASE"]
+
+	if sbom == nil || dbPath == "" {
+		return nil
+	}
+
+	if !filepath.IsAbs(dbPath) {
+		dbPath = filepath.Join(s.opts.Repo.Dir, dbPath)
+	}
+
+	s.opts.Reporter.AddStep("[system] checking dependency vulnerabilities")
+
+	advisories, err := loadOSVDatabase(dbPath)
+
+	if err != nil {
+		return fmt.Errorf("cannot read the advisory database: %s", err.Error())
+	}
+
+	sbom.Vulnerabilities = matchVulnerabilities(sbom.Components, advisories)
+
+	if len(sbom.Vulnerabilities) == 0 {
+		s.opts.Reporter.AddLine("No known vulnerabilities found")
+		return nil
+	}
+
+	failLevel := strings.ToLower(strings.TrimSpace(s.opts.Build.EnvVars["SK_VULNERABILITY_FAIL_LEVEL"]))
+	failed := 0
+
+	for _, v := range sbom.Vulnerabilities {
+		line := fmt.Sprintf("[warning] %s %s@%s (%s): %s", v.ID, v.Package, v.Version, v.Severity, v.Summary)
+
+		if v.FixedIn != "" {
+			line = fmt.Sprintf("%s. Fixed in %s", line, v.FixedIn)
+		}
+
+		s.opts.Reporter.AddLine(line)
+
+		if failLevel != "" && deploy.SeverityLevel(v.Severity) >= deploy.SeverityLevel(failLevel) {
+			failed++
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("found %d vulnerabilities with %s severity or above", failed, failLevel)
+	}
+
+	return nil
+}
+
+func newSBOM(source string, components []deploy.SBOMComponent) *deploy.SBOM {
+	seen := map[string]bool{}
+	unique := []deploy.SBOMComponent{}
+
+	for _, c := range components {
+		if c.Name == "" || c.Version == "" || seen[c.Name+"@"+c.Version] {
+			continue
+		}
+
+		seen[c.Name+"@"+c.Version] = true
+		unique = append(unique, c)
+	}
+
+	sort.Slice(unique, func(i, j int) bool {
+		if unique[i].Name == unique[j].Name {
+			return compareVersions(unique[i].Version, unique[j].Version) < 0
+		}
+
+		return unique[i].Name < unique[j].Name
+	})
+
+	return &deploy.SBOM{
+		Source:      source,
+		GeneratedAt: time.Now().Unix(),
+		Components:  unique,
+	}
+}
+
+type packageLockDependency struct {
+	Version      string                           `json:"version"`
+	Integrity    string                           `json:"integrity"`
+	Dev          bool                             `json:"dev"`
+	Link         bool                             `json:"link"`
+	Dependencies map[string]packageLockDependency `json:"dependencies"`
+}
+
+// parsePackageLock parses package-lock.json files. Version 2 and 3 lockfiles
+// list the packages in a flat `packages` map, version 1 lockfiles nest them.
+func parsePackageLock(data []byte) ([]deploy.SBOMComponent, error) {
+	lock := struct {
+		Packages     map[string]packageLockDependency `json:"packages"`
+		Dependencies map[string]packageLockDependency `json:"dependencies"`
+	}{}
+
+	if err := json.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	components := []deploy.SBOMComponent{}
+
+	if len(lock.Packages) > 0 {
+		for key, pkg := range lock.Packages {
+			index := strings.LastIndex(key, "node_modules/")
+
+			// The root package and workspace folders are not dependencies
+			if index == -1 || pkg.Link {
+				continue
+			}
+
+			components = append(components, deploy.SBOMComponent{
+				Name:      key[index+len("node_modules/"):],
+				Version:   pkg.Version,
+				Integrity: pkg.Integrity,
+				Dev:       pkg.Dev,
+			})
+		}
+
+		return components, nil
+	}
+
+	var walk func(deps map[string]packageLockDependency)
+
+	walk = func(deps map[string]packageLockDependency) {
+		for name, dep := range deps {
+			components = append(components, deploy.SBOMComponent{
+				Name:      name,
+				Version:   dep.Version,
+				Integrity: dep.Integrity,
+				Dev:       dep.Dev,
+			})
+
+			walk(dep.Dependencies)
+		}
+	}
+
+	walk(lock.Dependencies)
+
+	return components, nil
+}
+
+// parseYarnLock parses yarn.lock files, both the classic (v1)
+// and the berry (v2+) formats.
+func parseYarnLock(data []byte) ([]deploy.SBOMComponent, error) {
+	components := []deploy.SBOMComponent{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+
+	var current *deploy.SBOMComponent
+
+	flush := func() {
+		if current != nil {
+			components = append(components, *current)
+			current = nil
+		}
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		trimmed := strings.TrimSpace(line)
+
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+
+		// A new entry: "@babel/core@^7.0.0", "@babel/core@^7.1.0":
+		if !strings.HasPrefix(line, " ") {
+			flush()
+
+			spec := strings.TrimSuffix(trimmed, ":")
+			spec = strings.TrimSpace(strings.SplitN(spec, ",", 2)[0])
+			spec = strings.Trim(spec, `"`)
+
+			if spec == "__metadata" {
+				continue
+			}
+
+			if index := strings.LastIndex(spec, "@"); index > 0 {
+				current = &deploy.SBOMComponent{Name: spec[:index]}
+			}
+
+			continue
+		}
+
+		if current == nil {
+			continue
+		}
+
+		key, value, ok := strings.Cut(trimmed, " ")
+
+		if !ok {
+			continue
+		}
+
+		value = strings.Trim(strings.TrimSpace(value), `"`)
+
+		switch strings.TrimSuffix(key, ":") {
+		case "version":
+			current.Version = value
+		case "integrity":
+			current.Integrity = value
+		}
+	}
+
+	flush()
+
+	// Workspace packages in berry lockfiles use the `workspace:` protocol
+	filtered := []deploy.SBOMComponent{}
+
+	for _, c := range components {
+		if strings.Contains(c.Name, "@workspace:") || strings.HasSuffix(c.Version, "-use.local") {
+			continue
+		}
+
+		// Berry specs look like `lodash@npm:^4.17.21`
+		c.Name = strings.TrimSuffix(c.Name, "@npm")
+		filtered = append(filtered, c)
+	}
+
+	return filtered, scanner.Err()
+}
+
+// parsePnpmLock parses pnpm-lock.yaml files. The package keys are in one of
+// the following formats depending on the lockfile version:
+//
+//   - /lodash/4.17.21 (v5)
+//   - /lodash@4.17.21 (v6)
+//   - lodash@4.17.21 (v9)
+func parsePnpmLock(data []byte) ([]deploy.SBOMComponent, error) {
+	lock := struct {
+		Packages map[string]struct {
+			Name       string `yaml:"name"`
+			Version    string `yaml:"version"`
+			Dev        bool   `yaml:"dev"`
+			Resolution struct {
+				Integrity string `yaml:"integrity"`
+			} `yaml:"resolution"`
+		} `yaml:"packages"`
+	}{}
+
+	if err := yaml.Unmarshal(data, &lock); err != nil {
+		return nil, err
+	}
+
+	components := []deploy.SBOMComponent{}
+
+	for key, pkg := range lock.Packages {
+		key = strings.TrimPrefix(key, "/")
+
+		// Drop the peer dependency suffix: react-dom@18.2.0(react@18.2.0)
+		if index := strings.Index(key, "("); index > 0 {
+			key = key[:index]
+		}
+
+		name, version := pkg.Name, pkg.Version
+
+		if index := strings.LastIndex(key, "@"); index > 0 {
+			name, version = firstNonEmpty(name, key[:index]), firstNonEmpty(version, key[index+1:])
+		} else if index := strings.LastIndex(key, "/"); index > 0 {
+			name, version = firstNonEmpty(name, key[:index]), firstNonEmpty(version, key[index+1:])
+		}
+
+		components = append(components, deploy.SBOMComponent{
+			Name:      name,
+			Version:   version,
+			Integrity: pkg.Resolution.Integrity,
+			Dev:       pkg.Dev,
+		})
+	}
+
+	return components, nil
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+
+	return ""
+}
+
+// compareVersions compares two semantic versions. It returns -1 when a < b,
+// 0 when a == b and 1 when a > b. Build metadata is ignored.
+func compareVersions(a, b string) int {
+	parse := func(v string) ([]int, string) {
+		v = strings.TrimPrefix(strings.TrimSpace(v), "v")
+		v, _, _ = strings.Cut(v, "+")
+		v, pre, _ := strings.Cut(v, "-")
+		nums := []int{0, 0, 0}
+
+		for i, piece := range strings.SplitN(v, ".", 3) {
+			nums[i], _ = strconv.Atoi(piece)
+		}
+
+		return nums, pre
+	}
+
+	an, apre := parse(a)
+	bn, bpre := parse(b)
+
+	for i := range an {
+		if an[i] != bn[i] {
+			if an[i] < bn[i] {
+				return -1
+			}
+
+			return 1
+		}
+	}
+
+	// A version without a pre-release has higher precedence
+	switch {
+	case apre == bpre:
+		return 0
+	case apre == "":
+		return 1
+	case bpre == "":
+		return -1
+	}
+
+	ap, bp := strings.Split(apre, "."), strings.Split(bpre, ".")
+
+	for i := 0; i < len(ap) && i < len(bp); i++ {
+		if ap[i] == bp[i] {
+			continue
+		}
+
+		ai, aerr := strconv.Atoi(ap[i])
+		bi, berr := strconv.Atoi(bp[i])
+
+		if aerr == nil && berr == nil {
+			if ai < bi {
+				return -1
+			}
+
+			return 1
+		}
+
+		if ap[i] < bp[i] {
+			return -1
+		}
+
+		return 1
+	}
+
+	switch {
+	case len(ap) < len(bp):
+		return -1
+	case len(ap) > len(bp):
+		return 1
+	}
+
+	return 0
+}
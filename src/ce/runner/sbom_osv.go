@@ -0,0 +1,214 @@
+package runner
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+)
+
+// osvAdvisory is a subset of the OSV schema.
+// See https://ossf.github.io/osv-schema/ for more information.
+type osvAdvisory struct {
+	ID               string          `json:"id"`
+	Summary          string          `json:"summary"`
+	Details          string          `json:"details"`
+	Withdrawn        string          `json:"withdrawn"`
+	Affected         []osvAffected   `json:"affected"`
+	DatabaseSpecific osvSpecificData `json:"database_specific"`
+}
+
+type osvSpecificData struct {
+	Severity string `json:"severity"`
+}
+
+type osvAffected struct {
+	Package struct {
+		Ecosystem string `json:"ecosystem"`
+		Name      string `json:"name"`
+	} `json:"package"`
+	Ranges           []osvRange      `json:"ranges"`
+	Versions         []string        `json:"versions"`
+	DatabaseSpecific osvSpecificData `json:"database_specific"`
+}
+
+type osvRange struct {
+	Type   string `json:"type"`
+	Events []struct {
+		Introduced   string `json:"introduced"`
+		Fixed        string `json:"fixed"`
+		LastAffected string `json:"last_affected"`
+	} `json:"events"`
+}
+
+// loadOSVDatabase reads the advisories from the given path. The path can
+// point to a json file that contains one advisory or a list of advisories,
+// or to a folder of such files (for instance an extracted OSV export).
+func loadOSVDatabase(path string) ([]osvAdvisory, error) {
+	stat, err := os.Stat(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{path}
+
+	if stat.IsDir() {
+		files = []string{}
+
+		err := filepath.WalkDir(path, func(p string, d os.DirEntry, err error) error {
+			if err == nil && !d.IsDir() && strings.HasSuffix(p, ".json") {
+				files = append(files, p)
+			}
+
+			return err
+		})
+
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	advisories := []osvAdvisory{}
+
+	for _, file := range files {
+		data, err := os.ReadFile(file)
+
+		if err != nil {
+			return nil, err
+		}
+
+		data = []byte(strings.TrimSpace(string(data)))
+
+		if len(data) > 0 && data[0] == '[' {
+			list := []osvAdvisory{}
+
+			if err := json.Unmarshal(data, &list); err != nil {
+				return nil, err
+			}
+
+			advisories = append(advisories, list...)
+			continue
+		}
+
+		advisory := osvAdvisory{}
+
+		if err := json.Unmarshal(data, &advisory); err != nil {
+			return nil, err
+		}
+
+		advisories = append(advisories, advisory)
+	}
+
+	return advisories, nil
+}
+
+// matchVulnerabilities returns the vulnerabilities that affect the given components.
+func matchVulnerabilities(components []deploy.SBOMComponent, advisories []osvAdvisory) []deploy.SBOMVulnerability {
+	byName := map[string][]deploy.SBOMComponent{}
+
+	for _, c := range components {
+		byName[c.Name] = append(byName[c.Name], c)
+	}
+
+	vulnerabilities := []deploy.SBOMVulnerability{}
+
+	for _, advisory := range advisories {
+		if advisory.Withdrawn != "" {
+			continue
+		}
+
+		for _, affected := range advisory.Affected {
+			if !strings.EqualFold(affected.Package.Ecosystem, "npm") {
+				continue
+			}
+
+			for _, c := range byName[affected.Package.Name] {
+				fixedIn, ok := affected.affects(c.Version)
+
+				if !ok {
+					continue
+				}
+
+				summary := advisory.Summary
+
+				if summary == "" {
+					summary, _, _ = strings.Cut(advisory.Details, "\n")
+				}
+
+				vulnerabilities = append(vulnerabilities, deploy.SBOMVulnerability{
+					ID:       advisory.ID,
+					Package:  c.Name,
+					Version:  c.Version,
+					Severity: severity(affected.DatabaseSpecific.Severity, advisory.DatabaseSpecific.Severity),
+					Summary:  summary,
+					FixedIn:  fixedIn,
+				})
+			}
+		}
+	}
+
+	return vulnerabilities
+}
+
+// affects returns whether the given version is affected and,
+// when known, the version that fixes the vulnerability.
+func (a osvAffected) affects(version string) (string, bool) {
+	for _, v := range a.Versions {
+		if v == version {
+			return "", true
+		}
+	}
+
+	for _, r := range a.Ranges {
+		if r.Type != "SEMVER" && r.Type != "ECOSYSTEM" {
+			continue
+		}
+
+		affected := false
+		fixedIn := ""
+
+		for _, event := range r.Events {
+			switch {
+			case event.Introduced != "":
+				if event.Introduced == "0" || compareVersions(version, event.Introduced) >= 0 {
+					affected = true
+				}
+			case event.Fixed != "":
+				if compareVersions(version, event.Fixed) >= 0 {
+					affected = false
+				} else if affected && fixedIn == "" {
+					fixedIn = event.Fixed
+				}
+			case event.LastAffected != "":
+				if compareVersions(version, event.LastAffected) > 0 {
+					affected = false
+				}
+			}
+		}
+
+		if affected {
+			return fixedIn, true
+		}
+	}
+
+	return "", false
+}
+
+func severity(values ...string) string {
+	for _, value := range values {
+		if deploy.SeverityLevel(value) > 0 {
+			value = strings.ToLower(value)
+
+			if value == "medium" {
+				return deploy.SeverityModerate
+			}
+
+			return value
+		}
+	}
+
+	return deploy.SeverityUnknown
+}
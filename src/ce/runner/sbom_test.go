@@ -0,0 +1,214 @@
+package runner_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/runner"
+	"github.com/stretchr/testify/suite"
+)
+
+type SBOMSuite struct {
+	suite.Suite
+
+	config runner.RunnerOpts
+}
+
+const osvDatabase = `[
+	{
+		"id": "GHSA-35jh-r3h4-6jhm",
+		"summary": "Command Injection in lodash",
+		"affected": [{
+			"package": { "ecosystem": "npm", "name": "lodash" },
+			"ranges": [{ "type": "SEMVER", "events": [{ "introduced": "0" }, { "fixed": "4.17.21" }] }]
+		}],
+		"database_specific": { "severity": "HIGH" }
+	},
+	{
+		"id": "GHSA-p6mc-m468-83gw",
+		"summary": "Prototype Pollution in lodash",
+		"affected": [{
+			"package": { "ecosystem": "npm", "name": "lodash" },
+			"ranges": [{ "type": "SEMVER", "events": [{ "introduced": "4.17.0" }, { "last_affected": "4.17.15" }] }]
+		}],
+		"database_specific": { "severity": "MODERATE" }
+	},
+	{
+		"id": "GHSA-xxxx-yyyy-zzzz",
+		"summary": "Not affected",
+		"affected": [{
+			"package": { "ecosystem": "PyPI", "name": "lodash" },
+			"versions": ["4.17.20"]
+		}]
+	}
+]`
+
+func (s *SBOMSuite) BeforeTest(_, _ string) {
+	tmpDir, err := os.MkdirTemp("", "tmp-test-sbom-")
+	s.NoError(err)
+
+	s.config = runner.RunnerOpts{
+		Reporter: runner.NewReporter(""),
+		RootDir:  tmpDir,
+		WorkDir:  path.Join(tmpDir, "repo"),
+		Repo: runner.RepoOpts{
+			Dir: path.Join(tmpDir, "repo"),
+		},
+		Build: runner.BuildOpts{
+			EnvVars: map[string]string{},
+		},
+	}
+
+	s.NoError(s.config.MkdirAll())
+}
+
+func (s *SBOMSuite) AfterTest(_, _ string) {
+	s.config.RemoveAll()
+}
+
+func (s *SBOMSuite) writeFile(name, content string) {
+	s.NoError(os.MkdirAll(path.Dir(path.Join(s.config.WorkDir, name)), 0776))
+	s.NoError(os.WriteFile(path.Join(s.config.WorkDir, name), []byte(content), 0664))
+}
+
+func (s *SBOMSuite) Test_PackageLock() {
+	s.writeFile("package-lock.json", `{
+		"lockfileVersion": 3,
+		"packages": {
+			"": { "name": "my-app", "version": "1.0.0" },
+			"node_modules/lodash": { "version": "4.17.20", "integrity": "sha512-PlhdFcillOINfeV7Ni6oF1TAEayyZBoZ8bcshTHqOYJYlrqzRK5hagpagky5o4HfCzzd1TRkXPMFq6cKk9rGmA==" },
+			"node_modules/@babel/core": { "version": "7.23.0", "dev": true },
+			"node_modules/foo/node_modules/lodash": { "version": "3.10.1" },
+			"packages/ui": { "version": "0.0.1" },
+			"node_modules/ui": { "resolved": "packages/ui", "link": true }
+		}
+	}`)
+
+	sbom, err := runner.NewSBOM(s.config).Generate()
+	s.NoError(err)
+	s.Equal("package-lock.json", sbom.Source)
+	s.Equal([]deploy.SBOMComponent{
+		{Name: "@babel/core", Version: "7.23.0", Dev: true},
+		{Name: "lodash", Version: "3.10.1"},
+		{Name: "lodash", Version: "4.17.20", Integrity: "sha512-PlhdFcillOINfeV7Ni6oF1TAEayyZBoZ8bcshTHqOYJYlrqzRK5hagpagky5o4HfCzzd1TRkXPMFq6cKk9rGmA=="},
+	}, sbom.Components)
+}
+
+func (s *SBOMSuite) Test_YarnLock() {
+	s.writeFile("yarn.lock", `# THIS IS AN AUTOGENERATED FILE. DO NOT EDIT THIS FILE DIRECTLY.
+# yarn lockfile v1
+
+
+"@babel/core@^7.0.0", "@babel/core@^7.1.0":
+  version "7.23.0"
+  resolved "https://registry.yarnpkg.com/@babel/core/-/core-7.23.0.tgz"
+  integrity sha512-abc=
+  dependencies:
+    lodash "^4.17.0"
+
+lodash@^4.17.0:
+  version "4.17.20"
+`)
+
+	sbom, err := runner.NewSBOM(s.config).Generate()
+	s.NoError(err)
+	s.Equal("yarn.lock", sbom.Source)
+	s.Equal([]deploy.SBOMComponent{
+		{Name: "@babel/core", Version: "7.23.0", Integrity: "sha512-abc="},
+		{Name: "lodash", Version: "4.17.20"},
+	}, sbom.Components)
+}
+
+func (s *SBOMSuite) Test_PnpmLock() {
+	s.writeFile("pnpm-lock.yaml", `lockfileVersion: '6.0'
+
+packages:
+  /lodash@4.17.20:
+    resolution: {integrity: sha512-abc=}
+    dev: false
+
+  /@babel/core@7.23.0:
+    resolution: {integrity: sha512-def=}
+    dev: true
+
+  /react-dom@18.2.0(react@18.2.0):
+    resolution: {integrity: sha512-ghi=}
+`)
+
+	sbom, err := runner.NewSBOM(s.config).Generate()
+	s.NoError(err)
+	s.Equal("pnpm-lock.yaml", sbom.Source)
+	s.Equal([]deploy.SBOMComponent{
+		{Name: "@babel/core", Version: "7.23.0", Integrity: "sha512-def=", Dev: true},
+		{Name: "lodash", Version: "4.17.20", Integrity: "sha512-abc="},
+		{Name: "react-dom", Version: "18.2.0", Integrity: "sha512-ghi="},
+	}, sbom.Components)
+}
+
+func (s *SBOMSuite) Test_NodeModules() {
+	s.writeFile("node_modules/express/package.json", `{ "name": "express", "version": "4.18.2", "dependencies": { "debug": "2.6.9" } }`)
+	s.writeFile("node_modules/debug/package.json", `{ "name": "debug", "version": "2.6.9" }`)
+
+	s.config.Repo.PackageJson = &runner.PackageJson{
+		Dependencies: map[string]string{"express": "^4.18.0"},
+	}
+
+	sbom, err := runner.NewSBOM(s.config).Generate()
+	s.NoError(err)
+	s.Equal("node_modules", sbom.Source)
+	s.Equal([]deploy.SBOMComponent{
+		{Name: "debug", Version: "2.6.9"},
+		{Name: "express", Version: "4.18.2"},
+	}, sbom.Components)
+}
+
+func (s *SBOMSuite) Test_Disabled() {
+	s.writeFile("yarn.lock", "lodash@^4.17.0:\n  version \"4.17.20\"\n")
+	s.config.Build.EnvVars["SK_SBOM"] = "off"
+
+	sbom, err := runner.NewSBOM(s.config).Generate()
+	s.NoError(err)
+	s.Nil(sbom)
+}
+
+func (s *SBOMSuite) Test_CheckVulnerabilities() {
+	s.writeFile("osv/npm.json", osvDatabase)
+	s.config.Build.EnvVars["SK_OSV_DATABASE"] = "osv"
+
+	sbom := &deploy.SBOM{
+		Components: []deploy.SBOMComponent{
+			{Name: "lodash", Version: "4.17.20"},
+			{Name: "lodash", Version: "4.17.21"},
+		},
+	}
+
+	s.NoError(runner.NewSBOM(s.config).CheckVulnerabilities(sbom))
+	s.Equal([]deploy.SBOMVulnerability{
+		{ID: "GHSA-35jh-r3h4-6jhm", Package: "lodash", Version: "4.17.20", Severity: "high", Summary: "Command Injection in lodash", FixedIn: "4.17.21"},
+	}, sbom.Vulnerabilities)
+
+	s.Contains(s.config.Reporter.Logs(), "[warning] GHSA-35jh-r3h4-6jhm lodash@4.17.20 (high): Command Injection in lodash. Fixed in 4.17.21")
+}
+
+func (s *SBOMSuite) Test_CheckVulnerabilities_FailLevel() {
+	s.writeFile("osv.json", osvDatabase)
+	s.config.Build.EnvVars["SK_OSV_DATABASE"] = "osv.json"
+	s.config.Build.EnvVars["SK_VULNERABILITY_FAIL_LEVEL"] = "moderate"
+
+	sbom := &deploy.SBOM{
+		Components: []deploy.SBOMComponent{{Name: "lodash", Version: "4.17.15"}},
+	}
+
+	err := runner.NewSBOM(s.config).CheckVulnerabilities(sbom)
+	s.EqualError(err, "found 2 vulnerabilities with moderate severity or above")
+	s.Len(sbom.Vulnerabilities, 2)
+
+	s.config.Build.EnvVars["SK_VULNERABILITY_FAIL_LEVEL"] = "critical"
+	s.NoError(runner.NewSBOM(s.config).CheckVulnerabilities(sbom))
+}
+
+func TestSBOMSuite(t *testing.T) {
+	suite.Run(t, &SBOMSuite{})
+}
@@ -0,0 +1,139 @@
+package runner
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/xml"
+	"strings"
+)
+
+// maxExcerptLength is the maximum number of characters kept
+// from a failure message.
+const maxExcerptLength = 1000
+
+// StatusCheckReport is the summary of a JUnit XML or TAP report.
+type StatusCheckReport struct {
+	Tests    int
+	Failures int
+	Excerpt  string
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Content string `xml:",chardata"`
+}
+
+type junitTestCase struct {
+	Name    string        `xml:"name,attr"`
+	Failure *junitFailure `xml:"failure"`
+	Error   *junitFailure `xml:"error"`
+}
+
+type junitTestSuite struct {
+	TestCases  []junitTestCase  `xml:"testcase"`
+	TestSuites []junitTestSuite `xml:"testsuite"`
+}
+
+// ParseStatusCheckReport parses the given report. JUnit XML reports are
+// detected by their content, anything else is treated as TAP output.
+func ParseStatusCheckReport(data []byte) (*StatusCheckReport, error) {
+	if bytes.HasPrefix(bytes.TrimSpace(data), []byte("<")) {
+		return parseJUnitReport(data)
+	}
+
+	return parseTAPReport(data), nil
+}
+
+func parseJUnitReport(data []byte) (*StatusCheckReport, error) {
+	// The root element is either <testsuites> or <testsuite>, both can be
+	// unmarshaled into the same struct since we only care about test cases.
+	root := junitTestSuite{}
+
+	if err := xml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	report := &StatusCheckReport{}
+	collectJUnitTestCases(root, report)
+	return report, nil
+}
+
+func collectJUnitTestCases(suite junitTestSuite, report *StatusCheckReport) {
+	for _, tc := range suite.TestCases {
+		report.Tests++
+
+		failure := tc.Failure
+
+		if failure == nil {
+			failure = tc.Error
+		}
+
+		if failure == nil {
+			continue
+		}
+
+		report.Failures++
+
+		if report.Excerpt == "" {
+			message := strings.TrimSpace(failure.Message)
+
+			if content := strings.TrimSpace(failure.Content); content != "" {
+				message = strings.TrimSpace(message + "\n" + content)
+			}
+
+			report.Excerpt = truncateExcerpt(tc.Name + ": " + message)
+		}
+	}
+
+	for _, child := range suite.TestSuites {
+		collectJUnitTestCases(child, report)
+	}
+}
+
+func parseTAPReport(data []byte) *StatusCheckReport {
+	report := &StatusCheckReport{}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		failed := strings.HasPrefix(line, "not ok")
+
+		if !failed && !strings.HasPrefix(line, "ok") {
+			continue
+		}
+
+		report.Tests++
+
+		// Skipped and todo tests do not count as failures
+		if !failed || isTAPDirective(line) {
+			continue
+		}
+
+		report.Failures++
+
+		if report.Excerpt == "" {
+			report.Excerpt = truncateExcerpt(line)
+		}
+	}
+
+	return report
+}
+
+func isTAPDirective(line string) bool {
+	pieces := strings.SplitN(line, "#", 2)
+
+	if len(pieces) < 2 {
+		return false
+	}
+
+	directive := strings.ToUpper(strings.TrimSpace(pieces[1]))
+	return strings.HasPrefix(directive, "SKIP") || strings.HasPrefix(directive, "TODO")
+}
+
+func truncateExcerpt(excerpt string) string {
+	if len(excerpt) > maxExcerptLength {
+		return excerpt[:maxExcerptLength] + "..."
+	}
+
+	return excerpt
+}
@@ -0,0 +1,49 @@
+package runner_test
+
+import (
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/runner"
+	"github.com/stretchr/testify/suite"
+)
+
+type StatusCheckReportSuite struct {
+	suite.Suite
+}
+
+func (s *StatusCheckReportSuite) Test_JUnit() {
+	report, err := runner.ParseStatusCheckReport([]byte(`<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="unit">
+    <testcase name="renders" />
+    <testcase name="submits form">
+      <failure message="expected 200">received 500</failure>
+    </testcase>
+  </testsuite>
+  <testsuite name="e2e">
+    <testcase name="login"><error message="timeout" /></testcase>
+  </testsuite>
+</testsuites>`))
+
+	s.NoError(err)
+	s.Equal(&runner.StatusCheckReport{
+		Tests:    3,
+		Failures: 2,
+		Excerpt:  "submits form: expected 200\nreceived 500",
+	}, report)
+}
+
+func (s *StatusCheckReportSuite) Test_TAP() {
+	report, err := runner.ParseStatusCheckReport([]byte("TAP version 13\n1..4\nok 1 - renders\nnot ok 2 - submits form\nnot ok 3 - flaky # SKIP\nok 4 - logs in\n"))
+
+	s.NoError(err)
+	s.Equal(&runner.StatusCheckReport{
+		Tests:    4,
+		Failures: 1,
+		Excerpt:  "not ok 2 - submits form",
+	}, report)
+}
+
+func TestStatusCheckReportSuite(t *testing.T) {
+	suite.Run(t, &StatusCheckReportSuite{})
+}
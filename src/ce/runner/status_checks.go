@@ -1,11 +1,30 @@
 package runner
 
 import (
+	"bytes"
 	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils/sys"
 )
 
+// excerptLines is the number of output lines kept as a failure
+// excerpt when a check has no report.
+const excerptLines = 20
+
+// statusCheckWaitDelay is the time to wait for the output of child processes
+// after a check times out. Processes such as dev servers that are spawned by
+// the check would otherwise keep the check running.
+const statusCheckWaitDelay = 5 * time.Second
+
 type StatusChecks struct {
 	workDir  string
 	envVars  []string
@@ -20,22 +39,224 @@ func NewStatusChecks(opts RunnerOpts) *StatusChecks {
 	}
 }
 
-func (s *StatusChecks) Run(ctx context.Context, command string) error {
-	rep := s.reporter
-	rep.AddStep(command)
+// RunAll runs the given checks and returns their results. Checks that share
+// the same group run in parallel, and groups run in the order they first
+// appear. Checks without a group run on their own. Once a required check fails,
+// the remaining groups are skipped.
+func (s *StatusChecks) RunAll(ctx context.Context, checks []buildconf.StatusCheck) deploy.StatusCheckResults {
+	results := deploy.StatusCheckResults{}
+	failed := false
+
+	for _, group := range groupStatusChecks(checks) {
+		if failed {
+			for _, check := range group {
+				results = append(results, deploy.StatusCheckResult{
+					Name:     statusCheckName(check),
+					Cmd:      check.Cmd,
+					Status:   deploy.StatusCheckSkipped,
+					Required: !check.Optional,
+				})
+			}
+
+			continue
+		}
+
+		groupResults := make(deploy.StatusCheckResults, len(group))
+		outputs := make([]*bytes.Buffer, len(group))
+		wg := sync.WaitGroup{}
+
+		for i, check := range group {
+			outputs[i] = &bytes.Buffer{}
+			wg.Add(1)
+
+			go func(i int, check buildconf.StatusCheck) {
+				defer wg.Done()
+				groupResults[i] = s.run(ctx, check, outputs[i])
+			}(i, check)
+		}
+
+		wg.Wait()
+
+		// Logs are written once the group completes so that
+		// the output of parallel checks is not interleaved.
+		for i, result := range groupResults {
+			s.log(result, outputs[i])
+			results = append(results, result)
+
+			if result.Required && !result.IsSuccess() {
+				failed = true
+			}
+		}
+	}
+
+	return results
+}
+
+// Run runs a single check and writes its output to the reporter.
+func (s *StatusChecks) Run(ctx context.Context, check buildconf.StatusCheck) deploy.StatusCheckResult {
+	output := &bytes.Buffer{}
+	result := s.run(ctx, check, output)
+	s.log(result, output)
+	return result
+}
+
+func (s *StatusChecks) run(ctx context.Context, check buildconf.StatusCheck, output *bytes.Buffer) deploy.StatusCheckResult {
+	result := deploy.StatusCheckResult{
+		Name:     statusCheckName(check),
+		Cmd:      check.Cmd,
+		Required: !check.Optional,
+	}
+
+	if check.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(check.Timeout)*time.Second)
+		defer cancel()
+	}
+
+	writer := &lockedWriter{w: output}
+	start := time.Now()
 
 	cmd := sys.Command(ctx, sys.CommandOpts{
-		Name:   "sh",
-		Args:   []string{"-c", command},
-		Env:    s.envVars,
-		Dir:    s.workDir,
-		Stdout: rep.File(),
-		Stderr: rep.File(),
+		Name:      "sh",
+		Args:      []string{"-c", check.Cmd},
+		Env:       s.envVars,
+		Dir:       s.workDir,
+		Stdout:    writer,
+		Stderr:    writer,
+		WaitDelay: statusCheckWaitDelay,
 	})
 
 	err := cmd.Run()
 
-	rep.AddStep("[system] status check passed")
+	result.Duration = time.Since(start).Milliseconds()
+
+	switch {
+	case errors.Is(ctx.Err(), context.DeadlineExceeded):
+		result.Status = deploy.StatusCheckTimeout
+		result.Excerpt = fmt.Sprintf("status check timed out after %ds", check.Timeout)
+	case err != nil:
+		result.Status = deploy.StatusCheckFailed
+	default:
+		result.Status = deploy.StatusCheckPassed
+	}
+
+	if check.Report != "" {
+		s.parseReport(check, &result, writer)
+	}
+
+	if result.Status == deploy.StatusCheckFailed && result.Excerpt == "" {
+		result.Excerpt = outputExcerpt(output.String())
+	}
+
+	// The excerpt is stored with the deployment, unlike the logs it is not masked on write.
+	if result.Excerpt != "" && s.reporter != nil {
+		result.Excerpt = s.reporter.Mask(result.Excerpt)
+	}
+
+	return result
+}
+
+// parseReport reads the report file of the check and updates the result
+// with the number of tests, failures and the first failure message.
+func (s *StatusChecks) parseReport(check buildconf.StatusCheck, result *deploy.StatusCheckResult, output *lockedWriter) {
+	file := check.Report
+
+	if !filepath.IsAbs(file) {
+		file = filepath.Join(s.workDir, file)
+	}
+
+	data, err := os.ReadFile(file)
+
+	if err != nil {
+		fmt.Fprintf(output, "cannot read status check report %s: %s\n", check.Report, err.Error())
+		return
+	}
+
+	report, err := ParseStatusCheckReport(data)
+
+	if err != nil {
+		fmt.Fprintf(output, "cannot parse status check report %s: %s\n", check.Report, err.Error())
+		return
+	}
+
+	result.Tests = report.Tests
+	result.Failures = report.Failures
+
+	if report.Failures > 0 {
+		result.Excerpt = report.Excerpt
+
+		if result.Status == deploy.StatusCheckPassed {
+			result.Status = deploy.StatusCheckFailed
+		}
+	}
+}
+
+func (s *StatusChecks) log(result deploy.StatusCheckResult, output *bytes.Buffer) {
+	rep := s.reporter
+	rep.AddStep(result.Cmd)
+
+	if file := rep.File(); file != nil && output.Len() > 0 {
+		file.Write(output.Bytes())
+	}
+
+	if !result.Required && !result.IsSuccess() {
+		rep.AddStep(fmt.Sprintf("[system] optional status check %s", result.Status))
+	} else {
+		rep.AddStep(fmt.Sprintf("[system] status check %s", result.Status))
+	}
+}
+
+// groupStatusChecks splits the checks into groups that run in parallel.
+func groupStatusChecks(checks []buildconf.StatusCheck) [][]buildconf.StatusCheck {
+	groups := [][]buildconf.StatusCheck{}
+	indexes := map[string]int{}
+
+	for _, check := range checks {
+		if check.Group == "" {
+			groups = append(groups, []buildconf.StatusCheck{check})
+			continue
+		}
+
+		if index, ok := indexes[check.Group]; ok {
+			groups[index] = append(groups[index], check)
+			continue
+		}
+
+		indexes[check.Group] = len(groups)
+		groups = append(groups, []buildconf.StatusCheck{check})
+	}
+
+	return groups
+}
+
+func statusCheckName(check buildconf.StatusCheck) string {
+	if check.Name != "" {
+		return check.Name
+	}
+
+	return check.Cmd
+}
+
+// outputExcerpt returns the last lines of the output.
+func outputExcerpt(output string) string {
+	lines := strings.Split(strings.TrimSpace(output), "\n")
+
+	if len(lines) > excerptLines {
+		lines = lines[len(lines)-excerptLines:]
+	}
+
+	return truncateExcerpt(strings.Join(lines, "\n"))
+}
+
+// lockedWriter makes sure that stdout and stderr can
+// write concurrently into the same buffer.
+type lockedWriter struct {
+	w  *bytes.Buffer
+	mu sync.Mutex
+}
 
-	return err
+func (l *lockedWriter) Write(p []byte) (int, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.w.Write(p)
 }
@@ -2,14 +2,20 @@ package runner_test
 
 import (
 	"context"
+	"errors"
 	"os"
 	"path"
+	"reflect"
 	"strings"
 	"testing"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/runner"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils/sys"
 	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
 )
 
@@ -50,22 +56,87 @@ func (s *StatusChecksSuite) AfterTest(_, _ string) {
 	sys.DefaultCommand = nil
 }
 
+// mockCommand returns a separate command mock for each command, so that checks
+// that run in parallel receive the result of their own command.
+func (s *StatusChecksSuite) mockCommand(command string, err error) {
+	cmd := &mocks.CommandInterface{}
+	cmd.On("Run").Return(err).Once()
+
+	s.mockCmd.On("SetOpts", mock.MatchedBy(func(opts sys.CommandOpts) bool {
+		return opts.Name == "sh" &&
+			opts.Args[1] == command &&
+			opts.Dir == s.config.WorkDir &&
+			reflect.DeepEqual(opts.Env, s.config.Build.EnvVarsRaw)
+	})).Return(cmd).Once()
+}
+
 func (s *StatusChecksSuite) Test_Run() {
 	sc := runner.NewStatusChecks(s.config)
 	ctx := context.Background()
 
-	s.mockCmd.On("SetOpts", sys.CommandOpts{
-		Name:   "sh",
-		Args:   []string{"-c", "printenv"},
-		Env:    s.config.Build.EnvVarsRaw,
-		Dir:    s.config.WorkDir,
-		Stdout: s.config.Reporter.File(),
-		Stderr: s.config.Reporter.File(),
-	}).Return(s.mockCmd)
+	s.mockCommand("printenv", nil)
+
+	result := sc.Run(ctx, buildconf.StatusCheck{Name: "Print env", Cmd: "printenv"})
+
+	s.Equal("Print env", result.Name)
+	s.Equal(deploy.StatusCheckPassed, result.Status)
+	s.True(result.Required)
+	s.Contains(s.config.Reporter.Logs(), "[system] status check passed")
+}
+
+func (s *StatusChecksSuite) Test_RunAll_SkipsAfterRequiredFailure() {
+	sc := runner.NewStatusChecks(s.config)
+	ctx := context.Background()
+
+	s.mockCommand("npm run lint", errors.New("exit status 1"))
+	s.mockCommand("npm run e2e", nil)
+
+	results := sc.RunAll(ctx, []buildconf.StatusCheck{
+		{Name: "Lint", Cmd: "npm run lint", Optional: true, Group: "static"},
+		{Name: "E2E", Cmd: "npm run e2e", Group: "static"},
+		{Name: "Lighthouse", Cmd: "npm run lighthouse"},
+	})
+
+	s.Len(results, 3)
+	s.Equal(deploy.StatusCheckFailed, results[0].Status)
+	s.Equal(deploy.StatusCheckPassed, results[1].Status)
+	s.Equal(deploy.StatusCheckPassed, results[2].Status)
+	s.True(results.Passed())
+}
+
+func (s *StatusChecksSuite) Test_RunAll_Report() {
+	sc := runner.NewStatusChecks(s.config)
+	ctx := context.Background()
+
+	s.NoError(os.WriteFile(path.Join(s.config.WorkDir, "report.tap"), []byte("ok 1 - renders\nnot ok 2 - submits form\n"), 0664))
+	s.mockCommand("npm test", nil)
+	s.mockCommand("npm run lighthouse", nil)
+
+	results := sc.RunAll(ctx, []buildconf.StatusCheck{
+		{Name: "Tests", Cmd: "npm test", Report: "report.tap"},
+		{Name: "Lighthouse", Cmd: "npm run lighthouse"},
+	})
+
+	s.Equal(deploy.StatusCheckResults{
+		{Name: "Tests", Cmd: "npm test", Status: deploy.StatusCheckFailed, Required: true, Duration: results[0].Duration, Tests: 2, Failures: 1, Excerpt: "not ok 2 - submits form"},
+		{Name: "Lighthouse", Cmd: "npm run lighthouse", Status: deploy.StatusCheckSkipped, Required: true},
+	}, results)
+
+	s.False(results.Passed())
+}
+
+func (s *StatusChecksSuite) Test_Run_MasksExcerpt() {
+	sc := runner.NewStatusChecks(s.config)
+	ctx := context.Background()
+
+	s.config.Reporter.MaskSecrets(map[string]string{"API_TOKEN": "s3cr3t-token"})
+	s.NoError(os.WriteFile(path.Join(s.config.WorkDir, "report.tap"), []byte("not ok 1 - rejects s3cr3t-token\n"), 0664))
+	s.mockCommand("npm test", nil)
 
-	s.mockCmd.On("Run").Return(nil)
+	result := sc.Run(ctx, buildconf.StatusCheck{Name: "Tests", Cmd: "npm test", Report: "report.tap"})
 
-	s.NoError(sc.Run(ctx, "printenv"))
+	s.Equal(deploy.StatusCheckFailed, result.Status)
+	s.Equal("not ok 1 - rejects "+utils.SecretMask, result.Excerpt)
 }
 
 func TestStatusChecksSuite(t *testing.T) {
@@ -0,0 +1,92 @@
+package runner
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+)
+
+// LoadStormkitFile looks for a stormkit.config.yml (or .yaml, .json, .toml) file
+// in the working directory and the repository root, and applies it to the build
+// options. The working directory takes precedence. When no file is found,
+// it returns nil.
+func LoadStormkitFile(opts *RunnerOpts) (*buildconf.StormkitFile, error) {
+	path := buildconf.FindStormkitFile(opts.WorkDir, opts.Repo.Dir)
+
+	if path == "" {
+		return nil, nil
+	}
+
+	rel, err := filepath.Rel(opts.Repo.Dir, path)
+
+	if err != nil {
+		rel = filepath.Base(path)
+	}
+
+	opts.Reporter.AddStep(fmt.Sprintf("[system] reading %s", rel))
+
+	data, err := os.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := buildconf.ParseStormkitFile(rel, data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	applyStormkitFile(opts, file)
+
+	return file, nil
+}
+
+func applyStormkitFile(opts *RunnerOpts, file *buildconf.StormkitFile) {
+	build := &opts.Build
+
+	merged := file.Apply(&buildconf.BuildConf{
+		InstallCmd:    build.InstallCmd,
+		BuildCmd:      build.BuildCmd,
+		ServerCmd:     build.ServerCmd,
+		DistFolder:    build.DistFolder,
+		ServerFolder:  build.ServerFolder,
+		APIFolder:     build.APIFolder,
+		HeadersFile:   build.HeadersFile,
+		RedirectsFile: build.RedirectsFile,
+		StatusChecks:  build.StatusChecks,
+		Vars:          build.EnvVars,
+	}, build.Env)
+
+	build.InstallCmd = merged.InstallCmd
+	build.BuildCmd = merged.BuildCmd
+	build.ServerCmd = merged.ServerCmd
+	build.DistFolder = trim(merged.DistFolder)
+	build.ServerFolder = trim(merged.ServerFolder)
+	build.APIFolder = trim(merged.APIFolder)
+	build.HeadersFile = trim(merged.HeadersFile)
+	build.RedirectsFile = trim(merged.RedirectsFile)
+	build.StatusChecks = merged.StatusChecks
+	build.Headers = merged.Headers
+	build.Redirects = merged.Redirects
+
+	// Variables that are configured from the UI take precedence,
+	// so only the missing ones are added.
+	keys := []string{}
+
+	for key := range merged.Vars {
+		if _, ok := build.EnvVars[key]; !ok {
+			keys = append(keys, key)
+		}
+	}
+
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		build.EnvVars[key] = merged.Vars[key]
+		build.EnvVarsRaw = append(build.EnvVarsRaw, fmt.Sprintf("%s=%s", key, merged.Vars[key]))
+	}
+}
@@ -0,0 +1,93 @@
+package runner_test
+
+import (
+	"os"
+	"path"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/runner"
+	"github.com/stretchr/testify/suite"
+)
+
+type StormkitFileSuite struct {
+	suite.Suite
+
+	config runner.RunnerOpts
+}
+
+func (s *StormkitFileSuite) BeforeTest(_, _ string) {
+	tmpDir, err := os.MkdirTemp("", "tmp-test-stormkit-file-")
+	s.NoError(err)
+
+	s.config = runner.RunnerOpts{
+		Reporter: runner.NewReporter(""),
+		RootDir:  tmpDir,
+		WorkDir:  path.Join(tmpDir, "repo", "web"),
+		Repo: runner.RepoOpts{
+			Dir: path.Join(tmpDir, "repo"),
+		},
+		Build: runner.BuildOpts{
+			Env:        "production",
+			BuildCmd:   "npm run build",
+			DistFolder: "build",
+			EnvVars:    map[string]string{"API_URL": "https://api.example.org"},
+			EnvVarsRaw: []string{"API_URL=https://api.example.org"},
+		},
+	}
+
+	s.NoError(s.config.MkdirAll())
+	s.NoError(os.MkdirAll(s.config.WorkDir, 0776))
+}
+
+func (s *StormkitFileSuite) AfterTest(_, _ string) {
+	s.config.RemoveAll()
+}
+
+func (s *StormkitFileSuite) Test_NoFile() {
+	file, err := runner.LoadStormkitFile(&s.config)
+	s.NoError(err)
+	s.Nil(file)
+	s.Equal("npm run build", s.config.Build.BuildCmd)
+}
+
+func (s *StormkitFileSuite) Test_WorkDirTakesPrecedence() {
+	s.NoError(os.WriteFile(path.Join(s.config.Repo.Dir, "stormkit.config.yml"), []byte("buildCmd: npm run build:root\n"), 0664))
+	s.NoError(os.WriteFile(path.Join(s.config.WorkDir, "stormkit.config.json"), []byte(`{
+		"distFolder": "./dist",
+		"headers": "/*\n  x-frame-options: DENY",
+		"redirects": [{ "from": "/old", "to": "/new" }],
+		"vars": { "API_URL": "https://file.example.org", "NODE_ENV": "production" },
+		"environments": {
+			"production": {
+				"buildCmd": "npm run build:prod",
+				"statusChecks": [{ "cmd": "npm run e2e" }]
+			}
+		}
+	}`), 0664))
+
+	file, err := runner.LoadStormkitFile(&s.config)
+	s.NoError(err)
+	s.Equal("web/stormkit.config.json", file.Name)
+
+	build := s.config.Build
+	s.Equal("npm run build:prod", build.BuildCmd)
+	s.Equal("dist", build.DistFolder)
+	s.Equal("/*\n  x-frame-options: DENY", build.Headers)
+	s.Equal([]deploy.Redirect{{From: "/old", To: "/new"}}, build.Redirects)
+	s.Equal([]buildconf.StatusCheck{{Cmd: "npm run e2e"}}, build.StatusChecks)
+	s.Equal(map[string]string{"API_URL": "https://api.example.org", "NODE_ENV": "production"}, build.EnvVars)
+	s.Equal([]string{"API_URL=https://api.example.org", "NODE_ENV=production"}, build.EnvVarsRaw)
+}
+
+func (s *StormkitFileSuite) Test_InvalidFile() {
+	s.NoError(os.WriteFile(path.Join(s.config.Repo.Dir, "stormkit.config.yml"), []byte("buildCommand: npm run build\n"), 0664))
+
+	_, err := runner.LoadStormkitFile(&s.config)
+	s.EqualError(err, `stormkit.config.yml: unknown field "buildCommand"`)
+}
+
+func TestStormkitFileSuite(t *testing.T) {
+	suite.Run(t, &StormkitFileSuite{})
+}
@@ -0,0 +1,104 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+type BackgroundJobMessage struct {
+	ID types.ID `json:"id,string"`
+}
+
+// EnqueueBackgroundJob sends the given job to the queue. The job is processed
+// once its run date is reached.
+func EnqueueBackgroundJob(ctx context.Context, job *backgroundjob.Job) error {
+	_, err := tasks.Enqueue(ctx, tasks.BackgroundJobInvoke, BackgroundJobMessage{ID: job.ID}, &tasks.EnqueueOptions{
+		MaxRetry:  job.MaxRetries,
+		TaskID:    "background-job:" + job.ID.String(),
+		ProcessAt: job.RunAt.Time,
+	})
+
+	return err
+}
+
+// HandleBackgroundJob invokes the function of a background job. Failed attempts
+// are retried by returning an error, until the retry limit of the job is reached.
+func HandleBackgroundJob(ctx context.Context, t *asynq.Task) error {
+	msg := BackgroundJobMessage{}
+
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		slog.Errorf("HandleBackgroundJob cannot unmarshal payload information: %v", err)
+		return err
+	}
+
+	store := backgroundjob.NewStore()
+	job, err := store.JobByID(ctx, msg.ID)
+
+	if err != nil {
+		slog.Errorf("cannot fetch background job: %v", err)
+		return err
+	}
+
+	// The environment has been removed in the meantime, or the job already ran.
+	if job == nil || job.IsDone() {
+		return nil
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	attempt := retried + 1
+
+	if err := store.MarkRunning(ctx, job.ID, attempt); err != nil {
+		slog.Errorf("cannot mark background job as running: %v", err)
+	}
+
+	response, reason := invokeFunction(ctx, job.EnvID, functionRequest{
+		Path:    job.Path,
+		Method:  job.Method,
+		Headers: job.Headers.Make(),
+		Payload: []byte(job.Payload),
+		Context: map[string]any{
+			"job": map[string]any{
+				"id":      job.ID.String(),
+				"attempt": attempt,
+			},
+		},
+	})
+
+	response["attempt"] = attempt
+	status := backgroundjob.StatusSucceeded
+
+	var retryErr error
+
+	if reason != "" {
+		status = backgroundjob.StatusFailed
+
+		if retried < job.MaxRetries {
+			status = backgroundjob.StatusQueued
+			retryErr = errors.New(reason)
+		}
+	}
+
+	if err := store.Complete(context.WithoutCancel(ctx), job.ID, status, response); err != nil {
+		slog.Errorf("error while storing background job result: %v", err)
+	}
+
+	return retryErr
+}
+
+// RemoveOldBackgroundJobs removes the background jobs that completed
+// more than 30 days ago.
+func RemoveOldBackgroundJobs(ctx context.Context) error {
+	if err := backgroundjob.NewStore().RemoveOldJobs(ctx); err != nil {
+		slog.Errorf("error while removing old background jobs: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,170 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+	"time"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/apikey"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/applog"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type JobBackgroundJobsSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn             databasetest.TestDB
+	mockClient       mocks.TaskClient
+	mockIntegrations *mocks.ClientInterface
+	originalClient   func() tasks.TaskClient
+}
+
+func (s *JobBackgroundJobsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+	s.mockClient = mocks.TaskClient{}
+	s.mockIntegrations = &mocks.ClientInterface{}
+	s.originalClient = tasks.Client
+	integrations.SetDefaultClient(s.mockIntegrations)
+	tasks.Client = func() tasks.TaskClient {
+		return &s.mockClient
+	}
+}
+
+func (s *JobBackgroundJobsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	tasks.Client = s.originalClient
+	integrations.SetDefaultClient(nil)
+}
+
+func (s *JobBackgroundJobsSuite) task(job *factory.MockBackgroundJob) *asynq.Task {
+	payload, err := json.Marshal(jobs.BackgroundJobMessage{ID: job.ID})
+	s.NoError(err)
+	return asynq.NewTask(tasks.BackgroundJobInvoke, payload)
+}
+
+func (s *JobBackgroundJobsSuite) publishedEnv() (*factory.MockEnv, *factory.MockDeployment) {
+	env := s.MockEnv(nil)
+	depl := s.MockDeployment(env, map[string]any{
+		"ExitCode":         null.IntFrom(0),
+		"FunctionLocation": null.StringFrom("aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1"),
+		"Published":        []deploy.PublishedInfo{{EnvID: env.ID, Percentage: 100}},
+	})
+
+	return env, depl
+}
+
+func (s *JobBackgroundJobsSuite) Test_EnqueueBackgroundJob() {
+	job := s.MockBackgroundJob(nil, map[string]any{
+		"MaxRetries": 3,
+		"RunAt":      utils.UnixFrom(time.Now().Add(time.Hour)),
+	})
+
+	s.mockClient.On("Enqueue", mock.MatchedBy(func(t *asynq.Task) bool {
+		msg := jobs.BackgroundJobMessage{}
+		s.NoError(json.Unmarshal(t.Payload(), &msg))
+		return t.Type() == tasks.BackgroundJobInvoke && msg.ID == job.ID
+	})).Return(&asynq.TaskInfo{}, nil).Once()
+
+	s.NoError(jobs.EnqueueBackgroundJob(context.Background(), job.Job))
+	s.mockClient.AssertExpectations(s.T())
+}
+
+func (s *JobBackgroundJobsSuite) Test_HandleBackgroundJob_Success() {
+	env, depl := s.publishedEnv()
+	job := s.MockBackgroundJob(env, map[string]any{
+		"Path":    "/api/emails/send?batch=1",
+		"Payload": `{"to":"joe@stormkit.io"}`,
+	})
+
+	s.mockIntegrations.On("Invoke", mock.MatchedBy(func(args integrations.InvokeArgs) bool {
+		ctx := args.Context["job"].(map[string]any)
+		helper := args.Context["jobs"].(map[string]any)
+		kv := args.Context["kv"].(map[string]any)
+
+		return args.ARN == "aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1" &&
+			args.Method == "POST" &&
+			args.URL.Path == "/api/emails/send" &&
+			args.URL.Query().Get("batch") == "1" &&
+			args.DeploymentID == depl.ID &&
+			ctx["id"] == job.ID.String() &&
+			ctx["attempt"] == 1 &&
+			apikey.EnvIDFromFunctionToken(backgroundjob.TokenService, helper["token"].(string), time.Now()) == env.ID &&
+			apikey.EnvIDFromFunctionToken(kvstore.TokenService, kv["token"].(string), time.Now()) == env.ID
+	})).Return(&integrations.InvokeResult{
+		StatusCode: http.StatusOK,
+		Body:       []byte("sent"),
+		Logs:       []integrations.Log{{Message: "email sent", Level: "info"}},
+	}, nil).Once()
+
+	s.NoError(jobs.HandleBackgroundJob(context.Background(), s.task(job)))
+	s.mockIntegrations.AssertExpectations(s.T())
+
+	stored, err := backgroundjob.NewStore().JobByID(context.Background(), job.ID)
+	s.NoError(err)
+	s.Equal(backgroundjob.StatusSucceeded, stored.Status)
+	s.Equal(1, stored.Attempts)
+	s.Equal(float64(http.StatusOK), stored.Result["code"])
+	s.Equal("sent", stored.Result["body"])
+	s.True(stored.CompletedAt.Valid)
+
+	logs, err := applog.NewStore().Logs(context.Background(), &applog.LogQuery{AppID: env.AppID, DeploymentID: depl.ID})
+	s.NoError(err)
+	s.Len(logs, 1)
+	s.Equal("email sent", logs[0].Data)
+}
+
+func (s *JobBackgroundJobsSuite) Test_HandleBackgroundJob_Retry() {
+	env, _ := s.publishedEnv()
+	job := s.MockBackgroundJob(env, map[string]any{"MaxRetries": 2})
+
+	s.mockIntegrations.On("Invoke", mock.Anything).Return(&integrations.InvokeResult{
+		StatusCode: http.StatusInternalServerError,
+		Body:       []byte("boom"),
+	}, nil).Once()
+
+	s.EqualError(jobs.HandleBackgroundJob(context.Background(), s.task(job)), "Function responded with status 500")
+
+	stored, err := backgroundjob.NewStore().JobByID(context.Background(), job.ID)
+	s.NoError(err)
+	s.Equal(backgroundjob.StatusQueued, stored.Status)
+	s.False(stored.CompletedAt.Valid)
+}
+
+func (s *JobBackgroundJobsSuite) Test_HandleBackgroundJob_Failed() {
+	job := s.MockBackgroundJob(nil)
+
+	s.NoError(jobs.HandleBackgroundJob(context.Background(), s.task(job)))
+
+	stored, err := backgroundjob.NewStore().JobByID(context.Background(), job.ID)
+	s.NoError(err)
+	s.Equal(backgroundjob.StatusFailed, stored.Status)
+	s.Equal("Environment does not have a published deployment", stored.Result["error"])
+	s.True(stored.CompletedAt.Valid)
+}
+
+func (s *JobBackgroundJobsSuite) Test_HandleBackgroundJob_AlreadyDone() {
+	job := s.MockBackgroundJob(nil, map[string]any{"Status": backgroundjob.StatusSucceeded})
+
+	s.NoError(jobs.HandleBackgroundJob(context.Background(), s.task(job)))
+	s.mockIntegrations.AssertNotCalled(s.T(), "Invoke", mock.Anything)
+}
+
+func TestJobBackgroundJobsSuite(t *testing.T) {
+	suite.Run(t, &JobBackgroundJobsSuite{})
+}
@@ -0,0 +1,44 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// RequeueLostAgentJobs puts the deployments of build agents that stopped
+// sending heartbeats back to the agent queue, so that another agent can
+// pick them up.
+func RequeueLostAgentJobs(ctx context.Context) error {
+	store := buildagent.NewStore()
+
+	if err := store.CancelStoppedJobs(ctx); err != nil {
+		slog.Errorf("error while cancelling stopped agent jobs: %v", err)
+		return err
+	}
+
+	requeued, failed, err := store.RequeueLostJobs(ctx)
+
+	if err != nil {
+		slog.Errorf("error while requeuing lost agent jobs: %v", err)
+		return err
+	}
+
+	if len(requeued) > 0 || len(failed) > 0 {
+		slog.Infof("requeued %d and failed %d deployment(s) of lost build agents", len(requeued), len(failed))
+	}
+
+	return nil
+}
+
+// RemoveOldAgentJobs removes the completed and cancelled agent jobs
+// that are older than a week.
+func RemoveOldAgentJobs(ctx context.Context) error {
+	if err := buildagent.NewStore().RemoveOldJobs(ctx); err != nil {
+		slog.Errorf("error while removing old agent jobs: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,76 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/buildagent"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobBuildAgentsSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobBuildAgentsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *JobBuildAgentsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *JobBuildAgentsSuite) Test_RequeueLostAgentJobs() {
+	ctx := context.Background()
+	store := buildagent.NewStore()
+	env := s.MockEnv(nil)
+	lost := s.MockDeployment(env).Deployment
+	abandoned := s.MockDeployment(env).Deployment
+	alive := s.MockDeployment(env).Deployment
+
+	agent := &buildagent.Agent{Name: "builder", Token: buildagent.GenerateToken()}
+	s.NoError(store.InsertAgent(ctx, agent))
+
+	for _, d := range []*deploy.Deployment{lost, abandoned, alive} {
+		s.NoError(store.InsertJob(ctx, &buildagent.Job{DeploymentID: d.ID, Payload: "encrypted"}))
+
+		job, err := store.ClaimJob(ctx, agent)
+		s.NoError(err)
+		s.Equal(d.ID, job.DeploymentID)
+	}
+
+	_, err := s.conn.Exec(`UPDATE build_agent_jobs SET heartbeat_at = NOW() AT TIME ZONE 'UTC' - INTERVAL '10 minutes' WHERE deployment_id = ANY(ARRAY[$1, $2]::bigint[])`, lost.ID, abandoned.ID)
+	s.NoError(err)
+
+	_, err = s.conn.Exec(`UPDATE build_agent_jobs SET attempts = $1 WHERE deployment_id = $2`, buildagent.MaxJobAttempts, abandoned.ID)
+	s.NoError(err)
+
+	s.NoError(jobs.RequeueLostAgentJobs(ctx))
+
+	// The lost job is handed to the next agent
+	job, err := store.ClaimJob(ctx, agent)
+	s.NoError(err)
+	s.NotNil(job)
+	s.Equal(lost.ID, job.DeploymentID)
+
+	// The abandoned deployment is marked as failed
+	d, err := deploy.NewStore().DeploymentByID(ctx, abandoned.ID)
+	s.NoError(err)
+	s.Equal(int64(deploy.ExitCodeFailed), d.ExitCode.ValueOrZero())
+
+	// The deployment of the agent that is alive is not touched
+	job, err = store.ClaimJob(ctx, agent)
+	s.NoError(err)
+	s.Nil(job)
+}
+
+func TestJobBuildAgentsSuite(t *testing.T) {
+	suite.Run(t, &JobBuildAgentsSuite{})
+}
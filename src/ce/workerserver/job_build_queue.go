@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// DispatchBuildQueue starts the queued deployments once the concurrency
+// limits allow it.
+func DispatchBuildQueue(ctx context.Context) error {
+	if err := deployservice.DispatchQueue(ctx); err != nil {
+		slog.Errorf("error while dispatching the build queue: %v", err)
+		return err
+	}
+
+	return nil
+}
+
+// RemoveOldQueueEntries removes the build queue entries of deployments that
+// were dispatched or cancelled more than a week ago.
+func RemoveOldQueueEntries(ctx context.Context) error {
+	if err := deploy.NewStore().RemoveOldQueueEntries(ctx); err != nil {
+		slog.Errorf("error while removing old queue entries: %v", err)
+		return err
+	}
+
+	return nil
+}
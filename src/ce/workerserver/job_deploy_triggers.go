@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploytrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// RemoveOldDeployTriggerInvocations removes the deploy trigger invocations
+// that are older than 30 days.
+func RemoveOldDeployTriggerInvocations(ctx context.Context) error {
+	if err := deploytrigger.NewStore().RemoveOldInvocations(ctx); err != nil {
+		slog.Errorf("error while removing old deploy trigger invocations: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -4,6 +4,8 @@ import (
 	"context"
 	"strings"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/lib/config"
 	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
@@ -13,21 +15,20 @@ import (
 type KeyContextNumberOfDeploymentsToDelete struct{}
 
 // RemoveDeploymentArtifactsManually removes the artifacts of expired deployments.
-// An expired deployment is a deployment that has not been used for more than 30 days.
-// Overwrite the numberOfDays to set a custom expiration time.
-func RemoveDeploymentArtifactsManually(ctx context.Context, numberOfDays int) ([]string, error) {
+// Deployments expire according to the retention policy of their environment, or
+// the default retention policy of the instance. When dryRun is true, the ids of
+// the expired deployments are returned without removing their artifacts.
+func RemoveDeploymentArtifactsManually(ctx context.Context, dryRun bool) ([]string, error) {
 	limit, _ := ctx.Value(KeyContextNumberOfDeploymentsToDelete{}).(int)
 
 	if limit <= 0 {
 		limit = 100
 	}
 
-	if numberOfDays == 0 {
-		numberOfDays = 30
-	}
-
-	store := NewStore()
-	deployments, err := store.DeploymentsOlderThan30Days(ctx, numberOfDays, limit)
+	deployments, err := deploy.NewStore().ExpiredDeployments(ctx, deploy.ExpiredDeploymentsFilters{
+		Policy: admin.MustConfig().Retention(),
+		Limit:  limit,
+	})
 
 	if err != nil {
 		return nil, err
@@ -37,6 +38,17 @@ func RemoveDeploymentArtifactsManually(ctx context.Context, numberOfDays int) ([
 		return nil, nil
 	}
 
+	if dryRun {
+		ids := []string{}
+
+		for _, d := range deployments {
+			ids = append(ids, d.ID.String())
+		}
+
+		return ids, nil
+	}
+
+	store := NewStore()
 	client := integrations.Client()
 	idsToBeMarked := []types.ID{}
 	idsToBeMarkedStr := []string{}
@@ -67,7 +79,7 @@ func RemoveDeploymentArtifactsManually(ctx context.Context, numberOfDays int) ([
 
 // RemoveDeploymentArtifacts is a job to remove the artifacts of expired deployments.
 func RemoveDeploymentArtifacts(ctx context.Context) error {
-	idsToBeMarked, err := RemoveDeploymentArtifactsManually(ctx, 30)
+	idsToBeMarked, err := RemoveDeploymentArtifactsManually(ctx, false)
 
 	if err != nil {
 		return err
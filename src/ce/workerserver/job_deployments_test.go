@@ -4,6 +4,8 @@ import (
 	"context"
 	"testing"
 
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
@@ -92,6 +94,86 @@ func (s *JobDeploymentsSuite) Test_RemoveDeploymentsArtifacts() {
 	s.Equal(ids[1], deployments[1].ID)
 }
 
+func (s *JobDeploymentsSuite) Test_RemoveDeploymentsArtifacts_RetentionPolicy() {
+	usr := s.MockUser()
+	app := s.MockApp(usr)
+	env := s.MockEnv(app, map[string]any{
+		"Data": &buildconf.BuildConf{
+			Retention: &admin.RetentionPolicy{KeepLast: 1, MaxAgeDays: 10},
+		},
+	})
+
+	T45daysAgo := utils.NewUnix()
+	T45daysAgo.Time = T45daysAgo.AddDate(0, 0, -45)
+
+	T30daysAgo := utils.NewUnix()
+	T30daysAgo.Time = T30daysAgo.AddDate(0, 0, -30)
+
+	T15daysAgo := utils.NewUnix()
+	T15daysAgo.Time = T15daysAgo.AddDate(0, 0, -15)
+
+	// We have 4 deployments older than the maximum age of the environment:
+	//
+	// - A pinned deployment    (pinned - so no deletion)
+	// - A tagged deployment    (tagged - so no deletion)
+	// - A deployment           (this one should be removed)
+	// - The latest deployment  (kept because of keepLast)
+	deployments := s.MockDeployments(
+		4,
+		env,
+		map[string]any{"CreatedAt": T45daysAgo, "StorageLocation": null.StringFrom("local:/d-1")},
+		map[string]any{"CreatedAt": T45daysAgo, "StorageLocation": null.StringFrom("local:/d-2")},
+		map[string]any{"CreatedAt": T30daysAgo, "StorageLocation": null.StringFrom("local:/d-3")},
+		map[string]any{"CreatedAt": T15daysAgo, "StorageLocation": null.StringFrom("local:/d-4")},
+	)
+
+	deployments[0].Pinned = true
+	deployments[1].Tag = null.StringFrom("v1.0.0")
+
+	s.NoError(deploy.NewStore().UpdateRetention(context.Background(), deployments[0].Deployment))
+	s.NoError(deploy.NewStore().UpdateRetention(context.Background(), deployments[1].Deployment))
+
+	ids, err := jobs.RemoveDeploymentArtifactsManually(context.Background(), true)
+	s.NoError(err)
+	s.Equal([]string{deployments[2].ID.String()}, ids)
+
+	s.mockClient.On("DeleteArtifacts", mock.Anything, integrations.DeleteArtifactsArgs{StorageLocation: "local:/d-3"}).Return(nil).Once()
+	s.NoError(jobs.RemoveDeploymentArtifacts(context.Background()))
+	s.mockClient.AssertExpectations(s.T())
+}
+
+func (s *JobDeploymentsSuite) Test_RemoveDeploymentsArtifacts_PromotedDeployment() {
+	ctx := context.Background()
+	app := s.MockApp(s.MockUser())
+	staging := s.MockEnv(app, map[string]any{"Name": "staging"})
+	production := s.MockEnv(app, map[string]any{"Name": "production"})
+
+	T45daysAgo := utils.NewUnix()
+	T45daysAgo.Time = T45daysAgo.AddDate(0, 0, -45)
+
+	source := s.MockDeployment(staging, map[string]any{
+		"CreatedAt":        T45daysAgo,
+		"StorageLocation":  null.StringFrom("local:/d-1"),
+		"FunctionLocation": null.StringFrom("local:/d-1/fn"),
+	})
+
+	promoted := &deploy.Deployment{EnvID: production.ID, Env: production.Name, ConfigCopy: source.ConfigCopy}
+	s.NoError(deploy.NewStore().InsertPromotedDeployment(ctx, source.ID, promoted, nil))
+
+	// The source is expired, but the promoted deployment still uses its artifacts
+	ids, err := jobs.RemoveDeploymentArtifactsManually(ctx, true)
+	s.NoError(err)
+	s.Empty(ids)
+
+	// Once the promoted deployment is deleted as well, both are removed
+	_, err = s.conn.Exec(`UPDATE deployments SET deleted_at = NOW() WHERE deployment_id = $1`, promoted.ID)
+	s.NoError(err)
+
+	ids, err = jobs.RemoveDeploymentArtifactsManually(ctx, true)
+	s.NoError(err)
+	s.Equal([]string{source.ID.String(), promoted.ID.String()}, ids)
+}
+
 func TestJobDeploymentsSuite(t *testing.T) {
 	suite.Run(t, &JobDeploymentsSuite{})
 }
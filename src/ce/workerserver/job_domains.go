@@ -10,13 +10,19 @@ import (
 
 	"github.com/aws/aws-sdk-go/aws"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 )
 
 var CurrentMinute = func() int { return time.Now().Minute() }
 
+// CertificateExpiryThreshold is how long before the expiry date of a
+// certificate the expiring webhooks are triggered.
+const CertificateExpiryThreshold = 14 * 24 * time.Hour
+
 // PingDomains pings domains based on the configuration settings and updates the last ping timestamp.
 // It retrieves the configuration settings from the admin store and determines the ping interval and concurrency.
 // Then, it fetches the domains from the domain store based on the modulo of the current minute and the interval.
@@ -108,6 +114,10 @@ func PingDomains(ctx context.Context) error {
 
 				if res != nil {
 					pr.Status = res.StatusCode
+
+					if res.TLS != nil && len(res.TLS.PeerCertificates) > 0 {
+						pr.CertExpiresAt = res.TLS.PeerCertificates[0].NotAfter.Unix()
+					}
 				}
 
 				rchan <- pr
@@ -136,9 +146,59 @@ func PingDomains(ctx context.Context) error {
 		return err
 	}
 
+	triggerDomainWebhooks(ctx, domains, pingResults)
+
 	return nil
 }
 
+// triggerDomainWebhooks dispatches the outbound webhooks of the domains that
+// started failing, or whose certificate is about to expire. Webhooks are only
+// triggered on the transition, not on every ping.
+func triggerDomainWebhooks(ctx context.Context, domains []*buildconf.DomainModel, results []buildconf.PingResult) {
+	byID := map[types.ID]*buildconf.DomainModel{}
+
+	for _, domain := range domains {
+		byID[domain.ID] = domain
+	}
+
+	for _, pr := range results {
+		domain := byID[pr.DomainID]
+
+		if domain == nil {
+			continue
+		}
+
+		prev := domain.LastPing
+
+		if !isHealthy(pr.Status) && (prev == nil || isHealthy(prev.Status)) {
+			app.TriggerDomainEvent(ctx, app.TriggerOnDomainFailing, domain, app.OutboundWebhookSettings{
+				DomainError: utils.GetString(pr.Error, fmt.Sprintf("Domain responded with status %d", pr.Status)),
+			})
+		}
+
+		if certExpiresSoon(pr) && (prev == nil || !certExpiresSoon(*prev) || prev.CertExpiresAt != pr.CertExpiresAt) {
+			app.TriggerDomainEvent(ctx, app.TriggerOnCertificateExpiring, domain, app.OutboundWebhookSettings{
+				CertificateExpiresAt: utils.UnixFrom(time.Unix(pr.CertExpiresAt, 0)),
+			})
+		}
+	}
+}
+
+// isHealthy returns true when the domain responded with a 2xx or 3xx status.
+func isHealthy(status int) bool {
+	return status >= 200 && status < 400
+}
+
+// certExpiresSoon returns true when the certificate expires within the
+// threshold, relative to the time of the ping.
+func certExpiresSoon(pr buildconf.PingResult) bool {
+	if pr.CertExpiresAt == 0 {
+		return false
+	}
+
+	return time.Unix(pr.CertExpiresAt, 0).Sub(pr.LastPingAt.Time) < CertificateExpiryThreshold
+}
+
 func isSuccess(res *shttp.HTTPResponse) bool {
 	if res == nil {
 		return false
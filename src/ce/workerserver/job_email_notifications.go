@@ -0,0 +1,89 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	"github.com/stormkit-io/stormkit-io/src/ee/api/team"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+)
+
+// HandleEmailNotification emails the team members of the app about the event,
+// according to their notification preferences. Each recipient receives a
+// separate email, so that addresses are not shared within the team.
+func HandleEmailNotification(ctx context.Context, t *asynq.Task) error {
+	msg := app.EmailNotificationMessage{}
+
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		slog.Errorf("HandleEmailNotification cannot unmarshal payload information: %v", err)
+		return err
+	}
+
+	smtp := mailer.InstanceConfig()
+
+	// SMTP has been disabled in the meantime.
+	if smtp == nil {
+		return nil
+	}
+
+	appl, err := app.NewStore().AppByID(ctx, msg.Settings.AppID)
+
+	if err != nil || appl == nil {
+		return err
+	}
+
+	members, err := team.NewStore().TeamMembers(ctx, appl.TeamID)
+
+	if err != nil {
+		return err
+	}
+
+	userIDs := []types.ID{}
+
+	for _, m := range members {
+		userIDs = append(userIDs, m.UserID)
+	}
+
+	prefs, err := user.NewStore().NotificationPreferences(ctx, userIDs)
+
+	if err != nil {
+		return err
+	}
+
+	to := app.EmailRecipients(msg.Event, msg.Settings, members, prefs)
+
+	if len(to) == 0 {
+		return nil
+	}
+
+	email, err := app.NewNotificationEmail(msg.Event, msg.Settings, appl.DisplayName)
+
+	if err != nil {
+		slog.Errorf("error while rendering email notification: %v", err)
+		return nil
+	}
+
+	sent := 0
+
+	for _, addr := range to {
+		if err = mailer.Send(smtp, *email, []string{addr}); err != nil {
+			slog.Errorf("error while sending email notification to %s: %v", addr, err)
+			continue
+		}
+
+		sent++
+	}
+
+	// Retry only when the mail server rejected all emails, so that
+	// recipients do not receive the same email twice.
+	if sent == 0 {
+		return err
+	}
+
+	return nil
+}
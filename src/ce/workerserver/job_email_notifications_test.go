@@ -0,0 +1,129 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/smtp"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/mailer"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobEmailNotificationsSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobEmailNotificationsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+
+	vc := admin.InstanceConfig{
+		SMTPConfig: &admin.SMTPConfig{
+			Host:     "smtp.example.org",
+			Username: "notifications@example.org",
+			Password: "secret",
+			From:     "Stormkit <notifications@example.org>",
+		},
+	}
+
+	s.NoError(admin.Store().UpsertConfig(context.Background(), vc))
+}
+
+func (s *JobEmailNotificationsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	admin.ResetCache(context.Background())
+	mailer.SendMail = smtp.SendMail
+}
+
+func (s *JobEmailNotificationsSuite) task(event string, settings app.OutboundWebhookSettings) *asynq.Task {
+	payload, err := json.Marshal(app.EmailNotificationMessage{
+		Event:    event,
+		Settings: settings,
+	})
+
+	s.NoError(err)
+	return asynq.NewTask(tasks.EmailNotification, payload)
+}
+
+func (s *JobEmailNotificationsSuite) Test_DeployFailed_SentToAuthor() {
+	usr := s.MockUser()
+	appl := s.MockApp(usr)
+	calls := 0
+
+	mailer.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		calls++
+		s.Equal("smtp.example.org:587", addr)
+		s.Equal([]string{usr.PrimaryEmail()}, to)
+		s.Contains(string(msg), "From: Stormkit <notifications@example.org>")
+		s.Contains(string(msg), "Deployment #15 failed")
+		s.Contains(string(msg), "build failed")
+		return nil
+	}
+
+	s.NoError(jobs.HandleEmailNotification(context.Background(), s.task(app.TriggerOnDeployFailed, app.OutboundWebhookSettings{
+		AppID:            appl.ID,
+		DeploymentID:     15,
+		DeploymentError:  "build failed",
+		DeploymentAuthor: "Jane <" + usr.PrimaryEmail() + ">",
+		EnvironmentName:  "production",
+	})))
+
+	s.Equal(1, calls)
+}
+
+func (s *JobEmailNotificationsSuite) Test_DeployFailed_OtherAuthor() {
+	appl := s.MockApp(nil)
+	calls := 0
+
+	mailer.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		calls++
+		return nil
+	}
+
+	s.NoError(jobs.HandleEmailNotification(context.Background(), s.task(app.TriggerOnDeployFailed, app.OutboundWebhookSettings{
+		AppID:            appl.ID,
+		DeploymentID:     15,
+		DeploymentAuthor: "Joe <joe@example.org>",
+	})))
+
+	s.Equal(0, calls)
+}
+
+func (s *JobEmailNotificationsSuite) Test_OptedOut() {
+	usr := s.MockUser()
+	appl := s.MockApp(usr)
+	calls := 0
+
+	prefs := user.DefaultNotificationPreferences()
+	prefs.DomainFailing = false
+
+	s.NoError(user.NewStore().UpdateNotificationPreferences(context.Background(), usr.ID, prefs))
+
+	mailer.SendMail = func(addr string, a smtp.Auth, from string, to []string, msg []byte) error {
+		calls++
+		return nil
+	}
+
+	s.NoError(jobs.HandleEmailNotification(context.Background(), s.task(app.TriggerOnDomainFailing, app.OutboundWebhookSettings{
+		AppID:       appl.ID,
+		DomainName:  "www.example.org",
+		DomainError: "connection refused",
+	})))
+
+	s.Equal(0, calls)
+}
+
+func TestJobEmailNotifications(t *testing.T) {
+	suite.Run(t, &JobEmailNotificationsSuite{})
+}
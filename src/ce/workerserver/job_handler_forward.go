@@ -6,6 +6,7 @@ import (
 	"errors"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/applog"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/user"
 	"github.com/stormkit-io/stormkit-io/src/ee/api/analytics"
@@ -30,6 +31,8 @@ type HostingRecord struct {
 	Analytics       *analytics.Record  `json:"analytics"`
 	TotalBandwidth  int64              `json:"totalBandwidth"`
 	FunctionInvoked bool               `json:"functionInvoked"`
+	FunctionError   bool               `json:"functionError,omitempty"` // Whether the function returned an error.
+	StatusCode      int                `json:"statusCode,omitempty"`    // The response status code. Zero for records that only carry logs.
 }
 
 // IngestHandlerForward reads last 100 rows from redis, and inserts them into the database.
@@ -47,6 +50,7 @@ func IngestHandlerForward(ctx context.Context) error {
 	analyticsRecords := []analytics.Record{}
 	logRecords := []*applog.Log{}
 	stats := map[string]map[string]int64{} // userId -> metric -> value
+	rollouts := map[types.ID]deploy.RolloutMetrics{}
 	rows := 100
 
 	for i := 0; i < rows; i = i + 1 {
@@ -90,6 +94,21 @@ func IngestHandlerForward(ctx context.Context) error {
 			}
 		}
 
+		if record.StatusCode != 0 && record.DeploymentID != 0 {
+			metrics := rollouts[record.DeploymentID]
+			metrics.Requests++
+
+			if record.StatusCode >= 500 {
+				metrics.Errors5xx++
+			}
+
+			if record.FunctionError {
+				metrics.FunctionErrors++
+			}
+
+			rollouts[record.DeploymentID] = metrics
+		}
+
 		userID := record.BillingUserID.String()
 
 		if stats[userID] == nil {
@@ -118,6 +137,12 @@ func IngestHandlerForward(ctx context.Context) error {
 		}
 	}
 
+	if len(rollouts) > 0 {
+		if err := deploy.IncrementRolloutMetrics(ingestContext, rollouts); err != nil {
+			slog.Errorf("error while incrementing rollout metrics: %v", err)
+		}
+	}
+
 	userStats := []user.Usage{}
 
 	for userID, metrics := range stats {
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/redis/go-redis/v9"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
 	"github.com/stormkit-io/stormkit-io/src/ee/api/analytics"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
@@ -171,6 +172,32 @@ func (s *JobHandlerForwardTest) Test_IngestHandlerForward_RecordWithoutLogs() {
 	s.Equal(int64(0), length)
 }
 
+func (s *JobHandlerForwardTest) Test_IngestHandlerForward_RolloutMetrics() {
+	deploymentID := types.ID(789)
+	deploy.ResetRolloutMetrics(s.ctx, deploymentID)
+	defer s.client.Del(s.ctx, "rollout_metrics:789")
+
+	for _, status := range []int{200, 500, 502} {
+		record := s.createTestRecord()
+		record.StatusCode = status
+		record.FunctionError = status == 502
+		s.pushToQueue(record)
+	}
+
+	// Deployments that are not rolled out are not tracked
+	record := s.createTestRecord()
+	record.DeploymentID = types.ID(790)
+	record.StatusCode = 500
+	s.pushToQueue(record)
+
+	s.NoError(jobs.IngestHandlerForward(s.ctx))
+
+	metrics, err := deploy.RolloutMetricsByDeploymentID(s.ctx, deploymentID)
+	s.NoError(err)
+	s.Equal(deploy.RolloutMetrics{Requests: 3, Errors5xx: 2, FunctionErrors: 1}, metrics)
+	s.Equal(int64(0), s.client.Exists(s.ctx, "rollout_metrics:790").Val())
+}
+
 func TestJobHandlerForwardTest(t *testing.T) {
 	suite.Run(t, &JobHandlerForwardTest{})
 }
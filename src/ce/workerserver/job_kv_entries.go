@@ -0,0 +1,19 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// RemoveExpiredKVEntries removes the key-value entries that have expired.
+// Expired entries are not returned by the store, this job only reclaims space.
+func RemoveExpiredKVEntries(ctx context.Context) error {
+	if err := kvstore.NewStore().RemoveExpired(ctx); err != nil {
+		slog.Errorf("error while removing expired kv entries: %v", err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,41 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// HandleNotificationDelivery sends a notification to its channel. A failed
+// attempt returns an error so that the task is retried with an exponential
+// backoff.
+func HandleNotificationDelivery(ctx context.Context, t *asynq.Task) error {
+	msg := app.NotificationDeliveryMessage{}
+
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		slog.Errorf("HandleNotificationDelivery cannot unmarshal payload information: %v", err)
+		return err
+	}
+
+	channel, err := app.NewStore().NotificationChannel(ctx, msg.AppID, msg.ChannelID)
+
+	if err != nil {
+		slog.Errorf("error while fetching notification channel: %v", err)
+		return err
+	}
+
+	// The channel has been removed in the meantime.
+	if channel == nil {
+		return nil
+	}
+
+	if err := channel.Send(msg.Message); err != nil {
+		slog.Errorf("notification delivery to channel %s failed: %v", channel.ID.String(), err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,116 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/testutils"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobNotificationChannelsSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobNotificationChannelsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *JobNotificationChannelsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *JobNotificationChannelsSuite) task(appl *factory.MockApp, nc *app.NotificationChannel) *asynq.Task {
+	payload, err := json.Marshal(app.NotificationDeliveryMessage{
+		AppID:     appl.ID,
+		ChannelID: nc.ID,
+		Message: app.NewNotificationMessage(app.TriggerOnPublish, app.OutboundWebhookSettings{
+			AppID:           appl.ID,
+			EnvironmentName: "production",
+		}),
+	})
+
+	s.NoError(err)
+	return asynq.NewTask(tasks.NotificationDelivery, payload)
+}
+
+func (s *JobNotificationChannelsSuite) Test_Success() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusOK,
+		Method:   shttp.MethodPost,
+		DataText: "ok",
+		Expect: func(req *http.Request) {
+			body, _ := io.ReadAll(req.Body)
+			s.Contains(string(body), `"attachments"`)
+		},
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	ctx := context.Background()
+	appl := s.MockApp(nil)
+	nc := &app.NotificationChannel{
+		AppID:    appl.ID,
+		Name:     "Deployments",
+		Provider: app.NotificationProviderSlack,
+		Events:   []string{app.TriggerOnPublish},
+		Config:   app.NotificationChannelConfig{WebhookURL: ms.URL()},
+	}
+
+	s.NoError(app.NewStore().InsertNotificationChannel(ctx, nc))
+	s.NoError(jobs.HandleNotificationDelivery(ctx, s.task(appl, nc)))
+	s.Equal(1, mr.NumberOfCalls)
+}
+
+func (s *JobNotificationChannelsSuite) Test_Failure() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusInternalServerError,
+		Method:   shttp.MethodPost,
+		DataText: "error",
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	ctx := context.Background()
+	appl := s.MockApp(nil)
+	nc := &app.NotificationChannel{
+		AppID:    appl.ID,
+		Name:     "Deployments",
+		Provider: app.NotificationProviderTeams,
+		Events:   []string{app.TriggerOnPublish},
+		Config:   app.NotificationChannelConfig{WebhookURL: ms.URL()},
+	}
+
+	s.NoError(app.NewStore().InsertNotificationChannel(ctx, nc))
+	s.Error(jobs.HandleNotificationDelivery(ctx, s.task(appl, nc)))
+	s.Equal(1, mr.NumberOfCalls)
+}
+
+func (s *JobNotificationChannelsSuite) Test_DeletedChannel() {
+	ctx := context.Background()
+	appl := s.MockApp(nil)
+	nc := &app.NotificationChannel{AppID: appl.ID, ID: 1500}
+
+	s.NoError(jobs.HandleNotificationDelivery(ctx, s.task(appl, nc)))
+}
+
+func TestJobNotificationChannels(t *testing.T) {
+	suite.Run(t, &JobNotificationChannelsSuite{})
+}
@@ -0,0 +1,83 @@
+package jobs
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// HandleOutboundWebhookDelivery delivers an outbound webhook and records the
+// attempt. A failed attempt returns an error so that the task is retried with
+// an exponential backoff. When the last retry fails, the consecutive failures
+// of the webhook are incremented and the webhook is disabled once they reach
+// app.OutboundWebhookMaxFailures.
+func HandleOutboundWebhookDelivery(ctx context.Context, t *asynq.Task) error {
+	msg := app.OutboundWebhookMessage{}
+
+	if err := json.Unmarshal(t.Payload(), &msg); err != nil {
+		slog.Errorf("HandleOutboundWebhookDelivery cannot unmarshal payload information: %v", err)
+		return err
+	}
+
+	store := app.NewStore()
+	wh := store.OutboundWebhook(ctx, msg.AppID, msg.WebhookID)
+
+	// The webhook has been removed or disabled in the meantime.
+	if wh == nil || wh.IsDisabled() {
+		return nil
+	}
+
+	retried, _ := asynq.GetRetryCount(ctx)
+	maxRetry, _ := asynq.GetMaxRetry(ctx)
+
+	delivery := wh.Send(msg.Payload)
+	delivery.TriggerWhen = msg.TriggerWhen
+	delivery.Attempt = retried + 1
+
+	if err := store.InsertOutboundWebhookDelivery(ctx, delivery); err != nil {
+		slog.Errorf("error while inserting outbound webhook delivery: %v", err)
+	}
+
+	if delivery.IsSuccess {
+		if wh.ConsecutiveFailures > 0 {
+			if err := store.ResetOutboundWebhookFailures(ctx, wh.WebhookID); err != nil {
+				slog.Errorf("error while resetting outbound webhook failures: %v", err)
+			}
+		}
+
+		return nil
+	}
+
+	if retried >= maxRetry {
+		disabled, err := store.IncrementOutboundWebhookFailures(ctx, wh.WebhookID)
+
+		if err != nil {
+			slog.Errorf("error while incrementing outbound webhook failures: %v", err)
+		}
+
+		if disabled {
+			slog.Infof("disabled outbound webhook %s after sustained failures", wh.WebhookID.String())
+		}
+	}
+
+	if delivery.Response.Error != "" {
+		return fmt.Errorf("outbound webhook delivery failed: %s", delivery.Response.Error)
+	}
+
+	return fmt.Errorf("outbound webhook delivery failed with status %d", delivery.Response.Result.Status)
+}
+
+// RemoveOldOutboundWebhookDeliveries removes the outbound webhook deliveries
+// that are older than 30 days.
+func RemoveOldOutboundWebhookDeliveries(ctx context.Context) error {
+	if err := app.NewStore().RemoveOldOutboundWebhookDeliveries(ctx); err != nil {
+		slog.Errorf("error while removing old outbound webhook deliveries: %v", err)
+		return err
+	}
+
+	return nil
+}
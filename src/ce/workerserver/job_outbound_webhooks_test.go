@@ -0,0 +1,128 @@
+package jobs_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
+	"github.com/stormkit-io/stormkit-io/src/lib/testutils"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stretchr/testify/suite"
+)
+
+type JobOutboundWebhooksSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobOutboundWebhooksSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+}
+
+func (s *JobOutboundWebhooksSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+}
+
+func (s *JobOutboundWebhooksSuite) task(appl *factory.MockApp, wh *app.OutboundWebhook) *asynq.Task {
+	payload, err := json.Marshal(app.OutboundWebhookMessage{
+		AppID:       appl.ID,
+		WebhookID:   wh.WebhookID,
+		TriggerWhen: wh.TriggerWhen,
+		Payload:     `{"hello":"world"}`,
+	})
+
+	s.NoError(err)
+	return asynq.NewTask(tasks.OutboundWebhookDelivery, payload)
+}
+
+func (s *JobOutboundWebhooksSuite) Test_Success() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status:   http.StatusOK,
+		Method:   shttp.MethodPost,
+		DataText: "ok",
+		Expect: func(req *http.Request) {
+			s.NotEmpty(req.Header.Get(shttp.HeaderSignature))
+		},
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	ctx := context.Background()
+	appl := s.MockApp(nil)
+	store := app.NewStore()
+	wh := &app.OutboundWebhook{
+		RequestURL:    ms.URL(),
+		RequestMethod: shttp.MethodPost,
+		TriggerWhen:   app.TriggerOnPublish,
+		Secret:        utils.GenerateSigningSecret(),
+	}
+
+	s.NoError(store.InsertOutboundWebhook(ctx, appl.ID, wh))
+	s.NoError(jobs.HandleOutboundWebhookDelivery(ctx, s.task(appl, wh)))
+	s.Equal(1, mr.NumberOfCalls)
+
+	deliveries, err := store.OutboundWebhookDeliveries(ctx, appl.ID, wh.WebhookID)
+	s.NoError(err)
+	s.Len(deliveries, 1)
+	s.True(deliveries[0].IsSuccess)
+	s.Equal(1, deliveries[0].Attempt)
+	s.Equal(app.TriggerOnPublish, deliveries[0].TriggerWhen)
+	s.Equal(`{"hello":"world"}`, deliveries[0].Request.Payload)
+	s.Equal(http.StatusOK, deliveries[0].Response.Result.Status)
+}
+
+func (s *JobOutboundWebhooksSuite) Test_DisableAfterSustainedFailures() {
+	ms := testutils.MockServer()
+	mr := testutils.MockResponse{
+		Status: http.StatusServiceUnavailable,
+		Method: shttp.MethodPost,
+	}
+
+	ms.NewResponse("/", &mr)
+	defer ms.Close()
+
+	ctx := context.Background()
+	appl := s.MockApp(nil)
+	store := app.NewStore()
+	wh := &app.OutboundWebhook{
+		RequestURL:    ms.URL(),
+		RequestMethod: shttp.MethodPost,
+		TriggerWhen:   app.TriggerOnPublish,
+	}
+
+	s.NoError(store.InsertOutboundWebhook(ctx, appl.ID, wh))
+
+	// Outside of the worker server, every call is treated as the last retry.
+	for i := 0; i < app.OutboundWebhookMaxFailures; i++ {
+		s.Error(jobs.HandleOutboundWebhookDelivery(ctx, s.task(appl, wh)))
+	}
+
+	updated := store.OutboundWebhook(ctx, appl.ID, wh.WebhookID)
+	s.True(updated.IsDisabled())
+	s.Equal(app.OutboundWebhookMaxFailures, updated.ConsecutiveFailures)
+
+	// Disabled webhooks are skipped
+	s.NoError(jobs.HandleOutboundWebhookDelivery(ctx, s.task(appl, wh)))
+	s.Equal(app.OutboundWebhookMaxFailures, mr.NumberOfCalls)
+
+	deliveries, err := store.OutboundWebhookDeliveries(ctx, appl.ID, wh.WebhookID)
+	s.NoError(err)
+	s.Len(deliveries, app.OutboundWebhookMaxFailures)
+	s.False(deliveries[0].IsSuccess)
+}
+
+func TestJobOutboundWebhooks(t *testing.T) {
+	suite.Run(t, &JobOutboundWebhooksSuite{})
+}
@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// AdvanceRollouts checks the running rollouts. Rollouts whose candidate deployment
+// exceeds the error thresholds are rolled back, and the ones whose current step is
+// over move on to the next step.
+func AdvanceRollouts(ctx context.Context) error {
+	rollouts, err := deploy.NewStore().Rollouts(ctx, deploy.RolloutFilters{
+		Status: deploy.RolloutStatusRunning,
+		Limit:  1000,
+	})
+
+	if err != nil {
+		slog.Errorf("error while selecting running rollouts: %v", err)
+		return err
+	}
+
+	for _, r := range rollouts {
+		if err := deploy.AdvanceRollout(ctx, r); err != nil {
+			slog.Errorf("error while advancing rollout %s: %v", r.ID.String(), err)
+		}
+	}
+
+	return nil
+}
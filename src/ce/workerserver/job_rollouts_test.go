@@ -0,0 +1,75 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type JobRolloutsSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobRolloutsSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+
+	cache := &mocks.CacheInterface{}
+	cache.On("Reset", mock.Anything).Return(nil)
+	appcache.DefaultCacheService = cache
+}
+
+func (s *JobRolloutsSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	appcache.DefaultCacheService = nil
+}
+
+func (s *JobRolloutsSuite) Test_AdvanceRollouts_Rollback() {
+	ctx := context.Background()
+	env := s.MockEnv(nil)
+	previous := s.MockDeployment(env, map[string]any{
+		"ExitCode":  null.IntFrom(0),
+		"Published": []deploy.PublishedInfo{{EnvID: env.ID, Percentage: 100}},
+	})
+
+	candidate := s.MockDeployment(env, map[string]any{"ExitCode": null.IntFrom(0)})
+	defer rediscache.Client().Del(ctx, "rollout_metrics:"+candidate.ID.String())
+
+	_, err := deploy.StartRollout(ctx, candidate.Deployment, buildconf.RolloutPlan{
+		Steps:             []buildconf.RolloutStep{{Percentage: 10, Interval: 30}, {Percentage: 100}},
+		MaxFunctionErrors: 1,
+	})
+
+	s.NoError(err)
+	s.NoError(deploy.IncrementRolloutMetrics(ctx, map[types.ID]deploy.RolloutMetrics{
+		candidate.ID: {Requests: 5, FunctionErrors: 2},
+	}))
+
+	s.NoError(jobs.AdvanceRollouts(ctx))
+
+	r, err := deploy.NewStore().RolloutByEnvID(ctx, env.ID)
+	s.NoError(err)
+	s.Equal(deploy.RolloutStatusRolledBack, r.Status)
+
+	var deploymentID types.ID
+	s.NoError(s.conn.QueryRow(`SELECT deployment_id FROM deployments_published WHERE env_id = $1`, env.ID).Scan(&deploymentID))
+	s.Equal(previous.ID, deploymentID)
+}
+
+func TestJobRolloutsSuite(t *testing.T) {
+	suite.Run(t, &JobRolloutsSuite{})
+}
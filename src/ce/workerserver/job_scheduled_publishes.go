@@ -0,0 +1,31 @@
+package jobs
+
+import (
+	"context"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+)
+
+// PublishScheduledDeployments publishes the scheduled publishes whose publish
+// date has passed. Publishes that fall into a freeze window are marked as failed.
+func PublishScheduledDeployments(ctx context.Context) error {
+	schedules, err := deploy.NewStore().ScheduledPublishes(ctx, deploy.ScheduledPublishFilters{
+		Status: deploy.ScheduledPublishStatusPending,
+		Due:    true,
+		Limit:  1000,
+	})
+
+	if err != nil {
+		slog.Errorf("error while selecting scheduled publishes: %v", err)
+		return err
+	}
+
+	for _, sp := range schedules {
+		if err := deploy.ExecuteScheduledPublish(ctx, sp); err != nil {
+			slog.Errorf("error while executing scheduled publish %s: %v", sp.ID.String(), err)
+		}
+	}
+
+	return nil
+}
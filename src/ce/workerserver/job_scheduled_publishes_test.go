@@ -0,0 +1,137 @@
+package jobs_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appcache"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
+	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+	"github.com/stormkit-io/stormkit-io/src/mocks"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
+)
+
+type JobScheduledPublishesSuite struct {
+	suite.Suite
+	*factory.Factory
+	conn databasetest.TestDB
+}
+
+func (s *JobScheduledPublishesSuite) BeforeTest(suiteName, _ string) {
+	s.conn = databasetest.InitTx(suiteName)
+	s.Factory = factory.New(s.conn)
+
+	cache := &mocks.CacheInterface{}
+	cache.On("Reset", mock.Anything).Return(nil)
+	appcache.DefaultCacheService = cache
+}
+
+func (s *JobScheduledPublishesSuite) AfterTest(_, _ string) {
+	s.conn.CloseTx()
+	appcache.DefaultCacheService = nil
+}
+
+func (s *JobScheduledPublishesSuite) schedule(env *factory.MockEnv, publishAt time.Time) *deploy.ScheduledPublish {
+	d := s.MockDeployment(env, map[string]any{"ExitCode": null.IntFrom(0)})
+	sp := &deploy.ScheduledPublish{
+		AppID:     env.AppID,
+		EnvID:     env.ID,
+		Config:    []deploy.PublishConfig{{DeploymentID: d.ID, Percentage: 100}},
+		PublishAt: utils.UnixFrom(publishAt),
+		Status:    deploy.ScheduledPublishStatusPending,
+	}
+
+	s.NoError(deploy.NewStore().InsertScheduledPublish(context.Background(), sp))
+	return sp
+}
+
+func (s *JobScheduledPublishesSuite) status(envID types.ID) map[types.ID]string {
+	schedules, err := deploy.NewStore().ScheduledPublishes(context.Background(), deploy.ScheduledPublishFilters{EnvID: envID})
+	s.NoError(err)
+
+	statuses := map[types.ID]string{}
+
+	for _, sp := range schedules {
+		statuses[sp.ID] = sp.Status
+	}
+
+	return statuses
+}
+
+func (s *JobScheduledPublishesSuite) Test_PublishScheduledDeployments() {
+	env := s.MockEnv(nil)
+	due := s.schedule(env, time.Now().Add(-time.Minute))
+	later := s.schedule(env, time.Now().Add(time.Hour))
+
+	s.NoError(jobs.PublishScheduledDeployments(context.Background()))
+
+	s.Equal(map[types.ID]string{
+		due.ID:   deploy.ScheduledPublishStatusPublished,
+		later.ID: deploy.ScheduledPublishStatusPending,
+	}, s.status(env.ID))
+
+	var deploymentID types.ID
+	s.NoError(s.conn.QueryRow(`SELECT deployment_id FROM deployments_published WHERE env_id = $1`, env.ID).Scan(&deploymentID))
+	s.Equal(due.Config[0].DeploymentID, deploymentID)
+}
+
+func (s *JobScheduledPublishesSuite) Test_PublishScheduledDeployments_FreezeWindow() {
+	env := s.MockEnv(nil, map[string]any{
+		"Data": &buildconf.BuildConf{
+			FreezeWindows: []buildconf.FreezeWindow{{
+				Name:     "Release freeze",
+				StartsAt: utils.UnixFrom(time.Now().Add(-time.Hour)),
+				EndsAt:   utils.UnixFrom(time.Now().Add(time.Hour)),
+			}},
+		},
+	})
+
+	due := s.schedule(env, time.Now().Add(-time.Minute))
+
+	s.NoError(jobs.PublishScheduledDeployments(context.Background()))
+
+	schedules, err := deploy.NewStore().ScheduledPublishes(context.Background(), deploy.ScheduledPublishFilters{EnvID: env.ID})
+	s.NoError(err)
+	s.Len(schedules, 1)
+	s.Equal(due.ID, schedules[0].ID)
+	s.Equal(deploy.ScheduledPublishStatusFailed, schedules[0].Status)
+	s.Contains(schedules[0].Reason.ValueOrZero(), "Release freeze")
+}
+
+func (s *JobScheduledPublishesSuite) Test_PublishScheduledDeployments_ProtectedEnv() {
+	env := s.MockEnv(nil, map[string]any{
+		"Data": &buildconf.BuildConf{
+			Protection: &buildconf.ProtectionPolicy{RequiredApprovals: 1},
+		},
+	})
+
+	due := s.schedule(env, time.Now().Add(-time.Minute))
+
+	s.NoError(jobs.PublishScheduledDeployments(context.Background()))
+	s.Equal(map[types.ID]string{due.ID: deploy.ScheduledPublishStatusApprovalRequested}, s.status(env.ID))
+
+	var count int
+	s.NoError(s.conn.QueryRow(`SELECT COUNT(*) FROM deployments_published WHERE env_id = $1`, env.ID).Scan(&count))
+	s.Equal(0, count)
+
+	approvals, err := deploy.NewStore().PublishApprovals(context.Background(), deploy.PublishApprovalFilters{
+		EnvID:  env.ID,
+		Status: deploy.ApprovalStatusPending,
+	})
+
+	s.NoError(err)
+	s.Len(approvals, 1)
+	s.Equal(due.Config[0].DeploymentID, approvals[0].Config[0].DeploymentID)
+}
+
+func TestJobScheduledPublishesSuite(t *testing.T) {
+	suite.Run(t, &JobScheduledPublishesSuite{})
+}
@@ -3,13 +3,19 @@ package jobs
 import (
 	"context"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"time"
 
-	"github.com/adhocore/gronx"
 	"github.com/hibiken/asynq"
+	"github.com/redis/go-redis/v9"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/lib/rediscache"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/slog"
 	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
@@ -18,16 +24,54 @@ import (
 )
 
 type FunctionTriggerMessage struct {
-	ID        types.ID      `json:"id,string"`
-	Payload   []byte        `json:"payload"`
-	Headers   shttp.Headers `json:"headers"`
-	Method    string        `json:"method"`
-	URL       string        `json:"url"`
-	NextRunAt utils.Unix    `json:"nextRunAt"`
+	ID                types.ID      `json:"id,string"`
+	EnvID             types.ID      `json:"envId,string"`
+	Payload           []byte        `json:"payload"`
+	Headers           shttp.Headers `json:"headers"`
+	Method            string        `json:"method"`
+	URL               string        `json:"url"`
+	Path              string        `json:"path,omitempty"`
+	Timeout           int           `json:"timeout,omitempty"`
+	MaxRetries        int           `json:"maxRetries,omitempty"`
+	ConcurrencyPolicy string        `json:"concurrencyPolicy,omitempty"`
+	Manual            bool          `json:"manual,omitempty"` // Whether the run was requested by a user
 }
 
-// InvokeDueFunctionTriggers fetches function triggers from the database that are due date. Matching
-// function triggers will be prepared and sent to the queue for execution.
+// NewFunctionTriggerMessage returns the queue message for the given trigger.
+func NewFunctionTriggerMessage(tf *functiontrigger.FunctionTrigger) FunctionTriggerMessage {
+	return FunctionTriggerMessage{
+		URL:               tf.Options.URL,
+		Path:              tf.Options.Path,
+		Payload:           tf.Options.Payload,
+		Headers:           tf.Options.Headers,
+		Method:            tf.Options.Method,
+		ID:                tf.ID,
+		EnvID:             tf.EnvID,
+		Timeout:           tf.Options.Timeout,
+		MaxRetries:        tf.Options.MaxRetries,
+		ConcurrencyPolicy: tf.Options.ConcurrencyPolicy,
+	}
+}
+
+// RunFunctionTriggerNow enqueues an immediate run of the given trigger. Manual
+// runs are not retried and do not change the next scheduled run.
+func RunFunctionTriggerNow(ctx context.Context, tf *functiontrigger.FunctionTrigger) error {
+	msg := NewFunctionTriggerMessage(tf)
+	msg.Manual = true
+	msg.MaxRetries = 0
+
+	_, err := tasks.Enqueue(ctx, tasks.TriggerFunctionHttp, []FunctionTriggerMessage{msg}, nil)
+	return err
+}
+
+// timeout returns the time to wait for the function to respond.
+func (tf FunctionTriggerMessage) timeout() time.Duration {
+	return functiontrigger.Options{Timeout: tf.Timeout}.TimeoutDuration()
+}
+
+// InvokeDueFunctionTriggers fetches function triggers from the database that are due date. Each matching
+// function trigger is sent to the queue as a separate task, so that it can be retried on its own.
+// The next run date is advanced here, regardless of the outcome of the run.
 func InvokeDueFunctionTriggers(ctx context.Context) error {
 	tfs, err := functiontrigger.NewStore().DueTriggers(ctx)
 
@@ -36,38 +80,43 @@ func InvokeDueFunctionTriggers(ctx context.Context) error {
 		return err
 	}
 
-	messages := []FunctionTriggerMessage{}
+	updates := map[types.ID]utils.Unix{}
+
+	var enqueueErr error
 
 	for _, tf := range tfs {
-		nextRunAt, err := gronx.NextTickAfter(tf.Cron, time.Now().UTC(), false)
+		messages := []FunctionTriggerMessage{NewFunctionTriggerMessage(tf)}
+		opts := &tasks.EnqueueOptions{MaxRetry: tf.Options.MaxRetries}
+
+		if _, err := tasks.Enqueue(ctx, tasks.TriggerFunctionHttp, messages, opts); err != nil {
+			slog.Errorf("error occurred while enqueuing task %s", err.Error())
+			enqueueErr = err
+			continue
+		}
+
+		nextRunAt, err := tf.NextRunAfter(time.Now())
 
 		if err != nil {
 			slog.Errorf("error while calculating next tick: %s", err.Error())
+			continue
 		}
 
-		messages = append(messages, FunctionTriggerMessage{
-			URL:       tf.Options.URL,
-			Payload:   tf.Options.Payload,
-			Headers:   tf.Options.Headers,
-			Method:    tf.Options.Method,
-			ID:        tf.ID,
-			NextRunAt: utils.UnixFrom(nextRunAt),
-		})
-	}
-
-	if len(messages) == 0 {
-		return nil
+		updates[tf.ID] = utils.UnixFrom(nextRunAt)
 	}
 
-	if _, err := tasks.Enqueue(ctx, tasks.TriggerFunctionHttp, messages, nil); err != nil {
-		slog.Errorf("error occurred while enqueuing task %s", err.Error())
-		return err
+	if len(updates) > 0 {
+		if err := functiontrigger.NewStore().SetNextRunAt(ctx, updates); err != nil {
+			slog.Errorf("error while inserting function trigger batch updates: %s", err.Error())
+			return err
+		}
 	}
 
-	return nil
+	return enqueueErr
 }
 
-// HandleFunctionTrigger handles triggering a function trigger.
+// HandleFunctionTrigger handles triggering a function trigger. Failed runs are retried by
+// returning an error, until the retry limit of the trigger is reached. Outbound webhooks
+// are dispatched only once the last attempt fails.
 func HandleFunctionTrigger(ctx context.Context, t *asynq.Task) error {
 	tfs := []FunctionTriggerMessage{}
 
@@ -76,60 +125,244 @@ func HandleFunctionTrigger(ctx context.Context, t *asynq.Task) error {
 		return err
 	}
 
+	retried, _ := asynq.GetRetryCount(ctx)
+	taskID, _ := asynq.GetTaskID(ctx)
 	logs := []functiontrigger.TriggerLog{}
-	updates := map[types.ID]utils.Unix{}
 
-	for _, tf := range tfs {
-		res, err := shttp.NewRequestV2(utils.GetString(tf.Method, shttp.MethodGet), tf.URL).
-			Headers(tf.Headers.Make()).
-			Payload(tf.Payload).
-			Do()
+	var retryErr error
 
+	for _, tf := range tfs {
 		request := map[string]any{
 			"url":     tf.URL,
 			"method":  tf.Method,
 			"headers": tf.Headers,
 			"payload": string(tf.Payload),
+			"attempt": retried + 1,
+			"source":  "scheduled",
+		}
+
+		if tf.Manual {
+			request["source"] = "manual"
 		}
 
-		var response map[string]any
+		if tf.Path != "" {
+			request["path"] = tf.Path
+		}
 
-		if res != nil {
-			response = map[string]any{
-				"code": res.StatusCode,
-				"body": readBody(res.Response),
-			}
-		} else if err != nil {
-			response = map[string]any{
-				"error": err.Error(),
-			}
+		runCtx, acquired, release := lockFunctionTriggerRun(ctx, tf, taskID)
+
+		if !acquired {
+			logs = append(logs, functiontrigger.TriggerLog{
+				TriggerID: tf.ID,
+				Request:   request,
+				Response:  map[string]any{"skipped": "Previous run is still in progress"},
+			})
+
+			continue
 		}
 
+		response, reason := runFunctionTrigger(runCtx, tf, retried+1)
+		release()
+
 		logs = append(logs, functiontrigger.TriggerLog{
 			TriggerID: tf.ID,
 			Request:   request,
 			Response:  response,
 		})
 
-		if err != nil {
-			slog.Errorf("trigger function request failed %v", err)
+		// The run was cancelled, or it succeeded.
+		if runCtx.Err() != nil || reason == "" {
 			continue
 		}
 
-		updates[tf.ID] = tf.NextRunAt
-	}
+		slog.Errorf("trigger function request failed: %s", reason)
+
+		// Older tasks contain multiple triggers, which cannot be retried individually.
+		if len(tfs) == 1 && retried < tf.MaxRetries {
+			retryErr = errors.New(reason)
+			continue
+		}
 
-	store := functiontrigger.NewStore()
+		triggerFunctionFailedWebhooks(ctx, tf, reason)
+	}
 
-	if err := store.InsertLogs(ctx, logs); err != nil {
+	if err := functiontrigger.NewStore().InsertLogs(context.WithoutCancel(ctx), logs); err != nil {
 		slog.Errorf("error while inserting function trigger logs: %s", err.Error())
 	}
 
-	if err := store.SetNextRunAt(ctx, updates); err != nil {
-		slog.Errorf("error while inserting function trigger batch updates: %s", err.Error())
+	return retryErr
+}
+
+// runFunctionTrigger runs the trigger and returns the response to log. When the
+// run fails, the reason is returned as well. The run is abandoned as soon as
+// the context is cancelled or the timeout is reached.
+func runFunctionTrigger(ctx context.Context, tf FunctionTriggerMessage, attempt int) (map[string]any, string) {
+	type result struct {
+		response map[string]any
+		reason   string
+	}
+
+	done := make(chan result, 1)
+
+	go func() {
+		r := result{}
+
+		if tf.Path != "" {
+			r.response, r.reason = invokeFunctionTrigger(ctx, tf, attempt)
+		} else {
+			r.response, r.reason = requestFunctionTrigger(tf)
+		}
+
+		done <- r
+	}()
+
+	timer := time.NewTimer(tf.timeout())
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		if errors.Is(context.Cause(ctx), errReplacedByNewerRun) {
+			return map[string]any{"error": "Run was cancelled by a newer run"}, ""
+		}
+
+		return map[string]any{"error": "Run was cancelled"}, ""
+	case <-timer.C:
+		reason := fmt.Sprintf("Function did not respond within %s", tf.timeout())
+		return map[string]any{"error": reason}, reason
+	case r := <-done:
+		return r.response, r.reason
+	}
+}
+
+// requestFunctionTrigger sends an http request to the url of the trigger.
+func requestFunctionTrigger(tf FunctionTriggerMessage) (map[string]any, string) {
+	res, err := shttp.NewRequestV2(utils.GetString(tf.Method, shttp.MethodGet), tf.URL).
+		Headers(tf.Headers.Make()).
+		Payload(tf.Payload).
+		WithTimeout(tf.timeout()).
+		Do()
+
+	var netErr net.Error
+
+	if err != nil && errors.As(err, &netErr) && netErr.Timeout() {
+		reason := fmt.Sprintf("Function did not respond within %s", tf.timeout())
+		return map[string]any{"error": reason}, reason
+	}
+
+	if err != nil {
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	if res == nil {
+		return nil, ""
+	}
+
+	response := map[string]any{
+		"code": res.StatusCode,
+		"body": readBody(res.Response),
+	}
+
+	if res.StatusCode >= http.StatusBadRequest {
+		return response, fmt.Sprintf("Function responded with status %d", res.StatusCode)
+	}
+
+	return response, ""
+}
+
+// releaseRunScript deletes the run key only if it still belongs to the given task.
+var releaseRunScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// errReplacedByNewerRun is the cause of the cancellation of a run that is replaced by a newer run.
+var errReplacedByNewerRun = errors.New("run was replaced by a newer run")
+
+// lockFunctionTriggerRun applies the concurrency policy of the trigger. It returns false when
+// the run has to be skipped, and a function that releases the lock once the run is over.
+// The returned context is cancelled with errReplacedByNewerRun when a newer run replaces
+// this one. When Redis is not reachable, runs are allowed to overlap.
+func lockFunctionTriggerRun(ctx context.Context, tf FunctionTriggerMessage, taskID string) (context.Context, bool, func()) {
+	noop := func() {}
+	policy := tf.ConcurrencyPolicy
+
+	if taskID == "" || policy == "" || policy == functiontrigger.ConcurrencyAllow {
+		return ctx, true, noop
+	}
+
+	client := rediscache.Client()
+	key := fmt.Sprintf("function-trigger:%s:run", tf.ID.String())
+	ttl := tf.timeout() + time.Minute
+
+	switch policy {
+	case functiontrigger.ConcurrencyForbid:
+		ok, err := client.SetNX(ctx, key, taskID, ttl).Result()
+
+		if err != nil {
+			slog.Errorf("cannot acquire function trigger lock: %v", err)
+			return ctx, true, noop
+		}
+
+		// A retry of the same task may find its own lock.
+		if !ok && client.Get(ctx, key).Val() != taskID {
+			return ctx, false, noop
+		}
+	case functiontrigger.ConcurrencyReplace:
+		prev, err := client.SetArgs(ctx, key, taskID, redis.SetArgs{Get: true, TTL: ttl}).Result()
+
+		if err != nil && !errors.Is(err, redis.Nil) {
+			slog.Errorf("cannot acquire function trigger lock: %v", err)
+			return ctx, true, noop
+		}
+
+		if prev != "" && prev != taskID {
+			if err := tasks.Inspector().CancelProcessing(prev); err != nil {
+				slog.Errorf("cannot cancel previous function trigger run: %v", err)
+			}
+		}
+	}
+
+	// The newer run takes over the key before it cancels this run, so the owner of
+	// the key tells whether this run was replaced once the task context is cancelled.
+	runCtx, cancel := context.WithCancelCause(context.WithoutCancel(ctx))
+	stop := context.AfterFunc(ctx, func() {
+		cause := context.Cause(ctx)
+
+		if policy == functiontrigger.ConcurrencyReplace && client.Get(context.Background(), key).Val() != taskID {
+			cause = errReplacedByNewerRun
+		}
+
+		cancel(cause)
+	})
+
+	return runCtx, true, func() {
+		stop()
+		cancel(nil)
+
+		if err := releaseRunScript.Run(context.Background(), client, []string{key}, taskID).Err(); err != nil {
+			slog.Errorf("cannot release function trigger lock: %v", err)
+		}
+	}
+}
+
+// triggerFunctionFailedWebhooks dispatches the outbound webhooks that are
+// subscribed to the function trigger failed event.
+func triggerFunctionFailedWebhooks(ctx context.Context, tf FunctionTriggerMessage, reason string) {
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, tf.EnvID)
+
+	if err != nil || env == nil {
+		slog.Errorf("cannot fetch environment for function trigger webhooks: %v", err)
+		return
 	}
 
-	return nil
+	app.TriggerEvent(ctx, app.TriggerOnFunctionTriggerFailed, app.OutboundWebhookSettings{
+		AppID:                env.AppID,
+		EnvironmentName:      env.Name,
+		FunctionTriggerID:    tf.ID,
+		FunctionTriggerError: reason,
+	})
 }
 
 func readBody(res *http.Response) string {
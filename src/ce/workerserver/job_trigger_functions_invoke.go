@@ -0,0 +1,233 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/admin"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/appconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/buildconf"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/applog"
+	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/slog"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+// functionRequest is a request that is sent internally to a function
+// of the environment's published deployment.
+type functionRequest struct {
+	Path    string
+	Method  string
+	Headers http.Header
+	Payload []byte
+	Context map[string]any // Merged into the context that the function receives
+}
+
+// invokeFunctionTrigger invokes the function of the environment's published deployment
+// that serves the trigger path. The function receives the trigger information in
+// `context.trigger`, signed with the signing secret of the trigger.
+func invokeFunctionTrigger(ctx context.Context, tf FunctionTriggerMessage, attempt int) (map[string]any, string) {
+	store := functiontrigger.NewStore()
+	trigger, err := store.ByID(ctx, tf.ID)
+
+	if err != nil {
+		slog.Errorf("cannot fetch function trigger for function invocation: %v", err)
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	if trigger == nil {
+		reason := "Trigger does not exist"
+		return map[string]any{"error": reason}, reason
+	}
+
+	if err := store.EnsureSigningSecret(ctx, trigger); err != nil {
+		slog.Errorf("cannot set function trigger signing secret: %v", err)
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	ts := time.Now().Unix()
+
+	return invokeFunction(ctx, tf.EnvID, functionRequest{
+		Path:    tf.Path,
+		Method:  tf.Method,
+		Headers: tf.Headers.Make(),
+		Payload: tf.Payload,
+		Context: map[string]any{
+			"trigger": map[string]any{
+				"id":        tf.ID.String(),
+				"attempt":   attempt,
+				"timestamp": ts,
+				"signature": trigger.Signature(ts, tf.Payload),
+			},
+		},
+	})
+}
+
+// invokeFunction invokes the function of the environment's published deployment that
+// serves the request path, and returns the response to log. When the invocation fails,
+// the reason is returned as well. Function logs are stored next to the runtime logs
+// of the environment.
+func invokeFunction(ctx context.Context, envID types.ID, fn functionRequest) (map[string]any, string) {
+	cnf, hostName, err := publishedConfig(ctx, envID)
+
+	if err != nil {
+		slog.Errorf("cannot fetch published config for function invocation: %v", err)
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	if cnf == nil {
+		reason := "Environment does not have a published deployment"
+		return map[string]any{"error": reason}, reason
+	}
+
+	// Same routing rules as the hosting server.
+	arn := utils.GetString(cnf.FunctionLocation, cnf.APILocation)
+
+	if cnf.APILocation != "" && cnf.APIPathPrefix != "" && strings.HasPrefix(fn.Path, cnf.APIPathPrefix) {
+		arn = cnf.APILocation
+	}
+
+	if arn == "" {
+		reason := "Published deployment does not have any functions"
+		return map[string]any{"error": reason}, reason
+	}
+
+	u, err := url.Parse(fn.Path)
+
+	if err != nil {
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	u.Scheme = "https"
+	u.Host = hostName
+
+	masker := utils.NewSecretMasker(cnf.EnvVariables)
+
+	for _, name := range cnf.SecretVars {
+		masker.AddSecret(cnf.EnvVariables[name])
+	}
+
+	fnContext := map[string]any{
+		"apiPrefix": cnf.APIPathPrefix,
+		"jobs":      backgroundjob.FunctionContext(envID),
+		"kv":        kvstore.FunctionContext(envID),
+	}
+
+	for k, v := range fn.Context {
+		fnContext[k] = v
+	}
+
+	result, err := integrations.Client().Invoke(integrations.InvokeArgs{
+		URL:          u,
+		ARN:          arn,
+		Body:         io.NopCloser(bytes.NewReader(fn.Payload)),
+		Method:       utils.GetString(fn.Method, shttp.MethodGet),
+		Headers:      fn.Headers,
+		HostName:     hostName,
+		AppID:        cnf.AppID,
+		EnvID:        cnf.EnvID,
+		DeploymentID: cnf.DeploymentID,
+		Command:      cnf.ServerCmd,
+		EnvVariables: cnf.EnvVariables,
+		IsPublished:  true,
+		CaptureLogs:  true,
+		Context:      fnContext,
+	})
+
+	if result != nil && len(result.Logs) > 0 {
+		logs := []*applog.Log{}
+
+		for _, log := range result.Logs {
+			logs = append(logs, &applog.Log{
+				AppID:         cnf.AppID,
+				DeploymentID:  cnf.DeploymentID,
+				EnvironmentID: cnf.EnvID,
+				HostName:      hostName,
+				Timestamp:     log.Timestamp,
+				Label:         log.Level,
+				Data:          masker.MaskString(log.Message),
+			})
+		}
+
+		if err := applog.NewStore().InsertLogs(context.WithoutCancel(ctx), logs); err != nil {
+			slog.Errorf("error while inserting function runtime logs: %v", err)
+		}
+	}
+
+	if err != nil {
+		return map[string]any{"error": err.Error()}, err.Error()
+	}
+
+	if result == nil {
+		return map[string]any{"code": http.StatusNoContent}, ""
+	}
+
+	if result.ErrorMessage != "" && result.StatusCode == 0 {
+		result.StatusCode = http.StatusInternalServerError
+		result.Body = []byte(result.ErrorMessage)
+	}
+
+	response := map[string]any{
+		"code": result.StatusCode,
+		"body": masker.MaskString(string(result.Body)),
+	}
+
+	if result.StatusCode >= http.StatusBadRequest {
+		return response, fmt.Sprintf("Function responded with status %d", result.StatusCode)
+	}
+
+	return response, ""
+}
+
+// publishedConfig returns the hosting configuration of the deployment that receives the
+// largest share of the environment's traffic, along with the host name of the environment.
+func publishedConfig(ctx context.Context, envID types.ID) (*appconf.Config, string, error) {
+	env, err := buildconf.NewStore().EnvironmentByID(ctx, envID)
+
+	if err != nil || env == nil {
+		return nil, "", err
+	}
+
+	appl, err := app.NewStore().AppByID(ctx, env.AppID)
+
+	if err != nil || appl == nil {
+		return nil, "", err
+	}
+
+	configs, err := appconf.NewStore().Configs(ctx, appconf.ConfigFilters{
+		DisplayName: appl.DisplayName,
+		EnvName:     env.Name,
+	})
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	var cnf *appconf.Config
+
+	for _, c := range configs {
+		if cnf == nil || c.Percentage > cnf.Percentage {
+			cnf = c
+		}
+	}
+
+	hostName := appl.DisplayName
+
+	if preview, err := url.Parse(admin.MustConfig().PreviewURL(appl.DisplayName, env.Name)); err == nil && preview.Host != "" {
+		hostName = preview.Host
+	}
+
+	return cnf, hostName, nil
+}
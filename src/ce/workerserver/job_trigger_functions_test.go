@@ -10,18 +10,21 @@ import (
 	"testing"
 	"time"
 
-	"github.com/adhocore/gronx"
 	"github.com/hibiken/asynq"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deploy"
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/functiontrigger"
+	"github.com/stormkit-io/stormkit-io/src/ce/api/applog"
 	jobs "github.com/stormkit-io/stormkit-io/src/ce/workerserver"
 	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
 	"github.com/stormkit-io/stormkit-io/src/lib/factory"
+	"github.com/stormkit-io/stormkit-io/src/lib/integrations"
 	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
 	"github.com/stormkit-io/stormkit-io/src/lib/tasks"
 	"github.com/stormkit-io/stormkit-io/src/lib/utils"
 	"github.com/stormkit-io/stormkit-io/src/mocks"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/suite"
+	"gopkg.in/guregu/null.v3"
 )
 
 type JobTriggerFunctionsSuite struct {
@@ -51,7 +54,7 @@ func (s *JobTriggerFunctionsSuite) AfterTest(_, _ string) {
 	shttp.DefaultRequest = nil
 }
 
-func (s *JobTriggerFunctionsSuite) generateMockMessage() []byte {
+func (s *JobTriggerFunctionsSuite) mockTriggers() []*factory.MockFunctionTrigger {
 	currentTime := time.Now().UTC()
 	tenMinutesAgo := currentTime.Add(-time.Minute * 10)
 	twoMinutesAgo := currentTime.Add(-time.Minute * 2)
@@ -74,9 +77,11 @@ func (s *JobTriggerFunctionsSuite) generateMockMessage() []byte {
 	tf2 := s.MockTriggerFunction(nil, map[string]any{
 		"NextRunAt": t2,
 		"Options": functiontrigger.Options{
-			URL:     "https://example-2.org",
-			Method:  "PATCH",
-			Payload: []byte("Hello World!"),
+			URL:        "https://example-2.org",
+			Method:     "PATCH",
+			Payload:    []byte("Hello World!"),
+			Timeout:    30,
+			MaxRetries: 2,
 			Headers: shttp.Headers{
 				"content-type": "text/html",
 			},
@@ -86,21 +91,29 @@ func (s *JobTriggerFunctionsSuite) generateMockMessage() []byte {
 	// This should not be included
 	s.MockTriggerFunction(nil, map[string]any{"NextRunAt": t3})
 
-	messages := []jobs.FunctionTriggerMessage{}
-	triggers := []*factory.MockFunctionTrigger{tf1, tf2}
+	return []*factory.MockFunctionTrigger{tf1, tf2}
+}
 
-	for _, tf := range triggers {
-		nextRunAt, err := gronx.NextTickAfter(tf.Cron, time.Now().UTC(), false)
-		s.NoError(err)
+func (s *JobTriggerFunctionsSuite) message(tf *factory.MockFunctionTrigger) jobs.FunctionTriggerMessage {
+	return jobs.FunctionTriggerMessage{
+		ID:                tf.ID,
+		EnvID:             tf.EnvID,
+		URL:               tf.Options.URL,
+		Payload:           tf.Options.Payload,
+		Headers:           tf.Options.Headers,
+		Method:            tf.Options.Method,
+		Path:              tf.Options.Path,
+		Timeout:           tf.Options.Timeout,
+		MaxRetries:        tf.Options.MaxRetries,
+		ConcurrencyPolicy: tf.Options.ConcurrencyPolicy,
+	}
+}
 
-		messages = append(messages, jobs.FunctionTriggerMessage{
-			ID:        tf.ID,
-			URL:       tf.Options.URL,
-			Payload:   tf.Options.Payload,
-			Headers:   tf.Options.Headers,
-			Method:    tf.Options.Method,
-			NextRunAt: utils.UnixFrom(nextRunAt),
-		})
+func (s *JobTriggerFunctionsSuite) generateMockMessage() []byte {
+	messages := []jobs.FunctionTriggerMessage{}
+
+	for _, tf := range s.mockTriggers() {
+		messages = append(messages, s.message(tf))
 	}
 
 	payload, err := json.Marshal(messages)
@@ -109,15 +122,43 @@ func (s *JobTriggerFunctionsSuite) generateMockMessage() []byte {
 }
 
 func (s *JobTriggerFunctionsSuite) Test_CreatingIndividualTasks() {
-	payload := s.generateMockMessage()
+	triggers := s.mockTriggers()
 
 	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil)
 
 	s.NoError(jobs.InvokeDueFunctionTriggers(context.Background()))
 
-	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
-		return s.Equal(task.Payload(), payload) && s.Equal(task.Type(), tasks.TriggerFunctionHttp)
-	}))
+	s.mockClient.AssertNumberOfCalls(s.T(), "Enqueue", 2)
+
+	for _, tf := range triggers {
+		payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+		s.NoError(err)
+
+		s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+			return string(task.Payload()) == string(payload) && task.Type() == tasks.TriggerFunctionHttp
+		}))
+
+		nextRunAt, err := tf.NextRunAfter(time.Now())
+		s.NoError(err)
+
+		trigger, err := functiontrigger.NewStore().ByID(context.Background(), tf.ID)
+		s.NoError(err)
+		expected := utils.UnixFrom(nextRunAt)
+		s.Equal(expected.Unix(), trigger.NextRunAt.Unix())
+	}
+}
+
+func (s *JobTriggerFunctionsSuite) Test_CreatingIndividualTasks_EnqueueFails() {
+	triggers := s.mockTriggers()
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, errors.New("redis is down"))
+
+	s.Error(jobs.InvokeDueFunctionTriggers(context.Background()))
+
+	// The next run date is not advanced, so that the trigger is picked up again.
+	trigger, err := functiontrigger.NewStore().ByID(context.Background(), triggers[0].ID)
+	s.NoError(err)
+	s.Equal(triggers[0].NextRunAt.Unix(), trigger.NextRunAt.Unix())
 }
 
 func (s *JobTriggerFunctionsSuite) Test_ConsumingTasks() {
@@ -128,6 +169,7 @@ func (s *JobTriggerFunctionsSuite) Test_ConsumingTasks() {
 	s.mockRequest.On("Method", "GET").Return(s.mockRequest).Once()
 	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "application/json"})).Return(s.mockRequest).Once()
 	s.mockRequest.On("Payload", []byte(nil)).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 10*time.Second).Return(s.mockRequest).Once()
 	s.mockRequest.On("Do").Return(&shttp.HTTPResponse{
 		Response: &http.Response{
 			StatusCode: http.StatusOK,
@@ -141,6 +183,7 @@ func (s *JobTriggerFunctionsSuite) Test_ConsumingTasks() {
 	s.mockRequest.On("Method", "PATCH").Return(s.mockRequest).Once()
 	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "text/html"})).Return(s.mockRequest).Once()
 	s.mockRequest.On("Payload", []byte("Hello World!")).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 30*time.Second).Return(s.mockRequest).Once()
 	s.mockRequest.On("Do").Return(&shttp.HTTPResponse{
 		Response: &http.Response{
 			StatusCode: http.StatusOK,
@@ -162,6 +205,7 @@ func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_PartialFailure() {
 	s.mockRequest.On("Method", "GET").Return(s.mockRequest).Once()
 	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "application/json"})).Return(s.mockRequest).Once()
 	s.mockRequest.On("Payload", []byte(nil)).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 10*time.Second).Return(s.mockRequest).Once()
 	s.mockRequest.On("Do").Return(&shttp.HTTPResponse{
 		Response: &http.Response{
 			StatusCode: http.StatusOK,
@@ -175,6 +219,7 @@ func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_PartialFailure() {
 	s.mockRequest.On("Method", "PATCH").Return(s.mockRequest).Once()
 	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "text/html"})).Return(s.mockRequest).Once()
 	s.mockRequest.On("Payload", []byte("Hello World!")).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 30*time.Second).Return(s.mockRequest).Once()
 	s.mockRequest.On("Do").Return(nil, errors.New("boom")).Once()
 
 	s.NoError(jobs.HandleFunctionTrigger(context.Background(), task))
@@ -183,6 +228,171 @@ func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_PartialFailure() {
 	// Ensures code handles mixed success/failure gracefully.
 }
 
+func (s *JobTriggerFunctionsSuite) Test_RunFunctionTriggerNow() {
+	tf := s.mockTriggers()[1]
+	msg := s.message(tf)
+	msg.Manual = true
+	msg.MaxRetries = 0
+
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{msg})
+	s.NoError(err)
+
+	s.mockClient.On("Enqueue", mock.Anything).Return(nil, nil).Once()
+
+	s.NoError(jobs.RunFunctionTriggerNow(context.Background(), tf.FunctionTrigger))
+
+	s.mockClient.AssertCalled(s.T(), "Enqueue", mock.MatchedBy(func(task *asynq.Task) bool {
+		return string(task.Payload()) == string(payload) && task.Type() == tasks.TriggerFunctionHttp
+	}))
+
+	// The next run is not changed
+	trigger, err := functiontrigger.NewStore().ByID(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Equal(tf.NextRunAt.Unix(), trigger.NextRunAt.Unix())
+}
+
+func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_Retry() {
+	tf := s.mockTriggers()[1]
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+	s.NoError(err)
+
+	s.mockRequest.On("URL", "https://example-2.org").Return(s.mockRequest).Once()
+	s.mockRequest.On("Method", "PATCH").Return(s.mockRequest).Once()
+	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "text/html"})).Return(s.mockRequest).Once()
+	s.mockRequest.On("Payload", []byte("Hello World!")).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 30*time.Second).Return(s.mockRequest).Once()
+	s.mockRequest.On("Do").Return(&shttp.HTTPResponse{
+		Response: &http.Response{
+			StatusCode: http.StatusBadGateway,
+			Body:       io.NopCloser(strings.NewReader("bad gateway")),
+			Header:     make(http.Header),
+		},
+	}, nil).Once()
+
+	err = jobs.HandleFunctionTrigger(context.Background(), asynq.NewTask("", payload))
+	s.EqualError(err, "Function responded with status 502")
+
+	logs, err := functiontrigger.NewStore().Logs(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Len(logs, 1)
+	s.Equal(float64(1), logs[0].Request["attempt"])
+	s.Equal("scheduled", logs[0].Request["source"])
+}
+
+func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_NoRetries() {
+	tf := s.mockTriggers()[0]
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+	s.NoError(err)
+
+	s.mockRequest.On("URL", "https://example-1.org").Return(s.mockRequest).Once()
+	s.mockRequest.On("Method", "GET").Return(s.mockRequest).Once()
+	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "application/json"})).Return(s.mockRequest).Once()
+	s.mockRequest.On("Payload", []byte(nil)).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 10*time.Second).Return(s.mockRequest).Once()
+	s.mockRequest.On("Do").Return(nil, errors.New("boom")).Once()
+
+	s.NoError(jobs.HandleFunctionTrigger(context.Background(), asynq.NewTask("", payload)))
+}
+
+func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_Cancelled() {
+	tf := s.mockTriggers()[0]
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+	s.NoError(err)
+
+	s.mockRequest.On("URL", "https://example-1.org").Return(s.mockRequest).Once()
+	s.mockRequest.On("Method", "GET").Return(s.mockRequest).Once()
+	s.mockRequest.On("Headers", shttp.HeadersFromMap(map[string]string{"content-type": "application/json"})).Return(s.mockRequest).Once()
+	s.mockRequest.On("Payload", []byte(nil)).Return(s.mockRequest).Once()
+	s.mockRequest.On("WithTimeout", 10*time.Second).Return(s.mockRequest).Once()
+	s.mockRequest.On("Do").Return(nil, nil).After(time.Second).Once()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	// A cancellation that is not caused by a newer run is not reported as such.
+	s.NoError(jobs.HandleFunctionTrigger(ctx, asynq.NewTask("", payload)))
+
+	logs, err := functiontrigger.NewStore().Logs(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Len(logs, 1)
+	s.Equal("Run was cancelled", logs[0].Response["error"])
+}
+
+func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_InvokesFunction() {
+	env := s.MockEnv(nil)
+	depl := s.MockDeployment(env, map[string]any{
+		"ExitCode":         null.IntFrom(0),
+		"FunctionLocation": null.StringFrom("aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1"),
+		"Published":        []deploy.PublishedInfo{{EnvID: env.ID, Percentage: 100}},
+	})
+
+	tf := s.MockTriggerFunction(env, map[string]any{
+		"Options": functiontrigger.Options{
+			Method:  "POST",
+			Path:    "/cron/cleanup?dry=true",
+			Payload: []byte(`{"hello":"world"}`),
+		},
+	})
+
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+	s.NoError(err)
+
+	mockIntegrations := &mocks.ClientInterface{}
+	integrations.SetDefaultClient(mockIntegrations)
+	defer integrations.SetDefaultClient(nil)
+
+	mockIntegrations.On("Invoke", mock.MatchedBy(func(args integrations.InvokeArgs) bool {
+		trigger := args.Context["trigger"].(map[string]any)
+		ts := trigger["timestamp"].(int64)
+		signature := tf.Signature(ts, []byte(`{"hello":"world"}`))
+
+		return args.ARN == "aws:arn:aws:lambda:eu-central-1:1:function:my-fn/1" &&
+			args.Method == "POST" &&
+			args.URL.Path == "/cron/cleanup" &&
+			args.URL.Query().Get("dry") == "true" &&
+			args.DeploymentID == depl.ID &&
+			args.CaptureLogs &&
+			trigger["id"] == tf.ID.String() &&
+			trigger["attempt"] == 1 &&
+			trigger["signature"] == signature
+	})).Return(&integrations.InvokeResult{
+		StatusCode: http.StatusOK,
+		Body:       []byte("done"),
+		Logs:       []integrations.Log{{Message: "cleaned up", Level: "info"}},
+	}, nil).Once()
+
+	s.NoError(jobs.HandleFunctionTrigger(context.Background(), asynq.NewTask("", payload)))
+	mockIntegrations.AssertExpectations(s.T())
+
+	logs, err := functiontrigger.NewStore().Logs(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Len(logs, 1)
+	s.Equal("/cron/cleanup?dry=true", logs[0].Request["path"])
+	s.Equal(float64(http.StatusOK), logs[0].Response["code"])
+	s.Equal("done", logs[0].Response["body"])
+
+	runtimeLogs, err := applog.NewStore().Logs(context.Background(), &applog.LogQuery{AppID: env.AppID, DeploymentID: depl.ID})
+	s.NoError(err)
+	s.Len(runtimeLogs, 1)
+	s.Equal("cleaned up", runtimeLogs[0].Data)
+}
+
+func (s *JobTriggerFunctionsSuite) Test_HandleFunctionTrigger_NotPublished() {
+	tf := s.MockTriggerFunction(nil, map[string]any{
+		"Options": functiontrigger.Options{Path: "/cron"},
+	})
+
+	payload, err := json.Marshal([]jobs.FunctionTriggerMessage{s.message(tf)})
+	s.NoError(err)
+
+	s.NoError(jobs.HandleFunctionTrigger(context.Background(), asynq.NewTask("", payload)))
+
+	logs, err := functiontrigger.NewStore().Logs(context.Background(), tf.ID)
+	s.NoError(err)
+	s.Len(logs, 1)
+	s.Equal("Environment does not have a published deployment", logs[0].Response["error"])
+}
+
 func TestJobTriggerFunctionSuite(t *testing.T) {
 	suite.Run(t, &JobTriggerFunctionsSuite{})
 }
@@ -77,6 +77,10 @@ func Server(serverOpts ...ServerOpts) (*asynq.Server, *asynq.ServeMux) {
 
 	mux.HandleFunc(tasks.DeploymentStart, HandleDeploymentStart)
 	mux.HandleFunc(tasks.TriggerFunctionHttp, HandleFunctionTrigger)
+	mux.HandleFunc(tasks.OutboundWebhookDelivery, HandleOutboundWebhookDelivery)
+	mux.HandleFunc(tasks.NotificationDelivery, HandleNotificationDelivery)
+	mux.HandleFunc(tasks.EmailNotification, HandleEmailNotification)
+	mux.HandleFunc(tasks.BackgroundJobInvoke, HandleBackgroundJob)
 
 	priority := 10
 	concurrency := 10
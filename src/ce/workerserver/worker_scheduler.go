@@ -78,7 +78,17 @@ func (s *Scheduler) RegisterMasterTasks(ctx context.Context) {
 	daily := gocron.DailyJob(1, gocron.NewAtTimes(gocron.NewAtTime(0, 0, 0)))
 
 	tasks := []TaskDefinition{
+		{Handler: DispatchBuildQueue, Def: dj(EVERY_5_SECOND), Opt: immediate},
+		{Handler: RemoveOldQueueEntries, Def: dj(EVERY_HOUR), Opt: immediate},
+		{Handler: RequeueLostAgentJobs, Def: dj(EVERY_MINUTE), Opt: immediate},
+		{Handler: RemoveOldAgentJobs, Def: dj(EVERY_HOUR), Opt: immediate},
+		{Handler: RemoveOldDeployTriggerInvocations, Def: dj(EVERY_6_HOURS), Opt: immediate},
+		{Handler: RemoveOldOutboundWebhookDeliveries, Def: dj(EVERY_6_HOURS), Opt: immediate},
+		{Handler: RemoveOldBackgroundJobs, Def: dj(EVERY_6_HOURS), Opt: immediate},
+		{Handler: RemoveExpiredKVEntries, Def: dj(EVERY_HOUR), Opt: immediate},
 		{Handler: InvokeDueFunctionTriggers, Def: dj(EVERY_MINUTE), Opt: immediate},
+		{Handler: AdvanceRollouts, Def: dj(EVERY_MINUTE), Opt: immediate},
+		{Handler: PublishScheduledDeployments, Def: dj(EVERY_MINUTE), Opt: immediate},
 		{Handler: RemoveOldLogs, Def: dj(EVERY_HOUR * 2), Opt: immediate},
 		{Handler: RemoveStaleEnvironments, Def: dj(EVERY_6_HOURS), Opt: immediate},
 		{Handler: RemoveDeploymentArtifacts, Def: dj(EVERY_6_HOURS), Opt: immediate},
@@ -13,7 +13,6 @@ type statement struct {
 	markDeploymentArtifactsDeleted  string
 	markStaleAppsAndEnvsSoftDeleted string
 	deleteStaleEnvironments         string
-	selectOldOrDeletedDeployments   string
 	removeOldLogs                   string
 	syncAnalyticsVisitors           string
 	syncAnalyticsReferrers          string
@@ -54,25 +53,6 @@ var stmt = &statement{
 			LIMIT 50
 	);`, tableEnvs, tableEnvs, tableDeploys),
 
-	selectOldOrDeletedDeployments: `
-		SELECT
-			d.deployment_id, d.app_id, d.storage_location,
-			d.function_location, d.api_location
-		FROM
-			deployments d
-		LEFT JOIN
-			deployments_published dp ON dp.deployment_id = d.deployment_id
-		WHERE
-			(
-				d.created_at < NOW() - INTERVAL '{{ .days }} days' OR 
-				d.deleted_at IS NOT NULL
-			)
-			AND
-			dp.deployment_id IS NULL AND
-			d.artifacts_deleted != TRUE
-		LIMIT $1;
-	`,
-
 	markDeploymentArtifactsDeleted: `
 		UPDATE
 			deployments
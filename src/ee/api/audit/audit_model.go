@@ -15,19 +15,25 @@ import (
 )
 
 const (
-	CreateAction string = "CREATE"
-	UpdateAction string = "UPDATE"
-	DeleteAction string = "DELETE"
+	CreateAction   string = "CREATE"
+	UpdateAction   string = "UPDATE"
+	DeleteAction   string = "DELETE"
+	OverrideAction string = "OVERRIDE"
+	ApproveAction  string = "APPROVE"
+	RejectAction   string = "REJECT"
 )
 
 const (
-	TypeUser     string = "USER"
-	TypeApp      string = "APP"
-	TypeEnv      string = "ENV"
-	TypeTeam     string = "TEAM"
-	TypeDomain   string = "DOMAIN"
-	TypeSnippet  string = "SNIPPET"
-	TypeAuthWall string = "AUTHWALL"
+	TypeUser            string = "USER"
+	TypeApp             string = "APP"
+	TypeEnv             string = "ENV"
+	TypeTeam            string = "TEAM"
+	TypeDomain          string = "DOMAIN"
+	TypeSnippet         string = "SNIPPET"
+	TypeAuthWall        string = "AUTHWALL"
+	TypeFreezeWindow    string = "FREEZE_WINDOW"
+	TypePublishApproval string = "PUBLISH_APPROVAL"
+	TypeSecret          string = "SECRET"
 )
 
 type DiffFields struct {
@@ -55,6 +61,13 @@ type DiffFields struct {
 	AuthWallCreateLoginEmail string                 `json:"authWallCreateLoginEmail,omitempty"`
 	AuthWallCreateLoginID    string                 `json:"authWallCreateLoginId,omitempty"`
 	AuthWallDeleteLoginIDs   string                 `json:"authWallDeleteLoginIds,omitempty"`
+	FreezeWindowName         string                 `json:"freezeWindowName,omitempty"`
+	PublishedDeploymentIDs   []string               `json:"publishedDeploymentIds,omitempty"`
+	ApprovalID               string                 `json:"approvalId,omitempty"`
+	ApprovalComment          string                 `json:"approvalComment,omitempty"`
+	SecretName               string                 `json:"secretName,omitempty"`
+	SecretScope              string                 `json:"secretScope,omitempty"`
+	SecretVersion            int                    `json:"secretVersion,omitempty"`
 }
 
 type Diff struct {
@@ -34,6 +34,7 @@ var stmt = struct {
 	selectDefaultTeam     string
 	selectTeamMember      string
 	selectTeamMembers     string
+	selectMemberByAccount string
 	isTeamMember          string
 	markTeamAsSoftDeleted string
 	removeUserFromTeam    string
@@ -117,6 +118,24 @@ var stmt = struct {
 		LIMIT 100;
 	`, tableUserEmails, tableTeamMembers, tableUsers),
 
+	selectMemberByAccount: fmt.Sprintf(`
+		SELECT
+			u.user_id, u.first_name, u.last_name, u.display_name,
+			(SELECT ue.email FROM %s ue WHERE ue.user_id = u.user_id AND ue.is_primary IS TRUE),
+			tm.member_id, tm.member_role, tm.membership_status
+		FROM %s tm
+		LEFT JOIN %s u ON u.user_id = tm.user_id
+		LEFT JOIN user_access_tokens uat ON uat.user_id = tm.user_id
+		WHERE
+			tm.team_id = $1 AND
+			tm.membership_status IS TRUE AND
+			u.deleted_at IS NULL AND
+			uat.provider::text = $2 AND
+			uat.account_id = $3
+		ORDER BY tm.member_id ASC
+		LIMIT 1;
+	`, tableUserEmails, tableTeamMembers, tableUsers),
+
 	addUserToTeam: fmt.Sprintf(`
 		INSERT INTO %s (team_id, user_id, member_role, membership_status)
 		VALUES ($1, $2, $3, $4)
@@ -322,6 +341,40 @@ func (s *Store) TeamMember(ctx context.Context, memberID types.ID) (*Member, err
 	return m, nil
 }
 
+// TeamMemberByAccount returns the member of the team whose connected git provider
+// account matches the given account id. Logins can be changed by their owners, so
+// members are never matched by login. It returns nil when no member is found, or
+// when the account id is empty.
+func (s *Store) TeamMemberByAccount(ctx context.Context, teamID types.ID, provider, accountID string) (*Member, error) {
+	if accountID == "" {
+		return nil, nil
+	}
+
+	m := &Member{}
+
+	row, err := s.QueryRow(ctx, stmt.selectMemberByAccount, teamID, provider, accountID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = row.Scan(
+		&m.UserID, &m.FirstName, &m.LastName,
+		&m.DisplayName, &m.Email, &m.ID, &m.Role, &m.Status,
+	)
+
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+
+		return nil, err
+	}
+
+	m.TeamID = teamID
+	return m, nil
+}
+
 // TeamMembers returns members of for the given team.
 func (s *Store) TeamMembers(ctx context.Context, teamID types.ID) ([]Member, error) {
 	members := []Member{}
@@ -1,9 +1,14 @@
 package main
 
 import (
+	"context"
 	_ "embed"
 	"flag"
+	"io"
 	"os"
+	"os/signal"
+	"strings"
+	"syscall"
 
 	"github.com/stormkit-io/stormkit-io/src/ce/api/app/deployservice"
 	"github.com/stormkit-io/stormkit-io/src/ce/runner"
@@ -13,8 +18,12 @@ import (
 
 // These are flags
 var (
-	payload string
-	rootDir string
+	payload          string
+	rootDir          string
+	agent            bool
+	agentURL         string
+	agentLabels      string
+	agentConcurrency int
 )
 
 func mockValues() *deployservice.DeploymentMessage {
@@ -51,13 +60,33 @@ func mockValues() *deployservice.DeploymentMessage {
 // - tar
 // - zip
 func main() {
-	flag.StringVar(&payload, "payload", "", "Payload object with necessary information on the deployment")
+	flag.StringVar(&payload, "payload", "", "Payload object with necessary information on the deployment, or - to read it from the standard input")
 	flag.StringVar(&rootDir, "root-dir", "", "Root directory for the runner")
+	flag.BoolVar(&agent, "agent", false, "Run as a build agent that pulls deployments from the API")
+	flag.StringVar(&agentURL, "agent-url", os.Getenv("STORMKIT_AGENT_URL"), "The API url that the agent connects to")
+	flag.StringVar(&agentLabels, "agent-labels", os.Getenv("STORMKIT_AGENT_LABELS"), "Comma separated list of agent labels")
+	flag.IntVar(&agentConcurrency, "agent-concurrency", 1, "Number of deployments that the agent builds in parallel")
 	flag.Parse()
 
 	// Load config
 	config.Get()
 
+	if agent {
+		startAgent()
+		return
+	}
+
+	// Build agents pass the payload through the standard input
+	if payload == "-" {
+		data, err := io.ReadAll(os.Stdin)
+
+		if err != nil {
+			panic(err)
+		}
+
+		payload = string(data)
+	}
+
 	// This is a test deployment in this case
 	if payload == "" {
 		runner.FlagPrintLogs = true
@@ -76,3 +105,30 @@ func main() {
 		panic(err)
 	}
 }
+
+// startAgent runs the runner in agent mode. The agent token is
+// read from the STORMKIT_AGENT_TOKEN environment variable.
+func startAgent() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	labels := []string{}
+
+	for _, label := range strings.Split(agentLabels, ",") {
+		if label = strings.TrimSpace(label); label != "" {
+			labels = append(labels, label)
+		}
+	}
+
+	err := runner.NewAgent(runner.AgentOpts{
+		URL:         agentURL,
+		Token:       os.Getenv("STORMKIT_AGENT_TOKEN"),
+		Labels:      labels,
+		RootDir:     rootDir,
+		Concurrency: agentConcurrency,
+	}).Start(ctx)
+
+	if err != nil {
+		panic(err)
+	}
+}
@@ -2,10 +2,26 @@ package commands
 
 import "strings"
 
-const ActionDeploy string = "DEPLOY"
+const (
+	ActionDeploy   string = "DEPLOY"
+	ActionPublish  string = "PUBLISH"
+	ActionRollback string = "ROLLBACK"
+	ActionRedeploy string = "REDEPLOY"
+	ActionStatus   string = "STATUS"
+	ActionLogs     string = "LOGS"
+	ActionPromote  string = "PROMOTE"
+	ActionFreeze   string = "FREEZE"
+)
 
 var actionsMap = map[string]string{
-	"deploy": ActionDeploy,
+	"deploy":   ActionDeploy,
+	"publish":  ActionPublish,
+	"rollback": ActionRollback,
+	"redeploy": ActionRedeploy,
+	"status":   ActionStatus,
+	"logs":     ActionLogs,
+	"promote":  ActionPromote,
+	"freeze":   ActionFreeze,
 }
 
 type CommandOutput struct {
@@ -14,14 +30,27 @@ type CommandOutput struct {
 	Flags     map[string]string
 }
 
+// Name returns the lowercase name of the action, as it is typed in comments.
+func (c *CommandOutput) Name() string {
+	return strings.ToLower(c.Action)
+}
+
 // Parse the given command and return a structured command output. If no command
-// is found, or the action is not supported it returns nil.
+// is found, or the action is not supported it returns nil. Only the first line
+// of the command is parsed, so that commands can be followed by a description.
 func Parse(command string) *CommandOutput {
+	command = strings.TrimSpace(strings.SplitN(command, "\n", 2)[0])
+
 	if !strings.HasPrefix(command, "/stormkit") && !strings.HasPrefix(command, "@stormkit-io") {
 		return nil
 	}
 
-	pieces := strings.Split(command, " ")
+	pieces := strings.Fields(command)
+
+	if len(pieces) < 2 {
+		return nil
+	}
+
 	action := actionsMap[strings.ToLower(pieces[1])]
 
 	if action == "" {
@@ -36,7 +65,7 @@ func Parse(command string) *CommandOutput {
 
 	for _, piece := range pieces[2:] {
 		if strings.HasPrefix(piece, "--") {
-			flag := strings.Split(piece[2:], "=")
+			flag := strings.SplitN(piece[2:], "=", 2)
 			key := strings.TrimSpace(strings.ToLower(flag[0]))
 			val := "true"
 
@@ -16,3 +16,27 @@ func TestParse(t *testing.T) {
 	assert.Equal(t, output.Flags["flag-2"], "")
 	assert.Equal(t, output.Arguments, []string{"staging"})
 }
+
+func TestParse_Actions(t *testing.T) {
+	output := commands.Parse("@stormkit-io  promote --to=production\nShipping the fix.")
+
+	assert.NotNil(t, output)
+	assert.Equal(t, commands.ActionPromote, output.Action)
+	assert.Equal(t, "promote", output.Name())
+	assert.Equal(t, "production", output.Flags["to"])
+	assert.Empty(t, output.Arguments)
+
+	output = commands.Parse("/stormkit redeploy --env=staging")
+	assert.Equal(t, commands.ActionRedeploy, output.Action)
+	assert.Equal(t, "staging", output.Flags["env"])
+
+	for _, action := range []string{"publish", "rollback", "status", "logs", "freeze"} {
+		assert.NotNil(t, commands.Parse("/stormkit "+action), action)
+	}
+}
+
+func TestParse_Invalid(t *testing.T) {
+	assert.Nil(t, commands.Parse("/stormkit"))
+	assert.Nil(t, commands.Parse("/stormkit unknown"))
+	assert.Nil(t, commands.Parse("Looks good to me\n/stormkit publish"))
+}
@@ -35,6 +35,7 @@ const (
 
 	// Deployer services
 	DeployerServiceLocal = "local"
+	DeployerServiceAgent = "agent"
 )
 
 // Allowed environments
@@ -113,7 +114,7 @@ type DatabaseConfig struct {
 
 // DeployerConfig contains config for the deployer app.
 type DeployerConfig struct {
-	Service    string // Can be `local` | `github`
+	Service    string // Can be `local` | `github` | `agent`
 	StorageDir string // Directory to store deployments
 	Executable string // Directory to our deploy.js
 }
@@ -191,6 +192,7 @@ type Config struct {
 	Tracking         *TrackingConfig
 	InstanceID       string // The ID of the instance that is randomly assigned during start time
 	AppSecret        string
+	SecretsKey       string // The key that encrypts the data keys of environment secrets. Defaults to the app secret.
 	APIKey           string // The API key used to access several endpoints (for dedicated instances)
 	Env              string
 	Version          VersionConfig
@@ -291,10 +293,11 @@ func New() *Config {
 			PrometheusPort: get(os.Getenv("PROMETHEUS_PORT"), "2112"),
 		},
 
-		Env:       Env(),
-		RedisAddr: os.Getenv("REDIS_ADDR"),
-		AppSecret: AppSecret(),
-		Secrets:   secrets,
+		Env:        Env(),
+		RedisAddr:  os.Getenv("REDIS_ADDR"),
+		AppSecret:  AppSecret(),
+		SecretsKey: get(os.Getenv("STORMKIT_SECRETS_KEY"), AppSecret()),
+		Secrets:    secrets,
 		Version: VersionConfig{
 			Hash: hash,
 			Tag:  version,
@@ -123,3 +123,10 @@ func IsDuplicate(dberr error) bool {
 	err, ok := dberr.(*pq.Error)
 	return ok && err.Code == pq.ErrorCode(duplicateErrCode)
 }
+
+// IsInvalidNumber checks whether an error is caused by a value that
+// cannot be converted to a number, or that is out of range.
+func IsInvalidNumber(dberr error) bool {
+	err, ok := dberr.(*pq.Error)
+	return ok && (err.Code == pq.ErrorCode("22P02") || err.Code == pq.ErrorCode("22003"))
+}
@@ -14,10 +14,12 @@ type PayloadField struct {
 }
 
 type PayloadEmbed struct {
-	URL       string         `json:"url,omitempty"`
-	Title     string         `json:"title"`
-	Timestamp string         `json:"timestamp"` // ISO8601 format
-	Fields    []PayloadField `json:"fields"`
+	URL         string         `json:"url,omitempty"`
+	Title       string         `json:"title"`
+	Description string         `json:"description,omitempty"`
+	Color       int            `json:"color,omitempty"` // Decimal RGB value
+	Timestamp   string         `json:"timestamp"`       // ISO8601 format
+	Fields      []PayloadField `json:"fields"`
 }
 
 // Payload represents a discord message payload. For more
@@ -0,0 +1,83 @@
+package factory
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/backgroundjob"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/shttp"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type MockBackgroundJob struct {
+	*backgroundjob.Job
+	*Factory
+}
+
+func (j MockBackgroundJob) Insert(conn databasetest.TestDB) error {
+	headers, err := json.Marshal(j.Headers)
+
+	if err != nil {
+		panic(err)
+	}
+
+	insertQuery := `
+		INSERT INTO skitapi.background_jobs
+			(app_id, env_id, job_path, job_method, job_headers, job_payload,
+			 dedup_key, job_status, attempts, max_retries, run_at, created_at)
+		VALUES
+			($1, $2, $3, $4, $5, NULLIF($6, ''), NULLIF($7, ''), $8, $9, $10, $11, $12)
+		RETURNING
+			job_id;
+	`
+
+	return conn.PrepareOrPanic(insertQuery).QueryRow(
+		j.AppID,
+		j.EnvID,
+		j.Path,
+		j.Method,
+		headers,
+		j.Payload,
+		j.DedupKey,
+		j.Status,
+		j.Attempts,
+		j.MaxRetries,
+		j.RunAt,
+		j.CreatedAt,
+	).Scan(&j.ID)
+}
+
+func (f *Factory) MockBackgroundJob(env *MockEnv, overwrites ...map[string]any) *MockBackgroundJob {
+	if env == nil {
+		env = f.GetEnv()
+	}
+
+	job := &backgroundjob.Job{
+		AppID:     env.AppID,
+		EnvID:     env.ID,
+		Path:      "/api/job",
+		Method:    shttp.MethodPost,
+		Headers:   shttp.Headers{},
+		Status:    backgroundjob.StatusQueued,
+		RunAt:     utils.NewUnix(),
+		CreatedAt: utils.NewUnix(),
+	}
+
+	for _, o := range overwrites {
+		merge(job, o)
+	}
+
+	mock := f.newObject(MockBackgroundJob{
+		Job:     job,
+		Factory: f,
+	}).(MockBackgroundJob)
+
+	err := mock.Insert(f.conn)
+
+	if err != nil {
+		fmt.Printf("Error inserting background job %s", err.Error())
+	}
+
+	return &mock
+}
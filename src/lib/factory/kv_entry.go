@@ -0,0 +1,67 @@
+package factory
+
+import (
+	"fmt"
+
+	"github.com/stormkit-io/stormkit-io/src/ce/api/app/kvstore"
+	"github.com/stormkit-io/stormkit-io/src/lib/database/databasetest"
+	"github.com/stormkit-io/stormkit-io/src/lib/types"
+	"github.com/stormkit-io/stormkit-io/src/lib/utils"
+)
+
+type MockKVEntry struct {
+	*kvstore.Entry
+	EnvID types.ID
+	*Factory
+}
+
+func (e MockKVEntry) Insert(conn databasetest.TestDB) error {
+	insertQuery := `
+		INSERT INTO skitapi.kv_entries
+			(env_id, kv_key, kv_value, kv_version, expires_at, created_at)
+		VALUES
+			($1, $2, $3, $4, $5, $6);
+	`
+
+	_, err := conn.PrepareOrPanic(insertQuery).Exec(
+		e.EnvID,
+		e.Key,
+		e.Value,
+		e.Version,
+		e.ExpiresAt,
+		e.CreatedAt,
+	)
+
+	return err
+}
+
+func (f *Factory) MockKVEntry(env *MockEnv, overwrites ...map[string]any) *MockKVEntry {
+	if env == nil {
+		env = f.GetEnv()
+	}
+
+	entry := &kvstore.Entry{
+		Key:       "my-key",
+		Value:     "my-value",
+		Version:   1,
+		CreatedAt: utils.NewUnix(),
+	}
+
+	for _, o := range overwrites {
+		merge(entry, o)
+	}
+
+	mock := f.newObject(MockKVEntry{
+		Entry:   entry,
+		EnvID:   env.ID,
+		Factory: f,
+	}).(MockKVEntry)
+
+	err := mock.Insert(f.conn)
+
+	if err != nil {
+		fmt.Printf("Error inserting kv entry %s", err.Error())
+	}
+
+	return &mock
+}
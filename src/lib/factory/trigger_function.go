@@ -21,11 +21,21 @@ func (tf MockFunctionTrigger) Insert(conn databasetest.TestDB) error {
 		panic(err)
 	}
 
+	if tf.SigningSecret == "" {
+		tf.SigningSecret = utils.GenerateSigningSecret()
+	}
+
+	secret, err := utils.Encrypt([]byte(tf.SigningSecret))
+
+	if err != nil {
+		panic(err)
+	}
+
 	insertQuery := `
 		INSERT INTO skitapi.function_triggers
-			(env_id, cron, next_run_at, trigger_options, trigger_status, updated_at, created_at)
+			(env_id, cron, time_zone, next_run_at, trigger_options, trigger_status, updated_at, created_at, trigger_source, signing_secret)
 		VALUES
-			($1, $2, $3, $4, $5, $6, $7)
+			($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, NULLIF($9, ''), $10)
 		RETURNING
 			trigger_id;
 	`
@@ -33,11 +43,14 @@ func (tf MockFunctionTrigger) Insert(conn databasetest.TestDB) error {
 	return conn.PrepareOrPanic(insertQuery).QueryRow(
 		tf.EnvID,
 		tf.Cron,
+		tf.TimeZone,
 		tf.NextRunAt,
 		opts,
 		tf.Status,
 		tf.UpdatedAt,
 		tf.CreatedAt,
+		tf.Source,
+		utils.EncodeToString(secret),
 	).Scan(&tf.ID)
 }
 
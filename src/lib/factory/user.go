@@ -107,14 +107,15 @@ func (f *Factory) SeedProviders(usr *MockUser) {
 			INSERT INTO user_access_tokens (
 				user_id, display_name, account_uri, provider,
 				token_type, token_value, token_refresh, personal_access_token,
-				expire_at
+				expire_at, account_id
 			) VALUES (
 				$1, $2, $3, $4,
-				$5, $6, $7, $8, '2050-02-02'::timestamp
+				$5, $6, $7, $8, '2050-02-02'::timestamp, $9
 			)`,
 		).Exec(
 			usr.ID, usr.DisplayName, accountUri, provider,
 			"bearer", "1234-abcd-4251", "6431-refresh", accessToken,
+			usr.ID.String(),
 		)
 
 		if err != nil {
@@ -184,6 +184,67 @@ func Render(args RenderArgs) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// emailTemplate is the layout of the platform emails. Styles are inlined
+// since most email clients drop style tags.
+const emailTemplate = `<div style="background:#f4f4f7;padding:2rem 1rem;font-family:Helvetica,Arial,sans-serif;color:#1f1f2e;">
+	<div style="margin:0 auto;max-width:600px;background:#ffffff;border-radius:8px;padding:2rem;">
+		{{ template "email_content" . }}
+	</div>
+	<p style="margin:1.5rem auto 0;max-width:600px;font-size:12px;color:#6b6b80;text-align:center;">
+		You are receiving this email because you are a member of the team that owns {{ .app_name }}.
+		{{ if .preferences_url }}<a href="{{ .preferences_url }}" style="color:#6b6b80;">Manage your notification preferences</a>.{{ end }}
+	</p>
+</div>`
+
+// RenderEmail renders the email content within the email layout. The result
+// is the body of the email, without the html and body tags.
+func RenderEmail(args RenderArgs) ([]byte, error) {
+	var buf bytes.Buffer
+
+	tmpl, err := template.New("email").Parse(emailTemplate)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := tmpl.New("email_content").Parse(args.PageContent); err != nil {
+		return nil, err
+	}
+
+	if err := tmpl.Execute(&buf, args.ContentData); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+const emailButton = `<a href="{{ .link_url }}" style="display:inline-block;background:#78193b;color:#ffffff;padding:0.75rem 1.5rem;border-radius:6px;text-decoration:none;">{{ .link_title }}</a>`
+
+// EmailTemplates are the contents of the platform emails.
+var EmailTemplates = map[string]string{
+	"deploy_failed": `
+	<h2 style="margin-top:0;">Deployment failed</h2>
+	<p>Your deployment <strong>#{{ .deployment_id }}</strong> of <strong>{{ .app_name }}</strong> to <strong>{{ .env_name }}</strong>{{ if .branch }} from the <strong>{{ .branch }}</strong> branch{{ end }} has failed.</p>
+	{{ if .error }}<pre style="background:#f4f4f7;padding:1rem;border-radius:4px;white-space:pre-wrap;">{{ .error }}</pre>{{ end }}
+	<p>` + emailButton + `</p>`,
+
+	"protected_publish": `
+	<h2 style="margin-top:0;">Deployment published to {{ .env_name }}</h2>
+	<p>Deployment <strong>#{{ .deployment_id }}</strong> of <strong>{{ .app_name }}</strong> has been published to the protected environment <strong>{{ .env_name }}</strong>.</p>
+	<p>` + emailButton + `</p>`,
+
+	"domain_failing": `
+	<h2 style="margin-top:0;">{{ .domain_name }} is not responding</h2>
+	<p>The domain <strong>{{ .domain_name }}</strong> of <strong>{{ .app_name }}</strong> failed its last health check.</p>
+	{{ if .error }}<pre style="background:#f4f4f7;padding:1rem;border-radius:4px;white-space:pre-wrap;">{{ .error }}</pre>{{ end }}
+	{{ if .link_url }}<p>` + emailButton + `</p>{{ end }}`,
+
+	"certificate_expiring": `
+	<h2 style="margin-top:0;">Certificate of {{ .domain_name }} is expiring</h2>
+	<p>The TLS certificate of <strong>{{ .domain_name }}</strong> ({{ .app_name }}) expires on <strong>{{ .expires_at }}</strong>. Check that the domain still points to Stormkit so that the certificate can be renewed.</p>
+	{{ if .link_url }}<p>` + emailButton + `</p>{{ end }}`,
+}
+
 var Templates = map[string]string{
 	"error": `
 	<h1>Whoops! We got something wrong.</h1>
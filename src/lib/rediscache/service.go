@@ -306,6 +306,53 @@ func Broadcast(event string, payload ...string) error {
 	return Service().Broadcast(event, payload...)
 }
 
+// SubscribeContext subscribes to the given event until the context is cancelled.
+// Unlike Subscribe, it is meant for short-lived subscriptions such as streaming
+// responses to a client. The returned channel is closed when the context is done.
+func SubscribeContext(ctx context.Context, event string) (<-chan string, error) {
+	client := Client()
+
+	if client == nil {
+		return nil, fmt.Errorf("redis client is not initialized")
+	}
+
+	sub := client.Subscribe(ctx, event)
+
+	// Make sure the subscription is active before returning
+	if _, err := sub.Receive(ctx); err != nil {
+		sub.Close()
+		return nil, err
+	}
+
+	payloads := make(chan string)
+
+	go func() {
+		defer close(payloads)
+		defer sub.Close()
+
+		chn := sub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case msg, ok := <-chn:
+				if !ok {
+					return
+				}
+
+				select {
+				case payloads <- msg.Payload:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return payloads, nil
+}
+
 // SetAll is a convenience function to set a key-value pair across all services with optional filtering.
 func SetAll(key, value string, filter []string) error {
 	return Service().SetAll(key, value, filter)
@@ -55,6 +55,23 @@ func (s *ServiceSuite) Test_SubscribeAndBroadcast() {
 	}, 5*time.Second, 500*time.Millisecond)
 }
 
+func (s *ServiceSuite) Test_SubscribeContext() {
+	ctx, cancel := context.WithCancel(context.Background())
+	channel := "test-event-channel-ctx"
+
+	payloads, err := rediscache.SubscribeContext(ctx, channel)
+	s.NoError(err)
+	s.NoError(rediscache.Service().Broadcast(channel, "with-payload"))
+	s.Equal("with-payload", <-payloads)
+
+	cancel()
+
+	s.Eventually(func() bool {
+		_, ok := <-payloads
+		return !ok
+	}, 5*time.Second, 100*time.Millisecond)
+}
+
 func TestServiceSuite(t *testing.T) {
 	suite.Run(t, &ServiceSuite{})
 }
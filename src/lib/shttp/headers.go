@@ -8,6 +8,12 @@ import (
 	"strings"
 )
 
+// Headers of the requests that are signed with utils.SignPayload.
+const (
+	HeaderSignature = "X-Stormkit-Signature"
+	HeaderTimestamp = "X-Stormkit-Timestamp"
+)
+
 type Headers map[string]string
 
 func (h Headers) Make() http.Header {
@@ -23,14 +23,21 @@ func init() {
 
 // A list of task types.
 const (
-	DeploymentStart     = "deployment:start"
-	TriggerFunctionHttp = "triggerfunction:http"
+	DeploymentStart         = "deployment:start"
+	TriggerFunctionHttp     = "triggerfunction:http"
+	OutboundWebhookDelivery = "outboundwebhook:deliver"
+	NotificationDelivery    = "notification:deliver"
+	EmailNotification       = "email:notify"
+	BackgroundJobInvoke     = "backgroundjob:invoke"
 )
 
 type EnqueueOptions struct {
 	MaxRetry  int
 	QueueName string
 	TaskID    string
+
+	// ProcessAt delays the task until the given time. Zero value processes the task immediately.
+	ProcessAt time.Time
 }
 type TaskClient interface {
 	Enqueue(task *asynq.Task, opts ...asynq.Option) (*asynq.TaskInfo, error)
@@ -71,13 +78,48 @@ func NewServer(queues map[string]int, concurrency int) *asynq.Server {
 	return asynq.NewServer(
 		asynq.RedisClientOpt{Addr: config.Get().RedisAddr},
 		asynq.Config{
-			LogLevel:    logLevel,
-			Concurrency: concurrency,
-			Queues:      queues,
+			LogLevel:       logLevel,
+			Concurrency:    concurrency,
+			Queues:         queues,
+			RetryDelayFunc: RetryDelay,
 		},
 	)
 }
 
+// RetryDelay returns the duration to wait before retrying the task. Outbound
+// webhook and notification deliveries are retried with an exponential backoff
+// starting at 30 seconds and capped at 6 hours. Function triggers back off
+// from 10 seconds up to 10 minutes, so that retries finish before the next run.
+// Background jobs use the same backoff as function triggers.
+func RetryDelay(n int, err error, t *asynq.Task) time.Duration {
+	if t.Type() == OutboundWebhookDelivery || t.Type() == NotificationDelivery {
+		return ExponentialBackoff(n, 30*time.Second, 6*time.Hour)
+	}
+
+	if t.Type() == TriggerFunctionHttp || t.Type() == BackgroundJobInvoke {
+		return ExponentialBackoff(n, 10*time.Second, 10*time.Minute)
+	}
+
+	return asynq.DefaultRetryDelayFunc(n, err, t)
+}
+
+// ExponentialBackoff returns base * 2^n, capped at max.
+func ExponentialBackoff(n int, base, max time.Duration) time.Duration {
+	if n < 0 {
+		n = 0
+	}
+
+	if n >= 32 {
+		return max
+	}
+
+	if delay := base << n; delay > 0 && delay < max {
+		return delay
+	}
+
+	return max
+}
+
 // Inspector is a singleton method which creates a new asynq inspector and
 // returns it in subsequent calls.
 func Inspector() *asynq.Inspector {
@@ -120,8 +162,15 @@ func Enqueue(ctx context.Context, taskName string, message any, opts *EnqueueOpt
 		asynq.MaxRetry(opts.MaxRetry),
 	}
 
+	startAt := time.Now()
+
+	if opts.ProcessAt.After(startAt) {
+		startAt = opts.ProcessAt
+		options = append(options, asynq.ProcessAt(opts.ProcessAt))
+	}
+
 	if config.IsSelfHosted() {
-		options = append(options, asynq.Deadline(time.Now().Add(time.Hour*6)))
+		options = append(options, asynq.Deadline(startAt.Add(time.Hour*6)))
 	}
 
 	if opts.TaskID != "" {
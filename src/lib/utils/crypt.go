@@ -3,12 +3,16 @@ package utils
 import (
 	"crypto/aes"
 	"crypto/cipher"
+	"crypto/hmac"
 	"crypto/rand"
 	"crypto/sha1"
+	"crypto/sha256"
 	"encoding/base64"
 	"encoding/binary"
+	"encoding/hex"
 	"errors"
 	"io"
+	"strconv"
 
 	"github.com/stormkit-io/stormkit-io/src/lib/types"
 )
@@ -27,6 +31,22 @@ func Hash(text []byte) string {
 	return base64.URLEncoding.EncodeToString(hasher.Sum(nil))
 }
 
+// SignPayload returns the HMAC-SHA256 signature of `<timestamp>.<body>`,
+// prefixed with the algorithm. It is used to sign requests that Stormkit
+// sends and receives on behalf of users.
+func SignPayload(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(strconv.FormatInt(timestamp, 10)))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// GenerateSigningSecret returns a new secret to sign requests with SignPayload.
+func GenerateSigningSecret() string {
+	return "whsec_" + RandomToken(32)
+}
+
 type EncryptToStringFunc = func(plaintext string, altKey ...[]byte) string
 
 // Encrypts the given string, and then uses base64 to encode it.
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"bytes"
+	"regexp"
+	"sort"
+)
+
+// SecretMask is the placeholder that replaces masked values.
+const SecretMask = "***"
+
+// minSecretLength is the minimum length a value needs to have in order
+// to be masked. Shorter values such as `1` or `true` would otherwise
+// garble the logs.
+const minSecretLength = 4
+
+var secretKeyRegexp = regexp.MustCompile("(?i)secret|_key|_token|password")
+
+// credentialRegexps detects well-known credential formats, regardless
+// of whether they were declared as environment variables or not.
+var credentialRegexps = []*regexp.Regexp{
+	regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`),       // AWS access key ids
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{36,255}\b`),   // GitHub tokens
+	regexp.MustCompile(`\bgithub_pat_[A-Za-z0-9_]{22,255}\b`), // GitHub fine-grained tokens
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_\-]{20,}\b`),       // GitLab personal access tokens
+	regexp.MustCompile(`\bSK_[A-Za-z0-9]{62}\b`),              // Stormkit API keys
+}
+
+// IsSecretKey returns true when the given environment variable
+// name looks like it holds a secret value.
+func IsSecretKey(key string) bool {
+	return secretKeyRegexp.MatchString(key)
+}
+
+// SecretMasker replaces secret values and well-known credentials with SecretMask.
+type SecretMasker struct {
+	secrets [][]byte
+}
+
+// NewSecretMasker returns a new masker which masks the values of the
+// given variables whose keys are marked as secret (see IsSecretKey).
+func NewSecretMasker(vars map[string]string) *SecretMasker {
+	m := &SecretMasker{}
+
+	for key, value := range vars {
+		if IsSecretKey(key) {
+			m.AddSecret(value)
+		}
+	}
+
+	return m
+}
+
+// AddSecret adds the given value to the list of values to mask.
+func (m *SecretMasker) AddSecret(value string) {
+	if len(value) < minSecretLength {
+		return
+	}
+
+	m.secrets = append(m.secrets, []byte(value))
+
+	// Replace longer secrets first so that a secret which contains
+	// another one is masked as a whole.
+	sort.SliceStable(m.secrets, func(i, j int) bool {
+		return len(m.secrets[i]) > len(m.secrets[j])
+	})
+}
+
+// Mask returns a copy of the input with secrets replaced. A nil masker
+// still masks well-known credential formats.
+func (m *SecretMasker) Mask(input []byte) []byte {
+	mask := []byte(SecretMask)
+
+	if m != nil {
+		for _, secret := range m.secrets {
+			input = bytes.ReplaceAll(input, secret, mask)
+		}
+	}
+
+	for _, re := range credentialRegexps {
+		input = re.ReplaceAll(input, mask)
+	}
+
+	return input
+}
+
+// Pending returns the length of the longest suffix of the input that is the
+// beginning of a secret. Streams hold these bytes back until the next write,
+// so that a secret which is split across two writes is masked as a whole.
+func (m *SecretMasker) Pending(input []byte) int {
+	if m == nil {
+		return 0
+	}
+
+	longest := 0
+
+	for _, secret := range m.secrets {
+		start := max(0, len(input)-len(secret)+1)
+
+		for i := start; i < len(input) && len(input)-i > longest; i++ {
+			if bytes.HasPrefix(secret, input[i:]) {
+				longest = len(input) - i
+				break
+			}
+		}
+	}
+
+	return longest
+}
+
+// MaskString is the string equivalent of Mask.
+func (m *SecretMasker) MaskString(input string) string {
+	return string(m.Mask([]byte(input)))
+}
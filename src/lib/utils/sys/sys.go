@@ -8,6 +8,7 @@ import (
 	"strings"
 	"sync"
 	"syscall"
+	"time"
 
 	"github.com/google/shlex"
 )
@@ -34,6 +35,7 @@ type CommandWrapper struct {
 	stdout      io.Writer
 	stderr      io.Writer
 	sysProcAttr *syscall.SysProcAttr
+	waitDelay   time.Duration
 	_execCmd    *exec.Cmd
 }
 
@@ -48,6 +50,7 @@ func (c CommandWrapper) Cmd() *exec.Cmd {
 		cmd.Stdout = c.stdout
 		cmd.Stderr = c.stderr
 		cmd.SysProcAttr = c.sysProcAttr
+		cmd.WaitDelay = c.waitDelay
 		c._execCmd = cmd
 	}
 
@@ -111,6 +114,7 @@ type CommandOpts struct {
 	Stdout      io.Writer
 	Stderr      io.Writer
 	SysProcAttr *syscall.SysProcAttr // This is used to set process attributes like Pdeathsig
+	WaitDelay   time.Duration        // This is used to stop waiting for the I/O of child processes once the context is done
 }
 
 var DefaultCommand CommandInterface
@@ -146,5 +150,6 @@ func Command(ctx context.Context, opts CommandOpts) CommandInterface {
 		stdout:      opts.Stdout,
 		stderr:      opts.Stderr,
 		sysProcAttr: opts.SysProcAttr,
+		waitDelay:   opts.WaitDelay,
 	}
 }
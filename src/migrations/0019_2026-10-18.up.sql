@@ -0,0 +1 @@
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS status_check_results JSONB NULL;
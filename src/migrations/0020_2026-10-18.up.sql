@@ -0,0 +1 @@
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS sbom JSONB NULL;
@@ -0,0 +1,2 @@
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS promoted_from BIGINT NULL;
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS runtime_vars JSONB NULL;
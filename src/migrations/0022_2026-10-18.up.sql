@@ -0,0 +1,31 @@
+CREATE TABLE IF NOT EXISTS skitapi.rollouts (
+    rollout_id bigserial primary key NOT NULL,
+    app_id bigint NOT NULL,
+    env_id bigint NOT NULL,
+    deployment_id bigint NOT NULL,
+    previous_deployment_id bigint NULL,
+    rollout_plan jsonb NOT NULL,
+    current_step integer DEFAULT 0 NOT NULL,
+    rollout_status text NOT NULL,
+    status_reason text NULL,
+    next_step_at timestamp without time zone NULL,
+    updated_at timestamp without time zone NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_rollouts_env_id ON skitapi.rollouts USING btree (env_id);
+CREATE INDEX IF NOT EXISTS idx_rollouts_status_next_step_at ON skitapi.rollouts USING btree (rollout_status, next_step_at);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.rollouts
+        ADD CONSTRAINT rollouts_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
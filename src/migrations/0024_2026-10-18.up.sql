@@ -0,0 +1,52 @@
+CREATE TABLE IF NOT EXISTS skitapi.publish_approvals (
+    approval_id bigserial primary key NOT NULL,
+    app_id bigint NOT NULL,
+    env_id bigint NOT NULL,
+    publish_config jsonb NOT NULL,
+    required_approvals integer NOT NULL,
+    approval_status text NOT NULL,
+    requested_by bigint NULL,
+    updated_at timestamp without time zone NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_publish_approvals_env_id_status ON skitapi.publish_approvals USING btree (env_id, approval_status);
+
+CREATE TABLE IF NOT EXISTS skitapi.publish_approval_reviews (
+    review_id bigserial primary key NOT NULL,
+    approval_id bigint NOT NULL,
+    user_id bigint NOT NULL,
+    review_decision text NOT NULL,
+    review_comment text NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_publish_approval_reviews_approval_id_user_id ON skitapi.publish_approval_reviews USING btree (approval_id, user_id);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.publish_approvals
+        ADD CONSTRAINT publish_approvals_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.publish_approval_reviews
+        ADD CONSTRAINT publish_approval_reviews_approval_id_fkey FOREIGN KEY (approval_id) REFERENCES skitapi.publish_approvals(approval_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
@@ -0,0 +1,7 @@
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS is_pinned boolean DEFAULT false NOT NULL;
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS deployment_tag text NULL;
+ALTER TABLE skitapi.deployments ADD COLUMN IF NOT EXISTS last_published_at timestamp without time zone NULL;
+
+UPDATE skitapi.deployments d SET last_published_at = dp.created_at
+FROM skitapi.deployments_published dp
+WHERE dp.deployment_id = d.deployment_id AND d.last_published_at IS NULL;
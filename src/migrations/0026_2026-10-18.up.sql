@@ -0,0 +1,29 @@
+CREATE TABLE IF NOT EXISTS skitapi.deployments_queue (
+    deployment_id bigint primary key NOT NULL,
+    app_id bigint NOT NULL,
+    env_id bigint NOT NULL,
+    team_id bigint NOT NULL,
+    branch text DEFAULT '' NOT NULL,
+    queue_priority integer DEFAULT 0 NOT NULL,
+    queue_status text NOT NULL,
+    deployment_payload text NOT NULL,
+    queued_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    dispatched_at timestamp without time zone NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deployments_queue_status ON skitapi.deployments_queue USING btree (queue_status, queue_priority DESC, queued_at);
+CREATE INDEX IF NOT EXISTS idx_deployments_queue_env_id_branch ON skitapi.deployments_queue USING btree (env_id, branch);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.deployments_queue
+        ADD CONSTRAINT deployments_queue_deployment_id_fkey FOREIGN KEY (deployment_id) REFERENCES skitapi.deployments(deployment_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
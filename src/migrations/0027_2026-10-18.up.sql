@@ -0,0 +1,53 @@
+CREATE TABLE IF NOT EXISTS skitapi.build_agents (
+    agent_id bigserial primary key NOT NULL,
+    agent_name text NOT NULL,
+    agent_labels text[] DEFAULT '{}'::text[] NOT NULL,
+    agent_version text DEFAULT '' NOT NULL,
+    token_hash text NOT NULL,
+    last_heartbeat_at timestamp without time zone NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_build_agents_token_hash ON skitapi.build_agents USING btree (token_hash);
+
+CREATE TABLE IF NOT EXISTS skitapi.build_agent_jobs (
+    deployment_id bigint primary key NOT NULL,
+    agent_id bigint NULL,
+    required_labels text[] DEFAULT '{}'::text[] NOT NULL,
+    deployment_payload text NOT NULL,
+    job_status text NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    claimed_at timestamp without time zone NULL,
+    heartbeat_at timestamp without time zone NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_build_agent_jobs_status ON skitapi.build_agent_jobs USING btree (job_status, created_at);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.build_agent_jobs
+        ADD CONSTRAINT build_agent_jobs_deployment_id_fkey FOREIGN KEY (deployment_id) REFERENCES skitapi.deployments(deployment_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.build_agent_jobs
+        ADD CONSTRAINT build_agent_jobs_agent_id_fkey FOREIGN KEY (agent_id) REFERENCES skitapi.build_agents(agent_id) ON DELETE SET NULL;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
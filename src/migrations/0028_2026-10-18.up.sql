@@ -0,0 +1,68 @@
+CREATE TABLE IF NOT EXISTS skitapi.deploy_triggers (
+    trigger_id bigserial primary key NOT NULL,
+    env_id bigint NOT NULL,
+    trigger_name text NOT NULL,
+    trigger_token text NOT NULL,
+    signing_secret text NULL,
+    trigger_options jsonb DEFAULT '{}'::jsonb NOT NULL,
+    rate_limit integer DEFAULT 60 NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    updated_at timestamp without time zone NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_deploy_triggers_token ON skitapi.deploy_triggers USING btree (trigger_token);
+CREATE INDEX IF NOT EXISTS idx_deploy_triggers_env_id ON skitapi.deploy_triggers USING btree (env_id);
+
+CREATE TABLE IF NOT EXISTS skitapi.deploy_trigger_invocations (
+    invocation_id bigserial primary key NOT NULL,
+    trigger_id bigint NOT NULL,
+    deployment_id bigint NULL,
+    status_code integer NOT NULL,
+    invocation_error text NULL,
+    invocation_params jsonb DEFAULT '{}'::jsonb NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_deploy_trigger_invocations_trigger_id ON skitapi.deploy_trigger_invocations USING btree (trigger_id, created_at);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.deploy_triggers
+        ADD CONSTRAINT deploy_triggers_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.deploy_trigger_invocations
+        ADD CONSTRAINT deploy_trigger_invocations_trigger_id_fkey FOREIGN KEY (trigger_id) REFERENCES skitapi.deploy_triggers(trigger_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.deploy_trigger_invocations
+        ADD CONSTRAINT deploy_trigger_invocations_deployment_id_fkey FOREIGN KEY (deployment_id) REFERENCES skitapi.deployments(deployment_id) ON DELETE SET NULL;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
@@ -0,0 +1,31 @@
+ALTER TABLE skitapi.app_outbound_webhooks ADD COLUMN IF NOT EXISTS signing_secret text NULL;
+ALTER TABLE skitapi.app_outbound_webhooks ADD COLUMN IF NOT EXISTS consecutive_failures integer DEFAULT 0 NOT NULL;
+ALTER TABLE skitapi.app_outbound_webhooks ADD COLUMN IF NOT EXISTS disabled_at timestamp without time zone NULL;
+
+CREATE TABLE IF NOT EXISTS skitapi.outbound_webhook_deliveries (
+    delivery_id bigserial primary key NOT NULL,
+    wh_id integer NOT NULL,
+    trigger_when text NOT NULL,
+    request jsonb DEFAULT '{}'::jsonb NOT NULL,
+    response jsonb DEFAULT '{}'::jsonb NOT NULL,
+    latency_ms integer DEFAULT 0 NOT NULL,
+    attempt integer DEFAULT 1 NOT NULL,
+    is_success boolean DEFAULT FALSE NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbound_webhook_deliveries_wh_id ON skitapi.outbound_webhook_deliveries USING btree (wh_id, delivery_id DESC);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.outbound_webhook_deliveries
+        ADD CONSTRAINT outbound_webhook_deliveries_wh_id_fkey FOREIGN KEY (wh_id) REFERENCES skitapi.app_outbound_webhooks(wh_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
@@ -0,0 +1 @@
+ALTER TABLE skitapi.app_outbound_webhooks ADD COLUMN IF NOT EXISTS payload_format text DEFAULT 'raw' NOT NULL;
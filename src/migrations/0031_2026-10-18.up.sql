@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS skitapi.app_notification_channels (
+    channel_id bigserial primary key NOT NULL,
+    app_id bigint NOT NULL,
+    channel_name text NOT NULL,
+    channel_provider text NOT NULL,
+    channel_events text[] DEFAULT '{}'::text[] NOT NULL,
+    channel_config text NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    updated_at timestamp without time zone NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_app_notification_channels_app_id ON skitapi.app_notification_channels USING btree (app_id);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.app_notification_channels
+        ADD CONSTRAINT app_notification_channels_app_id_fkey FOREIGN KEY (app_id) REFERENCES skitapi.apps(app_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
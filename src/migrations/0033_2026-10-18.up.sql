@@ -0,0 +1 @@
+ALTER TABLE skitapi.function_triggers ADD COLUMN IF NOT EXISTS time_zone text NULL;
@@ -0,0 +1,38 @@
+CREATE TABLE IF NOT EXISTS skitapi.background_jobs (
+    job_id bigserial primary key NOT NULL,
+    app_id bigint NOT NULL,
+    env_id bigint NOT NULL,
+    job_path text NOT NULL,
+    job_method text NOT NULL,
+    job_headers jsonb DEFAULT '{}'::jsonb NOT NULL,
+    job_payload text NULL,
+    dedup_key text NULL,
+    job_status text DEFAULT 'queued' NOT NULL,
+    attempts integer DEFAULT 0 NOT NULL,
+    max_retries integer DEFAULT 0 NOT NULL,
+    job_result jsonb NULL,
+    run_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    updated_at timestamp without time zone NULL,
+    completed_at timestamp without time zone NULL
+);
+
+CREATE INDEX IF NOT EXISTS idx_background_jobs_env_id ON skitapi.background_jobs USING btree (env_id, job_id DESC);
+
+-- A deduplication key identifies a single pending job of the environment.
+CREATE UNIQUE INDEX IF NOT EXISTS idx_background_jobs_dedup_key ON skitapi.background_jobs USING btree (env_id, dedup_key)
+    WHERE dedup_key IS NOT NULL AND job_status IN ('queued', 'running');
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.background_jobs
+        ADD CONSTRAINT background_jobs_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
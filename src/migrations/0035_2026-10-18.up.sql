@@ -0,0 +1,26 @@
+CREATE TABLE IF NOT EXISTS skitapi.kv_entries (
+    env_id bigint NOT NULL,
+    kv_key text NOT NULL,
+    kv_value text NOT NULL,
+    kv_version bigint DEFAULT 1 NOT NULL,
+    expires_at timestamp without time zone NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    updated_at timestamp without time zone NULL,
+    PRIMARY KEY (env_id, kv_key)
+);
+
+CREATE INDEX IF NOT EXISTS idx_kv_entries_expires_at ON skitapi.kv_entries USING btree (expires_at) WHERE expires_at IS NOT NULL;
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.kv_entries
+        ADD CONSTRAINT kv_entries_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
@@ -0,0 +1,42 @@
+CREATE TABLE IF NOT EXISTS skitapi.env_secrets (
+    secret_id bigserial PRIMARY KEY,
+    env_id bigint NOT NULL,
+    secret_name text NOT NULL,
+    secret_scope text DEFAULT 'both' NOT NULL,
+    current_version integer DEFAULT 1 NOT NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    updated_at timestamp without time zone NULL
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_env_secrets_env_id_secret_name ON skitapi.env_secrets USING btree (env_id, secret_name);
+
+CREATE TABLE IF NOT EXISTS skitapi.env_secret_versions (
+    secret_id bigint NOT NULL,
+    secret_version integer NOT NULL,
+    encrypted_value text NOT NULL,
+    encrypted_key text NOT NULL,
+    restored_from integer NULL,
+    created_by bigint NULL,
+    created_at timestamp without time zone DEFAULT (now() AT TIME ZONE 'UTC'::text) NOT NULL,
+    PRIMARY KEY (secret_id, secret_version)
+);
+
+DO $$
+BEGIN
+  BEGIN
+
+    ALTER TABLE ONLY skitapi.env_secrets
+        ADD CONSTRAINT env_secrets_env_id_fkey FOREIGN KEY (env_id) REFERENCES skitapi.apps_build_conf(env_id) ON DELETE CASCADE;
+
+    ALTER TABLE ONLY skitapi.env_secret_versions
+        ADD CONSTRAINT env_secret_versions_secret_id_fkey FOREIGN KEY (secret_id) REFERENCES skitapi.env_secrets(secret_id) ON DELETE CASCADE;
+
+    ALTER TABLE ONLY skitapi.env_secret_versions
+        ADD CONSTRAINT env_secret_versions_created_by_fkey FOREIGN KEY (created_by) REFERENCES skitapi.users(user_id) ON DELETE SET NULL;
+
+  EXCEPTION
+    WHEN duplicate_table THEN  -- postgres raises duplicate_table at surprising times. Ex.: for UNIQUE constraints.
+    WHEN duplicate_object THEN
+      RAISE NOTICE 'Table constraint already exists';
+  END;
+END $$;
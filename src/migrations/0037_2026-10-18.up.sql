@@ -0,0 +1 @@
+ALTER TABLE skitapi.function_triggers ADD COLUMN IF NOT EXISTS trigger_source text NULL;
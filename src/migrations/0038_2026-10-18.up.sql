@@ -0,0 +1 @@
+ALTER TABLE skitapi.build_agent_jobs ADD COLUMN IF NOT EXISTS claim_token_hash text NULL;
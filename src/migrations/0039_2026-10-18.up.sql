@@ -0,0 +1 @@
+ALTER TABLE skitapi.user_access_tokens ADD COLUMN IF NOT EXISTS account_id text NULL;
@@ -0,0 +1,4 @@
+CREATE INDEX IF NOT EXISTS idx_deployments_env_id_retention ON skitapi.deployments USING btree (env_id, deployment_id DESC) WHERE artifacts_deleted IS NOT TRUE;
+CREATE INDEX IF NOT EXISTS idx_deployments_storage_location ON skitapi.deployments USING btree (storage_location) WHERE artifacts_deleted IS NOT TRUE;
+CREATE INDEX IF NOT EXISTS idx_deployments_function_location ON skitapi.deployments USING btree (function_location) WHERE artifacts_deleted IS NOT TRUE;
+CREATE INDEX IF NOT EXISTS idx_deployments_api_location ON skitapi.deployments USING btree (api_location) WHERE artifacts_deleted IS NOT TRUE;
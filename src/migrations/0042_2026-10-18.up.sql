@@ -0,0 +1 @@
+CREATE INDEX IF NOT EXISTS idx_kv_entries_env_id_kv_key ON skitapi.kv_entries USING btree (env_id, kv_key COLLATE "C");